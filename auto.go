@@ -0,0 +1,146 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hotsock/voker/vokerevents"
+)
+
+// AutoHandlers registers per-source handlers for [StartAuto]. Nil fields are
+// skipped; an event whose source has no handler is passed to OnFallback.
+type AutoHandlers struct {
+	// OnSQS handles Amazon SQS batches. [SQSHandler] returns a function of
+	// this type.
+	OnSQS func(context.Context, vokerevents.SQSEvent) (vokerevents.SQSEventResponse, error)
+
+	// OnS3 handles Amazon S3 event notifications.
+	OnS3 func(context.Context, vokerevents.S3Event) error
+
+	// OnEventBridge handles Amazon EventBridge events. Use
+	// [vokerevents.DecodeEventBridgeDetail] to decode the detail once its
+	// DetailType is known.
+	OnEventBridge func(context.Context, vokerevents.EventBridgeEvent[json.RawMessage]) error
+
+	// OnHTTP handles API Gateway, Function URL, and ALB requests. Wrap a typed
+	// handler, such as one built by vokerhttp.Wrap, with [RawHandler].
+	OnHTTP func(context.Context, json.RawMessage) (any, error)
+
+	// OnFallback handles every other payload, including recognized sources
+	// without a registered handler.
+	OnFallback func(context.Context, json.RawMessage) (any, error)
+}
+
+// StartAuto starts the Lambda runtime loop for a function subscribed to
+// several event sources. Each payload's shape is inspected to determine its
+// source, and the payload is decoded into that source's event type and passed
+// to the matching handler:
+//
+//	voker.StartAuto(voker.AutoHandlers{
+//	    OnSQS: voker.SQSHandler(processMessage),
+//	    OnEventBridge: func(ctx context.Context, event vokerevents.EventBridgeEvent[json.RawMessage]) error {
+//	        return reconcile(ctx, event.DetailType)
+//	    },
+//	})
+//
+// An event that matches no handler and no OnFallback fails with errorType
+// UnhandledEventError. StartAuto otherwise behaves like [Start].
+func StartAuto(handlers AutoHandlers, opts ...Option) {
+	Start(autoHandler(handlers), opts...)
+}
+
+// RawHandler adapts a typed handler into the json.RawMessage form used by
+// [AutoHandlers]. The payload is decoded exactly as [Start] would decode it,
+// including with the [Codec] and [Union] types passed to [WithCodec] and
+// [WithUnion].
+func RawHandler[TIn, TOut any](handler func(context.Context, TIn) (TOut, error)) func(context.Context, json.RawMessage) (any, error) {
+	return func(ctx context.Context, payload json.RawMessage) (any, error) {
+		input, err := unmarshalInput[TIn](payload, inputDecoderFromContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, input)
+	}
+}
+
+type eventSource string
+
+const (
+	eventSourceUnknown     eventSource = "unknown"
+	eventSourceSQS         eventSource = "SQS"
+	eventSourceS3          eventSource = "S3"
+	eventSourceEventBridge eventSource = "EventBridge"
+	eventSourceHTTP        eventSource = "HTTP"
+)
+
+// eventShape holds the fields that identify an event source. Decoding into
+// it skips every other field, so sniffing costs one pass over the payload.
+type eventShape struct {
+	Records []struct {
+		EventSource string `json:"eventSource"`
+	} `json:"Records"`
+	DetailType     *string `json:"detail-type"`
+	Source         string  `json:"source"`
+	HTTPMethod     string  `json:"httpMethod"`
+	RequestContext *struct {
+		HTTP *struct {
+			Method string `json:"method"`
+		} `json:"http"`
+	} `json:"requestContext"`
+}
+
+func detectEventSource(payload []byte) eventSource {
+	var shape eventShape
+	if err := json.Unmarshal(payload, &shape); err != nil {
+		return eventSourceUnknown
+	}
+
+	switch {
+	case len(shape.Records) > 0 && shape.Records[0].EventSource == "aws:sqs":
+		return eventSourceSQS
+	case len(shape.Records) > 0 && shape.Records[0].EventSource == "aws:s3":
+		return eventSourceS3
+	case shape.DetailType != nil && shape.Source != "":
+		return eventSourceEventBridge
+	case shape.HTTPMethod != "":
+		// API Gateway REST APIs and ALB target groups.
+		return eventSourceHTTP
+	case shape.RequestContext != nil && shape.RequestContext.HTTP != nil && shape.RequestContext.HTTP.Method != "":
+		// API Gateway HTTP APIs and Function URLs.
+		return eventSourceHTTP
+	default:
+		return eventSourceUnknown
+	}
+}
+
+func autoHandler(handlers AutoHandlers) func(context.Context, json.RawMessage) (any, error) {
+	return func(ctx context.Context, payload json.RawMessage) (any, error) {
+		source := detectEventSource(payload)
+		switch {
+		case source == eventSourceSQS && handlers.OnSQS != nil:
+			return RawHandler(handlers.OnSQS)(ctx, payload)
+		case source == eventSourceS3 && handlers.OnS3 != nil:
+			return RawHandler(withoutResponse(handlers.OnS3))(ctx, payload)
+		case source == eventSourceEventBridge && handlers.OnEventBridge != nil:
+			return RawHandler(withoutResponse(handlers.OnEventBridge))(ctx, payload)
+		case source == eventSourceHTTP && handlers.OnHTTP != nil:
+			return handlers.OnHTTP(ctx, payload)
+		case handlers.OnFallback != nil:
+			return handlers.OnFallback(ctx, payload)
+		default:
+			return nil, &ErrorResponse{
+				Message: fmt.Sprintf("no handler registered for %s event", source),
+				Type:    "UnhandledEventError",
+			}
+		}
+	}
+}
+
+// withoutResponse adapts an asynchronous event handler, whose result Lambda
+// discards, to the handler signature.
+func withoutResponse[TIn any](handler func(context.Context, TIn) error) func(context.Context, TIn) (struct{}, error) {
+	return func(ctx context.Context, input TIn) (struct{}, error) {
+		return struct{}{}, handler(ctx, input)
+	}
+}
@@ -0,0 +1,160 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hotsock/voker/vokerevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectEventSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    eventSource
+	}{
+		{"sqs", string(readEventFixture(t, "sqs-event.json")), eventSourceSQS},
+		{"s3", string(readEventFixture(t, "s3-event.json")), eventSourceS3},
+		{"eventbridge", string(readEventFixture(t, "eventbridge-event.json")), eventSourceEventBridge},
+		{"dynamodb", string(readEventFixture(t, "dynamodb-event.json")), eventSourceUnknown},
+		{"sns", `{"Records":[{"EventSource":"aws:sns"}]}`, eventSourceUnknown},
+		{"api gateway v1", `{"httpMethod":"GET","path":"/"}`, eventSourceHTTP},
+		{"alb", `{"httpMethod":"GET","requestContext":{"elb":{"targetGroupArn":"arn"}}}`, eventSourceHTTP},
+		{"function url", `{"version":"2.0","requestContext":{"http":{"method":"POST"}}}`, eventSourceHTTP},
+		{"authorizer context", `{"requestContext":{"accountId":"123"}}`, eventSourceUnknown},
+		{"custom", `{"name":"world"}`, eventSourceUnknown},
+		{"not an object", `"hello"`, eventSourceUnknown},
+		{"invalid", `{`, eventSourceUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectEventSource([]byte(tt.payload)))
+		})
+	}
+}
+
+func TestAutoHandler_Dispatch(t *testing.T) {
+	var got []string
+	handler := autoHandler(AutoHandlers{
+		OnSQS: SQSHandler(func(_ context.Context, msg vokerevents.SQSMessage) error {
+			got = append(got, "sqs:"+msg.MessageID)
+			return nil
+		}),
+		OnS3: func(_ context.Context, event vokerevents.S3Event) error {
+			got = append(got, "s3:"+event.Records[0].S3.Bucket.Name)
+			return nil
+		},
+		OnEventBridge: func(_ context.Context, event vokerevents.EventBridgeEvent[json.RawMessage]) error {
+			got = append(got, "eventbridge:"+event.Source)
+			return nil
+		},
+		OnHTTP: func(_ context.Context, payload json.RawMessage) (any, error) {
+			got = append(got, "http")
+			return map[string]int{"statusCode": 204}, nil
+		},
+		OnFallback: func(_ context.Context, payload json.RawMessage) (any, error) {
+			got = append(got, "fallback:"+string(payload))
+			return "ok", nil
+		},
+	})
+
+	ctx := context.Background()
+	response, err := handler(ctx, readEventFixture(t, "sqs-event.json"))
+	require.NoError(t, err)
+	assert.Equal(t, vokerevents.SQSEventResponse{BatchItemFailures: []vokerevents.BatchItemFailure{}}, response)
+
+	_, err = handler(ctx, readEventFixture(t, "s3-event.json"))
+	require.NoError(t, err)
+	_, err = handler(ctx, readEventFixture(t, "eventbridge-event.json"))
+	require.NoError(t, err)
+	response, err = handler(ctx, json.RawMessage(`{"httpMethod":"GET"}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"statusCode": 204}, response)
+	response, err = handler(ctx, json.RawMessage(`{"name":"world"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", response)
+
+	assert.Equal(t, []string{
+		"sqs:059f36b4-87a3-44ab-83d2-661975830a7d",
+		"sqs:2e1424d4-f796-459a-8184-9c92662be6da",
+		"s3:amzn-s3-demo-bucket",
+		"eventbridge:aws.ec2",
+		"http",
+		`fallback:{"name":"world"}`,
+	}, got)
+}
+
+func TestAutoHandler_UnregisteredSourceUsesFallback(t *testing.T) {
+	handler := autoHandler(AutoHandlers{
+		OnFallback: func(_ context.Context, payload json.RawMessage) (any, error) {
+			return len(payload), nil
+		},
+	})
+
+	payload := readEventFixture(t, "sqs-event.json")
+	response, err := handler(context.Background(), payload)
+	require.NoError(t, err)
+	assert.Equal(t, len(payload), response)
+}
+
+func TestAutoHandler_Unhandled(t *testing.T) {
+	handler := autoHandler(AutoHandlers{})
+
+	_, err := handler(context.Background(), readEventFixture(t, "s3-event.json"))
+	errResp, ok := errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "UnhandledEventError", errResp.Type)
+	assert.Equal(t, "no handler registered for S3 event", errResp.Message)
+
+	_, err = handler(context.Background(), json.RawMessage(`{}`))
+	require.Error(t, err)
+	assert.Equal(t, "no handler registered for unknown event", err.Error())
+}
+
+func TestAutoHandler_HandlerError(t *testing.T) {
+	handlerErr := errors.New("failed")
+	handler := autoHandler(AutoHandlers{
+		OnS3: func(context.Context, vokerevents.S3Event) error { return handlerErr },
+	})
+
+	_, err := handler(context.Background(), readEventFixture(t, "s3-event.json"))
+	assert.ErrorIs(t, err, handlerErr)
+}
+
+func TestRawHandler(t *testing.T) {
+	handler := RawHandler(func(_ context.Context, event testEvent) (testResponse, error) {
+		return testResponse{Message: "Hello, " + event.Name}, nil
+	})
+
+	response, err := handler(context.Background(), json.RawMessage(`{"name":"world"}`))
+	require.NoError(t, err)
+	assert.Equal(t, testResponse{Message: "Hello, world"}, response)
+
+	_, err = handler(context.Background(), json.RawMessage(`{`))
+	errResp, ok := errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "Runtime.UnmarshalError", errResp.Type)
+}
+
+func TestStartAuto_UsesCodec(t *testing.T) {
+	var bucket string
+	handler := autoHandler(AutoHandlers{
+		OnS3: func(_ context.Context, event vokerevents.S3Event) error {
+			bucket = event.Records[0].S3.Bucket.Name
+			return nil
+		},
+	})
+
+	codec := &countingCodec{}
+	options := &options{}
+	WithCodec(codec)(options)
+	_, err := callHandler(context.Background(), readEventFixture(t, "s3-event.json"), handler, options)
+	require.NoError(t, err)
+	assert.NotEmpty(t, bucket)
+	assert.Equal(t, 1, codec.unmarshals, "the routed S3 event should decode with the codec")
+}
@@ -0,0 +1,156 @@
+package voker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// BackgroundPolicy controls when the tasks started with [Go] must finish.
+type BackgroundPolicy int
+
+const (
+	// BackgroundWait delays an invocation's response until the tasks it
+	// started finish, or until 100ms before its deadline.
+	// Tasks receive the invocation's context and are canceled with it.
+	BackgroundWait BackgroundPolicy = iota
+
+	// BackgroundCarryOver responds without waiting. Tasks keep running
+	// while the execution environment is thawed, and the next invocation
+	// waits for them before its handler runs, as does shutdown. Tasks
+	// receive the invocation's context without its cancellation. It
+	// registers a shutdown hook, so like other internal extensions it is
+	// not supported on Lambda Managed Instances.
+	BackgroundCarryOver
+)
+
+// backgroundDeadlineMargin is the time left before an invocation's deadline
+// at which voker stops waiting for background tasks, leaving room to send
+// the response.
+const backgroundDeadlineMargin = 100 * time.Millisecond
+
+// WithBackgroundTasks tracks the tasks handlers start with [Go] and
+// finishes them according to policy. Lambda freezes the execution
+// environment as soon as an invocation responds, so goroutines a handler
+// leaves running are suspended mid-flight and may never complete.
+//
+//	voker.Start(handler, voker.WithBackgroundTasks(voker.BackgroundCarryOver))
+func WithBackgroundTasks(policy BackgroundPolicy) Option {
+	return func(o *options) {
+		o.background = &backgroundTasks{policy: policy}
+		if policy == BackgroundCarryOver {
+			o.shutdownHooks = append(o.shutdownHooks, o.background.shutdown)
+		}
+	}
+}
+
+// Go runs fn on a new goroutine that the invocation owning ctx tracks
+// according to the [WithBackgroundTasks] policy. A returned error is logged
+// with the runtime's logger, and a panic is recovered and logged instead of
+// crashing the process. Without WithBackgroundTasks, or outside an
+// invocation, fn runs untracked, and its error or panic is logged with
+// [slog.Default].
+func Go(ctx context.Context, fn func(ctx context.Context) error) {
+	tasks, ok := ctx.Value(invocationTasksKey{}).(*invocationTasks)
+	if !ok {
+		untracked := &invocationTasks{logger: slog.Default(), fullStackPaths: fullStackPathsFromContext(ctx)}
+		go untracked.run(ctx, fn)
+		return
+	}
+
+	taskCtx := ctx
+	if tasks.background.policy == BackgroundCarryOver {
+		taskCtx = context.WithoutCancel(ctx)
+	}
+	tasks.background.pending.Add(1)
+	tasks.wg.Go(func() {
+		defer tasks.background.pending.Done()
+		tasks.run(taskCtx, fn)
+	})
+}
+
+// backgroundTasks tracks the tasks of every invocation in the process.
+type backgroundTasks struct {
+	policy  BackgroundPolicy
+	pending sync.WaitGroup
+}
+
+// invocationTasks tracks the tasks of one invocation. A nil value, used
+// without WithBackgroundTasks, ignores every call.
+type invocationTasks struct {
+	background     *backgroundTasks
+	logger         *slog.Logger
+	fullStackPaths bool
+	wg             sync.WaitGroup
+}
+
+type invocationTasksKey struct{}
+
+// startTasks returns a copy of ctx that tracks the invocation's background
+// tasks. With BackgroundCarryOver it first waits for the tasks earlier
+// invocations left running.
+func (o *options) startTasks(ctx context.Context) (context.Context, *invocationTasks) {
+	if o.background == nil {
+		return ctx, nil
+	}
+	tasks := &invocationTasks{background: o.background, logger: o.logger, fullStackPaths: o.fullStackPaths}
+	if o.background.policy == BackgroundCarryOver {
+		tasks.await(ctx, &o.background.pending, "earlier invocations' background tasks still running")
+	}
+	return context.WithValue(ctx, invocationTasksKey{}, tasks), tasks
+}
+
+// finish waits for the invocation's tasks with BackgroundWait.
+func (t *invocationTasks) finish(ctx context.Context) {
+	if t == nil || t.background.policy != BackgroundWait {
+		return
+	}
+	t.await(ctx, &t.wg, "background tasks still running near the deadline")
+}
+
+// await waits for wg until backgroundDeadlineMargin before ctx's deadline,
+// logging message if the tasks are still running then.
+func (t *invocationTasks) await(ctx context.Context, wg *sync.WaitGroup, message string) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var timeout <-chan time.Time
+	if deadline, ok := ctx.Deadline(); ok {
+		timer := time.NewTimer(time.Until(deadline) - backgroundDeadlineMargin)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-done:
+	case <-timeout:
+		t.logger.WarnContext(ctx, message)
+	}
+}
+
+func (t *invocationTasks) run(ctx context.Context, fn func(ctx context.Context) error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			t.logger.ErrorContext(ctx, "background task panicked", "error", newPanicResponse(recovered, t.fullStackPaths))
+		}
+	}()
+	if err := fn(ctx); err != nil {
+		t.logger.ErrorContext(ctx, "background task failed", "error", err)
+	}
+}
+
+// shutdown waits for running tasks until ctx is done.
+func (b *backgroundTasks) shutdown(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		b.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,169 @@
+package voker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGo_Untracked(t *testing.T) {
+	done := make(chan struct{})
+	Go(context.Background(), func(ctx context.Context) error {
+		close(done)
+		return nil
+	})
+	<-done
+}
+
+// recordChannelHandler sends the message of every record to messages.
+type recordChannelHandler struct {
+	slog.Handler
+	messages chan string
+}
+
+func (h recordChannelHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h recordChannelHandler) Handle(_ context.Context, record slog.Record) error {
+	h.messages <- record.Message
+	return nil
+}
+
+func TestGo_UntrackedLogsFailures(t *testing.T) {
+	messages := make(chan string, 2)
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(recordChannelHandler{Handler: slog.DiscardHandler, messages: messages}))
+	defer slog.SetDefault(defaultLogger)
+
+	Go(context.Background(), func(ctx context.Context) error { return errors.New("upload failed") })
+	Go(context.Background(), func(ctx context.Context) error { panic("boom") })
+
+	got := []string{<-messages, <-messages}
+	assert.ElementsMatch(t, []string{"background task failed", "background task panicked"}, got)
+}
+
+func TestWithBackgroundTasks(t *testing.T) {
+	opts := &options{}
+	WithBackgroundTasks(BackgroundWait)(opts)
+	assert.Equal(t, BackgroundWait, opts.background.policy)
+	assert.Empty(t, opts.shutdownHooks)
+
+	opts = &options{}
+	WithBackgroundTasks(BackgroundCarryOver)(opts)
+	assert.Equal(t, BackgroundCarryOver, opts.background.policy)
+	assert.Len(t, opts.shutdownHooks, 1)
+}
+
+func TestHandleInvocation_BackgroundWait(t *testing.T) {
+	var finished atomic.Bool
+	var finishedBeforeResponse bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			w.Header().Set(headerRequestID, "background")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			_, _ = w.Write([]byte(`{"name":"background"}`))
+		default:
+			finishedBeforeResponse = finished.Load()
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.DiscardHandler)
+	options := &options{logger: logger}
+	WithBackgroundTasks(BackgroundWait)(options)
+
+	handler := func(ctx context.Context, event testEvent) (testResponse, error) {
+		Go(ctx, func(ctx context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			finished.Store(true)
+			return nil
+		})
+		return testResponse{Message: "hello"}, nil
+	}
+	require.NoError(t, handleInvocation(newRuntimeClient(server.URL[7:], logger), handler, options))
+	assert.True(t, finishedBeforeResponse)
+}
+
+func TestInvocationTasks_WaitStopsNearDeadline(t *testing.T) {
+	var logs bytes.Buffer
+	options := &options{logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	WithBackgroundTasks(BackgroundWait)(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundDeadlineMargin+20*time.Millisecond)
+	defer cancel()
+	ctx, tasks := options.startTasks(ctx)
+	release := make(chan struct{})
+	defer close(release)
+	Go(ctx, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	start := time.Now()
+	tasks.finish(ctx)
+	assert.Less(t, time.Since(start), backgroundDeadlineMargin)
+	assert.Contains(t, logs.String(), "background tasks still running near the deadline")
+}
+
+func TestInvocationTasks_CarryOver(t *testing.T) {
+	options := &options{logger: slog.New(slog.DiscardHandler)}
+	WithBackgroundTasks(BackgroundCarryOver)(options)
+
+	invocation, cancel := context.WithCancel(context.Background())
+	ctx, tasks := options.startTasks(invocation)
+	release := make(chan struct{})
+	var finished atomic.Bool
+	Go(ctx, func(ctx context.Context) error {
+		<-release
+		// The task outlives the invocation's context.
+		assert.NoError(t, ctx.Err())
+		finished.Store(true)
+		return nil
+	})
+	tasks.finish(ctx)
+	cancel()
+	assert.False(t, finished.Load())
+
+	// The next invocation waits for the task before its handler runs.
+	time.AfterFunc(10*time.Millisecond, func() { close(release) })
+	options.startTasks(context.Background())
+	assert.True(t, finished.Load())
+
+	// Shutdown waits too, until its deadline.
+	block := make(chan struct{})
+	defer close(block)
+	ctx, _ = options.startTasks(context.Background())
+	Go(ctx, func(ctx context.Context) error {
+		<-block
+		return nil
+	})
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShutdown()
+	options.shutdownHooks[0](shutdownCtx)
+	assert.Error(t, shutdownCtx.Err())
+}
+
+func TestGo_LogsFailures(t *testing.T) {
+	var logs bytes.Buffer
+	options := &options{logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	WithBackgroundTasks(BackgroundWait)(options)
+
+	ctx, tasks := options.startTasks(context.Background())
+	Go(ctx, func(ctx context.Context) error { return errors.New("upload failed") })
+	Go(ctx, func(ctx context.Context) error { panic("boom") })
+	tasks.finish(ctx)
+
+	assert.Contains(t, logs.String(), `msg="background task failed" error="upload failed"`)
+	assert.Contains(t, logs.String(), `msg="background task panicked"`)
+	assert.Contains(t, logs.String(), "boom")
+}
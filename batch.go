@@ -0,0 +1,104 @@
+package voker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type batchOptions struct {
+	concurrency        int
+	failWithBatchError bool
+}
+
+// BatchOption configures the batch handler wrappers such as [SQSHandler].
+type BatchOption func(*batchOptions)
+
+// WithBatchConcurrency processes up to n records of a batch concurrently.
+// The default of 1 processes records sequentially in batch order. Failures
+// are reported in batch order regardless of concurrency.
+func WithBatchConcurrency(n int) BatchOption {
+	return func(o *batchOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithoutPartialBatchResponse fails the invocation with a [*BatchError]
+// when any record fails, instead of returning a partial batch response, for
+// event source mappings that don't enable ReportBatchItemFailures. Lambda
+// then retries the whole batch, and the error lists every failed record.
+// Successful batches return an empty response as before.
+func WithoutPartialBatchResponse() BatchOption {
+	return func(o *batchOptions) {
+		o.failWithBatchError = true
+	}
+}
+
+func newBatchOptions(opts []BatchOption) *batchOptions {
+	options := &batchOptions{concurrency: 1}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.concurrency < 1 {
+		options.concurrency = 1
+	}
+	return options
+}
+
+// ProcessBatch calls fn for every record, running up to workers calls at
+// once, and returns each record's error at the record's index. It is the
+// engine behind [SQSHandler] and [DynamoDBStreamHandler], for batch sources
+// that voker doesn't wrap:
+//
+//	errs := voker.ProcessBatch(ctx, event.Records, 10, processRecord)
+//	for i, err := range errs {
+//	    if err != nil {
+//	        failures = append(failures, vokerevents.BatchItemFailure{ItemIdentifier: event.Records[i].Kinesis.SequenceNumber})
+//	    }
+//	}
+//
+// A panic in fn fails only its record, with an [*ErrorResponse] carrying the
+// panic's stack trace. Once ctx is done, records that haven't started fail
+// with the context's error instead of being passed to fn. With workers of 1
+// or less, records are processed sequentially in order.
+func ProcessBatch[T any](ctx context.Context, records []T, workers int, fn func(context.Context, T) error) []error {
+	errs := make([]error, len(records))
+	if workers <= 1 {
+		for i, record := range records {
+			errs[i] = processRecord(ctx, i, record, fn)
+		}
+		return errs
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, record := range records {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			errs[i] = processRecord(ctx, i, record, fn)
+		})
+	}
+	wg.Wait()
+	return errs
+}
+
+// processRecord calls fn for the record at index i unless ctx is done,
+// converting a panic into an error.
+func processRecord[T any](ctx context.Context, i int, record T, fn func(context.Context, T) error) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			response := newPanicResponse(recovered, fullStackPathsFromContext(ctx))
+			response.Message = fmt.Sprintf("record %d panicked: %s", i, response.Message)
+			// The panic is contained to its record, so it doesn't
+			// poison the execution environment.
+			response.fatal = false
+			err = response
+		}
+	}()
+	return fn(ctx, record)
+}
@@ -0,0 +1,75 @@
+package voker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBatchOptions_InvalidConcurrency(t *testing.T) {
+	assert.Equal(t, 1, newBatchOptions(nil).concurrency)
+	assert.Equal(t, 1, newBatchOptions([]BatchOption{WithBatchConcurrency(0)}).concurrency)
+	assert.Equal(t, 1, newBatchOptions([]BatchOption{WithBatchConcurrency(-4)}).concurrency)
+	assert.Equal(t, 8, newBatchOptions([]BatchOption{WithBatchConcurrency(8)}).concurrency)
+}
+
+func TestProcessBatch(t *testing.T) {
+	for _, workers := range []int{0, 1, 3} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			var processed atomic.Int32
+			errs := ProcessBatch(context.Background(), []string{"a", "b", "panic", "d"}, workers, func(_ context.Context, record string) error {
+				processed.Add(1)
+				switch record {
+				case "b":
+					return errors.New("b failed")
+				case "panic":
+					panic("record exploded")
+				}
+				return nil
+			})
+
+			require.Len(t, errs, 4)
+			assert.NoError(t, errs[0])
+			assert.EqualError(t, errs[1], "b failed")
+			assert.NoError(t, errs[3])
+			assert.Equal(t, int32(4), processed.Load())
+
+			var response *ErrorResponse
+			require.ErrorAs(t, errs[2], &response)
+			assert.Equal(t, "record 2 panicked: record exploded", response.Message)
+			assert.Equal(t, "Runtime.Panic.string", response.Type)
+			assert.NotEmpty(t, response.StackTrace)
+			assert.False(t, response.fatal)
+		})
+	}
+}
+
+func TestProcessBatch_StopsAtDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var processed []int
+	errs := ProcessBatch(ctx, []int{0, 1, 2, 3}, 1, func(_ context.Context, record int) error {
+		processed = append(processed, record)
+		if record == 1 {
+			cancel()
+		}
+		return nil
+	})
+
+	assert.Equal(t, []int{0, 1}, processed)
+	assert.Equal(t, []error{nil, nil, context.Canceled, context.Canceled}, errs)
+}
+
+func TestProcessRecord_FullStackPaths(t *testing.T) {
+	fn := func(context.Context, int) error { panic("boom") }
+
+	err := processRecord(withFullStackPaths(context.Background()), 0, 1, fn)
+	response, ok := errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	require.NotEmpty(t, response.StackTrace)
+	assert.Contains(t, fmt.Sprint(response.StackTrace), "github.com/hotsock/voker.TestProcessRecord_FullStackPaths")
+}
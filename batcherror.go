@@ -0,0 +1,120 @@
+package voker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// batchErrorMessageFailures is how many failed records a BatchError's
+// message names; its details list them all.
+const batchErrorMessageFailures = 3
+
+// BatchError reports the records of a batch that failed. Returned from a
+// handler, it fails the invocation with errorType BatchError and lists each
+// failed record's identifier, errorType, and errorMessage in the error's
+// errorDetails, so operators can see exactly which records failed.
+//
+// [SQSHandler] and [DynamoDBStreamHandler] return one with
+// [WithoutPartialBatchResponse]; [NewBatchError] builds one from the
+// results of [ProcessBatch].
+type BatchError struct {
+	// Records is the number of records in the batch.
+	Records int
+	// Failures are the failed records, in batch order.
+	Failures []BatchRecordError
+	// Skipped is the number of records that were never processed because
+	// processing stopped at the first failure.
+	Skipped int
+}
+
+// BatchRecordError is the failure of one record of a batch.
+type BatchRecordError struct {
+	// ItemIdentifier identifies the record, such as an SQS message ID or a
+	// stream sequence number.
+	ItemIdentifier string
+	Err            error
+}
+
+// NewBatchError returns a [*BatchError] for the records whose errs, as
+// returned by [ProcessBatch], are non-nil, identifying each with id. It
+// returns nil when every record succeeded:
+//
+//	errs := voker.ProcessBatch(ctx, event.Records, 10, processRecord)
+//	return struct{}{}, voker.NewBatchError(event.Records, errs, func(r vokerevents.KinesisEventRecord) string {
+//	    return r.Kinesis.SequenceNumber
+//	})
+func NewBatchError[T any](records []T, errs []error, id func(T) string) error {
+	batch := &BatchError{Records: len(records)}
+	for i, err := range errs {
+		if err != nil {
+			batch.Failures = append(batch.Failures, BatchRecordError{ItemIdentifier: id(records[i]), Err: err})
+		}
+	}
+	if len(batch.Failures) == 0 {
+		return nil
+	}
+	return batch
+}
+
+// Error summarizes the failures, naming the first few failed records, and
+// counts the records that were skipped.
+func (e *BatchError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d records failed", len(e.Failures), e.Records)
+	for i, failure := range e.Failures {
+		if i == batchErrorMessageFailures {
+			fmt.Fprintf(&b, "; and %d more", len(e.Failures)-i)
+			break
+		}
+		sep := "; "
+		if i == 0 {
+			sep = ": "
+		}
+		fmt.Fprintf(&b, "%s%s: %v", sep, failure.ItemIdentifier, failure.Err)
+	}
+	switch {
+	case e.Skipped == 1:
+		b.WriteString("; 1 record not processed")
+	case e.Skipped > 1:
+		fmt.Fprintf(&b, "; %d records not processed", e.Skipped)
+	}
+	return b.String()
+}
+
+// Unwrap returns the records' errors, so errors.Is and errors.As match any
+// of them.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+	return errs
+}
+
+// batchRecordFailure is a failed record as reported in errorDetails.
+type batchRecordFailure struct {
+	ItemIdentifier string `json:"itemIdentifier"`
+	ErrorType      string `json:"errorType"`
+	ErrorMessage   string `json:"errorMessage"`
+}
+
+// details returns the errorDetails of the error's response.
+func (e *BatchError) details() map[string]any {
+	failures := make([]batchRecordFailure, len(e.Failures))
+	for i, failure := range e.Failures {
+		response := newErrorResponse(failure.Err)
+		failures[i] = batchRecordFailure{
+			ItemIdentifier: failure.ItemIdentifier,
+			ErrorType:      response.Type,
+			ErrorMessage:   response.Message,
+		}
+	}
+	details := map[string]any{
+		"recordCount":   e.Records,
+		"failedRecords": failures,
+	}
+	if e.Skipped > 0 {
+		details["skippedRecordCount"] = e.Skipped
+	}
+	return details
+}
@@ -0,0 +1,87 @@
+package voker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBatchRecordError struct{ code int }
+
+func (e testBatchRecordError) Error() string { return fmt.Sprintf("code %d", e.code) }
+
+func TestNewBatchError(t *testing.T) {
+	records := []string{"a", "b", "c"}
+	id := func(record string) string { return "id-" + record }
+
+	assert.NoError(t, NewBatchError(records, make([]error, 3), id))
+
+	failed := errors.New("failed")
+	err := NewBatchError(records, []error{nil, failed, testBatchRecordError{code: 7}}, id)
+	batch, ok := errors.AsType[*BatchError](err)
+	require.True(t, ok)
+	assert.Equal(t, 3, batch.Records)
+	assert.Equal(t, []BatchRecordError{
+		{ItemIdentifier: "id-b", Err: failed},
+		{ItemIdentifier: "id-c", Err: testBatchRecordError{code: 7}},
+	}, batch.Failures)
+	assert.Equal(t, "2 of 3 records failed: id-b: failed; id-c: code 7", err.Error())
+	assert.ErrorIs(t, err, failed)
+	_, ok = errors.AsType[testBatchRecordError](err)
+	assert.True(t, ok)
+}
+
+func TestBatchError_MessageNamesFirstFailures(t *testing.T) {
+	batch := &BatchError{Records: 10}
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		batch.Failures = append(batch.Failures, BatchRecordError{ItemIdentifier: id, Err: errors.New("boom")})
+	}
+	assert.Equal(t, "5 of 10 records failed: a: boom; b: boom; c: boom; and 2 more", batch.Error())
+}
+
+func TestBatchError_Skipped(t *testing.T) {
+	batch := &BatchError{
+		Records:  5,
+		Failures: []BatchRecordError{{ItemIdentifier: "s2", Err: errors.New("boom")}},
+		Skipped:  3,
+	}
+	assert.Equal(t, "1 of 5 records failed: s2: boom; 3 records not processed", batch.Error())
+	assert.Equal(t, 3, batch.details()["skippedRecordCount"])
+
+	batch.Skipped = 0
+	assert.NotContains(t, batch.details(), "skippedRecordCount")
+}
+
+func TestBatchError_ErrorResponse(t *testing.T) {
+	err := NewBatchError([]string{"m1", "m2"}, []error{
+		testBatchRecordError{code: 3},
+		&ErrorResponse{Type: "Orders.Rejected", Message: "out of stock"},
+	}, func(id string) string { return id })
+
+	response := newErrorResponse(fmt.Errorf("processing orders: %w", err))
+	assert.Equal(t, "BatchError", response.Type)
+	assert.Equal(t, "processing orders: 2 of 2 records failed: m1: code 3; m2: out of stock", response.Message)
+
+	body, buf, encodeErr := encodeErrorResponse(response)
+	require.NoError(t, encodeErr)
+	defer buf.release()
+	assert.JSONEq(t, `{
+		"errorType": "BatchError",
+		"errorMessage": "processing orders: 2 of 2 records failed: m1: code 3; m2: out of stock",
+		"errorDetails": {
+			"recordCount": 2,
+			"failedRecords": [
+				{"itemIdentifier": "m1", "errorType": "testBatchRecordError", "errorMessage": "code 3"},
+				{"itemIdentifier": "m2", "errorType": "Orders.Rejected", "errorMessage": "out of stock"}
+			]
+		}
+	}`, string(body))
+
+	want, marshalErr := json.Marshal(response)
+	require.NoError(t, marshalErr)
+	assert.JSONEq(t, string(want), string(body))
+}
@@ -0,0 +1,90 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// WithChunkedResponses encodes JSON responses straight into a chunked POST
+// to the Runtime API instead of into a buffer that is then uploaded. This
+// avoids holding a second full copy of the encoded response, which lowers
+// peak memory for handlers that return multi-megabyte outputs.
+//
+// Lambda's 6 MB limit for buffered responses still applies. If encoding fails
+// partway, the upload is aborted and the marshal error is reported instead.
+// The option has no effect on streaming responses or when [WithCodec] is set.
+func WithChunkedResponses() Option {
+	return func(o *options) {
+		o.chunkedResponses = true
+	}
+}
+
+// sendChunked encodes value into a chunked /response upload. It returns the
+// number of bytes uploaded and, separately, any encoding error so the caller
+// can report it to the Runtime API.
+func (inv *invocation) sendChunked(ctx context.Context, value any, encoder JSONEncoderOptions) (n int, encodeErr error, err error) {
+	reader, writer := io.Pipe()
+	counter := &countingWriter{w: writer}
+	encoded := make(chan error, 1)
+	go func() {
+		enc := json.NewEncoder(&newlineTrimmer{w: counter})
+		encoder.configure(enc)
+		err := enc.Encode(value)
+		writer.CloseWithError(err)
+		encoded <- err
+	}()
+
+	err = inv.successReader(ctx, reader)
+	// Closing the reader unblocks the encoder if the upload ended before
+	// the encoder finished.
+	_ = reader.Close()
+	encodeErr = <-encoded
+	if encodeErr != nil && errors.Is(encodeErr, io.ErrClosedPipe) {
+		encodeErr = nil
+	}
+	return counter.n, encodeErr, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
+
+// newlineTrimmer drops the newline json.Encoder writes after each value so
+// chunked responses match json.Marshal. It holds back a trailing newline
+// until it knows whether more output follows, and never writes the last one.
+type newlineTrimmer struct {
+	w       io.Writer
+	pending bool
+}
+
+func (t *newlineTrimmer) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if t.pending {
+		if _, err := t.w.Write([]byte{'\n'}); err != nil {
+			return 0, err
+		}
+		t.pending = false
+	}
+	body := p
+	if p[len(p)-1] == '\n' {
+		body = p[:len(p)-1]
+		t.pending = true
+	}
+	if len(body) > 0 {
+		if _, err := t.w.Write(body); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,163 @@
+package voker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInvocation_ChunkedResponse(t *testing.T) {
+	type largeResponse struct {
+		Items []string `json:"items"`
+	}
+	response := largeResponse{Items: make([]string, 4096)}
+	for i := range response.Items {
+		response.Items[i] = strings.Repeat("x", 256) + "<&>\n"
+	}
+	want, err := json.Marshal(response)
+	require.NoError(t, err)
+
+	var received []byte
+	var transferEncoding []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			w.Header().Set(headerRequestID, "chunked")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			_, _ = w.Write([]byte(`{}`))
+		case "/2018-06-01/runtime/invocation/chunked/response":
+			transferEncoding = r.TransferEncoding
+			assert.Empty(t, r.Header.Get(headerResponseMode))
+			assert.Equal(t, contentTypeJSON, r.Header.Get(headerContentType))
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			received = body
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	client := newRuntimeClient(server.URL[7:], logger)
+	sink := &recordingSink{}
+	options := &options{logger: logger}
+	WithChunkedResponses()(options)
+	WithMetrics(sink)(options)
+
+	handler := func(context.Context, json.RawMessage) (largeResponse, error) {
+		return response, nil
+	}
+
+	require.NoError(t, handleInvocation(client, handler, options))
+	assert.Equal(t, []string{"chunked"}, transferEncoding)
+	assert.Equal(t, string(want), string(received))
+	require.Len(t, sink.metrics, 1)
+	assert.Equal(t, len(want), sink.metrics[0].ResponseBytes)
+}
+
+func TestInvocation_SendChunkedEncoderOptions(t *testing.T) {
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := newRuntimeClient(server.URL[7:], slog.New(slog.NewTextHandler(io.Discard, nil)))
+	inv := &invocation{client: client, requestID: "chunked"}
+	_, encodeErr, err := inv.sendChunked(context.Background(), map[string]string{"url": "/a?b=1&c=2"}, JSONEncoderOptions{DisableHTMLEscaping: true})
+	require.NoError(t, encodeErr)
+	require.NoError(t, err)
+	assert.Equal(t, `{"url":"/a?b=1&c=2"}`, string(received))
+}
+
+func TestHandleInvocation_ChunkedResponseMarshalError(t *testing.T) {
+	var errorBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			w.Header().Set(headerRequestID, "chunked")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			_, _ = w.Write([]byte(`{}`))
+		case "/2018-06-01/runtime/invocation/chunked/response":
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusAccepted)
+		case "/2018-06-01/runtime/invocation/chunked/error":
+			assert.Equal(t, "Runtime.MarshalError", r.Header.Get(headerFunctionErrorType))
+			errorBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := newRuntimeClient(server.URL[7:], logger)
+	options := &options{logger: logger}
+	WithChunkedResponses()(options)
+
+	handler := func(context.Context, json.RawMessage) (float64, error) {
+		return math.NaN(), nil
+	}
+
+	require.NoError(t, handleInvocation(client, handler, options))
+	var errResp ErrorResponse
+	require.NoError(t, json.Unmarshal(errorBody, &errResp))
+	assert.Equal(t, "Runtime.MarshalError", errResp.Type)
+	assert.Contains(t, errResp.Message, "failed to marshal output")
+}
+
+func TestCallHandler_ChunkedResponsesIgnoredWithCodec(t *testing.T) {
+	options := &options{}
+	WithChunkedResponses()(options)
+	WithCodec(&countingCodec{})(options)
+	handler := func(context.Context, json.RawMessage) (testResponse, error) {
+		return testResponse{Message: "ok"}, nil
+	}
+
+	response, err := callHandler(context.Background(), []byte(`{}`), handler, options)
+	require.NoError(t, err)
+	assert.False(t, response.encode)
+	assert.JSONEq(t, `{"message":"ok"}`, string(response.payload))
+}
+
+func TestNewlineTrimmer(t *testing.T) {
+	var buf bytes.Buffer
+	trimmer := &newlineTrimmer{w: &buf}
+
+	for _, chunk := range []string{"", "ab\n", "c", "\n", "d\n"} {
+		n, err := trimmer.Write([]byte(chunk))
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+	assert.Equal(t, "ab\nc\nd", buf.String())
+}
+
+func TestInvocation_SuccessReader_BadStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newRuntimeClient(server.URL[7:], slog.New(slog.NewTextHandler(io.Discard, nil)))
+	inv := &invocation{requestID: "req-123", client: client}
+
+	err := inv.successReader(context.Background(), strings.NewReader(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code")
+	assert.False(t, errors.Is(err, io.ErrClosedPipe))
+}
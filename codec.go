@@ -0,0 +1,65 @@
+package voker
+
+import "encoding/json"
+
+// Codec decodes invocation payloads into handler inputs and encodes handler
+// outputs. Implementations must produce and accept standard JSON and be safe
+// for concurrent use. The vokercodec module provides codecs backed by
+// faster JSON libraries.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONEncoderOptions controls how encoding/json encodes handler outputs.
+// The zero value matches json.Marshal.
+type JSONEncoderOptions struct {
+	// DisableHTMLEscaping leaves '<', '>', and '&' in strings as they are
+	// instead of escaping them as \u003c, \u003e, and \u0026, so URLs and
+	// markup in the response read the same to consumers that don't decode
+	// JSON escapes.
+	DisableHTMLEscaping bool
+
+	// Indent, when non-empty, indents nested values with it, one copy per
+	// level. Indented output is easier to read while testing locally but
+	// larger, and counts against Lambda's response size limit.
+	Indent string
+}
+
+// WithJSONEncoderOptions configures how handler outputs are encoded when no
+// [Codec] is set. Custom field naming and other encoding rules belong in a
+// Codec, which controls its own output and ignores these options.
+//
+//	voker.Start(handler, voker.WithJSONEncoderOptions(voker.JSONEncoderOptions{
+//	    DisableHTMLEscaping: true,
+//	}))
+//
+// Error responses are always encoded with the defaults.
+func WithJSONEncoderOptions(encoder JSONEncoderOptions) Option {
+	return func(o *options) {
+		o.jsonEncoder = encoder
+	}
+}
+
+// configure applies the options to enc, resetting any earlier settings.
+func (e JSONEncoderOptions) configure(enc *json.Encoder) {
+	enc.SetEscapeHTML(!e.DisableHTMLEscaping)
+	enc.SetIndent("", e.Indent)
+}
+
+// WithCodec replaces encoding/json for decoding handler inputs and encoding
+// handler outputs. json.RawMessage inputs are still passed through verbatim,
+// streaming outputs are unaffected, and error responses are always encoded
+// with encoding/json.
+func WithCodec(codec Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+func unmarshal(codec Codec, data []byte, v any) error {
+	if codec == nil {
+		return json.Unmarshal(data, v)
+	}
+	return codec.Unmarshal(data, v)
+}
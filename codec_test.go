@@ -0,0 +1,98 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingCodec struct {
+	marshals   int
+	unmarshals int
+	marshalErr error
+}
+
+func (c *countingCodec) Marshal(v any) ([]byte, error) {
+	c.marshals++
+	if c.marshalErr != nil {
+		return nil, c.marshalErr
+	}
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshals++
+	return json.Unmarshal(data, v)
+}
+
+func TestCallHandler_Codec(t *testing.T) {
+	codec := &countingCodec{}
+	options := &options{}
+	WithCodec(codec)(options)
+	handler := func(_ context.Context, event testEvent) (testResponse, error) {
+		return testResponse{Message: "Hello, " + event.Name}, nil
+	}
+
+	response, err := callHandler(context.Background(), []byte(`{"name":"world"}`), handler, options)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message":"Hello, world"}`, string(response.payload))
+	assert.Nil(t, response.buf)
+	assert.Equal(t, 1, codec.unmarshals)
+	assert.Equal(t, 1, codec.marshals)
+
+	options.middleware = []Middleware{func(next InvokeFunc) InvokeFunc { return next }}
+	_, err = callHandler(context.Background(), []byte(`{"name":"world"}`), handler, options)
+	require.NoError(t, err)
+	assert.Equal(t, 2, codec.unmarshals)
+}
+
+func TestCallHandler_CodecRawMessage(t *testing.T) {
+	codec := &countingCodec{}
+	handler := func(_ context.Context, payload json.RawMessage) (json.RawMessage, error) {
+		return payload, nil
+	}
+
+	_, err := callHandler(context.Background(), []byte(`{}`), handler, &options{codec: codec})
+	require.NoError(t, err)
+	assert.Zero(t, codec.unmarshals)
+}
+
+func TestCallHandler_CodecErrors(t *testing.T) {
+	codec := &countingCodec{marshalErr: errors.New("unsupported")}
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		return testResponse{}, nil
+	}
+
+	_, err := callHandler(context.Background(), []byte(`{`), handler, &options{codec: codec})
+	errResp, ok := errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "Runtime.UnmarshalError", errResp.Type)
+
+	_, err = callHandler(context.Background(), []byte(`{}`), handler, &options{codec: codec})
+	errResp, ok = errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "Runtime.MarshalError", errResp.Type)
+	assert.Equal(t, "failed to marshal output: unsupported", errResp.Message)
+}
+
+func TestCallHandler_JSONEncoderOptions(t *testing.T) {
+	handler := func(context.Context, json.RawMessage) (map[string]any, error) {
+		return map[string]any{"url": "https://example.com/?a=1&b=<2>", "tags": []string{"x"}}, nil
+	}
+
+	options := &options{}
+	WithJSONEncoderOptions(JSONEncoderOptions{DisableHTMLEscaping: true, Indent: "  "})(options)
+	response, err := callHandler(context.Background(), []byte(`{}`), handler, options)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"tags\": [\n    \"x\"\n  ],\n  \"url\": \"https://example.com/?a=1&b=<2>\"\n}", string(response.payload))
+	response.buf.release()
+
+	// Pooled encoders return to the defaults.
+	response, err = callHandler(context.Background(), []byte(`{}`), handler, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"tags":["x"],"url":"https://example.com/?a=1\u0026b=\u003c2\u003e"}`, string(response.payload))
+}
@@ -2,6 +2,9 @@ package voker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 )
 
 // ClientApplication contains metadata about the client application
@@ -16,7 +19,86 @@ type ClientApplication struct {
 type ClientContext struct {
 	Client ClientApplication `json:"client"`
 	Env    map[string]string `json:"env"`
+	// Custom holds the custom attributes set by the invoker. Values that
+	// are not JSON strings, such as the objects and numbers mobile SDKs and
+	// custom invokers send, hold their JSON text. Use [CustomContext] to
+	// decode them into a struct.
 	Custom map[string]string `json:"custom"`
+
+	// custom is the custom attributes as delivered, for CustomContext.
+	custom json.RawMessage
+}
+
+// UnmarshalJSON decodes a client context, accepting structured values in
+// custom.
+func (c *ClientContext) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Client ClientApplication `json:"client"`
+		Env    map[string]string `json:"env"`
+		Custom json.RawMessage   `json:"custom"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = ClientContext{Client: raw.Client, Env: raw.Env}
+	if len(raw.Custom) == 0 || string(raw.Custom) == "null" {
+		return nil
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(raw.Custom, &values); err != nil {
+		return fmt.Errorf("custom: %w", err)
+	}
+	c.custom = raw.Custom
+	c.Custom = make(map[string]string, len(values))
+	for key, value := range values {
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			text = string(value)
+		}
+		c.Custom[key] = text
+	}
+	return nil
+}
+
+// ErrNoLambdaContext is returned by [CustomContext] outside an invocation.
+var ErrNoLambdaContext = errors.New("no Lambda context")
+
+// CustomContext decodes the custom attributes of the invocation's client
+// context into T, typically a struct with json tags:
+//
+//	type appContext struct {
+//	    UserID   string   `json:"userId"`
+//	    Features []string `json:"features"`
+//	}
+//
+//	custom, err := voker.CustomContext[appContext](ctx)
+//
+// The attributes are decoded as Lambda delivered them, so structured values
+// keep their types. For a ClientContext built in code, such as in tests,
+// Custom is decoded as a JSON object of strings. A missing client context
+// decodes to the zero value.
+func CustomContext[T any](ctx context.Context) (T, error) {
+	var value T
+	lc, ok := FromContext(ctx)
+	if !ok {
+		return value, ErrNoLambdaContext
+	}
+
+	data := lc.ClientContext.custom
+	if data == nil {
+		if lc.ClientContext.Custom == nil {
+			return value, nil
+		}
+		var err error
+		if data, err = json.Marshal(lc.ClientContext.Custom); err != nil {
+			return value, fmt.Errorf("failed to encode client context custom: %w", err)
+		}
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("failed to decode client context custom: %w", err)
+	}
+	return value, nil
 }
 
 // CognitoIdentity contains Cognito identity information.
@@ -42,6 +124,13 @@ type LambdaContext struct {
 	// Lambda Runtime API.
 	TraceID string
 
+	// TraceParent and TraceState are the W3C Trace Context headers of an
+	// HTTP-shaped event (API Gateway, Function URL, or ALB). They are empty
+	// for other events and when the request carried no valid traceparent.
+	// Use [InjectTraceContext] to propagate them on outgoing requests.
+	TraceParent string
+	TraceState  string
+
 	// TenantID is the tenant identifier for functions using Lambda tenant
 	// isolation mode. It is empty when the function does not use tenant
 	// isolation or the invocation carries no tenant ID.
@@ -54,6 +143,37 @@ type LambdaContext struct {
 	ClientContext ClientContext
 }
 
+// WithMinimalContext skips the parts of [LambdaContext] that cost a JSON
+// decode per invocation: Identity, ClientContext, TraceParent, and
+// TraceState stay empty. AwsRequestID, InvokedFunctionArn, TraceID, and
+// TenantID are still set. Use it for functions that never read the skipped
+// fields, directly or through [CustomContext], [InjectTraceContext], or
+// [TenantFromCognitoIdentity].
+func WithMinimalContext() Option {
+	return func(o *options) {
+		o.minimalContext = true
+	}
+}
+
+// parseInvocationMetadata sets the fields of lc that [WithMinimalContext]
+// skips.
+func parseInvocationMetadata(lc *LambdaContext, inv *invocation) error {
+	lc.TraceParent, lc.TraceState = traceContextFromPayload(inv.payload)
+
+	if cognitoJSON := inv.headers.Get(headerCognitoIdentity); cognitoJSON != "" {
+		if err := json.Unmarshal([]byte(cognitoJSON), &lc.Identity); err != nil {
+			return fmt.Errorf("failed to parse cognito identity: %w", err)
+		}
+	}
+
+	if clientJSON := inv.headers.Get(headerClientContext); clientJSON != "" {
+		if err := json.Unmarshal([]byte(clientJSON), &lc.ClientContext); err != nil {
+			return fmt.Errorf("failed to parse client context: %w", err)
+		}
+	}
+	return nil
+}
+
 type contextKey struct{}
 
 var lambdaContextKey = &contextKey{}
@@ -68,3 +188,57 @@ func FromContext(ctx context.Context) (*LambdaContext, bool) {
 	lc, ok := ctx.Value(lambdaContextKey).(*LambdaContext)
 	return lc, ok
 }
+
+// TraceIDFromContext returns the X-Ray trace header of the invocation that
+// owns ctx, or "" outside an invocation. Voker never writes the trace header
+// to the process-wide _X_AMZN_TRACE_ID environment variable, because
+// concurrent invocations and background goroutines would observe each
+// other's values; read it from the context instead.
+func TraceIDFromContext(ctx context.Context) string {
+	if lc, ok := FromContext(ctx); ok {
+		return lc.TraceID
+	}
+	return ""
+}
+
+// WithContextValues makes values available to every invocation through
+// ctx.Value, for configuration that deeply nested code reads without each
+// caller passing it along:
+//
+//	type tableKey struct{}
+//
+//	voker.Start(handler, voker.WithContextValues(map[any]any{
+//	    tableKey{}: os.Getenv("TABLE_NAME"),
+//	}))
+//
+//	// Anywhere below the handler:
+//	table := ctx.Value(tableKey{}).(string)
+//
+// As with context.WithValue, keys should be unexported types so they can't
+// collide with other packages' keys. Values set by later calls replace
+// earlier ones with the same key, and values set inside an invocation take
+// precedence. The map is copied, so changing it after Start has no effect.
+func WithContextValues(values map[any]any) Option {
+	return func(o *options) {
+		if o.contextValues == nil {
+			o.contextValues = make(map[any]any, len(values))
+		}
+		for key, value := range values {
+			o.contextValues[key] = value
+		}
+	}
+}
+
+// valuesContext adds a fixed set of values to its parent without a
+// context.WithValue layer per value.
+type valuesContext struct {
+	context.Context
+	values map[any]any
+}
+
+func (c valuesContext) Value(key any) any {
+	if value, ok := c.values[key]; ok {
+		return value
+	}
+	return c.Context.Value(key)
+}
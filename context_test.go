@@ -2,9 +2,15 @@ package voker
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLambdaContext(t *testing.T) {
@@ -45,3 +51,162 @@ func TestFromContext_NotPresent(t *testing.T) {
 	assert.False(t, ok)
 	assert.Nil(t, lc)
 }
+
+func TestTraceIDFromContext(t *testing.T) {
+	ctx := NewContext(context.Background(), &LambdaContext{TraceID: "Root=1-test;Sampled=1"})
+	assert.Equal(t, "Root=1-test;Sampled=1", TraceIDFromContext(ctx))
+	assert.Empty(t, TraceIDFromContext(context.Background()))
+}
+
+type (
+	testConfigKey struct{}
+	testParentKey struct{}
+)
+
+func TestWithContextValues(t *testing.T) {
+	values := map[any]any{testConfigKey{}: "orders", "region": "us-east-1"}
+	opts := &options{}
+	WithContextValues(values)(opts)
+	WithContextValues(map[any]any{"region": "eu-west-1"})(opts)
+	values[testConfigKey{}] = "changed"
+
+	ctx := opts.invocationParent()
+	assert.Equal(t, "orders", ctx.Value(testConfigKey{}))
+	assert.Equal(t, "eu-west-1", ctx.Value("region"))
+	assert.Nil(t, ctx.Value("missing"))
+
+	shutdownCtx, shutdown := context.WithCancelCause(context.WithValue(context.Background(), testParentKey{}, "value"))
+	opts.shutdownCtx = shutdownCtx
+	ctx = opts.invocationParent()
+	assert.Equal(t, "value", ctx.Value(testParentKey{}))
+	shutdown(ErrSIGTERM)
+	assert.ErrorIs(t, context.Cause(ctx), ErrSIGTERM)
+}
+
+func TestHandleInvocation_ContextValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			w.Header().Set(headerRequestID, "req-123")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := newRuntimeClient(server.URL[7:], logger)
+	opts := &options{logger: logger}
+	WithContextValues(map[any]any{testConfigKey{}: "orders"})(opts)
+
+	called := false
+	handler := func(ctx context.Context, _ testEvent) (testResponse, error) {
+		called = true
+		assert.Equal(t, "orders", ctx.Value(testConfigKey{}))
+		_, ok := FromContext(ctx)
+		assert.True(t, ok)
+		return testResponse{}, nil
+	}
+
+	require.NoError(t, handleInvocation(client, handler, opts))
+	assert.True(t, called)
+}
+
+func TestHandleInvocation_MinimalContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			w.Header().Set(headerRequestID, "req-123")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			w.Header().Set(headerFunctionARN, "arn:aws:lambda:us-west-2:123:function:foo")
+			w.Header().Set(headerTraceID, "Root=1-5e9c5b5f-1234567890abcdef")
+			w.Header().Set(headerTenantID, "tenant-blue")
+			// Malformed metadata is never decoded, so it can't fail the
+			// invocation.
+			w.Header().Set(headerCognitoIdentity, `{"cognitoIdentityId":`)
+			w.Header().Set(headerClientContext, `{"client":{"installation_id":"install-1"}}`)
+			_, _ = w.Write([]byte(`{"headers":{"traceparent":"` + testTraceParent + `"}}`))
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := newRuntimeClient(server.URL[7:], logger)
+	opts := &options{logger: logger}
+	WithMinimalContext()(opts)
+
+	called := false
+	handler := func(ctx context.Context, _ testEvent) (testResponse, error) {
+		called = true
+		lc, ok := FromContext(ctx)
+		require.True(t, ok)
+		assert.Equal(t, &LambdaContext{
+			AwsRequestID:       "req-123",
+			InvokedFunctionArn: "arn:aws:lambda:us-west-2:123:function:foo",
+			TraceID:            "Root=1-5e9c5b5f-1234567890abcdef",
+			TenantID:           "tenant-blue",
+		}, lc)
+		return testResponse{}, nil
+	}
+
+	require.NoError(t, handleInvocation(client, handler, opts))
+	assert.True(t, called)
+}
+
+func TestClientContext_UnmarshalJSON(t *testing.T) {
+	var cc ClientContext
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"client": {"installation_id": "install-abc"},
+		"env": {"platform": "iOS"},
+		"custom": {"userId": "u-1", "features": ["beta", "dark"], "retries": 3}
+	}`), &cc))
+
+	assert.Equal(t, "install-abc", cc.Client.InstallationID)
+	assert.Equal(t, map[string]string{"platform": "iOS"}, cc.Env)
+	assert.Equal(t, map[string]string{
+		"userId":   "u-1",
+		"features": `["beta", "dark"]`,
+		"retries":  "3",
+	}, cc.Custom)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"custom":null}`), &cc))
+	assert.Nil(t, cc.Custom)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"custom":"not an object"}`), &cc))
+}
+
+func TestCustomContext(t *testing.T) {
+	type appContext struct {
+		UserID   string   `json:"userId"`
+		Features []string `json:"features"`
+		Retries  int      `json:"retries"`
+	}
+
+	var cc ClientContext
+	require.NoError(t, json.Unmarshal([]byte(`{"custom":{"userId":"u-1","features":["beta"],"retries":3}}`), &cc))
+	ctx := NewContext(context.Background(), &LambdaContext{ClientContext: cc})
+	custom, err := CustomContext[appContext](ctx)
+	require.NoError(t, err)
+	assert.Equal(t, appContext{UserID: "u-1", Features: []string{"beta"}, Retries: 3}, custom)
+
+	ctx = NewContext(context.Background(), &LambdaContext{ClientContext: ClientContext{Custom: map[string]string{"userId": "u-2"}}})
+	custom, err = CustomContext[appContext](ctx)
+	require.NoError(t, err)
+	assert.Equal(t, appContext{UserID: "u-2"}, custom)
+
+	custom, err = CustomContext[appContext](NewContext(context.Background(), &LambdaContext{}))
+	require.NoError(t, err)
+	assert.Zero(t, custom)
+
+	_, err = CustomContext[appContext](context.Background())
+	assert.ErrorIs(t, err, ErrNoLambdaContext)
+
+	_, err = CustomContext[struct {
+		UserID int `json:"userId"`
+	}](ctx)
+	assert.ErrorContains(t, err, "failed to decode client context custom")
+}
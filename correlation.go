@@ -0,0 +1,133 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+)
+
+// CorrelationExtractor derives log correlation attributes, such as the
+// identifiers an event source assigned to the event, from an invocation.
+// It returns nil when the invocation carries none it recognizes.
+type CorrelationExtractor interface {
+	Correlation(ctx context.Context, payload json.RawMessage) []slog.Attr
+}
+
+// CorrelationFunc adapts a function to a [CorrelationExtractor].
+type CorrelationFunc func(ctx context.Context, payload json.RawMessage) []slog.Attr
+
+// Correlation calls f.
+func (f CorrelationFunc) Correlation(ctx context.Context, payload json.RawMessage) []slog.Attr {
+	return f(ctx, payload)
+}
+
+type correlationContextKey struct{}
+
+// NewCorrelationContext returns a copy of parent that carries attrs after
+// any correlation attributes parent already carries.
+func NewCorrelationContext(parent context.Context, attrs ...slog.Attr) context.Context {
+	existing := CorrelationFromContext(parent)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(append(combined, existing...), attrs...)
+	return context.WithValue(parent, correlationContextKey{}, combined)
+}
+
+// CorrelationFromContext returns the correlation attributes [WithCorrelation]
+// extracted for the invocation that owns ctx.
+func CorrelationFromContext(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(correlationContextKey{}).([]slog.Attr)
+	return attrs
+}
+
+// WithCorrelation extracts correlation attributes from every invocation
+// with each extractor and stores them in the context for
+// [CorrelationFromContext]. vokerslog adds them to the record of every log
+// entry written with the invocation's context, so a function's logs can be
+// joined to the API Gateway access log, the SQS message, or the
+// EventBridge event that caused them:
+//
+//	voker.Start(handler, voker.WithCorrelation())
+//
+// Without extractors, [EventSourceCorrelation] is used. The extractors run
+// as middleware, so register WithCorrelation before middleware that logs.
+func WithCorrelation(extractors ...CorrelationExtractor) Option {
+	if len(extractors) == 0 {
+		extractors = []CorrelationExtractor{EventSourceCorrelation()}
+	}
+	return WithMiddleware(func(next InvokeFunc) InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			var attrs []slog.Attr
+			for _, extractor := range extractors {
+				attrs = append(attrs, extractor.Correlation(ctx, payload)...)
+			}
+			if len(attrs) > 0 {
+				ctx = NewCorrelationContext(ctx, attrs...)
+			}
+			return next(ctx, payload)
+		}
+	})
+}
+
+// EventSourceCorrelation recognizes events from common sources and returns
+// the identifiers they assign:
+//
+//   - apiRequestId: the request ID of API Gateway REST, HTTP, and WebSocket
+//     APIs and of function URLs
+//   - eventBridgeEventId: the ID of an EventBridge event
+//   - sqsMessageIds: the message IDs of an SQS batch
+//   - snsMessageIds: the message IDs of an SNS notification
+//   - s3RequestIds: the request IDs of the S3 requests that caused S3
+//     event notifications
+func EventSourceCorrelation() CorrelationExtractor {
+	return CorrelationFunc(func(_ context.Context, payload json.RawMessage) []slog.Attr {
+		var event struct {
+			RequestContext struct {
+				RequestID string `json:"requestId"`
+			} `json:"requestContext"`
+			DetailType string `json:"detail-type"`
+			ID         string `json:"id"`
+			Records    []struct {
+				MessageID string `json:"messageId"`
+				SNS       struct {
+					MessageID string `json:"MessageId"`
+				} `json:"Sns"`
+				ResponseElements struct {
+					RequestID string `json:"x-amz-request-id"`
+				} `json:"responseElements"`
+			} `json:"Records"`
+		}
+		if json.Unmarshal(payload, &event) != nil {
+			return nil
+		}
+
+		var attrs []slog.Attr
+		if event.RequestContext.RequestID != "" {
+			attrs = append(attrs, slog.String("apiRequestId", event.RequestContext.RequestID))
+		}
+		if event.DetailType != "" && event.ID != "" {
+			attrs = append(attrs, slog.String("eventBridgeEventId", event.ID))
+		}
+
+		var sqs, sns, s3 []string
+		for _, record := range event.Records {
+			switch {
+			case record.MessageID != "":
+				sqs = append(sqs, record.MessageID)
+			case record.SNS.MessageID != "":
+				sns = append(sns, record.SNS.MessageID)
+			case record.ResponseElements.RequestID != "":
+				s3 = append(s3, record.ResponseElements.RequestID)
+			}
+		}
+		attrs = appendCorrelationIDs(attrs, "sqsMessageIds", sqs)
+		attrs = appendCorrelationIDs(attrs, "snsMessageIds", sns)
+		return appendCorrelationIDs(attrs, "s3RequestIds", s3)
+	})
+}
+
+func appendCorrelationIDs(attrs []slog.Attr, key string, ids []string) []slog.Attr {
+	if len(ids) == 0 {
+		return attrs
+	}
+	return append(attrs, slog.Any(key, ids))
+}
@@ -0,0 +1,87 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventSourceCorrelation(t *testing.T) {
+	tests := map[string]struct {
+		payload string
+		want    []slog.Attr
+	}{
+		"API Gateway": {
+			payload: `{"rawPath":"/orders","requestContext":{"requestId":"c6af9ac6-7b61-11e6-9a41-93e8deadbeef"}}`,
+			want:    []slog.Attr{slog.String("apiRequestId", "c6af9ac6-7b61-11e6-9a41-93e8deadbeef")},
+		},
+		"EventBridge": {
+			payload: `{"id":"53dc4d37-cffa-4f76-80c9-8b7d4a4d2eaa","detail-type":"Order Placed","source":"com.example.orders","detail":{}}`,
+			want:    []slog.Attr{slog.String("eventBridgeEventId", "53dc4d37-cffa-4f76-80c9-8b7d4a4d2eaa")},
+		},
+		"SQS": {
+			payload: `{"Records":[{"messageId":"m1","eventSource":"aws:sqs"},{"messageId":"m2","eventSource":"aws:sqs"}]}`,
+			want:    []slog.Attr{slog.Any("sqsMessageIds", []string{"m1", "m2"})},
+		},
+		"SNS": {
+			payload: `{"Records":[{"EventSource":"aws:sns","Sns":{"MessageId":"n1"}}]}`,
+			want:    []slog.Attr{slog.Any("snsMessageIds", []string{"n1"})},
+		},
+		"S3": {
+			payload: `{"Records":[{"eventSource":"aws:s3","responseElements":{"x-amz-request-id":"C3D13FE58DE4C810"}}]}`,
+			want:    []slog.Attr{slog.Any("s3RequestIds", []string{"C3D13FE58DE4C810"})},
+		},
+		"unrecognized":  {payload: `{"name":"World"}`},
+		"not an object": {payload: `[1,2]`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := EventSourceCorrelation().Correlation(context.Background(), json.RawMessage(tt.payload))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWithCorrelation(t *testing.T) {
+	var got []slog.Attr
+	handler := func(ctx context.Context, _ json.RawMessage) (struct{}, error) {
+		got = CorrelationFromContext(ctx)
+		return struct{}{}, nil
+	}
+	custom := CorrelationFunc(func(context.Context, json.RawMessage) []slog.Attr {
+		return []slog.Attr{slog.String("orderId", "o-1")}
+	})
+	options := &options{}
+	WithCorrelation(EventSourceCorrelation(), custom)(options)
+
+	ctx := NewCorrelationContext(context.Background(), slog.String("upstream", "u-1"))
+	_, err := callHandler(ctx, []byte(`{"requestContext":{"requestId":"r-1"}}`), handler, options)
+	require.NoError(t, err)
+	assert.Equal(t, []slog.Attr{
+		slog.String("upstream", "u-1"),
+		slog.String("apiRequestId", "r-1"),
+		slog.String("orderId", "o-1"),
+	}, got)
+}
+
+func TestWithCorrelation_DefaultsToEventSource(t *testing.T) {
+	var got []slog.Attr
+	handler := func(ctx context.Context, _ json.RawMessage) (struct{}, error) {
+		got = CorrelationFromContext(ctx)
+		return struct{}{}, nil
+	}
+	options := &options{}
+	WithCorrelation()(options)
+
+	_, err := callHandler(context.Background(), []byte(`{"Records":[{"messageId":"m1"}]}`), handler, options)
+	require.NoError(t, err)
+	assert.Equal(t, []slog.Attr{slog.Any("sqsMessageIds", []string{"m1"})}, got)
+
+	_, err = callHandler(context.Background(), []byte(`{}`), handler, options)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,125 @@
+package voker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hotsock/voker/vokerevents"
+)
+
+// DynamoDBChange is a decoded DynamoDB stream record passed to a
+// [DynamoDBStreamHandler] handler.
+type DynamoDBChange[T any] struct {
+	// EventName is vokerevents.DynamoDBEventInsert, DynamoDBEventModify, or
+	// DynamoDBEventRemove.
+	EventName string
+
+	// Keys holds the primary key attributes of the changed item.
+	Keys map[string]vokerevents.DynamoDBAttributeValue
+
+	// OldImage is the item before the change. It is nil for inserts and when
+	// the stream view type does not include old images.
+	OldImage *T
+
+	// NewImage is the item after the change. It is nil for removals and when
+	// the stream view type does not include new images.
+	NewImage *T
+
+	// Record is the underlying stream record.
+	Record vokerevents.DynamoDBEventRecord
+}
+
+// DynamoDBStreamHandler adapts a per-change handler into a DynamoDB Streams
+// batch handler for [Start]. Item images are decoded into T using T's json
+// struct tags (see [vokerevents.UnmarshalDynamoDBImage]):
+//
+//	type Order struct {
+//	    ID     string `json:"pk"`
+//	    Status string `json:"status"`
+//	}
+//
+//	voker.Start(voker.DynamoDBStreamHandler(func(ctx context.Context, change voker.DynamoDBChange[Order]) error {
+//	    if change.EventName == vokerevents.DynamoDBEventModify {
+//	        return notify(ctx, change.OldImage.Status, change.NewImage.Status)
+//	    }
+//	    return nil
+//	}))
+//
+// A record whose images cannot be decoded, or for which handler returns an
+// error, fails. Lambda retries a stream batch from the first failed record,
+// so records are processed in order and processing stops at the first
+// failure, whose sequence number is reported in the partial batch response.
+// With [WithBatchConcurrency], records are processed concurrently and the
+// earliest failure in batch order is reported; later records may then be
+// processed again on retry. The event source mapping must enable
+// ReportBatchItemFailures; without it, Lambda ignores the response. Use
+// [WithoutPartialBatchResponse] for mappings that don't.
+func DynamoDBStreamHandler[T any](handler func(context.Context, DynamoDBChange[T]) error, opts ...BatchOption) func(context.Context, vokerevents.DynamoDBEvent) (vokerevents.DynamoDBEventResponse, error) {
+	options := newBatchOptions(opts)
+	process := func(ctx context.Context, record vokerevents.DynamoDBEventRecord) error {
+		change, err := newDynamoDBChange[T](record)
+		if err != nil {
+			return err
+		}
+		return handler(ctx, change)
+	}
+
+	return func(ctx context.Context, event vokerevents.DynamoDBEvent) (vokerevents.DynamoDBEventResponse, error) {
+		response := vokerevents.DynamoDBEventResponse{BatchItemFailures: []vokerevents.BatchItemFailure{}}
+
+		var errs []error
+		skipped := 0
+		if options.concurrency > 1 {
+			errs = ProcessBatch(ctx, event.Records, options.concurrency, process)
+		} else {
+			errs = make([]error, len(event.Records))
+			for i, record := range event.Records {
+				if errs[i] = processRecord(ctx, i, record, process); errs[i] != nil {
+					skipped = len(event.Records) - i - 1
+					break
+				}
+			}
+		}
+
+		if options.failWithBatchError {
+			err := NewBatchError(event.Records, errs, func(record vokerevents.DynamoDBEventRecord) string {
+				return record.Change.SequenceNumber
+			})
+			if batch, ok := err.(*BatchError); ok {
+				batch.Skipped = skipped
+			}
+			return response, err
+		}
+
+		for i, err := range errs {
+			if err != nil {
+				response.BatchItemFailures = append(response.BatchItemFailures, vokerevents.BatchItemFailure{
+					ItemIdentifier: event.Records[i].Change.SequenceNumber,
+				})
+				break
+			}
+		}
+		return response, nil
+	}
+}
+
+func newDynamoDBChange[T any](record vokerevents.DynamoDBEventRecord) (DynamoDBChange[T], error) {
+	change := DynamoDBChange[T]{
+		EventName: record.EventName,
+		Keys:      record.Change.Keys,
+		Record:    record,
+	}
+	if record.Change.OldImage != nil {
+		change.OldImage = new(T)
+		if err := vokerevents.UnmarshalDynamoDBImage(record.Change.OldImage, change.OldImage); err != nil {
+			return DynamoDBChange[T]{}, fmt.Errorf("failed to decode old image of %s: %w", record.EventID, err)
+		}
+	}
+	if record.Change.NewImage != nil {
+		change.NewImage = new(T)
+		if err := vokerevents.UnmarshalDynamoDBImage(record.Change.NewImage, change.NewImage); err != nil {
+			return DynamoDBChange[T]{}, fmt.Errorf("failed to decode new image of %s: %w", record.EventID, err)
+		}
+	}
+	return change, nil
+}
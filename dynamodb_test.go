@@ -0,0 +1,144 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/hotsock/voker/vokerevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDynamoDBItem struct {
+	ID      int    `json:"Id"`
+	Message string `json:"Message"`
+}
+
+func readDynamoDBFixture(t *testing.T) vokerevents.DynamoDBEvent {
+	t.Helper()
+
+	var event vokerevents.DynamoDBEvent
+	require.NoError(t, json.Unmarshal(readEventFixture(t, "dynamodb-event.json"), &event))
+	return event
+}
+
+func TestDynamoDBStreamHandler_DecodesImages(t *testing.T) {
+	var changes []DynamoDBChange[testDynamoDBItem]
+	handler := DynamoDBStreamHandler(func(_ context.Context, change DynamoDBChange[testDynamoDBItem]) error {
+		changes = append(changes, change)
+		return nil
+	})
+
+	response, err := handler(context.Background(), readDynamoDBFixture(t))
+	require.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	require.Len(t, changes, 3)
+
+	insert := changes[0]
+	assert.Equal(t, vokerevents.DynamoDBEventInsert, insert.EventName)
+	assert.Nil(t, insert.OldImage)
+	require.NotNil(t, insert.NewImage)
+	assert.Equal(t, testDynamoDBItem{ID: 101, Message: "New item!"}, *insert.NewImage)
+	assert.Equal(t, "101", *insert.Keys["Id"].N)
+
+	modify := changes[1]
+	assert.Equal(t, vokerevents.DynamoDBEventModify, modify.EventName)
+	assert.Equal(t, "New item!", modify.OldImage.Message)
+	assert.Equal(t, "This item has changed", modify.NewImage.Message)
+
+	remove := changes[2]
+	assert.Equal(t, vokerevents.DynamoDBEventRemove, remove.EventName)
+	assert.Nil(t, remove.NewImage)
+	assert.Equal(t, "This item has changed", remove.OldImage.Message)
+	assert.Equal(t, "333", remove.Record.Change.SequenceNumber)
+}
+
+func TestDynamoDBStreamHandler_StopsAtFirstFailure(t *testing.T) {
+	var processed []string
+	handler := DynamoDBStreamHandler(func(_ context.Context, change DynamoDBChange[testDynamoDBItem]) error {
+		processed = append(processed, change.EventName)
+		if change.EventName == vokerevents.DynamoDBEventModify {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	response, err := handler(context.Background(), readDynamoDBFixture(t))
+	require.NoError(t, err)
+	assert.Equal(t, []string{vokerevents.DynamoDBEventInsert, vokerevents.DynamoDBEventModify}, processed)
+	assert.Equal(t, []vokerevents.BatchItemFailure{{ItemIdentifier: "222"}}, response.BatchItemFailures)
+}
+
+func TestDynamoDBStreamHandler_DecodeFailure(t *testing.T) {
+	event := readDynamoDBFixture(t)
+	message := "not a number"
+	event.Records[0].Change.NewImage["Id"] = vokerevents.DynamoDBAttributeValue{S: &message}
+
+	handler := DynamoDBStreamHandler(func(context.Context, DynamoDBChange[testDynamoDBItem]) error {
+		t.Fatal("handler called for undecodable record")
+		return nil
+	})
+
+	response, err := handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, []vokerevents.BatchItemFailure{{ItemIdentifier: "111"}}, response.BatchItemFailures)
+
+	_, err = newDynamoDBChange[testDynamoDBItem](event.Records[0])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode new image of c4ca4238a0b923820dcc509a6f75849b")
+}
+
+func TestDynamoDBStreamHandler_ConcurrentReportsEarliestFailure(t *testing.T) {
+	var mu sync.Mutex
+	var processed []string
+	handler := DynamoDBStreamHandler(func(_ context.Context, change DynamoDBChange[testDynamoDBItem]) error {
+		mu.Lock()
+		processed = append(processed, change.EventName)
+		mu.Unlock()
+		if change.EventName != vokerevents.DynamoDBEventInsert {
+			return errors.New("failed")
+		}
+		return nil
+	}, WithBatchConcurrency(3))
+
+	response, err := handler(context.Background(), readDynamoDBFixture(t))
+	require.NoError(t, err)
+	assert.Len(t, processed, 3)
+	assert.Equal(t, []vokerevents.BatchItemFailure{{ItemIdentifier: "222"}}, response.BatchItemFailures)
+}
+
+func TestDynamoDBStreamHandler_PanicStopsProcessing(t *testing.T) {
+	var processed []string
+	handler := DynamoDBStreamHandler(func(_ context.Context, change DynamoDBChange[testDynamoDBItem]) error {
+		processed = append(processed, change.EventName)
+		if change.EventName == vokerevents.DynamoDBEventInsert {
+			panic("bad change")
+		}
+		return nil
+	})
+
+	response, err := handler(context.Background(), readDynamoDBFixture(t))
+	require.NoError(t, err)
+	assert.Equal(t, []string{vokerevents.DynamoDBEventInsert}, processed)
+	assert.Equal(t, []vokerevents.BatchItemFailure{{ItemIdentifier: "111"}}, response.BatchItemFailures)
+}
+
+func TestDynamoDBStreamHandler_WithoutPartialBatchResponse(t *testing.T) {
+	handler := DynamoDBStreamHandler(func(_ context.Context, change DynamoDBChange[testDynamoDBItem]) error {
+		if change.EventName == vokerevents.DynamoDBEventModify {
+			return errors.New("failed")
+		}
+		return nil
+	}, WithoutPartialBatchResponse())
+
+	response, err := handler(context.Background(), readDynamoDBFixture(t))
+	batch, ok := errors.AsType[*BatchError](err)
+	require.True(t, ok)
+	assert.Equal(t, []BatchRecordError{{ItemIdentifier: "222", Err: errors.New("failed")}}, batch.Failures)
+	assert.Equal(t, 1, batch.Skipped)
+	assert.Equal(t, "1 of 3 records failed: 222: failed; 1 record not processed", err.Error())
+	assert.Empty(t, response.BatchItemFailures)
+}
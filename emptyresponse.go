@@ -0,0 +1,53 @@
+package voker
+
+import "reflect"
+
+// EmptyResponse selects what voker posts to Lambda when a handler's output
+// is empty: nil, a nil pointer, map, or slice, or any other zero value.
+type EmptyResponse int
+
+const (
+	// EmptyResponseEncode encodes empty outputs like any other, so a nil
+	// pointer becomes null and a zero struct becomes an object of zero-valued
+	// fields. It is the default.
+	EmptyResponseEncode EmptyResponse = iota
+
+	// EmptyResponseNull posts null.
+	EmptyResponseNull
+
+	// EmptyResponseObject posts {}.
+	EmptyResponseObject
+
+	// EmptyResponseNoBody posts an empty body.
+	EmptyResponseNoBody
+)
+
+// WithEmptyResponse sets what is posted when the handler's output is empty.
+// Step Functions, Lambda destinations, and other consumers tell null, {},
+// and an empty payload apart, so pick the one they expect:
+//
+//	voker.Start(handler, voker.WithEmptyResponse(voker.EmptyResponseObject))
+//
+// The policy applies to the output middleware returns, and not to streaming
+// responses or errors.
+func WithEmptyResponse(policy EmptyResponse) Option {
+	return func(o *options) {
+		o.emptyResponse = policy
+	}
+}
+
+// payload returns the response body for an empty output, or nil when the
+// output should be encoded.
+func (p EmptyResponse) payload(output any) []byte {
+	if p == EmptyResponseEncode || (output != nil && !reflect.ValueOf(output).IsZero()) {
+		return nil
+	}
+	switch p {
+	case EmptyResponseNull:
+		return []byte("null")
+	case EmptyResponseObject:
+		return []byte("{}")
+	default:
+		return []byte{}
+	}
+}
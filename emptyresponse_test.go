@@ -0,0 +1,69 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEmptyResponse(t *testing.T) {
+	var output *testResponse
+	handler := func(context.Context, json.RawMessage) (*testResponse, error) {
+		return output, nil
+	}
+
+	tests := []struct {
+		policy EmptyResponse
+		want   string
+	}{
+		{EmptyResponseEncode, "null"},
+		{EmptyResponseNull, "null"},
+		{EmptyResponseObject, "{}"},
+		{EmptyResponseNoBody, ""},
+	}
+	for _, tt := range tests {
+		options := &options{}
+		WithEmptyResponse(tt.policy)(options)
+
+		output = nil
+		response, err := callHandler(context.Background(), []byte(`{}`), handler, options)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, string(response.payload))
+
+		output = &testResponse{}
+		response, err = callHandler(context.Background(), []byte(`{}`), handler, options)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"message":""}`, string(response.payload))
+	}
+}
+
+func TestEmptyResponse_Payload(t *testing.T) {
+	tests := []struct {
+		name   string
+		output any
+		empty  bool
+	}{
+		{"nil", nil, true},
+		{"zero struct", testResponse{}, true},
+		{"struct", testResponse{Message: "hi"}, false},
+		{"nil map", map[string]string(nil), true},
+		{"empty map", map[string]string{}, false},
+		{"zero int", 0, true},
+		{"empty string", "", true},
+		{"false", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := EmptyResponseObject.payload(tt.output)
+			if tt.empty {
+				assert.Equal(t, "{}", string(payload))
+			} else {
+				assert.Nil(t, payload)
+			}
+			assert.Nil(t, EmptyResponseEncode.payload(tt.output))
+		})
+	}
+}
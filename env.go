@@ -0,0 +1,60 @@
+package voker
+
+import (
+	"maps"
+	"os"
+)
+
+// env looks up the environment variables the runtime is configured by, such
+// as AWS_LAMBDA_RUNTIME_API and AWS_LAMBDA_LOG_LEVEL. The process
+// environment is used unless [WithEnv] replaces it.
+type env interface {
+	Lookup(key string) (string, bool)
+}
+
+// osEnv reads the process environment.
+type osEnv struct{}
+
+func (osEnv) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// mapEnv reads a fixed set of variables. Variables missing from the map are
+// unset, regardless of the process environment.
+type mapEnv map[string]string
+
+func (e mapEnv) Lookup(key string) (string, bool) {
+	value, ok := e[key]
+	return value, ok
+}
+
+// getenv returns the value of key in e, or the process environment when e is
+// nil.
+func getenv(e env, key string) string {
+	if e == nil {
+		e = osEnv{}
+	}
+	value, _ := e.Lookup(key)
+	return value
+}
+
+// WithEnv replaces the process environment as the source of the variables
+// the runtime reads, including AWS_LAMBDA_RUNTIME_API, the log format and
+// level, AWS_LAMBDA_MAX_CONCURRENCY, AWS_LAMBDA_INITIALIZATION_TYPE, and the
+// variable [StartRegistered] selects a handler by. Variables missing from
+// vars are treated as unset. Calling WithEnv more than once merges the maps,
+// with later values taking precedence.
+//
+// It lets tests run runtimes side by side, and programs that embed the
+// runtime configure it, without mutating the process environment with
+// os.Setenv. Variables read by handler code are unaffected.
+func WithEnv(vars map[string]string) Option {
+	return func(o *options) {
+		merged := mapEnv{}
+		if existing, ok := o.env.(mapEnv); ok {
+			maps.Copy(merged, existing)
+		}
+		maps.Copy(merged, vars)
+		o.env = merged
+	}
+}
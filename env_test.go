@@ -0,0 +1,136 @@
+package voker
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetenv(t *testing.T) {
+	t.Setenv("VOKER_TEST_ENV", "process")
+
+	assert.Equal(t, "process", getenv(nil, "VOKER_TEST_ENV"))
+	assert.Equal(t, "process", getenv(osEnv{}, "VOKER_TEST_ENV"))
+	assert.Empty(t, getenv(mapEnv{}, "VOKER_TEST_ENV"), "a map env must not fall back to the process environment")
+	assert.Equal(t, "map", getenv(mapEnv{"VOKER_TEST_ENV": "map"}, "VOKER_TEST_ENV"))
+}
+
+func TestWithEnv_Merges(t *testing.T) {
+	source := map[string]string{"A": "1", "B": "2"}
+	var o options
+	WithEnv(source)(&o)
+	WithEnv(map[string]string{"B": "3"})(&o)
+	source["A"] = "changed"
+
+	assert.Equal(t, "1", getenv(o.env, "A"), "the map must be copied")
+	assert.Equal(t, "3", getenv(o.env, "B"))
+}
+
+func TestWithEnv_InitType(t *testing.T) {
+	t.Parallel()
+
+	var o options
+	WithEnv(map[string]string{lambdaEnvInitializationType: string(InitManagedInstances)})(&o)
+	o.extensions = []InternalExtension{{Name: "unsupported"}}
+
+	var response *ErrorResponse
+	require.ErrorAs(t, validateRuntimeConfiguration(&o), &response)
+	assert.Equal(t, "Runtime.UnsupportedExtension", response.Type)
+}
+
+func TestDefaultLogger_Env(t *testing.T) {
+	t.Parallel()
+
+	logger := defaultLogger(mapEnv{lambdaEnvLogLevel: "error", lambdaEnvLogFormat: "JSON"})
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelWarn))
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelError))
+	_, isJSON := logger.Handler().(*slog.JSONHandler)
+	assert.True(t, isJSON)
+}
+
+func TestRun_WithEnv(t *testing.T) {
+	t.Parallel()
+
+	var responses []string
+	served := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			if served {
+				<-r.Context().Done()
+				return
+			}
+			served = true
+			w.Header().Set(headerRequestID, "test-request-id")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			_, _ = w.Write([]byte(`{"name":"world"}`))
+		case "/2018-06-01/runtime/invocation/test-request-id/error":
+			body, _ := io.ReadAll(r.Body)
+			responses = append(responses, string(body))
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		cancel()
+		return testResponse{}, assert.AnError
+	}
+
+	var logs bytes.Buffer
+	err := Run(ctx, handler,
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		WithEnv(map[string]string{
+			"AWS_LAMBDA_RUNTIME_API":      server.URL[7:],
+			"AWS_LAMBDA_FUNCTION_NAME":    "env-function",
+			"AWS_LAMBDA_FUNCTION_VERSION": "7",
+		}),
+	)
+	require.NoError(t, err)
+	require.Len(t, responses, 1)
+	assert.Contains(t, logs.String(), "record.functionName=env-function")
+	assert.Contains(t, logs.String(), "record.functionVersion=7")
+}
+
+func TestRun_WithEnvMissingRuntimeAPI(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", "127.0.0.1:9001")
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		return testResponse{}, nil
+	}
+
+	err := Run(context.Background(), handler,
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		WithEnv(map[string]string{}),
+	)
+	assert.ErrorIs(t, err, errMissingRuntimeAPI)
+}
+
+func TestRun_WithEnvMaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idleConns := make(chan int, 4)
+	err := run(ctx, func(ctx context.Context, client *runtimeClient, options *options) error {
+		idleConns <- client.httpClient.Transport.(*http.Transport).MaxIdleConnsPerHost
+		cancel()
+		return ctx.Err()
+	},
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithEnv(map[string]string{
+			"AWS_LAMBDA_RUNTIME_API": "127.0.0.1:9001",
+			lambdaEnvMaxConcurrency:  "4",
+		}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 4, <-idleConns)
+}
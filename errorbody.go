@@ -0,0 +1,188 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// Handlers that give up at their deadline commonly return these sentinels
+// unwrapped. Their payloads never vary, so they are encoded once.
+var (
+	deadlineExceededError = newStaticError(context.DeadlineExceeded)
+	canceledError         = newStaticError(context.Canceled)
+)
+
+// The same sentinels returned when the invocation's own deadline passes or
+// SIGTERM cancels it carry the context details of newHandlerErrorResponse,
+// which never vary either.
+var (
+	deadlineReachedError = newStaticContextError(context.DeadlineExceeded, contextTriggerDeadline,
+		context.DeadlineExceeded, "Lambda invocation deadline reached")
+	sigtermCanceledError = newStaticContextError(context.Canceled, contextTriggerSIGTERM,
+		ErrSIGTERM, "invocation canceled by SIGTERM: "+ErrSIGTERM.Error())
+)
+
+// staticError is an error response whose payload is encoded up front.
+type staticError struct {
+	response *ErrorResponse
+	body     []byte
+}
+
+func newStaticError(err error) staticError {
+	response := &ErrorResponse{Message: err.Error(), Type: getErrorType(err)}
+	body, err := json.Marshal(response)
+	if err != nil {
+		panic(err)
+	}
+	return staticError{response: response, body: body}
+}
+
+func newStaticContextError(err error, trigger string, cause error, reason string) staticError {
+	response := newContextErrorResponse(err, trigger, cause, reason)
+	body, err := json.Marshal(response)
+	if err != nil {
+		panic(err)
+	}
+	return staticError{response: response, body: body}
+}
+
+// errorBodyPrefixes are the pre-encoded openings of the payloads of the
+// error types voker reports itself, up to the start of the message string.
+var errorBodyPrefixes = map[string]string{
+	"HandlerError":           `{"errorType":"HandlerError","errorMessage":"`,
+	"Runtime.UnmarshalError": `{"errorType":"Runtime.UnmarshalError","errorMessage":"`,
+	"Runtime.MarshalError":   `{"errorType":"Runtime.MarshalError","errorMessage":"`,
+}
+
+// staticErrorResponse returns the shared response for err if its payload is
+// pre-encoded.
+func staticErrorResponse(err error) (*ErrorResponse, bool) {
+	switch err {
+	case context.DeadlineExceeded:
+		return deadlineExceededError.response, true
+	case context.Canceled:
+		return canceledError.response, true
+	}
+	return nil, false
+}
+
+// staticContextErrorResponse returns the shared response for err if it is the
+// unwrapped error of an invocation context ended by ctxErr with cause, and
+// its payload is pre-encoded.
+func staticContextErrorResponse(err, ctxErr, cause error) (*ErrorResponse, bool) {
+	switch {
+	case err == context.DeadlineExceeded && ctxErr == context.DeadlineExceeded && cause == context.DeadlineExceeded:
+		return deadlineReachedError.response, true
+	case err == context.Canceled && cause == ErrSIGTERM:
+		return sigtermCanceledError.response, true
+	}
+	return nil, false
+}
+
+// encodeErrorResponse encodes errResp as a Runtime API error payload. The
+// caller must release the returned buffer, which may be nil, once the payload
+// is sent.
+//
+// Responses without a stack trace are encoded by hand rather than with
+// encoding/json so that rejecting a request, the hot path for validators and
+// authorizers, doesn't pay for reflection. The output decodes to the same
+// value as json.Marshal's encoding of the ErrorResponse.
+func encodeErrorResponse(errResp *ErrorResponse) ([]byte, *jsonBuffer, error) {
+	switch errResp {
+	case deadlineExceededError.response:
+		return deadlineExceededError.body, nil, nil
+	case canceledError.response:
+		return canceledError.body, nil, nil
+	case deadlineReachedError.response:
+		return deadlineReachedError.body, nil, nil
+	case sigtermCanceledError.response:
+		return sigtermCanceledError.body, nil, nil
+	}
+
+	if len(errResp.StackTrace) > 0 || len(errResp.Details) > 0 {
+		// Only panics carry stack traces, and only deliberately structured
+		// errors carry details; both are rare enough that the reflective
+		// encoder is fine.
+		buf, err := marshalJSON(errResp, JSONEncoderOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return buf.Bytes(), buf, nil
+	}
+
+	buf := jsonBufferPool.Get().(*jsonBuffer)
+	buf.Reset()
+	dst := buf.AvailableBuffer()
+	if prefix, ok := errorBodyPrefixes[errResp.Type]; ok {
+		dst = append(dst, prefix...)
+	} else {
+		dst = append(dst, `{"errorType":`...)
+		dst = appendJSONString(dst, errResp.Type)
+		dst = append(dst, `,"errorMessage":"`...)
+	}
+	dst = appendJSONStringContents(dst, errResp.Message)
+	dst = append(dst, `"}`...)
+	buf.Write(dst)
+	return buf.Bytes(), buf, nil
+}
+
+func appendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	dst = appendJSONStringContents(dst, s)
+	return append(dst, '"')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONStringContents escapes s the way encoding/json does by default,
+// including HTML escaping and the replacement of invalid UTF-8.
+func appendJSONStringContents(dst []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= ' ' && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but end lines in JavaScript.
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	return append(dst, s[start:]...)
+}
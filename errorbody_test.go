@@ -0,0 +1,135 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeErrorResponse_MatchesEncodingJSON(t *testing.T) {
+	responses := []*ErrorResponse{
+		{Type: "HandlerError", Message: "name is required"},
+		{Type: "Runtime.UnmarshalError", Message: `failed to unmarshal input: invalid character '}' looking for "value"`},
+		{Type: "Runtime.MarshalError", Message: "failed to marshal output: json: unsupported type: chan int"},
+		{Type: "Application.ValidationError", Message: "<script>alert(1)</script> & more"},
+		{Type: "Custom\"Type\\", Message: "control \x01\x1f\b\f\n\r\t"},
+		{Type: "HandlerError", Message: "invalid \xff\xfe utf8, truncated \xc3"},
+		{Type: "HandlerError", Message: "line seps    , unicode ☃ héllo"},
+		{Type: "HandlerError", Message: ""},
+		{Type: "", Message: ""},
+	}
+
+	for _, response := range responses {
+		t.Run(response.Type+"/"+response.Message, func(t *testing.T) {
+			want, err := json.Marshal(response)
+			require.NoError(t, err)
+
+			got, buf, err := encodeErrorResponse(response)
+			require.NoError(t, err)
+			defer buf.release()
+			assert.JSONEq(t, string(want), string(got))
+		})
+	}
+}
+
+func TestEncodeErrorResponse_StackTrace(t *testing.T) {
+	response := &ErrorResponse{
+		Type:       "Runtime.Panic",
+		Message:    "boom",
+		StackTrace: []StackFrame{{Path: "main.go", Line: 12, Label: "handler"}},
+	}
+	want, err := json.Marshal(response)
+	require.NoError(t, err)
+
+	got, buf, err := encodeErrorResponse(response)
+	require.NoError(t, err)
+	defer buf.release()
+	assert.JSONEq(t, string(want), string(got))
+}
+
+func TestEncodeErrorResponse_StaticErrors(t *testing.T) {
+	for _, err := range []error{context.DeadlineExceeded, context.Canceled} {
+		t.Run(err.Error(), func(t *testing.T) {
+			response := newErrorResponse(err)
+			assert.Same(t, response, newErrorResponse(err))
+			assert.Equal(t, err.Error(), response.Message)
+			assert.Equal(t, getErrorType(err), response.Type)
+
+			want, marshalErr := json.Marshal(response)
+			require.NoError(t, marshalErr)
+			got, buf, encodeErr := encodeErrorResponse(response)
+			require.NoError(t, encodeErr)
+			assert.Nil(t, buf)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+
+	wrapped := newErrorResponse(fmt.Errorf("load: %w", context.DeadlineExceeded))
+	assert.NotSame(t, deadlineExceededError.response, wrapped)
+	assert.Equal(t, "load: context deadline exceeded", wrapped.Message)
+}
+
+func TestEncodeErrorResponse_StaticContextErrors(t *testing.T) {
+	for _, static := range []staticError{deadlineReachedError, sigtermCanceledError} {
+		t.Run(static.response.Message, func(t *testing.T) {
+			want, err := json.Marshal(static.response)
+			require.NoError(t, err)
+			got, buf, err := encodeErrorResponse(static.response)
+			require.NoError(t, err)
+			assert.Nil(t, buf)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func FuzzAppendJSONStringContents(f *testing.F) {
+	seeds := []string{
+		"",
+		"plain",
+		`quote " and \ slash`,
+		"control \x01\x1f \b\f\n\r\t",
+		"unicode ☃ héllo",
+		"invalid \xff\xfe utf8",
+		"truncated \xc3",
+		"line seps    ",
+		"<html>&stuff",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		b := appendJSONString(nil, s)
+
+		var got string
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("appendJSONString produced invalid JSON for %q: %v (output: %s)", s, err, b)
+		}
+
+		wantJSON, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("encoding/json rejected %q: %v", s, err)
+		}
+		var want string
+		if err := json.Unmarshal(wantJSON, &want); err != nil {
+			t.Fatalf("decode encoding/json output: %v", err)
+		}
+
+		if got != want {
+			t.Fatalf("appendJSONString(%q) decodes to %q; encoding/json decodes to %q", s, got, want)
+		}
+	})
+}
+
+func TestSendError_HandlerErrorPayload(t *testing.T) {
+	err := errors.New(`bad "input"`)
+	body, buf, encodeErr := encodeErrorResponse(newErrorResponse(err))
+	require.NoError(t, encodeErr)
+	defer buf.release()
+	assert.JSONEq(t, `{"errorType":"HandlerError","errorMessage":"bad \"input\""}`, string(body))
+}
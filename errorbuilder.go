@@ -0,0 +1,73 @@
+package voker
+
+import "fmt"
+
+// ErrorBuilder builds an [*ErrorResponse] fluently. It is itself an error
+// that reports as the response it builds, so handlers can return it
+// directly:
+//
+//	return Order{}, voker.NewError("Orders.OutOfStock").
+//	    Messagef("%d of %s left", available, sku).
+//	    WithDetail("sku", sku).
+//	    WithDetail("requested", quantity)
+//
+// Details are reported in the error payload's errorDetails, which
+// invocation destinations and DLQ consumers receive with the error.
+type ErrorBuilder struct {
+	response *ErrorResponse
+}
+
+// NewError returns a builder for an error of errorType. An empty errorType
+// reports HandlerError.
+func NewError(errorType string) *ErrorBuilder {
+	if errorType == "" {
+		errorType = "HandlerError"
+	}
+	return &ErrorBuilder{response: &ErrorResponse{Type: errorType}}
+}
+
+// Message sets the error message.
+func (b *ErrorBuilder) Message(message string) *ErrorBuilder {
+	b.response.Message = message
+	return b
+}
+
+// Messagef sets the error message from a format string.
+func (b *ErrorBuilder) Messagef(format string, args ...any) *ErrorBuilder {
+	b.response.Message = fmt.Sprintf(format, args...)
+	return b
+}
+
+// WithDetail sets a detail. value must marshal to JSON.
+func (b *ErrorBuilder) WithDetail(key string, value any) *ErrorBuilder {
+	if b.response.Details == nil {
+		b.response.Details = make(map[string]any)
+	}
+	b.response.Details[key] = value
+	return b
+}
+
+// WithStack records the stack of WithStack's caller as the error's
+// stackTrace. Unlike a panic's, the error doesn't end the execution
+// environment.
+func (b *ErrorBuilder) WithStack() *ErrorBuilder {
+	// Skip runtime.Callers, stackTrace, and WithStack.
+	b.response.StackTrace = stackTrace(3, false)
+	return b
+}
+
+// Response returns the built response.
+func (b *ErrorBuilder) Response() *ErrorResponse {
+	return b.response
+}
+
+// Error returns the error message.
+func (b *ErrorBuilder) Error() string {
+	return b.response.Message
+}
+
+// Unwrap returns the built response, which is what voker reports when the
+// builder is returned as an error.
+func (b *ErrorBuilder) Unwrap() error {
+	return b.response
+}
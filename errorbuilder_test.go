@@ -0,0 +1,64 @@
+package voker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewError(t *testing.T) {
+	err := NewError("Orders.OutOfStock").
+		Messagef("%d of %s left", 2, "sku-1").
+		WithDetail("sku", "sku-1").
+		WithDetail("requested", 5)
+
+	assert.Equal(t, "2 of sku-1 left", err.Error())
+	assert.Equal(t, &ErrorResponse{
+		Type:    "Orders.OutOfStock",
+		Message: "2 of sku-1 left",
+		Details: map[string]any{"sku": "sku-1", "requested": 5},
+	}, err.Response())
+
+	response := newErrorResponse(err)
+	assert.Same(t, err.Response(), response)
+
+	body, buf, encodeErr := encodeErrorResponse(response)
+	require.NoError(t, encodeErr)
+	defer buf.release()
+	assert.JSONEq(t, `{
+		"errorType": "Orders.OutOfStock",
+		"errorMessage": "2 of sku-1 left",
+		"errorDetails": {"sku": "sku-1", "requested": 5}
+	}`, string(body))
+}
+
+func TestNewError_Defaults(t *testing.T) {
+	response := NewError("").Message("100% failed").Response()
+	assert.Equal(t, "HandlerError", response.Type)
+	assert.Equal(t, "100% failed", response.Message)
+	assert.Nil(t, response.Details)
+	assert.Empty(t, response.StackTrace)
+}
+
+func TestNewError_WithStack(t *testing.T) {
+	response := NewError("Orders.Invalid").WithStack().Response()
+
+	require.NotEmpty(t, response.StackTrace)
+	assert.Equal(t, "TestNewError_WithStack", response.StackTrace[0].Label)
+	assert.False(t, response.fatal)
+}
+
+func TestNewError_ReturnedFromHandler(t *testing.T) {
+	handler := func(context.Context, struct{}) (struct{}, error) {
+		return struct{}{}, NewError("Orders.Rejected").Message("rejected").WithDetail("reason", "fraud")
+	}
+
+	_, err := callHandler(context.Background(), []byte(`{}`), handler, nil)
+	response, ok := errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "Orders.Rejected", response.Type)
+	assert.Equal(t, map[string]any{"reason": "fraud"}, response.Details)
+}
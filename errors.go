@@ -1,12 +1,15 @@
 package voker
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 )
 
 // ErrorResponse represents a Lambda function error response
@@ -14,7 +17,13 @@ type ErrorResponse struct {
 	Type       string       `json:"errorType"`
 	Message    string       `json:"errorMessage"`
 	StackTrace []StackFrame `json:"stackTrace,omitempty"`
-	fatal      bool
+
+	// Details holds structured data about the error, such as the failed
+	// records of a [BatchError]. It is reported as errorDetails, which
+	// invocation destinations and DLQ consumers receive with the error.
+	Details map[string]any `json:"errorDetails,omitempty"`
+
+	fatal bool
 }
 
 // Error implements the error interface for ErrorResponse
@@ -41,6 +50,10 @@ func (e *ErrorResponse) LogValue() slog.Value {
 		attrs = append(attrs, slog.Any("stackTrace", frameValues))
 	}
 
+	if len(e.Details) > 0 {
+		attrs = append(attrs, slog.Any("errorDetails", e.Details))
+	}
+
 	return slog.GroupValue(attrs...)
 }
 
@@ -51,13 +64,58 @@ type StackFrame struct {
 	Label string `json:"label"`
 }
 
+// WithFullStackPaths keeps the complete file path and the fully qualified
+// function name, such as "github.com/acme/orders/internal/store.(*DB).Save",
+// in every [StackFrame] of a panic's stack trace. By default paths are
+// trimmed to the end of the package's import path and labels to the type
+// and function, which reads well in logs but breaks IDE click-through and
+// symbolication in error trackers. The setting covers every panic the
+// runtime recovers, including those in batch handlers, background tasks,
+// streaming responses, and hooks. Stacks recorded by [ErrorBuilder.WithStack]
+// are captured outside the runtime and keep the shortened form.
+func WithFullStackPaths() Option {
+	return func(o *options) {
+		o.fullStackPaths = true
+	}
+}
+
+type fullStackPathsKey struct{}
+
+// withFullStackPaths returns a copy of ctx whose invocation reports complete
+// stack frame paths.
+func withFullStackPaths(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fullStackPathsKey{}, true)
+}
+
+// fullStackPathsFromContext reports whether [WithFullStackPaths] applies to
+// the invocation of ctx.
+func fullStackPathsFromContext(ctx context.Context) bool {
+	full, _ := ctx.Value(fullStackPathsKey{}).(bool)
+	return full
+}
+
 // newErrorResponse creates an ErrorResponse from a regular error. A wrapped
 // *ErrorResponse anywhere in the chain is preserved verbatim so its Type,
-// StackTrace, and fatality survive fmt.Errorf("...: %w", err) wrapping.
+// StackTrace, and fatality survive fmt.Errorf("...: %w", err) wrapping. A
+// wrapped *BatchError reports its failed records as details. Unwrapped
+// context.DeadlineExceeded and context.Canceled share responses whose
+// payloads are encoded once.
 func newErrorResponse(err error) *ErrorResponse {
+	// A BatchError unwraps to its records' errors, which may themselves be
+	// *ErrorResponse values, so it is matched first.
+	if batch, ok := errors.AsType[*BatchError](err); ok {
+		return &ErrorResponse{
+			Message: err.Error(),
+			Type:    getErrorType(batch),
+			Details: batch.details(),
+		}
+	}
 	if typed, ok := errors.AsType[*ErrorResponse](err); ok {
 		return typed
 	}
+	if static, ok := staticErrorResponse(err); ok {
+		return static
+	}
 
 	return &ErrorResponse{
 		Message: err.Error(),
@@ -65,21 +123,108 @@ func newErrorResponse(err error) *ErrorResponse {
 	}
 }
 
+// Context triggers reported in the contextTrigger detail of handler errors
+// caused by the invocation context ending.
+const (
+	contextTriggerDeadline = "deadline"
+	contextTriggerSIGTERM  = "sigterm"
+	contextTriggerCanceled = "canceled"
+)
+
+// newHandlerErrorResponse creates the ErrorResponse for an error returned by
+// the handler of the invocation whose context is ctx. When the error is the
+// context's own context.DeadlineExceeded or context.Canceled, the bare
+// "context deadline exceeded" says nothing about why, so the response names
+// what ended the context, the Lambda deadline or SIGTERM, and reports the
+// trigger and context.Cause as contextTrigger and contextCause details.
+func newHandlerErrorResponse(ctx context.Context, err error) *ErrorResponse {
+	if _, ok := errors.AsType[*ErrorResponse](err); ok {
+		return newErrorResponse(err)
+	}
+	if ctx.Err() == nil || !(errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)) {
+		return newErrorResponse(err)
+	}
+
+	cause := context.Cause(ctx)
+	if static, ok := staticContextErrorResponse(err, ctx.Err(), cause); ok {
+		return static
+	}
+
+	trigger, reason := contextTriggerCanceled, "invocation context canceled: "+cause.Error()
+	switch {
+	case errors.Is(cause, ErrSIGTERM):
+		trigger, reason = contextTriggerSIGTERM, "invocation canceled by SIGTERM: "+cause.Error()
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) && errors.Is(cause, context.DeadlineExceeded):
+		trigger, reason = contextTriggerDeadline, "Lambda invocation deadline reached"
+	}
+	return newContextErrorResponse(err, trigger, cause, reason)
+}
+
+// newContextErrorResponse creates the ErrorResponse for err, returned because
+// the invocation context ended, with trigger and cause as details.
+func newContextErrorResponse(err error, trigger string, cause error, reason string) *ErrorResponse {
+	return &ErrorResponse{
+		Message: fmt.Sprintf("%v (%s)", err, reason),
+		Type:    getErrorType(err),
+		Details: map[string]any{
+			"contextTrigger": trigger,
+			"contextCause":   cause.Error(),
+		},
+	}
+}
+
 // getErrorType returns the errorType reported for a handler error: the Go
 // type name of the error. Errors without a useful name — anonymous types and
 // the generic types produced by errors.New, fmt.Errorf, and errors.Join —
 // report the stable name HandlerError instead. Handlers that need a specific
 // errorType should return a *ErrorResponse.
+//
+// Classification runs for every failed invocation, so voker's own error
+// types and the context sentinels are matched directly, the generic types
+// by identity, and other names are cached by type, leaving reflection to the
+// first error of each type.
 func getErrorType(err error) string {
-	if err == nil {
+	switch err {
+	case nil, context.Canceled:
 		return "HandlerError"
+	case context.DeadlineExceeded:
+		return deadlineExceededType
+	}
+	switch err.(type) {
+	case *ErrorResponse:
+		return "ErrorResponse"
+	case *BatchError:
+		return "BatchError"
 	}
 
 	t := reflect.TypeOf(err)
-	if t == nil {
+	if slices.Contains(genericErrorTypes, t) {
 		return "HandlerError"
 	}
+	if name, ok := errorTypeNames.Load(t); ok {
+		return name.(string)
+	}
+	name := errorTypeName(t)
+	errorTypeNames.Store(t, name)
+	return name
+}
+
+// genericErrorTypes are the types of the errors that errors.New,
+// fmt.Errorf, and errors.Join return most often.
+var genericErrorTypes = []reflect.Type{
+	reflect.TypeOf(errors.New("")),
+	reflect.TypeOf(fmt.Errorf("%w", errors.New(""))),
+	reflect.TypeOf(errors.Join(errors.New(""), errors.New(""))),
+}
+
+// deadlineExceededType is the name of context.DeadlineExceeded's unexported
+// type.
+var deadlineExceededType = errorTypeName(reflect.TypeOf(context.DeadlineExceeded))
+
+// errorTypeNames caches errorTypeName by error type.
+var errorTypeNames sync.Map
 
+func errorTypeName(t reflect.Type) string {
 	if t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
@@ -98,15 +243,16 @@ func getErrorType(err error) string {
 	return "HandlerError"
 }
 
-// newPanicResponse creates an ErrorResponse from a panic
-func newPanicResponse(panicValue any) *ErrorResponse {
+// newPanicResponse creates an ErrorResponse from a panic. fullPaths keeps
+// complete stack frame paths, as set by WithFullStackPaths.
+func newPanicResponse(panicValue any, fullPaths bool) *ErrorResponse {
 	message := fmt.Sprintf("%v", panicValue)
 	errorType := getPanicType(panicValue)
 
 	return &ErrorResponse{
 		Message:    message,
 		Type:       errorType,
-		StackTrace: captureStackTrace(),
+		StackTrace: captureStackTrace(fullPaths),
 		fatal:      true,
 	}
 }
@@ -142,12 +288,18 @@ func getPanicType(panicValue any) string {
 }
 
 // captureStackTrace captures the current stack trace, skipping voker internal frames
-func captureStackTrace() []StackFrame {
-	const maxFrames = 32
+func captureStackTrace(fullPaths bool) []StackFrame {
 	const framesToSkip = 4 // captureStackTrace -> newPanicResponse -> recover -> handler
+	return stackTrace(framesToSkip+1, fullPaths)
+}
+
+// stackTrace captures the stack above the frames skipped as by
+// runtime.Callers, counting stackTrace's own frame.
+func stackTrace(skip int, fullPaths bool) []StackFrame {
+	const maxFrames = 32
 
 	pcs := make([]uintptr, maxFrames)
-	n := runtime.Callers(framesToSkip, pcs)
+	n := runtime.Callers(skip, pcs)
 	if n == 0 {
 		return []StackFrame{}
 	}
@@ -157,7 +309,7 @@ func captureStackTrace() []StackFrame {
 
 	for {
 		frame, more := frames.Next()
-		stackFrames = append(stackFrames, formatFrame(frame))
+		stackFrames = append(stackFrames, formatFrame(frame, fullPaths))
 		if !more {
 			break
 		}
@@ -166,10 +318,14 @@ func captureStackTrace() []StackFrame {
 	return stackFrames
 }
 
-// formatFrame converts a runtime.Frame to a StackFrame
-func formatFrame(frame runtime.Frame) StackFrame {
+// formatFrame converts a runtime.Frame to a StackFrame, trimming its path and
+// label unless fullPaths is set.
+func formatFrame(frame runtime.Frame, fullPaths bool) StackFrame {
 	path := frame.File
 	label := frame.Function
+	if fullPaths {
+		return StackFrame{Path: path, Line: frame.Line, Label: label}
+	}
 
 	// Strip GOPATH/module path from file path
 	// Count slashes in function name to determine how many path components to keep
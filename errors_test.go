@@ -1,11 +1,22 @@
 package voker
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewErrorResponse(t *testing.T) {
@@ -79,12 +90,24 @@ func TestGetErrorType(t *testing.T) {
 		{"fmt.Errorf", fmt.Errorf("boom"), "HandlerError"},
 		{"fmt.Errorf wrapping", fmt.Errorf("wrapped: %w", errors.New("boom")), "HandlerError"},
 		{"errors.Join", errors.Join(errors.New("a"), errors.New("b")), "HandlerError"},
+		{"fmt.Errorf wrapping twice", fmt.Errorf("%w: %w", errors.New("a"), errors.New("b")), "HandlerError"},
+		{"anonymous type", &struct{ customError }{}, "HandlerError"},
 		{"named value type", customError{msg: "boom"}, "customError"},
 		{"named pointer type", &customPointerError{msg: "boom"}, "customPointerError"},
+		{"context.Canceled", context.Canceled, "HandlerError"},
+		{"context.DeadlineExceeded", context.DeadlineExceeded, "deadlineExceededError"},
+		{"ErrorResponse", &ErrorResponse{Type: "Orders.Timeout"}, "ErrorResponse"},
+		{"BatchError", &BatchError{}, "BatchError"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			if tt.err != nil {
+				// Direct matches agree with the reflective classification.
+				assert.Equal(t, tt.want, errorTypeName(reflect.TypeOf(tt.err)))
+			}
+			assert.Equal(t, tt.want, getErrorType(tt.err))
+			// The second call is answered from the cache.
 			assert.Equal(t, tt.want, getErrorType(tt.err))
 		})
 	}
@@ -92,7 +115,7 @@ func TestGetErrorType(t *testing.T) {
 
 func TestNewPanicResponse(t *testing.T) {
 	panicValue := "panic message"
-	errResp := newPanicResponse(panicValue)
+	errResp := newPanicResponse(panicValue, false)
 
 	assert.Equal(t, "panic message", errResp.Message)
 	assert.Equal(t, "Runtime.Panic.string", errResp.Type)
@@ -108,7 +131,7 @@ func TestNewPanicResponse(t *testing.T) {
 
 func TestNewPanicResponse_CustomType(t *testing.T) {
 	panicValue := customError{msg: "panic error"}
-	errResp := newPanicResponse(panicValue)
+	errResp := newPanicResponse(panicValue, false)
 
 	assert.Equal(t, "panic error", errResp.Message)
 	assert.Equal(t, "Runtime.Panic.customError", errResp.Type)
@@ -139,7 +162,7 @@ func TestGetPanicType(t *testing.T) {
 }
 
 func TestCaptureStackTrace(t *testing.T) {
-	frames := captureStackTrace()
+	frames := captureStackTrace(false)
 	assert.NotEmpty(t, frames)
 
 	// Should have at least one frame
@@ -152,3 +175,168 @@ func TestCaptureStackTrace(t *testing.T) {
 		assert.NotEmpty(t, frame.Label)
 	}
 }
+
+func TestFormatFrame(t *testing.T) {
+	frame := runtime.Frame{
+		File:     "/home/user/go/pkg/mod/github.com/acme/orders/internal/store/db.go",
+		Line:     42,
+		Function: "github.com/acme/orders/internal/store.(*DB).Save",
+	}
+	assert.Equal(t, StackFrame{Path: "acme/orders/internal/store/db.go", Line: 42, Label: "(*DB).Save"}, formatFrame(frame, false))
+	assert.Equal(t, StackFrame{Path: frame.File, Line: 42, Label: frame.Function}, formatFrame(frame, true))
+}
+
+func TestWithFullStackPaths(t *testing.T) {
+	opts := &options{}
+	WithFullStackPaths()(opts)
+	assert.True(t, opts.fullStackPaths)
+
+	handler := func(context.Context, struct{}) (struct{}, error) {
+		panic("boom")
+	}
+	labels := func(options *options) []string {
+		_, err := callHandler(context.Background(), []byte(`{}`), handler, options)
+		response, ok := errors.AsType[*ErrorResponse](err)
+		require.True(t, ok)
+		var labels []string
+		for _, frame := range response.StackTrace {
+			labels = append(labels, frame.Label)
+		}
+		return labels
+	}
+
+	// The option is per runtime: a second one without it keeps short frames.
+	assert.Contains(t, strings.Join(labels(opts), "\n"), "github.com/hotsock/voker.TestWithFullStackPaths")
+	assert.NotContains(t, strings.Join(labels(&options{}), "\n"), "github.com/hotsock/voker.")
+}
+
+func TestFullStackPathsFromContext(t *testing.T) {
+	assert.False(t, fullStackPathsFromContext(context.Background()))
+	assert.True(t, fullStackPathsFromContext(withFullStackPaths(context.Background())))
+}
+
+func TestNewHandlerErrorResponse_Deadline(t *testing.T) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	response := newHandlerErrorResponse(ctx, fmt.Errorf("query orders: %w", ctx.Err()))
+	assert.Equal(t, "HandlerError", response.Type)
+	assert.Equal(t, "query orders: context deadline exceeded (Lambda invocation deadline reached)", response.Message)
+	assert.Equal(t, map[string]any{"contextTrigger": "deadline", "contextCause": "context deadline exceeded"}, response.Details)
+}
+
+func TestNewHandlerErrorResponse_SIGTERM(t *testing.T) {
+	parent, stop := context.WithCancelCause(context.Background())
+	ctx, cancel := context.WithDeadline(parent, time.Now().Add(time.Hour))
+	defer cancel()
+	stop(ErrSIGTERM)
+
+	response := newHandlerErrorResponse(ctx, ctx.Err())
+	assert.Equal(t, "context canceled (invocation canceled by SIGTERM: execution environment is shutting down)", response.Message)
+	assert.Equal(t, map[string]any{"contextTrigger": "sigterm", "contextCause": ErrSIGTERM.Error()}, response.Details)
+}
+
+func TestNewHandlerErrorResponse_OtherCause(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("upstream gone"))
+
+	response := newHandlerErrorResponse(ctx, context.Canceled)
+	assert.Equal(t, "context canceled (invocation context canceled: upstream gone)", response.Message)
+	assert.Equal(t, map[string]any{"contextTrigger": "canceled", "contextCause": "upstream gone"}, response.Details)
+}
+
+func TestNewHandlerErrorResponse_Unchanged(t *testing.T) {
+	live := context.Background()
+	assert.Same(t, newErrorResponse(context.DeadlineExceeded), newHandlerErrorResponse(live, context.DeadlineExceeded))
+
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+	plain := newHandlerErrorResponse(done, errors.New("validation failed"))
+	assert.Equal(t, "validation failed", plain.Message)
+	assert.Nil(t, plain.Details)
+
+	typed := &ErrorResponse{Type: "Orders.Timeout", Message: "gave up"}
+	assert.Same(t, typed, newHandlerErrorResponse(done, fmt.Errorf("%w: %w", typed, context.Canceled)))
+}
+
+func TestCallHandler_ReportsDeadlineCause(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	handler := func(ctx context.Context, _ struct{}) (struct{}, error) {
+		<-ctx.Done()
+		return struct{}{}, ctx.Err()
+	}
+
+	for _, options := range []*options{nil, {middleware: []Middleware{func(next InvokeFunc) InvokeFunc { return next }}}} {
+		_, err := callHandler(ctx, []byte(`{}`), handler, options)
+		response, ok := errors.AsType[*ErrorResponse](err)
+		require.True(t, ok)
+		assert.Equal(t, "deadline", response.Details["contextTrigger"])
+	}
+}
+
+func TestHandleInvocation_TimeoutSendsStaticBody(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			deadline := time.Now().Add(20 * time.Millisecond).UnixMilli()
+			w.Header().Set(headerRequestID, "test-request-id")
+			w.Header().Set(headerDeadlineMS, strconv.FormatInt(deadline, 10))
+			w.Write([]byte(`{}`))
+		case "/2018-06-01/runtime/invocation/test-request-id/error":
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.DiscardHandler)
+	client := newRuntimeClient(server.URL[7:], logger)
+	handler := func(ctx context.Context, _ struct{}) (struct{}, error) {
+		<-ctx.Done()
+		return struct{}{}, ctx.Err()
+	}
+
+	require.NoError(t, handleInvocation(client, handler, &options{logger: logger}))
+	assert.Equal(t, string(deadlineReachedError.body), string(body))
+	assert.JSONEq(t, `{
+		"errorType": "deadlineExceededError",
+		"errorMessage": "context deadline exceeded (Lambda invocation deadline reached)",
+		"errorDetails": {"contextTrigger": "deadline", "contextCause": "context deadline exceeded"}
+	}`, string(body))
+}
+
+func TestNewHandlerErrorResponse_Static(t *testing.T) {
+	deadline, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	assert.Same(t, deadlineReachedError.response, newHandlerErrorResponse(deadline, context.DeadlineExceeded))
+	assert.NotSame(t, deadlineReachedError.response, newHandlerErrorResponse(deadline, fmt.Errorf("load: %w", context.DeadlineExceeded)))
+
+	parent, stop := context.WithCancelCause(context.Background())
+	stop(ErrSIGTERM)
+	assert.Same(t, sigtermCanceledError.response, newHandlerErrorResponse(parent, context.Canceled))
+
+	custom, cancelCustom := context.WithDeadlineCause(context.Background(), time.Now().Add(-time.Second), fmt.Errorf("budget: %w", context.DeadlineExceeded))
+	defer cancelCustom()
+	response := newHandlerErrorResponse(custom, context.DeadlineExceeded)
+	assert.NotSame(t, deadlineReachedError.response, response)
+	assert.Equal(t, "budget: context deadline exceeded", response.Details["contextCause"])
+}
+
+func BenchmarkGetErrorType(b *testing.B) {
+	for name, err := range map[string]error{
+		"errors.New": errors.New("boom"),
+		"fmt.Errorf": fmt.Errorf("wrapped: %w", errors.New("boom")),
+		"named":      &customPointerError{msg: "boom"},
+		"deadline":   context.DeadlineExceeded,
+		"response":   &ErrorResponse{Type: "Orders.Timeout"},
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				_ = getErrorType(err)
+			}
+		})
+	}
+}
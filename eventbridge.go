@@ -0,0 +1,88 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hotsock/voker/vokerevents"
+)
+
+type eventBridgeRoute struct {
+	source     string
+	detailType string
+}
+
+type eventBridgeHandler func(context.Context, vokerevents.EventBridgeEvent[json.RawMessage]) error
+
+// EventBridgeRouter dispatches EventBridge events to handlers registered
+// with [HandleEventBridge] for their source and detail type, decoding each
+// event's detail into the handler's type. Its Handle method is a handler for
+// [Start], and its Dispatch method one for [AutoHandlers].OnEventBridge:
+//
+//	router := voker.NewEventBridgeRouter()
+//	voker.HandleEventBridge(router, "com.example.orders", "Order Placed", reserveStock)
+//	voker.HandleEventBridge(router, "aws.s3", "Object Created", indexObject)
+//	voker.Start(router.Handle)
+//
+//	func reserveStock(ctx context.Context, event vokerevents.EventBridgeEvent[OrderPlaced]) error {
+//	    // ...
+//	}
+//
+// Add routes before starting the runtime; an EventBridgeRouter is safe for
+// concurrent dispatch but not for concurrent registration.
+type EventBridgeRouter struct {
+	routes   map[eventBridgeRoute]eventBridgeHandler
+	fallback eventBridgeHandler
+}
+
+// NewEventBridgeRouter returns a router with no routes.
+func NewEventBridgeRouter() *EventBridgeRouter {
+	return &EventBridgeRouter{routes: make(map[eventBridgeRoute]eventBridgeHandler)}
+}
+
+// HandleEventBridge routes events with source and detailType to handler,
+// replacing any handler already registered for the pair, and returns r.
+// Details that fail to decode into D fail the invocation with errorType
+// Runtime.UnmarshalError.
+func HandleEventBridge[D any](r *EventBridgeRouter, source, detailType string, handler func(context.Context, vokerevents.EventBridgeEvent[D]) error) *EventBridgeRouter {
+	r.routes[eventBridgeRoute{source, detailType}] = func(ctx context.Context, event vokerevents.EventBridgeEvent[json.RawMessage]) error {
+		decoded, err := vokerevents.DecodeEventBridgeDetail[D](event)
+		if err != nil {
+			return &ErrorResponse{
+				Message: fmt.Sprintf("failed to unmarshal %s %q detail: %v", source, detailType, err),
+				Type:    "Runtime.UnmarshalError",
+			}
+		}
+		return handler(ctx, decoded)
+	}
+	return r
+}
+
+// HandleFallback sets the handler for events that match no route. Without
+// one, such events fail with errorType UnhandledEventError.
+func (r *EventBridgeRouter) HandleFallback(handler func(context.Context, vokerevents.EventBridgeEvent[json.RawMessage]) error) *EventBridgeRouter {
+	r.fallback = handler
+	return r
+}
+
+// Handle dispatches event like Dispatch, in the handler form [Start]
+// accepts.
+func (r *EventBridgeRouter) Handle(ctx context.Context, event vokerevents.EventBridgeEvent[json.RawMessage]) (struct{}, error) {
+	return struct{}{}, r.Dispatch(ctx, event)
+}
+
+// Dispatch calls the handler registered for the source and detail type of
+// event.
+func (r *EventBridgeRouter) Dispatch(ctx context.Context, event vokerevents.EventBridgeEvent[json.RawMessage]) error {
+	if handler, ok := r.routes[eventBridgeRoute{event.Source, event.DetailType}]; ok {
+		return handler(ctx, event)
+	}
+	if r.fallback != nil {
+		return r.fallback(ctx, event)
+	}
+	return &ErrorResponse{
+		Message: fmt.Sprintf("no handler registered for %s %q event", event.Source, event.DetailType),
+		Type:    "UnhandledEventError",
+	}
+}
@@ -0,0 +1,75 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hotsock/voker/vokerevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOrderDetail struct {
+	OrderID string `json:"orderId"`
+}
+
+func TestEventBridgeRouter(t *testing.T) {
+	var placed []string
+	var fallback []string
+	router := NewEventBridgeRouter()
+	HandleEventBridge(router, "com.example.orders", "Order Placed", func(_ context.Context, event vokerevents.EventBridgeEvent[testOrderDetail]) error {
+		placed = append(placed, event.ID+":"+event.Detail.OrderID)
+		return nil
+	})
+	HandleEventBridge(router, "com.example.orders", "Order Cancelled", func(context.Context, vokerevents.EventBridgeEvent[testOrderDetail]) error {
+		return errors.New("cancellation failed")
+	})
+
+	dispatch := func(payload string) error {
+		_, err := callHandler(context.Background(), []byte(payload), router.Handle, nil)
+		return err
+	}
+
+	err := dispatch(`{"id":"evt-1","source":"com.example.orders","detail-type":"Order Placed","detail":{"orderId":"o-1"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"evt-1:o-1"}, placed)
+
+	err = dispatch(`{"id":"evt-2","source":"com.example.orders","detail-type":"Order Cancelled","detail":{}}`)
+	assert.ErrorContains(t, err, "cancellation failed")
+
+	err = dispatch(`{"source":"com.example.orders","detail-type":"Order Placed","detail":{"orderId":7}}`)
+	errResp, ok := errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "Runtime.UnmarshalError", errResp.Type)
+	assert.Contains(t, errResp.Message, `failed to unmarshal com.example.orders "Order Placed" detail`)
+
+	// The same detail type from another source is a different route.
+	err = dispatch(`{"source":"com.example.billing","detail-type":"Order Placed","detail":{}}`)
+	errResp, ok = errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "UnhandledEventError", errResp.Type)
+	assert.Equal(t, `no handler registered for com.example.billing "Order Placed" event`, errResp.Message)
+
+	router.HandleFallback(func(_ context.Context, event vokerevents.EventBridgeEvent[json.RawMessage]) error {
+		fallback = append(fallback, event.Source+":"+string(event.Detail))
+		return nil
+	})
+	err = dispatch(`{"source":"com.example.billing","detail-type":"Order Placed","detail":{"total":1}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`com.example.billing:{"total":1}`}, fallback)
+}
+
+func TestEventBridgeRouter_AutoHandlers(t *testing.T) {
+	var got string
+	router := HandleEventBridge(NewEventBridgeRouter(), "aws.s3", "Object Created", func(_ context.Context, event vokerevents.EventBridgeEvent[map[string]any]) error {
+		got = event.Detail["key"].(string)
+		return nil
+	})
+
+	_, err := autoHandler(AutoHandlers{OnEventBridge: router.Dispatch})(context.Background(),
+		json.RawMessage(`{"source":"aws.s3","detail-type":"Object Created","detail":{"key":"photo.jpg"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "photo.jpg", got)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sync"
 	"time"
 )
@@ -32,21 +33,68 @@ type InternalExtension struct {
 	// API, but Lambda sends SIGTERM to the runtime process 600ms before
 	// SIGKILL. The context will have a deadline of 500ms to be safe.
 	OnSIGTERM func(ctx context.Context)
+
+	// OnTelemetry is called with each batch of telemetry the Telemetry API
+	// delivers (optional). Setting it subscribes the extension to the
+	// streams Telemetry selects, delivered to a local HTTP listener voker
+	// runs on the sandbox hostname. Lambda delivers batches asynchronously,
+	// so telemetry written late in an invocation may arrive during the next
+	// one. A panic is logged and fails only that delivery. After SIGTERM the
+	// listener accepts deliveries for another 100ms and waits for in-flight
+	// calls before OnSIGTERM is called, so OnSIGTERM can flush everything
+	// OnTelemetry received.
+	OnTelemetry func(ctx context.Context, events []TelemetryEvent)
+
+	// Telemetry configures the subscription made for OnTelemetry.
+	Telemetry TelemetrySubscription
+
+	// RegisterTimeout bounds the extension's registration with the
+	// Extensions API, including its telemetry subscription (optional). Zero
+	// waits indefinitely.
+	RegisterTimeout time.Duration
+
+	// FailurePolicy decides what happens when OnInit, registration, or the
+	// telemetry subscription fails or times out. The default,
+	// [ExtensionFailFast], fails initialization.
+	FailurePolicy ExtensionFailurePolicy
 }
 
+// ExtensionFailurePolicy decides how a failure to start an internal
+// extension affects the runtime.
+type ExtensionFailurePolicy int
+
+const (
+	// ExtensionFailFast fails initialization, so Lambda reports an init
+	// error and retries initialization on the next invocation.
+	ExtensionFailFast ExtensionFailurePolicy = iota
+	// ExtensionContinue logs the error and starts the function without the
+	// extension, whose state becomes [ExtensionFailed] and whose callbacks
+	// are not called. Use it for optional extensions, such as telemetry,
+	// that must not keep the function from serving traffic.
+	ExtensionContinue
+)
+
 const sigtermContextDeadline = 500 * time.Millisecond
 
 type extensionManager struct {
 	extensions []InternalExtension
 	client     *extensionAPIClient
+	listeners  []*telemetryListener
+	handles    []*ExtensionHandle
 	done       chan struct{}
 	wg         sync.WaitGroup
 	logger     *slog.Logger
+	// fullStackPaths is set by WithFullStackPaths.
+	fullStackPaths bool
+
+	// initDurations records how long each extension took to initialize
+	// and register during start.
+	initDurations []extensionInitDuration
 }
 
 func newExtensionManager(runtimeAPI string, extensions []InternalExtension, logger *slog.Logger) *extensionManager {
 	return &extensionManager{
-		extensions: extensions,
+		extensions: slices.Clone(extensions),
 		client:     newExtensionAPIClient(runtimeAPI, len(extensions)),
 		done:       make(chan struct{}),
 		logger:     logger,
@@ -54,32 +102,81 @@ func newExtensionManager(runtimeAPI string, extensions []InternalExtension, logg
 }
 
 func (m *extensionManager) start() error {
-	for _, ext := range m.extensions {
-		if ext.OnInit != nil {
-			if err := callExtensionInit(ext); err != nil {
+	for i, ext := range m.extensions {
+		initStart := time.Now()
+		handle := newExtensionHandle(ext.Name, m.client, m.logger)
+		m.handles = append(m.handles, handle)
+		ext = handle.bind(ext)
+		m.extensions[i] = ext
+
+		registered, err := m.startExtension(ext, handle)
+		if err != nil {
+			if ext.FailurePolicy != ExtensionContinue {
 				return err
 			}
+			m.logger.Error("continuing without extension", "extension", ext.Name, "error", err)
+			handle.stop(err)
+			if !registered {
+				continue
+			}
+		} else {
+			m.initDurations = append(m.initDurations, extensionInitDuration{name: ext.Name, duration: time.Since(initStart)})
 		}
 
-		var events []ExtensionEventType
-		if ext.OnInvoke != nil {
-			events = append(events, ExtensionEventInvoke)
+		m.wg.Go(func() { m.eventLoop(ext, handle) })
+	}
+	return nil
+}
+
+// startExtension initializes ext, registers it, and subscribes it to
+// telemetry. registered reports whether the Extensions API accepted the
+// registration, after which Lambda expects the extension's event loop to
+// run even if the telemetry subscription failed.
+func (m *extensionManager) startExtension(ext InternalExtension, handle *ExtensionHandle) (registered bool, err error) {
+	if ext.OnInit != nil {
+		if err := callExtensionInit(ext, m.fullStackPaths); err != nil {
+			return false, err
 		}
+	}
 
-		id, err := m.client.register(ext.Name, events)
-		if err != nil {
-			return fmt.Errorf("failed to register extension %s: %w", ext.Name, err)
+	ctx := context.Background()
+	if ext.RegisterTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ext.RegisterTimeout)
+		defer cancel()
+	}
+
+	var events []ExtensionEventType
+	if ext.OnInvoke != nil {
+		events = append(events, ExtensionEventInvoke)
+	}
+
+	id, err := m.client.registerContext(ctx, ext.Name, events)
+	if err != nil {
+		return false, fmt.Errorf("failed to register extension %s: %w", ext.Name, err)
+	}
+	handle.id = id
+	if ext.OnTelemetry != nil {
+		if err := m.subscribeTelemetry(ctx, ext, id); err != nil {
+			return true, fmt.Errorf("failed to subscribe extension %s to telemetry: %w", ext.Name, err)
 		}
+	}
+	return true, nil
+}
 
-		m.wg.Go(func() { m.eventLoop(ext, id) })
+func (m *extensionManager) subscribeTelemetry(ctx context.Context, ext InternalExtension, id string) error {
+	listener, err := startTelemetryListener(ext, m.logger)
+	if err != nil {
+		return err
 	}
-	return nil
+	m.listeners = append(m.listeners, listener)
+	return m.client.subscribeTelemetry(ctx, id, listener.uri(), ext.Telemetry)
 }
 
-func callExtensionInit(ext InternalExtension) (responseErr *ErrorResponse) {
+func callExtensionInit(ext InternalExtension, fullStackPaths bool) (responseErr *ErrorResponse) {
 	defer func() {
 		if recovered := recover(); recovered != nil {
-			responseErr = newPanicResponse(recovered)
+			responseErr = newPanicResponse(recovered, fullStackPaths)
 			responseErr.Message = fmt.Sprintf("extension %s init panicked: %s", ext.Name, responseErr.Message)
 		}
 	}()
@@ -99,6 +196,10 @@ func (m *extensionManager) shutdown() {
 
 	close(m.done)
 
+	for _, listener := range m.listeners {
+		listener.shutdown(ctx)
+	}
+
 	for _, ext := range m.extensions {
 		if ext.OnSIGTERM != nil {
 			ext.OnSIGTERM(ctx)
@@ -122,8 +223,9 @@ func callOnInvoke(ext InternalExtension, eventPayload *ExtensionEventPayload) {
 	ext.OnInvoke(ctx, *eventPayload)
 }
 
-func (m *extensionManager) eventLoop(ext InternalExtension, id string) {
+func (m *extensionManager) eventLoop(ext InternalExtension, handle *ExtensionHandle) {
 	ctx := context.Background()
+	exited := handle.Done()
 
 	for {
 		// Use a channel to make the blocking next() call interruptible
@@ -134,29 +236,42 @@ func (m *extensionManager) eventLoop(ext InternalExtension, id string) {
 		resultCh := make(chan result, 1)
 
 		go func() {
-			event, err := m.client.next(id)
+			event, err := m.client.next(handle.id)
 			resultCh <- result{event, err}
 		}()
 
-		select {
-		case <-m.done:
-			// SIGTERM signal received
-			return
-		case res := <-resultCh:
-			if res.err != nil {
-				m.logger.ErrorContext(ctx, "extension event loop error", "extension", ext.Name, "error", res.err)
+		var res result
+	wait:
+		for {
+			select {
+			case <-m.done:
+				// SIGTERM signal received
 				return
+			case <-exited:
+				// An extension registered for INVOKE events must keep
+				// asking for them, or Lambda holds every invocation.
+				if ext.OnInvoke == nil {
+					return
+				}
+				exited = nil
+			case res = <-resultCh:
+				break wait
 			}
+		}
 
-			switch res.eventPayload.EventType {
-			case ExtensionEventInvoke:
-				if ext.OnInvoke != nil {
-					callOnInvoke(ext, res.eventPayload)
-				}
-			default:
-				// Log unknown event types but continue processing
-				m.logger.ErrorContext(ctx, "extension received unknown event type", "extension", ext.Name, "eventType", res.eventPayload.EventType)
+		if res.err != nil {
+			m.logger.ErrorContext(ctx, "extension event loop error", "extension", ext.Name, "error", res.err)
+			return
+		}
+
+		switch res.eventPayload.EventType {
+		case ExtensionEventInvoke:
+			if ext.OnInvoke != nil {
+				callOnInvoke(ext, res.eventPayload)
 			}
+		default:
+			// Log unknown event types but continue processing
+			m.logger.ErrorContext(ctx, "extension received unknown event type", "extension", ext.Name, "eventType", res.eventPayload.EventType)
 		}
 	}
 }
@@ -6,10 +6,13 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -85,6 +88,9 @@ func TestExtensionManager_Start_OnInit(t *testing.T) {
 	if !initCalled {
 		t.Error("expected OnInit to be called")
 	}
+	if len(mgr.initDurations) != 1 || mgr.initDurations[0].name != "TestExtension" {
+		t.Errorf("expected init duration for TestExtension, got %+v", mgr.initDurations)
+	}
 
 	// Close server to terminate event loop
 	server.Close()
@@ -346,3 +352,114 @@ func TestExtensionManager_Shutdown(t *testing.T) {
 	server.Close()
 	time.Sleep(50 * time.Millisecond)
 }
+
+func TestExtensionManager_RegisterTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ext := InternalExtension{Name: "Slow", OnInvoke: func(context.Context, ExtensionEventPayload) {}, RegisterTimeout: 20 * time.Millisecond}
+	mgr := newExtensionManager(server.Listener.Addr().String(), []InternalExtension{ext}, slog.New(slog.DiscardHandler))
+
+	err := mgr.start()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestExtensionManager_FailurePolicyContinue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get(headerExtensionName) {
+		case "Broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Header().Set(headerExtensionIdentifier, "healthy-id")
+		}
+	}))
+	defer server.Close()
+
+	var logs syncBuffer
+	sigterms := map[string]int{}
+	var mu sync.Mutex
+	onSIGTERM := func(name string) func(context.Context) {
+		return func(context.Context) {
+			mu.Lock()
+			defer mu.Unlock()
+			sigterms[name]++
+		}
+	}
+	mgr := newExtensionManager(server.Listener.Addr().String(), []InternalExtension{
+		{Name: "Broken", OnSIGTERM: onSIGTERM("Broken"), FailurePolicy: ExtensionContinue},
+		{Name: "Healthy", OnSIGTERM: onSIGTERM("Healthy")},
+	}, slog.New(slog.NewTextHandler(&logs, nil)))
+
+	if err := mgr.start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mgr.shutdown()
+
+	if state := mgr.handles[0].State(); state != ExtensionFailed {
+		t.Errorf("expected Broken to be %s, got %s", ExtensionFailed, state)
+	}
+	if state := mgr.handles[1].State(); state != ExtensionRunning {
+		t.Errorf("expected Healthy to be %s, got %s", ExtensionRunning, state)
+	}
+	if len(mgr.initDurations) != 1 || mgr.initDurations[0].name != "Healthy" {
+		t.Errorf("expected only Healthy init duration, got %v", mgr.initDurations)
+	}
+	if want := map[string]int{"Healthy": 1}; !maps.Equal(sigterms, want) {
+		t.Errorf("expected OnSIGTERM calls %v, got %v", want, sigterms)
+	}
+	if !strings.Contains(logs.String(), `msg="continuing without extension" extension=Broken error="failed to register extension Broken: register failed with status: 500"`) {
+		t.Errorf("expected continuing log, got %s", logs.String())
+	}
+}
+
+func TestExtensionManager_FailurePolicyContinueAfterRegistration(t *testing.T) {
+	telemetryHost = "127.0.0.1"
+	t.Cleanup(func() { telemetryHost = "sandbox.localdomain" })
+
+	var nexts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2020-01-01/extension/register":
+			w.Header().Set(headerExtensionIdentifier, "test-id")
+		case "/2022-07-01/telemetry":
+			w.WriteHeader(http.StatusForbidden)
+		case "/2020-01-01/extension/event/next":
+			nexts.Add(1)
+			time.Sleep(5 * time.Millisecond)
+			json.NewEncoder(w).Encode(ExtensionEventPayload{EventType: ExtensionEventInvoke})
+		}
+	}))
+	defer server.Close()
+
+	var invokes atomic.Int32
+	ext := InternalExtension{
+		Name:          "Telemetry",
+		OnInvoke:      func(context.Context, ExtensionEventPayload) { invokes.Add(1) },
+		OnTelemetry:   func(context.Context, []TelemetryEvent) {},
+		FailurePolicy: ExtensionContinue,
+	}
+	mgr := newExtensionManager(server.Listener.Addr().String(), []InternalExtension{ext}, slog.New(slog.DiscardHandler))
+	if err := mgr.start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Lambda still expects the registered extension to ask for events.
+	deadline := time.Now().Add(time.Second)
+	for nexts.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	mgr.shutdown()
+
+	if nexts.Load() < 3 {
+		t.Errorf("expected the event loop to keep polling, got %d requests", nexts.Load())
+	}
+	if invokes.Load() != 0 {
+		t.Errorf("expected OnInvoke not to be called, got %d calls", invokes.Load())
+	}
+}
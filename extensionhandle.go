@@ -0,0 +1,172 @@
+package voker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// ExtensionState is the lifecycle state of an internal extension.
+type ExtensionState string
+
+const (
+	// ExtensionRunning is the state of a registered extension that has not
+	// exited.
+	ExtensionRunning ExtensionState = "RUNNING"
+	// ExtensionExited is the state of an extension that exited cleanly.
+	ExtensionExited ExtensionState = "EXITED"
+	// ExtensionFailed is the state of an extension that exited with an
+	// error.
+	ExtensionFailed ExtensionState = "FAILED"
+)
+
+// ExtensionHandle controls a registered internal extension. Its callbacks
+// receive it through their context; see [ExtensionFromContext].
+type ExtensionHandle struct {
+	name string
+	// id is the identifier the Extensions API assigned at registration. It
+	// is empty when registration failed.
+	id     string
+	client *extensionAPIClient
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	state  ExtensionState
+	err    error
+	exited chan struct{}
+}
+
+type extensionHandleKey struct{}
+
+// ExtensionFromContext returns the handle of the extension whose OnInvoke,
+// OnTelemetry, or OnSIGTERM callback received ctx, or nil outside an
+// extension callback.
+func ExtensionFromContext(ctx context.Context) *ExtensionHandle {
+	handle, _ := ctx.Value(extensionHandleKey{}).(*ExtensionHandle)
+	return handle
+}
+
+func newExtensionHandle(name string, client *extensionAPIClient, logger *slog.Logger) *ExtensionHandle {
+	return &ExtensionHandle{
+		name:   name,
+		client: client,
+		logger: logger,
+		state:  ExtensionRunning,
+		exited: make(chan struct{}),
+	}
+}
+
+// Name returns the extension's name.
+func (h *ExtensionHandle) Name() string {
+	return h.name
+}
+
+// State returns the extension's lifecycle state.
+func (h *ExtensionHandle) State() ExtensionState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.state
+}
+
+// Err returns the error the extension exited with, if any.
+func (h *ExtensionHandle) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+// Done returns a channel that is closed when the extension exits.
+func (h *ExtensionHandle) Done() <-chan struct{} {
+	return h.exited
+}
+
+// Exit stops the extension, for example when the downstream it serves is
+// permanently gone. None of its callbacks are called afterwards, including
+// OnSIGTERM, and telemetry deliveries are acknowledged and dropped. A call
+// from inside a callback takes effect when that callback returns. Only the
+// first call has an effect.
+//
+// With a nil err the extension enters [ExtensionExited]. Otherwise it enters
+// [ExtensionFailed] and the error is reported to the Extensions API's
+// exit/error endpoint, which Lambda treats as an extension failure and may
+// answer by resetting the execution environment; use a nil err when the
+// extension simply has nothing left to do.
+//
+// Lambda holds every invocation until each extension registered for INVOKE
+// events asks for the next event, so an extension with an OnInvoke callback
+// keeps acknowledging events without calling it. Other extensions stop
+// polling for events entirely.
+func (h *ExtensionHandle) Exit(err error) {
+	h.mu.Lock()
+	if h.state != ExtensionRunning {
+		h.mu.Unlock()
+		return
+	}
+	h.state = ExtensionExited
+	if err != nil {
+		h.state = ExtensionFailed
+		h.err = err
+	}
+	close(h.exited)
+	h.mu.Unlock()
+
+	ctx := context.Background()
+	if err == nil {
+		h.logger.InfoContext(ctx, "extension exited", "extension", h.name, "state", ExtensionExited)
+		return
+	}
+	h.logger.ErrorContext(ctx, "extension exited", "extension", h.name, "state", ExtensionFailed, "error", err)
+	if reportErr := h.client.exitError(h.id, newErrorResponse(err)); reportErr != nil {
+		h.logger.ErrorContext(ctx, "failed to report extension exit error", "extension", h.name, "error", reportErr)
+	}
+}
+
+// stop moves the extension to [ExtensionFailed] without reporting err to
+// the Extensions API, for an extension that failed to start.
+func (h *ExtensionHandle) stop(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.state == ExtensionRunning {
+		h.state = ExtensionFailed
+		h.err = err
+		close(h.exited)
+	}
+}
+
+// running reports whether the extension has not exited.
+func (h *ExtensionHandle) running() bool {
+	select {
+	case <-h.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// bind returns ext with callbacks that receive h in their context and are
+// skipped once the extension has exited. Nil callbacks stay nil, since they
+// determine the events the extension registers for.
+func (h *ExtensionHandle) bind(ext InternalExtension) InternalExtension {
+	if onInvoke := ext.OnInvoke; onInvoke != nil {
+		ext.OnInvoke = func(ctx context.Context, eventPayload ExtensionEventPayload) {
+			if h.running() {
+				onInvoke(context.WithValue(ctx, extensionHandleKey{}, h), eventPayload)
+			}
+		}
+	}
+	if onTelemetry := ext.OnTelemetry; onTelemetry != nil {
+		ext.OnTelemetry = func(ctx context.Context, events []TelemetryEvent) {
+			if h.running() {
+				onTelemetry(context.WithValue(ctx, extensionHandleKey{}, h), events)
+			}
+		}
+	}
+	if onSIGTERM := ext.OnSIGTERM; onSIGTERM != nil {
+		ext.OnSIGTERM = func(ctx context.Context) {
+			if h.running() {
+				onSIGTERM(context.WithValue(ctx, extensionHandleKey{}, h))
+			}
+		}
+	}
+	return ext
+}
@@ -0,0 +1,141 @@
+package voker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtensionHandle_Exit(t *testing.T) {
+	var logs bytes.Buffer
+	handle := newExtensionHandle("cache", nil, slog.New(slog.NewTextHandler(&logs, nil)))
+	assert.Equal(t, "cache", handle.Name())
+	assert.Equal(t, ExtensionRunning, handle.State())
+
+	handle.Exit(nil)
+	handle.Exit(errors.New("ignored"))
+
+	assert.Equal(t, ExtensionExited, handle.State())
+	assert.NoError(t, handle.Err())
+	select {
+	case <-handle.Done():
+	default:
+		t.Fatal("expected Done to be closed")
+	}
+	assert.Contains(t, logs.String(), `msg="extension exited" extension=cache state=EXITED`)
+}
+
+func TestExtensionHandle_ExitError(t *testing.T) {
+	type report struct {
+		id, errorType string
+		body          ErrorResponse
+	}
+	reports := make(chan report, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2020-01-01/extension/exit/error", r.URL.Path)
+		var body ErrorResponse
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		reports <- report{r.Header.Get(headerExtensionIdentifier), r.Header.Get(headerExtensionErrorType), body}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	client := newExtensionAPIClient(server.Listener.Addr().String(), 1)
+	handle := newExtensionHandle("cache", client, slog.New(slog.NewTextHandler(&logs, nil)))
+	handle.id = "ext-id"
+	handle.Exit(&ErrorResponse{Type: "Extension.DownstreamGone", Message: "cache cluster deleted"})
+
+	assert.Equal(t, ExtensionFailed, handle.State())
+	assert.EqualError(t, handle.Err(), "cache cluster deleted")
+	got := <-reports
+	assert.Equal(t, "ext-id", got.id)
+	assert.Equal(t, "Extension.DownstreamGone", got.errorType)
+	assert.Equal(t, "cache cluster deleted", got.body.Message)
+	assert.Contains(t, logs.String(), `msg="extension exited" extension=cache state=FAILED error.errorType=Extension.DownstreamGone error.errorMessage="cache cluster deleted"`)
+	assert.NotContains(t, logs.String(), "failed to report")
+}
+
+func TestExtensionFromContext(t *testing.T) {
+	assert.Nil(t, ExtensionFromContext(context.Background()))
+}
+
+func TestExtensionManager_ExitFromOnInvoke(t *testing.T) {
+	var nexts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2020-01-01/extension/register":
+			w.Header().Set(headerExtensionIdentifier, "test-id")
+		case "/2020-01-01/extension/event/next":
+			nexts.Add(1)
+			time.Sleep(5 * time.Millisecond)
+			json.NewEncoder(w).Encode(ExtensionEventPayload{EventType: ExtensionEventInvoke, RequestID: "req"})
+		}
+	}))
+	defer server.Close()
+
+	var invokes atomic.Int32
+	sigterms := 0
+	ext := InternalExtension{
+		Name: "cache",
+		OnInvoke: func(ctx context.Context, eventPayload ExtensionEventPayload) {
+			invokes.Add(1)
+			ExtensionFromContext(ctx).Exit(nil)
+		},
+		OnSIGTERM: func(ctx context.Context) { sigterms++ },
+	}
+	mgr := newExtensionManager(server.Listener.Addr().String(), []InternalExtension{ext}, slog.New(slog.DiscardHandler))
+	require.NoError(t, mgr.start())
+
+	assert.Eventually(t, func() bool { return nexts.Load() >= 4 }, time.Second, time.Millisecond,
+		"an exited extension registered for INVOKE keeps acknowledging events")
+	mgr.shutdown()
+
+	assert.Equal(t, int32(1), invokes.Load())
+	assert.Zero(t, sigterms)
+	assert.Equal(t, ExtensionExited, mgr.handles[0].State())
+}
+
+func TestExtensionManager_ExitStopsEventLoop(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2020-01-01/extension/register":
+			w.Header().Set(headerExtensionIdentifier, "test-id")
+		case "/2020-01-01/extension/event/next":
+			<-release
+		case "/2020-01-01/extension/exit/error":
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ext := InternalExtension{Name: "sidecar", OnSIGTERM: func(ctx context.Context) {}}
+	mgr := newExtensionManager(server.Listener.Addr().String(), []InternalExtension{ext}, slog.New(slog.DiscardHandler))
+	require.NoError(t, mgr.start())
+
+	mgr.handles[0].Exit(errors.New("downstream gone"))
+
+	stopped := make(chan struct{})
+	go func() {
+		mgr.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("event loop did not stop after Exit")
+	}
+	assert.Equal(t, ExtensionFailed, mgr.handles[0].State())
+}
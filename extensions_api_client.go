@@ -2,6 +2,7 @@ package voker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,7 +12,9 @@ import (
 const (
 	headerExtensionName       = "lambda-extension-name"
 	headerExtensionIdentifier = "lambda-extension-identifier"
+	headerExtensionErrorType  = "Lambda-Extension-Function-Error-Type"
 	extensionAPIVersion       = "2020-01-01"
+	telemetryAPIVersion       = "2022-07-01"
 )
 
 // ExtensionEventType identifies the kind of event delivered to an extension
@@ -25,10 +28,15 @@ type ExtensionEventType string
 const ExtensionEventInvoke ExtensionEventType = "INVOKE"
 
 type extensionAPIClient struct {
-	baseURL     string
-	registerURL string
-	nextURL     string
-	httpClient  *http.Client
+	baseURL      string
+	registerURL  string
+	nextURL      string
+	exitErrorURL string
+	telemetryURL string
+	httpClient   *http.Client
+	// userAgent is the User-Agent header value, shared with the runtime
+	// client.
+	userAgent []string
 }
 
 // newExtensionAPIClient returns a client for the Extensions API.
@@ -42,10 +50,13 @@ func newExtensionAPIClient(address string, maxIdleConnsPerHost int) *extensionAP
 
 	baseURL := "http://" + address + "/" + extensionAPIVersion + "/extension/"
 	return &extensionAPIClient{
-		baseURL:     baseURL,
-		registerURL: baseURL + "register",
-		nextURL:     baseURL + "event/next",
-		httpClient:  client,
+		baseURL:      baseURL,
+		registerURL:  baseURL + "register",
+		nextURL:      baseURL + "event/next",
+		exitErrorURL: baseURL + "exit/error",
+		telemetryURL: "http://" + address + "/" + telemetryAPIVersion + "/telemetry",
+		httpClient:   client,
+		userAgent:    userAgentValue,
 	}
 }
 
@@ -54,16 +65,21 @@ type registerRequest struct {
 }
 
 func (c *extensionAPIClient) register(name string, events []ExtensionEventType) (string, error) {
+	return c.registerContext(context.Background(), name, events)
+}
+
+func (c *extensionAPIClient) registerContext(ctx context.Context, name string, events []ExtensionEventType) (string, error) {
 	body, err := json.Marshal(registerRequest{Events: events})
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal register request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, c.registerURL, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.registerURL, bytes.NewReader(body))
 	if err != nil {
 		return "", fmt.Errorf("failed to create register request: %w", err)
 	}
 	req.Header.Set(headerExtensionName, name)
+	req.Header[headerUserAgent] = c.userAgent
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -104,6 +120,7 @@ func (c *extensionAPIClient) next(id string) (*ExtensionEventPayload, error) {
 		return nil, fmt.Errorf("failed to create next request: %w", err)
 	}
 	req.Header.Set(headerExtensionIdentifier, id)
+	req.Header[headerUserAgent] = c.userAgent
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -122,3 +139,92 @@ func (c *extensionAPIClient) next(id string) (*ExtensionEventPayload, error) {
 
 	return &payload, nil
 }
+
+// exitError reports that the extension is exiting because of errorResponse.
+func (c *extensionAPIClient) exitError(id string, errorResponse *ErrorResponse) error {
+	body, err := json.Marshal(errorResponse)
+	if err != nil {
+		return fmt.Errorf("failed to marshal exit error: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.exitErrorURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create exit error request: %w", err)
+	}
+	req.Header.Set(headerExtensionIdentifier, id)
+	req.Header.Set(headerExtensionErrorType, errorResponse.Type)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header[headerUserAgent] = c.userAgent
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to report exit error: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("exit error failed with status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type telemetrySubscribeRequest struct {
+	SchemaVersion string               `json:"schemaVersion"`
+	Types         []TelemetryType      `json:"types"`
+	Buffering     telemetryBuffering   `json:"buffering"`
+	Destination   telemetryDestination `json:"destination"`
+}
+
+type telemetryBuffering struct {
+	MaxItems  int   `json:"maxItems,omitempty"`
+	MaxBytes  int   `json:"maxBytes,omitempty"`
+	TimeoutMs int64 `json:"timeoutMs,omitempty"`
+}
+
+type telemetryDestination struct {
+	Protocol string `json:"protocol"`
+	URI      string `json:"URI"`
+}
+
+// subscribeTelemetry subscribes the extension to the Telemetry API,
+// delivering to uri.
+func (c *extensionAPIClient) subscribeTelemetry(ctx context.Context, id, uri string, subscription TelemetrySubscription) error {
+	types := subscription.Types
+	if len(types) == 0 {
+		types = []TelemetryType{TelemetryFunction}
+	}
+	body, err := json.Marshal(telemetrySubscribeRequest{
+		SchemaVersion: telemetrySchemaVersion,
+		Types:         types,
+		Buffering: telemetryBuffering{
+			MaxItems:  subscription.MaxItems,
+			MaxBytes:  subscription.MaxBytes,
+			TimeoutMs: subscription.Timeout.Milliseconds(),
+		},
+		Destination: telemetryDestination{Protocol: "HTTP", URI: uri},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal subscribe request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.telemetryURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create subscribe request: %w", err)
+	}
+	req.Header.Set(headerExtensionIdentifier, id)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header[headerUserAgent] = c.userAgent
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to telemetry: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("subscribe failed with status: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package voker
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -125,3 +126,39 @@ func TestExtensionAPIClient_Next_Error(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestExtensionAPIClient_UserAgent(t *testing.T) {
+	var agents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get(headerUserAgent))
+		switch r.URL.Path {
+		case "/2020-01-01/extension/register":
+			w.Header().Set(headerExtensionIdentifier, "ext-id")
+		case "/2020-01-01/extension/event/next":
+			w.Write([]byte(`{"eventType":"INVOKE"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := newExtensionAPIClient(server.Listener.Addr().String(), 1)
+	client.userAgent = []string{userAgent + " acme-lambda/2.3.1"}
+	if _, err := client.register("TestExtension", nil); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := client.next("ext-id"); err != nil {
+		t.Fatalf("unexpected next error: %v", err)
+	}
+	if err := client.subscribeTelemetry(context.Background(), "ext-id", "http://sandbox:1234", TelemetrySubscription{}); err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+
+	want := userAgent + " acme-lambda/2.3.1"
+	if len(agents) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(agents))
+	}
+	for _, agent := range agents {
+		if agent != want {
+			t.Errorf("expected User-Agent %q, got %q", want, agent)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package voker
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readEventFixture returns one of the vokerevents sample payloads.
+func readEventFixture(t *testing.T, name string) json.RawMessage {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("vokerevents", "testdata", name))
+	require.NoError(t, err)
+	return b
+}
@@ -0,0 +1,153 @@
+package voker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	heartbeatName            = "voker-heartbeat"
+	defaultHeartbeatInterval = time.Minute
+	lambdaEnvLogStreamName   = "AWS_LAMBDA_LOG_STREAM_NAME"
+)
+
+// HeartbeatMetrics describes an execution environment at one heartbeat.
+type HeartbeatMetrics struct {
+	// EnvironmentID identifies the execution environment. It is the
+	// environment's CloudWatch log stream name, which Lambda creates for
+	// each environment.
+	EnvironmentID string
+
+	// InitType is how Lambda initialized the environment.
+	InitType InitializationType
+
+	// Uptime is the time since the process started.
+	Uptime time.Duration
+
+	// Invocations is the number of invocations the environment has served.
+	Invocations int64
+
+	// Final is true for the heartbeat sent when Lambda shuts the
+	// environment down.
+	Final bool
+}
+
+// HeartbeatSink receives the heartbeats of a [Heartbeat] extension. The
+// vokermetrics EMF sink implements it. RecordHeartbeat is called from a
+// background goroutine and should not block.
+type HeartbeatSink interface {
+	RecordHeartbeat(ctx context.Context, heartbeat HeartbeatMetrics)
+}
+
+// Heartbeat configures an internal extension that reports a heartbeat for
+// the execution environment when it starts, on an interval while it runs,
+// and when it shuts down. Counting heartbeats by environment shows how
+// often Lambda replaces environments and how busy provisioned concurrency
+// is. Register it with [WithHeartbeat].
+type Heartbeat struct {
+	// Sink receives the heartbeats (required).
+	Sink HeartbeatSink
+
+	// Name is the extension name. The default is "voker-heartbeat".
+	Name string
+
+	// Interval is the time between heartbeats. The default is 1 minute.
+	Interval time.Duration
+}
+
+// WithHeartbeat registers heartbeat's internal extension. Lambda freezes
+// the environment between invocations, so an idle environment sends no
+// heartbeats, and the one that came due while it was frozen is sent when
+// the next invocation thaws it. The final heartbeat is sent when Lambda
+// sends SIGTERM. Like other internal extensions, heartbeats are not
+// supported on Lambda Managed Instances.
+//
+//	voker.Start(handler, voker.WithHeartbeat(voker.Heartbeat{
+//	    Sink: vokermetrics.NewEMFSink("Orders"),
+//	}))
+func WithHeartbeat(heartbeat Heartbeat) Option {
+	return func(o *options) {
+		WithInternalExtension(newHeartbeat(heartbeat, o).extension())(o)
+	}
+}
+
+type heartbeat struct {
+	config      Heartbeat
+	options     *options
+	invocations atomic.Int64
+
+	environmentID string
+	initType      InitializationType
+
+	// mu serializes heartbeats, so the final one is sent last.
+	mu      sync.Mutex
+	stopped bool
+	stop    chan struct{}
+}
+
+// newHeartbeat returns a heartbeat that reads its environment through o once
+// initialization starts, after every option has been applied.
+func newHeartbeat(config Heartbeat, o *options) *heartbeat {
+	if config.Name == "" {
+		config.Name = heartbeatName
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultHeartbeatInterval
+	}
+	return &heartbeat{config: config, options: o, stop: make(chan struct{})}
+}
+
+func (h *heartbeat) extension() InternalExtension {
+	return InternalExtension{
+		Name: h.config.Name,
+		OnInit: func() error {
+			if h.config.Sink == nil {
+				return errors.New("heartbeat has no sink")
+			}
+			h.environmentID = getenv(h.options.env, lambdaEnvLogStreamName)
+			h.initType = h.options.initType()
+			h.send(context.Background(), false)
+			go h.run()
+			return nil
+		},
+		OnInvoke: func(context.Context, ExtensionEventPayload) {
+			h.invocations.Add(1)
+		},
+		OnSIGTERM: func(ctx context.Context) {
+			close(h.stop)
+			h.send(ctx, true)
+		},
+	}
+}
+
+func (h *heartbeat) run() {
+	ticker := time.NewTicker(h.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			h.send(context.Background(), false)
+		}
+	}
+}
+
+func (h *heartbeat) send(ctx context.Context, final bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stopped {
+		return
+	}
+	h.stopped = final
+	h.config.Sink.RecordHeartbeat(ctx, HeartbeatMetrics{
+		EnvironmentID: h.environmentID,
+		InitType:      h.initType,
+		Uptime:        time.Since(processStart),
+		Invocations:   h.invocations.Load(),
+		Final:         final,
+	})
+}
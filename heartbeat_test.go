@@ -0,0 +1,93 @@
+package voker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHeartbeatSink struct {
+	mu         sync.Mutex
+	heartbeats []HeartbeatMetrics
+	sent       chan struct{}
+}
+
+func (s *recordingHeartbeatSink) RecordHeartbeat(_ context.Context, heartbeat HeartbeatMetrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeats = append(s.heartbeats, heartbeat)
+	select {
+	case s.sent <- struct{}{}:
+	default:
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	t.Setenv(lambdaEnvLogStreamName, "2024/05/01/[$LATEST]abc")
+	t.Setenv(lambdaEnvInitializationType, string(InitProvisionedConcurrency))
+
+	sink := &recordingHeartbeatSink{sent: make(chan struct{}, 100)}
+	ext := newHeartbeat(Heartbeat{Sink: sink, Interval: 10 * time.Millisecond}, &options{}).extension()
+	assert.Equal(t, "voker-heartbeat", ext.Name)
+
+	require.NoError(t, ext.OnInit())
+	<-sink.sent
+	ext.OnInvoke(context.Background(), ExtensionEventPayload{})
+	ext.OnInvoke(context.Background(), ExtensionEventPayload{})
+
+	// Wait for a tick that saw both invocations.
+	require.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		return sink.heartbeats[len(sink.heartbeats)-1].Invocations == 2
+	}, time.Second, 5*time.Millisecond)
+
+	ext.OnSIGTERM(context.Background())
+	time.Sleep(30 * time.Millisecond)
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	first, last := sink.heartbeats[0], sink.heartbeats[len(sink.heartbeats)-1]
+	assert.Equal(t, "2024/05/01/[$LATEST]abc", first.EnvironmentID)
+	assert.Equal(t, InitProvisionedConcurrency, first.InitType)
+	assert.Zero(t, first.Invocations)
+	assert.False(t, first.Final)
+	assert.Greater(t, first.Uptime, time.Duration(0))
+
+	assert.True(t, last.Final, "the final heartbeat is sent last")
+	assert.Equal(t, int64(2), last.Invocations)
+	assert.GreaterOrEqual(t, last.Uptime, first.Uptime)
+}
+
+func TestHeartbeat_NoSink(t *testing.T) {
+	err := newHeartbeat(Heartbeat{}, &options{}).extension().OnInit()
+	assert.EqualError(t, err, "heartbeat has no sink")
+}
+
+func TestHeartbeat_WithEnv(t *testing.T) {
+	t.Setenv(lambdaEnvLogStreamName, "process")
+	t.Setenv(lambdaEnvInitializationType, string(InitOnDemand))
+
+	sink := &recordingHeartbeatSink{sent: make(chan struct{}, 100)}
+	o := &options{}
+	WithHeartbeat(Heartbeat{Sink: sink, Interval: time.Hour})(o)
+	WithEnv(map[string]string{
+		lambdaEnvLogStreamName:      "2024/05/01/[$LATEST]def",
+		lambdaEnvInitializationType: string(InitSnapStart),
+	})(o)
+	require.Len(t, o.extensions, 1)
+
+	ext := o.extensions[0]
+	require.NoError(t, ext.OnInit())
+	ext.OnSIGTERM(context.Background())
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	require.NotEmpty(t, sink.heartbeats)
+	assert.Equal(t, "2024/05/01/[$LATEST]def", sink.heartbeats[0].EnvironmentID)
+	assert.Equal(t, InitSnapStart, sink.heartbeats[0].InitType)
+}
@@ -0,0 +1,102 @@
+package voker
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// lambdaInitTimeout is how long Lambda allows an on-demand environment
+	// to initialize before it restarts initialization as part of the first
+	// invocation.
+	lambdaInitTimeout = 10 * time.Second
+
+	defaultInitWarningThreshold = 8 * time.Second
+)
+
+// processStart approximates when the process started. Package variables
+// are initialized before main runs, so it includes the program's own
+// package initialization only when voker is initialized first.
+var processStart = time.Now()
+
+// WithInitDurationWarning logs a warning with a breakdown of where the time
+// went when initialization of an on-demand environment takes longer than
+// threshold. Lambda abandons initialization after 10 seconds and retries it
+// during the first invocation, a slow fallback that is otherwise easy to
+// miss. The default threshold is 8 seconds; zero disables the warning.
+//
+// Provisioned concurrency and SnapStart environments have longer init
+// limits and are not checked.
+func WithInitDurationWarning(threshold time.Duration) Option {
+	return func(o *options) {
+		o.initWarning = &threshold
+	}
+}
+
+// WithInitDurationLimit fails initialization with a Runtime.InitDurationExceeded
+// error when initialization of an on-demand environment takes longer than
+// limit, instead of letting Lambda time it out. A limit below 10 seconds
+// turns slow initialization into an immediate, visible failure.
+func WithInitDurationLimit(limit time.Duration) Option {
+	return func(o *options) {
+		o.initLimit = limit
+	}
+}
+
+// extensionInitDuration records how long one extension took to initialize
+// and register.
+type extensionInitDuration struct {
+	name     string
+	duration time.Duration
+}
+
+// initBreakdown describes where initialization time went.
+type initBreakdown struct {
+	// total is the time from process start until the runtime is ready to
+	// request the first invocation.
+	total time.Duration
+	// beforeStart is the part of total spent before Start was called.
+	beforeStart time.Duration
+	extensions  []extensionInitDuration
+}
+
+func (o *options) initWarningThreshold() time.Duration {
+	if o.initWarning == nil {
+		return defaultInitWarningThreshold
+	}
+	return *o.initWarning
+}
+
+// checkInitDuration warns about or rejects a slow initialization. It
+// returns an error when the configured limit was exceeded.
+func checkInitDuration(options *options, initType InitializationType, breakdown initBreakdown) error {
+	if initType != InitOnDemand && initType != "" {
+		return nil
+	}
+
+	limitExceeded := options.initLimit > 0 && breakdown.total > options.initLimit
+	threshold := options.initWarningThreshold()
+	if !limitExceeded && (threshold <= 0 || breakdown.total <= threshold) {
+		return nil
+	}
+
+	extensionAttrs := make([]any, 0, len(breakdown.extensions))
+	for _, ext := range breakdown.extensions {
+		extensionAttrs = append(extensionAttrs, slog.Duration(ext.name, ext.duration))
+	}
+	options.logger.Warn("slow initialization",
+		"initDuration", breakdown.total,
+		"initTimeout", lambdaInitTimeout,
+		"beforeStart", breakdown.beforeStart,
+		slog.Group("extensionInit", extensionAttrs...),
+	)
+
+	if limitExceeded {
+		return &ErrorResponse{
+			Type:    "Runtime.InitDurationExceeded",
+			Message: fmt.Sprintf("initialization took %s, exceeding the configured limit of %s", breakdown.total, options.initLimit),
+		}
+	}
+	return nil
+}
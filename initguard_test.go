@@ -0,0 +1,93 @@
+package voker
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckInitDuration(t *testing.T) {
+	slow := initBreakdown{
+		total:       9 * time.Second,
+		beforeStart: 2 * time.Second,
+		extensions: []extensionInitDuration{
+			{name: "fast", duration: time.Millisecond},
+			{name: "slow", duration: 6 * time.Second},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		initType  InitializationType
+		breakdown initBreakdown
+		wantWarn  bool
+		wantErr   bool
+	}{
+		{name: "fast init", initType: InitOnDemand, breakdown: initBreakdown{total: time.Second}},
+		{name: "default threshold", initType: InitOnDemand, breakdown: slow, wantWarn: true},
+		{name: "outside Lambda", breakdown: slow, wantWarn: true},
+		{name: "custom threshold", opts: []Option{WithInitDurationWarning(500 * time.Millisecond)}, initType: InitOnDemand, breakdown: initBreakdown{total: time.Second}, wantWarn: true},
+		{name: "warning disabled", opts: []Option{WithInitDurationWarning(0)}, initType: InitOnDemand, breakdown: slow},
+		{name: "provisioned concurrency", initType: InitProvisionedConcurrency, breakdown: slow},
+		{name: "SnapStart", initType: InitSnapStart, breakdown: slow},
+		{name: "limit exceeded", opts: []Option{WithInitDurationWarning(0), WithInitDurationLimit(5 * time.Second)}, initType: InitOnDemand, breakdown: slow, wantWarn: true, wantErr: true},
+		{name: "within limit", opts: []Option{WithInitDurationLimit(10 * time.Second)}, initType: InitOnDemand, breakdown: slow, wantWarn: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			opts := &options{logger: slog.New(slog.NewJSONHandler(&logs, nil))}
+			for _, opt := range tt.opts {
+				opt(opts)
+			}
+
+			err := checkInitDuration(opts, tt.initType, tt.breakdown)
+			if tt.wantErr {
+				var errResp *ErrorResponse
+				require.ErrorAs(t, err, &errResp)
+				assert.Equal(t, "Runtime.InitDurationExceeded", errResp.Type)
+				assert.Contains(t, errResp.Message, "exceeding the configured limit of 5s")
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if !tt.wantWarn {
+				assert.Empty(t, logs.String())
+				return
+			}
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+			assert.Equal(t, "WARN", record["level"])
+			assert.Equal(t, "slow initialization", record["msg"])
+			assert.InDelta(t, float64(tt.breakdown.total), record["initDuration"], 0)
+		})
+	}
+}
+
+func TestCheckInitDuration_ExtensionBreakdown(t *testing.T) {
+	var logs bytes.Buffer
+	opts := &options{logger: slog.New(slog.NewJSONHandler(&logs, nil))}
+
+	require.NoError(t, checkInitDuration(opts, InitOnDemand, initBreakdown{
+		total:       9 * time.Second,
+		beforeStart: 2 * time.Second,
+		extensions:  []extensionInitDuration{{name: "slow", duration: 6 * time.Second}},
+	}))
+
+	var record struct {
+		BeforeStart   time.Duration            `json:"beforeStart"`
+		InitTimeout   time.Duration            `json:"initTimeout"`
+		ExtensionInit map[string]time.Duration `json:"extensionInit"`
+	}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+	assert.Equal(t, 2*time.Second, record.BeforeStart)
+	assert.Equal(t, lambdaInitTimeout, record.InitTimeout)
+	assert.Equal(t, map[string]time.Duration{"slow": 6 * time.Second}, record.ExtensionInit)
+}
@@ -0,0 +1,72 @@
+package voker
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// InitializationType identifies how Lambda initialized the execution
+// environment, as reported by AWS_LAMBDA_INITIALIZATION_TYPE.
+type InitializationType string
+
+const (
+	InitOnDemand               InitializationType = "on-demand"
+	InitProvisionedConcurrency InitializationType = "provisioned-concurrency"
+	InitSnapStart              InitializationType = "snap-start"
+	InitManagedInstances       InitializationType = "lambda-managed-instances"
+)
+
+// InitType returns how Lambda initialized this execution environment. It is
+// empty outside Lambda.
+func InitType() InitializationType {
+	return InitializationType(os.Getenv(lambdaEnvInitializationType))
+}
+
+func (o *options) initType() InitializationType {
+	return InitializationType(getenv(o.env, lambdaEnvInitializationType))
+}
+
+// WithProvisionedWarmup registers a hook that runs during initialization of
+// provisioned concurrency environments, before the first invocation.
+// Provisioned environments are initialized ahead of traffic, so work that
+// on-demand environments defer to the first request, such as priming caches
+// or opening connection pools, can be front-loaded here instead.
+//
+// Hooks run in registration order. An error fails initialization. In other
+// environments the hooks never run.
+func WithProvisionedWarmup(hook func(ctx context.Context) error) Option {
+	return func(o *options) {
+		o.provisionedWarmup = append(o.provisionedWarmup, hook)
+	}
+}
+
+func runProvisionedWarmup(ctx context.Context, client *runtimeClient, options *options) error {
+	for _, hook := range options.provisionedWarmup {
+		if err := callRuntimeHook(ctx, "provisioned warmup", hook, options.fullStackPaths); err != nil {
+			reportInitError(client, err, options.logger)
+			return err
+		}
+	}
+	return nil
+}
+
+// callRuntimeHook runs an initialization hook, converting a panic into an
+// error so it can be reported to Lambda.
+func callRuntimeHook(ctx context.Context, phase string, hook func(context.Context) error, fullStackPaths bool) (responseErr error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			response := newPanicResponse(recovered, fullStackPaths)
+			response.Message = fmt.Sprintf("%s hook panicked: %s", phase, response.Message)
+			responseErr = response
+		}
+	}()
+
+	if err := hook(ctx); err != nil {
+		original := newErrorResponse(err)
+		response := *original
+		response.Message = fmt.Sprintf("%s hook failed: %s", phase, original.Message)
+		return &response
+	}
+	return nil
+}
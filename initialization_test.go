@@ -0,0 +1,67 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitType(t *testing.T) {
+	t.Setenv(lambdaEnvInitializationType, "provisioned-concurrency")
+	assert.Equal(t, InitProvisionedConcurrency, InitType())
+
+	t.Setenv(lambdaEnvInitializationType, "")
+	assert.Empty(t, InitType())
+}
+
+func TestRunProvisionedWarmup(t *testing.T) {
+	var calls []string
+	opts := &options{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	WithProvisionedWarmup(func(ctx context.Context) error {
+		calls = append(calls, "prime cache")
+		return nil
+	})(opts)
+	WithProvisionedWarmup(func(ctx context.Context) error {
+		calls = append(calls, "open pool")
+		return nil
+	})(opts)
+
+	require.NoError(t, runProvisionedWarmup(context.Background(), nil, opts))
+	assert.Equal(t, []string{"prime cache", "open pool"}, calls)
+}
+
+func TestRunProvisionedWarmup_Error(t *testing.T) {
+	var errorBody ErrorResponse
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2018-06-01/runtime/init/error", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &errorBody))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := newRuntimeClient(server.Listener.Addr().String(), logger)
+	opts := &options{logger: logger}
+	WithProvisionedWarmup(func(context.Context) error {
+		return errors.New("cache unavailable")
+	})(opts)
+	WithProvisionedWarmup(func(context.Context) error {
+		t.Error("later hooks must not run after a failure")
+		return nil
+	})(opts)
+
+	err := runProvisionedWarmup(context.Background(), client, opts)
+	require.Error(t, err)
+	assert.Equal(t, "provisioned warmup hook failed: cache unavailable", errorBody.Message)
+	assert.Equal(t, "HandlerError", errorBody.Type)
+}
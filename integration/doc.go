@@ -0,0 +1,13 @@
+// Package integration runs the examples under the AWS Lambda Runtime
+// Interface Emulator (RIE) and invokes them end to end. It catches protocol
+// regressions in headers, status codes, and error shapes that the httptest
+// fakes in the unit tests can't.
+//
+// The tests are opt-in: they need Docker and build only with the integration
+// tag:
+//
+//	go test -tags integration ./integration
+//
+// VOKER_RIE_IMAGE overrides the emulator image, which defaults to
+// public.ecr.aws/lambda/provided:al2023.
+package integration
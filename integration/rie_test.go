@@ -0,0 +1,176 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	defaultRIEImage = "public.ecr.aws/lambda/provided:al2023"
+	invocationsPath = "/2015-03-31/functions/function/invocations"
+)
+
+// rieContainer is a running emulator container serving one bootstrap binary.
+type rieContainer struct {
+	id       string
+	endpoint string
+}
+
+// buildExample cross-compiles the example in examples/name for Linux on the
+// host architecture and returns the path of its bootstrap binary.
+func buildExample(t *testing.T, name string) string {
+	t.Helper()
+
+	dir, err := filepath.Abs(filepath.Join("..", "examples", name))
+	require.NoError(t, err)
+	bootstrap := filepath.Join(t.TempDir(), "bootstrap")
+
+	cmd := exec.Command("go", "build", "-trimpath", "-o", bootstrap, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS=linux", "GOARCH="+runtime.GOARCH)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("building example %s: %v\n%s", name, err, out)
+	}
+	return bootstrap
+}
+
+// startRIE builds the example in examples/name, runs it under the emulator,
+// and waits until the emulator accepts invocations. The container is removed
+// when the test ends.
+func startRIE(t *testing.T, name string) *rieContainer {
+	t.Helper()
+
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker is not installed")
+	}
+	bootstrap := buildExample(t, name)
+
+	image := os.Getenv("VOKER_RIE_IMAGE")
+	if image == "" {
+		image = defaultRIEImage
+	}
+
+	args := []string{
+		"run", "--detach", "--rm",
+		"--platform", "linux/" + runtime.GOARCH,
+		"--publish", "127.0.0.1::8080",
+		"--volume", bootstrap + ":/var/runtime/bootstrap:ro",
+	}
+	args = append(args, image, "bootstrap")
+
+	id := strings.TrimSpace(docker(t, args...))
+	t.Cleanup(func() {
+		if t.Failed() {
+			logs, _ := exec.Command("docker", "logs", id).CombinedOutput()
+			t.Logf("container logs:\n%s", logs)
+		}
+		_ = exec.Command("docker", "rm", "--force", id).Run()
+	})
+
+	// docker port prints one line per address family, such as
+	// "127.0.0.1:49153".
+	addr, _, _ := strings.Cut(strings.TrimSpace(docker(t, "port", id, "8080/tcp")), "\n")
+	c := &rieContainer{id: id, endpoint: "http://" + addr + invocationsPath}
+	c.waitReady(t)
+	return c
+}
+
+func docker(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("docker", args...).Output()
+	if err != nil {
+		var stderr []byte
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			stderr = exitErr.Stderr
+		}
+		t.Fatalf("docker %s: %v\n%s", strings.Join(args, " "), err, stderr)
+	}
+	return string(out)
+}
+
+// waitReady polls until the emulator's HTTP listener accepts connections.
+// The emulator starts the bootstrap on the first invocation, so this doesn't
+// invoke the function.
+func (c *rieContainer) waitReady(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		resp, err := http.Get(c.endpoint)
+		if err == nil {
+			resp.Body.Close()
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("emulator did not start: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// invoke posts payload to the emulator and returns the response and its body.
+func (c *rieContainer) invoke(t *testing.T, payload string) (*http.Response, []byte) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewBufferString(payload))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
+func TestBasic(t *testing.T) {
+	rie := startRIE(t, "basic")
+
+	requestIDs := map[string]bool{}
+	for i := range 3 {
+		resp, body := rie.invoke(t, fmt.Sprintf(`{"attempt":%d}`, i))
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+		var got struct {
+			RequestID string `json:"requestId"`
+		}
+		require.NoError(t, json.Unmarshal(body, &got), string(body))
+		assert.NotEmpty(t, got.RequestID)
+		requestIDs[got.RequestID] = true
+	}
+	assert.Len(t, requestIDs, 3, "each invocation should see its own request ID")
+}
+
+func TestError(t *testing.T) {
+	rie := startRIE(t, "error")
+
+	resp, body := rie.invoke(t, `{"name":"voker"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	var got struct {
+		ErrorMessage string            `json:"errorMessage"`
+		ErrorType    string            `json:"errorType"`
+		StackTrace   []json.RawMessage `json:"stackTrace"`
+	}
+	require.NoError(t, json.Unmarshal(body, &got), string(body))
+	assert.True(t, strings.HasPrefix(got.ErrorType, "Runtime.Panic"), got.ErrorType)
+	assert.Contains(t, got.ErrorMessage, "index out of range")
+	assert.NotEmpty(t, got.StackTrace)
+}
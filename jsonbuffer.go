@@ -0,0 +1,52 @@
+package voker
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// maxPooledBufferSize caps the capacity of buffers returned to the pool so
+// one multi-megabyte response doesn't pin that memory for the life of the
+// process.
+const maxPooledBufferSize = 1 << 20
+
+// jsonBuffer is a reusable JSON encoding buffer. Responses are encoded into it
+// and POSTed to the Runtime API straight from its bytes, so the encoded
+// payload is never copied into a separate slice.
+type jsonBuffer struct {
+	bytes.Buffer
+	enc *json.Encoder
+}
+
+var jsonBufferPool = sync.Pool{
+	New: func() any {
+		b := &jsonBuffer{}
+		b.enc = json.NewEncoder(&b.Buffer)
+		return b
+	},
+}
+
+// marshalJSON encodes v like json.Marshal, adjusted by encoder, into a pooled
+// buffer. The caller must call release once it no longer needs the buffer's
+// bytes.
+func marshalJSON(v any, encoder JSONEncoderOptions) (*jsonBuffer, error) {
+	b := jsonBufferPool.Get().(*jsonBuffer)
+	b.Reset()
+	encoder.configure(b.enc)
+	if err := b.enc.Encode(v); err != nil {
+		b.release()
+		return nil, err
+	}
+	// Encode terminates each value with a newline that json.Marshal omits.
+	b.Truncate(b.Len() - 1)
+	return b, nil
+}
+
+// release returns b to the pool. It is safe to call on a nil buffer.
+func (b *jsonBuffer) release() {
+	if b == nil || b.Cap() > maxPooledBufferSize {
+		return
+	}
+	jsonBufferPool.Put(b)
+}
@@ -0,0 +1,50 @@
+package voker
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalJSON_MatchesMarshal(t *testing.T) {
+	for _, v := range []any{
+		testResponse{Message: "<b>hello</b> & goodbye"},
+		map[string]any{"b": 1, "a": []int{1, 2}},
+		"plain",
+		nil,
+	} {
+		want, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		buf, err := marshalJSON(v, JSONEncoderOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, string(want), buf.String())
+		buf.release()
+	}
+}
+
+func TestMarshalJSON_Error(t *testing.T) {
+	buf, err := marshalJSON(func() {}, JSONEncoderOptions{})
+	require.Error(t, err)
+	assert.Nil(t, buf)
+
+	_, wantErr := json.Marshal(func() {})
+	assert.Equal(t, wantErr.Error(), err.Error())
+}
+
+func TestJSONBuffer_Release(t *testing.T) {
+	var nilBuf *jsonBuffer
+	assert.NotPanics(t, nilBuf.release)
+
+	large, err := marshalJSON(strings.Repeat("x", maxPooledBufferSize), JSONEncoderOptions{})
+	require.NoError(t, err)
+	large.release()
+
+	buf, err := marshalJSON("small", JSONEncoderOptions{})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, buf.Cap(), maxPooledBufferSize, "oversized buffers must not be pooled")
+	buf.release()
+}
@@ -0,0 +1,137 @@
+package voker
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// leakDetector tracks the goroutines alive after each invocation.
+type leakDetector struct {
+	mu sync.Mutex
+	// sites counts goroutines by creation site at the highest goroutine
+	// count seen so far. It is nil until the first invocation finishes.
+	sites map[string]int
+	peak  int
+}
+
+// WithGoroutineLeakDetection snapshots the process's goroutines after every
+// invocation and logs a "goroutine count grew" warning when more are alive
+// than after any earlier invocation. The warning lists the creation stack of
+// each call site that started the extra goroutines, pointing at workers
+// that never exit and tickers that are never stopped, which otherwise
+// slowly degrade warm execution environments:
+//
+//	{"level":"WARN","msg":"goroutine count grew","goroutines":14,
+//	 "previous":12,"created":"2 goroutines created by main.handler\n\t/src/main.go:31"}
+//
+// The first invocation sets the baseline, so connection pools and other
+// goroutines started lazily on first use are not reported. Snapshots dump
+// every goroutine's stack, which costs more the more goroutines there are,
+// so enable it while diagnosing rather than permanently. With
+// [MaxConcurrency] above one, goroutines of invocations still in flight
+// are counted too.
+func WithGoroutineLeakDetection() Option {
+	return func(o *options) {
+		o.leaks = &leakDetector{}
+	}
+}
+
+// checkGoroutineLeaks compares the goroutines alive now with the highest
+// count seen after an earlier invocation.
+func (o *options) checkGoroutineLeaks(ctx context.Context) {
+	if o.leaks == nil {
+		return
+	}
+	sites := goroutineSites(goroutineDump())
+	total := 0
+	for _, n := range sites {
+		total += n
+	}
+
+	d := o.leaks
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.sites == nil {
+		d.sites, d.peak = sites, total
+		return
+	}
+	if total <= d.peak {
+		return
+	}
+
+	o.logger.WarnContext(ctx, "goroutine count grew",
+		"goroutines", total,
+		"previous", d.peak,
+		"created", formatSiteGrowth(d.sites, sites),
+	)
+	d.sites, d.peak = sites, total
+}
+
+// goroutineSites counts the goroutines in a stack dump by the function and
+// location that created them. Goroutines without a creator, such as the
+// main goroutine, are counted under the empty site.
+func goroutineSites(dump []byte) map[string]int {
+	sites := map[string]int{}
+	for stack := range bytes.SplitSeq(dump, []byte("\n\n")) {
+		if !bytes.HasPrefix(stack, []byte("goroutine ")) {
+			continue
+		}
+		sites[creationSite(string(stack))]++
+	}
+	return sites
+}
+
+// creationSite returns the "created by" frame of one goroutine's stack,
+// without the program counter offset.
+func creationSite(stack string) string {
+	_, created, ok := strings.Cut(stack, "\ncreated by ")
+	if !ok {
+		return ""
+	}
+	function, location, _ := strings.Cut(created, "\n")
+	location, _, _ = strings.Cut(strings.TrimSpace(location), " +0x")
+	// The creating goroutine's ID differs for every goroutine started from
+	// a per-invocation goroutine, so leave it out of the site.
+	function, _, _ = strings.Cut(function, " in goroutine ")
+	return function + "\n\t" + location
+}
+
+// formatSiteGrowth describes the creation sites with more goroutines in
+// current than in previous, the largest increases first.
+func formatSiteGrowth(previous, current map[string]int) string {
+	type growth struct {
+		site  string
+		added int
+	}
+	var grown []growth
+	for site, n := range current {
+		if added := n - previous[site]; added > 0 {
+			grown = append(grown, growth{site, added})
+		}
+	}
+	slices.SortFunc(grown, func(a, b growth) int {
+		return cmp.Or(b.added-a.added, strings.Compare(a.site, b.site))
+	})
+
+	var b strings.Builder
+	for i, g := range grown {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		noun := "goroutines"
+		if g.added == 1 {
+			noun = "goroutine"
+		}
+		if g.site == "" {
+			fmt.Fprintf(&b, "%d %s without a creator", g.added, noun)
+			continue
+		}
+		fmt.Fprintf(&b, "%d %s created by %s", g.added, noun, g.site)
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package voker
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testGoroutineDump = `goroutine 1 [running]:
+main.main()
+	/src/main.go:10 +0x1d
+
+goroutine 7 [chan receive]:
+main.worker()
+	/src/main.go:20 +0x25
+created by main.handler in goroutine 6
+	/src/main.go:31 +0x4f
+
+goroutine 8 [chan receive]:
+main.worker()
+	/src/main.go:20 +0x25
+created by main.handler in goroutine 9
+	/src/main.go:31 +0x4f
+`
+
+func TestGoroutineSites(t *testing.T) {
+	sites := goroutineSites([]byte(testGoroutineDump))
+	assert.Equal(t, map[string]int{
+		"":                                1,
+		"main.handler\n\t/src/main.go:31": 2,
+	}, sites)
+}
+
+func TestFormatSiteGrowth(t *testing.T) {
+	previous := map[string]int{"a\n\t/a.go:1": 1, "b\n\t/b.go:2": 3}
+	current := map[string]int{"a\n\t/a.go:1": 4, "b\n\t/b.go:2": 2, "c\n\t/c.go:3": 1, "": 1}
+
+	assert.Equal(t,
+		"3 goroutines created by a\n\t/a.go:1\n"+
+			"1 goroutine without a creator\n"+
+			"1 goroutine created by c\n\t/c.go:3",
+		formatSiteGrowth(previous, current))
+}
+
+func leakTestWorker(stop <-chan struct{}) {
+	<-stop
+}
+
+func TestCheckGoroutineLeaks(t *testing.T) {
+	logs := &syncBuffer{}
+	options := &options{logger: slog.New(slog.NewJSONHandler(logs, nil))}
+	WithGoroutineLeakDetection()(options)
+	ctx := context.Background()
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	options.checkGoroutineLeaks(ctx)
+	options.checkGoroutineLeaks(ctx)
+	assert.Empty(t, logs.String(), "the first invocation sets the baseline")
+
+	for range 3 {
+		go leakTestWorker(stop)
+	}
+	options.checkGoroutineLeaks(ctx)
+
+	output := logs.String()
+	assert.Contains(t, output, `"msg":"goroutine count grew"`)
+	assert.Contains(t, output, "3 goroutines created by github.com/hotsock/voker.TestCheckGoroutineLeaks")
+	assert.Contains(t, output, "leak_test.go")
+}
+
+func TestCheckGoroutineLeaks_Disabled(t *testing.T) {
+	logs := &syncBuffer{}
+	options := &options{logger: slog.New(slog.NewJSONHandler(logs, nil))}
+
+	options.checkGoroutineLeaks(context.Background())
+	assert.Empty(t, logs.String())
+}
@@ -24,13 +24,13 @@ const (
 // Note: Voker's internal logs only emit ERROR level messages. The log level
 // setting allows filtering of these messages or logs from user code that
 // uses the same logger instance.
-func defaultLogger() *slog.Logger {
+func defaultLogger(e env) *slog.Logger {
 	opts := &slog.HandlerOptions{
-		Level: loggerLevelFromLambdaEnv(),
+		Level: loggerLevelFromLambdaEnv(e),
 	}
 
 	var handler slog.Handler
-	if os.Getenv(lambdaEnvLogFormat) == "JSON" {
+	if getenv(e, lambdaEnvLogFormat) == "JSON" {
 		handler = slog.NewJSONHandler(os.Stderr, opts)
 	} else {
 		handler = slog.NewTextHandler(os.Stderr, opts)
@@ -39,8 +39,8 @@ func defaultLogger() *slog.Logger {
 	return slog.New(handler)
 }
 
-func loggerLevelFromLambdaEnv() slog.Level {
-	return loggerLevelFromString(os.Getenv(lambdaEnvLogLevel))
+func loggerLevelFromLambdaEnv(e env) slog.Level {
+	return loggerLevelFromString(getenv(e, lambdaEnvLogLevel))
 }
 
 // Supports: trace, debug, info, warn, error, fatal.
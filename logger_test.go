@@ -113,7 +113,7 @@ func TestLoggerLevelFromLambdaEnv(t *testing.T) {
 				os.Setenv(lambdaEnvLogLevel, tt.envValue)
 			}
 
-			result := loggerLevelFromLambdaEnv()
+			result := loggerLevelFromLambdaEnv(nil)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -167,7 +167,7 @@ func TestDefaultLogger_Format(t *testing.T) {
 				os.Setenv(lambdaEnvLogFormat, tt.logFormat)
 			}
 
-			logger := defaultLogger()
+			logger := defaultLogger(nil)
 			assert.NotNil(t, logger, tt.description)
 		})
 	}
@@ -0,0 +1,283 @@
+package voker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LogSink receives batches of log records from a [LogShipper]. The
+// vokerlogs package provides an HTTP sink, and vokeraws provides Kinesis,
+// Firehose, and S3 sinks.
+type LogSink interface {
+	WriteLogs(ctx context.Context, events []TelemetryEvent) error
+}
+
+// ErrLogBufferFull is reported to [LogShipper].OnError when log records are
+// dropped because the sink can't keep up.
+var ErrLogBufferFull = errors.New("log buffer is full")
+
+const (
+	logShipperName                 = "voker-log-shipper"
+	defaultLogShipperBatchSize     = 500
+	defaultLogShipperBufferSize    = 10000
+	defaultLogShipperFlushInterval = time.Second
+	defaultLogShipperMaxAttempts   = 3
+	logShipperRetryDelay           = 100 * time.Millisecond
+)
+
+// LogShipper configures an internal extension that subscribes to the
+// function's logs through the Telemetry API and delivers them in batches to
+// a [LogSink]. Register it with [WithLogShipper].
+type LogShipper struct {
+	// Sink receives the batches (required).
+	Sink LogSink
+
+	// Name is the extension name. The default is "voker-log-shipper".
+	Name string
+
+	// Types lists the Telemetry API streams to ship. The default is
+	// [TelemetryFunction].
+	Types []TelemetryType
+
+	// BatchSize is the most records passed to one WriteLogs call. The
+	// default is 500.
+	BatchSize int
+
+	// BufferSize is the most records held while the sink catches up. When
+	// the buffer is full, deliveries from Lambda wait for room, which makes
+	// Lambda buffer on its side, and are dropped if Lambda gives up. The
+	// default is 10,000.
+	BufferSize int
+
+	// FlushInterval is how long records wait for a full batch before they
+	// are written anyway. The default is 1 second.
+	FlushInterval time.Duration
+
+	// MaxAttempts is how many times a batch is written before it is
+	// dropped. Retries back off exponentially from 100ms. The default is 3.
+	MaxAttempts int
+
+	// OnError is called with each error that drops records (optional). It
+	// should not write to stdout or stderr, whose lines would be shipped
+	// too.
+	OnError func(err error)
+
+	// RegisterTimeout and FailurePolicy are passed to the extension; see
+	// [InternalExtension]. Set FailurePolicy to [ExtensionContinue] to
+	// start the function without log shipping when the Telemetry API is
+	// unavailable.
+	RegisterTimeout time.Duration
+	FailurePolicy   ExtensionFailurePolicy
+}
+
+// WithLogShipper registers shipper's internal extension. Records are
+// written in the background while the execution environment runs, and
+// Lambda freezes the environment between invocations, so the logs of an
+// invocation may be written during the next one. When Lambda sends SIGTERM,
+// the records still buffered are written once each within the 500ms
+// shutdown deadline. Like other internal extensions, log shippers are not
+// supported on Lambda Managed Instances.
+//
+//	voker.Start(handler, voker.WithLogShipper(voker.LogShipper{
+//	    Sink: vokerlogs.NewHTTPSink("https://logs.example.com/ingest"),
+//	}))
+func WithLogShipper(shipper LogShipper) Option {
+	return WithInternalExtension(newLogShipper(shipper).extension())
+}
+
+type logShipper struct {
+	config LogShipper
+
+	mu  sync.Mutex
+	buf []TelemetryEvent
+	// space is closed and replaced whenever records leave the buffer.
+	space chan struct{}
+
+	notify  chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+func newLogShipper(config LogShipper) *logShipper {
+	if config.Name == "" {
+		config.Name = logShipperName
+	}
+	if len(config.Types) == 0 {
+		config.Types = []TelemetryType{TelemetryFunction}
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultLogShipperBatchSize
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaultLogShipperBufferSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultLogShipperFlushInterval
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultLogShipperMaxAttempts
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &logShipper{
+		config:  config,
+		space:   make(chan struct{}),
+		notify:  make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+func (s *logShipper) extension() InternalExtension {
+	return InternalExtension{
+		Name: s.config.Name,
+		OnInit: func() error {
+			if s.config.Sink == nil {
+				return errors.New("log shipper has no sink")
+			}
+			go s.run()
+			return nil
+		},
+		OnTelemetry: s.receive,
+		Telemetry:   TelemetrySubscription{Types: s.config.Types},
+		OnSIGTERM:   s.shutdown,
+
+		RegisterTimeout: s.config.RegisterTimeout,
+		FailurePolicy:   s.config.FailurePolicy,
+	}
+}
+
+// receive buffers a delivery, waiting for room while the buffer is full.
+func (s *logShipper) receive(ctx context.Context, events []TelemetryEvent) {
+	s.mu.Lock()
+	for len(s.buf) > 0 && len(s.buf)+len(events) > s.config.BufferSize {
+		space := s.space
+		s.mu.Unlock()
+		select {
+		case <-space:
+		case <-ctx.Done():
+			s.report(fmt.Errorf("dropped %d log records: %w", len(events), ErrLogBufferFull))
+			return
+		}
+		s.mu.Lock()
+	}
+	s.buf = append(s.buf, events...)
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *logShipper) run() {
+	defer close(s.stopped)
+	for {
+		batch, ok := s.nextBatch()
+		if !ok {
+			return
+		}
+		s.write(s.ctx, batch, s.config.MaxAttempts)
+	}
+}
+
+// nextBatch waits for a full batch, or for the flush interval to pass once
+// records are buffered. It returns false when the shipper stops.
+func (s *logShipper) nextBatch() ([]TelemetryEvent, bool) {
+	var flush <-chan time.Time
+	for {
+		s.mu.Lock()
+		buffered := len(s.buf)
+		s.mu.Unlock()
+		if buffered >= s.config.BatchSize {
+			return s.take(), true
+		}
+		if buffered > 0 && flush == nil {
+			timer := time.NewTimer(s.config.FlushInterval)
+			defer timer.Stop()
+			flush = timer.C
+		}
+
+		select {
+		case <-s.stop:
+			return nil, false
+		case <-s.notify:
+		case <-flush:
+			return s.take(), true
+		}
+	}
+}
+
+// take removes up to a batch of records from the buffer.
+func (s *logShipper) take() []TelemetryEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := min(len(s.buf), s.config.BatchSize)
+	batch := s.buf[:n:n]
+	s.buf = append([]TelemetryEvent(nil), s.buf[n:]...)
+	close(s.space)
+	s.space = make(chan struct{})
+	return batch
+}
+
+// write passes batch to the sink, retrying failures until attempts run out
+// or ctx is done.
+func (s *logShipper) write(ctx context.Context, batch []TelemetryEvent, attempts int) {
+	delay := logShipperRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := s.config.Sink.WriteLogs(ctx, batch)
+		if err == nil {
+			return
+		}
+		if attempt >= attempts || ctx.Err() != nil {
+			s.report(fmt.Errorf("dropped %d log records: %w", len(batch), err))
+			return
+		}
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			s.report(fmt.Errorf("dropped %d log records: %w", len(batch), context.Cause(ctx)))
+			return
+		}
+	}
+}
+
+// shutdown stops the background writer and writes the remaining records,
+// each batch once, until ctx is done.
+func (s *logShipper) shutdown(ctx context.Context) {
+	close(s.stop)
+	select {
+	case <-s.stopped:
+	case <-ctx.Done():
+		s.cancel()
+		<-s.stopped
+	}
+	defer s.cancel()
+
+	for {
+		s.mu.Lock()
+		buffered := len(s.buf)
+		s.mu.Unlock()
+		if buffered == 0 {
+			return
+		}
+		if ctx.Err() != nil {
+			s.report(fmt.Errorf("dropped %d log records: %w", buffered, context.Cause(ctx)))
+			return
+		}
+		s.write(ctx, s.take(), 1)
+	}
+}
+
+func (s *logShipper) report(err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(err)
+	}
+}
@@ -0,0 +1,176 @@
+package voker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingLogSink struct {
+	mu      sync.Mutex
+	batches [][]TelemetryEvent
+	// failures is the number of WriteLogs calls that fail before the
+	// sink starts succeeding.
+	failures int
+	calls    int
+	written  chan struct{}
+}
+
+func newRecordingLogSink() *recordingLogSink {
+	return &recordingLogSink{written: make(chan struct{}, 100)}
+}
+
+func (s *recordingLogSink) WriteLogs(ctx context.Context, events []TelemetryEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("sink unavailable")
+	}
+	s.batches = append(s.batches, events)
+	s.written <- struct{}{}
+	return nil
+}
+
+func (s *recordingLogSink) records() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var records []string
+	for _, batch := range s.batches {
+		for _, event := range batch {
+			records = append(records, string(event.Record))
+		}
+	}
+	return records
+}
+
+func logEvents(records ...string) []TelemetryEvent {
+	events := make([]TelemetryEvent, len(records))
+	for i, record := range records {
+		events[i] = TelemetryEvent{Type: "function", Record: []byte(record)}
+	}
+	return events
+}
+
+func startLogShipper(t *testing.T, config LogShipper) *logShipper {
+	t.Helper()
+	shipper := newLogShipper(config)
+	require.NoError(t, shipper.extension().OnInit())
+	return shipper
+}
+
+func TestWithLogShipper(t *testing.T) {
+	opts := &options{}
+	WithLogShipper(LogShipper{Sink: newRecordingLogSink()})(opts)
+
+	require.Len(t, opts.extensions, 1)
+	ext := opts.extensions[0]
+	assert.Equal(t, "voker-log-shipper", ext.Name)
+	assert.NotNil(t, ext.OnTelemetry)
+	assert.NotNil(t, ext.OnSIGTERM)
+	assert.Equal(t, []TelemetryType{TelemetryFunction}, ext.Telemetry.Types)
+
+	assert.EqualError(t, newLogShipper(LogShipper{}).extension().OnInit(), "log shipper has no sink")
+
+	ext = newLogShipper(LogShipper{RegisterTimeout: time.Second, FailurePolicy: ExtensionContinue}).extension()
+	assert.Equal(t, time.Second, ext.RegisterTimeout)
+	assert.Equal(t, ExtensionContinue, ext.FailurePolicy)
+}
+
+func TestLogShipper_Batches(t *testing.T) {
+	sink := newRecordingLogSink()
+	shipper := startLogShipper(t, LogShipper{Sink: sink, BatchSize: 2, FlushInterval: time.Hour})
+
+	shipper.receive(context.Background(), logEvents(`"a"`, `"b"`, `"c"`))
+	<-sink.written
+	assert.Equal(t, []string{`"a"`, `"b"`}, sink.records())
+
+	// The partial batch waits for the flush interval or shutdown.
+	shipper.shutdown(context.Background())
+	assert.Equal(t, []string{`"a"`, `"b"`, `"c"`}, sink.records())
+}
+
+func TestLogShipper_FlushInterval(t *testing.T) {
+	sink := newRecordingLogSink()
+	shipper := startLogShipper(t, LogShipper{Sink: sink, FlushInterval: 10 * time.Millisecond})
+	defer shipper.shutdown(context.Background())
+
+	shipper.receive(context.Background(), logEvents(`"a"`))
+	select {
+	case <-sink.written:
+	case <-time.After(time.Second):
+		t.Fatal("records were not flushed")
+	}
+	assert.Equal(t, []string{`"a"`}, sink.records())
+}
+
+func TestLogShipper_Retries(t *testing.T) {
+	sink := newRecordingLogSink()
+	sink.failures = 2
+	var errs []error
+	shipper := startLogShipper(t, LogShipper{Sink: sink, BatchSize: 1, OnError: func(err error) { errs = append(errs, err) }})
+
+	shipper.receive(context.Background(), logEvents(`"a"`))
+	<-sink.written
+	shipper.shutdown(context.Background())
+	assert.Equal(t, []string{`"a"`}, sink.records())
+	assert.Equal(t, 3, sink.calls)
+	assert.Empty(t, errs)
+}
+
+func TestLogShipper_DropsAfterMaxAttempts(t *testing.T) {
+	sink := newRecordingLogSink()
+	sink.failures = 1
+	errs := make(chan error, 1)
+	shipper := startLogShipper(t, LogShipper{Sink: sink, BatchSize: 1, MaxAttempts: 1, OnError: func(err error) { errs <- err }})
+
+	shipper.receive(context.Background(), logEvents(`"a"`))
+	assert.EqualError(t, <-errs, "dropped 1 log records: sink unavailable")
+	shipper.shutdown(context.Background())
+	assert.Empty(t, sink.records())
+}
+
+func TestLogShipper_Backpressure(t *testing.T) {
+	sink := newRecordingLogSink()
+	var errs []error
+	shipper := newLogShipper(LogShipper{Sink: sink, BufferSize: 2, FlushInterval: time.Hour, OnError: func(err error) { errs = append(errs, err) }})
+
+	// Without a running writer the buffer never drains, so the delivery
+	// waits until Lambda gives up.
+	shipper.receive(context.Background(), logEvents(`"a"`, `"b"`))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	shipper.receive(ctx, logEvents(`"c"`))
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrLogBufferFull)
+
+	// Taking a batch makes room for waiting deliveries.
+	delivered := make(chan struct{})
+	go func() {
+		shipper.receive(context.Background(), logEvents(`"d"`))
+		close(delivered)
+	}()
+	assert.Len(t, shipper.take(), 2)
+	<-delivered
+	assert.Len(t, shipper.buf, 1)
+}
+
+func TestLogShipper_ShutdownDeadline(t *testing.T) {
+	sink := newRecordingLogSink()
+	sink.failures = 100
+	var errs []error
+	shipper := startLogShipper(t, LogShipper{Sink: sink, BatchSize: 1, FlushInterval: time.Hour, OnError: func(err error) { errs = append(errs, err) }})
+
+	shipper.receive(context.Background(), logEvents(`"a"`, `"b"`))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	shipper.shutdown(ctx)
+
+	assert.Empty(t, sink.records())
+	assert.NotEmpty(t, errs)
+}
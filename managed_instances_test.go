@@ -41,7 +41,7 @@ func TestParseMaxConcurrency(t *testing.T) {
 }
 
 func TestValidateRuntimeConfiguration_RejectsInternalExtensionsOnManagedInstances(t *testing.T) {
-	t.Setenv(lambdaEnvInitializationType, managedInstancesInitType)
+	t.Setenv(lambdaEnvInitializationType, string(InitManagedInstances))
 	called := false
 	ext := InternalExtension{
 		Name: "unsupported",
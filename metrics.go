@@ -0,0 +1,170 @@
+package voker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// InvocationMetrics describes a single invocation reported to a
+// [MetricsSink].
+type InvocationMetrics struct {
+	// RequestID is the invocation's AWS request ID.
+	RequestID string
+
+	// TenantID is the tenant [WithTenant] resolved, or empty.
+	TenantID string
+
+	// ColdStart is true for the first invocation handled by the process.
+	ColdStart bool
+
+	// InitDuration is set for the cold start invocation to the time from
+	// process start until the runtime was ready to receive invocations. It
+	// is zero for warm invocations and in SnapStart environments. Provisioned
+	// concurrency environments initialize before they receive traffic, so
+	// their init time doesn't delay the cold start invocation.
+	InitDuration time.Duration
+
+	// Duration is the time spent decoding the payload and running the
+	// handler and its middleware.
+	Duration time.Duration
+
+	// ErrorType is the errorType reported to Lambda, or empty when the
+	// invocation succeeded.
+	ErrorType string
+
+	// RequestBytes is the size of the invocation payload.
+	RequestBytes int
+
+	// ResponseBytes is the size of the JSON response payload. It is zero for
+	// errors and streaming responses.
+	ResponseBytes int
+
+	// ResponseLatency is the time taken to deliver the result to the Lambda
+	// Runtime API. For streaming responses it includes the time spent
+	// reading the stream.
+	ResponseLatency time.Duration
+
+	// NextLatency is the round trip of the GET /runtime/invocation/next
+	// request that fetched the invocation, until its payload was read. The
+	// request blocks until Lambda has an event, so it includes time the
+	// runtime spent waiting for one, and with [WithNextPrefetch] it may have
+	// completed while the previous invocation was running.
+	NextLatency time.Duration
+}
+
+// MetricsSink receives metrics for every invocation that reaches the
+// handler. RecordInvocation is called after the result is delivered to
+// Lambda, so it does not delay the response, but it does delay the next
+// invocation and should not block. On Lambda Managed Instances it may be
+// called concurrently.
+type MetricsSink interface {
+	RecordInvocation(ctx context.Context, metrics InvocationMetrics)
+}
+
+// WithMetrics reports invocation metrics to sink. The vokermetrics package
+// provides a CloudWatch Embedded Metric Format sink, and vokerotel provides
+// an OpenTelemetry sink.
+func WithMetrics(sink MetricsSink) Option {
+	return func(o *options) {
+		o.metrics = sink
+	}
+}
+
+// invocationRecorder collects metrics for one invocation. A nil recorder,
+// used when neither a sink nor the invocation report is configured, ignores
+// every call.
+type invocationRecorder struct {
+	sink          MetricsSink
+	metrics       InvocationMetrics
+	start         time.Time
+	responseStart time.Time
+
+	// report is the logger the invocation report is written to, or nil.
+	report      *slog.Logger
+	reportMu    sync.Mutex
+	reportAttrs []slog.Attr
+}
+
+func (o *options) startMetrics(inv *invocation) *invocationRecorder {
+	if o.metrics == nil && !o.invocationReport {
+		return nil
+	}
+	var report *slog.Logger
+	if o.invocationReport {
+		report = o.logger
+	}
+	r := &invocationRecorder{
+		sink:   o.metrics,
+		report: report,
+		metrics: InvocationMetrics{
+			RequestID:    inv.requestID,
+			ColdStart:    !o.invoked.Swap(true),
+			RequestBytes: len(inv.payload),
+			NextLatency:  inv.nextLatency,
+		},
+		start: time.Now(),
+	}
+	if r.metrics.ColdStart {
+		r.metrics.InitDuration = o.initDuration
+	}
+	return r
+}
+
+type recorderContextKey struct{}
+
+// withRecorder returns a copy of ctx that carries r, so middleware can add
+// to the invocation's metrics.
+func withRecorder(ctx context.Context, r *invocationRecorder) context.Context {
+	if r == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, recorderContextKey{}, r)
+}
+
+func recorderFromContext(ctx context.Context) *invocationRecorder {
+	r, _ := ctx.Value(recorderContextKey{}).(*invocationRecorder)
+	return r
+}
+
+func (r *invocationRecorder) tenant(tenant string) {
+	if r == nil {
+		return
+	}
+	r.metrics.TenantID = tenant
+}
+
+func (r *invocationRecorder) handled(response handlerResponse, err error) {
+	if r == nil {
+		return
+	}
+	r.responseStart = time.Now()
+	r.metrics.Duration = r.responseStart.Sub(r.start)
+	r.metrics.ResponseBytes = len(response.payload)
+	if err != nil {
+		r.metrics.ErrorType = newErrorResponse(err).Type
+	}
+}
+
+// responseBytes records the size of a response that was encoded while it was
+// being uploaded.
+func (r *invocationRecorder) responseBytes(n int) {
+	if r == nil {
+		return
+	}
+	r.metrics.ResponseBytes = n
+}
+
+func (r *invocationRecorder) record(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	r.metrics.ResponseLatency = time.Since(r.responseStart)
+	if r.sink != nil {
+		r.sink.RecordInvocation(context.WithoutCancel(ctx), r.metrics)
+	}
+	if r.report != nil {
+		r.writeReport(context.WithoutCancel(ctx))
+	}
+}
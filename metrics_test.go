@@ -0,0 +1,93 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	metrics []InvocationMetrics
+	ctxErr  error
+}
+
+func (s *recordingSink) RecordInvocation(ctx context.Context, metrics InvocationMetrics) {
+	s.ctxErr = ctx.Err()
+	s.metrics = append(s.metrics, metrics)
+}
+
+func TestHandleInvocation_Metrics(t *testing.T) {
+	requests := []string{"metrics-1", "metrics-2"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			w.Header().Set(headerRequestID, requests[0])
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			requests = requests[1:]
+			time.Sleep(5 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"name":"metrics"}`))
+		default:
+			time.Sleep(5 * time.Millisecond)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	client := newRuntimeClient(server.URL[7:], logger)
+	sink := &recordingSink{}
+	options := &options{logger: logger, initDuration: 2 * time.Second}
+	WithMetrics(sink)(options)
+
+	var fail bool
+	handler := func(_ context.Context, event testEvent) (testResponse, error) {
+		time.Sleep(5 * time.Millisecond)
+		if fail {
+			return testResponse{}, &ErrorResponse{Type: "CustomError", Message: "failed"}
+		}
+		return testResponse{Message: "hello " + event.Name}, nil
+	}
+
+	require.NoError(t, handleInvocation(client, handler, options))
+	fail = true
+	require.NoError(t, handleInvocation(client, handler, options))
+
+	require.Len(t, sink.metrics, 2)
+	assert.NoError(t, sink.ctxErr)
+
+	first := sink.metrics[0]
+	assert.Equal(t, "metrics-1", first.RequestID)
+	assert.True(t, first.ColdStart)
+	assert.Equal(t, 2*time.Second, first.InitDuration)
+	assert.Empty(t, first.ErrorType)
+	assert.Equal(t, len(`{"name":"metrics"}`), first.RequestBytes)
+	assert.Equal(t, len(`{"message":"hello metrics"}`), first.ResponseBytes)
+	assert.GreaterOrEqual(t, first.Duration, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, first.ResponseLatency, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, first.NextLatency, 5*time.Millisecond)
+
+	second := sink.metrics[1]
+	assert.Equal(t, "metrics-2", second.RequestID)
+	assert.False(t, second.ColdStart)
+	assert.Zero(t, second.InitDuration)
+	assert.Equal(t, "CustomError", second.ErrorType)
+	assert.Zero(t, second.ResponseBytes)
+}
+
+func TestInvocationRecorder_Nil(t *testing.T) {
+	var recorder *invocationRecorder
+	assert.NotPanics(t, func() {
+		recorder.handled(handlerResponse{}, errors.New("ignored"))
+		recorder.record(context.Background())
+	})
+	assert.Nil(t, (&options{}).startMetrics(&invocation{payload: json.RawMessage(`{}`)}))
+}
@@ -0,0 +1,52 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// InvokeFunc handles a single invocation payload. It is the unit that
+// [Middleware] wraps: the innermost InvokeFunc decodes the payload into the
+// handler's input type and calls the handler.
+type InvokeFunc func(ctx context.Context, payload json.RawMessage) (any, error)
+
+// Middleware wraps every invocation of a handler registered with
+// [WithMiddleware]. The context carries the invocation's [LambdaContext],
+// and middleware runs before voker reports the result to Lambda, so it can
+// observe, replace, or flush around the handler's output and error:
+//
+//	func timing(next voker.InvokeFunc) voker.InvokeFunc {
+//	    return func(ctx context.Context, payload json.RawMessage) (any, error) {
+//	        start := time.Now()
+//	        output, err := next(ctx, payload)
+//	        slog.InfoContext(ctx, "invocation finished", "duration", time.Since(start))
+//	        return output, err
+//	    }
+//	}
+//
+// Panics raised by the handler propagate through middleware, which can
+// recover and re-panic to observe them. A streaming output (an io.Reader)
+// is returned to middleware before Lambda reads it.
+type Middleware func(next InvokeFunc) InvokeFunc
+
+func newInvokeFunc[TIn, TOut any](handler func(context.Context, TIn) (TOut, error), decoder inputDecoder) InvokeFunc {
+	return func(ctx context.Context, payload json.RawMessage) (any, error) {
+		input, err := unmarshalInput[TIn](payload, decoder)
+		if err != nil {
+			return nil, err
+		}
+
+		output, err := handler(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return output, nil
+	}
+}
+
+func chainMiddleware(invoke InvokeFunc, middleware []Middleware) InvokeFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		invoke = middleware[i](invoke)
+	}
+	return invoke
+}
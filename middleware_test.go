@@ -0,0 +1,101 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallHandler_MiddlewareOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next InvokeFunc) InvokeFunc {
+			return func(ctx context.Context, payload json.RawMessage) (any, error) {
+				calls = append(calls, name+" before")
+				output, err := next(ctx, payload)
+				calls = append(calls, name+" after")
+				return output, err
+			}
+		}
+	}
+	handler := func(_ context.Context, event testEvent) (testResponse, error) {
+		calls = append(calls, "handler")
+		return testResponse{Message: "Hello, " + event.Name}, nil
+	}
+
+	response, err := callHandler(context.Background(), []byte(`{"name":"world"}`), handler, &options{middleware: []Middleware{record("outer"), record("inner")}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message":"Hello, world"}`, string(response.payload))
+	assert.Equal(t, []string{"outer before", "inner before", "handler", "inner after", "outer after"}, calls)
+}
+
+func TestCallHandler_MiddlewareReplacesResult(t *testing.T) {
+	handlerErr := errors.New("failed")
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		return testResponse{}, handlerErr
+	}
+
+	var sawErr error
+	recoverError := func(next InvokeFunc) InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			_, sawErr = next(ctx, payload)
+			return map[string]string{"status": "recovered"}, nil
+		}
+	}
+
+	response, err := callHandler(context.Background(), []byte(`{}`), handler, &options{middleware: []Middleware{recoverError}})
+	require.NoError(t, err)
+	assert.ErrorIs(t, sawErr, handlerErr)
+	assert.JSONEq(t, `{"status":"recovered"}`, string(response.payload))
+}
+
+func TestCallHandler_MiddlewareErrors(t *testing.T) {
+	passthrough := func(next InvokeFunc) InvokeFunc { return next }
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		return testResponse{}, &ErrorResponse{Type: "CustomError", Message: "custom"}
+	}
+
+	_, err := callHandler(context.Background(), []byte(`{`), handler, &options{middleware: []Middleware{passthrough}})
+	errResp, ok := errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "Runtime.UnmarshalError", errResp.Type)
+
+	_, err = callHandler(context.Background(), []byte(`{}`), handler, &options{middleware: []Middleware{passthrough}})
+	errResp, ok = errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "CustomError", errResp.Type)
+}
+
+func TestCallHandler_MiddlewareObservesPanic(t *testing.T) {
+	var recovered any
+	observe := func(next InvokeFunc) InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			defer func() {
+				recovered = recover()
+				panic(recovered)
+			}()
+			return next(ctx, payload)
+		}
+	}
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		panic("boom")
+	}
+
+	_, err := callHandler(context.Background(), []byte(`{}`), handler, &options{middleware: []Middleware{observe}})
+	errResp, ok := errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.True(t, errResp.fatal)
+	assert.Equal(t, "boom", recovered)
+}
+
+func TestWithMiddleware(t *testing.T) {
+	passthrough := func(next InvokeFunc) InvokeFunc { return next }
+	options := &options{}
+	WithMiddleware(passthrough)(options)
+	WithMiddleware(passthrough, passthrough)(options)
+	assert.Len(t, options.middleware, 3)
+}
@@ -0,0 +1,67 @@
+package voker
+
+import "context"
+
+// WithNextPrefetch is an experimental option that requests the next
+// invocation as soon as a response has been posted, concurrently with the
+// work voker does after the response, such as recording metrics and writing
+// the invocation report. The Runtime API round trip then overlaps that work
+// instead of following it, which lowers per-invocation overhead for
+// latency-sensitive functions.
+//
+// Lambda may freeze the execution environment as soon as the next invocation
+// is requested, so post-response work can be paused until the next invocation
+// arrives and its output may be delivered with that invocation's logs. Work
+// still in flight when the environment is shut down is lost. Only use it when
+// the post-response work is cheap and its timing doesn't matter.
+func WithNextPrefetch() Option {
+	return func(o *options) {
+		o.prefetchNext = true
+	}
+}
+
+// nextPrefetch holds one worker's prefetched invocation. Only the worker
+// goroutine reads or writes pending.
+type nextPrefetch struct {
+	pending chan nextResult
+}
+
+type nextResult struct {
+	inv *invocation
+	err error
+}
+
+type nextPrefetchKey struct{}
+
+// withNextPrefetch returns a copy of the worker context ctx with its own
+// prefetch slot.
+func withNextPrefetch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nextPrefetchKey{}, &nextPrefetch{})
+}
+
+// nextInvocation returns the invocation prefetched by the worker owning ctx,
+// or requests one when none is pending.
+func nextInvocation(ctx context.Context, client *runtimeClient) (*invocation, error) {
+	if p, ok := ctx.Value(nextPrefetchKey{}).(*nextPrefetch); ok && p.pending != nil {
+		result := <-p.pending
+		p.pending = nil
+		return result.inv, result.err
+	}
+	return client.nextContext(ctx)
+}
+
+// prefetchNext starts requesting the next invocation for the worker owning
+// ctx. It does nothing when the worker has no prefetch slot or a request is
+// already pending. The request is canceled with ctx.
+func prefetchNext(ctx context.Context, client *runtimeClient) {
+	p, ok := ctx.Value(nextPrefetchKey{}).(*nextPrefetch)
+	if !ok || p.pending != nil {
+		return
+	}
+	pending := make(chan nextResult, 1)
+	p.pending = pending
+	go func() {
+		inv, err := client.nextContext(ctx)
+		pending <- nextResult{inv: inv, err: err}
+	}()
+}
@@ -0,0 +1,126 @@
+package voker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingSink waits for release before recording the first invocation.
+type blockingSink struct {
+	release  <-chan struct{}
+	released []bool
+}
+
+func (s *blockingSink) RecordInvocation(ctx context.Context, metrics InvocationMetrics) {
+	if len(s.released) == 0 {
+		select {
+		case <-s.release:
+			s.released = append(s.released, true)
+		case <-time.After(5 * time.Second):
+			s.released = append(s.released, false)
+		}
+		return
+	}
+	s.released = append(s.released, true)
+}
+
+func TestHandleInvocation_NextPrefetch(t *testing.T) {
+	var gets atomic.Int32
+	secondGet := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		n := gets.Add(1)
+		if n == 2 {
+			close(secondGet)
+		}
+		w.Header().Set(headerRequestID, fmt.Sprintf("req-%d", n))
+		w.Header().Set(headerDeadlineMS, "999999999999999")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.DiscardHandler)
+	client := newRuntimeClient(server.URL[7:], logger)
+	sink := &blockingSink{release: secondGet}
+	opts := &options{logger: logger}
+	WithMetrics(sink)(opts)
+	WithNextPrefetch()(opts)
+
+	var requestIDs []string
+	handler := func(ctx context.Context, _ testEvent) (testResponse, error) {
+		lc, _ := FromContext(ctx)
+		requestIDs = append(requestIDs, lc.AwsRequestID)
+		return testResponse{}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = withNextPrefetch(ctx)
+	require.NoError(t, handleInvocationContext(ctx, client, handler, opts))
+	require.NoError(t, handleInvocationContext(ctx, client, handler, opts))
+
+	assert.Equal(t, []string{"req-1", "req-2"}, requestIDs)
+	assert.Equal(t, []bool{true, true}, sink.released, "next invocation should be requested before metrics are recorded")
+}
+
+func TestHandleInvocation_NextPrefetchError(t *testing.T) {
+	var gets atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if gets.Add(1) > 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set(headerRequestID, "req-1")
+		w.Header().Set(headerDeadlineMS, "999999999999999")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.DiscardHandler)
+	client := newRuntimeClient(server.URL[7:], logger)
+	opts := &options{logger: logger}
+	WithNextPrefetch()(opts)
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		return testResponse{}, nil
+	}
+
+	ctx := withNextPrefetch(context.Background())
+	require.NoError(t, handleInvocationContext(ctx, client, handler, opts))
+	err := handleInvocationContext(ctx, client, handler, opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code from runtime API: 500")
+	assert.Equal(t, int32(2), gets.Load())
+}
+
+func TestNextInvocation_WithoutPrefetch(t *testing.T) {
+	var gets atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gets.Add(1)
+		w.Header().Set(headerRequestID, "req-1")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newRuntimeClient(server.URL[7:], slog.New(slog.DiscardHandler))
+	prefetchNext(context.Background(), client)
+	inv, err := nextInvocation(context.Background(), client)
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", inv.requestID)
+	assert.Equal(t, int32(1), gets.Load(), "a worker without a prefetch slot never prefetches")
+}
@@ -37,7 +37,7 @@ func TestCallHandler_RawMessage_VerbatimPayload(t *testing.T) {
 		return "ok", nil
 	}
 
-	out, err := callHandler(context.Background(), payload, handler)
+	out, err := callHandler(context.Background(), payload, handler, nil)
 	require.NoError(t, err)
 	assert.JSONEq(t, `"ok"`, string(out.payload))
 	assert.Equal(t, string(payload), string(got))
@@ -52,7 +52,7 @@ func TestCallHandler_RawMessage_ZeroCopyAlias(t *testing.T) {
 		return struct{}{}, nil
 	}
 
-	_, err := callHandler(context.Background(), payload, handler)
+	_, err := callHandler(context.Background(), payload, handler, nil)
 	require.NoError(t, err)
 
 	// The handler must receive the exact same backing array, not a copy.
@@ -73,7 +73,7 @@ func TestCallHandler_RawMessage_InvalidJSONNotRejected(t *testing.T) {
 		return "handled", nil
 	}
 
-	out, err := callHandler(context.Background(), payload, handler)
+	out, err := callHandler(context.Background(), payload, handler, nil)
 	require.NoError(t, err)
 	assert.True(t, called, "handler should run even with non-JSON payload")
 	assert.Equal(t, string(payload), string(got))
@@ -89,7 +89,7 @@ func TestCallHandler_RawMessage_EmptyPayload(t *testing.T) {
 		return "ok", nil
 	}
 
-	out, err := callHandler(context.Background(), []byte{}, handler)
+	out, err := callHandler(context.Background(), []byte{}, handler, nil)
 	require.NoError(t, err)
 	assert.True(t, called, "handler should run on an empty payload instead of erroring")
 	assert.Empty(t, got)
@@ -103,7 +103,7 @@ func TestCallHandler_RawMessage_NilPayload(t *testing.T) {
 		return "ok", nil
 	}
 
-	_, err := callHandler(context.Background(), nil, handler)
+	_, err := callHandler(context.Background(), nil, handler, nil)
 	require.NoError(t, err)
 	assert.Empty(t, got)
 }
@@ -120,7 +120,7 @@ func TestCallHandler_RawMessage_HandlerDecodesItself(t *testing.T) {
 		return testResponse{Message: "hello " + ev.Name}, nil
 	}
 
-	out, err := callHandler(context.Background(), payload, handler)
+	out, err := callHandler(context.Background(), payload, handler, nil)
 	require.NoError(t, err)
 	assert.JSONEq(t, `{"message":"hello voker"}`, string(out.payload))
 }
@@ -134,13 +134,62 @@ func TestCallHandler_RawMessage_PointerInputUnaffected(t *testing.T) {
 		return "ok", nil
 	}
 
-	_, err := callHandler(context.Background(), payload, handler)
+	_, err := callHandler(context.Background(), payload, handler, nil)
 	require.Error(t, err, "*json.RawMessage should not trigger the raw bypass")
 	var errResp *ErrorResponse
 	require.ErrorAs(t, err, &errResp)
 	assert.Equal(t, "Runtime.UnmarshalError", errResp.Type)
 }
 
+func TestCallHandler_Bytes_ZeroCopyAlias(t *testing.T) {
+	// Binary payloads are not JSON, and a []byte input must not be decoded as
+	// a base64 string either.
+	payload := []byte("\x00\x01binary\xff")
+
+	var got []byte
+	handler := func(ctx context.Context, in []byte) (string, error) {
+		got = in
+		return "ok", nil
+	}
+
+	out, err := callHandler(context.Background(), payload, handler, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `"ok"`, string(out.payload))
+	assert.Equal(t, payload, got)
+	assert.Equal(t, firstByte(payload), firstByte(got),
+		"[]byte input should alias the payload buffer, not copy it")
+}
+
+func TestCallHandler_Bytes_CodecSkipped(t *testing.T) {
+	payload := []byte(`{"name":"voker"}`)
+
+	var got []byte
+	handler := func(ctx context.Context, in []byte) (string, error) {
+		got = in
+		return "ok", nil
+	}
+
+	codec := &countingCodec{}
+	_, err := callHandler(context.Background(), payload, handler, &options{codec: codec})
+	require.NoError(t, err)
+	assert.Equal(t, string(payload), string(got))
+	assert.Zero(t, codec.unmarshals)
+}
+
+func TestCallHandler_Bytes_NamedTypeUnaffected(t *testing.T) {
+	// Only []byte itself triggers the bypass; named byte slices still decode.
+	type blob []byte
+	var got blob
+	handler := func(ctx context.Context, in blob) (string, error) {
+		got = in
+		return "ok", nil
+	}
+
+	_, err := callHandler(context.Background(), []byte(`"aGk="`), handler, nil)
+	require.NoError(t, err)
+	assert.Equal(t, blob("hi"), got)
+}
+
 func TestCallHandler_TypedInput_StillValidates(t *testing.T) {
 	// Regression: non-RawMessage handlers must keep rejecting invalid JSON.
 	payload := []byte(`{not json`)
@@ -150,7 +199,7 @@ func TestCallHandler_TypedInput_StillValidates(t *testing.T) {
 		return "", nil
 	}
 
-	_, err := callHandler(context.Background(), payload, handler)
+	_, err := callHandler(context.Background(), payload, handler, nil)
 	require.Error(t, err)
 	var errResp *ErrorResponse
 	require.ErrorAs(t, err, &errResp)
@@ -166,7 +215,7 @@ func TestCallHandler_TypedInput_StillUnmarshals(t *testing.T) {
 		return testResponse{Message: "hi " + in.Name}, nil
 	}
 
-	out, err := callHandler(context.Background(), payload, handler)
+	out, err := callHandler(context.Background(), payload, handler, nil)
 	require.NoError(t, err)
 	assert.JSONEq(t, `{"message":"hi world"}`, string(out.payload))
 }
@@ -222,7 +271,7 @@ func BenchmarkCallHandler_RawMessage_1MB(b *testing.B) {
 	b.SetBytes(int64(len(payload)))
 	b.ReportAllocs()
 	for b.Loop() {
-		if _, err := callHandler(ctx, payload, handler); err != nil {
+		if _, err := callHandler(ctx, payload, handler, nil); err != nil {
 			b.Fatal(err)
 		}
 	}
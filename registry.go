@@ -0,0 +1,90 @@
+package voker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// defaultHandlerEnv is the variable Lambda sets to the function's handler
+// setting.
+const defaultHandlerEnv = "_HANDLER"
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]func(context.Context, []Option) error)
+)
+
+// Register adds handler to the handlers [StartRegistered] chooses from,
+// under name. opts apply only when handler is the one started, before the
+// options passed to StartRegistered. Register is meant to be called from
+// main or init functions, and panics if name is already registered.
+func Register[TIn, TOut any](name string, handler func(context.Context, TIn) (TOut, error), opts ...Option) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("voker: handler %q is already registered", name))
+	}
+	registry[name] = func(ctx context.Context, startOpts []Option) error {
+		return Run(ctx, handler, append(slices.Clip(opts), startOpts...)...)
+	}
+}
+
+// WithHandlerEnv sets the environment variable [StartRegistered] reads the
+// handler name from. The default is _HANDLER, which Lambda sets to the
+// function's handler setting.
+func WithHandlerEnv(name string) Option {
+	return func(o *options) {
+		o.handlerEnv = name
+	}
+}
+
+// StartRegistered starts the handler registered with [Register] under the
+// name in the _HANDLER environment variable, so one binary can back many
+// functions that differ only in their handler setting:
+//
+//	func main() {
+//	    voker.Register("create-user", createUser)
+//	    voker.Register("delete-user", deleteUser)
+//	    voker.StartRegistered(voker.WithLogger(logger))
+//	}
+//
+// If no handler is registered under the name, initialization fails with
+// errorType Runtime.HandlerNotFound. StartRegistered otherwise behaves like
+// [Start].
+func StartRegistered(opts ...Option) {
+	if err := RunRegistered(context.Background(), opts...); err != nil {
+		os.Exit(1)
+	}
+}
+
+// RunRegistered is like [StartRegistered], but returns fatal errors like
+// [Run].
+func RunRegistered(ctx context.Context, opts ...Option) error {
+	var configured options
+	for _, opt := range opts {
+		opt(&configured)
+	}
+	variable := configured.handlerEnv
+	if variable == "" {
+		variable = defaultHandlerEnv
+	}
+	name := getenv(configured.env, variable)
+
+	registryMu.Lock()
+	start, ok := registry[name]
+	registryMu.Unlock()
+	if ok {
+		return start(ctx, opts)
+	}
+
+	err := &ErrorResponse{
+		Type:    "Runtime.HandlerNotFound",
+		Message: fmt.Sprintf("no handler registered for %s=%q", variable, name),
+	}
+	return run(ctx, nil, append(opts, func(o *options) {
+		o.initErr = err
+	})...)
+}
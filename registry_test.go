@@ -0,0 +1,101 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func registerTestHandler[TIn, TOut any](t *testing.T, name string, handler func(context.Context, TIn) (TOut, error), opts ...Option) {
+	t.Helper()
+	Register(name, handler, opts...)
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(registry, name)
+	})
+}
+
+func TestRunRegistered(t *testing.T) {
+	var responses []string
+	served := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			if served {
+				<-r.Context().Done()
+				return
+			}
+			served = true
+			w.Header().Set(headerRequestID, "test-request-id")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			_, _ = w.Write([]byte(`{"name":"world"}`))
+		case "/2018-06-01/runtime/invocation/test-request-id/response":
+			body, _ := io.ReadAll(r.Body)
+			responses = append(responses, string(body))
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", server.URL[7:])
+	t.Setenv("_HANDLER", "create-user")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	registerTestHandler(t, "create-user", func(ctx context.Context, event testEvent) (testResponse, error) {
+		cancel()
+		assert.Equal(t, "value", ctx.Value(testConfigKey{}))
+		return testResponse{Message: "created " + event.Name}, nil
+	}, WithContextValues(map[any]any{testConfigKey{}: "value"}))
+	registerTestHandler(t, "delete-user", func(context.Context, testEvent) (testResponse, error) {
+		t.Error("unexpected call to delete-user")
+		return testResponse{}, nil
+	})
+
+	err := RunRegistered(ctx, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	require.NoError(t, err)
+	require.Len(t, responses, 1)
+	assert.JSONEq(t, `{"message":"created world"}`, responses[0])
+}
+
+func TestRunRegistered_HandlerNotFound(t *testing.T) {
+	var initError ErrorResponse
+	var errorType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/2018-06-01/runtime/init/error" {
+			errorType = r.Header.Get("Lambda-Runtime-Function-Error-Type")
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&initError))
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", server.URL[7:])
+	t.Setenv("_HANDLER", "create-user")
+	t.Setenv("APP_HANDLER", "archive-user")
+
+	registerTestHandler(t, "create-user", func(context.Context, testEvent) (testResponse, error) {
+		return testResponse{}, nil
+	})
+
+	err := RunRegistered(context.Background(), WithHandlerEnv("APP_HANDLER"), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	assert.EqualError(t, err, `no handler registered for APP_HANDLER="archive-user"`)
+	assert.Equal(t, "Runtime.HandlerNotFound", errorType)
+	assert.Equal(t, "Runtime.HandlerNotFound", initError.Type)
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		return testResponse{}, nil
+	}
+	registerTestHandler(t, "create-user", handler)
+	assert.PanicsWithValue(t, `voker: handler "create-user" is already registered`, func() {
+		Register("create-user", handler)
+	})
+}
@@ -0,0 +1,86 @@
+package voker
+
+import (
+	"context"
+	"log/slog"
+	"runtime/metrics"
+	"time"
+)
+
+// reportType is the type of invocation report records. vokerslog uses a
+// top-level "type" attribute as the record's type.
+const reportType = "app.report"
+
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// WithInvocationReport writes one structured record to the runtime's logger
+// at the end of every invocation, after the result is delivered to Lambda.
+// Unlike the platform's REPORT line, the record can be queried field by
+// field and extended by middleware with [AddReportAttrs]:
+//
+//	{"level":"INFO","msg":"invocation report","type":"app.report",
+//	 "requestId":"8f5b...","durationMs":12.4,"responseLatencyMs":0.3,
+//	 "coldStart":false,"requestBytes":512,"responseBytes":87,
+//	 "heapBytes":4194304,"errorType":"ValidationError","orderId":"o-123"}
+//
+// durationMs covers decoding the payload and running the handler and its
+// middleware, and heapBytes is the memory occupied by heap objects when the
+// invocation finished. errorType and tenantId are included when set, and
+// initDurationMs, the time the process took to become ready for
+// invocations, with the cold start ([InvocationMetrics].InitDuration).
+func WithInvocationReport() Option {
+	return func(o *options) {
+		o.invocationReport = true
+	}
+}
+
+// AddReportAttrs adds attributes to the invocation report of the invocation
+// that owns ctx. Attributes are written in the order they are added, after
+// the built-in fields. It does nothing without [WithInvocationReport] or
+// outside an invocation, and is safe for concurrent use.
+func AddReportAttrs(ctx context.Context, attrs ...slog.Attr) {
+	r := recorderFromContext(ctx)
+	if r == nil || r.report == nil {
+		return
+	}
+	r.reportMu.Lock()
+	defer r.reportMu.Unlock()
+	r.reportAttrs = append(r.reportAttrs, attrs...)
+}
+
+func (r *invocationRecorder) writeReport(ctx context.Context) {
+	sample := []metrics.Sample{{Name: heapObjectsMetric}}
+	metrics.Read(sample)
+
+	attrs := []slog.Attr{
+		slog.String("type", reportType),
+		slog.String("requestId", r.metrics.RequestID),
+		slog.Float64("durationMs", milliseconds(r.metrics.Duration)),
+		slog.Float64("responseLatencyMs", milliseconds(r.metrics.ResponseLatency)),
+		slog.Bool("coldStart", r.metrics.ColdStart),
+		slog.Int("requestBytes", r.metrics.RequestBytes),
+		slog.Int("responseBytes", r.metrics.ResponseBytes),
+	}
+	if sample[0].Value.Kind() == metrics.KindUint64 {
+		attrs = append(attrs, slog.Uint64("heapBytes", sample[0].Value.Uint64()))
+	}
+	if r.metrics.InitDuration > 0 {
+		attrs = append(attrs, slog.Float64("initDurationMs", milliseconds(r.metrics.InitDuration)))
+	}
+	if r.metrics.ErrorType != "" {
+		attrs = append(attrs, slog.String("errorType", r.metrics.ErrorType))
+	}
+	if r.metrics.TenantID != "" {
+		attrs = append(attrs, slog.String("tenantId", r.metrics.TenantID))
+	}
+
+	r.reportMu.Lock()
+	attrs = append(attrs, r.reportAttrs...)
+	r.reportMu.Unlock()
+
+	r.report.LogAttrs(ctx, slog.LevelInfo, "invocation report", attrs...)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,100 @@
+package voker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInvocation_InvocationReport(t *testing.T) {
+	requests := []string{"report-1", "report-2"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			w.Header().Set(headerRequestID, requests[0])
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			requests = requests[1:]
+			_, _ = w.Write([]byte(`{"name":"report"}`))
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	client := newRuntimeClient(server.URL[7:], logger)
+	options := &options{logger: logger, initDuration: 1500 * time.Millisecond}
+	WithInvocationReport()(options)
+	WithTenant(func(context.Context, json.RawMessage) string { return "tenant-a" })(options)
+	WithMiddleware(func(next InvokeFunc) InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			AddReportAttrs(ctx, slog.String("route", "orders"))
+			return next(ctx, payload)
+		}
+	})(options)
+
+	var fail bool
+	handler := func(ctx context.Context, event testEvent) (testResponse, error) {
+		AddReportAttrs(ctx, slog.Int("items", 3))
+		if fail {
+			return testResponse{}, &ErrorResponse{Type: "CustomError", Message: "failed"}
+		}
+		return testResponse{Message: "hello " + event.Name}, nil
+	}
+
+	require.NoError(t, handleInvocation(client, handler, options))
+	fail = true
+	require.NoError(t, handleInvocation(client, handler, options))
+
+	var reports []map[string]any
+	for line := range strings.Lines(logs.String()) {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		if record["msg"] == "invocation report" {
+			reports = append(reports, record)
+		}
+	}
+	require.Len(t, reports, 2)
+
+	first := reports[0]
+	assert.Equal(t, "app.report", first["type"])
+	assert.Equal(t, "report-1", first["requestId"])
+	assert.Equal(t, true, first["coldStart"])
+	assert.Equal(t, float64(len(`{"name":"report"}`)), first["requestBytes"])
+	assert.Equal(t, float64(len(`{"message":"hello report"}`)), first["responseBytes"])
+	assert.Equal(t, "tenant-a", first["tenantId"])
+	assert.Equal(t, "orders", first["route"])
+	assert.Equal(t, float64(3), first["items"])
+	assert.Contains(t, first, "durationMs")
+	assert.Contains(t, first, "responseLatencyMs")
+	assert.Greater(t, first["heapBytes"], float64(0))
+	assert.Equal(t, float64(1500), first["initDurationMs"])
+	assert.NotContains(t, first, "errorType")
+
+	second := reports[1]
+	assert.Equal(t, "report-2", second["requestId"])
+	assert.Equal(t, false, second["coldStart"])
+	assert.NotContains(t, second, "initDurationMs")
+	assert.Equal(t, "CustomError", second["errorType"])
+}
+
+func TestAddReportAttrs_WithoutReport(t *testing.T) {
+	assert.NotPanics(t, func() {
+		AddReportAttrs(context.Background(), slog.String("ignored", "value"))
+	})
+
+	// A recorder that only feeds a metrics sink ignores report attributes.
+	recorder := (&options{metrics: &recordingSink{}}).startMetrics(&invocation{})
+	AddReportAttrs(withRecorder(context.Background(), recorder), slog.String("ignored", "value"))
+	assert.Empty(t, recorder.reportAttrs)
+}
@@ -0,0 +1,150 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+type retryOptions struct {
+	attempts     int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	retryable    func(error) bool
+	logger       *slog.Logger
+	random       func() float64
+}
+
+// RetryOption configures [Retry].
+type RetryOption func(*retryOptions)
+
+// WithRetryAttempts sets the maximum number of times the handler runs,
+// including the first. The default is 3; values below 1 are treated as 1.
+func WithRetryAttempts(n int) RetryOption {
+	return func(o *retryOptions) {
+		o.attempts = n
+	}
+}
+
+// WithRetryBackoff sets the delay before the first retry, which doubles for
+// each further retry up to maxDelay. Each delay is jittered to between half
+// and all of its value. The defaults are 100ms and 2s.
+func WithRetryBackoff(initial, maxDelay time.Duration) RetryOption {
+	return func(o *retryOptions) {
+		o.initialDelay = initial
+		o.maxDelay = maxDelay
+	}
+}
+
+// WithRetryIf retries only the errors for which retryable returns true,
+// such as those of a flaky dependency:
+//
+//	voker.WithRetryIf(func(err error) bool {
+//	    var throttled *types.ThrottlingException
+//	    return errors.As(err, &throttled)
+//	})
+//
+// By default every error is retried except context errors and voker's own
+// Runtime.* errors, such as a payload that fails to decode, which fail the
+// same way every time.
+func WithRetryIf(retryable func(error) bool) RetryOption {
+	return func(o *retryOptions) {
+		o.retryable = retryable
+	}
+}
+
+// WithRetryLogger sets the logger that records each retry. The default is
+// slog.Default().
+func WithRetryLogger(logger *slog.Logger) RetryOption {
+	return func(o *retryOptions) {
+		o.logger = logger
+	}
+}
+
+// Retry returns middleware that runs the handler again when it fails with a
+// retryable error, backing off exponentially between attempts:
+//
+//	voker.Start(handler, voker.WithMiddleware(voker.Retry(
+//	    voker.WithRetryAttempts(4),
+//	    voker.WithRetryBackoff(50*time.Millisecond, time.Second),
+//	)))
+//
+// Retrying inside the invocation is cheaper than failing it for a flaky
+// downstream call, when Lambda or an SQS redelivery would re-run the whole
+// event after a visibility timeout. Retries stop once the invocation's
+// remaining time is shorter than the delay plus the duration of the last
+// attempt, so the final error is reported before the deadline instead of
+// the invocation timing out. The last error is returned unchanged.
+//
+// The handler must be safe to run more than once for the same event.
+// Panics are not retried.
+func Retry(opts ...RetryOption) Middleware {
+	o := retryOptions{
+		attempts:     3,
+		initialDelay: 100 * time.Millisecond,
+		maxDelay:     2 * time.Second,
+		retryable:    defaultRetryable,
+		random:       rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.attempts < 1 {
+		o.attempts = 1
+	}
+	if o.logger == nil {
+		o.logger = slog.Default()
+	}
+
+	return func(next InvokeFunc) InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			delay := o.initialDelay
+			for attempt := 1; ; attempt++ {
+				start := time.Now()
+				output, err := next(ctx, payload)
+				if err == nil || attempt == o.attempts || !o.retryable(err) {
+					return output, err
+				}
+
+				wait := o.jitter(delay)
+				if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait+time.Since(start) {
+					return output, err
+				}
+				o.logger.WarnContext(ctx, "retrying invocation",
+					"attempt", attempt,
+					"delay", wait.String(),
+					"error", err,
+				)
+
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return output, err
+				case <-timer.C:
+				}
+				delay = min(delay*2, o.maxDelay)
+			}
+		}
+	}
+}
+
+// jitter returns a delay between half and all of delay.
+func (o *retryOptions) jitter(delay time.Duration) time.Duration {
+	return delay/2 + time.Duration(o.random()*float64(delay/2))
+}
+
+// defaultRetryable reports whether err may succeed on another attempt.
+func defaultRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if typed, ok := errors.AsType[*ErrorResponse](err); ok && strings.HasPrefix(typed.Type, "Runtime.") {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errFlaky = errors.New("flaky")
+
+// failingInvoke fails the first failures calls with err and then returns
+// "ok", counting calls in calls.
+func failingInvoke(calls *int, failures int, err error) InvokeFunc {
+	return func(context.Context, json.RawMessage) (any, error) {
+		*calls++
+		if *calls <= failures {
+			return nil, err
+		}
+		return "ok", nil
+	}
+}
+
+func TestRetry_SucceedsAfterFailures(t *testing.T) {
+	var logs syncBuffer
+	var calls int
+	invoke := Retry(
+		WithRetryBackoff(0, 0),
+		WithRetryLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)(failingInvoke(&calls, 2, errFlaky))
+
+	output, err := invoke(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", output)
+	assert.Equal(t, 3, calls)
+	assert.Contains(t, logs.String(), `msg="retrying invocation" attempt=1`)
+	assert.Contains(t, logs.String(), `attempt=2`)
+}
+
+func TestRetry_StopsAtMaxAttempts(t *testing.T) {
+	var calls int
+	invoke := Retry(
+		WithRetryAttempts(2),
+		WithRetryBackoff(0, 0),
+		WithRetryLogger(slog.New(slog.DiscardHandler)),
+	)(failingInvoke(&calls, 5, errFlaky))
+
+	_, err := invoke(context.Background(), nil)
+	assert.Same(t, errFlaky, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetry_NonRetryableErrors(t *testing.T) {
+	unmarshal := &ErrorResponse{Type: "Runtime.UnmarshalError", Message: "bad input"}
+	tests := map[string]struct {
+		err  error
+		opts []RetryOption
+	}{
+		"runtime error":     {err: unmarshal},
+		"deadline":          {err: context.DeadlineExceeded},
+		"wrapped canceled":  {err: errors.Join(errFlaky, context.Canceled)},
+		"custom classifier": {err: errFlaky, opts: []RetryOption{WithRetryIf(func(err error) bool { return !errors.Is(err, errFlaky) })}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			opts := append([]RetryOption{WithRetryBackoff(0, 0), WithRetryLogger(slog.New(slog.DiscardHandler))}, tt.opts...)
+			_, err := Retry(opts...)(failingInvoke(&calls, 5, tt.err))(context.Background(), nil)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, 1, calls)
+		})
+	}
+}
+
+func TestRetry_BoundedByRemainingTime(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var calls int
+	invoke := Retry(
+		WithRetryAttempts(10),
+		WithRetryBackoff(time.Second, time.Second),
+		WithRetryLogger(slog.New(slog.DiscardHandler)),
+	)(failingInvoke(&calls, 10, errFlaky))
+
+	start := time.Now()
+	_, err := invoke(ctx, nil)
+	assert.Same(t, errFlaky, err)
+	assert.Equal(t, 1, calls)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestRetry_StopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	invoke := Retry(
+		WithRetryBackoff(time.Hour, time.Hour),
+		WithRetryLogger(slog.New(slog.DiscardHandler)),
+	)(func(context.Context, json.RawMessage) (any, error) {
+		calls++
+		cancel()
+		return nil, errFlaky
+	})
+
+	_, err := invoke(ctx, nil)
+	assert.Same(t, errFlaky, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryOptions_Jitter(t *testing.T) {
+	o := retryOptions{random: func() float64 { return 0 }}
+	assert.Equal(t, 50*time.Millisecond, o.jitter(100*time.Millisecond))
+	o.random = func() float64 { return 0.999999 }
+	assert.InDelta(t, float64(100*time.Millisecond), float64(o.jitter(100*time.Millisecond)), float64(time.Microsecond))
+}
+
+func TestRetry_WithHandler(t *testing.T) {
+	var calls int
+	handler := func(_ context.Context, input struct{ N int }) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errFlaky
+		}
+		return input.N * 2, nil
+	}
+	opts := &options{middleware: []Middleware{Retry(WithRetryBackoff(0, 0), WithRetryLogger(slog.New(slog.DiscardHandler)))}}
+
+	response, err := callHandler(context.Background(), []byte(`{"N":21}`), handler, opts)
+	require.NoError(t, err)
+	assert.JSONEq(t, `42`, string(response.payload))
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	_, err = callHandler(context.Background(), []byte(`not json`), handler, opts)
+	response2, ok := errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "Runtime.UnmarshalError", response2.Type)
+	assert.Equal(t, 0, calls)
+}
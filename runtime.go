@@ -12,6 +12,8 @@ import (
 	"net/url"
 	"runtime"
 	"runtime/debug"
+	"strings"
+	"time"
 )
 
 const (
@@ -75,53 +77,141 @@ func buildUserAgent() string {
 // route through a proxy from HTTP_PROXY et al., and enough idle connections
 // are retained for every concurrent worker to keep its connection alive
 // between invocations (http.DefaultTransport would keep only two).
+//
+// Idle connections never expire, as IdleConnTimeout is left unset: the
+// execution environment is frozen between invocations, and a timeout firing
+// on thaw would only force a redial on the next request. Compression is
+// disabled because the endpoint never compresses responses, so advertising
+// gzip only adds a header to every request. Go already enables TCP_NODELAY
+// on every TCP connection, so small writes such as a response posted right
+// after GET /next are not delayed by Nagle's algorithm.
 func newRuntimeTransport(maxIdleConnsPerHost int) *http.Transport {
 	return &http.Transport{
 		Proxy:               nil,
 		MaxIdleConnsPerHost: maxIdleConnsPerHost,
+		DisableCompression:  true,
 	}
 }
 
 type runtimeClient struct {
 	// host is the Runtime API host:port from AWS_LAMBDA_RUNTIME_API.
 	host string
+	// invocationPrefix and streamingPrefix are the invocation path prefixes
+	// for buffered and streaming responses.
+	invocationPrefix string
+	streamingPrefix  string
 	// nextURL is pre-parsed once: GET /next runs on every invocation.
 	nextURL      *url.URL
 	initErrorURL *url.URL
-	httpClient   *http.Client
-	logger       *slog.Logger
+	// restoreNextURL and restoreErrorURL are used only under SnapStart.
+	restoreNextURL  *url.URL
+	restoreErrorURL *url.URL
+	httpClient      *http.Client
+	logger          *slog.Logger
+	// userAgent is the User-Agent header value, including any suffix from
+	// WithUserAgentSuffix.
+	userAgent []string
+
+	// env supplies the function name and version logged with invocation
+	// errors. It is nil for the process environment.
+	env env
 }
 
-const invocationPathPrefix = "/" + runtimeAPIVersion + "/runtime/invocation/"
+// runtimeAPIVersions selects the Runtime API version used by each group of
+// endpoints. Every group uses the same version today, but keeping them
+// separate lets a new version of one group, such as streaming responses, be
+// adopted without changing the others.
+type runtimeAPIVersions struct {
+	// invocation covers next, buffered responses, and invocation errors.
+	invocation string
+	// streaming covers streaming responses.
+	streaming string
+	// init covers init errors.
+	init string
+	// restore covers the SnapStart restore endpoints.
+	restore string
+}
+
+// uniformRuntimeAPIVersions uses version for every endpoint group.
+func uniformRuntimeAPIVersions(version string) runtimeAPIVersions {
+	return runtimeAPIVersions{invocation: version, streaming: version, init: version, restore: version}
+}
 
 func newRuntimeClient(runtimeAPI string, logger *slog.Logger) *runtimeClient {
-	return &runtimeClient{
-		host:         runtimeAPI,
-		nextURL:      &url.URL{Scheme: "http", Host: runtimeAPI, Path: invocationPathPrefix + "next"},
-		initErrorURL: &url.URL{Scheme: "http", Host: runtimeAPI, Path: "/" + runtimeAPIVersion + "/runtime/init/error"},
+	c := &runtimeClient{
+		host: runtimeAPI,
 		httpClient: &http.Client{
 			Transport: newRuntimeTransport(MaxConcurrency()),
 			Timeout:   0, // No timeout for runtime API connections
 		},
-		logger: logger,
+		logger:    logger,
+		userAgent: userAgentValue,
 	}
+	c.setVersions(uniformRuntimeAPIVersions(runtimeAPIVersion))
+	return c
+}
+
+// setVersions points the client at the given endpoint versions.
+func (c *runtimeClient) setVersions(versions runtimeAPIVersions) {
+	c.invocationPrefix = "/" + versions.invocation + "/runtime/invocation/"
+	c.streamingPrefix = "/" + versions.streaming + "/runtime/invocation/"
+	c.nextURL = &url.URL{Scheme: "http", Host: c.host, Path: c.invocationPrefix + "next"}
+	c.initErrorURL = &url.URL{Scheme: "http", Host: c.host, Path: "/" + versions.init + "/runtime/init/error"}
+	c.restoreNextURL = &url.URL{Scheme: "http", Host: c.host, Path: "/" + versions.restore + "/runtime/restore/next"}
+	c.restoreErrorURL = &url.URL{Scheme: "http", Host: c.host, Path: "/" + versions.restore + "/runtime/restore/error"}
 }
 
 // invocationURL builds an invocation-scoped Runtime API URL without a URL
 // parse. Request IDs are Lambda-issued identifiers that need no escaping.
 func (c *runtimeClient) invocationURL(requestID, suffix string) *url.URL {
-	return &url.URL{Scheme: "http", Host: c.host, Path: invocationPathPrefix + requestID + suffix}
+	return &url.URL{Scheme: "http", Host: c.host, Path: c.invocationPrefix + requestID + suffix}
+}
+
+// streamingURL is invocationURL for streaming responses.
+func (c *runtimeClient) streamingURL(requestID, suffix string) *url.URL {
+	return &url.URL{Scheme: "http", Host: c.host, Path: c.streamingPrefix + requestID + suffix}
 }
 
 func (c *runtimeClient) initFailure(errorPayload []byte, errorType string) error {
 	return c.post(context.Background(), c.initErrorURL, errorPayload, errorType)
 }
 
+// restoreNext tells Lambda that initialization is complete and the
+// execution environment can be snapshotted. It returns once the environment
+// has been restored from the snapshot.
+func (c *runtimeClient) restoreNext(ctx context.Context) error {
+	req := (&http.Request{
+		Method: http.MethodGet,
+		URL:    c.restoreNextURL,
+		Header: http.Header{headerUserAgent: c.userAgent},
+	}).WithContext(ctx)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to wait for restore: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from runtime API: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *runtimeClient) restoreFailure(errorPayload []byte, errorType string) error {
+	return c.post(context.Background(), c.restoreErrorURL, errorPayload, errorType)
+}
+
 type invocation struct {
 	requestID string
 	payload   []byte
 	headers   http.Header
 	client    *runtimeClient
+
+	// nextLatency is the round trip of the request that fetched the
+	// invocation.
+	nextLatency time.Duration
 }
 
 func (c *runtimeClient) next() (*invocation, error) {
@@ -132,9 +222,10 @@ func (c *runtimeClient) nextContext(ctx context.Context) (*invocation, error) {
 	req := (&http.Request{
 		Method: http.MethodGet,
 		URL:    c.nextURL,
-		Header: http.Header{headerUserAgent: userAgentValue},
+		Header: http.Header{headerUserAgent: c.userAgent},
 	}).WithContext(ctx)
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get next invocation: %w", err)
@@ -151,10 +242,11 @@ func (c *runtimeClient) nextContext(ctx context.Context) (*invocation, error) {
 	}
 
 	return &invocation{
-		requestID: resp.Header.Get(headerRequestID),
-		payload:   payload,
-		headers:   resp.Header,
-		client:    c,
+		requestID:   resp.Header.Get(headerRequestID),
+		payload:     payload,
+		headers:     resp.Header,
+		client:      c,
+		nextLatency: time.Since(start),
 	}, nil
 }
 
@@ -162,6 +254,68 @@ func (c *runtimeClient) nextContext(ctx context.Context) (*invocation, error) {
 // read it, so it is safe to share across concurrent workers.
 var userAgentValue = []string{userAgent}
 
+// runtimeAPIVersionEnv overrides the Runtime API version without a code
+// change.
+const runtimeAPIVersionEnv = "VOKER_RUNTIME_API_VERSION"
+
+// WithRuntimeAPIVersion selects the Runtime API version voker calls, in place
+// of the default "2018-06-01", for runtimes or emulators that serve a newer
+// version. The VOKER_RUNTIME_API_VERSION environment variable takes
+// precedence over this option.
+func WithRuntimeAPIVersion(version string) Option {
+	return func(o *options) {
+		o.apiVersion = version
+	}
+}
+
+// runtimeAPIVersion returns the configured Runtime API version.
+func (o *options) runtimeAPIVersion() string {
+	if version := getenv(o.env, runtimeAPIVersionEnv); version != "" {
+		return version
+	}
+	if o.apiVersion != "" {
+		return o.apiVersion
+	}
+	return runtimeAPIVersion
+}
+
+// validateRuntimeAPIVersion rejects versions that are not a single URL path
+// segment.
+func validateRuntimeAPIVersion(version string) error {
+	if strings.ContainsAny(version, "/?#% \t\n") {
+		return &ErrorResponse{
+			Type:    "Runtime.InvalidAPIVersion",
+			Message: fmt.Sprintf("invalid Runtime API version %q", version),
+		}
+	}
+	return nil
+}
+
+// WithUserAgentSuffix appends suffix to the "voker/<version> go/<version>"
+// User-Agent sent to the Runtime and Extensions APIs, so frameworks built on
+// voker can identify themselves, e.g. "acme-lambda/2.3.1". Multiple calls
+// append in order, separated by spaces.
+func WithUserAgentSuffix(suffix string) Option {
+	return func(o *options) {
+		if suffix = strings.TrimSpace(suffix); suffix == "" {
+			return
+		}
+		if o.userAgentSuffix != "" {
+			suffix = o.userAgentSuffix + " " + suffix
+		}
+		o.userAgentSuffix = suffix
+	}
+}
+
+// userAgentHeader returns the User-Agent header value for the configured
+// suffix, sharing userAgentValue when there is none.
+func (o *options) userAgentHeader() []string {
+	if o.userAgentSuffix == "" {
+		return userAgentValue
+	}
+	return []string{userAgent + " " + o.userAgentSuffix}
+}
+
 func readBody(resp *http.Response) ([]byte, error) {
 	if resp.ContentLength < 0 {
 		return io.ReadAll(resp.Body)
@@ -181,9 +335,38 @@ func (inv *invocation) success(responsePayload []byte) error {
 	return inv.client.post(context.Background(), url, responsePayload, "")
 }
 
+// successReader uploads a buffered-mode response from body with chunked
+// transfer encoding, so the full payload never needs to be in memory.
+func (inv *invocation) successReader(ctx context.Context, body io.Reader) error {
+	req := (&http.Request{
+		Method: http.MethodPost,
+		URL:    inv.client.invocationURL(inv.requestID, responsePath),
+		Header: http.Header{
+			headerUserAgent:   inv.client.userAgent,
+			headerContentType: contentTypeJSONValue,
+		},
+		Body:          io.NopCloser(body),
+		ContentLength: -1,
+	}).WithContext(ctx)
+
+	resp, err := inv.client.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to POST to runtime API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("unexpected status code from runtime API: %d", resp.StatusCode)
+	}
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		inv.client.logger.ErrorContext(ctx, "failed to drain response body", "error", err)
+	}
+	return nil
+}
+
 func (inv *invocation) successStreaming(ctx context.Context, reader io.Reader, contentType string) (streamErr error, responseErr error) {
-	body := &streamingRequestBody{reader: reader}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inv.client.invocationURL(inv.requestID, responsePath).String(), body)
+	body := &streamingRequestBody{reader: reader, fullStackPaths: fullStackPathsFromContext(ctx)}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inv.client.streamingURL(inv.requestID, responsePath).String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -192,7 +375,7 @@ func (inv *invocation) successStreaming(ctx context.Context, reader io.Reader, c
 		contentType = "application/octet-stream"
 	}
 	req.Header.Set(headerContentType, contentType)
-	req.Header.Set(headerUserAgent, userAgent)
+	req.Header[headerUserAgent] = inv.client.userAgent
 	req.Header.Set(headerResponseMode, "streaming")
 	req.TransferEncoding = []string{"chunked"}
 	req.Trailer = http.Header{
@@ -219,10 +402,11 @@ func (inv *invocation) successStreaming(ctx context.Context, reader io.Reader, c
 }
 
 type streamingRequestBody struct {
-	reader     io.Reader
-	trailer    http.Header
-	streamErr  error
-	pendingEOF bool
+	reader         io.Reader
+	trailer        http.Header
+	streamErr      error
+	pendingEOF     bool
+	fullStackPaths bool
 }
 
 func (b *streamingRequestBody) Read(p []byte) (n int, err error) {
@@ -230,7 +414,7 @@ func (b *streamingRequestBody) Read(p []byte) (n int, err error) {
 		if recovered := recover(); recovered != nil {
 			n = 0
 			err = io.EOF
-			b.setError(newPanicResponse(recovered))
+			b.setError(newPanicResponse(recovered, b.fullStackPaths))
 		}
 	}()
 	if b.pendingEOF {
@@ -283,7 +467,7 @@ func (c *runtimeClient) post(ctx context.Context, url *url.URL, body []byte, err
 		Method: http.MethodPost,
 		URL:    url,
 		Header: http.Header{
-			headerUserAgent:   userAgentValue,
+			headerUserAgent:   c.userAgent,
 			headerContentType: contentTypeJSONValue,
 		},
 		Body:          io.NopCloser(bytes.NewReader(body)),
@@ -8,9 +8,11 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -301,3 +303,138 @@ func TestRuntimeClient_Post_BadStatus(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unexpected status code")
 }
+
+func TestWithUserAgentSuffix(t *testing.T) {
+	o := &options{}
+	assert.Equal(t, userAgentValue, o.userAgentHeader())
+
+	WithUserAgentSuffix(" acme-lambda/2.3.1 ")(o)
+	WithUserAgentSuffix("")(o)
+	WithUserAgentSuffix("billing/1.0")(o)
+	assert.Equal(t, []string{userAgent + " acme-lambda/2.3.1 billing/1.0"}, o.userAgentHeader())
+}
+
+func TestRuntimeClient_UserAgent(t *testing.T) {
+	var agents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get(headerUserAgent))
+		if r.Method == http.MethodGet {
+			w.Header().Set(headerRequestID, "req-ua")
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	o := &options{}
+	WithUserAgentSuffix("acme-lambda/2.3.1")(o)
+	client := newRuntimeClient(server.URL[7:], slog.New(slog.DiscardHandler))
+	client.userAgent = o.userAgentHeader()
+
+	inv, err := client.next()
+	require.NoError(t, err)
+	require.NoError(t, inv.success([]byte(`{}`)))
+	_, err = inv.successStreaming(context.Background(), bytes.NewReader([]byte("data")), "text/plain")
+	require.NoError(t, err)
+
+	want := userAgent + " acme-lambda/2.3.1"
+	assert.Equal(t, []string{want, want, want}, agents)
+}
+
+func TestRuntimeClient_Transport(t *testing.T) {
+	var encodings []string
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Accept-Encoding"))
+		if r.Method == http.MethodGet {
+			w.Header().Set(headerRequestID, "req-transport")
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := newRuntimeClient(server.URL[7:], slog.New(slog.DiscardHandler))
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.Nil(t, transport.Proxy)
+	assert.True(t, transport.DisableCompression)
+	assert.Zero(t, transport.IdleConnTimeout)
+	assert.Equal(t, MaxConcurrency(), transport.MaxIdleConnsPerHost)
+
+	for range 3 {
+		inv, err := client.next()
+		require.NoError(t, err)
+		require.NoError(t, inv.success([]byte(`{}`)))
+	}
+
+	assert.Equal(t, []string{"", "", "", "", "", ""}, encodings)
+	assert.Equal(t, int32(1), conns.Load(), "sequential invocations should reuse one connection")
+}
+
+func TestRuntimeAPIVersion(t *testing.T) {
+	o := &options{env: mapEnv{}}
+	assert.Equal(t, "2018-06-01", o.runtimeAPIVersion())
+
+	WithRuntimeAPIVersion("2030-01-01")(o)
+	assert.Equal(t, "2030-01-01", o.runtimeAPIVersion())
+
+	WithEnv(map[string]string{runtimeAPIVersionEnv: "2031-01-01"})(o)
+	assert.Equal(t, "2031-01-01", o.runtimeAPIVersion())
+}
+
+func TestValidateRuntimeAPIVersion(t *testing.T) {
+	assert.NoError(t, validateRuntimeAPIVersion("2018-06-01"))
+	assert.NoError(t, validateRuntimeAPIVersion("v2"))
+
+	err := validateRuntimeAPIVersion("2018-06-01/runtime")
+	var errResp *ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, "Runtime.InvalidAPIVersion", errResp.Type)
+	assert.Equal(t, `invalid Runtime API version "2018-06-01/runtime"`, errResp.Message)
+
+	o := &options{}
+	WithRuntimeAPIVersion("a b")(o)
+	assert.ErrorAs(t, validateRuntimeConfiguration(o), &errResp)
+}
+
+func TestRuntimeClient_SetVersions(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Method == http.MethodGet {
+			w.Header().Set(headerRequestID, "req-v")
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := newRuntimeClient(server.URL[7:], slog.New(slog.DiscardHandler))
+	versions := uniformRuntimeAPIVersions("2030-01-01")
+	versions.streaming = "2031-01-01"
+	client.setVersions(versions)
+
+	inv, err := client.next()
+	require.NoError(t, err)
+	require.NoError(t, inv.success([]byte(`{}`)))
+	_, err = inv.successStreaming(context.Background(), bytes.NewReader([]byte("data")), "text/plain")
+	require.NoError(t, err)
+	require.NoError(t, client.initFailure([]byte(`{}`), "Runtime.Test"))
+
+	assert.Equal(t, []string{
+		"/2030-01-01/runtime/invocation/next",
+		"/2030-01-01/runtime/invocation/req-v/response",
+		"/2031-01-01/runtime/invocation/req-v/response",
+		"/2030-01-01/runtime/init/error",
+	}, paths)
+}
@@ -0,0 +1,41 @@
+package voker
+
+import "context"
+
+// shutdownHookExtensionName names the internal extension that carries
+// shutdown hooks.
+const shutdownHookExtensionName = "voker-shutdown"
+
+// WithShutdownHook runs hook when Lambda shuts down the execution
+// environment, so functions can flush buffers and close connections without
+// defining an [InternalExtension].
+//
+// Lambda only sends SIGTERM to runtimes with at least one registered
+// extension, so the first hook registers an internal extension that
+// subscribes to no events. Hooks run in registration order after every other
+// extension's OnSIGTERM and share its 500ms deadline. Like other internal
+// extensions, shutdown hooks are not supported on Lambda Managed Instances.
+func WithShutdownHook(hook func(ctx context.Context)) Option {
+	return func(o *options) {
+		o.shutdownHooks = append(o.shutdownHooks, hook)
+	}
+}
+
+// registerShutdownHooks adds the extension that runs the shutdown hooks. It
+// is also added without hooks when WithEnableSIGTERM needs an extension to
+// receive SIGTERM at all, except on Lambda Managed Instances, which don't
+// support extensions and deliver SIGTERM without one.
+func (o *options) registerShutdownHooks() {
+	if len(o.shutdownHooks) == 0 && (!o.enableSIGTERM || len(o.extensions) > 0 || o.initType() == InitManagedInstances) {
+		return
+	}
+	hooks := o.shutdownHooks
+	o.extensions = append(o.extensions, InternalExtension{
+		Name: shutdownHookExtensionName,
+		OnSIGTERM: func(ctx context.Context) {
+			for _, hook := range hooks {
+				hook(ctx)
+			}
+		},
+	})
+}
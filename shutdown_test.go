@@ -0,0 +1,64 @@
+package voker
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithShutdownHook(t *testing.T) {
+	var calls []string
+	opts := &options{}
+	WithInternalExtension(InternalExtension{
+		Name:      "TestExtension",
+		OnSIGTERM: func(context.Context) { calls = append(calls, "extension") },
+	})(opts)
+	WithShutdownHook(func(ctx context.Context) {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		calls = append(calls, "first")
+	})(opts)
+	WithShutdownHook(func(context.Context) { calls = append(calls, "second") })(opts)
+
+	opts.registerShutdownHooks()
+	require.Len(t, opts.extensions, 2)
+	hookExt := opts.extensions[1]
+	assert.Equal(t, shutdownHookExtensionName, hookExt.Name)
+	assert.Nil(t, hookExt.OnInvoke)
+
+	var registered []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2020-01-01/extension/register":
+			registered = append(registered, r.Header.Get(headerExtensionName))
+			w.Header().Set(headerExtensionIdentifier, "test-id")
+			w.WriteHeader(http.StatusOK)
+		case "/2020-01-01/extension/event/next":
+			// Block to prevent tight loop
+			time.Sleep(10 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	mgr := newExtensionManager(server.Listener.Addr().String(), opts.extensions, logger)
+	require.NoError(t, mgr.start())
+	mgr.shutdown()
+
+	assert.Equal(t, []string{"TestExtension", shutdownHookExtensionName}, registered)
+	assert.Equal(t, []string{"extension", "first", "second"}, calls)
+}
+
+func TestRegisterShutdownHooks_None(t *testing.T) {
+	opts := &options{}
+	opts.registerShutdownHooks()
+	assert.Empty(t, opts.extensions)
+}
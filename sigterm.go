@@ -0,0 +1,41 @@
+package voker
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSIGTERM is the cause of an invocation context canceled because Lambda
+// is shutting down the execution environment. See [WithEnableSIGTERM].
+var ErrSIGTERM = errors.New("execution environment is shutting down")
+
+// WithEnableSIGTERM cancels the context of every in-flight invocation when
+// Lambda sends SIGTERM, before extensions' OnSIGTERM callbacks and shutdown
+// hooks run. Long-running handlers can then stop cleanly when the environment
+// is reclaimed mid-invocation; context.Cause reports [ErrSIGTERM].
+//
+// Lambda only sends SIGTERM to runtimes with at least one registered
+// extension, so this option registers the same no-op internal extension as
+// [WithShutdownHook] when no other extension is configured. Lambda Managed
+// Instances don't support extensions, so there the option only installs a
+// signal handler; combined with [WithShutdownHook] or other internal
+// extensions it still fails initialization there.
+func WithEnableSIGTERM() Option {
+	return func(o *options) {
+		o.enableSIGTERM = true
+	}
+}
+
+// invocationParent returns the context every invocation context derives
+// from. It is canceled with ErrSIGTERM on shutdown when WithEnableSIGTERM is
+// set, and carries the values set with WithContextValues.
+func (o *options) invocationParent() context.Context {
+	parent := context.Background()
+	if o.shutdownCtx != nil {
+		parent = o.shutdownCtx
+	}
+	if len(o.contextValues) > 0 {
+		return valuesContext{Context: parent, values: o.contextValues}
+	}
+	return parent
+}
@@ -0,0 +1,121 @@
+package voker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInvocation_SIGTERMCancelsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			w.Header().Set(headerRequestID, "req-123")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := newRuntimeClient(server.URL[7:], logger)
+	shutdownCtx, shutdown := context.WithCancelCause(context.Background())
+	opts := &options{logger: logger, enableSIGTERM: true, shutdownCtx: shutdownCtx}
+
+	handler := func(ctx context.Context, _ testEvent) (testResponse, error) {
+		shutdown(ErrSIGTERM)
+		<-ctx.Done()
+		assert.ErrorIs(t, context.Cause(ctx), ErrSIGTERM)
+		return testResponse{}, context.Cause(ctx)
+	}
+
+	require.NoError(t, handleInvocation(client, handler, opts))
+}
+
+func TestInvocationParent(t *testing.T) {
+	assert.Equal(t, context.Background(), (&options{}).invocationParent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.Equal(t, ctx, (&options{shutdownCtx: ctx}).invocationParent())
+}
+
+func TestWithEnableSIGTERM_RegistersExtension(t *testing.T) {
+	opts := &options{}
+	WithEnableSIGTERM()(opts)
+	opts.registerShutdownHooks()
+	require.Len(t, opts.extensions, 1)
+	assert.Equal(t, shutdownHookExtensionName, opts.extensions[0].Name)
+
+	opts = &options{}
+	WithInternalExtension(InternalExtension{Name: "TestExtension"})(opts)
+	WithEnableSIGTERM()(opts)
+	opts.registerShutdownHooks()
+	require.Len(t, opts.extensions, 1)
+	assert.Equal(t, "TestExtension", opts.extensions[0].Name)
+}
+
+func TestWithEnableSIGTERM_ManagedInstances(t *testing.T) {
+	opts := &options{}
+	WithEnv(map[string]string{lambdaEnvInitializationType: string(InitManagedInstances)})(opts)
+	WithEnableSIGTERM()(opts)
+	opts.registerShutdownHooks()
+	assert.Empty(t, opts.extensions)
+	require.NoError(t, validateRuntimeConfiguration(opts))
+
+	// Shutdown hooks still need an extension, which Managed Instances reject.
+	WithShutdownHook(func(context.Context) {})(opts)
+	opts.registerShutdownHooks()
+	var response *ErrorResponse
+	require.ErrorAs(t, validateRuntimeConfiguration(opts), &response)
+	assert.Equal(t, "Runtime.UnsupportedExtension", response.Type)
+}
+
+func TestRun_SIGTERMWithoutExtensions(t *testing.T) {
+	var nexts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			if nexts.Add(1) > 1 {
+				<-r.Context().Done()
+				return
+			}
+			w.Header().Set(headerRequestID, "req-123")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	cause := make(chan error, 1)
+	handler := func(ctx context.Context, _ testEvent) (testResponse, error) {
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		return testResponse{}, ctx.Err()
+	}
+
+	err := Run(context.Background(), handler,
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithEnv(map[string]string{
+			"AWS_LAMBDA_RUNTIME_API":    server.URL[7:],
+			lambdaEnvInitializationType: string(InitManagedInstances),
+		}),
+		WithEnableSIGTERM(),
+	)
+	require.NoError(t, err)
+	assert.ErrorIs(t, <-cause, ErrSIGTERM)
+}
@@ -0,0 +1,57 @@
+package voker
+
+import "context"
+
+// WithBeforeCheckpoint registers a hook that runs under Lambda SnapStart
+// after initialization and before Lambda snapshots the execution
+// environment. Use it to close network connections and discard state that
+// must not be shared by every environment restored from the snapshot.
+//
+// Hooks run in reverse registration order. An error fails initialization.
+// Outside SnapStart the hooks never run.
+func WithBeforeCheckpoint(hook func(ctx context.Context) error) Option {
+	return func(o *options) {
+		o.beforeCheckpoint = append(o.beforeCheckpoint, hook)
+	}
+}
+
+// WithAfterRestore registers a hook that runs under Lambda SnapStart each
+// time an execution environment is restored from a snapshot, before the
+// first invocation. Use it to reopen connections and re-seed anything that
+// must be unique per environment, such as random number generators or
+// cached credentials.
+//
+// Hooks run in registration order. An error is reported to Lambda as a
+// restore failure. Outside SnapStart the hooks never run.
+func WithAfterRestore(hook func(ctx context.Context) error) Option {
+	return func(o *options) {
+		o.afterRestore = append(o.afterRestore, hook)
+	}
+}
+
+// runSnapStartHooks implements the Runtime API's SnapStart protocol: the
+// before-checkpoint hooks run, GET /runtime/restore/next blocks until the
+// snapshot is restored, and then the after-restore hooks run.
+func runSnapStartHooks(ctx context.Context, client *runtimeClient, options *options) error {
+	for i := len(options.beforeCheckpoint) - 1; i >= 0; i-- {
+		if err := callRuntimeHook(ctx, "before checkpoint", options.beforeCheckpoint[i], options.fullStackPaths); err != nil {
+			reportInitError(client, err, options.logger)
+			return err
+		}
+	}
+
+	if err := client.restoreNext(ctx); err != nil {
+		return err
+	}
+
+	for _, hook := range options.afterRestore {
+		if err := callRuntimeHook(ctx, "after restore", hook, options.fullStackPaths); err != nil {
+			errResp, errorJSON := marshalInitError(err)
+			if reportErr := client.restoreFailure(errorJSON, errResp.Type); reportErr != nil {
+				options.logger.Error("failed to report restore error", "error", reportErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
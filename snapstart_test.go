@@ -0,0 +1,120 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type snapStartServer struct {
+	*httptest.Server
+	errorType string
+	errorBody ErrorResponse
+}
+
+func newSnapStartServer(t *testing.T, calls *[]string) *snapStartServer {
+	s := &snapStartServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/restore/next":
+			assert.Equal(t, http.MethodGet, r.Method)
+			*calls = append(*calls, "restore")
+			w.WriteHeader(http.StatusOK)
+		case "/2018-06-01/runtime/restore/error", "/2018-06-01/runtime/init/error":
+			*calls = append(*calls, r.URL.Path)
+			s.errorType = r.Header.Get(headerFunctionErrorType)
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &s.errorBody))
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestRunSnapStartHooks(t *testing.T) {
+	var calls []string
+	server := newSnapStartServer(t, &calls)
+	client := newRuntimeClient(server.Listener.Addr().String(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	hook := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	opts := &options{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	WithBeforeCheckpoint(hook("close db"))(opts)
+	WithBeforeCheckpoint(hook("flush cache"))(opts)
+	WithAfterRestore(hook("reseed"))(opts)
+	WithAfterRestore(hook("open db"))(opts)
+
+	require.NoError(t, runSnapStartHooks(context.Background(), client, opts))
+	assert.Equal(t, []string{"flush cache", "close db", "restore", "reseed", "open db"}, calls)
+}
+
+func TestRunSnapStartHooks_BeforeCheckpointError(t *testing.T) {
+	var calls []string
+	server := newSnapStartServer(t, &calls)
+	client := newRuntimeClient(server.Listener.Addr().String(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	opts := &options{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	WithBeforeCheckpoint(func(context.Context) error {
+		return &ErrorResponse{Type: "Checkpoint.Error", Message: "pool busy"}
+	})(opts)
+	WithAfterRestore(func(context.Context) error {
+		t.Error("after restore hook must not run")
+		return nil
+	})(opts)
+
+	err := runSnapStartHooks(context.Background(), client, opts)
+	require.Error(t, err)
+	assert.Equal(t, []string{"/2018-06-01/runtime/init/error"}, calls)
+	assert.Equal(t, "Checkpoint.Error", server.errorType)
+	assert.Equal(t, "before checkpoint hook failed: pool busy", server.errorBody.Message)
+}
+
+func TestRunSnapStartHooks_AfterRestoreError(t *testing.T) {
+	var calls []string
+	server := newSnapStartServer(t, &calls)
+	client := newRuntimeClient(server.Listener.Addr().String(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	opts := &options{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	WithAfterRestore(func(context.Context) error {
+		return errors.New("dial failed")
+	})(opts)
+
+	err := runSnapStartHooks(context.Background(), client, opts)
+	require.Error(t, err)
+	assert.Equal(t, []string{"restore", "/2018-06-01/runtime/restore/error"}, calls)
+	assert.Equal(t, "HandlerError", server.errorType)
+	assert.Equal(t, "after restore hook failed: dial failed", server.errorBody.Message)
+}
+
+func TestRunSnapStartHooks_AfterRestorePanic(t *testing.T) {
+	var calls []string
+	server := newSnapStartServer(t, &calls)
+	client := newRuntimeClient(server.Listener.Addr().String(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	opts := &options{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	WithAfterRestore(func(context.Context) error {
+		panic("boom")
+	})(opts)
+
+	err := runSnapStartHooks(context.Background(), client, opts)
+	require.Error(t, err)
+	assert.Equal(t, "Runtime.Panic.string", server.errorType)
+	assert.Equal(t, "after restore hook panicked: boom", server.errorBody.Message)
+	assert.NotEmpty(t, server.errorBody.StackTrace)
+}
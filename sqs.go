@@ -0,0 +1,96 @@
+package voker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hotsock/voker/vokerevents"
+)
+
+// SQSHandler adapts a per-message handler into an SQS batch handler for
+// [Start] that reports partial batch failures:
+//
+//	voker.Start(voker.SQSHandler(func(ctx context.Context, msg vokerevents.SQSMessage) error {
+//	    return process(ctx, msg.Body)
+//	}, voker.WithBatchConcurrency(10)))
+//
+// Each message for which handler returns an error is listed in the
+// response's batchItemFailures, so Lambda deletes the successful messages
+// and only the failures become visible again. The event source mapping must
+// enable ReportBatchItemFailures; without it, Lambda ignores the response
+// and deletes the whole batch. Use [WithoutPartialBatchResponse] for
+// mappings that don't.
+//
+// Messages from FIFO queues are processed in order within each message
+// group, with [WithBatchConcurrency] limiting how many groups are processed
+// at once. When a message fails, the rest of its group is reported as failed
+// without being processed, so the retry delivers the group in order.
+func SQSHandler(handler func(context.Context, vokerevents.SQSMessage) error, opts ...BatchOption) func(context.Context, vokerevents.SQSEvent) (vokerevents.SQSEventResponse, error) {
+	options := newBatchOptions(opts)
+	return func(ctx context.Context, event vokerevents.SQSEvent) (vokerevents.SQSEventResponse, error) {
+		var errs []error
+		if isFIFOBatch(event.Records) {
+			errs = processFIFOMessages(ctx, event.Records, options.concurrency, handler)
+		} else {
+			errs = ProcessBatch(ctx, event.Records, options.concurrency, handler)
+		}
+
+		if options.failWithBatchError {
+			err := NewBatchError(event.Records, errs, func(msg vokerevents.SQSMessage) string { return msg.MessageID })
+			return vokerevents.SQSEventResponse{BatchItemFailures: []vokerevents.BatchItemFailure{}}, err
+		}
+
+		response := vokerevents.SQSEventResponse{BatchItemFailures: []vokerevents.BatchItemFailure{}}
+		for i, err := range errs {
+			if err != nil {
+				response.BatchItemFailures = append(response.BatchItemFailures, vokerevents.BatchItemFailure{
+					ItemIdentifier: event.Records[i].MessageID,
+				})
+			}
+		}
+		return response, nil
+	}
+}
+
+// sqsAttributeMessageGroupID is the system attribute Lambda sets on
+// messages from FIFO queues.
+const sqsAttributeMessageGroupID = "MessageGroupId"
+
+// errSkippedAfterGroupFailure fails the messages of a FIFO message group
+// that follow a failed message.
+var errSkippedAfterGroupFailure = errors.New("skipped after an earlier message in the group failed")
+
+func isFIFOBatch(records []vokerevents.SQSMessage) bool {
+	return len(records) > 0 && records[0].Attributes[sqsAttributeMessageGroupID] != ""
+}
+
+// processFIFOMessages processes the message groups of a FIFO batch
+// concurrently and the messages of each group in order.
+func processFIFOMessages(ctx context.Context, records []vokerevents.SQSMessage, concurrency int, handler func(context.Context, vokerevents.SQSMessage) error) []error {
+	var groups [][]int
+	groupIndex := make(map[string]int)
+	for i, record := range records {
+		id := record.Attributes[sqsAttributeMessageGroupID]
+		g, ok := groupIndex[id]
+		if !ok {
+			g = len(groups)
+			groupIndex[id] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+
+	errs := make([]error, len(records))
+	ProcessBatch(ctx, groups, concurrency, func(ctx context.Context, group []int) error {
+		for n, i := range group {
+			if errs[i] = processRecord(ctx, i, records[i], handler); errs[i] != nil {
+				for _, skipped := range group[n+1:] {
+					errs[skipped] = errSkippedAfterGroupFailure
+				}
+				break
+			}
+		}
+		return nil
+	})
+	return errs
+}
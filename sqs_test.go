@@ -0,0 +1,144 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hotsock/voker/vokerevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSQSEvent(ids ...string) vokerevents.SQSEvent {
+	event := vokerevents.SQSEvent{}
+	for _, id := range ids {
+		event.Records = append(event.Records, vokerevents.SQSMessage{MessageID: id, Body: id})
+	}
+	return event
+}
+
+func TestSQSHandler_ReportsFailuresInOrder(t *testing.T) {
+	var processed []string
+	handler := SQSHandler(func(_ context.Context, msg vokerevents.SQSMessage) error {
+		processed = append(processed, msg.MessageID)
+		if msg.Body == "b" || msg.Body == "d" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	response, err := handler(context.Background(), newTestSQSEvent("a", "b", "c", "d"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, processed)
+	assert.Equal(t, []vokerevents.BatchItemFailure{{ItemIdentifier: "b"}, {ItemIdentifier: "d"}}, response.BatchItemFailures)
+}
+
+func TestSQSHandler_AllSucceededMarshalsEmptyList(t *testing.T) {
+	handler := SQSHandler(func(context.Context, vokerevents.SQSMessage) error { return nil })
+
+	response, err := handler(context.Background(), newTestSQSEvent("a"))
+	require.NoError(t, err)
+	b, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"batchItemFailures":[]}`, string(b))
+}
+
+func TestSQSHandler_Concurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	handler := SQSHandler(func(_ context.Context, msg vokerevents.SQSMessage) error {
+		updatePeak(&peak, running.Add(1))
+		defer running.Add(-1)
+		time.Sleep(10 * time.Millisecond)
+		if msg.Body == "e" || msg.Body == "a" {
+			return errors.New("failed")
+		}
+		return nil
+	}, WithBatchConcurrency(3))
+
+	response, err := handler(context.Background(), newTestSQSEvent("a", "b", "c", "d", "e", "f"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), peak.Load())
+	assert.Equal(t, []vokerevents.BatchItemFailure{{ItemIdentifier: "a"}, {ItemIdentifier: "e"}}, response.BatchItemFailures)
+}
+
+func TestSQSHandler_PassesContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	handler := SQSHandler(func(ctx context.Context, _ vokerevents.SQSMessage) error {
+		assert.Equal(t, "value", ctx.Value(key{}))
+		return nil
+	}, WithBatchConcurrency(2))
+
+	_, err := handler(ctx, newTestSQSEvent("a", "b"))
+	require.NoError(t, err)
+}
+
+func TestSQSHandler_FIFOSkipsRestOfFailedGroup(t *testing.T) {
+	event := vokerevents.SQSEvent{}
+	for _, m := range []struct{ id, group string }{
+		{"a1", "a"}, {"b1", "b"}, {"a2", "a"}, {"b2", "b"}, {"a3", "a"}, {"c1", "c"},
+	} {
+		event.Records = append(event.Records, vokerevents.SQSMessage{
+			MessageID:  m.id,
+			Attributes: map[string]string{"MessageGroupId": m.group},
+		})
+	}
+
+	for _, concurrency := range []int{1, 3} {
+		var mu sync.Mutex
+		processed := map[string][]string{}
+		handler := SQSHandler(func(_ context.Context, msg vokerevents.SQSMessage) error {
+			group := msg.Attributes["MessageGroupId"]
+			mu.Lock()
+			processed[group] = append(processed[group], msg.MessageID)
+			mu.Unlock()
+			if msg.MessageID == "a2" {
+				return errors.New("failed")
+			}
+			return nil
+		}, WithBatchConcurrency(concurrency))
+
+		response, err := handler(context.Background(), event)
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"a": {"a1", "a2"}, "b": {"b1", "b2"}, "c": {"c1"}}, processed)
+		assert.Equal(t, []vokerevents.BatchItemFailure{{ItemIdentifier: "a2"}, {ItemIdentifier: "a3"}}, response.BatchItemFailures)
+	}
+}
+
+func TestSQSHandler_PanicFailsOnlyItsMessage(t *testing.T) {
+	handler := SQSHandler(func(_ context.Context, msg vokerevents.SQSMessage) error {
+		if msg.Body == "b" {
+			panic("bad message")
+		}
+		return nil
+	})
+
+	response, err := handler(context.Background(), newTestSQSEvent("a", "b", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, []vokerevents.BatchItemFailure{{ItemIdentifier: "b"}}, response.BatchItemFailures)
+}
+
+func TestSQSHandler_WithoutPartialBatchResponse(t *testing.T) {
+	handler := SQSHandler(func(_ context.Context, msg vokerevents.SQSMessage) error {
+		if msg.Body == "b" {
+			return errors.New("failed")
+		}
+		return nil
+	}, WithoutPartialBatchResponse())
+
+	response, err := handler(context.Background(), newTestSQSEvent("a", "b", "c"))
+	batch, ok := errors.AsType[*BatchError](err)
+	require.True(t, ok)
+	assert.Equal(t, 3, batch.Records)
+	require.Len(t, batch.Failures, 1)
+	assert.Equal(t, "b", batch.Failures[0].ItemIdentifier)
+	assert.Empty(t, response.BatchItemFailures)
+
+	_, err = handler(context.Background(), newTestSQSEvent("a", "c"))
+	require.NoError(t, err)
+}
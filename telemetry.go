@@ -0,0 +1,135 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// TelemetryType identifies a stream of the Lambda Telemetry API.
+type TelemetryType string
+
+const (
+	// TelemetryPlatform carries platform events such as platform.start and
+	// platform.report.
+	TelemetryPlatform TelemetryType = "platform"
+	// TelemetryFunction carries the function's log lines.
+	TelemetryFunction TelemetryType = "function"
+	// TelemetryExtension carries the log lines of extensions.
+	TelemetryExtension TelemetryType = "extension"
+)
+
+// TelemetryEvent is one record delivered by the Lambda Telemetry API, as
+// documented in
+// https://docs.aws.amazon.com/lambda/latest/dg/telemetry-schema-reference.html.
+type TelemetryEvent struct {
+	Time time.Time `json:"time"`
+
+	// Type is "function" or "extension" for log lines, or a platform event
+	// type such as "platform.report".
+	Type string `json:"type"`
+
+	// Record is a JSON string for text log lines, and an object for JSON log
+	// lines and platform events.
+	Record json.RawMessage `json:"record"`
+}
+
+// TelemetrySubscription configures the Telemetry API subscription of an
+// [InternalExtension] with an OnTelemetry callback.
+type TelemetrySubscription struct {
+	// Types lists the streams to receive. The default is
+	// [TelemetryFunction].
+	Types []TelemetryType
+
+	// MaxItems, MaxBytes, and Timeout control how long Lambda buffers
+	// telemetry before delivering a batch. Zero values use Lambda's
+	// defaults of 10,000 events, 256 KB, and 1 second.
+	MaxItems int
+	MaxBytes int
+	Timeout  time.Duration
+}
+
+// telemetryHost is the sandbox hostname the Telemetry API delivers to.
+var telemetryHost = "sandbox.localdomain"
+
+const telemetrySchemaVersion = "2022-12-13"
+
+// telemetryDrainTimeout is how long the listener keeps accepting deliveries
+// after SIGTERM, so a batch Lambda flushes at shutdown still reaches
+// OnTelemetry. It leaves most of the shutdown deadline to OnSIGTERM.
+const telemetryDrainTimeout = 100 * time.Millisecond
+
+// telemetryListener receives the batches the Telemetry API posts for one
+// extension and passes them to its OnTelemetry callback.
+type telemetryListener struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// startTelemetryListener listens on an ephemeral port of the sandbox
+// hostname, the only destination host the Telemetry API accepts.
+func startTelemetryListener(ext InternalExtension, logger *slog.Logger) (*telemetryListener, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(telemetryHost, "0"))
+	if err != nil {
+		return nil, err
+	}
+	l := &telemetryListener{listener: listener}
+	l.server = &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var events []TelemetryEvent
+			if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+				http.Error(w, "invalid telemetry batch", http.StatusBadRequest)
+				return
+			}
+			if err := callOnTelemetry(r.Context(), ext, events); err != nil {
+				logger.ErrorContext(r.Context(), "extension telemetry handler panicked", "extension", ext.Name, "error", err)
+				http.Error(w, "telemetry handler failed", http.StatusInternalServerError)
+			}
+		}),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := l.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			_ = listener.Close()
+		}
+	}()
+	return l, nil
+}
+
+// uri returns the destination to subscribe with.
+func (l *telemetryListener) uri() string {
+	port := l.listener.Addr().(*net.TCPAddr).Port
+	return "http://" + net.JoinHostPort(telemetryHost, strconv.Itoa(port))
+}
+
+// callOnTelemetry passes a delivery to the extension, recovering a panic so
+// that one bad batch doesn't take the function down.
+func callOnTelemetry(ctx context.Context, ext InternalExtension, events []TelemetryEvent) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+	ext.OnTelemetry(ctx, events)
+	return nil
+}
+
+// shutdown keeps accepting deliveries for the drain timeout, then stops
+// and waits for in-flight OnTelemetry calls until ctx is done.
+func (l *telemetryListener) shutdown(ctx context.Context) {
+	drain := time.NewTimer(telemetryDrainTimeout)
+	select {
+	case <-drain.C:
+	case <-ctx.Done():
+		drain.Stop()
+	}
+	if err := l.server.Shutdown(ctx); err != nil {
+		_ = l.server.Close()
+	}
+}
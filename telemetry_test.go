@@ -0,0 +1,173 @@
+package voker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtensionManager_Telemetry(t *testing.T) {
+	telemetryHost = "127.0.0.1"
+	t.Cleanup(func() { telemetryHost = "sandbox.localdomain" })
+
+	subscriptions := make(chan telemetrySubscribeRequest, 1)
+	var subscribedID string
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2020-01-01/extension/register":
+			w.Header().Set(headerExtensionIdentifier, "test-id")
+		case "/2020-01-01/extension/event/next":
+			<-done
+		case "/2022-07-01/telemetry":
+			assert.Equal(t, http.MethodPut, r.Method)
+			subscribedID = r.Header.Get(headerExtensionIdentifier)
+			var subscription telemetrySubscribeRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&subscription))
+			subscriptions <- subscription
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	received := make(chan []TelemetryEvent, 1)
+	ext := InternalExtension{
+		Name: "telemetry",
+		OnTelemetry: func(ctx context.Context, events []TelemetryEvent) {
+			received <- events
+		},
+		Telemetry: TelemetrySubscription{Types: []TelemetryType{TelemetryPlatform, TelemetryFunction}, MaxItems: 1000, Timeout: 25 * time.Millisecond},
+	}
+	mgr := newExtensionManager(server.Listener.Addr().String(), []InternalExtension{ext}, slog.New(slog.DiscardHandler))
+	require.NoError(t, mgr.start())
+
+	subscription := <-subscriptions
+	assert.Equal(t, "test-id", subscribedID)
+	assert.Equal(t, telemetrySchemaVersion, subscription.SchemaVersion)
+	assert.Equal(t, []TelemetryType{TelemetryPlatform, TelemetryFunction}, subscription.Types)
+	assert.Equal(t, telemetryBuffering{MaxItems: 1000, TimeoutMs: 25}, subscription.Buffering)
+	assert.Equal(t, "HTTP", subscription.Destination.Protocol)
+	assert.Regexp(t, `^http://127\.0\.0\.1:\d+$`, subscription.Destination.URI)
+
+	batch := `[{"time":"2022-10-12T00:03:50.000Z","type":"function","record":"hello\n"},{"time":"2022-10-12T00:03:50.001Z","type":"platform.start","record":{"requestId":"abc"}}]`
+	resp, err := http.Post(subscription.Destination.URI, "application/json", bytes.NewReader([]byte(batch)))
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	events := <-received
+	require.Len(t, events, 2)
+	assert.Equal(t, "function", events[0].Type)
+	assert.JSONEq(t, `"hello\n"`, string(events[0].Record))
+	assert.Equal(t, time.Date(2022, 10, 12, 0, 3, 50, 1_000_000, time.UTC), events[1].Time)
+
+	resp, err = http.Post(subscription.Destination.URI, "application/json", bytes.NewReader([]byte("not json")))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mgr.shutdown()
+	_, err = http.Post(subscription.Destination.URI, "application/json", bytes.NewReader([]byte(batch)))
+	assert.Error(t, err)
+}
+
+func TestExtensionManager_TelemetrySubscribeError(t *testing.T) {
+	telemetryHost = "127.0.0.1"
+	t.Cleanup(func() { telemetryHost = "sandbox.localdomain" })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2020-01-01/extension/register":
+			w.Header().Set(headerExtensionIdentifier, "test-id")
+		case "/2022-07-01/telemetry":
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	ext := InternalExtension{Name: "telemetry", OnTelemetry: func(context.Context, []TelemetryEvent) {}}
+	mgr := newExtensionManager(server.Listener.Addr().String(), []InternalExtension{ext}, slog.New(slog.DiscardHandler))
+	assert.EqualError(t, mgr.start(), "failed to subscribe extension telemetry to telemetry: subscribe failed with status: 403")
+}
+
+func TestTelemetryListener_RecoversPanics(t *testing.T) {
+	telemetryHost = "127.0.0.1"
+	t.Cleanup(func() { telemetryHost = "sandbox.localdomain" })
+
+	var logs bytes.Buffer
+	calls := 0
+	ext := InternalExtension{
+		Name: "telemetry",
+		OnTelemetry: func(ctx context.Context, events []TelemetryEvent) {
+			calls++
+			if calls == 1 {
+				panic("bad batch")
+			}
+		},
+	}
+	listener, err := startTelemetryListener(ext, slog.New(slog.NewTextHandler(&logs, nil)))
+	require.NoError(t, err)
+	defer listener.shutdown(context.Background())
+
+	for _, want := range []int{http.StatusInternalServerError, http.StatusOK} {
+		resp, err := http.Post(listener.uri(), "application/json", bytes.NewReader([]byte(`[]`)))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, want, resp.StatusCode)
+	}
+	assert.Contains(t, logs.String(), `msg="extension telemetry handler panicked" extension=telemetry error="bad batch"`)
+}
+
+func TestTelemetryListener_DrainsOnShutdown(t *testing.T) {
+	telemetryHost = "127.0.0.1"
+	t.Cleanup(func() { telemetryHost = "sandbox.localdomain" })
+
+	received := make(chan []TelemetryEvent, 1)
+	ext := InternalExtension{
+		Name:        "telemetry",
+		OnTelemetry: func(ctx context.Context, events []TelemetryEvent) { received <- events },
+	}
+	listener, err := startTelemetryListener(ext, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	stopped := make(chan struct{})
+	go func() {
+		listener.shutdown(context.Background())
+		close(stopped)
+	}()
+
+	// A batch Lambda flushes right after SIGTERM is still delivered.
+	resp, err := http.Post(listener.uri(), "application/json", bytes.NewReader([]byte(`[{"type":"function","record":"bye"}]`)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, <-received, 1)
+
+	<-stopped
+	_, err = http.Post(listener.uri(), "application/json", bytes.NewReader([]byte(`[]`)))
+	assert.Error(t, err)
+}
+
+func TestTelemetryListener_ShutdownDeadline(t *testing.T) {
+	telemetryHost = "127.0.0.1"
+	t.Cleanup(func() { telemetryHost = "sandbox.localdomain" })
+
+	listener, err := startTelemetryListener(InternalExtension{Name: "telemetry", OnTelemetry: func(context.Context, []TelemetryEvent) {}}, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	listener.shutdown(ctx)
+	assert.Less(t, time.Since(start), telemetryDrainTimeout)
+}
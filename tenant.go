@@ -0,0 +1,168 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// TenantSource derives a tenant identifier from an invocation. It returns ""
+// when the invocation doesn't identify a tenant the way the source expects.
+type TenantSource func(ctx context.Context, payload json.RawMessage) string
+
+type tenantContextKey struct{}
+
+// NewTenantContext returns a copy of parent that carries tenant. Use it in
+// tests, or in custom middleware that resolves tenants some other way.
+func NewTenantContext(parent context.Context, tenant string) context.Context {
+	return context.WithValue(parent, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant identifier [WithTenant] resolved for
+// the invocation that owns ctx.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenant, ok
+}
+
+// WithTenant resolves a tenant identifier for every invocation from the
+// first source that returns one, and stores it in the context for
+// [TenantFromContext]. vokerslog adds it to log records as tenantId,
+// vokerotel adds it to the invocation span as tenant.id, and it is reported
+// to the [MetricsSink] as [InvocationMetrics].TenantID.
+//
+//	voker.Start(handler, voker.WithTenant(
+//	    voker.TenantFromClaim("custom:tenant_id"),
+//	    voker.TenantFromHeader("X-Tenant-Id"),
+//	))
+//
+// Invocations no source identifies run without a tenant. The resolver is
+// middleware, so register it before other middleware, such as
+// vokerotel.Middleware, that should see the tenant.
+func WithTenant(sources ...TenantSource) Option {
+	return WithMiddleware(func(next InvokeFunc) InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			for _, source := range sources {
+				if tenant := source(ctx, payload); tenant != "" {
+					recorderFromContext(ctx).tenant(tenant)
+					return next(NewTenantContext(ctx, tenant), payload)
+				}
+			}
+			return next(ctx, payload)
+		}
+	})
+}
+
+// TenantFromLambda uses the tenant ID Lambda sends to functions in tenant
+// isolation mode, [LambdaContext].TenantID.
+func TenantFromLambda() TenantSource {
+	return func(ctx context.Context, _ json.RawMessage) string {
+		if lc, ok := FromContext(ctx); ok && lc != nil {
+			return lc.TenantID
+		}
+		return ""
+	}
+}
+
+// TenantFromHeader uses a request header of an API Gateway, Function URL,
+// or ALB event. The name is matched case-insensitively.
+func TenantFromHeader(name string) TenantSource {
+	return func(_ context.Context, payload json.RawMessage) string {
+		var event struct {
+			Headers           map[string]string   `json:"headers"`
+			MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+		}
+		if json.Unmarshal(payload, &event) != nil {
+			return ""
+		}
+		for key, value := range event.Headers {
+			if strings.EqualFold(key, name) && value != "" {
+				return value
+			}
+		}
+		for key, values := range event.MultiValueHeaders {
+			if strings.EqualFold(key, name) && len(values) > 0 {
+				return values[0]
+			}
+		}
+		return ""
+	}
+}
+
+// TenantFromClaim uses a claim, such as "custom:tenant_id", that an API
+// Gateway authorizer verified: an HTTP API JWT authorizer, a REST API
+// Cognito user pool authorizer, or the context a Lambda authorizer
+// returned. The token itself is not read, since nothing here verifies it.
+func TenantFromClaim(claim string) TenantSource {
+	return func(_ context.Context, payload json.RawMessage) string {
+		var event struct {
+			RequestContext struct {
+				Authorizer struct {
+					JWT struct {
+						Claims map[string]json.RawMessage `json:"claims"`
+					} `json:"jwt"`
+					Claims map[string]json.RawMessage `json:"claims"`
+					Lambda map[string]json.RawMessage `json:"lambda"`
+				} `json:"authorizer"`
+			} `json:"requestContext"`
+		}
+		if !strings.Contains(string(payload), claim) || json.Unmarshal(payload, &event) != nil {
+			return ""
+		}
+		authorizer := event.RequestContext.Authorizer
+		for _, claims := range []map[string]json.RawMessage{authorizer.JWT.Claims, authorizer.Claims, authorizer.Lambda} {
+			if tenant := tenantString(claims[claim]); tenant != "" {
+				return tenant
+			}
+		}
+
+		// REST API Lambda authorizers return their context as top-level
+		// authorizer fields.
+		return TenantFromField("requestContext", "authorizer", claim)(context.Background(), payload)
+	}
+}
+
+// TenantFromCognitoIdentity uses the Cognito identity pool ID of an
+// invocation made with Cognito credentials, for applications that give each
+// tenant its own identity pool.
+func TenantFromCognitoIdentity() TenantSource {
+	return func(ctx context.Context, _ json.RawMessage) string {
+		if lc, ok := FromContext(ctx); ok && lc != nil {
+			return lc.Identity.CognitoIdentityPoolID
+		}
+		return ""
+	}
+}
+
+// TenantFromField uses the string or number found by following path through
+// the event's JSON objects, such as TenantFromField("detail", "tenantId")
+// for an EventBridge event.
+func TenantFromField(path ...string) TenantSource {
+	return func(_ context.Context, payload json.RawMessage) string {
+		value := payload
+		for _, key := range path {
+			var object map[string]json.RawMessage
+			if json.Unmarshal(value, &object) != nil {
+				return ""
+			}
+			if value = object[key]; value == nil {
+				return ""
+			}
+		}
+		return tenantString(value)
+	}
+}
+
+// tenantString returns a JSON string's value or a JSON number's text, and ""
+// for anything else.
+func tenantString(value json.RawMessage) string {
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(value, &n) == nil {
+		return n.String()
+	}
+	return ""
+}
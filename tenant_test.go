@@ -0,0 +1,110 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantSources(t *testing.T) {
+	ctx := NewContext(context.Background(), &LambdaContext{
+		TenantID: "lambda-tenant",
+		Identity: CognitoIdentity{CognitoIdentityID: "us-east-1:identity", CognitoIdentityPoolID: "us-east-1:pool"},
+	})
+
+	tests := []struct {
+		name    string
+		source  TenantSource
+		payload string
+		want    string
+	}{
+		{"lambda", TenantFromLambda(), `{}`, "lambda-tenant"},
+		{"cognito identity", TenantFromCognitoIdentity(), `{}`, "us-east-1:pool"},
+		{"header", TenantFromHeader("X-Tenant-Id"), `{"headers":{"x-tenant-id":"acme"}}`, "acme"},
+		{"multi-value header", TenantFromHeader("X-Tenant-Id"), `{"headers":null,"multiValueHeaders":{"X-Tenant-Id":["acme","other"]}}`, "acme"},
+		{"missing header", TenantFromHeader("X-Tenant-Id"), `{"headers":{"host":"example.com"}}`, ""},
+		{"HTTP API JWT claim", TenantFromClaim("custom:tenant_id"), `{"requestContext":{"authorizer":{"jwt":{"claims":{"custom:tenant_id":"acme"}}}}}`, "acme"},
+		{"REST API Cognito claim", TenantFromClaim("custom:tenant_id"), `{"requestContext":{"authorizer":{"claims":{"custom:tenant_id":"acme"}}}}`, "acme"},
+		{"HTTP API Lambda authorizer", TenantFromClaim("tenant"), `{"requestContext":{"authorizer":{"lambda":{"tenant":42}}}}`, "42"},
+		{"REST API Lambda authorizer", TenantFromClaim("tenant"), `{"requestContext":{"authorizer":{"tenant":"acme","principalId":"user"}}}`, "acme"},
+		{"unverified claim", TenantFromClaim("tenant"), `{"body":"{\"tenant\":\"acme\"}"}`, ""},
+		{"field", TenantFromField("detail", "tenantId"), `{"detail":{"tenantId":"acme"}}`, "acme"},
+		{"numeric field", TenantFromField("tenantId"), `{"tenantId":7}`, "7"},
+		{"object field", TenantFromField("detail"), `{"detail":{"tenantId":"acme"}}`, ""},
+		{"missing field", TenantFromField("detail", "tenantId"), `{"detail":"acme"}`, ""},
+		{"not JSON", TenantFromField("tenantId"), `tenantId`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.source(ctx, json.RawMessage(tt.payload)))
+		})
+	}
+
+	assert.Empty(t, TenantFromLambda()(context.Background(), nil))
+	assert.Empty(t, TenantFromCognitoIdentity()(context.Background(), nil))
+}
+
+func TestWithTenant(t *testing.T) {
+	var tenants []string
+	handler := func(ctx context.Context, event testEvent) (testResponse, error) {
+		tenant, ok := TenantFromContext(ctx)
+		if !ok {
+			tenant = "none"
+		}
+		tenants = append(tenants, tenant)
+		return testResponse{}, nil
+	}
+
+	opts := &options{}
+	WithTenant(TenantFromHeader("X-Tenant-Id"), TenantFromField("tenantId"))(opts)
+
+	for _, payload := range []string{
+		`{"headers":{"x-tenant-id":"header-tenant"},"tenantId":"field-tenant"}`,
+		`{"tenantId":"field-tenant"}`,
+		`{"name":"world"}`,
+	} {
+		_, err := callHandler(context.Background(), []byte(payload), handler, opts)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"header-tenant", "field-tenant", "none"}, tenants)
+}
+
+func TestWithTenant_Metrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			w.Header().Set(headerRequestID, "tenant-1")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			w.Header().Set(headerTenantID, "acme")
+			_, _ = w.Write([]byte(`{"name":"tenant"}`))
+		default:
+			time.Sleep(5 * time.Millisecond)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	client := newRuntimeClient(server.URL[7:], logger)
+	sink := &recordingSink{}
+	options := &options{logger: logger}
+	WithMetrics(sink)(options)
+	WithTenant(TenantFromLambda())(options)
+
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		return testResponse{}, nil
+	}
+	require.NoError(t, handleInvocation(client, handler, options))
+
+	require.Len(t, sink.metrics, 1)
+	assert.Equal(t, "acme", sink.metrics[0].TenantID)
+}
@@ -0,0 +1,103 @@
+package voker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const (
+	headerTraceParent = "traceparent"
+	headerTraceState  = "tracestate"
+)
+
+// InjectTraceContext sets the traceparent and tracestate headers of an
+// outgoing request from the W3C Trace Context the invocation received, so
+// downstream services continue the caller's trace. It does nothing when the
+// context carries no trace context.
+//
+// The headers are forwarded unchanged, so downstream spans become children of
+// the caller's span. Tracing libraries that start a span for the invocation
+// should inject their own context instead.
+func InjectTraceContext(ctx context.Context, header http.Header) {
+	lc, ok := FromContext(ctx)
+	if !ok || lc.TraceParent == "" {
+		return
+	}
+	header.Set(headerTraceParent, lc.TraceParent)
+	if lc.TraceState != "" {
+		header.Set(headerTraceState, lc.TraceState)
+	} else {
+		header.Del(headerTraceState)
+	}
+}
+
+// traceContextFromPayload returns the W3C Trace Context headers of an
+// HTTP-shaped event. Payloads that cannot contain a traceparent header are
+// rejected without decoding, so other events pay only for a substring scan.
+func traceContextFromPayload(payload []byte) (traceParent, traceState string) {
+	// Matches "traceparent" and "Traceparent".
+	if !bytes.Contains(payload, []byte("raceparent")) {
+		return "", ""
+	}
+
+	var event struct {
+		Headers           map[string]string   `json:"headers"`
+		MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return "", ""
+	}
+
+	header := http.Header{}
+	for name, value := range event.Headers {
+		header.Set(name, value)
+	}
+	for name, values := range event.MultiValueHeaders {
+		header.Del(name)
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+
+	traceParent = header.Get(headerTraceParent)
+	if !validTraceParent(traceParent) {
+		return "", ""
+	}
+	return traceParent, strings.Join(header.Values(headerTraceState), ",")
+}
+
+// validTraceParent reports whether value is a well-formed traceparent header,
+// such as "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01". Versions
+// after 00 may append fields, which are ignored.
+func validTraceParent(value string) bool {
+	if len(value) < 55 || (len(value) > 55 && value[55] != '-') {
+		return false
+	}
+	version, traceID, parentID, flags := value[0:2], value[3:35], value[36:52], value[53:55]
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return false
+	}
+	if !isLowerHex(version) || version == "ff" || (version == "00" && len(value) != 55) {
+		return false
+	}
+	if !isLowerHex(traceID) || strings.Trim(traceID, "0") == "" {
+		return false
+	}
+	if !isLowerHex(parentID) || strings.Trim(parentID, "0") == "" {
+		return false
+	}
+	return isLowerHex(flags)
+}
+
+func isLowerHex(s string) bool {
+	for i := range len(s) {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package voker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTraceContextFromPayload(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     string
+		traceParent string
+		traceState  string
+	}{
+		{
+			name:        "function url",
+			payload:     `{"headers":{"traceparent":"` + testTraceParent + `","tracestate":"congo=t61rcWkgMzE"}}`,
+			traceParent: testTraceParent,
+			traceState:  "congo=t61rcWkgMzE",
+		},
+		{
+			name:        "api gateway v1 canonical case",
+			payload:     `{"headers":{"Traceparent":"` + testTraceParent + `"},"multiValueHeaders":{"Traceparent":["` + testTraceParent + `"],"Tracestate":["a=1","b=2"]}}`,
+			traceParent: testTraceParent,
+			traceState:  "a=1,b=2",
+		},
+		{
+			name:    "invalid traceparent",
+			payload: `{"headers":{"traceparent":"00-00000000000000000000000000000000-00f067aa0ba902b7-01"}}`,
+		},
+		{
+			name:    "no headers",
+			payload: `{"name":"traceparent"}`,
+		},
+		{
+			name:    "not http",
+			payload: `{"Records":[]}`,
+		},
+		{
+			name:    "invalid json",
+			payload: `{"headers":{"traceparent"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceParent, traceState := traceContextFromPayload([]byte(tt.payload))
+			assert.Equal(t, tt.traceParent, traceParent)
+			assert.Equal(t, tt.traceState, traceState)
+		})
+	}
+}
+
+func TestValidTraceParent(t *testing.T) {
+	assert.True(t, validTraceParent(testTraceParent))
+	assert.True(t, validTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future"))
+	assert.False(t, validTraceParent(testTraceParent+"-extra"))
+	assert.False(t, validTraceParent("ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	assert.False(t, validTraceParent("00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"))
+	assert.False(t, validTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"))
+	assert.False(t, validTraceParent("00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	assert.False(t, validTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"))
+}
+
+func TestInjectTraceContext(t *testing.T) {
+	header := http.Header{}
+	InjectTraceContext(context.Background(), header)
+	assert.Empty(t, header)
+
+	ctx := NewContext(context.Background(), &LambdaContext{TraceParent: testTraceParent, TraceState: "a=1"})
+	InjectTraceContext(ctx, header)
+	assert.Equal(t, testTraceParent, header.Get("Traceparent"))
+	assert.Equal(t, "a=1", header.Get("Tracestate"))
+
+	header.Set("Tracestate", "stale=1")
+	InjectTraceContext(NewContext(context.Background(), &LambdaContext{TraceParent: testTraceParent}), header)
+	assert.Empty(t, header.Values("Tracestate"))
+}
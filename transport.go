@@ -0,0 +1,70 @@
+package voker
+
+import "net/http"
+
+// Headers InstrumentedTransport sets on outgoing requests.
+const (
+	outgoingRequestIDHeader = "X-Request-Id"
+	outgoingTraceIDHeader   = "X-Amzn-Trace-Id"
+	outgoingTenantIDHeader  = "X-Tenant-Id"
+)
+
+// InstrumentedTransport returns an http.RoundTripper that adds the
+// correlation data of the invocation that owns a request's context to the
+// request before passing it to base:
+//
+//   - X-Request-Id: the invocation's AWS request ID
+//   - X-Amzn-Trace-Id: the invocation's X-Ray trace header
+//   - traceparent and tracestate: the W3C Trace Context the invocation
+//     received, as [InjectTraceContext] sets them
+//   - X-Tenant-Id: the tenant [WithTenant] resolved
+//
+// Headers the request already carries, and values the invocation doesn't
+// have, are left alone, and requests made outside an invocation are sent
+// unchanged. A nil base uses http.DefaultTransport.
+//
+//	client := &http.Client{Transport: voker.InstrumentedTransport(nil)}
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://orders.internal/orders", nil)
+//	resp, err := client.Do(req)
+func InstrumentedTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &instrumentedTransport{base: base}
+}
+
+type instrumentedTransport struct {
+	base http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	lc, ok := FromContext(ctx)
+	if !ok || lc == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the caller's request.
+	header := req.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	setMissing(header, outgoingRequestIDHeader, lc.AwsRequestID)
+	setMissing(header, outgoingTraceIDHeader, lc.TraceID)
+	if header.Get(headerTraceParent) == "" {
+		InjectTraceContext(ctx, header)
+	}
+	if tenant, ok := TenantFromContext(ctx); ok {
+		setMissing(header, outgoingTenantIDHeader, tenant)
+	}
+
+	out := req.Clone(ctx)
+	out.Header = header
+	return t.base.RoundTrip(out)
+}
+
+func setMissing(header http.Header, name, value string) {
+	if value != "" && header.Get(name) == "" {
+		header.Set(name, value)
+	}
+}
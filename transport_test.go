@@ -0,0 +1,60 @@
+package voker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedTransport(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: InstrumentedTransport(server.Client().Transport)}
+	send := func(ctx context.Context, header http.Header) *http.Request {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return req
+	}
+
+	ctx := NewContext(context.Background(), &LambdaContext{
+		AwsRequestID: "request-123",
+		TraceID:      "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=1",
+		TraceParent:  "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		TraceState:   "vendor=value",
+	})
+	ctx = NewTenantContext(ctx, "tenant-a")
+	req := send(ctx, nil)
+	assert.Equal(t, "request-123", received.Get("X-Request-Id"))
+	assert.Equal(t, "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=1", received.Get("X-Amzn-Trace-Id"))
+	assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", received.Get("traceparent"))
+	assert.Equal(t, "vendor=value", received.Get("tracestate"))
+	assert.Equal(t, "tenant-a", received.Get("X-Tenant-Id"))
+	assert.Empty(t, req.Header, "the caller's request must not be modified")
+
+	// Headers the caller set win.
+	send(ctx, http.Header{"X-Request-Id": {"custom"}, "Traceparent": {"00-11111111111111111111111111111111-2222222222222222-01"}})
+	assert.Equal(t, "custom", received.Get("X-Request-Id"))
+	assert.Equal(t, "00-11111111111111111111111111111111-2222222222222222-01", received.Get("traceparent"))
+	assert.Empty(t, received.Get("tracestate"))
+
+	// Outside an invocation nothing is added.
+	send(context.Background(), nil)
+	assert.Empty(t, received.Get("X-Request-Id"))
+	assert.Empty(t, received.Get("X-Amzn-Trace-Id"))
+	assert.Empty(t, received.Get("X-Tenant-Id"))
+}
@@ -0,0 +1,80 @@
+package voker
+
+import (
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+)
+
+const (
+	lambdaEnvFunctionMemorySize = "AWS_LAMBDA_FUNCTION_MEMORY_SIZE"
+
+	// megabytesPerVCPU is the memory size at which Lambda allocates the
+	// equivalent of one full vCPU. CPU scales linearly with memory.
+	megabytesPerVCPU = 1769
+
+	// memoryLimitPercent of the function's memory is given to the Go
+	// runtime as its soft memory limit. The remainder covers non-heap
+	// memory the limit doesn't account for and any external extensions
+	// sharing the sandbox.
+	memoryLimitPercent = 90
+)
+
+// WithResourceTuning sizes the Go runtime to the function's configuration
+// during initialization. GOMAXPROCS is lowered to the number of vCPUs
+// implied by AWS_LAMBDA_FUNCTION_MEMORY_SIZE, rounded up, so small functions
+// don't schedule more Ps than they have CPU time for. The soft memory limit
+// is set to 90% of the memory size so the garbage collector works harder as
+// the heap nears the limit instead of the sandbox running out of memory.
+//
+// Explicit GOMAXPROCS and GOMEMLIMIT environment variables take precedence.
+// GOMAXPROCS is left alone on Lambda Managed Instances, whose CPU allocation
+// doesn't follow the memory size.
+func WithResourceTuning() Option {
+	return func(o *options) {
+		o.resourceTuning = true
+	}
+}
+
+type resourceTuning struct {
+	maxProcs    int
+	memoryLimit int64
+}
+
+// computeResourceTuning returns the settings to apply, leaving a field zero
+// when it should not be changed.
+func computeResourceTuning(getenv func(string) string, currentProcs int) resourceTuning {
+	memoryMB, err := strconv.Atoi(getenv(lambdaEnvFunctionMemorySize))
+	if err != nil || memoryMB <= 0 {
+		return resourceTuning{}
+	}
+
+	var tuning resourceTuning
+	if getenv("GOMAXPROCS") == "" && InitializationType(getenv(lambdaEnvInitializationType)) != InitManagedInstances {
+		procs := (memoryMB + megabytesPerVCPU - 1) / megabytesPerVCPU
+		if procs < currentProcs {
+			tuning.maxProcs = procs
+		}
+	}
+	if getenv("GOMEMLIMIT") == "" {
+		tuning.memoryLimit = int64(memoryMB) << 20 * memoryLimitPercent / 100
+	}
+	return tuning
+}
+
+func applyResourceTuning(e env, logger *slog.Logger) {
+	tuning := computeResourceTuning(func(key string) string {
+		return getenv(e, key)
+	}, runtime.GOMAXPROCS(0))
+	if tuning.maxProcs > 0 {
+		runtime.GOMAXPROCS(tuning.maxProcs)
+	}
+	if tuning.memoryLimit > 0 {
+		debug.SetMemoryLimit(tuning.memoryLimit)
+	}
+	logger.Debug("applied resource tuning",
+		"gomaxprocs", runtime.GOMAXPROCS(0),
+		"gomemlimit", debug.SetMemoryLimit(-1),
+	)
+}
@@ -0,0 +1,79 @@
+package voker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeResourceTuning(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		currentProcs int
+		want         resourceTuning
+	}{
+		{
+			name:         "no memory size",
+			env:          map[string]string{},
+			currentProcs: 2,
+			want:         resourceTuning{},
+		},
+		{
+			name:         "invalid memory size",
+			env:          map[string]string{lambdaEnvFunctionMemorySize: "lots"},
+			currentProcs: 2,
+			want:         resourceTuning{},
+		},
+		{
+			name:         "small function",
+			env:          map[string]string{lambdaEnvFunctionMemorySize: "128"},
+			currentProcs: 2,
+			want:         resourceTuning{maxProcs: 1, memoryLimit: 128 << 20 * 90 / 100},
+		},
+		{
+			name:         "exactly one vCPU",
+			env:          map[string]string{lambdaEnvFunctionMemorySize: "1769"},
+			currentProcs: 2,
+			want:         resourceTuning{maxProcs: 1, memoryLimit: 1769 << 20 * 90 / 100},
+		},
+		{
+			name:         "fractional vCPU rounds up",
+			env:          map[string]string{lambdaEnvFunctionMemorySize: "3008"},
+			currentProcs: 6,
+			want:         resourceTuning{maxProcs: 2, memoryLimit: 3008 << 20 * 90 / 100},
+		},
+		{
+			name:         "never raises GOMAXPROCS",
+			env:          map[string]string{lambdaEnvFunctionMemorySize: "10240"},
+			currentProcs: 2,
+			want:         resourceTuning{memoryLimit: 10240 << 20 * 90 / 100},
+		},
+		{
+			name: "explicit environment wins",
+			env: map[string]string{
+				lambdaEnvFunctionMemorySize: "128",
+				"GOMAXPROCS":                "4",
+				"GOMEMLIMIT":                "100MiB",
+			},
+			currentProcs: 4,
+			want:         resourceTuning{},
+		},
+		{
+			name: "managed instances keep GOMAXPROCS",
+			env: map[string]string{
+				lambdaEnvFunctionMemorySize: "2048",
+				lambdaEnvInitializationType: string(InitManagedInstances),
+			},
+			currentProcs: 8,
+			want:         resourceTuning{memoryLimit: 2048 << 20 * 90 / 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			assert.Equal(t, tt.want, computeResourceTuning(getenv, tt.currentProcs))
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package voker
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Union decodes JSON into one of several concrete types that implement the
+// interface T, chosen by the value of a discriminator field. Registered
+// with [WithUnion], it lets a handler whose input type is T receive a typed
+// value for each kind of event it handles:
+//
+//	type OrderEvent interface{ OrderID() string }
+//
+//	orders := voker.NewUnion[OrderEvent]("detail-type")
+//	voker.AddVariant[OrderPlaced](orders, "Order Placed")
+//	voker.AddVariant[OrderShipped](orders, "Order Shipped")
+//
+//	voker.Start(func(ctx context.Context, event OrderEvent) (any, error) {
+//	    switch event := event.(type) {
+//	    case OrderPlaced:
+//	        // ...
+//	    case *OrderShipped:
+//	        // ...
+//	    }
+//	}, voker.WithUnion(orders))
+//
+// A Union is not safe for concurrent registration; add its variants before
+// starting the runtime.
+type Union[T any] struct {
+	field    []string
+	variants map[string]func(data []byte, codec Codec) (T, error)
+}
+
+// NewUnion returns a Union that reads the discriminator from field. Nested
+// fields are separated by dots, such as "detail.kind". NewUnion panics if T
+// is not an interface type.
+func NewUnion[T any](field string) *Union[T] {
+	if reflect.TypeFor[T]().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("voker: union type %v is not an interface", reflect.TypeFor[T]()))
+	}
+	return &Union[T]{
+		field:    strings.Split(field, "."),
+		variants: make(map[string]func([]byte, Codec) (T, error)),
+	}
+}
+
+// AddVariant registers V as the type decoded when the discriminator is
+// value, and returns u. Payloads decode into a V when V implements T, or
+// into a *V when only *V does. Discriminators that are JSON strings match
+// value as they are; other discriminators match their JSON text, such as
+// "2" or "true". AddVariant panics if neither V nor *V implements T.
+func AddVariant[V, T any](u *Union[T], value string) *Union[T] {
+	target := reflect.TypeFor[T]()
+	switch {
+	case reflect.TypeFor[V]().Implements(target):
+		u.variants[value] = func(data []byte, codec Codec) (T, error) {
+			var v V
+			err := unmarshal(codec, data, &v)
+			return any(v).(T), err
+		}
+	case reflect.TypeFor[*V]().Implements(target):
+		u.variants[value] = func(data []byte, codec Codec) (T, error) {
+			v := new(V)
+			err := unmarshal(codec, data, v)
+			return any(v).(T), err
+		}
+	default:
+		panic(fmt.Sprintf("voker: union variant %v does not implement %v", reflect.TypeFor[V](), target))
+	}
+	return u
+}
+
+// Unmarshal decodes data with encoding/json into the variant its
+// discriminator selects.
+func (u *Union[T]) Unmarshal(data []byte) (T, error) {
+	return u.decode(data, nil)
+}
+
+func (u *Union[T]) decode(data []byte, codec Codec) (T, error) {
+	var zero T
+	discriminator, err := u.discriminator(data)
+	if err != nil {
+		return zero, err
+	}
+	decode, ok := u.variants[discriminator]
+	if !ok {
+		return zero, fmt.Errorf("unknown %s %q", strings.Join(u.field, "."), discriminator)
+	}
+	return decode(data, codec)
+}
+
+func (u *Union[T]) discriminator(data []byte) (string, error) {
+	raw := json.RawMessage(data)
+	for _, name := range u.field {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return "", err
+		}
+		var ok bool
+		if raw, ok = object[name]; !ok {
+			return "", fmt.Errorf("missing %s", strings.Join(u.field, "."))
+		}
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw), nil
+	}
+	return value, nil
+}
+
+// WithUnion decodes invocation payloads with u for handlers whose input
+// type is T. Inputs of other types are unaffected. Payloads whose
+// discriminator is missing or has no variant fail with errorType
+// Runtime.UnmarshalError. Variants are decoded with the [Codec] set by
+// [WithCodec], if any.
+func WithUnion[T any](u *Union[T]) Option {
+	return func(o *options) {
+		if o.unions == nil {
+			o.unions = make(map[reflect.Type]unionDecoder)
+		}
+		o.unions[reflect.TypeFor[T]()] = func(data []byte, codec Codec) (any, error) {
+			return u.decode(data, codec)
+		}
+	}
+}
+
+type unionDecoder func(data []byte, codec Codec) (any, error)
@@ -0,0 +1,107 @@
+package voker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOrderEvent interface {
+	orderID() string
+}
+
+type testOrderPlaced struct {
+	Detail struct {
+		OrderID string  `json:"orderId"`
+		Total   float64 `json:"total"`
+	} `json:"detail"`
+}
+
+func (e testOrderPlaced) orderID() string { return e.Detail.OrderID }
+
+type testOrderShipped struct {
+	Detail struct {
+		OrderID string `json:"orderId"`
+		Carrier string `json:"carrier"`
+	} `json:"detail"`
+}
+
+func (e *testOrderShipped) orderID() string { return e.Detail.OrderID }
+
+func newTestOrderUnion() *Union[testOrderEvent] {
+	orders := NewUnion[testOrderEvent]("detail-type")
+	AddVariant[testOrderPlaced](orders, "Order Placed")
+	return AddVariant[testOrderShipped](orders, "Order Shipped")
+}
+
+func TestUnion_Unmarshal(t *testing.T) {
+	orders := newTestOrderUnion()
+
+	event, err := orders.Unmarshal([]byte(`{"detail-type":"Order Placed","detail":{"orderId":"o-1","total":9.5}}`))
+	require.NoError(t, err)
+	placed, ok := event.(testOrderPlaced)
+	require.True(t, ok)
+	assert.Equal(t, "o-1", placed.Detail.OrderID)
+	assert.Equal(t, 9.5, placed.Detail.Total)
+
+	event, err = orders.Unmarshal([]byte(`{"detail-type":"Order Shipped","detail":{"orderId":"o-2","carrier":"ups"}}`))
+	require.NoError(t, err)
+	shipped, ok := event.(*testOrderShipped)
+	require.True(t, ok)
+	assert.Equal(t, "ups", shipped.Detail.Carrier)
+
+	_, err = orders.Unmarshal([]byte(`{"detail-type":"Order Lost"}`))
+	assert.EqualError(t, err, `unknown detail-type "Order Lost"`)
+
+	_, err = orders.Unmarshal([]byte(`{"detail":{}}`))
+	assert.EqualError(t, err, "missing detail-type")
+
+	_, err = orders.Unmarshal([]byte(`[]`))
+	assert.Error(t, err)
+}
+
+func TestUnion_NestedNonStringDiscriminator(t *testing.T) {
+	orders := NewUnion[testOrderEvent]("detail.version")
+	AddVariant[testOrderPlaced](orders, "2")
+
+	event, err := orders.Unmarshal([]byte(`{"detail":{"version":2,"orderId":"o-3"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "o-3", event.orderID())
+}
+
+func TestUnion_Panics(t *testing.T) {
+	assert.PanicsWithValue(t, "voker: union type voker.testOrderPlaced is not an interface", func() {
+		NewUnion[testOrderPlaced]("type")
+	})
+	assert.PanicsWithValue(t, "voker: union variant voker.testEvent does not implement voker.testOrderEvent", func() {
+		AddVariant[testEvent](NewUnion[testOrderEvent]("type"), "test")
+	})
+}
+
+func TestCallHandler_Union(t *testing.T) {
+	codec := &countingCodec{}
+	options := &options{codec: codec}
+	WithUnion(newTestOrderUnion())(options)
+	handler := func(_ context.Context, event testOrderEvent) (testResponse, error) {
+		return testResponse{Message: event.orderID()}, nil
+	}
+
+	response, err := callHandler(context.Background(), []byte(`{"detail-type":"Order Shipped","detail":{"orderId":"o-4"}}`), handler, options)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message":"o-4"}`, string(response.payload))
+	assert.Equal(t, 1, codec.unmarshals)
+
+	options.middleware = []Middleware{func(next InvokeFunc) InvokeFunc { return next }}
+	response, err = callHandler(context.Background(), []byte(`{"detail-type":"Order Placed","detail":{"orderId":"o-5"}}`), handler, options)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message":"o-5"}`, string(response.payload))
+
+	_, err = callHandler(context.Background(), []byte(`{"detail-type":"Order Lost"}`), handler, options)
+	errResp, ok := errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "Runtime.UnmarshalError", errResp.Type)
+	assert.Equal(t, `failed to unmarshal input: unknown detail-type "Order Lost"`, errResp.Message)
+}
@@ -23,21 +23,23 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"reflect"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
 
 var (
-	errHandlerPanicked = errors.New("handler panicked")
-	errRuntimeShutdown = errors.New("runtime shutdown")
+	errHandlerPanicked   = errors.New("handler panicked")
+	errRuntimeShutdown   = errors.New("runtime shutdown")
+	errMissingRuntimeAPI = errors.New("AWS_LAMBDA_RUNTIME_API environment variable is not set")
 )
 
 const (
 	lambdaEnvMaxConcurrency     = "AWS_LAMBDA_MAX_CONCURRENCY"
 	lambdaEnvInitializationType = "AWS_LAMBDA_INITIALIZATION_TYPE"
-	managedInstancesInitType    = "lambda-managed-instances"
 )
 
 var configuredMaxConcurrency = parseMaxConcurrency(os.Getenv(lambdaEnvMaxConcurrency))
@@ -46,6 +48,49 @@ type options struct {
 	extensions     []InternalExtension
 	logger         *slog.Logger
 	maxConcurrency int
+	middleware     []Middleware
+	metrics        MetricsSink
+	invoked        atomic.Bool
+	watchdog       *watchdogOptions
+	leaks          *leakDetector
+	background     *backgroundTasks
+	codec          Codec
+	unions         map[reflect.Type]unionDecoder
+	jsonEncoder    JSONEncoderOptions
+	emptyResponse  EmptyResponse
+	contextValues  map[any]any
+	handlerEnv     string
+	env            env
+	maxPayload     int
+
+	userAgentSuffix string
+	apiVersion      string
+
+	// initErr fails initialization, such as when StartRegistered finds no
+	// handler to start.
+	initErr error
+
+	chunkedResponses bool
+	invocationReport bool
+	minimalContext   bool
+	prefetchNext     bool
+	resourceTuning   bool
+	fullStackPaths   bool
+	shutdownHooks    []func(context.Context)
+	beforeCheckpoint []func(context.Context) error
+	afterRestore     []func(context.Context) error
+
+	provisionedWarmup []func(context.Context) error
+
+	initWarning *time.Duration
+	initLimit   time.Duration
+	// initDuration is reported with the cold start invocation.
+	initDuration time.Duration
+
+	enableSIGTERM bool
+	// shutdownCtx is the parent of invocation contexts. It is set only
+	// when WithEnableSIGTERM is used.
+	shutdownCtx context.Context
 }
 
 // Option is a function that modifies Options.
@@ -58,6 +103,14 @@ func WithInternalExtension(ext InternalExtension) Option {
 	}
 }
 
+// WithMiddleware appends middleware that wraps every invocation. The first
+// middleware registered is the outermost. See [Middleware].
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, middleware...)
+	}
+}
+
 // WithLogger sets a custom slog logger for the runtime.
 // If not provided, a default logger will be created based on
 // AWS_LAMBDA_LOG_FORMAT and AWS_LAMBDA_LOG_LEVEL environment variables.
@@ -87,7 +140,9 @@ func WithLogger(logger *slog.Logger) Option {
 // the invocation payload verbatim. voker skips unmarshaling (and JSON
 // validation) and hands the raw bytes to the handler, which is then
 // responsible for decoding them. This is useful for handlers that work with
-// large payloads and want to measure or control their own decoding.
+// large payloads and want to measure or control their own decoding. A TIn of
+// []byte receives the payload the same way, for binary protocols and
+// pass-through proxies that never decode it as JSON.
 //
 // On Lambda Managed Instances, AWS_LAMBDA_MAX_CONCURRENCY controls how many
 // invocations call handler concurrently. The handler and all process-wide
@@ -98,70 +153,144 @@ func WithLogger(logger *slog.Logger) Option {
 // Runtime API, invalid configuration, or a handler panic) it reports the
 // error and terminates the process with os.Exit(1). It returns only when the
 // runtime shuts down gracefully after Lambda sends SIGTERM to a process with
-// registered internal extensions.
+// registered internal extensions or shutdown hooks. Use [Run] to handle
+// fatal errors yourself.
 func Start[TIn, TOut any](handler func(context.Context, TIn) (TOut, error), opts ...Option) {
-	start(func(ctx context.Context, client *runtimeClient, options *options) error {
+	if err := Run(context.Background(), handler, opts...); err != nil {
+		os.Exit(1)
+	}
+}
+
+// Run is like [Start], but returns fatal errors instead of terminating the
+// process, for programs that supervise the runtime or embed it alongside
+// other work. The error is logged and, when it occurs during
+// initialization, reported to Lambda before Run returns.
+//
+// Canceling ctx stops the runtime from polling for new invocations. Run
+// waits for in-flight invocations to finish and then returns nil, as it does
+// after a graceful SIGTERM shutdown. Lambda expects the process to exit once
+// the runtime stops.
+func Run[TIn, TOut any](ctx context.Context, handler func(context.Context, TIn) (TOut, error), opts ...Option) error {
+	return run(ctx, func(ctx context.Context, client *runtimeClient, options *options) error {
 		return handleInvocationContext(ctx, client, handler, options)
 	}, opts...)
 }
 
-func start(handle func(context.Context, *runtimeClient, *options) error, opts ...Option) {
+func run(ctx context.Context, handle func(context.Context, *runtimeClient, *options) error, opts ...Option) error {
+	startCalled := time.Now()
 	options := &options{}
 	for _, opt := range opts {
 		opt(options)
 	}
 
 	if options.logger == nil {
-		options.logger = defaultLogger()
+		options.logger = defaultLogger(options.env)
 	}
 	options.maxConcurrency = MaxConcurrency()
+	if options.env != nil {
+		options.maxConcurrency = parseMaxConcurrency(getenv(options.env, lambdaEnvMaxConcurrency))
+	}
+	if options.resourceTuning {
+		applyResourceTuning(options.env, options.logger)
+	}
 
-	runtimeAPI := os.Getenv("AWS_LAMBDA_RUNTIME_API")
+	runtimeAPI := getenv(options.env, "AWS_LAMBDA_RUNTIME_API")
 	if runtimeAPI == "" {
 		options.logger.Error("AWS_LAMBDA_RUNTIME_API environment variable is not set")
-		os.Exit(1)
+		return errMissingRuntimeAPI
 	}
 
+	options.registerShutdownHooks()
+
 	client := newRuntimeClient(runtimeAPI, options.logger)
+	client.httpClient.Transport = newRuntimeTransport(options.concurrency())
+	client.env = options.env
+	client.userAgent = options.userAgentHeader()
 	if err := validateRuntimeConfiguration(options); err != nil {
 		options.logger.Error("invalid runtime configuration", "error", err)
-		if reportErr := sendInitError(client, err); reportErr != nil {
-			options.logger.Error("failed to report initialization error", "error", reportErr)
-		}
-		os.Exit(1)
+		reportInitError(client, err, options.logger)
+		return err
 	}
+	client.setVersions(uniformRuntimeAPIVersions(options.runtimeAPIVersion()))
 
-	workerCtx, cancelWorkers := context.WithCancelCause(context.Background())
+	workerCtx, cancelWorkers := context.WithCancelCause(ctx)
 	defer cancelWorkers(errRuntimeShutdown)
 
+	breakdown := initBreakdown{beforeStart: startCalled.Sub(processStart)}
+	var extMgr *extensionManager
 	if len(options.extensions) > 0 {
-		extMgr := newExtensionManager(runtimeAPI, options.extensions, options.logger)
+		extMgr = newExtensionManager(runtimeAPI, options.extensions, options.logger)
+		extMgr.client.userAgent = client.userAgent
+		extMgr.fullStackPaths = options.fullStackPaths
 		if err := extMgr.start(); err != nil {
 			options.logger.Error("failed to start extensions", "error", err)
-			if reportErr := sendInitError(client, err); reportErr != nil {
-				options.logger.Error("failed to report initialization error", "error", reportErr)
-			}
-			os.Exit(1)
+			reportInitError(client, err, options.logger)
+			return err
+		}
+		breakdown.extensions = extMgr.initDurations
+	}
+
+	// Without extensions, SIGTERM is only handled for WithEnableSIGTERM on
+	// Lambda Managed Instances, which don't support extensions.
+	if extMgr != nil || options.enableSIGTERM {
+		cancelInvocations := func(error) {}
+		if options.enableSIGTERM {
+			var shutdownCtx context.Context
+			shutdownCtx, cancelInvocations = context.WithCancelCause(context.Background())
+			options.shutdownCtx = shutdownCtx
 		}
 
 		sigterm := make(chan os.Signal, 1)
 		signal.Notify(sigterm, syscall.SIGTERM)
+		defer signal.Stop(sigterm)
 		go func() {
-			<-sigterm
-			extMgr.shutdown()
+			select {
+			case <-sigterm:
+			case <-workerCtx.Done():
+				return
+			}
+			cancelInvocations(ErrSIGTERM)
+			if extMgr != nil {
+				extMgr.shutdown()
+			}
 			cancelWorkers(errRuntimeShutdown)
 		}()
 	}
 
+	initType := options.initType()
+	switch initType {
+	case InitProvisionedConcurrency:
+		if err := runProvisionedWarmup(ctx, client, options); err != nil {
+			options.logger.Error("provisioned warmup failed", "error", err)
+			return err
+		}
+	case InitSnapStart:
+		if err := runSnapStartHooks(ctx, client, options); err != nil {
+			options.logger.Error("SnapStart runtime hook failed", "error", err)
+			return err
+		}
+	}
+
+	breakdown.total = time.Since(processStart)
+	if err := checkInitDuration(options, initType, breakdown); err != nil {
+		reportInitError(client, err, options.logger)
+		return err
+	}
+	if initType != InitSnapStart {
+		// A restored snapshot's process started when the snapshot was taken,
+		// so its init time says nothing about this environment.
+		options.initDuration = breakdown.total
+	}
+
 	err := runInvocationWorkers(workerCtx, client, options, handle)
-	if errors.Is(err, errRuntimeShutdown) {
-		return
+	if errors.Is(err, errRuntimeShutdown) || ctx.Err() != nil {
+		return nil
 	}
 	// Don't log panics here - they're already logged in sendError.
 	if !errors.Is(err, errHandlerPanicked) {
 		options.logger.Error("fatal invocation loop error", "error", err)
 	}
-	os.Exit(1)
+	return err
 }
 
 // MaxConcurrency returns the number of invocations this runtime process is
@@ -188,7 +317,13 @@ func (o *options) concurrency() int {
 }
 
 func validateRuntimeConfiguration(options *options) error {
-	if os.Getenv(lambdaEnvInitializationType) == managedInstancesInitType && len(options.extensions) > 0 {
+	if options.initErr != nil {
+		return options.initErr
+	}
+	if err := validateRuntimeAPIVersion(options.runtimeAPIVersion()); err != nil {
+		return err
+	}
+	if options.initType() == InitManagedInstances && len(options.extensions) > 0 {
 		return &ErrorResponse{
 			Type:    "Runtime.UnsupportedExtension",
 			Message: "internal extensions are not supported on Lambda Managed Instances",
@@ -209,6 +344,10 @@ func runInvocationWorkers(
 	var wg sync.WaitGroup
 	for range options.concurrency() {
 		wg.Go(func() {
+			ctx := ctx
+			if options.prefetchNext {
+				ctx = withNextPrefetch(ctx)
+			}
 			for {
 				if err := handle(ctx, client, options); err != nil {
 					cancel(err)
@@ -221,24 +360,37 @@ func runInvocationWorkers(
 	return context.Cause(ctx)
 }
 
+// reportInitError reports err to Lambda as an initialization error,
+// logging any failure to do so.
+func reportInitError(client *runtimeClient, err error, logger *slog.Logger) {
+	if reportErr := sendInitError(client, err); reportErr != nil {
+		logger.Error("failed to report initialization error", "error", reportErr)
+	}
+}
+
 func sendInitError(client *runtimeClient, err error) error {
+	errResp, errorJSON := marshalInitError(err)
+	if postErr := client.initFailure(errorJSON, errResp.Type); postErr != nil {
+		return fmt.Errorf("failed to send initialization error: %w", postErr)
+	}
+	return nil
+}
+
+func marshalInitError(err error) (*ErrorResponse, []byte) {
 	errResp := newErrorResponse(err)
 	errorJSON, marshalErr := json.Marshal(errResp)
 	if marshalErr != nil {
 		errorJSON = fmt.Appendf(nil, `{"errorMessage":"failed to marshal initialization error: %s","errorType":"Runtime.MarshalError"}`, marshalErr)
 	}
-	if postErr := client.initFailure(errorJSON, errResp.Type); postErr != nil {
-		return fmt.Errorf("failed to send initialization error: %w", postErr)
-	}
-	return nil
+	return errResp, errorJSON
 }
 
 func handleInvocation[TIn, TOut any](client *runtimeClient, handler func(context.Context, TIn) (TOut, error), options *options) error {
 	return handleInvocationContext(context.Background(), client, handler, options)
 }
 
-func handleInvocationContext[TIn, TOut any](workerCtx context.Context, client *runtimeClient, handler func(context.Context, TIn) (TOut, error), options *options) error {
-	inv, err := client.nextContext(workerCtx)
+func handleInvocationContext[TIn, TOut any](workerCtx context.Context, client *runtimeClient, handler func(context.Context, TIn) (TOut, error), options *options) (handleErr error) {
+	inv, err := nextInvocation(workerCtx, client)
 	if err != nil {
 		return fmt.Errorf("failed to get next invocation: %w", err)
 	}
@@ -250,7 +402,7 @@ func handleInvocationContext[TIn, TOut any](workerCtx context.Context, client *r
 		return sendError(context.Background(), inv, newErrorResponse(err), options.logger)
 	}
 
-	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	ctx, cancel := context.WithDeadline(options.invocationParent(), deadline)
 	defer cancel()
 
 	lc := &LambdaContext{
@@ -259,22 +411,34 @@ func handleInvocationContext[TIn, TOut any](workerCtx context.Context, client *r
 		TraceID:            traceID,
 		TenantID:           inv.headers.Get(headerTenantID),
 	}
-
-	if cognitoJSON := inv.headers.Get(headerCognitoIdentity); cognitoJSON != "" {
-		if err := json.Unmarshal([]byte(cognitoJSON), &lc.Identity); err != nil {
-			return sendError(ctx, inv, newErrorResponse(fmt.Errorf("failed to parse cognito identity: %w", err)), options.logger)
-		}
-	}
-
-	if clientJSON := inv.headers.Get(headerClientContext); clientJSON != "" {
-		if err := json.Unmarshal([]byte(clientJSON), &lc.ClientContext); err != nil {
-			return sendError(ctx, inv, newErrorResponse(fmt.Errorf("failed to parse client context: %w", err)), options.logger)
+	if !options.minimalContext {
+		if err := parseInvocationMetadata(lc, inv); err != nil {
+			return sendError(ctx, inv, newErrorResponse(err), options.logger)
 		}
 	}
 
 	ctx = NewContext(ctx, lc)
-
-	response, err := callHandler(ctx, inv.payload, handler)
+	if options.fullStackPaths {
+		ctx = withFullStackPaths(ctx)
+	}
+	ctx, tasks := options.startTasks(ctx)
+
+	metrics := options.startMetrics(inv)
+	stopWatchdog := options.startWatchdog(ctx, deadline)
+	response, err := callHandler(withRecorder(ctx, metrics), inv.payload, handler, options)
+	stopWatchdog()
+	tasks.finish(ctx)
+	metrics.handled(response, err)
+	defer options.checkGoroutineLeaks(ctx)
+	defer metrics.record(ctx)
+	if options.prefetchNext {
+		// Deferred last so it runs first, before the deferred work above.
+		defer func() {
+			if handleErr == nil {
+				prefetchNext(workerCtx, client)
+			}
+		}()
+	}
 	if err != nil {
 		return sendError(ctx, inv, err, options.logger)
 	}
@@ -290,8 +454,21 @@ func handleInvocationContext[TIn, TOut any](workerCtx context.Context, client *r
 				return errHandlerPanicked
 			}
 		}
-	} else if err := inv.success(response.payload); err != nil {
-		return fmt.Errorf("failed to send success response: %w", err)
+	} else if response.encode {
+		n, encodeErr, err := inv.sendChunked(ctx, response.value, options.jsonEncoder)
+		metrics.responseBytes(n)
+		if encodeErr != nil {
+			return sendError(ctx, inv, newMarshalError(encodeErr), options.logger)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to send success response: %w", err)
+		}
+	} else {
+		err := inv.success(response.payload)
+		response.buf.release()
+		if err != nil {
+			return fmt.Errorf("failed to send success response: %w", err)
+		}
 	}
 
 	return nil
@@ -301,47 +478,70 @@ type handlerResponse struct {
 	payload     []byte
 	stream      io.Reader
 	contentType string
+
+	// buf backs payload and is released once the response has been sent.
+	buf *jsonBuffer
+
+	// encode is set when value is to be encoded during a chunked upload
+	// instead of already being encoded in payload.
+	encode bool
+	value  any
 }
 
-func callHandler[TIn, TOut any](ctx context.Context, payload []byte, handler func(context.Context, TIn) (TOut, error)) (response handlerResponse, responseErr error) {
+// callHandler decodes payload, runs handler and any middleware, and encodes
+// the result. A nil options uses the defaults.
+func callHandler[TIn, TOut any](ctx context.Context, payload []byte, handler func(context.Context, TIn) (TOut, error), options *options) (response handlerResponse, responseErr error) {
 	defer func() {
 		if r := recover(); r != nil {
 			response = handlerResponse{}
-			responseErr = newPanicResponse(r)
+			responseErr = newPanicResponse(r, options != nil && options.fullStackPaths)
 		}
 	}()
 
-	var input TIn
+	if options != nil && options.maxPayload > 0 && len(payload) > options.maxPayload {
+		return handlerResponse{}, newPayloadTooLargeError(len(payload), options.maxPayload)
+	}
 
-	// When the handler's input type is json.RawMessage, hand it the raw payload
-	// verbatim and skip unmarshaling entirely. This lets handlers that work with
-	// large payloads measure and control their own decoding rather than paying
-	// for an unmarshal they didn't ask for.
-	//
-	// The payload is aliased, not copied: each invocation receives a fresh
-	// buffer (see runtimeClient.next) that voker never reuses or mutates, so
-	// the handler can safely read it for the duration of the invocation.
-	//
-	// Note: this also bypasses JSON validation. A json.RawMessage handler
-	// receives the bytes as-is, even if the payload is empty or not valid JSON,
-	// and is responsible for handling those cases itself.
-	if raw, ok := any(&input).(*json.RawMessage); ok {
-		*raw = payload
-	} else if err := json.Unmarshal(payload, &input); err != nil {
-		return handlerResponse{}, &ErrorResponse{
-			Message: fmt.Sprintf("failed to unmarshal input: %v", err),
-			Type:    "Runtime.UnmarshalError",
-		}
+	var codec Codec
+	var unions map[reflect.Type]unionDecoder
+	var encoder JSONEncoderOptions
+	var middleware []Middleware
+	if options != nil {
+		codec = options.codec
+		unions = options.unions
+		encoder = options.jsonEncoder
+		middleware = options.middleware
 	}
 
-	output, err := handler(ctx, input)
-	if err != nil {
-		return handlerResponse{}, newErrorResponse(err)
+	if codec != nil || unions != nil {
+		// Handlers that decode payloads themselves through RawHandler decode
+		// them the same way.
+		ctx = withInputDecoder(ctx, inputDecoder{codec, unions})
+	}
+
+	var boxed any
+	if len(middleware) == 0 {
+		input, err := unmarshalInput[TIn](payload, inputDecoder{codec, unions})
+		if err != nil {
+			return handlerResponse{}, err
+		}
+
+		output, err := handler(ctx, input)
+		if err != nil {
+			return handlerResponse{}, newHandlerErrorResponse(ctx, err)
+		}
+
+		// Box the generic output once and reuse the interface value for the
+		// streaming checks and JSON marshaling below.
+		boxed = output
+	} else {
+		output, err := chainMiddleware(newInvokeFunc(handler, inputDecoder{codec, unions}), middleware)(ctx, payload)
+		if err != nil {
+			return handlerResponse{}, newHandlerErrorResponse(ctx, err)
+		}
+		boxed = output
 	}
 
-	// Box the generic output once and reuse the interface value for the
-	// streaming checks and JSON marshaling below.
-	boxed := any(output)
 	if stream, ok := boxed.(io.Reader); ok {
 		contentType := "application/octet-stream"
 		if typed, ok := stream.(interface{ ContentType() string }); ok {
@@ -350,36 +550,153 @@ func callHandler[TIn, TOut any](ctx context.Context, payload []byte, handler fun
 		return handlerResponse{stream: stream, contentType: contentType}, nil
 	}
 
-	responseBytes, err := json.Marshal(boxed)
+	if options != nil {
+		if payload := options.emptyResponse.payload(boxed); payload != nil {
+			return handlerResponse{payload: payload}, nil
+		}
+	}
+
+	if options != nil && options.chunkedResponses && codec == nil {
+		return handlerResponse{encode: true, value: boxed}, nil
+	}
+
+	if codec != nil {
+		responseBytes, err := codec.Marshal(boxed)
+		if err != nil {
+			return handlerResponse{}, newMarshalError(err)
+		}
+		return handlerResponse{payload: responseBytes}, nil
+	}
+
+	buf, err := marshalJSON(boxed, encoder)
+	if err != nil {
+		return handlerResponse{}, newMarshalError(err)
+	}
+
+	return handlerResponse{payload: buf.Bytes(), buf: buf}, nil
+}
+
+// WithMaxPayloadSize rejects invocations whose event payload is larger than
+// n bytes with a Runtime.PayloadTooLarge error, before the payload is
+// decoded or passed to middleware. Decoding a payload can take several times
+// its size in memory, so a cap well below Lambda's own 6 MB limit protects
+// functions with little memory from pathological inputs, such as large
+// proxied request bodies:
+//
+//	voker.Start(handler, voker.WithMaxPayloadSize(256<<10))
+//
+// A value of zero or less disables the check.
+func WithMaxPayloadSize(n int) Option {
+	return func(o *options) {
+		o.maxPayload = n
+	}
+}
+
+func newPayloadTooLargeError(size, limit int) *ErrorResponse {
+	return &ErrorResponse{
+		Message: fmt.Sprintf("payload of %d bytes exceeds the maximum of %d bytes", size, limit),
+		Type:    "Runtime.PayloadTooLarge",
+	}
+}
+
+func newMarshalError(err error) *ErrorResponse {
+	return &ErrorResponse{
+		Message: fmt.Sprintf("failed to marshal output: %v", err),
+		Type:    "Runtime.MarshalError",
+	}
+}
+
+// unmarshalInput decodes an invocation payload into a handler's input type.
+//
+// When the handler's input type is json.RawMessage or []byte, the raw payload
+// is returned verbatim and unmarshaling is skipped entirely. This lets handlers
+// that work with large payloads measure and control their own decoding rather
+// than paying for an unmarshal they didn't ask for.
+//
+// The payload is aliased, not copied: each invocation receives a fresh
+// buffer (see runtimeClient.next) that voker never reuses or mutates. The
+// handler owns it and may modify the bytes or retain them after the
+// invocation returns. Middleware receives the same slice, so changes the
+// handler makes are visible to middleware that reads the payload afterwards.
+//
+// Note: this also bypasses JSON validation. A json.RawMessage or []byte handler
+// receives the bytes as-is, even if the payload is empty or not valid JSON,
+// and is responsible for handling those cases itself.
+//
+// Inputs whose type has a [Union] registered with [WithUnion] are decoded by
+// it. A nil codec decodes with encoding/json.
+func unmarshalInput[TIn any](payload []byte, decoder inputDecoder) (TIn, error) {
+	var input TIn
+	var err error
+	switch raw := any(&input).(type) {
+	case *json.RawMessage:
+		*raw = payload
+		return input, nil
+	case *[]byte:
+		*raw = payload
+		return input, nil
+	}
+	if decode, ok := decoder.unions[reflect.TypeFor[TIn]()]; ok {
+		var value any
+		if value, err = decode(payload, decoder.codec); err == nil {
+			input = value.(TIn)
+		}
+	} else {
+		err = unmarshal(decoder.codec, payload, &input)
+	}
 	if err != nil {
-		return handlerResponse{}, &ErrorResponse{
-			Message: fmt.Sprintf("failed to marshal output: %v", err),
-			Type:    "Runtime.MarshalError",
+		return input, &ErrorResponse{
+			Message: fmt.Sprintf("failed to unmarshal input: %v", err),
+			Type:    "Runtime.UnmarshalError",
 		}
 	}
+	return input, nil
+}
 
-	return handlerResponse{payload: responseBytes}, nil
+// inputDecoder holds the options that control how payloads are decoded into
+// handler inputs.
+type inputDecoder struct {
+	codec  Codec
+	unions map[reflect.Type]unionDecoder
+}
+
+type inputDecoderKey struct{}
+
+func withInputDecoder(ctx context.Context, decoder inputDecoder) context.Context {
+	return context.WithValue(ctx, inputDecoderKey{}, decoder)
+}
+
+// inputDecoderFromContext returns the decoder of the invocation of ctx, or
+// the default decoder outside an invocation.
+func inputDecoderFromContext(ctx context.Context) inputDecoder {
+	decoder, _ := ctx.Value(inputDecoderKey{}).(inputDecoder)
+	return decoder
 }
 
 func sendError(ctx context.Context, inv *invocation, err error, logger *slog.Logger) error {
 	errResp := newErrorResponse(err)
 
-	errorJSON, marshalErr := json.Marshal(errResp)
+	errorJSON, buf, marshalErr := encodeErrorResponse(errResp)
 	if marshalErr != nil {
 		// If we can't marshal the error, create a simple error
 		errorJSON = fmt.Appendf(nil, `{"errorMessage":"failed to marshal error: %s","errorType":"Runtime.MarshalError"}`, marshalErr.Error())
 	}
-
-	logger.ErrorContext(
-		ctx,
-		"invocation error",
-		"error", errResp,
-		slog.Group("record",
-			"requestId", inv.requestID,
-			"functionName", os.Getenv("AWS_LAMBDA_FUNCTION_NAME"),
-			"functionVersion", os.Getenv("AWS_LAMBDA_FUNCTION_VERSION"),
-		),
-	)
+	defer buf.release()
+
+	// Building the record group allocates, so skip it when nothing would be
+	// logged.
+	if logger.Enabled(ctx, slog.LevelError) {
+		logger.ErrorContext(
+			ctx,
+			"invocation error",
+			"error", errResp,
+			slog.Group("record",
+				"requestId", inv.requestID,
+				"functionName", getenv(inv.client.env, "AWS_LAMBDA_FUNCTION_NAME"),
+				"functionVersion", getenv(inv.client.env, "AWS_LAMBDA_FUNCTION_VERSION"),
+			),
+		)
+	}
 
 	if err := inv.failure(errorJSON, errResp.Type); err != nil {
 		return fmt.Errorf("failed to send error response: %w", err)
@@ -3,6 +3,7 @@ package voker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -85,6 +86,45 @@ func BenchmarkHandleInvocation_WithMetadata(b *testing.B) {
 	}
 }
 
+// BenchmarkHandleInvocation_MinimalContext measures the same invocation as
+// BenchmarkHandleInvocation_WithMetadata with its metadata left unparsed.
+func BenchmarkHandleInvocation_MinimalContext(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			w.Header().Set(headerRequestID, "bench-request-id")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			w.Header().Set(headerFunctionARN, "arn:aws:lambda:us-east-1:123456789012:function:bench")
+			w.Header().Set(headerTraceID, "Root=1-5e9c5b5f-1234567890abcdef")
+			w.Header().Set(headerCognitoIdentity, `{"cognitoIdentityId":"us-west-2:d3f4d380-1d37-c31f-40af-e9e2dd41fd54","cognitoIdentityPoolId":"us-west-2:0958aa92-1810-4a32-8ae0-b07e1075a558"}`)
+			w.Header().Set(headerClientContext, `{"client":{"installation_id":"install-789"},"custom":{"key":"value"}}`)
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(testEvent{Name: "benchmark"})
+
+		case "/2018-06-01/runtime/invocation/bench-request-id/response":
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	client := newRuntimeClient(server.URL[7:], logger)
+
+	handler := func(ctx context.Context, event testEvent) (testResponse, error) {
+		return testResponse{Message: "hello " + event.Name}, nil
+	}
+	opts := &options{logger: logger}
+	WithMinimalContext()(opts)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if err := handleInvocation(client, handler, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 // BenchmarkJSONMarshalUnmarshal measures JSON operations in isolation
 func BenchmarkJSONMarshalUnmarshal(b *testing.B) {
 	event := testEvent{Name: "benchmark"}
@@ -202,8 +242,37 @@ func BenchmarkCallHandler(b *testing.B) {
 	b.ReportAllocs()
 
 	for b.Loop() {
-		if _, err := callHandler(ctx, eventJSON, handler); err != nil {
+		response, err := callHandler(ctx, eventJSON, handler, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		response.buf.release()
+	}
+}
+
+// BenchmarkCallHandler_Error measures the rejected-request path: a handler
+// error is converted to an ErrorResponse and encoded as the Runtime API error
+// payload.
+func BenchmarkCallHandler_Error(b *testing.B) {
+	ctx := context.Background()
+	eventJSON, _ := json.Marshal(testEvent{Name: "benchmark"})
+	errInvalid := errors.New("name must not contain <script> tags")
+
+	handler := func(ctx context.Context, event testEvent) (testResponse, error) {
+		return testResponse{}, errInvalid
+	}
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_, err := callHandler(ctx, eventJSON, handler, nil)
+		if err == nil {
+			b.Fatal("expected error")
+		}
+		body, buf, err := encodeErrorResponse(newErrorResponse(err))
+		if err != nil || len(body) == 0 {
 			b.Fatal(err)
 		}
+		buf.release()
 	}
 }
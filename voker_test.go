@@ -188,7 +188,7 @@ func TestCallHandler_StreamingContentType(t *testing.T) {
 		return contentTypeReader{Reader: strings.NewReader("event")}, nil
 	}
 
-	response, err := callHandler(context.Background(), []byte(`{"name":"test"}`), handler)
+	response, err := callHandler(context.Background(), []byte(`{"name":"test"}`), handler, nil)
 	require.NoError(t, err)
 	assert.Nil(t, response.payload)
 	assert.Equal(t, "text/event-stream", response.contentType)
@@ -242,10 +242,42 @@ func TestCallHandler_PreservesTypedErrorResponse(t *testing.T) {
 		return testResponse{}, want
 	}
 
-	_, err := callHandler(context.Background(), []byte(`{"name":""}`), handler)
+	_, err := callHandler(context.Background(), []byte(`{"name":""}`), handler, nil)
 	assert.Same(t, want, err)
 }
 
+func TestCallHandler_MaxPayloadSize(t *testing.T) {
+	called := false
+	handler := func(_ context.Context, event testEvent) (testResponse, error) {
+		called = true
+		return testResponse{Message: "Hello, " + event.Name}, nil
+	}
+	middlewareCalled := false
+	options := &options{middleware: []Middleware{func(next InvokeFunc) InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			middlewareCalled = true
+			return next(ctx, payload)
+		}
+	}}}
+	WithMaxPayloadSize(16)(options)
+
+	_, err := callHandler(context.Background(), []byte(`{"name":"a long name"}`), handler, options)
+	errResp, ok := errors.AsType[*ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "Runtime.PayloadTooLarge", errResp.Type)
+	assert.Equal(t, "payload of 22 bytes exceeds the maximum of 16 bytes", errResp.Message)
+	assert.False(t, called)
+	assert.False(t, middlewareCalled, "the payload must be rejected before middleware runs")
+
+	response, err := callHandler(context.Background(), []byte(`{"name":"abcdef"}`), handler, options)
+	require.Error(t, err, "payloads over the limit by one byte are rejected")
+	assert.Empty(t, response.payload)
+
+	response, err = callHandler(context.Background(), []byte(`{"name":"abcde"}`), handler, options)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message":"Hello, abcde"}`, string(response.payload))
+}
+
 func TestSendError_TypedStackTraceIsNotFatal(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusAccepted)
@@ -428,7 +460,7 @@ func TestHandleInvocation_WithTraceContext(t *testing.T) {
 			w.Header().Set(headerFunctionARN, "arn:aws:lambda:us-west-2:123:function:foo")
 			w.Header().Set(headerTraceID, "Root=1-5e9c5b5f-1234567890abcdef")
 			w.WriteHeader(http.StatusOK)
-			json.NewEncoder(w).Encode(testEvent{Name: "test"})
+			_, _ = w.Write([]byte(`{"name":"test","headers":{"traceparent":"` + testTraceParent + `"}}`))
 
 		case "/2018-06-01/runtime/invocation/req-123/response":
 			w.WriteHeader(http.StatusAccepted)
@@ -443,6 +475,9 @@ func TestHandleInvocation_WithTraceContext(t *testing.T) {
 		lc, ok := FromContext(ctx)
 		require.True(t, ok)
 		assert.Equal(t, "Root=1-5e9c5b5f-1234567890abcdef", lc.TraceID)
+		assert.Equal(t, testTraceParent, lc.TraceParent)
+		assert.Equal(t, lc.TraceID, TraceIDFromContext(ctx))
+		assert.Empty(t, os.Getenv("_X_AMZN_TRACE_ID"), "trace header must not leak into the process environment")
 		return testResponse{Message: "ok"}, nil
 	}
 
@@ -485,3 +520,64 @@ func TestParseDeadline(t *testing.T) {
 		})
 	}
 }
+
+func TestRun_MissingRuntimeAPI(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", "")
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		return testResponse{}, nil
+	}
+
+	err := Run(context.Background(), handler, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	assert.ErrorIs(t, err, errMissingRuntimeAPI)
+}
+
+func TestRun_ContextCanceled(t *testing.T) {
+	var responses []string
+	served := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2018-06-01/runtime/invocation/next":
+			if served {
+				<-r.Context().Done()
+				return
+			}
+			served = true
+			w.Header().Set(headerRequestID, "test-request-id")
+			w.Header().Set(headerDeadlineMS, "999999999999999")
+			_, _ = w.Write([]byte(`{"name":"world"}`))
+		case "/2018-06-01/runtime/invocation/test-request-id/response":
+			body, _ := io.ReadAll(r.Body)
+			responses = append(responses, string(body))
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", server.URL[7:])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler := func(_ context.Context, event testEvent) (testResponse, error) {
+		cancel()
+		return testResponse{Message: "Hello, " + event.Name}, nil
+	}
+
+	err := Run(ctx, handler, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	require.NoError(t, err)
+	require.Len(t, responses, 1)
+	assert.JSONEq(t, `{"message":"Hello, world"}`, responses[0])
+}
+
+func TestRun_ReturnsFatalError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", server.URL[7:])
+
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		return testResponse{}, nil
+	}
+
+	err := Run(context.Background(), handler, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	assert.ErrorContains(t, err, "unexpected status code from runtime API: 500")
+}
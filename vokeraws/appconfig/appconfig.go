@@ -0,0 +1,353 @@
+// Package appconfig serves AWS AppConfig feature flags and freeform JSON
+// configuration to voker handlers without the AWS AppConfig Agent Lambda
+// extension layer.
+//
+// A [Poller] runs as an internal extension. It reads the configuration
+// profile during initialization and polls the AppConfig Data API for
+// changes when invocations start, alongside the handler. Its middleware
+// pins the current configuration to each invocation's context, so every
+// flag a handler reads with [Get] comes from the same version:
+//
+//	flags := appconfig.NewPoller(cfg, "orders", "prod", "flags")
+//	voker.Start(handler,
+//	    voker.WithInternalExtension(flags.Extension()),
+//	    voker.WithMiddleware(flags.Middleware()),
+//	)
+//
+//	// In the handler:
+//	if appconfig.Enabled(ctx, "new-checkout") {
+//	    // ...
+//	}
+//
+// Internal extensions are not supported on Lambda Managed Instances.
+package appconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/hotsock/voker"
+)
+
+// ExtensionName is the name the AppConfig extension registers with.
+const ExtensionName = "voker-appconfig"
+
+const (
+	// DefaultPollInterval matches the AWS AppConfig Agent Lambda extension.
+	DefaultPollInterval = 45 * time.Second
+
+	// minPollInterval is the smallest interval the AppConfig Data API
+	// accepts.
+	minPollInterval = 15 * time.Second
+)
+
+var (
+	// ErrFlagNotFound is returned for a flag that isn't in the
+	// configuration.
+	ErrFlagNotFound = errors.New("appconfig: flag not found")
+
+	// ErrNoConfiguration is returned when the context carries no
+	// configuration, because the [Poller.Middleware] isn't installed.
+	ErrNoConfiguration = errors.New("appconfig: no configuration in context")
+)
+
+type options struct {
+	pollInterval time.Duration
+	logger       *slog.Logger
+	levelFlag    string
+	level        *slog.LevelVar
+}
+
+// Option configures a [Poller].
+type Option func(*options)
+
+// WithPollInterval sets how often the configuration is checked for changes.
+// Intervals under 15 seconds are raised to 15 seconds, the minimum AppConfig
+// allows. The default is [DefaultPollInterval].
+func WithPollInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = interval
+	}
+}
+
+// WithLogger sets the logger for poll failures. The default is
+// slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithLogLevel sets level from flag whenever a new configuration is loaded,
+// so operators can raise or lower a function's log level without a
+// redeploy. Pass the same level to the log handler:
+//
+//	level := new(slog.LevelVar)
+//	logger := slog.New(vokerslog.NewHandler(os.Stderr, vokerslog.WithLevel(level)))
+//	flags := appconfig.NewPoller(cfg, "orders", "prod", "flags",
+//	    appconfig.WithLogLevel("log-level", level))
+//
+// The flag is either a string or a feature flag with a "level" attribute,
+// such as {"enabled": true, "level": "DEBUG"}. Levels are TRACE, DEBUG,
+// INFO, WARN, ERROR, and FATAL, optionally with an offset such as
+// "DEBUG+2". When the flag is missing or disabled, level returns to the
+// value it had when the poller was created. An invalid level is logged and
+// ignored.
+func WithLogLevel(flag string, level *slog.LevelVar) Option {
+	return func(o *options) {
+		o.levelFlag = flag
+		o.level = level
+	}
+}
+
+// Poller keeps the latest version of an AppConfig configuration profile in
+// memory.
+type Poller struct {
+	client  *dataClient
+	session startSessionRequest
+	logger  *slog.Logger
+	now     func() time.Time
+
+	// levelFlag names the flag that sets level; baseLevel is restored when
+	// the flag is missing or disabled.
+	levelFlag string
+	level     *slog.LevelVar
+	baseLevel slog.Level
+
+	current atomic.Pointer[configuration]
+
+	// mu serializes polls, which also own the fields below.
+	mu           sync.Mutex
+	token        string
+	pollInterval time.Duration
+	nextPoll     time.Time
+}
+
+type configuration struct {
+	flags map[string]json.RawMessage
+}
+
+// NewPoller returns a poller for the configuration profile identified by
+// application, environment, and profile, which may be names or IDs. It
+// calls the AppConfig Data API with cfg's region and credentials.
+func NewPoller(cfg aws.Config, application, environment, profile string, opts ...Option) *Poller {
+	o := options{pollInterval: DefaultPollInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.pollInterval = max(o.pollInterval, minPollInterval)
+	if o.logger == nil {
+		o.logger = slog.Default()
+	}
+
+	p := &Poller{
+		client: newDataClient(cfg),
+		session: startSessionRequest{
+			ApplicationIdentifier:                application,
+			EnvironmentIdentifier:                environment,
+			ConfigurationProfileIdentifier:       profile,
+			RequiredMinimumPollIntervalInSeconds: int(o.pollInterval / time.Second),
+		},
+		logger:       o.logger,
+		now:          time.Now,
+		pollInterval: o.pollInterval,
+		levelFlag:    o.levelFlag,
+		level:        o.level,
+	}
+	if p.level != nil {
+		p.baseLevel = p.level.Level()
+	}
+	return p
+}
+
+// Extension returns the internal extension that loads the configuration
+// during initialization, failing initialization if it can't be read, and
+// polls for changes when invocations start. A failed poll is logged and
+// the previous configuration is kept.
+func (p *Poller) Extension() voker.InternalExtension {
+	return voker.InternalExtension{
+		Name: ExtensionName,
+		OnInit: func() error {
+			return p.poll(context.Background())
+		},
+		OnInvoke: func(ctx context.Context, _ voker.ExtensionEventPayload) {
+			if err := p.poll(ctx); err != nil {
+				p.logger.WarnContext(ctx, "failed to poll AppConfig configuration", "error", err)
+			}
+		},
+	}
+}
+
+// Middleware returns middleware that attaches the current configuration to
+// each invocation's context for [Get] and [Enabled].
+func (p *Poller) Middleware() voker.Middleware {
+	return func(next voker.InvokeFunc) voker.InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			if current := p.current.Load(); current != nil {
+				ctx = context.WithValue(ctx, configurationKey{}, current)
+			}
+			return next(ctx, payload)
+		}
+	}
+}
+
+// poll fetches the configuration if the poll interval has elapsed, starting
+// a new session when there is none or the previous one failed.
+func (p *Poller) poll(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if now.Before(p.nextPoll) {
+		return nil
+	}
+	p.nextPoll = now.Add(p.pollInterval)
+
+	if p.token == "" {
+		token, err := p.client.startSession(ctx, p.session)
+		if err != nil {
+			return fmt.Errorf("appconfig: %w", err)
+		}
+		p.token = token
+	}
+
+	latest, err := p.client.latestConfiguration(ctx, p.token)
+	if err != nil {
+		// Tokens can't be reused after an error and expire after 24
+		// hours, so the next poll starts over.
+		p.token = ""
+		return fmt.Errorf("appconfig: %w", err)
+	}
+	p.token = latest.nextToken
+	if latest.pollInterval > p.pollInterval {
+		p.nextPoll = now.Add(latest.pollInterval)
+	}
+
+	// An empty body means the configuration hasn't changed.
+	if len(latest.configuration) == 0 && p.current.Load() != nil {
+		return nil
+	}
+	var flags map[string]json.RawMessage
+	if err := json.Unmarshal(latest.configuration, &flags); err != nil {
+		return fmt.Errorf("appconfig: configuration is not a JSON object: %w", err)
+	}
+	p.current.Store(&configuration{flags: flags})
+	p.applyLogLevel(ctx, flags)
+	return nil
+}
+
+// applyLogLevel sets the log level from a newly loaded configuration.
+func (p *Poller) applyLogLevel(ctx context.Context, flags map[string]json.RawMessage) {
+	if p.level == nil {
+		return
+	}
+	level, err := logLevelFromFlag(flags[p.levelFlag], p.baseLevel)
+	if err != nil {
+		p.logger.WarnContext(ctx, "ignoring AppConfig log level", "flag", p.levelFlag, "error", err)
+		return
+	}
+	if level != p.level.Level() {
+		p.level.Set(level)
+		p.logger.InfoContext(ctx, "log level changed", "flag", p.levelFlag, "level", level)
+	}
+}
+
+// logLevelFromFlag returns the level a flag value selects, or base for a
+// missing or disabled flag.
+func logLevelFromFlag(raw json.RawMessage, base slog.Level) (slog.Level, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return base, nil
+	}
+
+	var name string
+	if raw[0] == '{' {
+		var flag struct {
+			Enabled *bool  `json:"enabled"`
+			Level   string `json:"level"`
+		}
+		if err := json.Unmarshal(raw, &flag); err != nil {
+			return base, err
+		}
+		if flag.Enabled != nil && !*flag.Enabled {
+			return base, nil
+		}
+		name = flag.Level
+	} else if err := json.Unmarshal(raw, &name); err != nil {
+		return base, fmt.Errorf("flag is not a string or feature flag: %w", err)
+	}
+	if strings.TrimSpace(name) == "" {
+		return base, nil
+	}
+	return parseLogLevel(name)
+}
+
+// parseLogLevel parses the level names Lambda's AWS_LAMBDA_LOG_LEVEL
+// accepts, which add TRACE and FATAL to slog's, with an optional offset.
+func parseLogLevel(name string) (slog.Level, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	for prefix, level := range map[string]slog.Level{
+		"TRACE": slog.LevelDebug - 4,
+		"FATAL": slog.LevelError + 4,
+	} {
+		if rest, ok := strings.CutPrefix(name, prefix); ok {
+			var offset slog.Level
+			if rest != "" {
+				if err := offset.UnmarshalText([]byte("INFO" + rest)); err != nil {
+					return 0, fmt.Errorf("invalid log level %q", name)
+				}
+			}
+			return level + offset, nil
+		}
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", name)
+	}
+	return level, nil
+}
+
+type configurationKey struct{}
+
+// Get decodes the value of flag from the configuration attached to ctx.
+// For a feature flags profile, the value is an object with an "enabled"
+// field and the flag's attributes:
+//
+//	type Checkout struct {
+//	    Enabled bool `json:"enabled"`
+//	    Variant string `json:"variant"`
+//	}
+//	checkout, err := appconfig.Get[Checkout](ctx, "new-checkout")
+//
+// For a freeform JSON profile, flag is a top-level key.
+func Get[T any](ctx context.Context, flag string) (T, error) {
+	var v T
+	current, ok := ctx.Value(configurationKey{}).(*configuration)
+	if !ok {
+		return v, ErrNoConfiguration
+	}
+	raw, ok := current.flags[flag]
+	if !ok {
+		return v, fmt.Errorf("%w: %q", ErrFlagNotFound, flag)
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return v, fmt.Errorf("appconfig: decode flag %q: %w", flag, err)
+	}
+	return v, nil
+}
+
+// Enabled reports whether a feature flag is enabled. Missing flags and
+// errors report false.
+func Enabled(ctx context.Context, flag string) bool {
+	v, err := Get[struct {
+		Enabled bool `json:"enabled"`
+	}](ctx, flag)
+	return err == nil && v.Enabled
+}
@@ -0,0 +1,255 @@
+package appconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAppConfig struct {
+	mu            sync.Mutex
+	sessions      []startSessionRequest
+	tokens        []string
+	configuration string
+	fail          bool
+	pollInterval  string
+}
+
+func (f *fakeAppConfig) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		!strings.Contains(r.Header.Get("Authorization"), "/us-east-1/appconfig/aws4_request") {
+		http.Error(w, "unsigned", http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/configurationsessions":
+		var in startSessionRequest
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		f.sessions = append(f.sessions, in)
+		_, _ = fmt.Fprintf(w, `{"InitialConfigurationToken":"session-%d"}`, len(f.sessions))
+	case r.Method == http.MethodGet && r.URL.Path == "/configuration":
+		token := r.URL.Query().Get("configuration_token")
+		f.tokens = append(f.tokens, token)
+		if f.fail {
+			w.Header().Set("X-Amzn-ErrorType", "BadRequestException:http://internal.amazon.com/coral/")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"Message":"token expired"}`))
+			return
+		}
+		w.Header().Set("Next-Poll-Configuration-Token", token+"+")
+		w.Header().Set("Next-Poll-Interval-In-Seconds", f.pollInterval)
+		_, _ = w.Write([]byte(f.configuration))
+		f.configuration = ""
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestPoller(t *testing.T, fake *fakeAppConfig, opts ...Option) (*Poller, *time.Time) {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cfg := aws.Config{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		BaseEndpoint: aws.String(server.URL),
+	}
+	p := NewPoller(cfg, "orders", "prod", "flags", opts...)
+	now := time.Unix(1700000000, 0)
+	p.now = func() time.Time { return now }
+	return p, &now
+}
+
+func invoke(t *testing.T, p *Poller, fn func(ctx context.Context)) {
+	t.Helper()
+	_, err := p.Middleware()(func(ctx context.Context, _ json.RawMessage) (any, error) {
+		fn(ctx)
+		return nil, nil
+	})(context.Background(), nil)
+	require.NoError(t, err)
+}
+
+func TestPoller(t *testing.T) {
+	fake := &fakeAppConfig{
+		configuration: `{"new-checkout":{"enabled":true,"variant":"b"},"old-cart":{"enabled":false},"limit":10}`,
+		pollInterval:  "45",
+	}
+	p, now := newTestPoller(t, fake, WithPollInterval(time.Second))
+
+	ext := p.Extension()
+	assert.Equal(t, ExtensionName, ext.Name)
+	require.NoError(t, ext.OnInit())
+
+	require.Len(t, fake.sessions, 1)
+	assert.Equal(t, startSessionRequest{
+		ApplicationIdentifier:                "orders",
+		EnvironmentIdentifier:                "prod",
+		ConfigurationProfileIdentifier:       "flags",
+		RequiredMinimumPollIntervalInSeconds: 15,
+	}, fake.sessions[0])
+
+	invoke(t, p, func(ctx context.Context) {
+		type checkout struct {
+			Enabled bool   `json:"enabled"`
+			Variant string `json:"variant"`
+		}
+		got, err := Get[checkout](ctx, "new-checkout")
+		require.NoError(t, err)
+		assert.Equal(t, checkout{Enabled: true, Variant: "b"}, got)
+
+		limit, err := Get[int](ctx, "limit")
+		require.NoError(t, err)
+		assert.Equal(t, 10, limit)
+
+		assert.True(t, Enabled(ctx, "new-checkout"))
+		assert.False(t, Enabled(ctx, "old-cart"))
+		assert.False(t, Enabled(ctx, "missing"))
+
+		_, err = Get[bool](ctx, "missing")
+		assert.ErrorIs(t, err, ErrFlagNotFound)
+		_, err = Get[string](ctx, "limit")
+		assert.ErrorContains(t, err, `decode flag "limit"`)
+	})
+
+	// The server's longer poll interval wins.
+	*now = now.Add(30 * time.Second)
+	ext.OnInvoke(context.Background(), voker.ExtensionEventPayload{})
+	assert.Equal(t, []string{"session-1"}, fake.tokens)
+
+	// An unchanged configuration keeps the current flags.
+	*now = now.Add(15 * time.Second)
+	ext.OnInvoke(context.Background(), voker.ExtensionEventPayload{})
+	assert.Equal(t, []string{"session-1", "session-1+"}, fake.tokens)
+	invoke(t, p, func(ctx context.Context) {
+		assert.True(t, Enabled(ctx, "new-checkout"))
+	})
+
+	fake.configuration = `{"new-checkout":{"enabled":false}}`
+	*now = now.Add(45 * time.Second)
+	ext.OnInvoke(context.Background(), voker.ExtensionEventPayload{})
+	invoke(t, p, func(ctx context.Context) {
+		assert.False(t, Enabled(ctx, "new-checkout"))
+	})
+}
+
+func TestPoller_FailedPoll(t *testing.T) {
+	fake := &fakeAppConfig{configuration: `{"flag":{"enabled":true}}`}
+	var logs bytes.Buffer
+	p, now := newTestPoller(t, fake, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	ext := p.Extension()
+	require.NoError(t, ext.OnInit())
+
+	fake.fail = true
+	*now = now.Add(DefaultPollInterval)
+	ext.OnInvoke(context.Background(), voker.ExtensionEventPayload{})
+	assert.Contains(t, logs.String(), "failed to poll AppConfig configuration")
+	assert.Contains(t, logs.String(), "BadRequestException: token expired (status 400)")
+	invoke(t, p, func(ctx context.Context) {
+		assert.True(t, Enabled(ctx, "flag"))
+	})
+
+	// The next poll starts a new session.
+	fake.fail = false
+	*now = now.Add(DefaultPollInterval)
+	ext.OnInvoke(context.Background(), voker.ExtensionEventPayload{})
+	assert.Len(t, fake.sessions, 2)
+	assert.Equal(t, "session-2", fake.tokens[len(fake.tokens)-1])
+}
+
+func TestPoller_OnInitError(t *testing.T) {
+	p, _ := newTestPoller(t, &fakeAppConfig{configuration: "not: json"})
+	err := p.Extension().OnInit()
+	assert.ErrorContains(t, err, "configuration is not a JSON object")
+
+	p, _ = newTestPoller(t, &fakeAppConfig{fail: true})
+	err = p.Extension().OnInit()
+	assert.ErrorContains(t, err, "appconfig: get latest configuration: BadRequestException")
+}
+
+func TestPoller_LogLevel(t *testing.T) {
+	fake := &fakeAppConfig{configuration: `{"log-level":"debug"}`}
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelWarn)
+	var logs bytes.Buffer
+	p, now := newTestPoller(t, fake,
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		WithLogLevel("log-level", level))
+	ext := p.Extension()
+	require.NoError(t, ext.OnInit())
+	assert.Equal(t, slog.LevelDebug, level.Level())
+	assert.Contains(t, logs.String(), "log level changed")
+
+	poll := func(configuration string) {
+		fake.configuration = configuration
+		*now = now.Add(DefaultPollInterval)
+		ext.OnInvoke(context.Background(), voker.ExtensionEventPayload{})
+	}
+
+	poll(`{"log-level":{"enabled":true,"level":"TRACE"}}`)
+	assert.Equal(t, slog.LevelDebug-4, level.Level())
+
+	// A disabled or missing flag restores the original level.
+	poll(`{"log-level":{"enabled":false,"level":"DEBUG"}}`)
+	assert.Equal(t, slog.LevelWarn, level.Level())
+	poll(`{"log-level":"ERROR+2"}`)
+	assert.Equal(t, slog.LevelError+2, level.Level())
+	poll(`{}`)
+	assert.Equal(t, slog.LevelWarn, level.Level())
+
+	// An invalid level keeps the current one.
+	poll(`{"log-level":"verbose"}`)
+	assert.Equal(t, slog.LevelWarn, level.Level())
+	assert.Contains(t, logs.String(), `ignoring AppConfig log level`)
+	assert.Contains(t, logs.String(), `invalid log level \"VERBOSE\"`)
+}
+
+func TestParseLogLevel(t *testing.T) {
+	for name, want := range map[string]slog.Level{
+		"trace":   slog.LevelDebug - 4,
+		"TRACE+1": slog.LevelDebug - 3,
+		"debug":   slog.LevelDebug,
+		" Info ":  slog.LevelInfo,
+		"WARN":    slog.LevelWarn,
+		"error-1": slog.LevelError - 1,
+		"fatal":   slog.LevelError + 4,
+	} {
+		got, err := parseLogLevel(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	for _, name := range []string{"", "verbose", "TRACEY", "FATAL+x"} {
+		_, err := parseLogLevel(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestGet_NoConfiguration(t *testing.T) {
+	_, err := Get[bool](context.Background(), "flag")
+	assert.ErrorIs(t, err, ErrNoConfiguration)
+	assert.False(t, Enabled(context.Background(), "flag"))
+}
+
+func TestNewDataClient_Endpoint(t *testing.T) {
+	assert.Equal(t, "https://appconfigdata.eu-west-1.amazonaws.com", newDataClient(aws.Config{Region: "eu-west-1"}).endpoint)
+	assert.Equal(t, "https://appconfigdata.cn-north-1.amazonaws.com.cn", newDataClient(aws.Config{Region: "cn-north-1"}).endpoint)
+}
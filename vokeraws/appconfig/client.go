@@ -0,0 +1,88 @@
+package appconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/hotsock/voker/vokeraws/internal/signedhttp"
+)
+
+// signingName is the SigV4 service name of the AppConfig Data API.
+const signingName = "appconfig"
+
+// dataClient calls the two AppConfig Data API operations the poller needs,
+// StartConfigurationSession and GetLatestConfiguration, over SigV4-signed
+// HTTP.
+type dataClient struct {
+	endpoint string
+	client   *signedhttp.Client
+}
+
+func newDataClient(cfg aws.Config) *dataClient {
+	return &dataClient{
+		endpoint: signedhttp.Endpoint(cfg, "appconfigdata"),
+		client:   signedhttp.New(cfg, signingName),
+	}
+}
+
+type startSessionRequest struct {
+	ApplicationIdentifier                string `json:"ApplicationIdentifier"`
+	EnvironmentIdentifier                string `json:"EnvironmentIdentifier"`
+	ConfigurationProfileIdentifier       string `json:"ConfigurationProfileIdentifier"`
+	RequiredMinimumPollIntervalInSeconds int    `json:"RequiredMinimumPollIntervalInSeconds,omitempty"`
+}
+
+// startSession returns the initial configuration token of a new session.
+func (c *dataClient) startSession(ctx context.Context, in startSessionRequest) (string, error) {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.client.Do(ctx, http.MethodPost, c.endpoint+"/configurationsessions", body)
+	if err != nil {
+		return "", fmt.Errorf("start configuration session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		InitialConfigurationToken string `json:"InitialConfigurationToken"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("start configuration session: %w", err)
+	}
+	return out.InitialConfigurationToken, nil
+}
+
+type latestConfiguration struct {
+	// configuration is empty when it hasn't changed since the previous
+	// call.
+	configuration []byte
+	nextToken     string
+	pollInterval  time.Duration
+}
+
+func (c *dataClient) latestConfiguration(ctx context.Context, token string) (latestConfiguration, error) {
+	resp, err := c.client.Do(ctx, http.MethodGet, c.endpoint+"/configuration?configuration_token="+url.QueryEscape(token), nil)
+	if err != nil {
+		return latestConfiguration{}, fmt.Errorf("get latest configuration: %w", err)
+	}
+	defer resp.Body.Close()
+
+	configuration, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return latestConfiguration{}, fmt.Errorf("get latest configuration: %w", err)
+	}
+	seconds, _ := strconv.Atoi(resp.Header.Get("Next-Poll-Interval-In-Seconds"))
+	return latestConfiguration{
+		configuration: configuration,
+		nextToken:     resp.Header.Get("Next-Poll-Configuration-Token"),
+		pollInterval:  time.Duration(seconds) * time.Second,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package vokeraws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/hotsock/voker/vokeraws/internal/signedhttp"
+	"github.com/hotsock/voker/vokerevents"
+)
+
+// CodePipelineClient reports the results of CodePipeline jobs. It
+// implements [vokerevents.CodePipelineClient]:
+//
+//	voker.Start(vokerevents.CodePipelineJobHandler(vokeraws.NewCodePipelineClient(cfg), deploy))
+type CodePipelineClient struct {
+	endpoint string
+	client   *signedhttp.Client
+}
+
+// NewCodePipelineClient returns a client for CodePipeline in cfg's region.
+func NewCodePipelineClient(cfg aws.Config) *CodePipelineClient {
+	return &CodePipelineClient{
+		endpoint: signedhttp.Endpoint(cfg, "codepipeline"),
+		client:   signedhttp.New(cfg, "codepipeline"),
+	}
+}
+
+// PutJobSuccessResult reports a job as succeeded, or as still in progress
+// when result has a continuation token.
+func (c *CodePipelineClient) PutJobSuccessResult(ctx context.Context, result vokerevents.CodePipelineJobSuccess) error {
+	if err := c.call(ctx, "CodePipeline_20150709.PutJobSuccessResult", result); err != nil {
+		return fmt.Errorf("vokeraws: put success result of job %s: %w", result.JobID, err)
+	}
+	return nil
+}
+
+// PutJobFailureResult reports a job as failed.
+func (c *CodePipelineClient) PutJobFailureResult(ctx context.Context, result vokerevents.CodePipelineJobFailure) error {
+	if err := c.call(ctx, "CodePipeline_20150709.PutJobFailureResult", result); err != nil {
+		return fmt.Errorf("vokeraws: put failure result of job %s: %w", result.JobID, err)
+	}
+	return nil
+}
+
+func (c *CodePipelineClient) call(ctx context.Context, operation string, input any) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Call(ctx, c.endpoint, operation, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
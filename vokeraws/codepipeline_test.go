@@ -0,0 +1,59 @@
+package vokeraws
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/hotsock/voker/vokerevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ vokerevents.CodePipelineClient = (*CodePipelineClient)(nil)
+
+func TestCodePipelineClient(t *testing.T) {
+	type request struct {
+		target string
+		body   map[string]any
+	}
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		data, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(data, &body))
+		requests = append(requests, request{r.Header.Get("X-Amz-Target"), body})
+		assert.Contains(t, r.Header.Get("Authorization"), "/codepipeline/aws4_request")
+		if body["jobId"] == "throttled" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.codepipeline#ThrottlingException","message":"Rate exceeded"}`))
+		}
+	}))
+	defer server.Close()
+
+	cfg := testWebSocketConfig.Copy()
+	cfg.BaseEndpoint = aws.String(server.URL)
+	client := NewCodePipelineClient(cfg)
+	ctx := context.Background()
+
+	require.NoError(t, client.PutJobSuccessResult(ctx, vokerevents.CodePipelineJobSuccess{JobID: "job-1", ContinuationToken: "deploy-42"}))
+	require.NoError(t, client.PutJobFailureResult(ctx, vokerevents.CodePipelineJobFailure{
+		JobID:          "job-2",
+		FailureDetails: vokerevents.CodePipelineFailureDetails{Type: vokerevents.CodePipelineJobFailed, Message: "tests failed"},
+	}))
+	err := client.PutJobSuccessResult(ctx, vokerevents.CodePipelineJobSuccess{JobID: "throttled"})
+	assert.EqualError(t, err, "vokeraws: put success result of job throttled: ThrottlingException: Rate exceeded (status 400)")
+
+	require.Len(t, requests, 3)
+	assert.Equal(t, "CodePipeline_20150709.PutJobSuccessResult", requests[0].target)
+	assert.Equal(t, map[string]any{"jobId": "job-1", "continuationToken": "deploy-42"}, requests[0].body)
+	assert.Equal(t, "CodePipeline_20150709.PutJobFailureResult", requests[1].target)
+	assert.Equal(t, map[string]any{
+		"jobId":          "job-2",
+		"failureDetails": map[string]any{"type": "JobFailed", "message": "tests failed"},
+	}, requests[1].body)
+}
@@ -0,0 +1,110 @@
+// Package vokeraws wires the AWS SDK for Go v2 into voker functions.
+//
+// [LoadConfig] loads an aws.Config once during initialization with SDK
+// logging sent to the function's slog logger, and [Middleware] makes it
+// available to every invocation through the handler's context. SDK calls
+// made with that context join the invocation's X-Ray trace ([AddTracing]):
+//
+//	cfg, err := vokeraws.LoadConfig(context.Background(), logger)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	voker.Start(handler, voker.WithLogger(logger), voker.WithMiddleware(vokeraws.Middleware(cfg)))
+//
+// [SecretsCache] keeps Secrets Manager secrets and SSM parameters in memory
+// with an internal extension, and the appconfig subpackage does the same for
+// AWS AppConfig feature flags. [WebSocketClient] sends messages to the
+// clients of API Gateway WebSocket APIs. [FirehoseLogSink], [KinesisLogSink],
+// and [S3LogSink] are [voker.LogSink] destinations for voker.WithLogShipper.
+// [Envelope] encrypts payload fields with KMS envelope encryption.
+package vokeraws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/hotsock/voker"
+)
+
+// LoadConfig loads the default aws.Config for the execution environment.
+// The SDK's log output goes to logger, or slog.Default() when logger is nil,
+// with the context of the SDK call that produced it, so handlers such as
+// vokerslog attach the invocation's request ID, and calls made during an
+// invocation are traced with [AddTracing]. optFns are applied after the
+// logger and may override it.
+func LoadConfig(ctx context.Context, logger *slog.Logger, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	opts := append([]func(*config.LoadOptions) error{
+		config.WithLogger(NewLogger(logger)),
+		config.WithAPIOptions([]func(*middleware.Stack) error{AddTracing}),
+	}, optFns...)
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("vokeraws: load AWS config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Middleware returns middleware that attaches cfg to every invocation's
+// context, where handlers read it with [ConfigFromContext].
+func Middleware(cfg aws.Config) voker.Middleware {
+	return func(next voker.InvokeFunc) voker.InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			return next(NewContext(ctx, cfg), payload)
+		}
+	}
+}
+
+type configKey struct{}
+
+// NewContext returns a copy of parent carrying cfg.
+func NewContext(parent context.Context, cfg aws.Config) context.Context {
+	return context.WithValue(parent, configKey{}, cfg)
+}
+
+// ConfigFromContext returns the aws.Config attached by [Middleware] or
+// [NewContext], if present.
+func ConfigFromContext(ctx context.Context) (aws.Config, bool) {
+	cfg, ok := ctx.Value(configKey{}).(aws.Config)
+	return cfg, ok
+}
+
+// NewLogger adapts logger to the SDK's logging.Logger interface. SDK
+// warnings are logged at slog.LevelWarn and debug output, enabled with
+// config.WithClientLogMode, at slog.LevelDebug.
+func NewLogger(logger *slog.Logger) logging.Logger {
+	return sdkLogger{logger: logger, ctx: context.Background()}
+}
+
+type sdkLogger struct {
+	logger *slog.Logger
+	ctx    context.Context
+}
+
+// WithContext implements logging.ContextLogger. The SDK calls it with the
+// context passed to each operation.
+func (l sdkLogger) WithContext(ctx context.Context) logging.Logger {
+	return sdkLogger{logger: l.logger, ctx: ctx}
+}
+
+func (l sdkLogger) Logf(classification logging.Classification, format string, v ...any) {
+	level := slog.LevelInfo
+	switch classification {
+	case logging.Warn:
+		level = slog.LevelWarn
+	case logging.Debug:
+		level = slog.LevelDebug
+	}
+	if !l.logger.Enabled(l.ctx, level) {
+		return
+	}
+	l.logger.Log(l.ctx, level, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,96 @@
+package vokeraws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/smithy-go/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	cfg, err := LoadConfig(context.Background(), logger,
+		func(o *config.LoadOptions) error {
+			o.Credentials = credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", cfg.Region)
+
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKID", creds.AccessKeyID)
+
+	cfg.Logger.Logf(logging.Warn, "checksum %s", "skipped")
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), `msg="checksum skipped"`)
+}
+
+func TestLoadConfig_Error(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent")
+	t.Setenv("AWS_PROFILE", "missing")
+
+	_, err := LoadConfig(context.Background(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "vokeraws: load AWS config")
+}
+
+type ctxKey struct{}
+
+type contextHandler struct {
+	slog.Handler
+	values *[]any
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	*h.values = append(*h.values, ctx.Value(ctxKey{}))
+	return h.Handler.Handle(ctx, r)
+}
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	var values []any
+	logger := slog.New(contextHandler{
+		Handler: slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}),
+		values:  &values,
+	})
+
+	sdk := logging.WithContext(context.WithValue(context.Background(), ctxKey{}, "request-1"), NewLogger(logger))
+	sdk.Logf(logging.Debug, "dropped")
+	sdk.Logf(logging.Warn, "kept %d", 1)
+
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), `msg="kept 1"`)
+	assert.Equal(t, []any{"request-1"}, values)
+}
+
+func TestMiddleware(t *testing.T) {
+	cfg := aws.Config{Region: "us-east-2"}
+
+	_, ok := ConfigFromContext(context.Background())
+	assert.False(t, ok)
+
+	invoke := Middleware(cfg)(func(ctx context.Context, payload json.RawMessage) (any, error) {
+		got, ok := ConfigFromContext(ctx)
+		require.True(t, ok)
+		return got.Region, nil
+	})
+	region, err := invoke(context.Background(), json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-2", region)
+}
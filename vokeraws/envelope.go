@@ -0,0 +1,381 @@
+package vokeraws
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/hotsock/voker"
+	"github.com/hotsock/voker/vokeraws/internal/signedhttp"
+)
+
+const (
+	// DefaultDataKeyMaxAge is how long an [Envelope] encrypts with one data
+	// key before it generates another.
+	DefaultDataKeyMaxAge = 5 * time.Minute
+
+	envelopeVersion = 1
+
+	// maxDecryptedKeys bounds the data keys an Envelope keeps decrypted.
+	maxDecryptedKeys = 256
+)
+
+// ErrInvalidCiphertext is returned for ciphertext that [Envelope] didn't
+// produce or that has been modified.
+var ErrInvalidCiphertext = errors.New("vokeraws: invalid ciphertext")
+
+type envelopeOptions struct {
+	decryptFields     [][]string
+	encryptFields     [][]string
+	encryptionContext map[string]string
+	dataKeyMaxAge     time.Duration
+}
+
+// EnvelopeOption configures an [Envelope].
+type EnvelopeOption func(*envelopeOptions)
+
+// WithDecryptFields sets the payload fields [Envelope.Middleware] decrypts
+// before the handler decodes the payload. Paths are dot-separated object
+// keys, and a "*" segment matches every element of an array or member of an
+// object, such as "Records.*.body". Fields that are missing or null are left
+// alone.
+func WithDecryptFields(paths ...string) EnvelopeOption {
+	return func(o *envelopeOptions) {
+		o.decryptFields = append(o.decryptFields, splitFieldPaths(paths)...)
+	}
+}
+
+// WithEncryptFields sets the response fields [Envelope.Middleware] encrypts
+// after the handler returns, with the same paths as [WithDecryptFields].
+func WithEncryptFields(paths ...string) EnvelopeOption {
+	return func(o *envelopeOptions) {
+		o.encryptFields = append(o.encryptFields, splitFieldPaths(paths)...)
+	}
+}
+
+// WithEncryptionContext sets the KMS encryption context that data keys are
+// bound to. Ciphertext can only be decrypted with the same context.
+func WithEncryptionContext(encryptionContext map[string]string) EnvelopeOption {
+	return func(o *envelopeOptions) {
+		o.encryptionContext = encryptionContext
+	}
+}
+
+// WithDataKeyMaxAge sets how long one data key is used for encryption. The
+// default is [DefaultDataKeyMaxAge].
+func WithDataKeyMaxAge(maxAge time.Duration) EnvelopeOption {
+	return func(o *envelopeOptions) {
+		o.dataKeyMaxAge = maxAge
+	}
+}
+
+func splitFieldPaths(paths []string) [][]string {
+	split := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		split = append(split, strings.Split(path, "."))
+	}
+	return split
+}
+
+type dataKey struct {
+	plaintext []byte
+	encrypted []byte
+	created   time.Time
+}
+
+// Envelope encrypts values with AES-256-GCM data keys from AWS KMS, so
+// payloads stay confidential end to end without a KMS request per value.
+// Data keys live in memory across warm invocations: one data key encrypts
+// until it is [DefaultDataKeyMaxAge] old, and decrypted data keys are
+// reused for every value they encrypted.
+//
+// Its middleware decrypts fields of the invocation payload and encrypts
+// fields of the response:
+//
+//	envelope := vokeraws.NewEnvelope(cfg, "alias/payments",
+//	    vokeraws.WithDecryptFields("card.number"),
+//	    vokeraws.WithEncryptFields("receipt"),
+//	)
+//	voker.Start(handler, voker.WithMiddleware(envelope.Middleware()))
+//
+// Encrypted values are base64 strings holding the KMS-encrypted data key,
+// a nonce, and the sealed JSON encoding of the value, so any JSON value can
+// be encrypted and is restored with its original type. [Envelope.Encrypt]
+// produces the same format for the clients that send encrypted payloads.
+type Envelope struct {
+	keyID    string
+	endpoint string
+	client   *signedhttp.Client
+	options  envelopeOptions
+	now      func() time.Time
+
+	mu        sync.Mutex
+	current   *dataKey
+	decrypted map[string][]byte
+}
+
+// NewEnvelope returns an Envelope that generates data keys with the KMS key
+// keyID, which can be a key ID, key ARN, alias name, or alias ARN.
+func NewEnvelope(cfg aws.Config, keyID string, opts ...EnvelopeOption) *Envelope {
+	options := envelopeOptions{dataKeyMaxAge: DefaultDataKeyMaxAge}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Envelope{
+		keyID:     keyID,
+		endpoint:  signedhttp.Endpoint(cfg, "kms"),
+		client:    signedhttp.New(cfg, "kms"),
+		options:   options,
+		now:       time.Now,
+		decrypted: make(map[string][]byte),
+	}
+}
+
+// Encrypt encrypts plaintext and returns it base64-encoded.
+func (e *Envelope) Encrypt(ctx context.Context, plaintext []byte) (string, error) {
+	key, err := e.dataKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(key.plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	header := make([]byte, 3, 3+len(key.encrypted)+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	header[0] = envelopeVersion
+	binary.BigEndian.PutUint16(header[1:], uint16(len(key.encrypted)))
+	header = append(header, key.encrypted...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(append(header, nonce...), nonce, plaintext, header)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts ciphertext produced by [Envelope.Encrypt]. It returns
+// [ErrInvalidCiphertext] if ciphertext is malformed or has been modified.
+func (e *Envelope) Decrypt(ctx context.Context, ciphertext string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(data) < 3 || data[0] != envelopeVersion {
+		return nil, ErrInvalidCiphertext
+	}
+	keyEnd := 3 + int(binary.BigEndian.Uint16(data[1:]))
+	if len(data) < keyEnd {
+		return nil, ErrInvalidCiphertext
+	}
+	header := data[:keyEnd]
+
+	plaintextKey, err := e.decryptDataKey(ctx, header[3:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(plaintextKey)
+	if err != nil {
+		return nil, err
+	}
+	rest := data[keyEnd:]
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], header)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+// Middleware returns middleware that decrypts the fields set with
+// [WithDecryptFields] before the handler runs, and encrypts the fields set
+// with [WithEncryptFields] in its JSON-encoded output. Streaming responses
+// are returned unchanged.
+func (e *Envelope) Middleware() voker.Middleware {
+	return func(next voker.InvokeFunc) voker.InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			for _, path := range e.options.decryptFields {
+				var err error
+				payload, err = transformField(payload, path, func(value []byte) ([]byte, error) {
+					var ciphertext string
+					if err := json.Unmarshal(value, &ciphertext); err != nil {
+						return nil, ErrInvalidCiphertext
+					}
+					return e.Decrypt(ctx, ciphertext)
+				})
+				if err != nil {
+					return nil, fmt.Errorf("vokeraws: decrypt field %s: %w", strings.Join(path, "."), err)
+				}
+			}
+
+			output, err := next(ctx, payload)
+			if err != nil || output == nil || len(e.options.encryptFields) == 0 {
+				return output, err
+			}
+			if _, ok := output.(io.Reader); ok {
+				return output, nil
+			}
+
+			encoded, err := json.Marshal(output)
+			if err != nil {
+				return nil, err
+			}
+			for _, path := range e.options.encryptFields {
+				encoded, err = transformField(encoded, path, func(value []byte) ([]byte, error) {
+					ciphertext, err := e.Encrypt(ctx, value)
+					if err != nil {
+						return nil, err
+					}
+					return json.Marshal(ciphertext)
+				})
+				if err != nil {
+					return nil, fmt.Errorf("vokeraws: encrypt field %s: %w", strings.Join(path, "."), err)
+				}
+			}
+			return json.RawMessage(encoded), nil
+		}
+	}
+}
+
+// transformField replaces the values at path in the JSON document data with
+// the result of fn. Missing and null values are skipped.
+func transformField(data []byte, path []string, fn func([]byte) ([]byte, error)) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(path) == 0 {
+		if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			return data, nil
+		}
+		return fn(trimmed)
+	}
+	if len(trimmed) == 0 {
+		return data, nil
+	}
+
+	switch trimmed[0] {
+	case '{':
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &object); err != nil {
+			return nil, err
+		}
+		changed := false
+		for name, value := range object {
+			if path[0] != "*" && path[0] != name {
+				continue
+			}
+			replaced, err := transformField(value, path[1:], fn)
+			if err != nil {
+				return nil, err
+			}
+			object[name] = replaced
+			changed = true
+		}
+		if !changed {
+			return data, nil
+		}
+		return json.Marshal(object)
+	case '[':
+		if path[0] != "*" {
+			return data, nil
+		}
+		var array []json.RawMessage
+		if err := json.Unmarshal(trimmed, &array); err != nil {
+			return nil, err
+		}
+		for i, value := range array {
+			replaced, err := transformField(value, path[1:], fn)
+			if err != nil {
+				return nil, err
+			}
+			array[i] = replaced
+		}
+		return json.Marshal(array)
+	}
+	return data, nil
+}
+
+func (e *Envelope) dataKey(ctx context.Context) (*dataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.current != nil && e.now().Sub(e.current.created) < e.options.dataKeyMaxAge {
+		return e.current, nil
+	}
+
+	request, err := json.Marshal(struct {
+		KeyId             string
+		KeySpec           string
+		EncryptionContext map[string]string `json:",omitempty"`
+	}{e.keyID, "AES_256", e.options.encryptionContext})
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		CiphertextBlob []byte
+		Plaintext      []byte
+	}
+	if err := callJSON(ctx, e.client, e.endpoint, "TrentService.GenerateDataKey", request, &response); err != nil {
+		return nil, fmt.Errorf("vokeraws: generate data key with %s: %w", e.keyID, err)
+	}
+	e.current = &dataKey{plaintext: response.Plaintext, encrypted: response.CiphertextBlob, created: e.now()}
+	e.remember(response.CiphertextBlob, response.Plaintext)
+	return e.current, nil
+}
+
+func (e *Envelope) decryptDataKey(ctx context.Context, encrypted []byte) ([]byte, error) {
+	e.mu.Lock()
+	plaintext, ok := e.decrypted[string(encrypted)]
+	e.mu.Unlock()
+	if ok {
+		return plaintext, nil
+	}
+
+	request, err := json.Marshal(struct {
+		KeyId             string
+		CiphertextBlob    []byte
+		EncryptionContext map[string]string `json:",omitempty"`
+	}{e.keyID, encrypted, e.options.encryptionContext})
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Plaintext []byte
+	}
+	if err := callJSON(ctx, e.client, e.endpoint, "TrentService.Decrypt", request, &response); err != nil {
+		var apiErr *signedhttp.APIError
+		if errors.As(err, &apiErr) && apiErr.Type == "InvalidCiphertextException" {
+			return nil, ErrInvalidCiphertext
+		}
+		return nil, fmt.Errorf("vokeraws: decrypt data key with %s: %w", e.keyID, err)
+	}
+
+	e.mu.Lock()
+	e.remember(encrypted, response.Plaintext)
+	e.mu.Unlock()
+	return response.Plaintext, nil
+}
+
+// remember caches a decrypted data key. e.mu must be held.
+func (e *Envelope) remember(encrypted, plaintext []byte) {
+	if len(e.decrypted) >= maxDecryptedKeys {
+		clear(e.decrypted)
+	}
+	e.decrypted[string(encrypted)] = plaintext
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("vokeraws: invalid data key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
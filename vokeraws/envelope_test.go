@@ -0,0 +1,191 @@
+package vokeraws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKMS struct {
+	generated atomic.Int32
+	decrypted atomic.Int32
+}
+
+func newTestEnvelope(t *testing.T, opts ...EnvelopeOption) (*Envelope, *fakeKMS) {
+	t.Helper()
+	kms := &fakeKMS{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			KeyId             string
+			KeySpec           string
+			CiphertextBlob    []byte
+			EncryptionContext map[string]string
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "alias/payments", request.KeyId)
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GenerateDataKey":
+			n := kms.generated.Add(1)
+			assert.Equal(t, "AES_256", request.KeySpec)
+			plaintext := bytes.Repeat([]byte{byte(n)}, 32)
+			_ = json.NewEncoder(w).Encode(map[string][]byte{
+				"CiphertextBlob": append([]byte("wrapped:"), plaintext...),
+				"Plaintext":      plaintext,
+			})
+		case "TrentService.Decrypt":
+			kms.decrypted.Add(1)
+			plaintext, ok := bytes.CutPrefix(request.CiphertextBlob, []byte("wrapped:"))
+			if !ok || request.EncryptionContext["tenant"] == "other" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"InvalidCiphertextException"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": plaintext})
+		default:
+			t.Errorf("unexpected target %q", r.Header.Get("X-Amz-Target"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := testWebSocketConfig.Copy()
+	cfg.BaseEndpoint = aws.String(server.URL)
+	return NewEnvelope(cfg, "alias/payments", opts...), kms
+}
+
+func TestEnvelope_EncryptDecrypt(t *testing.T) {
+	envelope, kms := newTestEnvelope(t)
+	ctx := context.Background()
+
+	first, err := envelope.Encrypt(ctx, []byte(`"4111111111111111"`))
+	require.NoError(t, err)
+	second, err := envelope.Encrypt(ctx, []byte(`"4111111111111111"`))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, int32(1), kms.generated.Load())
+
+	plaintext, err := envelope.Decrypt(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, `"4111111111111111"`, string(plaintext))
+	assert.Equal(t, int32(0), kms.decrypted.Load(), "the generated data key is reused")
+
+	// Another execution environment decrypts the data key once.
+	other, kms := newTestEnvelope(t)
+	for range 2 {
+		plaintext, err = other.Decrypt(ctx, second)
+		require.NoError(t, err)
+		assert.Equal(t, `"4111111111111111"`, string(plaintext))
+	}
+	assert.Equal(t, int32(1), kms.decrypted.Load())
+}
+
+func TestEnvelope_DataKeyMaxAge(t *testing.T) {
+	envelope, kms := newTestEnvelope(t, WithDataKeyMaxAge(time.Minute))
+	now := time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC)
+	envelope.now = func() time.Time { return now }
+
+	_, err := envelope.Encrypt(context.Background(), []byte(`1`))
+	require.NoError(t, err)
+	now = now.Add(59 * time.Second)
+	_, err = envelope.Encrypt(context.Background(), []byte(`2`))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), kms.generated.Load())
+
+	now = now.Add(time.Second)
+	_, err = envelope.Encrypt(context.Background(), []byte(`3`))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), kms.generated.Load())
+}
+
+func TestEnvelope_DecryptInvalid(t *testing.T) {
+	envelope, _ := newTestEnvelope(t)
+	ctx := context.Background()
+
+	ciphertext, err := envelope.Encrypt(ctx, []byte(`{"amount":100}`))
+	require.NoError(t, err)
+	tampered := []byte(ciphertext)
+	tampered[len(tampered)-4] ^= 1
+
+	for _, ciphertext := range []string{"", "not base64!", "AQ==", "AQAF", string(tampered)} {
+		_, err := envelope.Decrypt(ctx, ciphertext)
+		assert.ErrorIs(t, err, ErrInvalidCiphertext, ciphertext)
+	}
+
+	other, _ := newTestEnvelope(t, WithEncryptionContext(map[string]string{"tenant": "other"}))
+	_, err = other.Decrypt(ctx, ciphertext)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestEnvelope_Middleware(t *testing.T) {
+	envelope, _ := newTestEnvelope(t,
+		WithDecryptFields("card.number", "Records.*.body"),
+		WithEncryptFields("receipt", "missing.field"),
+	)
+	ctx := context.Background()
+
+	number, err := envelope.Encrypt(ctx, []byte(`"4111111111111111"`))
+	require.NoError(t, err)
+	body, err := envelope.Encrypt(ctx, []byte(`{"item":"book"}`))
+	require.NoError(t, err)
+	payload, err := json.Marshal(map[string]any{
+		"card":    map[string]any{"number": number, "expiry": "12/30"},
+		"Records": []any{map[string]any{"body": body}, map[string]any{"body": nil}},
+	})
+	require.NoError(t, err)
+
+	type response struct {
+		Receipt any    `json:"receipt"`
+		Status  string `json:"status"`
+	}
+	invoke := envelope.Middleware()(func(ctx context.Context, payload json.RawMessage) (any, error) {
+		assert.JSONEq(t, `{
+			"card": {"number": "4111111111111111", "expiry": "12/30"},
+			"Records": [{"body": {"item": "book"}}, {"body": null}]
+		}`, string(payload))
+		return response{Receipt: map[string]int{"total": 100}, Status: "paid"}, nil
+	})
+
+	output, err := invoke(ctx, payload)
+	require.NoError(t, err)
+	var encrypted struct {
+		Receipt string `json:"receipt"`
+		Status  string `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(output.(json.RawMessage), &encrypted))
+	assert.Equal(t, "paid", encrypted.Status)
+	receipt, err := envelope.Decrypt(ctx, encrypted.Receipt)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"total":100}`, string(receipt))
+
+	_, err = invoke(ctx, json.RawMessage(`{"card":{"number":"4111111111111111"}}`))
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	assert.ErrorContains(t, err, "vokeraws: decrypt field card.number")
+}
+
+func TestEnvelope_MiddlewarePassesThrough(t *testing.T) {
+	envelope, kms := newTestEnvelope(t, WithEncryptFields("secret"))
+	var middleware voker.Middleware = envelope.Middleware()
+
+	output, err := middleware(func(ctx context.Context, payload json.RawMessage) (any, error) {
+		return nil, nil
+	})(context.Background(), json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.Nil(t, output)
+
+	output, err = middleware(func(ctx context.Context, payload json.RawMessage) (any, error) {
+		return map[string]string{"public": "value"}, nil
+	})(context.Background(), json.RawMessage(`{}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"public":"value"}`, string(output.(json.RawMessage)))
+	assert.Equal(t, int32(0), kms.generated.Load())
+}
@@ -0,0 +1,138 @@
+// Package signedhttp sends SigV4-signed requests to the AWS APIs that
+// vokeraws calls without the SDK's service clients.
+package signedhttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// APIError is an error response from an AWS API.
+type APIError struct {
+	// Type is the error code, such as "BadRequestException".
+	Type       string
+	Message    string
+	StatusCode int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s (status %d)", e.Type, e.Message, e.StatusCode)
+}
+
+// Client signs requests for one AWS service.
+type Client struct {
+	cfg         aws.Config
+	http        aws.HTTPClient
+	signer      *v4.Signer
+	signingName string
+}
+
+// New returns a client that signs requests as signingName with cfg's
+// credentials and region.
+func New(cfg aws.Config, signingName string) *Client {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Client{
+		cfg:         cfg,
+		http:        client,
+		signer:      v4.NewSigner(),
+		signingName: signingName,
+	}
+}
+
+// Endpoint returns cfg.BaseEndpoint, or the regional endpoint of the
+// service whose hostname starts with prefix.
+func Endpoint(cfg aws.Config, prefix string) string {
+	if endpoint := aws.ToString(cfg.BaseEndpoint); endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/")
+	}
+	domain := "amazonaws.com"
+	if strings.HasPrefix(cfg.Region, "cn-") {
+		domain = "amazonaws.com.cn"
+	}
+	return "https://" + prefix + "." + cfg.Region + "." + domain
+}
+
+// Do sends a signed request with body, sent as JSON when it is non-nil. It
+// returns an *APIError for non-2xx responses, whose body it closes.
+func (c *Client) Do(ctx context.Context, method, target string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.send(ctx, req, body)
+}
+
+// Call invokes operation, such as "Firehose_20150804.PutRecordBatch", of an
+// API that uses the AWS JSON 1.1 protocol at endpoint. It returns an
+// *APIError like Do.
+func (c *Client) Call(ctx context.Context, endpoint, operation string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", operation)
+	return c.send(ctx, req, body)
+}
+
+func (c *Client) send(ctx context.Context, req *http.Request, body []byte) (*http.Response, error) {
+	if c.cfg.Credentials == nil {
+		return nil, errors.New("aws.Config has no credentials")
+	}
+	creds, err := c.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve credentials: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), c.signingName, c.cfg.Region, time.Now()); err != nil {
+		return nil, fmt.Errorf("sign request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Message string `json:"Message"`
+			// Some APIs, such as the API Gateway Management API, use a
+			// lowercase key.
+			LowerMessage string `json:"message"`
+			// JSON 1.1 APIs also name the error in the body.
+			Type string `json:"__type"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&apiErr)
+		errorType, _, _ := strings.Cut(resp.Header.Get("X-Amzn-ErrorType"), ":")
+		if errorType == "" {
+			errorType = apiErr.Type[strings.LastIndex(apiErr.Type, "#")+1:]
+		}
+		if errorType == "" {
+			errorType = http.StatusText(resp.StatusCode)
+		}
+		message := apiErr.Message
+		if message == "" {
+			message = apiErr.LowerMessage
+		}
+		return nil, &APIError{Type: errorType, Message: message, StatusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
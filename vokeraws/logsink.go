@@ -0,0 +1,235 @@
+package vokeraws
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hotsock/voker"
+	"github.com/hotsock/voker/vokeraws/internal/signedhttp"
+)
+
+// Firehose and Kinesis accept at most 500 records and a few megabytes per
+// call. Larger batches are split.
+const (
+	maxStreamRecords = 500
+	maxStreamBytes   = 4 << 20
+)
+
+// FirehoseLogSink writes log records to an Amazon Data Firehose stream, one
+// newline-terminated JSON Telemetry API event per record, so streams that
+// deliver to S3 produce JSON Lines objects:
+//
+//	voker.Start(handler, voker.WithLogShipper(voker.LogShipper{
+//	    Sink: vokeraws.NewFirehoseLogSink(cfg, "function-logs"),
+//	}))
+//
+// A batch Firehose partially rejects is returned as an error and retried
+// whole, so records can be delivered more than once.
+type FirehoseLogSink struct {
+	stream   string
+	endpoint string
+	client   *signedhttp.Client
+}
+
+// NewFirehoseLogSink returns a sink that writes to the Firehose stream
+// named stream.
+func NewFirehoseLogSink(cfg aws.Config, stream string) *FirehoseLogSink {
+	return &FirehoseLogSink{
+		stream:   stream,
+		endpoint: signedhttp.Endpoint(cfg, "firehose"),
+		client:   signedhttp.New(cfg, "firehose"),
+	}
+}
+
+// WriteLogs implements [voker.LogSink].
+func (s *FirehoseLogSink) WriteLogs(ctx context.Context, events []voker.TelemetryEvent) error {
+	type record struct {
+		Data []byte `json:"Data"`
+	}
+	return writeStreamRecords(events, func(batch [][]byte) error {
+		records := make([]record, len(batch))
+		for i, data := range batch {
+			records[i] = record{Data: data}
+		}
+		body, err := json.Marshal(map[string]any{"DeliveryStreamName": s.stream, "Records": records})
+		if err != nil {
+			return err
+		}
+		var output struct {
+			FailedPutCount int `json:"FailedPutCount"`
+		}
+		if err := callJSON(ctx, s.client, s.endpoint, "Firehose_20150804.PutRecordBatch", body, &output); err != nil {
+			return fmt.Errorf("vokeraws: put records to firehose stream %s: %w", s.stream, err)
+		}
+		if output.FailedPutCount > 0 {
+			return fmt.Errorf("vokeraws: firehose stream %s rejected %d of %d records", s.stream, output.FailedPutCount, len(records))
+		}
+		return nil
+	})
+}
+
+// KinesisLogSink writes log records to a Kinesis data stream, one
+// newline-terminated JSON Telemetry API event per record. Records are
+// partitioned by the execution environment's log stream name, so each
+// environment's records stay in order:
+//
+//	voker.Start(handler, voker.WithLogShipper(voker.LogShipper{
+//	    Sink: vokeraws.NewKinesisLogSink(cfg, "function-logs"),
+//	}))
+//
+// A batch Kinesis partially rejects is returned as an error and retried
+// whole, so records can be delivered more than once.
+type KinesisLogSink struct {
+	stream       string
+	partitionKey string
+	endpoint     string
+	client       *signedhttp.Client
+}
+
+// NewKinesisLogSink returns a sink that writes to the Kinesis data stream
+// named stream.
+func NewKinesisLogSink(cfg aws.Config, stream string) *KinesisLogSink {
+	partitionKey := os.Getenv("AWS_LAMBDA_LOG_STREAM_NAME")
+	if partitionKey == "" {
+		partitionKey = randomHex()
+	}
+	return &KinesisLogSink{
+		stream:       stream,
+		partitionKey: partitionKey,
+		endpoint:     signedhttp.Endpoint(cfg, "kinesis"),
+		client:       signedhttp.New(cfg, "kinesis"),
+	}
+}
+
+// WriteLogs implements [voker.LogSink].
+func (s *KinesisLogSink) WriteLogs(ctx context.Context, events []voker.TelemetryEvent) error {
+	type record struct {
+		Data         []byte `json:"Data"`
+		PartitionKey string `json:"PartitionKey"`
+	}
+	return writeStreamRecords(events, func(batch [][]byte) error {
+		records := make([]record, len(batch))
+		for i, data := range batch {
+			records[i] = record{Data: data, PartitionKey: s.partitionKey}
+		}
+		body, err := json.Marshal(map[string]any{"StreamName": s.stream, "Records": records})
+		if err != nil {
+			return err
+		}
+		var output struct {
+			FailedRecordCount int `json:"FailedRecordCount"`
+		}
+		if err := callJSON(ctx, s.client, s.endpoint, "Kinesis_20131202.PutRecords", body, &output); err != nil {
+			return fmt.Errorf("vokeraws: put records to kinesis stream %s: %w", s.stream, err)
+		}
+		if output.FailedRecordCount > 0 {
+			return fmt.Errorf("vokeraws: kinesis stream %s rejected %d of %d records", s.stream, output.FailedRecordCount, len(records))
+		}
+		return nil
+	})
+}
+
+// writeStreamRecords encodes events as JSON lines and passes them to put in
+// batches within the Firehose and Kinesis limits.
+func writeStreamRecords(events []voker.TelemetryEvent, put func(batch [][]byte) error) error {
+	var batch [][]byte
+	size := 0
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("vokeraws: encode log record: %w", err)
+		}
+		data = append(data, '\n')
+		if len(batch) == maxStreamRecords || (len(batch) > 0 && size+len(data) > maxStreamBytes) {
+			if err := put(batch); err != nil {
+				return err
+			}
+			batch, size = nil, 0
+		}
+		batch = append(batch, data)
+		size += len(data)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return put(batch)
+}
+
+func callJSON(ctx context.Context, client *signedhttp.Client, endpoint, operation string, body []byte, output any) error {
+	resp, err := client.Call(ctx, endpoint, operation, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(output); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// S3PutAPI is the subset of the S3 client used by [S3LogSink].
+type S3PutAPI interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3LogSink writes each batch of log records to its own S3 object of JSON
+// Lines, keyed by prefix, the hour it was written, and a unique name, such
+// as "logs/2024/05/01/13/1714569600000000000-3f9c2a1b.jsonl":
+//
+//	voker.Start(handler, voker.WithLogShipper(voker.LogShipper{
+//	    Sink:          vokeraws.NewS3LogSink(s3.NewFromConfig(cfg), "my-log-bucket", "logs/"),
+//	    BatchSize:     5000,
+//	    FlushInterval: 30 * time.Second,
+//	}))
+//
+// Every batch is a PUT request, so larger batches and flush intervals keep
+// costs down.
+type S3LogSink struct {
+	client S3PutAPI
+	bucket string
+	prefix string
+	now    func() time.Time
+}
+
+// NewS3LogSink returns a sink that writes objects to bucket under prefix.
+func NewS3LogSink(client S3PutAPI, bucket, prefix string) *S3LogSink {
+	return &S3LogSink{client: client, bucket: bucket, prefix: prefix, now: time.Now}
+}
+
+// WriteLogs implements [voker.LogSink].
+func (s *S3LogSink) WriteLogs(ctx context.Context, events []voker.TelemetryEvent) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("vokeraws: encode log record: %w", err)
+		}
+	}
+
+	now := s.now().UTC()
+	key := fmt.Sprintf("%s%s/%d-%s.jsonl", s.prefix, now.Format("2006/01/02/15"), now.UnixNano(), randomHex())
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body.Bytes()),
+		ContentType: aws.String("application/x-ndjson"),
+	})
+	if err != nil {
+		return fmt.Errorf("vokeraws: put log object %s: %w", key, err)
+	}
+	return nil
+}
+
+func randomHex() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,145 @@
+package vokeraws
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogEvents(n int) []voker.TelemetryEvent {
+	events := make([]voker.TelemetryEvent, n)
+	for i := range events {
+		events[i] = voker.TelemetryEvent{
+			Time:   time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC),
+			Type:   "function",
+			Record: []byte(`"hello"`),
+		}
+	}
+	return events
+}
+
+type streamRequest struct {
+	Target  string
+	Stream  string
+	Records []struct {
+		Data         []byte
+		PartitionKey string
+	}
+}
+
+func newStreamServer(t *testing.T, response string) (*httptest.Server, *[]streamRequest) {
+	t.Helper()
+	var requests []streamRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-amz-json-1.1", r.Header.Get("Content-Type"))
+		assert.Contains(t, r.Header.Get("Authorization"), "AWS4-HMAC-SHA256")
+		var body struct {
+			DeliveryStreamName string
+			StreamName         string
+			Records            []struct {
+				Data         []byte
+				PartitionKey string
+			}
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, streamRequest{
+			Target:  r.Header.Get("X-Amz-Target"),
+			Stream:  body.DeliveryStreamName + body.StreamName,
+			Records: body.Records,
+		})
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestFirehoseLogSink(t *testing.T) {
+	server, requests := newStreamServer(t, `{"FailedPutCount":0}`)
+	cfg := testWebSocketConfig.Copy()
+	cfg.BaseEndpoint = aws.String(server.URL)
+
+	sink := NewFirehoseLogSink(cfg, "function-logs")
+	require.NoError(t, sink.WriteLogs(context.Background(), testLogEvents(501)))
+
+	require.Len(t, *requests, 2)
+	assert.Equal(t, "Firehose_20150804.PutRecordBatch", (*requests)[0].Target)
+	assert.Equal(t, "function-logs", (*requests)[0].Stream)
+	assert.Len(t, (*requests)[0].Records, 500)
+	assert.Len(t, (*requests)[1].Records, 1)
+	assert.Equal(t, `{"time":"2024-05-01T13:00:00Z","type":"function","record":"hello"}`+"\n", string((*requests)[1].Records[0].Data))
+}
+
+func TestFirehoseLogSink_PartialFailure(t *testing.T) {
+	server, _ := newStreamServer(t, `{"FailedPutCount":1}`)
+	cfg := testWebSocketConfig.Copy()
+	cfg.BaseEndpoint = aws.String(server.URL)
+
+	err := NewFirehoseLogSink(cfg, "function-logs").WriteLogs(context.Background(), testLogEvents(2))
+	assert.EqualError(t, err, "vokeraws: firehose stream function-logs rejected 1 of 2 records")
+}
+
+func TestKinesisLogSink(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_LOG_STREAM_NAME", "2024/05/01/[$LATEST]abc")
+	server, requests := newStreamServer(t, `{"FailedRecordCount":0}`)
+	cfg := testWebSocketConfig.Copy()
+	cfg.BaseEndpoint = aws.String(server.URL)
+
+	sink := NewKinesisLogSink(cfg, "function-logs")
+	require.NoError(t, sink.WriteLogs(context.Background(), testLogEvents(2)))
+
+	require.Len(t, *requests, 1)
+	assert.Equal(t, "Kinesis_20131202.PutRecords", (*requests)[0].Target)
+	assert.Equal(t, "function-logs", (*requests)[0].Stream)
+	require.Len(t, (*requests)[0].Records, 2)
+	assert.Equal(t, "2024/05/01/[$LATEST]abc", (*requests)[0].Records[0].PartitionKey)
+}
+
+func TestKinesisLogSink_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"com.amazonaws.kinesis#ResourceNotFoundException","message":"Stream not found"}`))
+	}))
+	defer server.Close()
+	cfg := testWebSocketConfig.Copy()
+	cfg.BaseEndpoint = aws.String(server.URL)
+
+	err := NewKinesisLogSink(cfg, "missing").WriteLogs(context.Background(), testLogEvents(1))
+	assert.EqualError(t, err, "vokeraws: put records to kinesis stream missing: ResourceNotFoundException: Stream not found (status 400)")
+}
+
+type fakeS3Put struct {
+	input *s3.PutObjectInput
+	body  string
+}
+
+func (f *fakeS3Put) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	f.input = params
+	data, _ := io.ReadAll(params.Body)
+	f.body = string(data)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestS3LogSink(t *testing.T) {
+	client := &fakeS3Put{}
+	sink := NewS3LogSink(client, "my-log-bucket", "logs/")
+	sink.now = func() time.Time { return time.Date(2024, 5, 1, 13, 30, 0, 0, time.UTC) }
+
+	require.NoError(t, sink.WriteLogs(context.Background(), testLogEvents(2)))
+	assert.Equal(t, "my-log-bucket", aws.ToString(client.input.Bucket))
+	assert.Regexp(t, `^logs/2024/05/01/13/\d+-[0-9a-f]{8}\.jsonl$`, aws.ToString(client.input.Key))
+	assert.Equal(t, "application/x-ndjson", aws.ToString(client.input.ContentType))
+	lines := strings.Split(strings.TrimSuffix(client.body, "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.JSONEq(t, `{"time":"2024-05-01T13:00:00Z","type":"function","record":"hello"}`, lines[0])
+}
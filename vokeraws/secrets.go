@@ -0,0 +1,288 @@
+package vokeraws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/hotsock/voker"
+)
+
+// SecretsCacheExtensionName is the name the secrets cache extension
+// registers with.
+const SecretsCacheExtensionName = "voker-secrets-cache"
+
+// DefaultSecretsTTL is how long a cached value is served before it is
+// refreshed, matching the AWS Parameters and Secrets Lambda Extension.
+const DefaultSecretsTTL = 5 * time.Minute
+
+// ErrNotCached is returned for a secret or parameter that was not
+// configured on the [SecretsCache].
+var ErrNotCached = errors.New("vokeraws: not configured in the secrets cache")
+
+// SecretsManagerAPI is the subset of the Secrets Manager client used by
+// [SecretsCache].
+type SecretsManagerAPI interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// SSMAPI is the subset of the Systems Manager client used by
+// [SecretsCache].
+type SSMAPI interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// CacheOption configures a [SecretsCache].
+type CacheOption func(*SecretsCache)
+
+// WithSecrets caches the Secrets Manager secrets with the given names or
+// ARNs.
+func WithSecrets(ids ...string) CacheOption {
+	return func(c *SecretsCache) {
+		for _, id := range ids {
+			c.entries[cacheKey{kind: secretKind, name: id}] = &cacheEntry{}
+		}
+	}
+}
+
+// WithParameters caches the SSM Parameter Store parameters with the given
+// names. SecureString parameters are decrypted.
+func WithParameters(names ...string) CacheOption {
+	return func(c *SecretsCache) {
+		for _, name := range names {
+			c.entries[cacheKey{kind: parameterKind, name: name}] = &cacheEntry{}
+		}
+	}
+}
+
+// WithTTL sets how long cached values are served before they are refreshed.
+// The default is [DefaultSecretsTTL].
+func WithTTL(ttl time.Duration) CacheOption {
+	return func(c *SecretsCache) {
+		c.ttl = ttl
+	}
+}
+
+// WithCacheLogger sets the logger for refresh failures. The default is
+// slog.Default().
+func WithCacheLogger(logger *slog.Logger) CacheOption {
+	return func(c *SecretsCache) {
+		c.logger = logger
+	}
+}
+
+// WithSecretsManagerClient replaces the Secrets Manager client built from
+// the aws.Config.
+func WithSecretsManagerClient(client SecretsManagerAPI) CacheOption {
+	return func(c *SecretsCache) {
+		c.secrets = client
+	}
+}
+
+// WithSSMClient replaces the Systems Manager client built from the
+// aws.Config.
+func WithSSMClient(client SSMAPI) CacheOption {
+	return func(c *SecretsCache) {
+		c.ssm = client
+	}
+}
+
+type cacheKind uint8
+
+const (
+	secretKind cacheKind = iota
+	parameterKind
+)
+
+func (k cacheKind) String() string {
+	if k == secretKind {
+		return "secret"
+	}
+	return "parameter"
+}
+
+type cacheKey struct {
+	kind cacheKind
+	name string
+}
+
+type cacheEntry struct {
+	value     []byte
+	fetchedAt time.Time
+}
+
+// SecretsCache holds Secrets Manager secrets and SSM parameters in memory,
+// in place of the AWS Parameters and Secrets Lambda Extension layer. Its
+// [SecretsCache.Extension] fetches every configured value during
+// initialization, failing initialization if any cannot be read, and
+// refreshes values older than the TTL when an invocation starts. Refreshes
+// run alongside the handler, which keeps reading the previous value until
+// the new one arrives. A failed refresh is logged and the previous value is
+// kept.
+//
+//	cache := vokeraws.NewSecretsCache(cfg,
+//	    vokeraws.WithSecrets("prod/db"),
+//	    vokeraws.WithParameters("/prod/feature-x"),
+//	)
+//	voker.Start(handler, voker.WithInternalExtension(cache.Extension()))
+//
+// Internal extensions are not supported on Lambda Managed Instances.
+type SecretsCache struct {
+	secrets SecretsManagerAPI
+	ssm     SSMAPI
+	ttl     time.Duration
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu      sync.RWMutex
+	entries map[cacheKey]*cacheEntry
+}
+
+// NewSecretsCache returns a cache that reads values with clients built from
+// cfg.
+func NewSecretsCache(cfg aws.Config, opts ...CacheOption) *SecretsCache {
+	c := &SecretsCache{
+		ttl:     DefaultSecretsTTL,
+		now:     time.Now,
+		entries: make(map[cacheKey]*cacheEntry),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.secrets == nil {
+		c.secrets = secretsmanager.NewFromConfig(cfg)
+	}
+	if c.ssm == nil {
+		c.ssm = ssm.NewFromConfig(cfg)
+	}
+	if c.logger == nil {
+		c.logger = slog.Default()
+	}
+	return c
+}
+
+// Extension returns the internal extension that fills and refreshes the
+// cache.
+func (c *SecretsCache) Extension() voker.InternalExtension {
+	return voker.InternalExtension{
+		Name: SecretsCacheExtensionName,
+		OnInit: func() error {
+			return c.refresh(context.Background(), true)
+		},
+		OnInvoke: func(ctx context.Context, _ voker.ExtensionEventPayload) {
+			_ = c.refresh(ctx, false)
+		},
+	}
+}
+
+// Secret returns the SecretString of a cached secret, or its SecretBinary
+// for binary secrets.
+func (c *SecretsCache) Secret(id string) (string, error) {
+	value, err := c.get(cacheKey{kind: secretKind, name: id})
+	return string(value), err
+}
+
+// SecretBinary returns a cached secret's value as bytes. The slice must not
+// be modified.
+func (c *SecretsCache) SecretBinary(id string) ([]byte, error) {
+	return c.get(cacheKey{kind: secretKind, name: id})
+}
+
+// Parameter returns the value of a cached parameter.
+func (c *SecretsCache) Parameter(name string) (string, error) {
+	value, err := c.get(cacheKey{kind: parameterKind, name: name})
+	return string(value), err
+}
+
+// SecretJSON decodes a cached secret holding a JSON document, such as the
+// username and password pairs Secrets Manager stores for databases.
+func SecretJSON[T any](c *SecretsCache, id string) (T, error) {
+	var v T
+	value, err := c.SecretBinary(id)
+	if err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return v, fmt.Errorf("vokeraws: decode secret %q: %w", id, err)
+	}
+	return v, nil
+}
+
+func (c *SecretsCache) get(key cacheKey) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	if !ok || entry.value == nil {
+		return nil, fmt.Errorf("%w: %s %q", ErrNotCached, key.kind, key.name)
+	}
+	return entry.value, nil
+}
+
+// refresh fetches the values that are older than the TTL, or every value
+// when all is true. During initialization the errors are returned; on later
+// refreshes they are logged and the stale value is kept.
+func (c *SecretsCache) refresh(ctx context.Context, all bool) error {
+	now := c.now()
+	var stale []cacheKey
+	c.mu.RLock()
+	for key, entry := range c.entries {
+		if all || now.Sub(entry.fetchedAt) >= c.ttl {
+			stale = append(stale, key)
+		}
+	}
+	c.mu.RUnlock()
+
+	var errs []error
+	for _, key := range stale {
+		value, err := c.fetch(ctx, key)
+		if err != nil {
+			err = fmt.Errorf("vokeraws: fetch %s %q: %w", key.kind, key.name, err)
+			if !all {
+				c.logger.WarnContext(ctx, "failed to refresh cached value", "error", err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		c.mu.Lock()
+		c.entries[key] = &cacheEntry{value: value, fetchedAt: now}
+		c.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+func (c *SecretsCache) fetch(ctx context.Context, key cacheKey) ([]byte, error) {
+	if key.kind == parameterKind {
+		out, err := c.ssm.GetParameter(ctx, &ssm.GetParameterInput{
+			Name:           aws.String(key.name),
+			WithDecryption: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if out.Parameter == nil {
+			return []byte{}, nil
+		}
+		return []byte(aws.ToString(out.Parameter.Value)), nil
+	}
+
+	out, err := c.secrets.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(key.name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.SecretString != nil {
+		return []byte(*out.SecretString), nil
+	}
+	if out.SecretBinary == nil {
+		return []byte{}, nil
+	}
+	return out.SecretBinary, nil
+}
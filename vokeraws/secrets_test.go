@@ -0,0 +1,150 @@
+package vokeraws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSecretsManager struct {
+	mu     sync.Mutex
+	values map[string]*secretsmanager.GetSecretValueOutput
+	err    error
+	calls  int
+}
+
+func (f *fakeSecretsManager) GetSecretValue(_ context.Context, in *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	out, ok := f.values[aws.ToString(in.SecretId)]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException")
+	}
+	return out, nil
+}
+
+type fakeSSM struct {
+	values     map[string]string
+	decryption bool
+}
+
+func (f *fakeSSM) GetParameter(_ context.Context, in *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	f.decryption = aws.ToBool(in.WithDecryption)
+	value, ok := f.values[aws.ToString(in.Name)]
+	if !ok {
+		return nil, errors.New("ParameterNotFound")
+	}
+	return &ssm.GetParameterOutput{Parameter: &types.Parameter{Value: aws.String(value)}}, nil
+}
+
+func newTestCache(sm *fakeSecretsManager, opts ...CacheOption) *SecretsCache {
+	params := &fakeSSM{values: map[string]string{"/app/flag": "on"}}
+	opts = append([]CacheOption{
+		WithSecretsManagerClient(sm),
+		WithSSMClient(params),
+		WithCacheLogger(slog.New(slog.DiscardHandler)),
+	}, opts...)
+	return NewSecretsCache(aws.Config{}, opts...)
+}
+
+func TestSecretsCache_OnInit(t *testing.T) {
+	sm := &fakeSecretsManager{values: map[string]*secretsmanager.GetSecretValueOutput{
+		"db":  {SecretString: aws.String(`{"username":"app","password":"hunter2"}`)},
+		"key": {SecretBinary: []byte{0x01, 0x02}},
+	}}
+	cache := newTestCache(sm, WithSecrets("db", "key"), WithParameters("/app/flag"))
+
+	ext := cache.Extension()
+	assert.Equal(t, SecretsCacheExtensionName, ext.Name)
+	require.NoError(t, ext.OnInit())
+
+	secret, err := cache.Secret("db")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"username":"app","password":"hunter2"}`, secret)
+
+	binary, err := cache.SecretBinary("key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x02}, binary)
+
+	flag, err := cache.Parameter("/app/flag")
+	require.NoError(t, err)
+	assert.Equal(t, "on", flag)
+	assert.True(t, cache.ssm.(*fakeSSM).decryption)
+
+	creds, err := SecretJSON[struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}](cache, "db")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", creds.Password)
+
+	_, err = SecretJSON[map[string]string](cache, "key")
+	assert.ErrorContains(t, err, `decode secret "key"`)
+
+	_, err = cache.Secret("other")
+	assert.ErrorIs(t, err, ErrNotCached)
+	_, err = cache.Parameter("db")
+	assert.ErrorIs(t, err, ErrNotCached)
+}
+
+func TestSecretsCache_OnInitError(t *testing.T) {
+	cache := newTestCache(&fakeSecretsManager{}, WithSecrets("missing"), WithParameters("/missing"))
+
+	err := cache.Extension().OnInit()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `fetch secret "missing": ResourceNotFoundException`)
+	assert.ErrorContains(t, err, `fetch parameter "/missing": ParameterNotFound`)
+}
+
+func TestSecretsCache_RefreshOnInvoke(t *testing.T) {
+	sm := &fakeSecretsManager{values: map[string]*secretsmanager.GetSecretValueOutput{
+		"db": {SecretString: aws.String("v1")},
+	}}
+	var logs bytes.Buffer
+	cache := newTestCache(sm, WithSecrets("db"), WithTTL(time.Minute),
+		WithCacheLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	now := time.Unix(1700000000, 0)
+	cache.now = func() time.Time { return now }
+
+	ext := cache.Extension()
+	require.NoError(t, ext.OnInit())
+	sm.values["db"] = &secretsmanager.GetSecretValueOutput{SecretString: aws.String("v2")}
+
+	// Fresh values are not fetched again.
+	now = now.Add(30 * time.Second)
+	ext.OnInvoke(context.Background(), voker.ExtensionEventPayload{})
+	assert.Equal(t, 1, sm.calls)
+
+	now = now.Add(30 * time.Second)
+	ext.OnInvoke(context.Background(), voker.ExtensionEventPayload{})
+	assert.Equal(t, 2, sm.calls)
+	secret, err := cache.Secret("db")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", secret)
+
+	// A failed refresh keeps serving the previous value.
+	sm.err = errors.New("throttled")
+	now = now.Add(time.Minute)
+	ext.OnInvoke(context.Background(), voker.ExtensionEventPayload{})
+	secret, err = cache.Secret("db")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", secret)
+	assert.Contains(t, logs.String(), "failed to refresh cached value")
+	assert.Contains(t, logs.String(), "throttled")
+}
@@ -0,0 +1,133 @@
+package vokeraws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hotsock/voker/vokerevents"
+)
+
+// Message attributes the SQS extended client libraries set on messages
+// whose payload was offloaded to S3. The legacy name is used by versions 1.x
+// of the Java library.
+const (
+	extendedPayloadSizeAttribute = "ExtendedPayloadSize"
+	legacyPayloadSizeAttribute   = "SQSLargePayloadSize"
+)
+
+// S3API is the subset of the S3 client used by [SQSExtendedHandler].
+type S3API interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+type extendedOptions struct {
+	deletePayloads bool
+}
+
+// ExtendedOption configures [SQSExtendedHandler].
+type ExtendedOption func(*extendedOptions)
+
+// WithDeletePayloads deletes a message's S3 payload after the handler
+// processes it successfully, as the extended client libraries do when a
+// consumer deletes the message. A failed delete is logged with
+// slog.Default() and does not fail the message. Leave it off when several
+// queues receive the same payload, such as through SNS fan-out.
+func WithDeletePayloads() ExtendedOption {
+	return func(o *extendedOptions) {
+		o.deletePayloads = true
+	}
+}
+
+// SQSExtendedHandler wraps a per-message handler to read messages sent by
+// the Amazon SQS Extended Client Library, which stores payloads too large
+// for SQS in S3 and sends a pointer to the object instead. The handler
+// receives the message with Body replaced by the S3 object's contents and
+// the extended client's size attribute removed. Other messages pass through
+// unchanged. An error reading the payload fails the message.
+//
+//	voker.Start(voker.SQSHandler(vokeraws.SQSExtendedHandler(s3.NewFromConfig(cfg), process,
+//	    vokeraws.WithDeletePayloads(),
+//	)))
+func SQSExtendedHandler(client S3API, handler func(context.Context, vokerevents.SQSMessage) error, opts ...ExtendedOption) func(context.Context, vokerevents.SQSMessage) error {
+	options := &extendedOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return func(ctx context.Context, msg vokerevents.SQSMessage) error {
+		pointer, ok, err := parseS3Pointer(msg)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return handler(ctx, msg)
+		}
+
+		out, err := client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(pointer.Bucket),
+			Key:    aws.String(pointer.Key),
+		})
+		if err != nil {
+			return fmt.Errorf("vokeraws: get SQS payload s3://%s/%s: %w", pointer.Bucket, pointer.Key, err)
+		}
+		body, err := io.ReadAll(out.Body)
+		out.Body.Close()
+		if err != nil {
+			return fmt.Errorf("vokeraws: read SQS payload s3://%s/%s: %w", pointer.Bucket, pointer.Key, err)
+		}
+
+		msg.Body = string(body)
+		msg.MessageAttributes = maps.Clone(msg.MessageAttributes)
+		delete(msg.MessageAttributes, extendedPayloadSizeAttribute)
+		delete(msg.MessageAttributes, legacyPayloadSizeAttribute)
+		if err := handler(ctx, msg); err != nil {
+			return err
+		}
+
+		if options.deletePayloads {
+			if _, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(pointer.Bucket),
+				Key:    aws.String(pointer.Key),
+			}); err != nil {
+				slog.WarnContext(ctx, "failed to delete SQS payload",
+					"bucket", pointer.Bucket, "key", pointer.Key, "error", err)
+			}
+		}
+		return nil
+	}
+}
+
+type s3Pointer struct {
+	Bucket string `json:"s3BucketName"`
+	Key    string `json:"s3Key"`
+}
+
+// parseS3Pointer decodes the body of a message carrying the extended
+// client's size attribute, which is a two-element JSON array of the
+// pointer's Java class name and the pointer itself:
+//
+//	["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"key"}]
+func parseS3Pointer(msg vokerevents.SQSMessage) (s3Pointer, bool, error) {
+	_, extended := msg.MessageAttributes[extendedPayloadSizeAttribute]
+	_, legacy := msg.MessageAttributes[legacyPayloadSizeAttribute]
+	if !extended && !legacy {
+		return s3Pointer{}, false, nil
+	}
+
+	var envelope []json.RawMessage
+	var pointer s3Pointer
+	if err := json.Unmarshal([]byte(msg.Body), &envelope); err != nil || len(envelope) != 2 {
+		return s3Pointer{}, false, fmt.Errorf("vokeraws: message %s has an extended payload attribute but no S3 pointer", msg.MessageID)
+	}
+	if err := json.Unmarshal(envelope[1], &pointer); err != nil || pointer.Bucket == "" || pointer.Key == "" {
+		return s3Pointer{}, false, fmt.Errorf("vokeraws: message %s has an invalid S3 pointer", msg.MessageID)
+	}
+	return pointer, true, nil
+}
@@ -0,0 +1,136 @@
+package vokeraws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hotsock/voker/vokerevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeS3 struct {
+	objects map[string]string
+	deleted []string
+	getErr  error
+	delErr  error
+}
+
+func (f *fakeS3) GetObject(_ context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	body, ok := f.objects[aws.ToString(in.Bucket)+"/"+aws.ToString(in.Key)]
+	if !ok {
+		return nil, errors.New("NoSuchKey")
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader([]byte(body)))}, nil
+}
+
+func (f *fakeS3) DeleteObject(_ context.Context, in *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	f.deleted = append(f.deleted, aws.ToString(in.Bucket)+"/"+aws.ToString(in.Key))
+	return &s3.DeleteObjectOutput{}, f.delErr
+}
+
+func extendedMessage(attribute, body string) vokerevents.SQSMessage {
+	return vokerevents.SQSMessage{
+		MessageID: "m1",
+		Body:      body,
+		MessageAttributes: map[string]vokerevents.SQSMessageAttribute{
+			attribute: {StringValue: aws.String("300000"), DataType: "Number"},
+			"trace":   {StringValue: aws.String("abc"), DataType: "String"},
+		},
+	}
+}
+
+func TestSQSExtendedHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		attribute string
+		body      string
+	}{
+		{"payload offloading", extendedPayloadSizeAttribute, `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"key"}]`},
+		{"legacy", legacyPayloadSizeAttribute, `["com.amazon.sqs.javamessaging.MessageS3Pointer",{"s3BucketName":"bucket","s3Key":"key"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeS3{objects: map[string]string{"bucket/key": "large payload"}}
+			msg := extendedMessage(tt.attribute, tt.body)
+
+			var got vokerevents.SQSMessage
+			handler := SQSExtendedHandler(client, func(_ context.Context, msg vokerevents.SQSMessage) error {
+				got = msg
+				return nil
+			}, WithDeletePayloads())
+			require.NoError(t, handler(context.Background(), msg))
+
+			assert.Equal(t, "large payload", got.Body)
+			assert.NotContains(t, got.MessageAttributes, tt.attribute)
+			assert.Contains(t, got.MessageAttributes, "trace")
+			assert.Contains(t, msg.MessageAttributes, tt.attribute, "caller's message must not be modified")
+			assert.Equal(t, []string{"bucket/key"}, client.deleted)
+		})
+	}
+}
+
+func TestSQSExtendedHandler_PassThrough(t *testing.T) {
+	client := &fakeS3{}
+	msg := vokerevents.SQSMessage{MessageID: "m1", Body: `["not","a pointer"]`}
+
+	var got vokerevents.SQSMessage
+	handler := SQSExtendedHandler(client, func(_ context.Context, msg vokerevents.SQSMessage) error {
+		got = msg
+		return nil
+	}, WithDeletePayloads())
+	require.NoError(t, handler(context.Background(), msg))
+	assert.Equal(t, msg, got)
+	assert.Empty(t, client.deleted)
+}
+
+func TestSQSExtendedHandler_Errors(t *testing.T) {
+	pointer := `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"key"}]`
+	called := false
+	process := func(context.Context, vokerevents.SQSMessage) error {
+		called = true
+		return nil
+	}
+
+	err := SQSExtendedHandler(&fakeS3{}, process)(context.Background(), extendedMessage(extendedPayloadSizeAttribute, "plain body"))
+	assert.ErrorContains(t, err, "message m1 has an extended payload attribute but no S3 pointer")
+
+	err = SQSExtendedHandler(&fakeS3{}, process)(context.Background(), extendedMessage(extendedPayloadSizeAttribute, `["class",{"s3Key":"key"}]`))
+	assert.ErrorContains(t, err, "message m1 has an invalid S3 pointer")
+
+	err = SQSExtendedHandler(&fakeS3{getErr: errors.New("AccessDenied")}, process)(context.Background(), extendedMessage(extendedPayloadSizeAttribute, pointer))
+	assert.ErrorContains(t, err, "get SQS payload s3://bucket/key: AccessDenied")
+	assert.False(t, called)
+
+	// Failed messages keep their payload for the retry.
+	client := &fakeS3{objects: map[string]string{"bucket/key": "payload"}}
+	err = SQSExtendedHandler(client, func(context.Context, vokerevents.SQSMessage) error {
+		return errors.New("boom")
+	}, WithDeletePayloads())(context.Background(), extendedMessage(extendedPayloadSizeAttribute, pointer))
+	assert.EqualError(t, err, "boom")
+	assert.Empty(t, client.deleted)
+}
+
+func TestSQSExtendedHandler_DeleteFailure(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	client := &fakeS3{objects: map[string]string{"bucket/key": "payload"}, delErr: errors.New("AccessDenied")}
+	handler := SQSExtendedHandler(client, func(context.Context, vokerevents.SQSMessage) error { return nil }, WithDeletePayloads())
+	err := handler(context.Background(), extendedMessage(extendedPayloadSizeAttribute,
+		`["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"key"}]`))
+	require.NoError(t, err)
+	assert.Contains(t, logs.String(), "failed to delete SQS payload")
+}
@@ -0,0 +1,78 @@
+package vokeraws
+
+import (
+	"context"
+	"fmt"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/hotsock/voker"
+	"github.com/hotsock/voker/vokerxray"
+)
+
+const traceHeader = "X-Amzn-Trace-Id"
+
+// AddTracing adds middleware to an SDK client's stack that makes each call
+// during an invocation part of the invocation's X-Ray trace. [LoadConfig]
+// adds it to every client; add it to configurations loaded another way
+// through aws.Config.APIOptions:
+//
+//	cfg.APIOptions = append(cfg.APIOptions, vokeraws.AddTracing)
+//
+// When the invocation is sampled, each call is recorded as a vokerxray
+// subsegment named after the service, spanning its retries, and its
+// requests carry a trace header with that subsegment as their parent.
+// Otherwise requests carry the invocation's trace header as is, which also
+// lets Lambda detect recursive invocation loops. The SDK's own propagation
+// reads the _X_AMZN_TRACE_ID variable, which voker never sets.
+func AddTracing(stack *middleware.Stack) error {
+	if err := stack.Initialize.Add(tracingSubsegment{}, middleware.After); err != nil {
+		return err
+	}
+	return stack.Build.Add(tracingHeader{}, middleware.Before)
+}
+
+// tracingSubsegment records each operation as a subsegment.
+type tracingSubsegment struct{}
+
+func (tracingSubsegment) ID() string { return "VokerTracingSubsegment" }
+
+func (tracingSubsegment) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	ctx, seg := vokerxray.BeginSubsegment(ctx, awsmiddleware.GetServiceID(ctx))
+	if seg == nil {
+		return next.HandleInitialize(ctx, in)
+	}
+	seg.SetNamespace("aws")
+	seg.AddMetadata("operation", awsmiddleware.GetOperationName(ctx))
+	if region := awsmiddleware.GetRegion(ctx); region != "" {
+		seg.AddMetadata("region", region)
+	}
+
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+		seg.AddMetadata("request_id", requestID)
+	}
+	seg.Close(err)
+	return out, metadata, err
+}
+
+// tracingHeader sets the trace header of each request.
+type tracingHeader struct{}
+
+func (tracingHeader) ID() string { return "VokerTracingHeader" }
+
+func (tracingHeader) HandleBuild(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+	req, ok := in.Request.(*smithyhttp.Request)
+	if !ok {
+		return middleware.BuildOutput{}, middleware.Metadata{}, fmt.Errorf("vokeraws: unknown request type %T", in.Request)
+	}
+	header := vokerxray.SubsegmentFromContext(ctx).TraceHeader()
+	if header == "" {
+		header = voker.TraceIDFromContext(ctx)
+	}
+	if header != "" && req.Header.Get(traceHeader) == "" {
+		req.Header.Set(traceHeader, header)
+	}
+	return next.HandleBuild(ctx, in)
+}
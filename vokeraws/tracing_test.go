@@ -0,0 +1,76 @@
+package vokeraws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddTracing(t *testing.T) {
+	daemon, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = daemon.Close() })
+	t.Setenv("AWS_XRAY_DAEMON_ADDRESS", daemon.LocalAddr().String())
+
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("X-Amzn-Trace-Id"))
+		w.Header().Set("X-Amzn-RequestId", "ssm-request-1")
+		_, _ = w.Write([]byte(`{"Parameter":{"Name":"/app/flag","Value":"on"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := ssm.NewFromConfig(aws.Config{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		BaseEndpoint: aws.String(server.URL),
+		APIOptions:   []func(*middleware.Stack) error{AddTracing},
+	})
+	call := func(ctx context.Context) {
+		_, err := client.GetParameter(ctx, &ssm.GetParameterInput{Name: aws.String("/app/flag")})
+		require.NoError(t, err)
+	}
+	traceContext := func(sampled string) context.Context {
+		return voker.NewContext(context.Background(), &voker.LambdaContext{
+			TraceID: "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=" + sampled,
+		})
+	}
+
+	call(traceContext("1"))
+	require.NoError(t, daemon.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buf := make([]byte, 64*1024)
+	n, err := daemon.Read(buf)
+	require.NoError(t, err)
+	_, body, _ := bytes.Cut(buf[:n], []byte("\n"))
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(body, &doc))
+
+	assert.Equal(t, "SSM", doc["name"])
+	assert.Equal(t, "aws", doc["namespace"])
+	assert.Equal(t, "53995c3f42cd8ad8", doc["parent_id"])
+	assert.Equal(t, map[string]any{"default": map[string]any{
+		"operation":  "GetParameter",
+		"region":     "us-east-1",
+		"request_id": "ssm-request-1",
+	}}, doc["metadata"])
+	assert.Equal(t, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent="+doc["id"].(string)+";Sampled=1", headers[0])
+
+	call(traceContext("0"))
+	assert.Equal(t, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0", headers[1])
+
+	call(context.Background())
+	assert.Empty(t, headers[2])
+}
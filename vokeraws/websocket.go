@@ -0,0 +1,82 @@
+package vokeraws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/hotsock/voker/vokeraws/internal/signedhttp"
+	"github.com/hotsock/voker/vokerevents"
+)
+
+// ErrGone is returned by [WebSocketClient] methods when the connection has
+// already closed. Handlers usually treat it as a signal to forget the
+// connection ID.
+var ErrGone = errors.New("vokeraws: connection is gone")
+
+// WebSocketClient calls the API Gateway Management API of a WebSocket API
+// stage to send messages to, or close, its client connections.
+type WebSocketClient struct {
+	endpoint string
+	client   *signedhttp.Client
+}
+
+// NewWebSocketClient returns a client for the stage at endpoint, such as
+// "https://abcdef123.execute-api.us-east-1.amazonaws.com/production".
+func NewWebSocketClient(cfg aws.Config, endpoint string) *WebSocketClient {
+	return &WebSocketClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   signedhttp.New(cfg, "execute-api"),
+	}
+}
+
+// WebSocketClientFor returns a client for the stage that sent the event
+// with request context rc:
+//
+//	func handler(ctx context.Context, event vokerevents.WebSocketRequest) (vokerevents.WebSocketResponse, error) {
+//	    client := vokeraws.WebSocketClientFor(cfg, event.RequestContext)
+//	    err := client.PostToConnection(ctx, event.RequestContext.ConnectionID, []byte("pong"))
+//	    ...
+//	}
+//
+// Events that arrive through a custom domain name are sent to the API's
+// execute-api endpoint in cfg's region, since custom domains can map the
+// stage to any base path.
+func WebSocketClientFor(cfg aws.Config, rc vokerevents.WebSocketRequestContext) *WebSocketClient {
+	domain := rc.DomainName
+	if !strings.Contains(domain, ".execute-api.") {
+		domain = strings.TrimPrefix(signedhttp.Endpoint(aws.Config{Region: cfg.Region}, rc.APIID+".execute-api"), "https://")
+	}
+	return NewWebSocketClient(cfg, "https://"+domain+"/"+rc.Stage)
+}
+
+// PostToConnection sends data to the client of connectionID as one
+// message. It returns [ErrGone] if the client has disconnected.
+func (c *WebSocketClient) PostToConnection(ctx context.Context, connectionID string, data []byte) error {
+	if data == nil {
+		data = []byte{}
+	}
+	return c.do(ctx, http.MethodPost, connectionID, data, "post to connection")
+}
+
+// DeleteConnection closes the connection of connectionID. It returns
+// [ErrGone] if the client has already disconnected.
+func (c *WebSocketClient) DeleteConnection(ctx context.Context, connectionID string) error {
+	return c.do(ctx, http.MethodDelete, connectionID, nil, "delete connection")
+}
+
+func (c *WebSocketClient) do(ctx context.Context, method, connectionID string, body []byte, operation string) error {
+	resp, err := c.client.Do(ctx, method, c.endpoint+"/@connections/"+url.PathEscape(connectionID), body)
+	if err != nil {
+		var apiErr *signedhttp.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusGone {
+			return fmt.Errorf("vokeraws: %s %s: %w", operation, connectionID, ErrGone)
+		}
+		return fmt.Errorf("vokeraws: %s %s: %w", operation, connectionID, err)
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,83 @@
+package vokeraws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/hotsock/voker/vokerevents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testWebSocketConfig = aws.Config{
+	Region:      "us-east-1",
+	Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+}
+
+func TestWebSocketClient(t *testing.T) {
+	type request struct {
+		method, path, auth, body string
+	}
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, request{r.Method, r.URL.EscapedPath(), r.Header.Get("Authorization"), string(body)})
+		if strings.HasSuffix(r.URL.Path, "/gone") {
+			w.Header().Set("X-Amzn-ErrorType", "GoneException")
+			w.WriteHeader(http.StatusGone)
+			_, _ = w.Write([]byte(`{"message":null}`))
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/throttled") {
+			w.Header().Set("X-Amzn-ErrorType", "LimitExceededException")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message":"Rate exceeded"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewWebSocketClient(testWebSocketConfig, server.URL+"/production/")
+	ctx := context.Background()
+	require.NoError(t, client.PostToConnection(ctx, "L0SM9cOFvHcCIhw=", []byte(`{"message":"hello"}`)))
+	require.NoError(t, client.DeleteConnection(ctx, "L0SM9cOFvHcCIhw="))
+
+	err := client.PostToConnection(ctx, "gone", []byte("hello"))
+	assert.ErrorIs(t, err, ErrGone)
+	assert.EqualError(t, err, "vokeraws: post to connection gone: vokeraws: connection is gone")
+
+	err = client.DeleteConnection(ctx, "throttled")
+	assert.NotErrorIs(t, err, ErrGone)
+	assert.EqualError(t, err, "vokeraws: delete connection throttled: LimitExceededException: Rate exceeded (status 429)")
+
+	require.Len(t, requests, 4)
+	assert.Equal(t, http.MethodPost, requests[0].method)
+	assert.Equal(t, "/production/@connections/L0SM9cOFvHcCIhw=", requests[0].path)
+	assert.Equal(t, `{"message":"hello"}`, requests[0].body)
+	assert.Contains(t, requests[0].auth, "/us-east-1/execute-api/aws4_request")
+	assert.Equal(t, http.MethodDelete, requests[1].method)
+	assert.Empty(t, requests[1].body)
+}
+
+func TestWebSocketClientFor(t *testing.T) {
+	rc := vokerevents.WebSocketRequestContext{
+		DomainName: "abcdef123.execute-api.us-east-1.amazonaws.com",
+		Stage:      "production",
+		APIID:      "abcdef123",
+	}
+	assert.Equal(t, "https://abcdef123.execute-api.us-east-1.amazonaws.com/production", WebSocketClientFor(testWebSocketConfig, rc).endpoint)
+
+	rc.DomainName = "ws.example.com"
+	assert.Equal(t, "https://abcdef123.execute-api.us-east-1.amazonaws.com/production", WebSocketClientFor(testWebSocketConfig, rc).endpoint)
+
+	cfg := testWebSocketConfig
+	cfg.Region = "cn-north-1"
+	assert.Equal(t, "https://abcdef123.execute-api.cn-north-1.amazonaws.com.cn/production", WebSocketClientFor(cfg, rc).endpoint)
+}
@@ -0,0 +1,248 @@
+// Package vokerchaos injects faults into voker invocations for chaos
+// experiments and game days: added latency, error responses, and malformed
+// responses, for a percentage of invocations.
+//
+// Faults are configured with environment variables, or loaded from a
+// source such as an SSM parameter so an experiment can start and stop
+// without redeploying the function:
+//
+//	voker.Start(handler, voker.WithMiddleware(vokerchaos.Middleware()))
+//
+// With no configuration, or a percentage of 0, the middleware passes every
+// invocation through untouched.
+package vokerchaos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hotsock/voker"
+)
+
+// DefaultErrorType is the errorType of injected errors when
+// [Config].ErrorType is empty.
+const DefaultErrorType = "ChaosInjectedError"
+
+// Config describes the faults to inject.
+type Config struct {
+	// Percentage is the share of invocations faults are injected into,
+	// from 0 to 100.
+	Percentage float64
+
+	// Latency is added before the handler runs. The delay ends early if
+	// the invocation's context is canceled.
+	Latency time.Duration
+
+	// ErrorType and ErrorMessage, when either is set, fail the invocation
+	// with an error response instead of calling the handler.
+	ErrorType    string
+	ErrorMessage string
+
+	// Corrupt replaces the handler's output with its JSON encoding as a
+	// string: valid JSON of the wrong shape, so callers see a malformed
+	// response. Streaming responses are not corrupted.
+	Corrupt bool
+}
+
+type configJSON struct {
+	Percentage   float64 `json:"percentage"`
+	Latency      string  `json:"latency"`
+	ErrorType    string  `json:"errorType"`
+	ErrorMessage string  `json:"errorMessage"`
+	Corrupt      bool    `json:"corrupt"`
+}
+
+// ParseConfig parses a JSON configuration, such as the value of an SSM
+// parameter. Latency is a duration string:
+//
+//	{"percentage": 10, "latency": "500ms", "errorType": "ThrottlingException", "corrupt": false}
+func ParseConfig(data []byte) (Config, error) {
+	var raw configJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Config{}, fmt.Errorf("vokerchaos: parse config: %w", err)
+	}
+	cfg := Config{
+		Percentage:   raw.Percentage,
+		ErrorType:    raw.ErrorType,
+		ErrorMessage: raw.ErrorMessage,
+		Corrupt:      raw.Corrupt,
+	}
+	if raw.Latency != "" {
+		latency, err := time.ParseDuration(raw.Latency)
+		if err != nil {
+			return Config{}, fmt.Errorf("vokerchaos: parse config: invalid latency: %w", err)
+		}
+		cfg.Latency = latency
+	}
+	return cfg, cfg.validate()
+}
+
+// ConfigFromEnv reads the configuration from the VOKER_CHAOS_PERCENTAGE,
+// VOKER_CHAOS_LATENCY, VOKER_CHAOS_ERROR_TYPE, VOKER_CHAOS_ERROR_MESSAGE, and
+// VOKER_CHAOS_CORRUPT environment variables. Unset variables leave their
+// field at its zero value.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		ErrorType:    os.Getenv("VOKER_CHAOS_ERROR_TYPE"),
+		ErrorMessage: os.Getenv("VOKER_CHAOS_ERROR_MESSAGE"),
+	}
+	var err error
+	if value := os.Getenv("VOKER_CHAOS_PERCENTAGE"); value != "" {
+		if cfg.Percentage, err = strconv.ParseFloat(value, 64); err != nil {
+			return Config{}, fmt.Errorf("vokerchaos: invalid VOKER_CHAOS_PERCENTAGE: %w", err)
+		}
+	}
+	if value := os.Getenv("VOKER_CHAOS_LATENCY"); value != "" {
+		if cfg.Latency, err = time.ParseDuration(value); err != nil {
+			return Config{}, fmt.Errorf("vokerchaos: invalid VOKER_CHAOS_LATENCY: %w", err)
+		}
+	}
+	if value := os.Getenv("VOKER_CHAOS_CORRUPT"); value != "" {
+		if cfg.Corrupt, err = strconv.ParseBool(value); err != nil {
+			return Config{}, fmt.Errorf("vokerchaos: invalid VOKER_CHAOS_CORRUPT: %w", err)
+		}
+	}
+	return cfg, cfg.validate()
+}
+
+func (c Config) validate() error {
+	if c.Percentage < 0 || c.Percentage > 100 {
+		return fmt.Errorf("vokerchaos: percentage %v is not between 0 and 100", c.Percentage)
+	}
+	if c.Latency < 0 {
+		return errors.New("vokerchaos: latency is negative")
+	}
+	return nil
+}
+
+type options struct {
+	source func(context.Context) (Config, error)
+	logger *slog.Logger
+	random func() float64
+}
+
+// Option configures [Middleware].
+type Option func(*options)
+
+// WithConfig injects the faults in cfg instead of those configured by the
+// environment.
+func WithConfig(cfg Config) Option {
+	return func(o *options) {
+		o.source = func(context.Context) (Config, error) { return cfg, nil }
+	}
+}
+
+// WithConfigSource loads the configuration with source on every
+// invocation, so experiments change without a deployment. source should
+// read from memory; with an SSM parameter kept fresh by
+// vokeraws.SecretsCache:
+//
+//	vokerchaos.WithConfigSource(func(context.Context) (vokerchaos.Config, error) {
+//	    value, err := cache.Parameter("/game-day/orders")
+//	    if err != nil {
+//	        return vokerchaos.Config{}, err
+//	    }
+//	    return vokerchaos.ParseConfig([]byte(value))
+//	})
+//
+// Invocations whose configuration fails to load run without faults.
+func WithConfigSource(source func(context.Context) (Config, error)) Option {
+	return func(o *options) {
+		o.source = source
+	}
+}
+
+// WithLogger sets the logger that records injected faults and configuration
+// errors. The default is slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// Middleware returns middleware that injects faults into the configured
+// percentage of invocations. Without [WithConfig] or [WithConfigSource],
+// the configuration is read once from the environment with
+// [ConfigFromEnv]; if it is invalid, the error is logged and no faults are
+// injected. Every injected fault is logged as a warning.
+func Middleware(opts ...Option) voker.Middleware {
+	o := options{random: rand.Float64}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.logger == nil {
+		o.logger = slog.Default()
+	}
+	if o.source == nil {
+		cfg, err := ConfigFromEnv()
+		if err != nil {
+			o.logger.Error("invalid chaos configuration", "error", err)
+		}
+		o.source = func(context.Context) (Config, error) { return cfg, nil }
+	}
+
+	return func(next voker.InvokeFunc) voker.InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			cfg, err := o.source(ctx)
+			if err != nil {
+				o.logger.WarnContext(ctx, "failed to load chaos configuration", "error", err)
+				return next(ctx, payload)
+			}
+			if cfg.Percentage <= 0 || o.random()*100 >= cfg.Percentage {
+				return next(ctx, payload)
+			}
+			return inject(ctx, cfg, o.logger, next, payload)
+		}
+	}
+}
+
+func inject(ctx context.Context, cfg Config, logger *slog.Logger, next voker.InvokeFunc, payload json.RawMessage) (any, error) {
+	injectError := cfg.ErrorType != "" || cfg.ErrorMessage != ""
+	logger.WarnContext(ctx, "injecting chaos faults",
+		"latency", cfg.Latency,
+		"error", injectError,
+		"corrupt", cfg.Corrupt && !injectError,
+	)
+
+	if cfg.Latency > 0 {
+		timer := time.NewTimer(cfg.Latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	if injectError {
+		errorType := cfg.ErrorType
+		if errorType == "" {
+			errorType = DefaultErrorType
+		}
+		message := cfg.ErrorMessage
+		if message == "" {
+			message = "fault injected by vokerchaos"
+		}
+		return nil, &voker.ErrorResponse{Type: errorType, Message: message}
+	}
+
+	output, err := next(ctx, payload)
+	if err != nil || !cfg.Corrupt {
+		return output, err
+	}
+	if _, ok := output.(io.Reader); ok {
+		return output, nil
+	}
+	encoded, err := json.Marshal(output)
+	if err != nil {
+		return output, nil
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,157 @@
+package vokerchaos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHandler(calls *int) voker.InvokeFunc {
+	return func(context.Context, json.RawMessage) (any, error) {
+		*calls++
+		return map[string]string{"status": "ok"}, nil
+	}
+}
+
+func newTestMiddleware(random float64, opts ...Option) (voker.Middleware, *bytes.Buffer) {
+	var logs bytes.Buffer
+	opts = append([]Option{WithLogger(slog.New(slog.NewTextHandler(&logs, nil)))}, opts...)
+	middleware := Middleware(append(opts, func(o *options) {
+		o.random = func() float64 { return random }
+	})...)
+	return middleware, &logs
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"percentage":12.5,"latency":"250ms","errorType":"ThrottlingException","errorMessage":"slow down","corrupt":true}`))
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Percentage:   12.5,
+		Latency:      250 * time.Millisecond,
+		ErrorType:    "ThrottlingException",
+		ErrorMessage: "slow down",
+		Corrupt:      true,
+	}, cfg)
+
+	_, err = ParseConfig([]byte(`{"latency":"soon"}`))
+	assert.ErrorContains(t, err, "vokerchaos: parse config: invalid latency")
+	_, err = ParseConfig([]byte(`{"percentage":101}`))
+	assert.EqualError(t, err, "vokerchaos: percentage 101 is not between 0 and 100")
+	_, err = ParseConfig([]byte(`[]`))
+	assert.ErrorContains(t, err, "vokerchaos: parse config")
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("VOKER_CHAOS_PERCENTAGE", "5")
+	t.Setenv("VOKER_CHAOS_LATENCY", "1s")
+	t.Setenv("VOKER_CHAOS_ERROR_TYPE", "")
+	t.Setenv("VOKER_CHAOS_ERROR_MESSAGE", "boom")
+	t.Setenv("VOKER_CHAOS_CORRUPT", "true")
+
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Config{Percentage: 5, Latency: time.Second, ErrorMessage: "boom", Corrupt: true}, cfg)
+
+	t.Setenv("VOKER_CHAOS_CORRUPT", "maybe")
+	_, err = ConfigFromEnv()
+	assert.ErrorContains(t, err, "vokerchaos: invalid VOKER_CHAOS_CORRUPT")
+
+	t.Setenv("VOKER_CHAOS_CORRUPT", "")
+	t.Setenv("VOKER_CHAOS_LATENCY", "-1s")
+	_, err = ConfigFromEnv()
+	assert.EqualError(t, err, "vokerchaos: latency is negative")
+}
+
+func TestMiddleware_Percentage(t *testing.T) {
+	cfg := Config{Percentage: 25, ErrorMessage: "boom"}
+
+	var calls int
+	middleware, _ := newTestMiddleware(0.3, WithConfig(cfg))
+	output, err := middleware(testHandler(&calls))(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"status": "ok"}, output)
+	assert.Equal(t, 1, calls)
+
+	middleware, logs := newTestMiddleware(0.2, WithConfig(cfg))
+	_, err = middleware(testHandler(&calls))(context.Background(), nil)
+	errResp, ok := errors.AsType[*voker.ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, DefaultErrorType, errResp.Type)
+	assert.Equal(t, "boom", errResp.Message)
+	assert.Equal(t, 1, calls, "the handler is not called")
+	assert.Contains(t, logs.String(), `msg="injecting chaos faults" latency=0s error=true corrupt=false`)
+}
+
+func TestMiddleware_LatencyAndCorruption(t *testing.T) {
+	var calls int
+	middleware, _ := newTestMiddleware(0, WithConfig(Config{Percentage: 100, Latency: 20 * time.Millisecond, Corrupt: true}))
+
+	start := time.Now()
+	output, err := middleware(testHandler(&calls))(context.Background(), nil)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, `{"status":"ok"}`, output)
+
+	streaming := func(context.Context, json.RawMessage) (any, error) {
+		return strings.NewReader("data"), nil
+	}
+	output, err = middleware(streaming)(context.Background(), nil)
+	require.NoError(t, err)
+	_, ok := output.(io.Reader)
+	assert.True(t, ok)
+}
+
+func TestMiddleware_LatencyEndsWithContext(t *testing.T) {
+	var calls int
+	middleware, _ := newTestMiddleware(0, WithConfig(Config{Percentage: 100, Latency: time.Hour}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := middleware(testHandler(&calls))(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestMiddleware_ConfigSource(t *testing.T) {
+	var calls int
+	loads := 0
+	middleware, logs := newTestMiddleware(0, WithConfigSource(func(context.Context) (Config, error) {
+		loads++
+		if loads == 1 {
+			return Config{}, errors.New("parameter not cached")
+		}
+		return Config{Percentage: 100, ErrorType: "ThrottlingException"}, nil
+	}))
+	invoke := middleware(testHandler(&calls))
+
+	_, err := invoke(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Contains(t, logs.String(), "failed to load chaos configuration")
+
+	_, err = invoke(context.Background(), nil)
+	errResp, ok := errors.AsType[*voker.ErrorResponse](err)
+	require.True(t, ok)
+	assert.Equal(t, "ThrottlingException", errResp.Type)
+	assert.Equal(t, "fault injected by vokerchaos", errResp.Message)
+}
+
+func TestMiddleware_InvalidEnvironment(t *testing.T) {
+	t.Setenv("VOKER_CHAOS_PERCENTAGE", "all")
+	var calls int
+	middleware, logs := newTestMiddleware(0)
+
+	_, err := middleware(testHandler(&calls))(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Contains(t, logs.String(), "invalid chaos configuration")
+}
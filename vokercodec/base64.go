@@ -0,0 +1,108 @@
+package vokercodec
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"unsafe"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/hotsock/voker/vokerevents"
+)
+
+// base64Tag is the struct tag option that marks a field as base64-encoded:
+//
+//	Data Order `json:"data" voker:"base64"`
+const base64Tag = "base64"
+
+// UpdateStructDescriptor wraps the coders of fields tagged voker:"base64".
+func (e *decoderExtension) UpdateStructDescriptor(desc *jsoniter.StructDescriptor) {
+	for _, binding := range desc.Fields {
+		if !slices.Contains(strings.Split(binding.Field.Tag().Get("voker"), ","), base64Tag) {
+			continue
+		}
+		kind := binding.Field.Type().Kind()
+		raw := kind == reflect.String || binding.Field.Type().Type1() == reflect.TypeFor[[]byte]()
+		if binding.Decoder != nil {
+			binding.Decoder = base64Decoder{api: e.api, inner: binding.Decoder, raw: raw, kind: kind}
+		}
+		if binding.Encoder != nil {
+			binding.Encoder = base64Encoder{api: e.api, inner: binding.Encoder, raw: raw, kind: kind}
+		}
+	}
+}
+
+// base64Decoder decodes a base64 string and then the decoded bytes into the
+// field: directly for string and []byte fields, and as a JSON document for
+// any other type.
+type base64Decoder struct {
+	api   jsoniter.API
+	inner jsoniter.ValDecoder
+	raw   bool
+	kind  reflect.Kind
+}
+
+func (d base64Decoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	if iter.ReadNil() {
+		return
+	}
+	if iter.WhatIsNext() != jsoniter.StringValue {
+		iter.Skip()
+		iter.ReportError("decode base64 field", "expected a base64 string")
+		return
+	}
+	decoded, err := vokerevents.DecodeBase64(iter.ReadString())
+	if err != nil {
+		iter.ReportError("decode base64 field", err.Error())
+		return
+	}
+
+	switch {
+	case d.raw && d.kind == reflect.String:
+		*(*string)(ptr) = string(decoded)
+	case d.raw:
+		*(*[]byte)(ptr) = decoded
+	default:
+		sub := d.api.BorrowIterator(decoded)
+		defer d.api.ReturnIterator(sub)
+		d.inner.Decode(ptr, sub)
+		if sub.Error != nil && !errors.Is(sub.Error, io.EOF) {
+			iter.ReportError("decode base64 field", sub.Error.Error())
+		}
+	}
+}
+
+// base64Encoder encodes the field as a standard base64 string of its raw
+// bytes or JSON encoding, mirroring base64Decoder.
+type base64Encoder struct {
+	api   jsoniter.API
+	inner jsoniter.ValEncoder
+	raw   bool
+	kind  reflect.Kind
+}
+
+func (e base64Encoder) IsEmpty(ptr unsafe.Pointer) bool {
+	return e.inner.IsEmpty(ptr)
+}
+
+func (e base64Encoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	switch {
+	case e.raw && e.kind == reflect.String:
+		stream.WriteString(base64.StdEncoding.EncodeToString([]byte(*(*string)(ptr))))
+	case e.raw:
+		stream.WriteString(base64.StdEncoding.EncodeToString(*(*[]byte)(ptr)))
+	default:
+		sub := e.api.BorrowStream(nil)
+		defer e.api.ReturnStream(sub)
+		e.inner.Encode(ptr, sub)
+		if sub.Error != nil {
+			stream.Error = sub.Error
+			return
+		}
+		stream.WriteString(base64.StdEncoding.EncodeToString(sub.Buffer()))
+	}
+}
@@ -0,0 +1,63 @@
+package vokercodec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type base64Order struct {
+	OrderID string `json:"orderId"`
+	Total   int    `json:"total"`
+}
+
+type base64Record struct {
+	Order   base64Order  `json:"order" voker:"base64"`
+	Pointer *base64Order `json:"pointer,omitempty" voker:"base64"`
+	Text    string       `json:"text" voker:"base64"`
+	Bytes   []byte       `json:"bytes" voker:"base64"`
+	Plain   string       `json:"plain"`
+}
+
+func TestNewJsoniter_Base64Fields(t *testing.T) {
+	codec := NewJsoniter()
+
+	var v base64Record
+	require.NoError(t, codec.Unmarshal([]byte(`{
+		"order": "eyJvcmRlcklkIjoiby00MiIsInRvdGFsIjoxOTk5fQ==",
+		"pointer": "eyJvcmRlcklkIjoiby00MyJ9",
+		"text": "aGVsbG8",
+		"bytes": "aGk_",
+		"plain": "aGVsbG8="
+	}`), &v))
+	assert.Equal(t, base64Order{OrderID: "o-42", Total: 1999}, v.Order)
+	require.NotNil(t, v.Pointer)
+	assert.Equal(t, "o-43", v.Pointer.OrderID)
+	assert.Equal(t, "hello", v.Text)
+	assert.Equal(t, []byte("hi?"), v.Bytes)
+	assert.Equal(t, "aGVsbG8=", v.Plain, "untagged fields are unaffected")
+
+	b, err := codec.Marshal(base64Record{Order: v.Order, Text: "hello", Bytes: []byte("hi?"), Plain: "plain"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"order": "eyJvcmRlcklkIjoiby00MiIsInRvdGFsIjoxOTk5fQ==",
+		"text": "aGVsbG8=",
+		"bytes": "aGk/",
+		"plain": "plain"
+	}`, string(b))
+
+	var back base64Record
+	require.NoError(t, codec.Unmarshal(b, &back))
+	assert.Equal(t, v.Order, back.Order)
+}
+
+func TestNewJsoniter_Base64FieldErrors(t *testing.T) {
+	codec := NewJsoniter()
+
+	var v base64Record
+	assert.ErrorContains(t, codec.Unmarshal([]byte(`{"order":42}`), &v), "expected a base64 string")
+	assert.ErrorContains(t, codec.Unmarshal([]byte(`{"order":"%%%"}`), &v), "failed to decode base64 data")
+	assert.Error(t, codec.Unmarshal([]byte(`{"order":"bm90IGpzb24="}`), &v))
+	require.NoError(t, codec.Unmarshal([]byte(`{"order":null}`), &v))
+}
@@ -0,0 +1,62 @@
+package vokercodec
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hotsock/voker"
+	"github.com/hotsock/voker/vokerhttp"
+)
+
+type stdlibCodec struct{}
+
+func (stdlibCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (stdlibCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+func benchmarkCodecs() map[string]voker.Codec {
+	codecs := map[string]voker.Codec{"encoding/json": stdlibCodec{}}
+	for name, codec := range testCodecs {
+		codecs[name] = codec
+	}
+	return codecs
+}
+
+func BenchmarkUnmarshalAPIGatewayV2Request(b *testing.B) {
+	payload := readFixture(b)
+	for name, codec := range benchmarkCodecs() {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(payload)))
+			b.ReportAllocs()
+			for b.Loop() {
+				var request vokerhttp.APIGatewayV2Request
+				if err := codec.Unmarshal(payload, &request); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMarshalAPIGatewayV2Response(b *testing.B) {
+	response := vokerhttp.APIGatewayV2Response{
+		StatusCode: 200,
+		Headers: map[string]string{
+			"content-type":  "application/json",
+			"cache-control": "no-store",
+			"x-request-id":  "c6af9ac6-7b61-11e6-9a41-93e8deadbeef",
+		},
+		Body:    `{"items":[` + strings.Repeat(`{"id":"item","count":42,"tags":["a","b"]},`, 31) + `{"id":"last","count":0,"tags":[]}]}`,
+		Cookies: []string{"session=abc123; Path=/; HttpOnly; Secure"},
+	}
+	for name, codec := range benchmarkCodecs() {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := codec.Marshal(response); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Package vokercodec provides [voker.Codec] implementations backed by faster
+// JSON libraries than encoding/json.
+//
+// Install one with [voker.WithCodec]:
+//
+//	voker.Start(handler, voker.WithCodec(vokercodec.Jsoniter))
+//
+// [Sonic] is only available on amd64. Both codecs are configured for
+// compatibility with encoding/json, so struct tags, json.Marshaler and
+// json.Unmarshaler implementations behave the same way.
+//
+// [NewJsoniter] builds a json-iterator codec that also decodes timestamps in
+// other formats, such as epoch milliseconds, integers sent as strings, and
+// fields tagged voker:"base64".
+package vokercodec
+
+import (
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/hotsock/voker"
+)
+
+// Jsoniter encodes and decodes with json-iterator using its encoding/json
+// compatible configuration.
+var Jsoniter voker.Codec = jsoniterCodec{api: jsoniter.ConfigCompatibleWithStandardLibrary}
+
+type jsoniterCodec struct {
+	api jsoniter.API
+}
+
+func (c jsoniterCodec) Marshal(v any) ([]byte, error) {
+	return c.api.Marshal(v)
+}
+
+func (c jsoniterCodec) Unmarshal(data []byte, v any) error {
+	return c.api.Unmarshal(data, v)
+}
@@ -0,0 +1,96 @@
+package vokercodec
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hotsock/voker"
+	"github.com/hotsock/voker/vokerhttp"
+)
+
+// testCodecs lists the codecs available on the current architecture.
+var testCodecs = map[string]voker.Codec{
+	"jsoniter": Jsoniter,
+}
+
+type textValue string
+
+func (v textValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal("custom:" + string(v))
+}
+
+type compatibilityValue struct {
+	Name      string            `json:"name"`
+	Omitted   string            `json:"omitted,omitempty"`
+	Ignored   string            `json:"-"`
+	Raw       json.RawMessage   `json:"raw"`
+	Custom    textValue         `json:"custom"`
+	Time      time.Time         `json:"time"`
+	Labels    map[string]string `json:"labels"`
+	Bytes     []byte            `json:"bytes"`
+	HTMLField string            `json:"html"`
+}
+
+func TestCodecs_MatchEncodingJSON(t *testing.T) {
+	value := compatibilityValue{
+		Name:      "voker",
+		Ignored:   "hidden",
+		Raw:       json.RawMessage(`{"nested":[1,2,3]}`),
+		Custom:    "value",
+		Time:      time.Date(2026, 7, 10, 12, 30, 0, 0, time.UTC),
+		Labels:    map[string]string{"b": "2", "a": "1"},
+		Bytes:     []byte("bytes"),
+		HTMLField: "<a href=\"x\">&</a>",
+	}
+	want, err := json.Marshal(value)
+	require.NoError(t, err)
+
+	for name, codec := range testCodecs {
+		t.Run(name, func(t *testing.T) {
+			got, err := codec.Marshal(value)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(got))
+
+			var decoded map[string]any
+			require.NoError(t, codec.Unmarshal(got, &decoded))
+			assert.Equal(t, "custom:value", decoded["custom"])
+			assert.NotContains(t, decoded, "omitted")
+		})
+	}
+}
+
+func TestCodecs_DecodeAPIGatewayV2Request(t *testing.T) {
+	payload := readFixture(t)
+	var want vokerhttp.APIGatewayV2Request
+	require.NoError(t, json.Unmarshal(payload, &want))
+
+	for name, codec := range testCodecs {
+		t.Run(name, func(t *testing.T) {
+			var got vokerhttp.APIGatewayV2Request
+			require.NoError(t, codec.Unmarshal(payload, &got))
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestCodecs_UnmarshalError(t *testing.T) {
+	for name, codec := range testCodecs {
+		t.Run(name, func(t *testing.T) {
+			var got vokerhttp.APIGatewayV2Request
+			assert.Error(t, codec.Unmarshal([]byte(`{"version":`), &got))
+		})
+	}
+}
+
+func readFixture(t testing.TB) []byte {
+	t.Helper()
+
+	payload, err := os.ReadFile("../vokerhttp/testdata/apigwv2-request.json")
+	require.NoError(t, err)
+	return payload
+}
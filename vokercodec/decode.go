@@ -0,0 +1,217 @@
+package vokercodec
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+	"unsafe"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/modern-go/reflect2"
+
+	"github.com/hotsock/voker"
+)
+
+// TimeDecoder decodes a time.Time from value, the raw JSON of a time field:
+// a quoted string or a number.
+type TimeDecoder func(value []byte) (time.Time, error)
+
+// EpochTime returns a TimeDecoder for times given as a count of unit since
+// the Unix epoch, such as time.Second for DynamoDB TTL attributes or
+// time.Millisecond for Kinesis approximate arrival timestamps. Counts may be
+// JSON numbers, including fractional ones, or strings holding a number.
+// Decoded times are in UTC.
+func EpochTime(unit time.Duration) TimeDecoder {
+	return func(value []byte) (time.Time, error) {
+		text := string(value)
+		if unquoted, err := strconv.Unquote(text); err == nil {
+			text = unquoted
+		}
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return time.Unix(0, 0).Add(time.Duration(n) * unit).UTC(), nil
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s is not an epoch time", value)
+		}
+		return time.Unix(0, int64(f*float64(unit))).UTC(), nil
+	}
+}
+
+// LayoutTime returns a TimeDecoder for JSON strings in any of layouts, as
+// accepted by time.Parse. Times in layouts without a zone, such as
+// "2006-01-02T15:04:05", are in UTC.
+func LayoutTime(layouts ...string) TimeDecoder {
+	return func(value []byte) (time.Time, error) {
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			return time.Time{}, fmt.Errorf("%s is not a string", value)
+		}
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, text); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("%q matches no time layout", text)
+	}
+}
+
+type codecOptions struct {
+	timeDecoders []TimeDecoder
+	stringInts   bool
+}
+
+// Option configures a codec built by [NewJsoniter].
+type Option func(*codecOptions)
+
+// WithTimeDecoders decodes time.Time values that aren't RFC 3339 strings
+// with the first of decoders that succeeds. AWS event sources are
+// inconsistent about timestamp formats:
+//
+//	codec := vokercodec.NewJsoniter(vokercodec.WithTimeDecoders(
+//	    vokercodec.EpochTime(time.Millisecond),
+//	    vokercodec.LayoutTime("2006-01-02T15:04:05", "2006-01-02 15:04:05"),
+//	))
+//	voker.Start(handler, voker.WithCodec(codec))
+//
+// Encoding is unaffected, so times are still encoded as RFC 3339 strings.
+func WithTimeDecoders(decoders ...TimeDecoder) Option {
+	return func(o *codecOptions) {
+		o.timeDecoders = append(o.timeDecoders, decoders...)
+	}
+}
+
+// WithStringIntegers decodes integer values from JSON strings as well as
+// numbers, as some AWS services send large integers, such as int64 IDs, to
+// avoid losing precision in JavaScript. Types that implement
+// json.Unmarshaler or encoding.TextUnmarshaler decode themselves.
+func WithStringIntegers() Option {
+	return func(o *codecOptions) {
+		o.stringInts = true
+	}
+}
+
+// NewJsoniter returns a codec like [Jsoniter] that decodes with the custom
+// rules set by opts. Each codec caches its own decoders, so the rules don't
+// affect [Jsoniter] or other codecs.
+//
+// Fields tagged voker:"base64" are decoded from base64 strings in the
+// standard or URL-safe alphabet, with or without padding, and encoded as
+// standard base64 strings. String and []byte fields hold the decoded bytes;
+// fields of other types hold the JSON document the bytes contain:
+//
+//	type KinesisOrder struct {
+//	    Data Order `json:"data" voker:"base64"`
+//	}
+func NewJsoniter(opts ...Option) voker.Codec {
+	var options codecOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	extension := &decoderExtension{options: options}
+	extension.api = jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+	}.Froze()
+	extension.api.RegisterExtension(extension)
+	return jsoniterCodec{api: extension.api}
+}
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+type decoderExtension struct {
+	jsoniter.DummyExtension
+	api     jsoniter.API
+	options codecOptions
+}
+
+func (e *decoderExtension) CreateDecoder(typ reflect2.Type) jsoniter.ValDecoder {
+	t := typ.Type1()
+	if t == timeType && len(e.options.timeDecoders) > 0 {
+		return timeDecoder{decoders: e.options.timeDecoders}
+	}
+	if !e.options.stringInts || reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return integerDecoder{typ: t}
+	}
+	return nil
+}
+
+type timeDecoder struct {
+	decoders []TimeDecoder
+}
+
+func (d timeDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	if iter.ReadNil() {
+		return
+	}
+	value := iter.SkipAndReturnBytes()
+	if iter.Error != nil {
+		return
+	}
+	t := (*time.Time)(ptr)
+	if err := t.UnmarshalJSON(value); err == nil {
+		return
+	}
+	for _, decode := range d.decoders {
+		if decoded, err := decode(value); err == nil {
+			*t = decoded
+			return
+		}
+	}
+	iter.ReportError("decode time.Time", fmt.Sprintf("cannot parse %s as a time", value))
+}
+
+type integerDecoder struct {
+	typ reflect.Type
+}
+
+func (d integerDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	if iter.ReadNil() {
+		return
+	}
+	var text string
+	switch iter.WhatIsNext() {
+	case jsoniter.StringValue:
+		text = iter.ReadString()
+	case jsoniter.NumberValue:
+		text = string(iter.ReadNumber())
+	default:
+		iter.Skip()
+		iter.ReportError("decode "+d.typ.String(), "expected a number or string")
+		return
+	}
+	if iter.Error != nil {
+		return
+	}
+
+	v := reflect.NewAt(d.typ, ptr).Elem()
+	var err error
+	if v.CanInt() {
+		var n int64
+		if n, err = strconv.ParseInt(text, 10, d.typ.Bits()); err == nil {
+			v.SetInt(n)
+		}
+	} else {
+		var n uint64
+		if n, err = strconv.ParseUint(text, 10, d.typ.Bits()); err == nil {
+			v.SetUint(n)
+		}
+	}
+	if err != nil {
+		iter.ReportError("decode "+d.typ.String(), errors.Unwrap(err).Error()+": "+strconv.Quote(text))
+	}
+}
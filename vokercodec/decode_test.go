@@ -0,0 +1,107 @@
+package vokercodec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEpochTime(t *testing.T) {
+	want := time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC)
+	for value, unit := range map[string]time.Duration{
+		`1714568400`:      time.Second,
+		`"1714568400"`:    time.Second,
+		`1714568400000`:   time.Millisecond,
+		`1714568400.0005`: time.Second,
+	} {
+		got, err := EpochTime(unit)([]byte(value))
+		require.NoError(t, err, value)
+		assert.WithinDuration(t, want, got, time.Millisecond, value)
+		assert.Equal(t, time.UTC, got.Location())
+	}
+
+	_, err := EpochTime(time.Second)([]byte(`"yesterday"`))
+	assert.EqualError(t, err, `"yesterday" is not an epoch time`)
+}
+
+func TestLayoutTime(t *testing.T) {
+	decode := LayoutTime("2006-01-02T15:04:05", "2006-01-02 15:04:05")
+
+	got, err := decode([]byte(`"2024-05-01 13:00:00"`))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC), got)
+
+	_, err = decode([]byte(`"May 1"`))
+	assert.EqualError(t, err, `"May 1" matches no time layout`)
+	_, err = decode([]byte(`1714568400`))
+	assert.EqualError(t, err, `1714568400 is not a string`)
+}
+
+type customTimes struct {
+	Standard time.Time  `json:"standard"`
+	Millis   time.Time  `json:"millis"`
+	Zoneless *time.Time `json:"zoneless"`
+	Missing  *time.Time `json:"missing"`
+}
+
+func TestNewJsoniter_TimeDecoders(t *testing.T) {
+	codec := NewJsoniter(WithTimeDecoders(EpochTime(time.Millisecond), LayoutTime("2006-01-02T15:04:05")))
+
+	var v customTimes
+	require.NoError(t, codec.Unmarshal([]byte(`{
+		"standard": "2024-05-01T13:00:00+02:00",
+		"millis": 1714568400000,
+		"zoneless": "2024-05-01T13:00:00",
+		"missing": null
+	}`), &v))
+	assert.True(t, v.Standard.Equal(time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC), v.Millis)
+	require.NotNil(t, v.Zoneless)
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC), *v.Zoneless)
+	assert.Nil(t, v.Missing)
+
+	err := codec.Unmarshal([]byte(`{"millis":"soon"}`), &v)
+	assert.ErrorContains(t, err, `cannot parse "soon" as a time`)
+
+	// Other codecs are unaffected.
+	assert.Error(t, Jsoniter.Unmarshal([]byte(`{"millis":1714568400000}`), &v))
+}
+
+type integerID int64
+
+type customIntegers struct {
+	ID       int64     `json:"id"`
+	Count    uint32    `json:"count"`
+	Named    integerID `json:"named"`
+	Plain    int       `json:"plain"`
+	Optional *int64    `json:"optional"`
+}
+
+func TestNewJsoniter_StringIntegers(t *testing.T) {
+	codec := NewJsoniter(WithStringIntegers())
+
+	var v customIntegers
+	require.NoError(t, codec.Unmarshal([]byte(`{
+		"id": "9007199254740993",
+		"count": "42",
+		"named": "7",
+		"plain": 3,
+		"optional": "-5"
+	}`), &v))
+	assert.Equal(t, int64(9007199254740993), v.ID)
+	assert.Equal(t, uint32(42), v.Count)
+	assert.Equal(t, integerID(7), v.Named)
+	assert.Equal(t, 3, v.Plain)
+	require.NotNil(t, v.Optional)
+	assert.Equal(t, int64(-5), *v.Optional)
+
+	assert.ErrorContains(t, codec.Unmarshal([]byte(`{"count":"-1"}`), &v), `invalid syntax: "-1"`)
+	assert.ErrorContains(t, codec.Unmarshal([]byte(`{"count":"4294967296"}`), &v), `value out of range: "4294967296"`)
+	assert.ErrorContains(t, codec.Unmarshal([]byte(`{"id":true}`), &v), "expected a number or string")
+
+	encoded, err := codec.Marshal(customIntegers{ID: 1})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"count":0,"named":0,"plain":0,"optional":null}`, string(encoded))
+}
@@ -0,0 +1,28 @@
+//go:build amd64
+
+package vokercodec
+
+import (
+	"github.com/bytedance/sonic"
+
+	"github.com/hotsock/voker"
+)
+
+// Sonic encodes and decodes with bytedance/sonic using its encoding/json
+// compatible configuration. Sonic JIT-compiles codecs per type, so the first
+// invocation for each input and output type is slower than steady state.
+//
+// On Go versions sonic does not yet support, it falls back to encoding/json.
+var Sonic voker.Codec = sonicCodec{api: sonic.ConfigStd}
+
+type sonicCodec struct {
+	api sonic.API
+}
+
+func (c sonicCodec) Marshal(v any) ([]byte, error) {
+	return c.api.Marshal(v)
+}
+
+func (c sonicCodec) Unmarshal(data []byte, v any) error {
+	return c.api.Unmarshal(data, v)
+}
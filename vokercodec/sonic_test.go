@@ -0,0 +1,7 @@
+//go:build amd64
+
+package vokercodec
+
+func init() {
+	testCodecs["sonic"] = Sonic
+}
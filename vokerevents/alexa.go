@@ -0,0 +1,353 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Alexa request types, for [AlexaRequestBody].Type.
+const (
+	AlexaLaunchRequest       = "LaunchRequest"
+	AlexaIntentRequest       = "IntentRequest"
+	AlexaSessionEndedRequest = "SessionEndedRequest"
+)
+
+// Alexa dialog states, for [AlexaRequestBody].DialogState.
+const (
+	AlexaDialogStarted    = "STARTED"
+	AlexaDialogInProgress = "IN_PROGRESS"
+	AlexaDialogCompleted  = "COMPLETED"
+)
+
+// alexaResponseVersion is the only response format version Alexa accepts.
+const alexaResponseVersion = "1.0"
+
+// alexaEntityResolutionMatch is the status code of a slot value resolved to
+// one of the slot type's values.
+const alexaEntityResolutionMatch = "ER_SUCCESS_MATCH"
+
+// AlexaRequest is the request the Alexa Skills Kit sends to a skill's
+// function. Respond with an [AlexaResponse] built with [NewAlexaResponse].
+type AlexaRequest struct {
+	Version string           `json:"version"`
+	Session AlexaSession     `json:"session"`
+	Context AlexaContext     `json:"context"`
+	Request AlexaRequestBody `json:"request"`
+}
+
+// AlexaSession is the conversation the request belongs to. Attributes are
+// the session attributes of the skill's previous response.
+type AlexaSession struct {
+	New         bool             `json:"new"`
+	SessionID   string           `json:"sessionId"`
+	Application AlexaApplication `json:"application"`
+	Attributes  map[string]any   `json:"attributes,omitempty"`
+	User        AlexaUser        `json:"user"`
+}
+
+// AlexaApplication identifies the skill.
+type AlexaApplication struct {
+	ApplicationID string `json:"applicationId"`
+}
+
+// AlexaUser is the Amazon account using the skill. AccessToken is set once
+// the user has linked an account.
+type AlexaUser struct {
+	UserID      string            `json:"userId"`
+	AccessToken string            `json:"accessToken,omitempty"`
+	Permissions *AlexaPermissions `json:"permissions,omitempty"`
+}
+
+// AlexaPermissions holds the consent token for permissions the user granted.
+type AlexaPermissions struct {
+	ConsentToken string `json:"consentToken"`
+}
+
+// AlexaContext is the state of the device and of Alexa services when the
+// request was sent.
+type AlexaContext struct {
+	System AlexaSystem `json:"System"`
+}
+
+// AlexaSystem describes the device and how to call the Alexa APIs.
+type AlexaSystem struct {
+	Application    AlexaApplication `json:"application"`
+	User           AlexaUser        `json:"user"`
+	Device         AlexaDevice      `json:"device"`
+	APIEndpoint    string           `json:"apiEndpoint"`
+	APIAccessToken string           `json:"apiAccessToken,omitempty"`
+}
+
+// AlexaDevice is the device the user spoke to. SupportedInterfaces maps
+// each interface the device supports, such as "AudioPlayer", to its
+// settings.
+type AlexaDevice struct {
+	DeviceID            string                     `json:"deviceId"`
+	SupportedInterfaces map[string]json.RawMessage `json:"supportedInterfaces,omitempty"`
+}
+
+// AlexaRequestBody is what the user asked for.
+type AlexaRequestBody struct {
+	// Type is one of the Alexa request type constants.
+	Type      string    `json:"type"`
+	RequestID string    `json:"requestId"`
+	Timestamp time.Time `json:"timestamp"`
+	Locale    string    `json:"locale"`
+	// Intent and DialogState are set for IntentRequest.
+	Intent      AlexaIntent `json:"intent"`
+	DialogState string      `json:"dialogState,omitempty"`
+	// Reason and Error are set for SessionEndedRequest. Reason is
+	// "USER_INITIATED", "ERROR", or "EXCEEDED_MAX_REPROMPTS".
+	Reason string      `json:"reason,omitempty"`
+	Error  *AlexaError `json:"error,omitempty"`
+}
+
+// AlexaError is why Alexa ended a session with an error.
+type AlexaError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// AlexaIntent is the intent Alexa matched the user's utterance to.
+type AlexaIntent struct {
+	Name               string               `json:"name"`
+	ConfirmationStatus string               `json:"confirmationStatus,omitempty"`
+	Slots              map[string]AlexaSlot `json:"slots,omitempty"`
+}
+
+// SlotValue returns the resolved value of the named slot, or the value as
+// spoken when it didn't resolve, or "" when the slot wasn't filled.
+func (i AlexaIntent) SlotValue(name string) string {
+	slot := i.Slots[name]
+	if value, _, ok := slot.ResolvedValue(); ok {
+		return value
+	}
+	return slot.Value
+}
+
+// AlexaSlot is a slot of an intent. Value is what the user said;
+// Resolutions maps it to the slot type's values.
+type AlexaSlot struct {
+	Name               string            `json:"name"`
+	Value              string            `json:"value,omitempty"`
+	ConfirmationStatus string            `json:"confirmationStatus,omitempty"`
+	Resolutions        *AlexaResolutions `json:"resolutions,omitempty"`
+}
+
+// ResolvedValue returns the name and ID of the first slot type value the
+// spoken value resolved to.
+func (s AlexaSlot) ResolvedValue() (name, id string, ok bool) {
+	if s.Resolutions == nil {
+		return "", "", false
+	}
+	for _, authority := range s.Resolutions.ResolutionsPerAuthority {
+		if authority.Status.Code == alexaEntityResolutionMatch && len(authority.Values) > 0 {
+			value := authority.Values[0].Value
+			return value.Name, value.ID, true
+		}
+	}
+	return "", "", false
+}
+
+// AlexaResolutions holds the results of entity resolution for a slot.
+type AlexaResolutions struct {
+	ResolutionsPerAuthority []AlexaResolution `json:"resolutionsPerAuthority"`
+}
+
+// AlexaResolution is the result of resolving a slot value against one
+// source of values, such as the slot type.
+type AlexaResolution struct {
+	Authority string                 `json:"authority"`
+	Status    AlexaResolutionStatus  `json:"status"`
+	Values    []AlexaResolutionValue `json:"values,omitempty"`
+}
+
+// AlexaResolutionStatus is the outcome of a resolution, such as
+// "ER_SUCCESS_MATCH" or "ER_SUCCESS_NO_MATCH".
+type AlexaResolutionStatus struct {
+	Code string `json:"code"`
+}
+
+// AlexaResolutionValue is a slot type value a spoken value resolved to.
+type AlexaResolutionValue struct {
+	Value struct {
+		Name string `json:"name"`
+		ID   string `json:"id"`
+	} `json:"value"`
+}
+
+// AlexaResponse is a skill's response to an [AlexaRequest].
+type AlexaResponse struct {
+	Version           string            `json:"version"`
+	SessionAttributes map[string]any    `json:"sessionAttributes,omitempty"`
+	Response          AlexaResponseBody `json:"response"`
+}
+
+// AlexaResponseBody is what Alexa says and shows. ShouldEndSession nil keeps
+// the session open on devices with a screen and closes it otherwise.
+type AlexaResponseBody struct {
+	OutputSpeech     *AlexaOutputSpeech `json:"outputSpeech,omitempty"`
+	Card             *AlexaCard         `json:"card,omitempty"`
+	Reprompt         *AlexaReprompt     `json:"reprompt,omitempty"`
+	ShouldEndSession *bool              `json:"shouldEndSession,omitempty"`
+	// Directives are values marshalled as directives, such as
+	// Dialog.Delegate or AudioPlayer.Play.
+	Directives []any `json:"directives,omitempty"`
+}
+
+// AlexaOutputSpeech is speech as plain text or SSML.
+type AlexaOutputSpeech struct {
+	// Type is "PlainText" or "SSML".
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+	SSML string `json:"ssml,omitempty"`
+}
+
+// AlexaReprompt is what Alexa says when the user doesn't answer.
+type AlexaReprompt struct {
+	OutputSpeech AlexaOutputSpeech `json:"outputSpeech"`
+}
+
+// AlexaCard is a card shown in the Alexa app and on devices with a screen.
+type AlexaCard struct {
+	// Type is "Simple", "Standard", "LinkAccount", or
+	// "AskForPermissionsConsent".
+	Type string `json:"type"`
+	// Title and Content are set for Simple cards; Title, Text, and Image for
+	// Standard cards.
+	Title   string          `json:"title,omitempty"`
+	Content string          `json:"content,omitempty"`
+	Text    string          `json:"text,omitempty"`
+	Image   *AlexaCardImage `json:"image,omitempty"`
+	// Permissions are the permissions an AskForPermissionsConsent card
+	// requests, such as "read::alexa:device:all:address".
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// AlexaCardImage is the image of a Standard card, as HTTPS URLs.
+type AlexaCardImage struct {
+	SmallImageURL string `json:"smallImageUrl,omitempty"`
+	LargeImageURL string `json:"largeImageUrl,omitempty"`
+}
+
+// AlexaResponseBuilder builds an [AlexaResponse]. Create one with
+// [NewAlexaResponse]:
+//
+//	return vokerevents.NewAlexaResponse().
+//	    Speak("Which city?").
+//	    Reprompt("Which city should I check the weather for?").
+//	    WithSessionAttribute("step", "city").
+//	    Build(), nil
+type AlexaResponseBuilder struct {
+	response AlexaResponse
+}
+
+// NewAlexaResponse returns a builder for an empty response.
+func NewAlexaResponse() *AlexaResponseBuilder {
+	return &AlexaResponseBuilder{response: AlexaResponse{Version: alexaResponseVersion}}
+}
+
+// Speak sets the speech to plain text.
+func (b *AlexaResponseBuilder) Speak(text string) *AlexaResponseBuilder {
+	b.response.Response.OutputSpeech = alexaPlainText(text)
+	return b
+}
+
+// SpeakSSML sets the speech to SSML, wrapped in a speak element if it
+// isn't already.
+func (b *AlexaResponseBuilder) SpeakSSML(ssml string) *AlexaResponseBuilder {
+	b.response.Response.OutputSpeech = alexaSSML(ssml)
+	return b
+}
+
+// Reprompt sets the plain text speech for when the user doesn't answer, and
+// keeps the session open for the answer.
+func (b *AlexaResponseBuilder) Reprompt(text string) *AlexaResponseBuilder {
+	b.response.Response.Reprompt = &AlexaReprompt{OutputSpeech: *alexaPlainText(text)}
+	return b.EndSession(false)
+}
+
+// RepromptSSML is Reprompt with SSML speech.
+func (b *AlexaResponseBuilder) RepromptSSML(ssml string) *AlexaResponseBuilder {
+	b.response.Response.Reprompt = &AlexaReprompt{OutputSpeech: *alexaSSML(ssml)}
+	return b.EndSession(false)
+}
+
+// SimpleCard sets a card with a title and plain text content.
+func (b *AlexaResponseBuilder) SimpleCard(title, content string) *AlexaResponseBuilder {
+	b.response.Response.Card = &AlexaCard{Type: "Simple", Title: title, Content: content}
+	return b
+}
+
+// StandardCard sets a card with a title, text, and an image given as small
+// and large HTTPS URLs.
+func (b *AlexaResponseBuilder) StandardCard(title, text, smallImageURL, largeImageURL string) *AlexaResponseBuilder {
+	card := &AlexaCard{Type: "Standard", Title: title, Text: text}
+	if smallImageURL != "" || largeImageURL != "" {
+		card.Image = &AlexaCardImage{SmallImageURL: smallImageURL, LargeImageURL: largeImageURL}
+	}
+	b.response.Response.Card = card
+	return b
+}
+
+// LinkAccountCard sets a card asking the user to link their account.
+func (b *AlexaResponseBuilder) LinkAccountCard() *AlexaResponseBuilder {
+	b.response.Response.Card = &AlexaCard{Type: "LinkAccount"}
+	return b
+}
+
+// AskForPermissionsCard sets a card asking the user to grant permissions.
+func (b *AlexaResponseBuilder) AskForPermissionsCard(permissions ...string) *AlexaResponseBuilder {
+	b.response.Response.Card = &AlexaCard{Type: "AskForPermissionsConsent", Permissions: permissions}
+	return b
+}
+
+// WithSessionAttributes copies attrs, typically the request's
+// Session.Attributes, into the session attributes Alexa returns with the
+// next request.
+func (b *AlexaResponseBuilder) WithSessionAttributes(attrs map[string]any) *AlexaResponseBuilder {
+	for key, value := range attrs {
+		b.WithSessionAttribute(key, value)
+	}
+	return b
+}
+
+// WithSessionAttribute sets a session attribute Alexa returns with the next
+// request.
+func (b *AlexaResponseBuilder) WithSessionAttribute(key string, value any) *AlexaResponseBuilder {
+	if b.response.SessionAttributes == nil {
+		b.response.SessionAttributes = make(map[string]any)
+	}
+	b.response.SessionAttributes[key] = value
+	return b
+}
+
+// WithDirective adds a directive, such as
+// map[string]any{"type": "Dialog.Delegate"}.
+func (b *AlexaResponseBuilder) WithDirective(directive any) *AlexaResponseBuilder {
+	b.response.Response.Directives = append(b.response.Response.Directives, directive)
+	return b
+}
+
+// EndSession sets whether the session ends after the response.
+func (b *AlexaResponseBuilder) EndSession(end bool) *AlexaResponseBuilder {
+	b.response.Response.ShouldEndSession = &end
+	return b
+}
+
+// Build returns the response.
+func (b *AlexaResponseBuilder) Build() AlexaResponse {
+	return b.response
+}
+
+func alexaPlainText(text string) *AlexaOutputSpeech {
+	return &AlexaOutputSpeech{Type: "PlainText", Text: text}
+}
+
+func alexaSSML(ssml string) *AlexaOutputSpeech {
+	if !strings.HasPrefix(strings.TrimSpace(ssml), "<speak>") {
+		ssml = "<speak>" + ssml + "</speak>"
+	}
+	return &AlexaOutputSpeech{Type: "SSML", SSML: ssml}
+}
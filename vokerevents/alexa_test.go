@@ -0,0 +1,112 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlexaRequest_Fixture(t *testing.T) {
+	var event AlexaRequest
+	readEventFixture(t, "alexa-intent-event.json", &event)
+
+	assert.Equal(t, AlexaIntentRequest, event.Request.Type)
+	assert.Equal(t, AlexaDialogInProgress, event.Request.DialogState)
+	assert.Equal(t, time.Date(2024, 6, 1, 16, 0, 0, 0, time.UTC), event.Request.Timestamp)
+	assert.Equal(t, map[string]any{"step": "city"}, event.Session.Attributes)
+	assert.Equal(t, "https://api.amazonalexa.com", event.Context.System.APIEndpoint)
+	assert.Contains(t, event.Context.System.Device.SupportedInterfaces, "AudioPlayer")
+
+	intent := event.Request.Intent
+	assert.Equal(t, "GetWeatherIntent", intent.Name)
+	assert.Equal(t, "New York", intent.SlotValue("City"))
+	assert.Equal(t, "tomorrow", intent.SlotValue("Day"))
+	assert.Empty(t, intent.SlotValue("Unit"))
+	assert.Empty(t, intent.SlotValue("Missing"))
+
+	name, id, ok := intent.Slots["City"].ResolvedValue()
+	assert.True(t, ok)
+	assert.Equal(t, "New York", name)
+	assert.Equal(t, "NYC", id)
+}
+
+func TestAlexaSlot_ResolvedValueNoMatch(t *testing.T) {
+	slot := AlexaSlot{Value: "gotham", Resolutions: &AlexaResolutions{
+		ResolutionsPerAuthority: []AlexaResolution{{Status: AlexaResolutionStatus{Code: "ER_SUCCESS_NO_MATCH"}}},
+	}}
+	_, _, ok := slot.ResolvedValue()
+	assert.False(t, ok)
+	assert.Equal(t, "gotham", AlexaIntent{Slots: map[string]AlexaSlot{"City": slot}}.SlotValue("City"))
+}
+
+func TestAlexaRequest_SessionEnded(t *testing.T) {
+	var event AlexaRequest
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"version": "1.0",
+		"request": {
+			"type": "SessionEndedRequest",
+			"requestId": "amzn1.echo-api.request.1",
+			"timestamp": "2024-06-01T16:00:00Z",
+			"reason": "ERROR",
+			"error": {"type": "INVALID_RESPONSE", "message": "speech is too long"}
+		}
+	}`), &event))
+
+	assert.Equal(t, AlexaSessionEndedRequest, event.Request.Type)
+	assert.Equal(t, "ERROR", event.Request.Reason)
+	assert.Equal(t, &AlexaError{Type: "INVALID_RESPONSE", Message: "speech is too long"}, event.Request.Error)
+}
+
+func TestAlexaResponseBuilder(t *testing.T) {
+	response := NewAlexaResponse().
+		Speak("Which city?").
+		Reprompt("Which city should I check the weather for?").
+		SimpleCard("Weather", "Asking for a city").
+		WithSessionAttributes(map[string]any{"step": "start"}).
+		WithSessionAttribute("step", "city").
+		Build()
+
+	b, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": "1.0",
+		"sessionAttributes": {"step": "city"},
+		"response": {
+			"outputSpeech": {"type": "PlainText", "text": "Which city?"},
+			"reprompt": {"outputSpeech": {"type": "PlainText", "text": "Which city should I check the weather for?"}},
+			"card": {"type": "Simple", "title": "Weather", "content": "Asking for a city"},
+			"shouldEndSession": false
+		}
+	}`, string(b))
+}
+
+func TestAlexaResponseBuilder_SSMLAndCards(t *testing.T) {
+	response := NewAlexaResponse().
+		SpeakSSML(`It's <say-as interpret-as="cardinal">72</say-as> degrees.`).
+		RepromptSSML("<speak>Anything else?</speak>").
+		StandardCard("Weather", "72°F and sunny", "https://example.com/small.png", "https://example.com/large.png").
+		WithDirective(map[string]any{"type": "Dialog.Delegate"}).
+		EndSession(true).
+		Build()
+
+	body := response.Response
+	assert.Equal(t, &AlexaOutputSpeech{Type: "SSML", SSML: `<speak>It's <say-as interpret-as="cardinal">72</say-as> degrees.</speak>`}, body.OutputSpeech)
+	assert.Equal(t, "<speak>Anything else?</speak>", body.Reprompt.OutputSpeech.SSML)
+	assert.Equal(t, &AlexaCardImage{SmallImageURL: "https://example.com/small.png", LargeImageURL: "https://example.com/large.png"}, body.Card.Image)
+	assert.Equal(t, []any{map[string]any{"type": "Dialog.Delegate"}}, body.Directives)
+	require.NotNil(t, body.ShouldEndSession)
+	assert.True(t, *body.ShouldEndSession)
+
+	assert.Equal(t, &AlexaCard{Type: "LinkAccount"}, NewAlexaResponse().LinkAccountCard().Build().Response.Card)
+	assert.Equal(t, []string{"read::alexa:device:all:address"}, NewAlexaResponse().AskForPermissionsCard("read::alexa:device:all:address").Build().Response.Card.Permissions)
+	assert.Nil(t, NewAlexaResponse().StandardCard("Weather", "Sunny", "", "").Build().Response.Card.Image)
+}
+
+func TestAlexaResponseBuilder_Empty(t *testing.T) {
+	b, err := json.Marshal(NewAlexaResponse().Build())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":"1.0","response":{}}`, string(b))
+}
@@ -0,0 +1,353 @@
+package vokerevents
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// API Gateway Lambda authorizer request types.
+const (
+	AuthorizerTypeToken   = "TOKEN"
+	AuthorizerTypeRequest = "REQUEST"
+)
+
+// APIGatewayCustomAuthorizerRequest is the event delivered to a REST API
+// TOKEN authorizer.
+type APIGatewayCustomAuthorizerRequest struct {
+	Type               string `json:"type"`
+	AuthorizationToken string `json:"authorizationToken"`
+	MethodArn          string `json:"methodArn"`
+}
+
+// APIGatewayCustomAuthorizerRequestTypeRequest is the event delivered to a
+// REST API REQUEST authorizer.
+type APIGatewayCustomAuthorizerRequestTypeRequest struct {
+	Type                            string                                              `json:"type"`
+	MethodArn                       string                                              `json:"methodArn"`
+	Resource                        string                                              `json:"resource"`
+	Path                            string                                              `json:"path"`
+	HTTPMethod                      string                                              `json:"httpMethod"`
+	Headers                         map[string]string                                   `json:"headers"`
+	MultiValueHeaders               map[string][]string                                 `json:"multiValueHeaders"`
+	QueryStringParameters           map[string]string                                   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string                                 `json:"multiValueQueryStringParameters"`
+	PathParameters                  map[string]string                                   `json:"pathParameters"`
+	StageVariables                  map[string]string                                   `json:"stageVariables"`
+	RequestContext                  APIGatewayCustomAuthorizerRequestTypeRequestContext `json:"requestContext"`
+}
+
+// APIGatewayCustomAuthorizerRequestTypeRequestContext is the request context
+// of a REST API REQUEST authorizer event.
+type APIGatewayCustomAuthorizerRequestTypeRequestContext struct {
+	Path         string                                               `json:"path"`
+	AccountID    string                                               `json:"accountId"`
+	ResourceID   string                                               `json:"resourceId"`
+	Stage        string                                               `json:"stage"`
+	RequestID    string                                               `json:"requestId"`
+	Identity     APIGatewayCustomAuthorizerRequestTypeRequestIdentity `json:"identity"`
+	ResourcePath string                                               `json:"resourcePath"`
+	HTTPMethod   string                                               `json:"httpMethod"`
+	APIID        string                                               `json:"apiId"`
+}
+
+// APIGatewayCustomAuthorizerRequestTypeRequestIdentity is the caller
+// identity of a REST API REQUEST authorizer event.
+type APIGatewayCustomAuthorizerRequestTypeRequestIdentity struct {
+	APIKey    string `json:"apiKey"`
+	SourceIP  string `json:"sourceIp"`
+	UserAgent string `json:"userAgent"`
+}
+
+// APIGatewayCustomAuthorizerResponse is the IAM policy response returned by
+// REST API authorizers and by HTTP API authorizers that use payload format
+// 1.0 or disable simple responses. Build it with [AuthPolicyBuilder].
+type APIGatewayCustomAuthorizerResponse struct {
+	PrincipalID        string                           `json:"principalId"`
+	PolicyDocument     APIGatewayCustomAuthorizerPolicy `json:"policyDocument"`
+	Context            map[string]any                   `json:"context,omitempty"`
+	UsageIdentifierKey string                           `json:"usageIdentifierKey,omitempty"`
+}
+
+// APIGatewayCustomAuthorizerPolicy is the IAM policy document of an
+// authorizer response.
+type APIGatewayCustomAuthorizerPolicy struct {
+	Version   string               `json:"Version"`
+	Statement []IAMPolicyStatement `json:"Statement"`
+}
+
+// IAMPolicyStatement is a single statement of an IAM policy document.
+type IAMPolicyStatement struct {
+	Action    []string                  `json:"Action"`
+	Effect    string                    `json:"Effect"`
+	Resource  []string                  `json:"Resource"`
+	Condition map[string]map[string]any `json:"Condition,omitempty"`
+}
+
+// APIGatewayV2CustomAuthorizerV2Request is the event delivered to an HTTP
+// API Lambda authorizer using payload format 2.0.
+type APIGatewayV2CustomAuthorizerV2Request struct {
+	Version               string                                     `json:"version"`
+	Type                  string                                     `json:"type"`
+	RouteArn              string                                     `json:"routeArn"`
+	IdentitySource        []string                                   `json:"identitySource"`
+	RouteKey              string                                     `json:"routeKey"`
+	RawPath               string                                     `json:"rawPath"`
+	RawQueryString        string                                     `json:"rawQueryString"`
+	Cookies               []string                                   `json:"cookies"`
+	Headers               map[string]string                          `json:"headers"`
+	QueryStringParameters map[string]string                          `json:"queryStringParameters"`
+	RequestContext        APIGatewayV2CustomAuthorizerRequestContext `json:"requestContext"`
+	PathParameters        map[string]string                          `json:"pathParameters"`
+	StageVariables        map[string]string                          `json:"stageVariables"`
+}
+
+// APIGatewayV2CustomAuthorizerRequestContext is the request context of an
+// HTTP API authorizer event.
+type APIGatewayV2CustomAuthorizerRequestContext struct {
+	AccountID    string                                         `json:"accountId"`
+	APIID        string                                         `json:"apiId"`
+	DomainName   string                                         `json:"domainName"`
+	DomainPrefix string                                         `json:"domainPrefix"`
+	HTTP         APIGatewayV2CustomAuthorizerRequestContextHTTP `json:"http"`
+	RequestID    string                                         `json:"requestId"`
+	RouteKey     string                                         `json:"routeKey"`
+	Stage        string                                         `json:"stage"`
+	Time         string                                         `json:"time"`
+	TimeEpoch    int64                                          `json:"timeEpoch"`
+}
+
+// APIGatewayV2CustomAuthorizerRequestContextHTTP contains the HTTP details of
+// an HTTP API authorizer request.
+type APIGatewayV2CustomAuthorizerRequestContextHTTP struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Protocol  string `json:"protocol"`
+	SourceIP  string `json:"sourceIp"`
+	UserAgent string `json:"userAgent"`
+}
+
+// APIGatewayV2CustomAuthorizerSimpleResponse is the response of an HTTP API
+// authorizer with simple responses enabled.
+type APIGatewayV2CustomAuthorizerSimpleResponse struct {
+	IsAuthorized bool           `json:"isAuthorized"`
+	Context      map[string]any `json:"context,omitempty"`
+}
+
+const (
+	authPolicyVersion = "2012-10-17"
+	authPolicyAction  = "execute-api:Invoke"
+
+	// AuthPolicyAllMethods matches every HTTP verb in an [AuthPolicyBuilder]
+	// statement.
+	AuthPolicyAllMethods = "*"
+
+	// AuthPolicyAllResources matches every resource path in an
+	// [AuthPolicyBuilder] statement.
+	AuthPolicyAllResources = "*"
+)
+
+var authPolicyResourcePattern = regexp.MustCompile(`^[/.a-zA-Z0-9\-_*{}+]*$`)
+
+var authPolicyMethods = map[string]bool{
+	AuthPolicyAllMethods: true,
+	"GET":                true,
+	"POST":               true,
+	"PUT":                true,
+	"PATCH":              true,
+	"HEAD":               true,
+	"DELETE":             true,
+	"OPTIONS":            true,
+}
+
+// AuthPolicyBuilder builds the IAM policy response for an API Gateway Lambda
+// authorizer. It derives execute-api resource ARNs from the method or route
+// ARN of the request, so statements only need an HTTP verb and a resource
+// path:
+//
+//	builder, err := vokerevents.NewAuthPolicyBuilder("user-123", event.MethodArn)
+//	if err != nil {
+//	    return vokerevents.APIGatewayCustomAuthorizerResponse{}, err
+//	}
+//	return builder.
+//	    Allow("GET", "/pets/*").
+//	    Deny(vokerevents.AuthPolicyAllMethods, "/admin/*").
+//	    WithContext("tenant", "acme").
+//	    Build()
+//
+// Invalid verbs, resource paths, and context values are reported by Build.
+type AuthPolicyBuilder struct {
+	principalID        string
+	arnPrefix          string
+	stage              string
+	allow              []authPolicyRule
+	deny               []authPolicyRule
+	context            map[string]any
+	usageIdentifierKey string
+	errs               []error
+}
+
+type authPolicyRule struct {
+	resource  string
+	condition map[string]map[string]any
+}
+
+// NewAuthPolicyBuilder returns a builder for principalID whose statements
+// target the API and stage of methodArn. methodArn is the MethodArn of a
+// REST API authorizer event or the RouteArn of an HTTP API authorizer event.
+func NewAuthPolicyBuilder(principalID, methodArn string) (*AuthPolicyBuilder, error) {
+	// arn:<partition>:execute-api:<region>:<account>:<apiId>/<stage>/<verb>/<resource>
+	arnParts := strings.SplitN(methodArn, ":", 6)
+	if len(arnParts) != 6 || arnParts[0] != "arn" || arnParts[2] != "execute-api" {
+		return nil, fmt.Errorf("invalid execute-api method ARN %q", methodArn)
+	}
+	pathParts := strings.SplitN(arnParts[5], "/", 3)
+	if len(pathParts) < 2 || pathParts[0] == "" || pathParts[1] == "" {
+		return nil, fmt.Errorf("invalid execute-api method ARN %q", methodArn)
+	}
+
+	return &AuthPolicyBuilder{
+		principalID: principalID,
+		arnPrefix:   strings.Join(arnParts[:5], ":") + ":" + pathParts[0],
+		stage:       pathParts[1],
+	}, nil
+}
+
+// Allow adds a statement allowing verb on resource. verb is an HTTP method or
+// [AuthPolicyAllMethods]; resource is a path such as "/pets/*" or
+// [AuthPolicyAllResources].
+func (b *AuthPolicyBuilder) Allow(verb, resource string) *AuthPolicyBuilder {
+	return b.addRule(&b.allow, verb, resource, nil)
+}
+
+// Deny adds a statement denying verb on resource. An explicit deny overrides
+// any allow that matches the same request.
+func (b *AuthPolicyBuilder) Deny(verb, resource string) *AuthPolicyBuilder {
+	return b.addRule(&b.deny, verb, resource, nil)
+}
+
+// AllowWithCondition adds an allow statement that only applies when the IAM
+// condition block matches, for example
+// {"IpAddress": {"aws:SourceIp": ["203.0.113.0/24"]}}.
+func (b *AuthPolicyBuilder) AllowWithCondition(verb, resource string, condition map[string]map[string]any) *AuthPolicyBuilder {
+	return b.addRule(&b.allow, verb, resource, condition)
+}
+
+// DenyWithCondition adds a deny statement that only applies when the IAM
+// condition block matches.
+func (b *AuthPolicyBuilder) DenyWithCondition(verb, resource string, condition map[string]map[string]any) *AuthPolicyBuilder {
+	return b.addRule(&b.deny, verb, resource, condition)
+}
+
+// AllowAll allows every method on every resource of the stage.
+func (b *AuthPolicyBuilder) AllowAll() *AuthPolicyBuilder {
+	return b.Allow(AuthPolicyAllMethods, AuthPolicyAllResources)
+}
+
+// DenyAll denies every method on every resource of the stage.
+func (b *AuthPolicyBuilder) DenyAll() *AuthPolicyBuilder {
+	return b.Deny(AuthPolicyAllMethods, AuthPolicyAllResources)
+}
+
+// WithContext adds a key to the authorizer context that API Gateway passes to
+// the integration. API Gateway only accepts string, number, and boolean
+// values; Build rejects anything else.
+func (b *AuthPolicyBuilder) WithContext(key string, value any) *AuthPolicyBuilder {
+	switch value.(type) {
+	case string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+	default:
+		b.errs = append(b.errs, fmt.Errorf("context value for %q must be a string, number, or boolean, got %T", key, value))
+		return b
+	}
+	if b.context == nil {
+		b.context = make(map[string]any)
+	}
+	b.context[key] = value
+	return b
+}
+
+// WithUsageIdentifierKey sets the API key used for usage plans when the API's
+// API key source is AUTHORIZER.
+func (b *AuthPolicyBuilder) WithUsageIdentifierKey(key string) *AuthPolicyBuilder {
+	b.usageIdentifierKey = key
+	return b
+}
+
+// Build returns the authorizer response. It fails if any statement or context
+// value was invalid, or if the policy has no statements: API Gateway treats
+// an empty policy as an error rather than a deny.
+func (b *AuthPolicyBuilder) Build() (APIGatewayCustomAuthorizerResponse, error) {
+	if len(b.errs) > 0 {
+		return APIGatewayCustomAuthorizerResponse{}, errors.Join(b.errs...)
+	}
+	if len(b.allow) == 0 && len(b.deny) == 0 {
+		return APIGatewayCustomAuthorizerResponse{}, errors.New("authorizer policy has no statements")
+	}
+
+	var statements []IAMPolicyStatement
+	statements = appendAuthPolicyStatements(statements, "Allow", b.allow)
+	statements = appendAuthPolicyStatements(statements, "Deny", b.deny)
+
+	return APIGatewayCustomAuthorizerResponse{
+		PrincipalID: b.principalID,
+		PolicyDocument: APIGatewayCustomAuthorizerPolicy{
+			Version:   authPolicyVersion,
+			Statement: statements,
+		},
+		Context:            b.context,
+		UsageIdentifierKey: b.usageIdentifierKey,
+	}, nil
+}
+
+func (b *AuthPolicyBuilder) addRule(rules *[]authPolicyRule, verb, resource string, condition map[string]map[string]any) *AuthPolicyBuilder {
+	verb = strings.ToUpper(verb)
+	if !authPolicyMethods[verb] {
+		b.errs = append(b.errs, fmt.Errorf("invalid HTTP verb %q", verb))
+		return b
+	}
+	if !authPolicyResourcePattern.MatchString(resource) {
+		b.errs = append(b.errs, fmt.Errorf("invalid resource path %q", resource))
+		return b
+	}
+
+	resource = strings.TrimPrefix(resource, "/")
+	*rules = append(*rules, authPolicyRule{
+		resource:  b.arnPrefix + "/" + b.stage + "/" + verb + "/" + resource,
+		condition: condition,
+	})
+	return b
+}
+
+// appendAuthPolicyStatements groups unconditional rules into one statement
+// and gives each conditional rule its own, since a condition applies to every
+// resource of its statement.
+func appendAuthPolicyStatements(statements []IAMPolicyStatement, effect string, rules []authPolicyRule) []IAMPolicyStatement {
+	var unconditional []string
+	for _, rule := range rules {
+		if rule.condition == nil {
+			unconditional = append(unconditional, rule.resource)
+		}
+	}
+	if len(unconditional) > 0 {
+		statements = append(statements, IAMPolicyStatement{
+			Action:   []string{authPolicyAction},
+			Effect:   effect,
+			Resource: unconditional,
+		})
+	}
+	for _, rule := range rules {
+		if rule.condition != nil {
+			statements = append(statements, IAMPolicyStatement{
+				Action:    []string{authPolicyAction},
+				Effect:    effect,
+				Resource:  []string{rule.resource},
+				Condition: rule.condition,
+			})
+		}
+	}
+	return statements
+}
@@ -0,0 +1,147 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIGatewayCustomAuthorizerRequest_JSON(t *testing.T) {
+	var event APIGatewayCustomAuthorizerRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"TOKEN","authorizationToken":"allow","methodArn":"arn:aws:execute-api:us-east-1:123456789012:abcdef123/test/GET/pets"}`), &event))
+
+	assert.Equal(t, AuthorizerTypeToken, event.Type)
+	assert.Equal(t, "allow", event.AuthorizationToken)
+	assert.Equal(t, "arn:aws:execute-api:us-east-1:123456789012:abcdef123/test/GET/pets", event.MethodArn)
+}
+
+func TestAPIGatewayCustomAuthorizerRequestTypeRequest_Fixture(t *testing.T) {
+	var event APIGatewayCustomAuthorizerRequestTypeRequest
+	readEventFixture(t, "authorizer-request-event.json", &event)
+
+	assert.Equal(t, AuthorizerTypeRequest, event.Type)
+	assert.Equal(t, "GET", event.HTTPMethod)
+	assert.Equal(t, "headerValue1", event.Headers["HeaderAuth1"])
+	assert.Equal(t, []string{"queryValue1"}, event.MultiValueQueryStringParameters["QueryString1"])
+	assert.Equal(t, "stageValue1", event.StageVariables["StageVar1"])
+	assert.Equal(t, "abcdef123", event.RequestContext.APIID)
+	assert.Equal(t, "203.0.113.1", event.RequestContext.Identity.SourceIP)
+}
+
+func TestAPIGatewayV2CustomAuthorizerV2Request_Fixture(t *testing.T) {
+	var event APIGatewayV2CustomAuthorizerV2Request
+	readEventFixture(t, "authorizer-v2-request-event.json", &event)
+
+	assert.Equal(t, "2.0", event.Version)
+	assert.Equal(t, []string{"user1", "123"}, event.IdentitySource)
+	assert.Equal(t, "GET /request", event.RouteKey)
+	assert.Equal(t, "GET", event.RequestContext.HTTP.Method)
+	assert.Equal(t, int64(1583348638390), event.RequestContext.TimeEpoch)
+}
+
+func TestAPIGatewayV2CustomAuthorizerSimpleResponse_JSON(t *testing.T) {
+	b, err := json.Marshal(APIGatewayV2CustomAuthorizerSimpleResponse{
+		IsAuthorized: true,
+		Context:      map[string]any{"user": "alice"},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"isAuthorized":true,"context":{"user":"alice"}}`, string(b))
+
+	b, err = json.Marshal(APIGatewayV2CustomAuthorizerSimpleResponse{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"isAuthorized":false}`, string(b))
+}
+
+func TestAuthPolicyBuilder(t *testing.T) {
+	builder, err := NewAuthPolicyBuilder("user-123", "arn:aws:execute-api:us-east-1:123456789012:abcdef123/prod/GET/pets/cat")
+	require.NoError(t, err)
+
+	response, err := builder.
+		Allow("get", "/pets/*").
+		Allow(AuthPolicyAllMethods, "/orders").
+		Deny(AuthPolicyAllMethods, "/admin/*").
+		AllowWithCondition("POST", "/pets", map[string]map[string]any{
+			"IpAddress": {"aws:SourceIp": []string{"203.0.113.0/24"}},
+		}).
+		WithContext("tenant", "acme").
+		WithContext("admin", false).
+		WithContext("quota", 10).
+		WithUsageIdentifierKey("usage-key").
+		Build()
+	require.NoError(t, err)
+
+	b, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"principalId": "user-123",
+		"policyDocument": {
+			"Version": "2012-10-17",
+			"Statement": [
+				{
+					"Action": ["execute-api:Invoke"],
+					"Effect": "Allow",
+					"Resource": [
+						"arn:aws:execute-api:us-east-1:123456789012:abcdef123/prod/GET/pets/*",
+						"arn:aws:execute-api:us-east-1:123456789012:abcdef123/prod/*/orders"
+					]
+				},
+				{
+					"Action": ["execute-api:Invoke"],
+					"Effect": "Allow",
+					"Resource": ["arn:aws:execute-api:us-east-1:123456789012:abcdef123/prod/POST/pets"],
+					"Condition": {"IpAddress": {"aws:SourceIp": ["203.0.113.0/24"]}}
+				},
+				{
+					"Action": ["execute-api:Invoke"],
+					"Effect": "Deny",
+					"Resource": ["arn:aws:execute-api:us-east-1:123456789012:abcdef123/prod/*/admin/*"]
+				}
+			]
+		},
+		"context": {"tenant": "acme", "admin": false, "quota": 10},
+		"usageIdentifierKey": "usage-key"
+	}`, string(b))
+}
+
+func TestAuthPolicyBuilder_AllAndDenyAll(t *testing.T) {
+	builder, err := NewAuthPolicyBuilder("user", "arn:aws-cn:execute-api:cn-north-1:123456789012:abcdef123/$default/GET/request")
+	require.NoError(t, err)
+
+	response, err := builder.AllowAll().DenyAll().Build()
+	require.NoError(t, err)
+	require.Len(t, response.PolicyDocument.Statement, 2)
+	assert.Equal(t, []string{"arn:aws-cn:execute-api:cn-north-1:123456789012:abcdef123/$default/*/*"}, response.PolicyDocument.Statement[0].Resource)
+	assert.Equal(t, "Allow", response.PolicyDocument.Statement[0].Effect)
+	assert.Equal(t, "Deny", response.PolicyDocument.Statement[1].Effect)
+	assert.Nil(t, response.Context)
+}
+
+func TestAuthPolicyBuilder_Errors(t *testing.T) {
+	for _, arn := range []string{
+		"",
+		"arn:aws:lambda:us-east-1:123456789012:function:f",
+		"arn:aws:execute-api:us-east-1:123456789012:abcdef123",
+		"arn:aws:execute-api:us-east-1:123456789012:/prod/GET/",
+	} {
+		_, err := NewAuthPolicyBuilder("user", arn)
+		assert.Error(t, err, arn)
+	}
+
+	builder, err := NewAuthPolicyBuilder("user", "arn:aws:execute-api:us-east-1:123456789012:abcdef123/prod/GET/pets")
+	require.NoError(t, err)
+
+	_, err = builder.Build()
+	assert.EqualError(t, err, "authorizer policy has no statements")
+
+	_, err = builder.
+		Allow("FETCH", "/pets").
+		Allow("GET", "/pets?name=x").
+		WithContext("claims", map[string]string{"sub": "123"}).
+		Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid HTTP verb "FETCH"`)
+	assert.Contains(t, err.Error(), `invalid resource path "/pets?name=x"`)
+	assert.Contains(t, err.Error(), `context value for "claims" must be a string, number, or boolean`)
+}
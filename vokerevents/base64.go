@@ -0,0 +1,68 @@
+package vokerevents
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DecodeBase64 decodes data as delivered by Lambda event sources that
+// base64-encode binary fields, such as Kinesis record data and Kafka record
+// values. It accepts the standard and URL-safe alphabets, with or without
+// padding, since producers don't agree on one.
+func DecodeBase64(data string) ([]byte, error) {
+	encoding := base64.StdEncoding
+	if strings.ContainsAny(data, "-_") {
+		encoding = base64.URLEncoding
+	}
+	if !strings.HasSuffix(data, "=") && len(data)%4 != 0 {
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+	decoded, err := encoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 data: %w", err)
+	}
+	return decoded, nil
+}
+
+// Base64JSON is a JSON document delivered base64-encoded inside an event,
+// such as a JSON message put on a Kinesis stream or Kafka topic. It decodes
+// the base64 string and then the document into Value, and encodes Value the
+// same way, so handlers can declare the payload's type directly:
+//
+//	type OrderRecord struct {
+//	    Data vokerevents.Base64JSON[Order] `json:"data"`
+//	}
+//
+// The document itself is decoded with encoding/json, whichever codec decodes
+// the enclosing event.
+type Base64JSON[T any] struct {
+	Value T
+}
+
+// UnmarshalJSON decodes a base64 string holding a JSON document. JSON null
+// leaves Value unchanged.
+func (b *Base64JSON[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := DecodeBase64(encoded)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, &b.Value)
+}
+
+// MarshalJSON encodes Value as a JSON document in a standard base64 string.
+func (b Base64JSON[T]) MarshalJSON() ([]byte, error) {
+	document, err := json.Marshal(b.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(document)
+}
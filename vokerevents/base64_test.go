@@ -0,0 +1,57 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBase64(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"standard", "aGk/Pz4+"},
+		{"url safe", "aGk_Pz4-"},
+		{"padded", "aGk/Pz4+aQ=="},
+		{"unpadded", "aGk/Pz4+aQ"},
+		{"url safe unpadded", "aGk_Pz4-aQ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeBase64(tt.input)
+			require.NoError(t, err)
+			assert.Contains(t, []string{"hi??>>", "hi??>>i"}, string(decoded))
+		})
+	}
+
+	_, err := DecodeBase64("not base64!")
+	assert.ErrorContains(t, err, "failed to decode base64 data")
+}
+
+type testOrder struct {
+	OrderID string `json:"orderId"`
+	Total   int    `json:"total"`
+}
+
+func TestBase64JSON(t *testing.T) {
+	var record struct {
+		Data Base64JSON[testOrder] `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"data":"eyJvcmRlcklkIjoiby00MiIsInRvdGFsIjoxOTk5fQ=="}`), &record))
+	assert.Equal(t, testOrder{OrderID: "o-42", Total: 1999}, record.Data.Value)
+
+	b, err := json.Marshal(record)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":"eyJvcmRlcklkIjoiby00MiIsInRvdGFsIjoxOTk5fQ=="}`, string(b))
+}
+
+func TestBase64JSON_Errors(t *testing.T) {
+	var value Base64JSON[testOrder]
+	require.NoError(t, json.Unmarshal([]byte(`null`), &value))
+	assert.Error(t, json.Unmarshal([]byte(`42`), &value))
+	assert.ErrorContains(t, json.Unmarshal([]byte(`"%%%"`), &value), "failed to decode base64 data")
+	assert.Error(t, json.Unmarshal([]byte(`"bm90IGpzb24="`), &value), "the decoded document must be JSON")
+}
@@ -0,0 +1,160 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CloudTrailAPICallDetailType is the detail type of the events EventBridge
+// delivers for API calls recorded by CloudTrail.
+const CloudTrailAPICallDetailType = "AWS API Call via CloudTrail"
+
+// CloudTrail user identity types, for [CloudTrailUserIdentity].Type.
+const (
+	CloudTrailIdentityRoot            = "Root"
+	CloudTrailIdentityIAMUser         = "IAMUser"
+	CloudTrailIdentityAssumedRole     = "AssumedRole"
+	CloudTrailIdentityFederatedUser   = "FederatedUser"
+	CloudTrailIdentityAWSAccount      = "AWSAccount"
+	CloudTrailIdentityAWSService      = "AWSService"
+	CloudTrailIdentityIdentityCenter  = "IdentityCenterUser"
+	CloudTrailIdentityWebIdentityUser = "WebIdentityUser"
+)
+
+// CloudTrailAPICall is the detail of "AWS API Call via CloudTrail" events,
+// one per API call CloudTrail records:
+//
+//	func handler(ctx context.Context, event vokerevents.EventBridgeEvent[vokerevents.CloudTrailAPICall]) (struct{}, error)
+//
+// Request parameters and response elements depend on the API called; decode
+// them with [CloudTrailAPICall.DecodeRequestParameters] and
+// [CloudTrailAPICall.DecodeResponseElements] once EventSource and EventName
+// are known.
+type CloudTrailAPICall struct {
+	EventVersion string                 `json:"eventVersion"`
+	UserIdentity CloudTrailUserIdentity `json:"userIdentity"`
+	EventTime    time.Time              `json:"eventTime"`
+	// EventSource is the service called, such as "s3.amazonaws.com".
+	EventSource     string `json:"eventSource"`
+	EventName       string `json:"eventName"`
+	AWSRegion       string `json:"awsRegion"`
+	SourceIPAddress string `json:"sourceIPAddress"`
+	UserAgent       string `json:"userAgent"`
+	// ErrorCode and ErrorMessage are set when the call failed.
+	ErrorCode           string                `json:"errorCode,omitempty"`
+	ErrorMessage        string                `json:"errorMessage,omitempty"`
+	RequestParameters   json.RawMessage       `json:"requestParameters"`
+	ResponseElements    json.RawMessage       `json:"responseElements"`
+	AdditionalEventData json.RawMessage       `json:"additionalEventData,omitempty"`
+	RequestID           string                `json:"requestID"`
+	EventID             string                `json:"eventID"`
+	ReadOnly            bool                  `json:"readOnly"`
+	Resources           []CloudTrailResource  `json:"resources,omitempty"`
+	EventType           string                `json:"eventType"`
+	ManagementEvent     bool                  `json:"managementEvent"`
+	RecipientAccountID  string                `json:"recipientAccountId"`
+	SharedEventID       string                `json:"sharedEventID,omitempty"`
+	VPCEndpointID       string                `json:"vpcEndpointId,omitempty"`
+	EventCategory       string                `json:"eventCategory"`
+	TLSDetails          *CloudTrailTLSDetails `json:"tlsDetails,omitempty"`
+	// SessionCredentialFromConsole is "true" for calls made from the AWS
+	// Management Console.
+	SessionCredentialFromConsole string `json:"sessionCredentialFromConsole,omitempty"`
+}
+
+// Failed reports whether the API call returned an error.
+func (c CloudTrailAPICall) Failed() bool {
+	return c.ErrorCode != ""
+}
+
+// DecodeRequestParameters decodes RequestParameters into v. Calls recorded
+// without parameters leave v unchanged.
+func (c CloudTrailAPICall) DecodeRequestParameters(v any) error {
+	return decodeCloudTrailField(c.RequestParameters, v)
+}
+
+// DecodeResponseElements decodes ResponseElements into v. Failed calls and
+// calls whose responses CloudTrail doesn't record leave v unchanged.
+func (c CloudTrailAPICall) DecodeResponseElements(v any) error {
+	return decodeCloudTrailField(c.ResponseElements, v)
+}
+
+// DecodeAdditionalEventData decodes AdditionalEventData into v.
+func (c CloudTrailAPICall) DecodeAdditionalEventData(v any) error {
+	return decodeCloudTrailField(c.AdditionalEventData, v)
+}
+
+// decodeCloudTrailField decodes raw into v, leaving v unchanged when raw is
+// missing or null.
+func decodeCloudTrailField(raw json.RawMessage, v any) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// CloudTrailUserIdentity is who made an API call.
+type CloudTrailUserIdentity struct {
+	// Type is one of the CloudTrail user identity type constants.
+	Type        string `json:"type"`
+	PrincipalID string `json:"principalId,omitempty"`
+	ARN         string `json:"arn,omitempty"`
+	AccountID   string `json:"accountId,omitempty"`
+	AccessKeyID string `json:"accessKeyId,omitempty"`
+	UserName    string `json:"userName,omitempty"`
+	// InvokedBy is the service that made the call on the principal's
+	// behalf, such as "cloudformation.amazonaws.com".
+	InvokedBy      string                    `json:"invokedBy,omitempty"`
+	SessionContext *CloudTrailSessionContext `json:"sessionContext,omitempty"`
+	// IdentityProvider is set for FederatedUser and WebIdentityUser
+	// identities.
+	IdentityProvider string                `json:"identityProvider,omitempty"`
+	OnBehalfOf       *CloudTrailOnBehalfOf `json:"onBehalfOf,omitempty"`
+}
+
+// CloudTrailSessionContext describes the temporary credentials an API call
+// was made with.
+type CloudTrailSessionContext struct {
+	SessionIssuer       *CloudTrailSessionIssuer    `json:"sessionIssuer,omitempty"`
+	WebIDFederationData json.RawMessage             `json:"webIdFederationData,omitempty"`
+	Attributes          CloudTrailSessionAttributes `json:"attributes"`
+	SourceIdentity      string                      `json:"sourceIdentity,omitempty"`
+	EC2RoleDelivery     string                      `json:"ec2RoleDelivery,omitempty"`
+}
+
+// CloudTrailSessionIssuer is the principal that issued temporary
+// credentials, such as the role that was assumed.
+type CloudTrailSessionIssuer struct {
+	Type        string `json:"type"`
+	PrincipalID string `json:"principalId"`
+	ARN         string `json:"arn"`
+	AccountID   string `json:"accountId"`
+	UserName    string `json:"userName,omitempty"`
+}
+
+// CloudTrailSessionAttributes describes when temporary credentials were
+// issued. CloudTrail records MFAAuthenticated as "true" or "false".
+type CloudTrailSessionAttributes struct {
+	CreationDate     time.Time `json:"creationDate"`
+	MFAAuthenticated string    `json:"mfaAuthenticated"`
+}
+
+// CloudTrailOnBehalfOf is the IAM Identity Center user a call was made for.
+type CloudTrailOnBehalfOf struct {
+	UserID           string `json:"userId"`
+	IdentityStoreARN string `json:"identityStoreArn"`
+}
+
+// CloudTrailResource is a resource an API call accessed.
+type CloudTrailResource struct {
+	ARN       string `json:"ARN"`
+	AccountID string `json:"accountId,omitempty"`
+	Type      string `json:"type,omitempty"`
+}
+
+// CloudTrailTLSDetails describes the TLS connection an API call was made on.
+type CloudTrailTLSDetails struct {
+	TLSVersion               string `json:"tlsVersion"`
+	CipherSuite              string `json:"cipherSuite"`
+	ClientProvidedHostHeader string `json:"clientProvidedHostHeader"`
+}
@@ -0,0 +1,77 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudTrailAPICall_EventBridgeFixture(t *testing.T) {
+	var event EventBridgeEvent[CloudTrailAPICall]
+	readEventFixture(t, "eventbridge-cloudtrail-event.json", &event)
+
+	assert.Equal(t, CloudTrailAPICallDetailType, event.DetailType)
+	call := event.Detail
+	assert.Equal(t, "s3.amazonaws.com", call.EventSource)
+	assert.Equal(t, "PutBucketPolicy", call.EventName)
+	assert.Equal(t, time.Date(2024, 5, 10, 17, 41, 2, 0, time.UTC), call.EventTime)
+	assert.False(t, call.Failed())
+	assert.True(t, call.ManagementEvent)
+	assert.Equal(t, "TLSv1.3", call.TLSDetails.TLSVersion)
+	assert.Equal(t, []CloudTrailResource{{ARN: "arn:aws:s3:::example-bucket", AccountID: "123456789012", Type: "AWS::S3::Bucket"}}, call.Resources)
+
+	identity := call.UserIdentity
+	assert.Equal(t, CloudTrailIdentityAssumedRole, identity.Type)
+	require.NotNil(t, identity.SessionContext)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/Admin", identity.SessionContext.SessionIssuer.ARN)
+	assert.Equal(t, "true", identity.SessionContext.Attributes.MFAAuthenticated)
+
+	var params struct {
+		BucketName   string `json:"bucketName"`
+		BucketPolicy struct {
+			Statement []struct {
+				Effect    string `json:"Effect"`
+				Principal any    `json:"Principal"`
+			} `json:"Statement"`
+		} `json:"bucketPolicy"`
+	}
+	require.NoError(t, call.DecodeRequestParameters(&params))
+	assert.Equal(t, "example-bucket", params.BucketName)
+	require.Len(t, params.BucketPolicy.Statement, 1)
+	assert.Equal(t, "*", params.BucketPolicy.Statement[0].Principal)
+
+	response := map[string]any{"kept": true}
+	require.NoError(t, call.DecodeResponseElements(&response))
+	assert.Equal(t, map[string]any{"kept": true}, response)
+
+	var additional struct {
+		SignatureVersion string `json:"SignatureVersion"`
+	}
+	require.NoError(t, call.DecodeAdditionalEventData(&additional))
+	assert.Equal(t, "SigV4", additional.SignatureVersion)
+}
+
+func TestCloudTrailAPICall_Failed(t *testing.T) {
+	var call CloudTrailAPICall
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"eventName": "TerminateInstances",
+		"errorCode": "Client.UnauthorizedOperation",
+		"errorMessage": "You are not authorized to perform this operation."
+	}`), &call))
+
+	assert.True(t, call.Failed())
+	var params map[string]any
+	require.NoError(t, call.DecodeRequestParameters(&params))
+	assert.Nil(t, params)
+}
+
+func TestCloudTrailAPICall_DecodeError(t *testing.T) {
+	call := CloudTrailAPICall{RequestParameters: json.RawMessage(`{"instanceId":42}`)}
+	var params struct {
+		InstanceID string `json:"instanceId"`
+	}
+	require.Error(t, call.DecodeRequestParameters(&params))
+}
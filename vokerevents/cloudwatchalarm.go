@@ -0,0 +1,100 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CloudWatch alarm states.
+const (
+	CloudwatchAlarmStateOK               = "OK"
+	CloudwatchAlarmStateAlarm            = "ALARM"
+	CloudwatchAlarmStateInsufficientData = "INSUFFICIENT_DATA"
+)
+
+// cloudwatchAlarmTimeLayout is the timestamp format of alarm events, which
+// omits the colon from the UTC offset and so isn't RFC 3339.
+const cloudwatchAlarmTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// CloudwatchAlarmEvent is the event a CloudWatch alarm sends when it invokes
+// a Lambda function directly as an alarm action.
+type CloudwatchAlarmEvent struct {
+	Source    string `json:"source"`
+	AlarmARN  string `json:"alarmArn"`
+	AccountID string `json:"accountId"`
+	// Time uses the same format as [CloudwatchAlarmState].Timestamp.
+	Time      string                `json:"time"`
+	Region    string                `json:"region"`
+	AlarmData CloudwatchAlarmChange `json:"alarmData"`
+}
+
+// CloudwatchAlarmChange describes an alarm's state change. It is the
+// alarmData of a [CloudwatchAlarmEvent] and the detail of the "CloudWatch
+// Alarm State Change" events EventBridge delivers:
+//
+//	func handler(ctx context.Context, event vokerevents.EventBridgeEvent[vokerevents.CloudwatchAlarmChange]) (struct{}, error)
+type CloudwatchAlarmChange struct {
+	AlarmName     string                       `json:"alarmName"`
+	State         CloudwatchAlarmState         `json:"state"`
+	PreviousState CloudwatchAlarmState         `json:"previousState"`
+	Configuration CloudwatchAlarmConfiguration `json:"configuration"`
+}
+
+// CloudwatchAlarmState is an alarm state and why the alarm entered it.
+type CloudwatchAlarmState struct {
+	// Value is one of the CloudwatchAlarmState constants.
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+
+	// ReasonData is a JSON document with the data points that caused the
+	// change, when CloudWatch provides it.
+	ReasonData string `json:"reasonData,omitempty"`
+
+	// Timestamp is when the alarm entered the state. Use [CloudwatchAlarmState.Time]
+	// to parse it.
+	Timestamp string `json:"timestamp"`
+}
+
+// Time parses Timestamp.
+func (s CloudwatchAlarmState) Time() (time.Time, error) {
+	return time.Parse(cloudwatchAlarmTimeLayout, s.Timestamp)
+}
+
+// DecodeReasonData decodes ReasonData into v.
+func (s CloudwatchAlarmState) DecodeReasonData(v any) error {
+	return json.Unmarshal([]byte(s.ReasonData), v)
+}
+
+// CloudwatchAlarmConfiguration is the alarm's configuration when the state
+// changed. Metric alarms list their Metrics; composite alarms set AlarmRule.
+type CloudwatchAlarmConfiguration struct {
+	Description string                  `json:"description,omitempty"`
+	Metrics     []CloudwatchAlarmMetric `json:"metrics,omitempty"`
+	AlarmRule   string                  `json:"alarmRule,omitempty"`
+}
+
+// CloudwatchAlarmMetric is one metric or metric math expression an alarm
+// evaluates.
+type CloudwatchAlarmMetric struct {
+	ID         string                     `json:"id"`
+	MetricStat *CloudwatchAlarmMetricStat `json:"metricStat,omitempty"`
+	Expression string                     `json:"expression,omitempty"`
+	Label      string                     `json:"label,omitempty"`
+	ReturnData bool                       `json:"returnData"`
+}
+
+// CloudwatchAlarmMetricStat is a metric and the statistic an alarm computes
+// over each period.
+type CloudwatchAlarmMetricStat struct {
+	Metric CloudwatchMetric `json:"metric"`
+	Period int              `json:"period"`
+	Stat   string           `json:"stat"`
+	Unit   string           `json:"unit,omitempty"`
+}
+
+// CloudwatchMetric identifies a CloudWatch metric.
+type CloudwatchMetric struct {
+	Namespace  string            `json:"namespace"`
+	Name       string            `json:"name"`
+	Dimensions map[string]string `json:"dimensions,omitempty"`
+}
@@ -0,0 +1,59 @@
+package vokerevents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudwatchAlarmEvent_Fixture(t *testing.T) {
+	var event CloudwatchAlarmEvent
+	readEventFixture(t, "cloudwatch-alarm-event.json", &event)
+
+	assert.Equal(t, "aws.cloudwatch", event.Source)
+	assert.Equal(t, "arn:aws:cloudwatch:us-east-1:444455556666:alarm:lambda-demo-metric-alarm", event.AlarmARN)
+	assert.Equal(t, "444455556666", event.AccountID)
+
+	alarm := event.AlarmData
+	assert.Equal(t, "lambda-demo-metric-alarm", alarm.AlarmName)
+	assert.Equal(t, CloudwatchAlarmStateAlarm, alarm.State.Value)
+	assert.Equal(t, CloudwatchAlarmStateOK, alarm.PreviousState.Value)
+	assert.Empty(t, alarm.PreviousState.ReasonData)
+
+	changed, err := alarm.State.Time()
+	require.NoError(t, err)
+	assert.True(t, changed.Equal(time.Date(2023, 8, 4, 12, 36, 15, 490_000_000, time.UTC)))
+
+	var reason struct {
+		Statistic        string    `json:"statistic"`
+		RecentDatapoints []float64 `json:"recentDatapoints"`
+		Threshold        float64   `json:"threshold"`
+	}
+	require.NoError(t, alarm.State.DecodeReasonData(&reason))
+	assert.Equal(t, "Average", reason.Statistic)
+	assert.Equal(t, []float64{5}, reason.RecentDatapoints)
+	assert.Equal(t, 1.0, reason.Threshold)
+
+	require.Len(t, alarm.Configuration.Metrics, 1)
+	metric := alarm.Configuration.Metrics[0]
+	assert.True(t, metric.ReturnData)
+	require.NotNil(t, metric.MetricStat)
+	assert.Equal(t, CloudwatchMetric{Namespace: "AWS/Logs", Name: "CallCount", Dimensions: map[string]string{"InstanceId": "i-12345678"}}, metric.MetricStat.Metric)
+	assert.Equal(t, 60, metric.MetricStat.Period)
+	assert.Equal(t, "Percent", metric.MetricStat.Unit)
+}
+
+func TestCloudwatchAlarmChange_EventBridgeFixture(t *testing.T) {
+	var event EventBridgeEvent[CloudwatchAlarmChange]
+	readEventFixture(t, "eventbridge-cloudwatch-alarm-event.json", &event)
+
+	assert.Equal(t, "CloudWatch Alarm State Change", event.DetailType)
+	assert.Equal(t, "ServerCpuTooHigh", event.Detail.AlarmName)
+	assert.Equal(t, CloudwatchAlarmStateAlarm, event.Detail.State.Value)
+	assert.Equal(t, "AWS/EC2", event.Detail.Configuration.Metrics[0].MetricStat.Metric.Namespace)
+
+	_, err := (CloudwatchAlarmState{Timestamp: "2019-10-02T17:04:40Z"}).Time()
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package vokerevents
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// CloudWatch Logs subscription message types.
+const (
+	// CloudwatchLogsDataMessage carries log events.
+	CloudwatchLogsDataMessage = "DATA_MESSAGE"
+
+	// CloudwatchLogsControlMessage is sent when a subscription is created to
+	// check that the destination is reachable. It carries no log events.
+	CloudwatchLogsControlMessage = "CONTROL_MESSAGE"
+)
+
+// CloudwatchLogsEvent is the event delivered to a Lambda function subscribed
+// to a CloudWatch Logs log group or account-level subscription filter.
+type CloudwatchLogsEvent struct {
+	AWSLogs CloudwatchLogsRawData `json:"awslogs"`
+}
+
+// Parse decodes the compressed subscription payload. It is shorthand for
+// event.AWSLogs.Parse().
+func (e CloudwatchLogsEvent) Parse() (CloudwatchLogsData, error) {
+	return e.AWSLogs.Parse()
+}
+
+// CloudwatchLogsRawData holds the subscription payload as delivered by
+// CloudWatch Logs: gzip-compressed JSON, base64-encoded.
+type CloudwatchLogsRawData struct {
+	Data string `json:"data"`
+}
+
+// Parse base64-decodes and gunzips Data and unmarshals the resulting JSON.
+func (r CloudwatchLogsRawData) Parse() (CloudwatchLogsData, error) {
+	compressed, err := base64.StdEncoding.DecodeString(r.Data)
+	if err != nil {
+		return CloudwatchLogsData{}, fmt.Errorf("failed to decode base64 log data: %w", err)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return CloudwatchLogsData{}, fmt.Errorf("failed to open gzip log data: %w", err)
+	}
+	defer reader.Close()
+
+	var data CloudwatchLogsData
+	if err := json.NewDecoder(reader).Decode(&data); err != nil {
+		return CloudwatchLogsData{}, fmt.Errorf("failed to decode log data: %w", err)
+	}
+	return data, nil
+}
+
+// CloudwatchLogsData is the decoded subscription payload.
+type CloudwatchLogsData struct {
+	// MessageType is [CloudwatchLogsDataMessage] or
+	// [CloudwatchLogsControlMessage].
+	MessageType         string                   `json:"messageType"`
+	Owner               string                   `json:"owner"`
+	LogGroup            string                   `json:"logGroup"`
+	LogStream           string                   `json:"logStream"`
+	SubscriptionFilters []string                 `json:"subscriptionFilters"`
+	LogEvents           []CloudwatchLogsLogEvent `json:"logEvents"`
+
+	// PolicyLevel is set for account-level subscription filters
+	// ("ACCOUNT_LEVEL_POLICY") and empty for log group subscriptions.
+	PolicyLevel string `json:"policyLevel,omitempty"`
+}
+
+// CloudwatchLogsLogEvent is a single log event in a subscription payload.
+type CloudwatchLogsLogEvent struct {
+	ID string `json:"id"`
+
+	// Timestamp is the event time in milliseconds since the Unix epoch.
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+
+	// ExtractedFields contains fields extracted by a subscription filter
+	// pattern, when the pattern defines any.
+	ExtractedFields map[string]string `json:"extractedFields,omitempty"`
+}
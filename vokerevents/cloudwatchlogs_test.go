@@ -0,0 +1,80 @@
+package vokerevents
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudwatchLogsEvent_Parse(t *testing.T) {
+	var event CloudwatchLogsEvent
+	readEventFixture(t, "cloudwatchlogs-event.json", &event)
+
+	data, err := event.Parse()
+	require.NoError(t, err)
+
+	assert.Equal(t, CloudwatchLogsDataMessage, data.MessageType)
+	assert.Equal(t, "123456789012", data.Owner)
+	assert.Equal(t, "/aws/lambda/echo", data.LogGroup)
+	assert.Equal(t, "2026/07/10/[$LATEST]94fa867e5374431291a7fc14e2f56ae7", data.LogStream)
+	assert.Equal(t, []string{"LambdaStream_echo"}, data.SubscriptionFilters)
+	require.Len(t, data.LogEvents, 2)
+	assert.Equal(t, "34622316099697884706540976068822859012661220141643892546", data.LogEvents[0].ID)
+	assert.Equal(t, int64(1783680000000), data.LogEvents[0].Timestamp)
+	assert.Contains(t, data.LogEvents[0].Message, "START RequestId")
+	assert.Contains(t, data.LogEvents[1].Message, "REPORT RequestId")
+}
+
+func TestCloudwatchLogsRawData_Parse_ControlMessage(t *testing.T) {
+	raw := CloudwatchLogsRawData{Data: gzipBase64(t, `{"messageType":"CONTROL_MESSAGE","owner":"CloudwatchLogs","logGroup":"","logStream":"","subscriptionFilters":[],"logEvents":[{"id":"","timestamp":1783680000000,"message":"CWL CONTROL MESSAGE: Checking health of destination Kinesis stream."}]}`)}
+
+	data, err := raw.Parse()
+	require.NoError(t, err)
+	assert.Equal(t, CloudwatchLogsControlMessage, data.MessageType)
+	assert.Empty(t, data.PolicyLevel)
+}
+
+func TestCloudwatchLogsRawData_Parse_ExtractedFields(t *testing.T) {
+	raw := CloudwatchLogsRawData{Data: gzipBase64(t, `{"messageType":"DATA_MESSAGE","policyLevel":"ACCOUNT_LEVEL_POLICY","logEvents":[{"id":"1","timestamp":1,"message":"GET /index 200","extractedFields":{"method":"GET","status":"200"}}]}`)}
+
+	data, err := raw.Parse()
+	require.NoError(t, err)
+	assert.Equal(t, "ACCOUNT_LEVEL_POLICY", data.PolicyLevel)
+	require.Len(t, data.LogEvents, 1)
+	assert.Equal(t, map[string]string{"method": "GET", "status": "200"}, data.LogEvents[0].ExtractedFields)
+}
+
+func TestCloudwatchLogsRawData_Parse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "invalid base64", data: "not base64!", want: "failed to decode base64 log data"},
+		{name: "not gzip", data: base64.StdEncoding.EncodeToString([]byte(`{"messageType":"DATA_MESSAGE"}`)), want: "failed to open gzip log data"},
+		{name: "invalid json", data: gzipBase64(t, `{"messageType":`), want: "failed to decode log data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CloudwatchLogsRawData{Data: tt.data}.Parse()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func gzipBase64(t *testing.T, s string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
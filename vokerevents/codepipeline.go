@@ -0,0 +1,217 @@
+package vokerevents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CodePipeline failure types, for [CodePipelineFailureDetails].Type.
+const (
+	CodePipelineJobFailed           = "JobFailed"
+	CodePipelineConfigurationError  = "ConfigurationError"
+	CodePipelinePermissionError     = "PermissionError"
+	CodePipelineRevisionOutOfSync   = "RevisionOutOfSync"
+	CodePipelineRevisionUnavailable = "RevisionUnavailable"
+	CodePipelineSystemUnavailable   = "SystemUnavailable"
+)
+
+// codePipelineMaxMessage is the longest failure message CodePipeline
+// accepts.
+const codePipelineMaxMessage = 5000
+
+// CodePipelineJobEvent is the event CodePipeline sends when a pipeline's
+// Lambda invoke action runs. The function must report the job's result
+// with PutJobSuccessResult or PutJobFailureResult, or the action fails only
+// when it times out; [CodePipelineJobHandler] does this for a handler.
+type CodePipelineJobEvent struct {
+	Job CodePipelineJob `json:"CodePipeline.job"`
+}
+
+// CodePipelineJob is a job CodePipeline runs for an action.
+type CodePipelineJob struct {
+	ID        string           `json:"id"`
+	AccountID string           `json:"accountId"`
+	Data      CodePipelineData `json:"data"`
+}
+
+// CodePipelineData holds the job's configuration, artifacts, and the
+// temporary credentials for reading and writing the artifacts.
+type CodePipelineData struct {
+	ActionConfiguration CodePipelineActionConfiguration `json:"actionConfiguration"`
+	InputArtifacts      []CodePipelineArtifact          `json:"inputArtifacts"`
+	OutputArtifacts     []CodePipelineArtifact          `json:"outputArtifacts"`
+	ArtifactCredentials CodePipelineArtifactCredentials `json:"artifactCredentials"`
+	// ContinuationToken is set when a previous invocation for the job
+	// reported success with a continuation token, to the token it
+	// reported.
+	ContinuationToken string                     `json:"continuationToken,omitempty"`
+	EncryptionKey     *CodePipelineEncryptionKey `json:"encryptionKey,omitempty"`
+}
+
+// CodePipelineActionConfiguration is the configuration of the action.
+type CodePipelineActionConfiguration struct {
+	Configuration CodePipelineConfiguration `json:"configuration"`
+}
+
+// CodePipelineConfiguration holds the Lambda invoke action's settings.
+type CodePipelineConfiguration struct {
+	FunctionName   string `json:"FunctionName"`
+	UserParameters string `json:"UserParameters,omitempty"`
+}
+
+// DecodeUserParameters decodes UserParameters, when the action passes a
+// JSON document, into v.
+func (c CodePipelineConfiguration) DecodeUserParameters(v any) error {
+	return json.Unmarshal([]byte(c.UserParameters), v)
+}
+
+// CodePipelineArtifact is an input or output artifact stored in S3.
+type CodePipelineArtifact struct {
+	Name     string                       `json:"name"`
+	Revision *string                      `json:"revision"`
+	Location CodePipelineArtifactLocation `json:"location"`
+}
+
+// CodePipelineArtifactLocation is where an artifact is stored. Type is "S3".
+type CodePipelineArtifactLocation struct {
+	Type       string                 `json:"type"`
+	S3Location CodePipelineS3Location `json:"s3Location"`
+}
+
+// CodePipelineS3Location is the S3 object of an artifact.
+type CodePipelineS3Location struct {
+	BucketName string `json:"bucketName"`
+	ObjectKey  string `json:"objectKey"`
+}
+
+// CodePipelineArtifactCredentials are temporary credentials scoped to the
+// job's artifacts.
+type CodePipelineArtifactCredentials struct {
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	SessionToken    string `json:"sessionToken"`
+}
+
+// CodePipelineEncryptionKey is the KMS key that encrypts the artifacts.
+type CodePipelineEncryptionKey struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// CodePipelineClient reports job results to CodePipeline. vokeraws provides
+// an implementation; an adapter over the AWS SDK's CodePipeline client works
+// too.
+type CodePipelineClient interface {
+	PutJobSuccessResult(ctx context.Context, result CodePipelineJobSuccess) error
+	PutJobFailureResult(ctx context.Context, result CodePipelineJobFailure) error
+}
+
+// CodePipelineJobSuccess is a PutJobSuccessResult request.
+type CodePipelineJobSuccess struct {
+	JobID string `json:"jobId"`
+	// ContinuationToken reports the job as still in progress. CodePipeline
+	// invokes the function again with the token in
+	// [CodePipelineData].ContinuationToken.
+	ContinuationToken string                        `json:"continuationToken,omitempty"`
+	ExecutionDetails  *CodePipelineExecutionDetails `json:"executionDetails,omitempty"`
+	OutputVariables   map[string]string             `json:"outputVariables,omitempty"`
+}
+
+// CodePipelineJobFailure is a PutJobFailureResult request.
+type CodePipelineJobFailure struct {
+	JobID          string                     `json:"jobId"`
+	FailureDetails CodePipelineFailureDetails `json:"failureDetails"`
+}
+
+// CodePipelineExecutionDetails describes the work done, shown in the
+// CodePipeline console.
+type CodePipelineExecutionDetails struct {
+	Summary             string `json:"summary,omitempty"`
+	ExternalExecutionID string `json:"externalExecutionId,omitempty"`
+	PercentComplete     int    `json:"percentComplete,omitempty"`
+}
+
+// CodePipelineFailureDetails describes why a job failed. It is an error, so
+// a handler passed to [CodePipelineJobHandler] can return one to choose the
+// failure type:
+//
+//	return vokerevents.CodePipelineJobResult{}, &vokerevents.CodePipelineFailureDetails{
+//	    Type:    vokerevents.CodePipelineConfigurationError,
+//	    Message: "UserParameters must name a stage",
+//	}
+type CodePipelineFailureDetails struct {
+	// Type is one of the CodePipeline failure type constants.
+	Type                string `json:"type"`
+	Message             string `json:"message"`
+	ExternalExecutionID string `json:"externalExecutionId,omitempty"`
+}
+
+func (d *CodePipelineFailureDetails) Error() string {
+	return d.Message
+}
+
+// CodePipelineJobResult is the outcome of a job handler.
+type CodePipelineJobResult struct {
+	// ContinuationToken, when set, reports the job as still in progress,
+	// for jobs that outlast one invocation. The next invocation for the
+	// job carries it in [CodePipelineData].ContinuationToken.
+	ContinuationToken string
+	ExecutionDetails  *CodePipelineExecutionDetails
+	OutputVariables   map[string]string
+}
+
+// CodePipelineJobHandler returns a handler that runs handler for a job and
+// reports its result with client. A returned error reports the job as
+// failed, with the type and details of a [*CodePipelineFailureDetails] in
+// its chain or as a JobFailed with the error's message otherwise. A panic
+// also reports the job as failed before it propagates. The returned
+// handler fails only when the result can't be reported, so Lambda's
+// retries don't rerun a job whose outcome CodePipeline already has.
+//
+//	voker.Start(vokerevents.CodePipelineJobHandler(client, deploy))
+func CodePipelineJobHandler(client CodePipelineClient, handler func(context.Context, CodePipelineJobEvent) (CodePipelineJobResult, error)) func(context.Context, CodePipelineJobEvent) (struct{}, error) {
+	return func(ctx context.Context, event CodePipelineJobEvent) (_ struct{}, err error) {
+		jobID := event.Job.ID
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				_ = client.PutJobFailureResult(ctx, codePipelineFailure(jobID, fmt.Errorf("panic: %v", recovered)))
+				panic(recovered)
+			}
+		}()
+
+		result, handlerErr := handler(ctx, event)
+		if handlerErr != nil {
+			if err := client.PutJobFailureResult(ctx, codePipelineFailure(jobID, handlerErr)); err != nil {
+				return struct{}{}, fmt.Errorf("report failure of CodePipeline job %s: %w", jobID, errors.Join(handlerErr, err))
+			}
+			return struct{}{}, nil
+		}
+
+		if err := client.PutJobSuccessResult(ctx, CodePipelineJobSuccess{
+			JobID:             jobID,
+			ContinuationToken: result.ContinuationToken,
+			ExecutionDetails:  result.ExecutionDetails,
+			OutputVariables:   result.OutputVariables,
+		}); err != nil {
+			return struct{}{}, fmt.Errorf("report success of CodePipeline job %s: %w", jobID, err)
+		}
+		return struct{}{}, nil
+	}
+}
+
+func codePipelineFailure(jobID string, err error) CodePipelineJobFailure {
+	details := CodePipelineFailureDetails{Type: CodePipelineJobFailed, Message: err.Error()}
+	if failure, ok := errors.AsType[*CodePipelineFailureDetails](err); ok {
+		details = *failure
+		if details.Type == "" {
+			details.Type = CodePipelineJobFailed
+		}
+	}
+	if len(details.Message) > codePipelineMaxMessage {
+		details.Message = strings.ToValidUTF8(details.Message[:codePipelineMaxMessage], "")
+	}
+	return CodePipelineJobFailure{JobID: jobID, FailureDetails: details}
+}
@@ -0,0 +1,250 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// AWS Config message types, for [ConfigInvokingEvent].MessageType.
+const (
+	ConfigItemChangeNotification          = "ConfigurationItemChangeNotification"
+	ConfigOversizedItemChangeNotification = "OversizedConfigurationItemChangeNotification"
+	ConfigScheduledNotification           = "ScheduledNotification"
+)
+
+// AWS Config compliance types, for [ConfigEvaluation].ComplianceType.
+const (
+	ConfigCompliant        = "COMPLIANT"
+	ConfigNonCompliant     = "NON_COMPLIANT"
+	ConfigNotApplicable    = "NOT_APPLICABLE"
+	ConfigInsufficientData = "INSUFFICIENT_DATA"
+)
+
+// configTestModeToken is the result token of invocations from the rule's
+// test in the console, whose evaluations must be sent in test mode.
+const configTestModeToken = "TESTMODE"
+
+// configMaxAnnotation is the longest annotation AWS Config accepts.
+const configMaxAnnotation = 256
+
+// ConfigEvent is the event AWS Config sends to a custom rule's function.
+// InvokingEvent and RuleParameters are JSON documents encoded as strings;
+// use [ConfigEvent.ParseInvokingEvent] and [ConfigEvent.DecodeRuleParameters].
+// Report results with PutEvaluations, built with [ConfigEvent.Evaluations].
+type ConfigEvent struct {
+	Version        string `json:"version"`
+	InvokingEvent  string `json:"invokingEvent"`
+	RuleParameters string `json:"ruleParameters,omitempty"`
+	ResultToken    string `json:"resultToken"`
+	// EventLeftScope reports that the resource is no longer in the rule's
+	// scope, so it should be evaluated as NOT_APPLICABLE.
+	EventLeftScope   bool   `json:"eventLeftScope"`
+	ExecutionRoleARN string `json:"executionRoleArn"`
+	ConfigRuleARN    string `json:"configRuleArn"`
+	ConfigRuleName   string `json:"configRuleName"`
+	ConfigRuleID     string `json:"configRuleId"`
+	AccountID        string `json:"accountId"`
+	// EvaluationMode is "DETECTIVE" or "PROACTIVE".
+	EvaluationMode string `json:"evaluationMode,omitempty"`
+}
+
+// ParseInvokingEvent decodes InvokingEvent.
+func (e ConfigEvent) ParseInvokingEvent() (ConfigInvokingEvent, error) {
+	var invoking ConfigInvokingEvent
+	err := json.Unmarshal([]byte(e.InvokingEvent), &invoking)
+	return invoking, err
+}
+
+// DecodeRuleParameters decodes RuleParameters into v. A rule without
+// parameters leaves v unchanged.
+func (e ConfigEvent) DecodeRuleParameters(v any) error {
+	if e.RuleParameters == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(e.RuleParameters), v)
+}
+
+// ConfigInvokingEvent is what triggered a rule evaluation: a configuration
+// change, which sets ConfigurationItem, an oversized change, which sets
+// only ConfigurationItemSummary, or a periodic schedule, which sets
+// neither.
+type ConfigInvokingEvent struct {
+	// MessageType is one of the AWS Config message type constants.
+	MessageType              string             `json:"messageType"`
+	ConfigurationItem        *ConfigItem        `json:"configurationItem,omitempty"`
+	ConfigurationItemSummary *ConfigItemSummary `json:"configurationItemSummary,omitempty"`
+	NotificationCreationTime string             `json:"notificationCreationTime"`
+	RecordVersion            string             `json:"recordVersion"`
+	// AWSAccountID is set for scheduled notifications.
+	AWSAccountID string `json:"awsAccountId,omitempty"`
+}
+
+// Oversized reports whether the configuration item was too large to
+// include. The function must fetch it, such as with
+// GetResourceConfigHistory, using the summary's resource type and ID.
+func (e ConfigInvokingEvent) Oversized() bool {
+	return e.MessageType == ConfigOversizedItemChangeNotification
+}
+
+// ConfigItemSummary identifies a configuration item and its state.
+type ConfigItemSummary struct {
+	ChangeType                   string `json:"changeType,omitempty"`
+	ConfigurationItemVersion     string `json:"configurationItemVersion"`
+	ConfigurationItemCaptureTime string `json:"configurationItemCaptureTime"`
+	ConfigurationStateID         int64  `json:"configurationStateId"`
+	AWSAccountID                 string `json:"awsAccountId"`
+	// ConfigurationItemStatus is "OK", "ResourceDiscovered",
+	// "ResourceNotRecorded", "ResourceDeleted", or
+	// "ResourceDeletedNotRecorded".
+	ConfigurationItemStatus   string `json:"configurationItemStatus"`
+	ResourceType              string `json:"resourceType"`
+	ResourceID                string `json:"resourceId"`
+	ResourceName              string `json:"resourceName,omitempty"`
+	ARN                       string `json:"ARN"`
+	AWSRegion                 string `json:"awsRegion"`
+	AvailabilityZone          string `json:"availabilityZone,omitempty"`
+	ConfigurationStateMd5Hash string `json:"configurationStateMd5Hash,omitempty"`
+	ResourceCreationTime      string `json:"resourceCreationTime,omitempty"`
+}
+
+// deleted reports whether the resource no longer exists.
+func (s ConfigItemSummary) deleted() bool {
+	return s.ConfigurationItemStatus == "ResourceDeleted" || s.ConfigurationItemStatus == "ResourceDeletedNotRecorded"
+}
+
+// ConfigItem is a resource's configuration at a point in time.
+// Configuration and SupplementaryConfiguration are specific to the resource
+// type; decode them into the matching struct.
+type ConfigItem struct {
+	ConfigItemSummary
+	RelatedEvents              []string                   `json:"relatedEvents,omitempty"`
+	Relationships              []ConfigRelationship       `json:"relationships,omitempty"`
+	Configuration              json.RawMessage            `json:"configuration,omitempty"`
+	SupplementaryConfiguration map[string]json.RawMessage `json:"supplementaryConfiguration,omitempty"`
+	Tags                       map[string]string          `json:"tags,omitempty"`
+}
+
+// ConfigRelationship is a resource related to a configuration item.
+type ConfigRelationship struct {
+	ResourceID   string `json:"resourceId"`
+	ResourceName string `json:"resourceName,omitempty"`
+	ResourceType string `json:"resourceType"`
+	// Name describes the relationship, such as "Is attached to Volume".
+	Name string `json:"name"`
+}
+
+// ConfigEvaluation is the compliance of one resource. Its JSON encoding is
+// that of the PutEvaluations API.
+type ConfigEvaluation struct {
+	ComplianceResourceType string
+	ComplianceResourceID   string
+	// ComplianceType is one of the AWS Config compliance type constants.
+	ComplianceType    string
+	Annotation        string
+	OrderingTimestamp time.Time
+}
+
+// MarshalJSON encodes e for PutEvaluations, whose timestamps are epoch
+// seconds.
+func (e ConfigEvaluation) MarshalJSON() ([]byte, error) {
+	type evaluation struct {
+		ComplianceResourceType string      `json:"ComplianceResourceType"`
+		ComplianceResourceID   string      `json:"ComplianceResourceId"`
+		ComplianceType         string      `json:"ComplianceType"`
+		Annotation             string      `json:"Annotation,omitempty"`
+		OrderingTimestamp      json.Number `json:"OrderingTimestamp"`
+	}
+	seconds := strconv.FormatFloat(float64(e.OrderingTimestamp.UnixMilli())/1000, 'f', -1, 64)
+	return json.Marshal(evaluation{
+		ComplianceResourceType: e.ComplianceResourceType,
+		ComplianceResourceID:   e.ComplianceResourceID,
+		ComplianceType:         e.ComplianceType,
+		Annotation:             e.Annotation,
+		OrderingTimestamp:      json.Number(seconds),
+	})
+}
+
+// ConfigEvaluations collects the evaluations of a rule invocation. Its JSON
+// encoding is the PutEvaluations request.
+type ConfigEvaluations struct {
+	Evaluations []ConfigEvaluation `json:"Evaluations"`
+	ResultToken string             `json:"ResultToken"`
+	// TestMode is set for invocations from the rule's test in the AWS
+	// Config console, whose evaluations AWS Config must not record.
+	TestMode bool `json:"TestMode,omitempty"`
+
+	invoking  ConfigInvokingEvent
+	leftScope bool
+}
+
+// Evaluations returns an empty set of evaluations for the event:
+//
+//	evaluations, err := event.Evaluations()
+//	if err != nil {
+//	    return struct{}{}, err
+//	}
+//	if isEncrypted(evaluations) {
+//	    evaluations.EvaluateItem(vokerevents.ConfigCompliant, "")
+//	} else {
+//	    evaluations.EvaluateItem(vokerevents.ConfigNonCompliant, "volume is not encrypted")
+//	}
+//	// Send evaluations with PutEvaluations.
+func (e ConfigEvent) Evaluations() (*ConfigEvaluations, error) {
+	invoking, err := e.ParseInvokingEvent()
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigEvaluations{
+		Evaluations: []ConfigEvaluation{},
+		ResultToken: e.ResultToken,
+		TestMode:    e.ResultToken == configTestModeToken,
+		invoking:    invoking,
+		leftScope:   e.EventLeftScope,
+	}, nil
+}
+
+// Evaluate adds the compliance of a resource, ordered by the notification
+// time. Periodic rules use it for each resource they check. Annotations are
+// truncated to the 256 characters AWS Config accepts.
+func (c *ConfigEvaluations) Evaluate(resourceType, resourceID, complianceType, annotation string) *ConfigEvaluations {
+	ordering, _ := time.Parse(time.RFC3339, c.invoking.NotificationCreationTime)
+	return c.add(resourceType, resourceID, complianceType, annotation, ordering)
+}
+
+// EvaluateItem adds the compliance of the resource whose configuration
+// change triggered the rule, ordered by the item's capture time. A resource
+// that left the rule's scope or was deleted is evaluated as NOT_APPLICABLE
+// regardless of complianceType. EvaluateItem does nothing for scheduled
+// notifications, which carry no configuration item.
+func (c *ConfigEvaluations) EvaluateItem(complianceType, annotation string) *ConfigEvaluations {
+	var summary ConfigItemSummary
+	switch {
+	case c.invoking.ConfigurationItem != nil:
+		summary = c.invoking.ConfigurationItem.ConfigItemSummary
+	case c.invoking.ConfigurationItemSummary != nil:
+		summary = *c.invoking.ConfigurationItemSummary
+	default:
+		return c
+	}
+	if c.leftScope || summary.deleted() {
+		complianceType, annotation = ConfigNotApplicable, ""
+	}
+	ordering, _ := time.Parse(time.RFC3339, summary.ConfigurationItemCaptureTime)
+	return c.add(summary.ResourceType, summary.ResourceID, complianceType, annotation, ordering)
+}
+
+func (c *ConfigEvaluations) add(resourceType, resourceID, complianceType, annotation string, ordering time.Time) *ConfigEvaluations {
+	if utf8.RuneCountInString(annotation) > configMaxAnnotation {
+		annotation = string([]rune(annotation)[:configMaxAnnotation])
+	}
+	c.Evaluations = append(c.Evaluations, ConfigEvaluation{
+		ComplianceResourceType: resourceType,
+		ComplianceResourceID:   resourceID,
+		ComplianceType:         complianceType,
+		Annotation:             annotation,
+		OrderingTimestamp:      ordering,
+	})
+	return c
+}
@@ -0,0 +1,163 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigEvent_Fixture(t *testing.T) {
+	var event ConfigEvent
+	readEventFixture(t, "config-rule-event.json", &event)
+
+	assert.Equal(t, "encrypted-volumes", event.ConfigRuleName)
+	assert.Equal(t, "myResultToken", event.ResultToken)
+	assert.Equal(t, "DETECTIVE", event.EvaluationMode)
+
+	var params struct {
+		RequiredTag string `json:"requiredTag"`
+	}
+	require.NoError(t, event.DecodeRuleParameters(&params))
+	assert.Equal(t, "team", params.RequiredTag)
+
+	invoking, err := event.ParseInvokingEvent()
+	require.NoError(t, err)
+	assert.Equal(t, ConfigItemChangeNotification, invoking.MessageType)
+	assert.False(t, invoking.Oversized())
+	require.NotNil(t, invoking.ConfigurationItem)
+	item := invoking.ConfigurationItem
+	assert.Equal(t, "AWS::EC2::Volume", item.ResourceType)
+	assert.Equal(t, "vol-00000000", item.ResourceID)
+	assert.Equal(t, int64(1455672994043), item.ConfigurationStateID)
+	assert.Equal(t, map[string]string{"team": "platform"}, item.Tags)
+	require.Len(t, item.Relationships, 1)
+	assert.Equal(t, "Is attached to Instance", item.Relationships[0].Name)
+
+	var volume struct {
+		Encrypted bool `json:"encrypted"`
+		Size      int  `json:"size"`
+	}
+	require.NoError(t, json.Unmarshal(item.Configuration, &volume))
+	assert.Equal(t, 8, volume.Size)
+	assert.False(t, volume.Encrypted)
+}
+
+func TestConfigEvent_DecodeRuleParametersEmpty(t *testing.T) {
+	params := struct{ Tag string }{Tag: "default"}
+	require.NoError(t, ConfigEvent{}.DecodeRuleParameters(&params))
+	assert.Equal(t, "default", params.Tag)
+}
+
+func TestConfigEvaluations_EvaluateItem(t *testing.T) {
+	var event ConfigEvent
+	readEventFixture(t, "config-rule-event.json", &event)
+
+	evaluations, err := event.Evaluations()
+	require.NoError(t, err)
+	evaluations.EvaluateItem(ConfigNonCompliant, "volume is not encrypted")
+
+	assert.Equal(t, "myResultToken", evaluations.ResultToken)
+	assert.False(t, evaluations.TestMode)
+	assert.Equal(t, []ConfigEvaluation{{
+		ComplianceResourceType: "AWS::EC2::Volume",
+		ComplianceResourceID:   "vol-00000000",
+		ComplianceType:         ConfigNonCompliant,
+		Annotation:             "volume is not encrypted",
+		OrderingTimestamp:      time.Date(2016, 2, 17, 1, 36, 34, 43e6, time.UTC),
+	}}, evaluations.Evaluations)
+
+	body, err := json.Marshal(evaluations)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Evaluations": [{
+			"ComplianceResourceType": "AWS::EC2::Volume",
+			"ComplianceResourceId": "vol-00000000",
+			"ComplianceType": "NON_COMPLIANT",
+			"Annotation": "volume is not encrypted",
+			"OrderingTimestamp": 1455672994.043
+		}],
+		"ResultToken": "myResultToken"
+	}`, string(body))
+}
+
+func TestConfigEvaluations_EvaluateItemNotApplicable(t *testing.T) {
+	tests := map[string]ConfigEvent{
+		"left scope": {
+			EventLeftScope: true,
+			InvokingEvent:  `{"messageType":"ConfigurationItemChangeNotification","configurationItem":{"resourceType":"AWS::S3::Bucket","resourceId":"logs","configurationItemStatus":"OK"}}`,
+		},
+		"deleted": {
+			InvokingEvent: `{"messageType":"ConfigurationItemChangeNotification","configurationItem":{"resourceType":"AWS::S3::Bucket","resourceId":"logs","configurationItemStatus":"ResourceDeleted"}}`,
+		},
+		"oversized deleted": {
+			InvokingEvent: `{"messageType":"OversizedConfigurationItemChangeNotification","configurationItemSummary":{"resourceType":"AWS::S3::Bucket","resourceId":"logs","configurationItemStatus":"ResourceDeletedNotRecorded"}}`,
+		},
+	}
+	for name, event := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluations, err := event.Evaluations()
+			require.NoError(t, err)
+			evaluations.EvaluateItem(ConfigNonCompliant, "not versioned")
+
+			require.Len(t, evaluations.Evaluations, 1)
+			assert.Equal(t, ConfigNotApplicable, evaluations.Evaluations[0].ComplianceType)
+			assert.Empty(t, evaluations.Evaluations[0].Annotation)
+			assert.Equal(t, "logs", evaluations.Evaluations[0].ComplianceResourceID)
+		})
+	}
+}
+
+func TestConfigEvaluations_Oversized(t *testing.T) {
+	event := ConfigEvent{
+		InvokingEvent: `{"messageType":"OversizedConfigurationItemChangeNotification","notificationCreationTime":"2016-02-17T01:37:00Z","configurationItemSummary":{"changeType":"UPDATE","resourceType":"AWS::EC2::SecurityGroup","resourceId":"sg-00000000","configurationItemStatus":"OK","configurationItemCaptureTime":"2016-02-17T01:36:34Z"}}`,
+	}
+
+	invoking, err := event.ParseInvokingEvent()
+	require.NoError(t, err)
+	assert.True(t, invoking.Oversized())
+	assert.Nil(t, invoking.ConfigurationItem)
+	require.NotNil(t, invoking.ConfigurationItemSummary)
+	assert.Equal(t, "UPDATE", invoking.ConfigurationItemSummary.ChangeType)
+
+	evaluations, err := event.Evaluations()
+	require.NoError(t, err)
+	evaluations.EvaluateItem(ConfigCompliant, "")
+	require.Len(t, evaluations.Evaluations, 1)
+	assert.Equal(t, "sg-00000000", evaluations.Evaluations[0].ComplianceResourceID)
+	assert.Equal(t, time.Date(2016, 2, 17, 1, 36, 34, 0, time.UTC), evaluations.Evaluations[0].OrderingTimestamp)
+}
+
+func TestConfigEvaluations_Scheduled(t *testing.T) {
+	event := ConfigEvent{
+		ResultToken:   "TESTMODE",
+		InvokingEvent: `{"awsAccountId":"123456789012","notificationCreationTime":"2016-02-17T01:37:00Z","messageType":"ScheduledNotification","recordVersion":"1.0"}`,
+	}
+
+	evaluations, err := event.Evaluations()
+	require.NoError(t, err)
+	assert.True(t, evaluations.TestMode)
+
+	evaluations.EvaluateItem(ConfigCompliant, "")
+	assert.Empty(t, evaluations.Evaluations)
+
+	evaluations.
+		Evaluate("AWS::::Account", "123456789012", ConfigCompliant, "").
+		Evaluate("AWS::IAM::User", "AIDA0000", ConfigNonCompliant, strings.Repeat("é", 300))
+	require.Len(t, evaluations.Evaluations, 2)
+	assert.Equal(t, time.Date(2016, 2, 17, 1, 37, 0, 0, time.UTC), evaluations.Evaluations[0].OrderingTimestamp)
+	assert.Equal(t, strings.Repeat("é", 256), evaluations.Evaluations[1].Annotation)
+
+	body, err := json.Marshal(evaluations)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"TestMode":true`)
+	assert.Contains(t, string(body), `"OrderingTimestamp":1455673020`)
+}
+
+func TestConfigEvent_InvalidInvokingEvent(t *testing.T) {
+	_, err := ConfigEvent{InvokingEvent: "{"}.Evaluations()
+	require.Error(t, err)
+}
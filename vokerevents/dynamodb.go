@@ -0,0 +1,166 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DynamoDB stream event names.
+const (
+	DynamoDBEventInsert = "INSERT"
+	DynamoDBEventModify = "MODIFY"
+	DynamoDBEventRemove = "REMOVE"
+)
+
+// DynamoDBEvent is the event delivered by a DynamoDB Streams event source
+// mapping.
+type DynamoDBEvent struct {
+	Records []DynamoDBEventRecord `json:"Records"`
+}
+
+// DynamoDBEventRecord is a single stream record.
+type DynamoDBEventRecord struct {
+	AWSRegion string `json:"awsRegion"`
+
+	// Change holds the item data of the record.
+	Change  DynamoDBStreamRecord `json:"dynamodb"`
+	EventID string               `json:"eventID"`
+
+	// EventName is one of the DynamoDBEvent constants.
+	EventName      string `json:"eventName"`
+	EventSource    string `json:"eventSource"`
+	EventVersion   string `json:"eventVersion"`
+	EventSourceArn string `json:"eventSourceARN"`
+
+	// UserIdentity is set for items removed by Time to Live.
+	UserIdentity *DynamoDBUserIdentity `json:"userIdentity,omitempty"`
+}
+
+// DynamoDBUserIdentity identifies the principal that made a change. DynamoDB
+// only reports it for TTL deletions, as Type "Service" and PrincipalID
+// "dynamodb.amazonaws.com".
+type DynamoDBUserIdentity struct {
+	Type        string `json:"type"`
+	PrincipalID string `json:"principalId"`
+}
+
+// DynamoDBStreamRecord contains the keys and item images of a stream record.
+// Which images are present depends on the stream's StreamViewType.
+type DynamoDBStreamRecord struct {
+	// ApproximateCreationDateTime is in seconds since the Unix epoch.
+	ApproximateCreationDateTime float64                           `json:"ApproximateCreationDateTime,omitempty"`
+	Keys                        map[string]DynamoDBAttributeValue `json:"Keys,omitempty"`
+	NewImage                    map[string]DynamoDBAttributeValue `json:"NewImage,omitempty"`
+	OldImage                    map[string]DynamoDBAttributeValue `json:"OldImage,omitempty"`
+	SequenceNumber              string                            `json:"SequenceNumber"`
+	SizeBytes                   int64                             `json:"SizeBytes"`
+	StreamViewType              string                            `json:"StreamViewType"`
+}
+
+// DynamoDBAttributeValue is a DynamoDB attribute in the typed JSON format
+// used by DynamoDB Streams, such as {"S": "text"} or {"N": "42"}. Exactly
+// one field is set.
+type DynamoDBAttributeValue struct {
+	S    *string                           `json:"S,omitempty"`
+	N    *string                           `json:"N,omitempty"`
+	B    []byte                            `json:"B,omitempty"`
+	SS   []string                          `json:"SS,omitempty"`
+	NS   []string                          `json:"NS,omitempty"`
+	BS   [][]byte                          `json:"BS,omitempty"`
+	M    map[string]DynamoDBAttributeValue `json:"M,omitempty"`
+	L    []DynamoDBAttributeValue          `json:"L,omitempty"`
+	NULL *bool                             `json:"NULL,omitempty"`
+	BOOL *bool                             `json:"BOOL,omitempty"`
+}
+
+// DynamoDBEventResponse is the partial batch response for DynamoDB stream
+// event sources. Each failure's ItemIdentifier is the record's sequence
+// number. Lambda retries the batch starting from the lowest reported
+// sequence number.
+type DynamoDBEventResponse struct {
+	BatchItemFailures []BatchItemFailure `json:"batchItemFailures"`
+}
+
+// UnmarshalDynamoDBImage decodes a stream image, such as
+// record.Change.NewImage, into v using v's json struct tags. Strings, sets,
+// lists, and maps decode to their JSON counterparts; numbers decode into
+// any numeric (or json.Number) field; binary values decode into []byte.
+func UnmarshalDynamoDBImage(image map[string]DynamoDBAttributeValue, v any) error {
+	plain, err := plainDynamoDBMap(image)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(plain)
+	if err != nil {
+		return fmt.Errorf("failed to encode DynamoDB image: %w", err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to decode DynamoDB image: %w", err)
+	}
+	return nil
+}
+
+func plainDynamoDBMap(values map[string]DynamoDBAttributeValue) (map[string]any, error) {
+	plain := make(map[string]any, len(values))
+	for name, value := range values {
+		converted, err := value.plain()
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", name, err)
+		}
+		plain[name] = converted
+	}
+	return plain, nil
+}
+
+// plain converts the attribute to a value whose JSON encoding is the
+// attribute's untyped form.
+func (av DynamoDBAttributeValue) plain() (any, error) {
+	switch {
+	case av.S != nil:
+		return *av.S, nil
+	case av.N != nil:
+		return dynamoDBNumber(*av.N)
+	case av.B != nil:
+		return av.B, nil
+	case av.BOOL != nil:
+		return *av.BOOL, nil
+	case av.NULL != nil:
+		return nil, nil
+	case av.SS != nil:
+		return av.SS, nil
+	case av.NS != nil:
+		numbers := make([]json.Number, len(av.NS))
+		for i, n := range av.NS {
+			number, err := dynamoDBNumber(n)
+			if err != nil {
+				return nil, err
+			}
+			numbers[i] = number
+		}
+		return numbers, nil
+	case av.BS != nil:
+		return av.BS, nil
+	case av.M != nil:
+		return plainDynamoDBMap(av.M)
+	case av.L != nil:
+		list := make([]any, len(av.L))
+		for i, item := range av.L {
+			converted, err := item.plain()
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			list[i] = converted
+		}
+		return list, nil
+	default:
+		return nil, errors.New("attribute value has no type")
+	}
+}
+
+func dynamoDBNumber(n string) (json.Number, error) {
+	if n == "" || (n[0] != '-' && (n[0] < '0' || n[0] > '9')) || !json.Valid([]byte(n)) {
+		return "", fmt.Errorf("invalid number %q", n)
+	}
+	return json.Number(n), nil
+}
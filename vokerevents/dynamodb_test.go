@@ -0,0 +1,102 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDynamoDBItem struct {
+	ID      int               `json:"Id"`
+	Message string            `json:"Message"`
+	Tags    []string          `json:"Tags"`
+	Scores  []float64         `json:"Scores"`
+	Active  bool              `json:"Active"`
+	Deleted *string           `json:"Deleted"`
+	Avatar  []byte            `json:"Avatar"`
+	Address map[string]any    `json:"Address"`
+	History []json.RawMessage `json:"History"`
+}
+
+func TestDynamoDBEvent_Fixture(t *testing.T) {
+	var event DynamoDBEvent
+	readEventFixture(t, "dynamodb-event.json", &event)
+
+	require.Len(t, event.Records, 3)
+	assert.Equal(t, DynamoDBEventInsert, event.Records[0].EventName)
+	assert.Equal(t, DynamoDBEventModify, event.Records[1].EventName)
+	assert.Equal(t, DynamoDBEventRemove, event.Records[2].EventName)
+	assert.Equal(t, float64(1783680000), event.Records[0].Change.ApproximateCreationDateTime)
+	assert.Equal(t, "111", event.Records[0].Change.SequenceNumber)
+	assert.Equal(t, "NEW_AND_OLD_IMAGES", event.Records[0].Change.StreamViewType)
+	assert.Nil(t, event.Records[0].UserIdentity)
+	require.NotNil(t, event.Records[2].UserIdentity)
+	assert.Equal(t, "dynamodb.amazonaws.com", event.Records[2].UserIdentity.PrincipalID)
+}
+
+func TestUnmarshalDynamoDBImage(t *testing.T) {
+	var event DynamoDBEvent
+	readEventFixture(t, "dynamodb-event.json", &event)
+
+	var item testDynamoDBItem
+	require.NoError(t, UnmarshalDynamoDBImage(event.Records[0].Change.NewImage, &item))
+	assert.Equal(t, 101, item.ID)
+	assert.Equal(t, "New item!", item.Message)
+	assert.Equal(t, []string{"a", "b"}, item.Tags)
+	assert.Equal(t, []float64{1, 2.5}, item.Scores)
+	assert.True(t, item.Active)
+	assert.Nil(t, item.Deleted)
+	assert.Equal(t, []byte("hello"), item.Avatar)
+	assert.Equal(t, map[string]any{"City": "Seattle", "Zip": float64(98101)}, item.Address)
+	require.Len(t, item.History, 2)
+	assert.JSONEq(t, `"created"`, string(item.History[0]))
+	assert.JSONEq(t, `1`, string(item.History[1]))
+
+	var keys struct {
+		ID json.Number `json:"Id"`
+	}
+	require.NoError(t, UnmarshalDynamoDBImage(event.Records[0].Change.Keys, &keys))
+	assert.Equal(t, json.Number("101"), keys.ID)
+}
+
+func TestUnmarshalDynamoDBImage_Errors(t *testing.T) {
+	notNumber := `"oops"`
+	tests := []struct {
+		name  string
+		image map[string]DynamoDBAttributeValue
+		want  string
+	}{
+		{
+			name:  "untyped attribute",
+			image: map[string]DynamoDBAttributeValue{"Id": {}},
+			want:  `attribute "Id": attribute value has no type`,
+		},
+		{
+			name:  "invalid number",
+			image: map[string]DynamoDBAttributeValue{"Id": {N: &notNumber}},
+			want:  `attribute "Id": invalid number`,
+		},
+		{
+			name:  "nested invalid number",
+			image: map[string]DynamoDBAttributeValue{"Scores": {NS: []string{"1", "x"}}},
+			want:  `attribute "Scores": invalid number "x"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out map[string]any
+			err := UnmarshalDynamoDBImage(tt.image, &out)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	message := "text"
+	var item testDynamoDBItem
+	err := UnmarshalDynamoDBImage(map[string]DynamoDBAttributeValue{"Id": {S: &message}}, &item)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode DynamoDB image")
+}
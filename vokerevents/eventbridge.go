@@ -0,0 +1,43 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventBridgeEvent is an event delivered by an Amazon EventBridge rule or
+// a scheduled rule. D is the type of the event's detail, which depends on
+// the event source and detail type. Use json.RawMessage to defer decoding
+// until DetailType is known.
+type EventBridgeEvent[D any] struct {
+	Version    string    `json:"version"`
+	ID         string    `json:"id"`
+	DetailType string    `json:"detail-type"`
+	Source     string    `json:"source"`
+	Account    string    `json:"account"`
+	Time       time.Time `json:"time"`
+	Region     string    `json:"region"`
+	Resources  []string  `json:"resources"`
+	Detail     D         `json:"detail"`
+}
+
+// DecodeEventBridgeDetail decodes the detail of an event received with a
+// json.RawMessage detail into D.
+func DecodeEventBridgeDetail[D any](event EventBridgeEvent[json.RawMessage]) (EventBridgeEvent[D], error) {
+	decoded := EventBridgeEvent[D]{
+		Version:    event.Version,
+		ID:         event.ID,
+		DetailType: event.DetailType,
+		Source:     event.Source,
+		Account:    event.Account,
+		Time:       event.Time,
+		Region:     event.Region,
+		Resources:  event.Resources,
+	}
+	if len(event.Detail) > 0 {
+		if err := json.Unmarshal(event.Detail, &decoded.Detail); err != nil {
+			return EventBridgeEvent[D]{}, err
+		}
+	}
+	return decoded, nil
+}
@@ -0,0 +1,42 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEC2StateChange struct {
+	InstanceID string `json:"instance-id"`
+	State      string `json:"state"`
+}
+
+func TestEventBridgeEvent_Fixture(t *testing.T) {
+	var event EventBridgeEvent[testEC2StateChange]
+	readEventFixture(t, "eventbridge-event.json", &event)
+
+	assert.Equal(t, "EC2 Instance State-change Notification", event.DetailType)
+	assert.Equal(t, "aws.ec2", event.Source)
+	assert.Equal(t, "111122223333", event.Account)
+	assert.Equal(t, time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC), event.Time)
+	assert.Equal(t, []string{"arn:aws:ec2:us-east-2:111122223333:instance/i-abcd1111"}, event.Resources)
+	assert.Equal(t, testEC2StateChange{InstanceID: "i-abcd1111", State: "pending"}, event.Detail)
+}
+
+func TestDecodeEventBridgeDetail(t *testing.T) {
+	var raw EventBridgeEvent[json.RawMessage]
+	readEventFixture(t, "eventbridge-event.json", &raw)
+
+	event, err := DecodeEventBridgeDetail[testEC2StateChange](raw)
+	require.NoError(t, err)
+	assert.Equal(t, raw.ID, event.ID)
+	assert.Equal(t, raw.Time, event.Time)
+	assert.Equal(t, "pending", event.Detail.State)
+
+	raw.Detail = json.RawMessage(`"not an object"`)
+	_, err = DecodeEventBridgeDetail[testEC2StateChange](raw)
+	require.Error(t, err)
+}
@@ -0,0 +1,21 @@
+// Package vokerevents provides typed payloads for AWS services that invoke
+// Lambda functions with events, together with the small helpers those event
+// sources otherwise force every function to reimplement.
+//
+// The types are plain JSON structs that work with [voker.Start]:
+//
+//	func handler(ctx context.Context, event vokerevents.CloudwatchLogsEvent) (struct{}, error) {
+//	    data, err := event.AWSLogs.Parse()
+//	    // ...
+//	}
+//
+// HTTP-shaped events (API Gateway, Function URLs, and ALB) live in the
+// vokerhttp package, which also converts them to net/http requests.
+package vokerevents
+
+// BatchItemFailure identifies a record that failed processing in a partial
+// batch response. For SQS, ItemIdentifier is the message ID; for Kinesis and
+// DynamoDB streams, it is the record's sequence number.
+type BatchItemFailure struct {
+	ItemIdentifier string `json:"itemIdentifier"`
+}
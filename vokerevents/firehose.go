@@ -0,0 +1,107 @@
+package vokerevents
+
+// Amazon Data Firehose transformation results.
+const (
+	// KinesisFirehoseTransformedStateOk marks a record as successfully
+	// transformed.
+	KinesisFirehoseTransformedStateOk = "Ok"
+
+	// KinesisFirehoseTransformedStateDropped marks a record as intentionally
+	// dropped. Firehose treats it as processed and does not deliver it.
+	KinesisFirehoseTransformedStateDropped = "Dropped"
+
+	// KinesisFirehoseTransformedStateProcessingFailed marks a record as failed.
+	// Firehose delivers it to the processing-failed S3 prefix.
+	KinesisFirehoseTransformedStateProcessingFailed = "ProcessingFailed"
+)
+
+// KinesisFirehoseEvent is the event delivered to an Amazon Data Firehose
+// (formerly Kinesis Data Firehose) data transformation function.
+type KinesisFirehoseEvent struct {
+	InvocationID           string                       `json:"invocationId"`
+	DeliveryStreamArn      string                       `json:"deliveryStreamArn"`
+	SourceKinesisStreamArn string                       `json:"sourceKinesisStreamArn,omitempty"`
+	Region                 string                       `json:"region"`
+	Records                []KinesisFirehoseEventRecord `json:"records"`
+}
+
+// KinesisFirehoseEventRecord is a single record to transform. Data is the
+// decoded record payload; Firehose delivers it base64-encoded.
+type KinesisFirehoseEventRecord struct {
+	RecordID string `json:"recordId"`
+
+	// ApproximateArrivalTimestamp is in milliseconds since the Unix epoch.
+	ApproximateArrivalTimestamp int64  `json:"approximateArrivalTimestamp"`
+	Data                        []byte `json:"data"`
+
+	// KinesisRecordMetadata is set when the delivery stream reads from a
+	// Kinesis data stream.
+	KinesisRecordMetadata *KinesisFirehoseRecordMetadata `json:"kinesisRecordMetadata,omitempty"`
+}
+
+// KinesisFirehoseRecordMetadata describes the Kinesis data stream record a
+// Firehose record was read from.
+type KinesisFirehoseRecordMetadata struct {
+	ShardID                     string `json:"shardId"`
+	PartitionKey                string `json:"partitionKey"`
+	ApproximateArrivalTimestamp int64  `json:"approximateArrivalTimestamp"`
+	SequenceNumber              string `json:"sequenceNumber"`
+	SubsequenceNumber           int64  `json:"subsequenceNumber"`
+}
+
+// KinesisFirehoseResponse is the response of a data transformation function.
+// It must contain exactly one record for every record in the event.
+type KinesisFirehoseResponse struct {
+	Records []KinesisFirehoseResponseRecord `json:"records"`
+}
+
+// KinesisFirehoseResponseRecord is the transformation result for one record.
+// Data is base64-encoded when marshaled.
+type KinesisFirehoseResponseRecord struct {
+	RecordID string `json:"recordId"`
+
+	// Result is one of the KinesisFirehoseTransformedState constants.
+	Result string `json:"result"`
+	Data   []byte `json:"data"`
+
+	// Metadata carries partition keys for Firehose dynamic partitioning.
+	Metadata *KinesisFirehoseResponseRecordMetadata `json:"metadata,omitempty"`
+}
+
+// KinesisFirehoseResponseRecordMetadata contains the dynamic partitioning
+// keys extracted for a record.
+type KinesisFirehoseResponseRecordMetadata struct {
+	PartitionKeys map[string]string `json:"partitionKeys"`
+}
+
+// TransformRecords calls transform for each record and builds the response
+// Firehose requires, preserving record order and IDs.
+//
+// transform returns the new record data and one of the
+// KinesisFirehoseTransformedState results; an empty result means
+// [KinesisFirehoseTransformedStateOk]. When transform returns an error, the
+// record is reported as [KinesisFirehoseTransformedStateProcessingFailed]
+// with its original data, so Firehose can deliver it to the error output
+// unchanged. Dropped records with nil data also keep their original data.
+func (e KinesisFirehoseEvent) TransformRecords(transform func(record KinesisFirehoseEventRecord) (data []byte, result string, err error)) KinesisFirehoseResponse {
+	response := KinesisFirehoseResponse{
+		Records: make([]KinesisFirehoseResponseRecord, len(e.Records)),
+	}
+	for i, record := range e.Records {
+		data, result, err := transform(record)
+		switch {
+		case err != nil:
+			data, result = record.Data, KinesisFirehoseTransformedStateProcessingFailed
+		case result == "":
+			result = KinesisFirehoseTransformedStateOk
+		case result == KinesisFirehoseTransformedStateDropped && data == nil:
+			data = record.Data
+		}
+		response.Records[i] = KinesisFirehoseResponseRecord{
+			RecordID: record.RecordID,
+			Result:   result,
+			Data:     data,
+		}
+	}
+	return response
+}
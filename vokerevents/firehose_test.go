@@ -0,0 +1,70 @@
+package vokerevents
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKinesisFirehoseEvent_Fixture(t *testing.T) {
+	var event KinesisFirehoseEvent
+	readEventFixture(t, "firehose-event.json", &event)
+
+	assert.Equal(t, "invocationIdExample", event.InvocationID)
+	assert.Equal(t, "us-west-2", event.Region)
+	require.Len(t, event.Records, 3)
+	assert.Equal(t, "Hello, this is a test 123.", string(event.Records[0].Data))
+	require.NotNil(t, event.Records[0].KinesisRecordMetadata)
+	assert.Equal(t, "shardId-000000000000", event.Records[0].KinesisRecordMetadata.ShardID)
+	assert.Nil(t, event.Records[1].KinesisRecordMetadata)
+}
+
+func TestKinesisFirehoseEvent_TransformRecords(t *testing.T) {
+	var event KinesisFirehoseEvent
+	readEventFixture(t, "firehose-event.json", &event)
+
+	response := event.TransformRecords(func(record KinesisFirehoseEventRecord) ([]byte, string, error) {
+		switch string(record.Data) {
+		case "drop me":
+			return nil, KinesisFirehoseTransformedStateDropped, nil
+		case "fail me":
+			return []byte("ignored"), "", errors.New("bad record")
+		default:
+			return bytes.ToUpper(record.Data), "", nil
+		}
+	})
+
+	b, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"records":[
+		{"recordId":"49546986683135544286507457936321625675700192471156785154","result":"Ok","data":"SEVMTE8sIFRISVMgSVMgQSBURVNUIDEyMy4="},
+		{"recordId":"49546986683135544286507457936321625675700192471156785155","result":"Dropped","data":"ZHJvcCBtZQ=="},
+		{"recordId":"49546986683135544286507457936321625675700192471156785156","result":"ProcessingFailed","data":"ZmFpbCBtZQ=="}
+	]}`, string(b))
+}
+
+func TestKinesisFirehoseEvent_TransformRecords_Empty(t *testing.T) {
+	response := KinesisFirehoseEvent{}.TransformRecords(func(KinesisFirehoseEventRecord) ([]byte, string, error) {
+		t.Fatal("transform called for empty event")
+		return nil, "", nil
+	})
+
+	b, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"records":[]}`, string(b))
+}
+
+func TestKinesisFirehoseResponseRecord_Metadata(t *testing.T) {
+	b, err := json.Marshal(KinesisFirehoseResponseRecord{
+		RecordID: "1",
+		Result:   KinesisFirehoseTransformedStateOk,
+		Data:     []byte("x"),
+		Metadata: &KinesisFirehoseResponseRecordMetadata{PartitionKeys: map[string]string{"customer": "acme"}},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"recordId":"1","result":"Ok","data":"eA==","metadata":{"partitionKeys":{"customer":"acme"}}}`, string(b))
+}
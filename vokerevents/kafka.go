@@ -0,0 +1,87 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// KafkaEvent is the event delivered by an Amazon MSK or self-managed Apache
+// Kafka event source mapping. Records are grouped by topic and partition,
+// keyed as "topic-partition".
+type KafkaEvent struct {
+	EventSource      string                   `json:"eventSource"`
+	EventSourceArn   string                   `json:"eventSourceArn,omitempty"`
+	BootstrapServers string                   `json:"bootstrapServers"`
+	Records          map[string][]KafkaRecord `json:"records"`
+}
+
+// KafkaRecord is a single record read from a topic. Key and Value are the
+// decoded record key and value; Lambda delivers them base64-encoded.
+type KafkaRecord struct {
+	Topic     string `json:"topic"`
+	Partition int64  `json:"partition"`
+	Offset    int64  `json:"offset"`
+
+	// Timestamp is in milliseconds since the Unix epoch. TimestampType is
+	// CREATE_TIME or LOG_APPEND_TIME.
+	Timestamp     int64                         `json:"timestamp"`
+	TimestampType string                        `json:"timestampType"`
+	Key           []byte                        `json:"key,omitempty"`
+	Value         []byte                        `json:"value"`
+	Headers       []map[string]KafkaHeaderValue `json:"headers"`
+}
+
+// Header returns the value of the first header named key.
+func (r KafkaRecord) Header(key string) ([]byte, bool) {
+	for _, header := range r.Headers {
+		if value, ok := header[key]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// KafkaHeaderValue is a record header value. Lambda delivers header values
+// as arrays of byte values rather than base64 strings; KafkaHeaderValue
+// decodes both, and encodes the array form.
+type KafkaHeaderValue []byte
+
+// UnmarshalJSON decodes an array of byte values, which may be signed as
+// produced by Java clients, or a base64 string.
+func (v *KafkaHeaderValue) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(data, &encoded); err != nil {
+			return err
+		}
+		decoded, err := DecodeBase64(encoded)
+		if err != nil {
+			return err
+		}
+		*v = decoded
+		return nil
+	}
+
+	var values []int
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	decoded := make([]byte, len(values))
+	for i, n := range values {
+		if n < -128 || n > 255 {
+			return fmt.Errorf("header byte %d is out of range", n)
+		}
+		decoded[i] = byte(n)
+	}
+	*v = decoded
+	return nil
+}
+
+// MarshalJSON encodes the value as an array of byte values.
+func (v KafkaHeaderValue) MarshalJSON() ([]byte, error) {
+	values := make([]int, len(v))
+	for i, b := range v {
+		values[i] = int(b)
+	}
+	return json.Marshal(values)
+}
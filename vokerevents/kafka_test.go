@@ -0,0 +1,42 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaEvent_Fixture(t *testing.T) {
+	var event KafkaEvent
+	readEventFixture(t, "kafka-event.json", &event)
+
+	assert.Equal(t, "aws:kafka", event.EventSource)
+	require.Len(t, event.Records["mytopic-0"], 1)
+	record := event.Records["mytopic-0"][0]
+	assert.Equal(t, int64(15), record.Offset)
+	assert.Equal(t, "order-42", string(record.Key))
+	assert.JSONEq(t, `{"orderId":"o-42","total":1999}`, string(record.Value))
+
+	value, ok := record.Header("headerKey")
+	require.True(t, ok)
+	assert.Equal(t, "headerValue", string(value))
+	_, ok = record.Header("missing")
+	assert.False(t, ok)
+}
+
+func TestKafkaHeaderValue(t *testing.T) {
+	var value KafkaHeaderValue
+	require.NoError(t, json.Unmarshal([]byte(`[-1,0,127,255]`), &value))
+	assert.Equal(t, KafkaHeaderValue{0xff, 0, 0x7f, 0xff}, value)
+
+	require.NoError(t, json.Unmarshal([]byte(`"aGk="`), &value))
+	assert.Equal(t, "hi", string(value))
+
+	assert.ErrorContains(t, json.Unmarshal([]byte(`[256]`), &value), "out of range")
+
+	b, err := json.Marshal(KafkaHeaderValue("hi"))
+	require.NoError(t, err)
+	assert.Equal(t, `[104,105]`, string(b))
+}
@@ -0,0 +1,213 @@
+package vokerevents
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// KinesisEvent is the event delivered by an Amazon Kinesis Data Streams
+// event source mapping.
+type KinesisEvent struct {
+	Records []KinesisEventRecord `json:"Records"`
+}
+
+// KinesisEventRecord is a single record in a Kinesis batch. EventID is
+// the shard ID and sequence number joined by a colon.
+type KinesisEventRecord struct {
+	AWSRegion         string        `json:"awsRegion"`
+	EventID           string        `json:"eventID"`
+	EventName         string        `json:"eventName"`
+	EventSource       string        `json:"eventSource"`
+	EventSourceArn    string        `json:"eventSourceARN"`
+	EventVersion      string        `json:"eventVersion"`
+	InvokeIdentityArn string        `json:"invokeIdentityArn"`
+	Kinesis           KinesisRecord `json:"kinesis"`
+}
+
+// KinesisRecord is the data record read from the stream. Data is the
+// decoded record payload; Kinesis delivers it base64-encoded. Declare a
+// record type with a [Base64JSON] field instead to decode JSON payloads
+// directly.
+type KinesisRecord struct {
+	// ApproximateArrivalTimestamp is in seconds since the Unix epoch, with
+	// millisecond precision.
+	ApproximateArrivalTimestamp float64 `json:"approximateArrivalTimestamp"`
+	Data                        []byte  `json:"data"`
+	EncryptionType              string  `json:"encryptionType,omitempty"`
+	PartitionKey                string  `json:"partitionKey"`
+	SequenceNumber              string  `json:"sequenceNumber"`
+	KinesisSchemaVersion        string  `json:"kinesisSchemaVersion"`
+}
+
+// KinesisEventResponse is the partial batch response for Kinesis event
+// sources. The event source mapping must enable ReportBatchItemFailures;
+// Lambda then retries the batch from the earliest failed sequence number.
+type KinesisEventResponse struct {
+	BatchItemFailures []BatchItemFailure `json:"batchItemFailures"`
+}
+
+// kplMagic prefixes records aggregated by the Kinesis Producer Library.
+var kplMagic = []byte{0xf3, 0x89, 0x9a, 0xc2}
+
+// errMalformedAggregate reports a KPL aggregated record whose checksum
+// matches but whose protobuf body can't be decoded.
+var errMalformedAggregate = errors.New("malformed KPL aggregated record")
+
+// KinesisUserRecord is a record as the producer put it. The Kinesis
+// Producer Library aggregates many user records into one Kinesis record;
+// each keeps its own partition key but shares the Kinesis record's
+// sequence number.
+type KinesisUserRecord struct {
+	PartitionKey string
+	// ExplicitHashKey is set when the producer chose the shard by hash key.
+	ExplicitHashKey string
+	Data            []byte
+	// SequenceNumber is the sequence number of the Kinesis record holding
+	// the user record; report it in batchItemFailures.
+	SequenceNumber string
+	// SubSequenceNumber is the user record's position in its aggregated
+	// record. It is 0 for records that aren't aggregated.
+	SubSequenceNumber int
+	// Aggregated reports whether the user record was unpacked from a KPL
+	// aggregated record.
+	Aggregated bool
+}
+
+// UserRecords returns the user records of every record in the event, in
+// order. See [KinesisRecord.UserRecords].
+func (e KinesisEvent) UserRecords() ([]KinesisUserRecord, error) {
+	var records []KinesisUserRecord
+	for _, record := range e.Records {
+		userRecords, err := record.Kinesis.UserRecords()
+		if err != nil {
+			return nil, fmt.Errorf("failed to deaggregate record %s: %w", record.Kinesis.SequenceNumber, err)
+		}
+		records = append(records, userRecords...)
+	}
+	return records, nil
+}
+
+// UserRecords unpacks a record aggregated by the Kinesis Producer Library
+// into its user records. A record that isn't aggregated, including one
+// whose checksum doesn't match, is returned as its only user record, the
+// same as the KPL's own deaggregation.
+func (r KinesisRecord) UserRecords() ([]KinesisUserRecord, error) {
+	body, ok := kplAggregateBody(r.Data)
+	if !ok {
+		return []KinesisUserRecord{{
+			PartitionKey:   r.PartitionKey,
+			Data:           r.Data,
+			SequenceNumber: r.SequenceNumber,
+		}}, nil
+	}
+
+	var partitionKeys, hashKeys []string
+	var encoded [][]byte
+	err := protoFields(body, func(field int, _ uint64, data []byte) error {
+		switch field {
+		case 1:
+			partitionKeys = append(partitionKeys, string(data))
+		case 2:
+			hashKeys = append(hashKeys, string(data))
+		case 3:
+			encoded = append(encoded, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]KinesisUserRecord, 0, len(encoded))
+	for i, b := range encoded {
+		record := KinesisUserRecord{SequenceNumber: r.SequenceNumber, SubSequenceNumber: i, Aggregated: true}
+		var hasPartitionKey bool
+		err := protoFields(b, func(field int, v uint64, data []byte) error {
+			switch field {
+			case 1:
+				if v >= uint64(len(partitionKeys)) {
+					return errMalformedAggregate
+				}
+				record.PartitionKey, hasPartitionKey = partitionKeys[v], true
+			case 2:
+				if v >= uint64(len(hashKeys)) {
+					return errMalformedAggregate
+				}
+				record.ExplicitHashKey = hashKeys[v]
+			case 3:
+				record.Data = data
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !hasPartitionKey {
+			return nil, errMalformedAggregate
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// kplAggregateBody returns the protobuf body of a KPL aggregated record:
+// the magic prefix, the body, and the body's MD5 digest.
+func kplAggregateBody(data []byte) ([]byte, bool) {
+	if len(data) < len(kplMagic)+md5.Size || !bytes.HasPrefix(data, kplMagic) {
+		return nil, false
+	}
+	body := data[len(kplMagic) : len(data)-md5.Size]
+	digest := md5.Sum(body)
+	if !bytes.Equal(digest[:], data[len(data)-md5.Size:]) {
+		return nil, false
+	}
+	return body, true
+}
+
+// protoFields calls fn for each field of a protobuf message with the
+// field's value: v for varints and data for length-delimited fields. Fixed
+// width fields are skipped.
+func protoFields(b []byte, fn func(field int, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errMalformedAggregate
+		}
+		b = b[n:]
+		field := int(key >> 3)
+		var v uint64
+		var data []byte
+		switch key & 7 {
+		case 0:
+			if v, n = binary.Uvarint(b); n <= 0 {
+				return errMalformedAggregate
+			}
+			b = b[n:]
+		case 1, 5:
+			size := 8
+			if key&7 == 5 {
+				size = 4
+			}
+			if len(b) < size {
+				return errMalformedAggregate
+			}
+			b = b[size:]
+			continue
+		case 2:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || length > uint64(len(b)-n) {
+				return errMalformedAggregate
+			}
+			data, b = b[n:n+int(length)], b[n+int(length):]
+		default:
+			return errMalformedAggregate
+		}
+		if err := fn(field, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package vokerevents
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKinesisEvent_Fixture(t *testing.T) {
+	var event KinesisEvent
+	readEventFixture(t, "kinesis-event.json", &event)
+
+	require.Len(t, event.Records, 2)
+	record := event.Records[0]
+	assert.Equal(t, "aws:kinesis", record.EventSource)
+	assert.Equal(t, "arn:aws:kinesis:us-east-2:123456789012:stream/lambda-stream", record.EventSourceArn)
+	assert.Equal(t, "1", record.Kinesis.PartitionKey)
+	assert.Equal(t, "Hello, this is a test.", string(record.Kinesis.Data))
+	assert.InDelta(t, 1545084650.987, record.Kinesis.ApproximateArrivalTimestamp, 0.0001)
+	assert.Equal(t, "KMS", event.Records[1].Kinesis.EncryptionType)
+}
+
+func TestKinesisEvent_Base64JSON(t *testing.T) {
+	type orderRecord struct {
+		Kinesis struct {
+			Data Base64JSON[testOrder] `json:"data"`
+		} `json:"kinesis"`
+	}
+	var event KinesisEvent
+	readEventFixture(t, "kinesis-event.json", &event)
+	require.Len(t, event.Records, 2)
+
+	raw, err := json.Marshal(event.Records[1])
+	require.NoError(t, err)
+	var record orderRecord
+	require.NoError(t, json.Unmarshal(raw, &record))
+	assert.Equal(t, testOrder{OrderID: "o-42", Total: 1999}, record.Kinesis.Data.Value)
+}
+
+// appendProtoBytes appends a length-delimited protobuf field.
+func appendProtoBytes(b []byte, field int, data []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// appendProtoVarint appends a varint protobuf field.
+func appendProtoVarint(b []byte, field int, v uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+// kplAggregate frames a protobuf AggregatedRecord body as the KPL does.
+func kplAggregate(body []byte) []byte {
+	digest := md5.Sum(body)
+	data := append([]byte{0xf3, 0x89, 0x9a, 0xc2}, body...)
+	return append(data, digest[:]...)
+}
+
+func TestKinesisRecord_UserRecordsAggregated(t *testing.T) {
+	var body []byte
+	body = appendProtoBytes(body, 1, []byte("user-1"))
+	body = appendProtoBytes(body, 1, []byte("user-2"))
+	body = appendProtoBytes(body, 2, []byte("170141183460469231731687303715884105728"))
+
+	var first, second, tag []byte
+	first = appendProtoVarint(first, 1, 0)
+	first = appendProtoBytes(first, 3, []byte(`{"n":1}`))
+	tag = appendProtoBytes(tag, 1, []byte("env"))
+	tag = appendProtoBytes(tag, 2, []byte("prod"))
+	second = appendProtoVarint(second, 1, 1)
+	second = appendProtoVarint(second, 2, 0)
+	second = appendProtoBytes(second, 3, []byte(`{"n":2}`))
+	second = appendProtoBytes(second, 4, tag)
+	body = appendProtoBytes(body, 3, first)
+	body = appendProtoBytes(body, 3, second)
+
+	record := KinesisRecord{PartitionKey: "aggregate", SequenceNumber: "4959", Data: kplAggregate(body)}
+	records, err := record.UserRecords()
+	require.NoError(t, err)
+	assert.Equal(t, []KinesisUserRecord{
+		{PartitionKey: "user-1", Data: []byte(`{"n":1}`), SequenceNumber: "4959", SubSequenceNumber: 0, Aggregated: true},
+		{PartitionKey: "user-2", ExplicitHashKey: "170141183460469231731687303715884105728", Data: []byte(`{"n":2}`), SequenceNumber: "4959", SubSequenceNumber: 1, Aggregated: true},
+	}, records)
+}
+
+func TestKinesisRecord_UserRecordsNotAggregated(t *testing.T) {
+	var body []byte
+	body = appendProtoBytes(body, 1, []byte("user-1"))
+	corrupt := kplAggregate(body)
+	corrupt[len(corrupt)-1] ^= 0xff
+
+	for name, data := range map[string][]byte{
+		"plain":             []byte("Hello, this is a test."),
+		"checksum mismatch": corrupt,
+		"short":             {0xf3, 0x89, 0x9a, 0xc2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			record := KinesisRecord{PartitionKey: "1", SequenceNumber: "4959", Data: data}
+			records, err := record.UserRecords()
+			require.NoError(t, err)
+			assert.Equal(t, []KinesisUserRecord{{PartitionKey: "1", Data: data, SequenceNumber: "4959"}}, records)
+		})
+	}
+}
+
+func TestKinesisRecord_UserRecordsMalformed(t *testing.T) {
+	var outOfRange []byte
+	outOfRange = appendProtoVarint(outOfRange, 1, 3)
+	outOfRange = appendProtoBytes(outOfRange, 3, []byte("data"))
+
+	var noKey []byte
+	noKey = appendProtoBytes(noKey, 3, []byte("data"))
+
+	tests := map[string][]byte{
+		"partition key index out of range": appendProtoBytes(appendProtoBytes(nil, 1, []byte("k")), 3, outOfRange),
+		"missing partition key":            appendProtoBytes(appendProtoBytes(nil, 1, []byte("k")), 3, noKey),
+		"truncated field":                  {0x0a, 0x05, 'k'},
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := KinesisRecord{Data: kplAggregate(body)}.UserRecords()
+			assert.ErrorIs(t, err, errMalformedAggregate)
+		})
+	}
+}
+
+func TestKinesisEvent_UserRecords(t *testing.T) {
+	var event KinesisEvent
+	readEventFixture(t, "kinesis-event.json", &event)
+
+	var body, user []byte
+	user = appendProtoVarint(user, 1, 0)
+	user = appendProtoBytes(user, 3, []byte("aggregated"))
+	body = appendProtoBytes(body, 1, []byte("user-1"))
+	body = appendProtoBytes(body, 3, user)
+	body = appendProtoBytes(body, 3, user)
+	event.Records[1].Kinesis.Data = kplAggregate(body)
+
+	records, err := event.UserRecords()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "Hello, this is a test.", string(records[0].Data))
+	assert.False(t, records[0].Aggregated)
+	assert.Equal(t, event.Records[1].Kinesis.SequenceNumber, records[2].SequenceNumber)
+	assert.Equal(t, 1, records[2].SubSequenceNumber)
+
+	event.Records[1].Kinesis.Data = kplAggregate([]byte{0x0a, 0x05})
+	_, err = event.UserRecords()
+	require.ErrorIs(t, err, errMalformedAggregate)
+	assert.Contains(t, err.Error(), event.Records[1].Kinesis.SequenceNumber)
+}
@@ -0,0 +1,279 @@
+package vokerevents
+
+// Lex V2 invocation sources.
+const (
+	LexV2DialogCodeHook      = "DialogCodeHook"
+	LexV2FulfillmentCodeHook = "FulfillmentCodeHook"
+)
+
+// Lex V2 dialog action types.
+const (
+	LexV2DialogActionClose         = "Close"
+	LexV2DialogActionConfirmIntent = "ConfirmIntent"
+	LexV2DialogActionDelegate      = "Delegate"
+	LexV2DialogActionElicitIntent  = "ElicitIntent"
+	LexV2DialogActionElicitSlot    = "ElicitSlot"
+)
+
+// Lex V2 intent states.
+const (
+	LexV2IntentStateFailed                = "Failed"
+	LexV2IntentStateFulfilled             = "Fulfilled"
+	LexV2IntentStateFulfillmentInProgress = "FulfillmentInProgress"
+	LexV2IntentStateInProgress            = "InProgress"
+	LexV2IntentStateReadyForFulfillment   = "ReadyForFulfillment"
+	LexV2IntentStateWaiting               = "Waiting"
+)
+
+// Lex V2 message content types.
+const (
+	LexV2ContentTypeCustomPayload     = "CustomPayload"
+	LexV2ContentTypeImageResponseCard = "ImageResponseCard"
+	LexV2ContentTypePlainText         = "PlainText"
+	LexV2ContentTypeSSML              = "SSML"
+)
+
+// LexV2Event is the request Amazon Lex V2 sends to a dialog or fulfillment
+// code hook. Respond with a [LexV2Response], usually built with
+// [LexV2Event.Close], [LexV2Event.Delegate], or [LexV2Event.ElicitSlot].
+type LexV2Event struct {
+	MessageVersion string `json:"messageVersion"`
+	// InvocationSource is LexV2DialogCodeHook or LexV2FulfillmentCodeHook.
+	InvocationSource string `json:"invocationSource"`
+	// InputMode is "DTMF", "Speech", or "Text".
+	InputMode           string                `json:"inputMode"`
+	ResponseContentType string                `json:"responseContentType"`
+	SessionID           string                `json:"sessionId"`
+	InputTranscript     string                `json:"inputTranscript"`
+	RawInputTranscript  string                `json:"rawInputTranscript,omitempty"`
+	InvocationLabel     string                `json:"invocationLabel,omitempty"`
+	Bot                 LexV2Bot              `json:"bot"`
+	Interpretations     []LexV2Interpretation `json:"interpretations"`
+	ProposedNextState   *LexV2ProposedState   `json:"proposedNextState,omitempty"`
+	RequestAttributes   map[string]string     `json:"requestAttributes,omitempty"`
+	SessionState        LexV2SessionState     `json:"sessionState"`
+	Transcriptions      []LexV2Transcription  `json:"transcriptions,omitempty"`
+}
+
+// LexV2Bot identifies the bot that sent the event.
+type LexV2Bot struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	AliasID   string `json:"aliasId"`
+	AliasName string `json:"aliasName,omitempty"`
+	LocaleID  string `json:"localeId"`
+	Version   string `json:"version"`
+}
+
+// LexV2Interpretation is one intent Lex considers a match for the user's
+// input, ordered by NLUConfidence.
+type LexV2Interpretation struct {
+	// InterpretationSource is "Lex" or "Bedrock".
+	InterpretationSource string                  `json:"interpretationSource,omitempty"`
+	Intent               LexV2Intent             `json:"intent"`
+	NLUConfidence        float64                 `json:"nluConfidence,omitempty"`
+	SentimentResponse    *LexV2SentimentResponse `json:"sentimentResponse,omitempty"`
+}
+
+// LexV2SentimentResponse is the sentiment Amazon Comprehend detected in the
+// input, when sentiment analysis is enabled.
+type LexV2SentimentResponse struct {
+	Sentiment      string              `json:"sentiment"`
+	SentimentScore LexV2SentimentScore `json:"sentimentScore"`
+}
+
+// LexV2SentimentScore holds the confidence of each sentiment.
+type LexV2SentimentScore struct {
+	Mixed    float64 `json:"mixed"`
+	Negative float64 `json:"negative"`
+	Neutral  float64 `json:"neutral"`
+	Positive float64 `json:"positive"`
+}
+
+// LexV2Intent is an intent with its slots and progress.
+type LexV2Intent struct {
+	Name string `json:"name"`
+	// Slots maps slot names to their values. A slot the user hasn't filled
+	// is nil.
+	Slots map[string]*LexV2Slot `json:"slots"`
+	// State is one of the LexV2IntentState constants.
+	State string `json:"state"`
+	// ConfirmationState is "Confirmed", "Denied", or "None".
+	ConfirmationState string `json:"confirmationState"`
+}
+
+// SlotValue returns the interpreted value of the named slot, or "" when the
+// slot is unfilled.
+func (i LexV2Intent) SlotValue(name string) string {
+	return i.Slots[name].InterpretedValue()
+}
+
+// LexV2Slot is the value of a slot. Scalar slots set Value; list slots
+// (Shape "List") set Values, one slot per element.
+type LexV2Slot struct {
+	Shape  string          `json:"shape,omitempty"`
+	Value  *LexV2SlotValue `json:"value,omitempty"`
+	Values []*LexV2Slot    `json:"values,omitempty"`
+}
+
+// InterpretedValue returns the slot's interpreted value, or "" for a nil or
+// list slot.
+func (s *LexV2Slot) InterpretedValue() string {
+	if s == nil || s.Value == nil {
+		return ""
+	}
+	return s.Value.InterpretedValue
+}
+
+// LexV2SlotValue is what the user said for a slot and how Lex resolved it.
+type LexV2SlotValue struct {
+	OriginalValue    string   `json:"originalValue,omitempty"`
+	InterpretedValue string   `json:"interpretedValue"`
+	ResolvedValues   []string `json:"resolvedValues,omitempty"`
+}
+
+// LexV2ProposedState is the state Lex would move to next without the code
+// hook's intervention.
+type LexV2ProposedState struct {
+	DialogAction LexV2DialogAction `json:"dialogAction"`
+	Intent       LexV2Intent       `json:"intent"`
+	Prompt       *LexV2Prompt      `json:"prompt,omitempty"`
+}
+
+// LexV2Prompt reports how many times the user has been prompted.
+type LexV2Prompt struct {
+	Attempt string `json:"attempt"`
+}
+
+// LexV2SessionState is the state of the conversation. Return it, modified,
+// in the response.
+type LexV2SessionState struct {
+	ActiveContexts       []LexV2ActiveContext `json:"activeContexts,omitempty"`
+	SessionAttributes    map[string]string    `json:"sessionAttributes,omitempty"`
+	RuntimeHints         *LexV2RuntimeHints   `json:"runtimeHints,omitempty"`
+	DialogAction         *LexV2DialogAction   `json:"dialogAction,omitempty"`
+	Intent               *LexV2Intent         `json:"intent,omitempty"`
+	OriginatingRequestID string               `json:"originatingRequestId,omitempty"`
+}
+
+// LexV2ActiveContext is a context that is active in the session.
+type LexV2ActiveContext struct {
+	Name              string                 `json:"name"`
+	ContextAttributes map[string]string      `json:"contextAttributes"`
+	TimeToLive        LexV2ContextTimeToLive `json:"timeToLive"`
+}
+
+// LexV2ContextTimeToLive bounds how long a context stays active.
+type LexV2ContextTimeToLive struct {
+	TimeToLiveInSeconds int `json:"timeToLiveInSeconds"`
+	TurnsToLive         int `json:"turnsToLive"`
+}
+
+// LexV2RuntimeHints improves recognition of slot values. SlotHints maps
+// intent names to slot names to their hints.
+type LexV2RuntimeHints struct {
+	SlotHints map[string]map[string]LexV2RuntimeHintDetails `json:"slotHints,omitempty"`
+}
+
+// LexV2RuntimeHintDetails lists the phrases to expect for a slot.
+type LexV2RuntimeHintDetails struct {
+	RuntimeHintValues []LexV2RuntimeHintValue `json:"runtimeHintValues"`
+}
+
+// LexV2RuntimeHintValue is a phrase the user is likely to say.
+type LexV2RuntimeHintValue struct {
+	Phrase string `json:"phrase"`
+}
+
+// LexV2DialogAction tells Lex what to do next.
+type LexV2DialogAction struct {
+	// Type is one of the LexV2DialogAction constants.
+	Type         string `json:"type"`
+	SlotToElicit string `json:"slotToElicit,omitempty"`
+	// SlotElicitationStyle is "Default", "SpellByLetter", or "SpellByWord".
+	SlotElicitationStyle string `json:"slotElicitationStyle,omitempty"`
+}
+
+// LexV2Transcription is one transcription of a speech input, with the
+// intent and slots Lex resolved from it.
+type LexV2Transcription struct {
+	Transcription           string                `json:"transcription"`
+	RawTranscription        string                `json:"rawTranscription,omitempty"`
+	TranscriptionConfidence float64               `json:"transcriptionConfidence"`
+	ResolvedContext         *LexV2ResolvedContext `json:"resolvedContext,omitempty"`
+	ResolvedSlots           map[string]*LexV2Slot `json:"resolvedSlots,omitempty"`
+}
+
+// LexV2ResolvedContext names the intent a transcription was resolved for.
+type LexV2ResolvedContext struct {
+	Intent string `json:"intent"`
+}
+
+// LexV2Response is the response to a [LexV2Event].
+type LexV2Response struct {
+	SessionState      LexV2SessionState `json:"sessionState"`
+	Messages          []LexV2Message    `json:"messages,omitempty"`
+	RequestAttributes map[string]string `json:"requestAttributes,omitempty"`
+}
+
+// LexV2Message is a message Lex returns to the user.
+type LexV2Message struct {
+	// ContentType is one of the LexV2ContentType constants.
+	ContentType       string                  `json:"contentType"`
+	Content           string                  `json:"content,omitempty"`
+	ImageResponseCard *LexV2ImageResponseCard `json:"imageResponseCard,omitempty"`
+}
+
+// LexV2PlainText returns a plain text message.
+func LexV2PlainText(content string) LexV2Message {
+	return LexV2Message{ContentType: LexV2ContentTypePlainText, Content: content}
+}
+
+// LexV2ImageResponseCard is a card with buttons the user can choose from.
+type LexV2ImageResponseCard struct {
+	Title    string        `json:"title"`
+	Subtitle string        `json:"subtitle,omitempty"`
+	ImageURL string        `json:"imageUrl,omitempty"`
+	Buttons  []LexV2Button `json:"buttons,omitempty"`
+}
+
+// LexV2Button is a button on an image response card. Value is sent to Lex
+// when the user chooses it.
+type LexV2Button struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// Close ends the intent in state, one of the LexV2IntentState constants,
+// usually LexV2IntentStateFulfilled or LexV2IntentStateFailed.
+func (e LexV2Event) Close(state string, messages ...LexV2Message) LexV2Response {
+	return e.respond(LexV2DialogAction{Type: LexV2DialogActionClose}, state, messages)
+}
+
+// Delegate lets Lex choose the next step, according to the bot's
+// configuration.
+func (e LexV2Event) Delegate() LexV2Response {
+	return e.respond(LexV2DialogAction{Type: LexV2DialogActionDelegate}, "", nil)
+}
+
+// ElicitSlot asks the user for the named slot, such as after a code hook
+// rejects a value.
+func (e LexV2Event) ElicitSlot(slot string, messages ...LexV2Message) LexV2Response {
+	return e.respond(LexV2DialogAction{Type: LexV2DialogActionElicitSlot, SlotToElicit: slot}, "", messages)
+}
+
+// respond returns the event's session state with dialogAction and, when set,
+// the intent state. The intent's slots are shared with the event, so changes
+// made to them before responding are returned to Lex.
+func (e LexV2Event) respond(dialogAction LexV2DialogAction, state string, messages []LexV2Message) LexV2Response {
+	session := e.SessionState
+	session.DialogAction = &dialogAction
+	if session.Intent != nil {
+		intent := *session.Intent
+		if state != "" {
+			intent.State = state
+		}
+		session.Intent = &intent
+	}
+	return LexV2Response{SessionState: session, Messages: messages}
+}
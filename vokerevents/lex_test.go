@@ -0,0 +1,81 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLexV2Event_Fixture(t *testing.T) {
+	var event LexV2Event
+	readEventFixture(t, "lexv2-event.json", &event)
+
+	assert.Equal(t, LexV2DialogCodeHook, event.InvocationSource)
+	assert.Equal(t, "Text", event.InputMode)
+	assert.Equal(t, LexV2Bot{ID: "ABCDEFGHIJ", Name: "BookTrip", AliasID: "TSTALIASID", AliasName: "TestBotAlias", LocaleID: "en_US", Version: "DRAFT"}, event.Bot)
+
+	require.Len(t, event.Interpretations, 2)
+	top := event.Interpretations[0]
+	assert.Equal(t, "BookHotel", top.Intent.Name)
+	assert.Equal(t, 0.92, top.NLUConfidence)
+	require.NotNil(t, top.SentimentResponse)
+	assert.Equal(t, 0.9, top.SentimentResponse.SentimentScore.Neutral)
+
+	assert.Equal(t, "Seattle", top.Intent.SlotValue("Location"))
+	assert.Empty(t, top.Intent.SlotValue("CheckInDate"))
+	assert.Empty(t, top.Intent.SlotValue("Unknown"))
+	assert.Contains(t, top.Intent.Slots, "CheckInDate")
+	assert.Nil(t, top.Intent.Slots["CheckInDate"])
+
+	amenities := top.Intent.Slots["Amenities"]
+	assert.Equal(t, "List", amenities.Shape)
+	require.Len(t, amenities.Values, 2)
+	assert.Equal(t, "gym", amenities.Values[1].InterpretedValue())
+	assert.Empty(t, event.Interpretations[1].Intent.Slots)
+
+	require.NotNil(t, event.ProposedNextState)
+	assert.Equal(t, LexV2DialogAction{Type: LexV2DialogActionElicitSlot, SlotToElicit: "CheckInDate"}, event.ProposedNextState.DialogAction)
+	assert.Equal(t, "Initial", event.ProposedNextState.Prompt.Attempt)
+
+	session := event.SessionState
+	assert.Equal(t, "gold", session.SessionAttributes["customerTier"])
+	require.Len(t, session.ActiveContexts, 1)
+	assert.Equal(t, LexV2ContextTimeToLive{TimeToLiveInSeconds: 600, TurnsToLive: 5}, session.ActiveContexts[0].TimeToLive)
+	assert.Equal(t, "Spokane", session.RuntimeHints.SlotHints["BookHotel"]["Location"].RuntimeHintValues[1].Phrase)
+	assert.Equal(t, "3", session.Intent.SlotValue("Nights"))
+
+	require.Len(t, event.Transcriptions, 1)
+	assert.Equal(t, "BookHotel", event.Transcriptions[0].ResolvedContext.Intent)
+	assert.Equal(t, "Seattle", event.Transcriptions[0].ResolvedSlots["Location"].InterpretedValue())
+}
+
+func TestLexV2Event_Responses(t *testing.T) {
+	var event LexV2Event
+	readEventFixture(t, "lexv2-event.json", &event)
+
+	closed := event.Close(LexV2IntentStateFulfilled, LexV2PlainText("Your room is booked."))
+	assert.Equal(t, LexV2DialogAction{Type: LexV2DialogActionClose}, *closed.SessionState.DialogAction)
+	assert.Equal(t, LexV2IntentStateFulfilled, closed.SessionState.Intent.State)
+	assert.Equal(t, LexV2IntentStateInProgress, event.SessionState.Intent.State, "the event's intent is unchanged")
+	assert.Equal(t, "gold", closed.SessionState.SessionAttributes["customerTier"])
+
+	b, err := json.Marshal(closed)
+	require.NoError(t, err)
+	var wire map[string]any
+	require.NoError(t, json.Unmarshal(b, &wire))
+	assert.Equal(t, []any{map[string]any{"contentType": "PlainText", "content": "Your room is booked."}}, wire["messages"])
+	slots := wire["sessionState"].(map[string]any)["intent"].(map[string]any)["slots"].(map[string]any)
+	assert.Contains(t, slots, "CheckInDate")
+	assert.Nil(t, slots["CheckInDate"], "unfilled slots are sent back as null")
+
+	elicit := event.ElicitSlot("CheckInDate", LexV2PlainText("When do you check in?"))
+	assert.Equal(t, LexV2DialogAction{Type: LexV2DialogActionElicitSlot, SlotToElicit: "CheckInDate"}, *elicit.SessionState.DialogAction)
+	assert.Equal(t, LexV2IntentStateInProgress, elicit.SessionState.Intent.State)
+
+	delegate := (LexV2Event{}).Delegate()
+	assert.Equal(t, LexV2DialogActionDelegate, delegate.SessionState.DialogAction.Type)
+	assert.Nil(t, delegate.SessionState.Intent)
+	assert.Empty(t, delegate.Messages)
+}
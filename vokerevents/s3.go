@@ -0,0 +1,66 @@
+package vokerevents
+
+import (
+	"net/url"
+	"time"
+)
+
+// S3Event is the event delivered by an Amazon S3 event notification.
+type S3Event struct {
+	Records []S3EventRecord `json:"Records"`
+}
+
+// S3EventRecord describes a single S3 object change.
+type S3EventRecord struct {
+	EventVersion      string            `json:"eventVersion"`
+	EventSource       string            `json:"eventSource"`
+	AWSRegion         string            `json:"awsRegion"`
+	EventTime         time.Time         `json:"eventTime"`
+	EventName         string            `json:"eventName"`
+	UserIdentity      S3UserIdentity    `json:"userIdentity"`
+	RequestParameters S3RequestParams   `json:"requestParameters"`
+	ResponseElements  map[string]string `json:"responseElements"`
+	S3                S3Entity          `json:"s3"`
+}
+
+// S3UserIdentity identifies the principal that caused the event.
+type S3UserIdentity struct {
+	PrincipalID string `json:"principalId"`
+}
+
+// S3RequestParams holds request details for the operation that caused the
+// event.
+type S3RequestParams struct {
+	SourceIPAddress string `json:"sourceIPAddress"`
+}
+
+// S3Entity describes the bucket and object of an S3 event record.
+type S3Entity struct {
+	SchemaVersion   string   `json:"s3SchemaVersion"`
+	ConfigurationID string   `json:"configurationId"`
+	Bucket          S3Bucket `json:"bucket"`
+	Object          S3Object `json:"object"`
+}
+
+// S3Bucket identifies the bucket of an S3 event record.
+type S3Bucket struct {
+	Name          string         `json:"name"`
+	OwnerIdentity S3UserIdentity `json:"ownerIdentity"`
+	Arn           string         `json:"arn"`
+}
+
+// S3Object identifies the object of an S3 event record. Key is URL-encoded
+// as delivered by S3; use [S3Object.DecodedKey] to obtain the object key.
+type S3Object struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size,omitempty"`
+	ETag      string `json:"eTag,omitempty"`
+	VersionID string `json:"versionId,omitempty"`
+	Sequencer string `json:"sequencer"`
+}
+
+// DecodedKey returns the object key with S3's form encoding removed, so
+// "my+photo%21.jpg" becomes "my photo!.jpg".
+func (o S3Object) DecodedKey() (string, error) {
+	return url.QueryUnescape(o.Key)
+}
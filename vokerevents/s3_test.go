@@ -0,0 +1,35 @@
+package vokerevents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3Event_Fixture(t *testing.T) {
+	var event S3Event
+	readEventFixture(t, "s3-event.json", &event)
+
+	require.Len(t, event.Records, 1)
+	record := event.Records[0]
+	assert.Equal(t, "aws:s3", record.EventSource)
+	assert.Equal(t, "ObjectCreated:Put", record.EventName)
+	assert.Equal(t, time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC), record.EventTime)
+	assert.Equal(t, "AWS:AIDAINPONIXQXHT3IKHL2", record.UserIdentity.PrincipalID)
+	assert.Equal(t, "205.255.255.255", record.RequestParameters.SourceIPAddress)
+	assert.Equal(t, "D82B88E5F771F645", record.ResponseElements["x-amz-request-id"])
+	assert.Equal(t, "amzn-s3-demo-bucket", record.S3.Bucket.Name)
+	assert.Equal(t, "arn:aws:s3:::amzn-s3-demo-bucket", record.S3.Bucket.Arn)
+	assert.Equal(t, int64(1305107), record.S3.Object.Size)
+
+	key, err := record.S3.Object.DecodedKey()
+	require.NoError(t, err)
+	assert.Equal(t, "uploads/my photo!.jpg", key)
+}
+
+func TestS3Object_DecodedKeyInvalid(t *testing.T) {
+	_, err := S3Object{Key: "bad%zz"}.DecodedKey()
+	require.Error(t, err)
+}
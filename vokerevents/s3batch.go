@@ -0,0 +1,119 @@
+package vokerevents
+
+import (
+	"net/url"
+	"strings"
+)
+
+// S3 Batch Operations result codes, for [S3BatchJobResult].ResultCode.
+const (
+	S3BatchJobResultSucceeded        = "Succeeded"
+	S3BatchJobResultTemporaryFailure = "TemporaryFailure"
+	S3BatchJobResultPermanentFailure = "PermanentFailure"
+)
+
+// S3BatchJobEvent is the event an S3 Batch Operations job sends to invoke a
+// Lambda function for its tasks. Schema version 1.0 identifies each task's
+// bucket by ARN; 2.0 by name, and adds the job's user arguments. Respond
+// with [S3BatchJobEvent.Response].
+type S3BatchJobEvent struct {
+	InvocationSchemaVersion string           `json:"invocationSchemaVersion"`
+	InvocationID            string           `json:"invocationId"`
+	Job                     S3BatchJob       `json:"job"`
+	Tasks                   []S3BatchJobTask `json:"tasks"`
+}
+
+// S3BatchJob identifies the batch job. UserArguments are the key-value
+// pairs set when the job was created, with schema version 2.0.
+type S3BatchJob struct {
+	ID            string            `json:"id"`
+	UserArguments map[string]string `json:"userArguments,omitempty"`
+}
+
+// S3BatchJobTask is one object of the job's manifest. S3Key is URL-encoded;
+// use [S3BatchJobTask.DecodedKey] to obtain the object key.
+type S3BatchJobTask struct {
+	TaskID      string  `json:"taskId"`
+	S3Key       string  `json:"s3Key"`
+	S3VersionID *string `json:"s3VersionId"`
+	// S3BucketARN is set with schema version 1.0.
+	S3BucketARN string `json:"s3BucketArn,omitempty"`
+	// S3Bucket is set with schema version 2.0.
+	S3Bucket string `json:"s3Bucket,omitempty"`
+}
+
+// DecodedKey returns the object key with S3's form encoding removed.
+func (t S3BatchJobTask) DecodedKey() (string, error) {
+	return url.QueryUnescape(t.S3Key)
+}
+
+// BucketName returns the task's bucket name under either schema version.
+func (t S3BatchJobTask) BucketName() string {
+	if t.S3Bucket != "" {
+		return t.S3Bucket
+	}
+	return t.S3BucketARN[strings.LastIndex(t.S3BucketARN, ":")+1:]
+}
+
+// Succeeded returns the task's successful result. resultString is recorded
+// in the job's completion report.
+func (t S3BatchJobTask) Succeeded(resultString string) S3BatchJobResult {
+	return S3BatchJobResult{TaskID: t.TaskID, ResultCode: S3BatchJobResultSucceeded, ResultString: resultString}
+}
+
+// TemporaryFailure returns the task's result for a failure S3 Batch
+// Operations should retry.
+func (t S3BatchJobTask) TemporaryFailure(resultString string) S3BatchJobResult {
+	return S3BatchJobResult{TaskID: t.TaskID, ResultCode: S3BatchJobResultTemporaryFailure, ResultString: resultString}
+}
+
+// PermanentFailure returns the task's result for a failure that retrying
+// won't fix.
+func (t S3BatchJobTask) PermanentFailure(resultString string) S3BatchJobResult {
+	return S3BatchJobResult{TaskID: t.TaskID, ResultCode: S3BatchJobResultPermanentFailure, ResultString: resultString}
+}
+
+// S3BatchJobResult is the outcome of one task.
+type S3BatchJobResult struct {
+	TaskID string `json:"taskId"`
+	// ResultCode is one of the S3 Batch Operations result code constants.
+	ResultCode   string `json:"resultCode"`
+	ResultString string `json:"resultString"`
+}
+
+// S3BatchJobResponse is the response to an [S3BatchJobEvent]. It must echo
+// the event's schema version and invocation ID and hold a result for every
+// task.
+type S3BatchJobResponse struct {
+	InvocationSchemaVersion string `json:"invocationSchemaVersion"`
+	// TreatMissingKeysAs is the result code of tasks without a result.
+	// S3 Batch Operations treats them as PermanentFailure when it is empty.
+	TreatMissingKeysAs string             `json:"treatMissingKeysAs,omitempty"`
+	InvocationID       string             `json:"invocationId"`
+	Results            []S3BatchJobResult `json:"results"`
+}
+
+// Response returns the response reporting results, echoing the event's
+// schema version and invocation ID:
+//
+//	func handler(ctx context.Context, event vokerevents.S3BatchJobEvent) (vokerevents.S3BatchJobResponse, error) {
+//	    results := make([]vokerevents.S3BatchJobResult, 0, len(event.Tasks))
+//	    for _, task := range event.Tasks {
+//	        if err := process(ctx, task); err != nil {
+//	            results = append(results, task.PermanentFailure(err.Error()))
+//	            continue
+//	        }
+//	        results = append(results, task.Succeeded(""))
+//	    }
+//	    return event.Response(results...), nil
+//	}
+func (e S3BatchJobEvent) Response(results ...S3BatchJobResult) S3BatchJobResponse {
+	if results == nil {
+		results = []S3BatchJobResult{}
+	}
+	return S3BatchJobResponse{
+		InvocationSchemaVersion: e.InvocationSchemaVersion,
+		InvocationID:            e.InvocationID,
+		Results:                 results,
+	}
+}
@@ -0,0 +1,64 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3BatchJobEvent_Fixture(t *testing.T) {
+	var event S3BatchJobEvent
+	readEventFixture(t, "s3-batch-event.json", &event)
+
+	assert.Equal(t, "2.0", event.InvocationSchemaVersion)
+	assert.Equal(t, map[string]string{"targetPrefix": "thumbnails/"}, event.Job.UserArguments)
+	require.Len(t, event.Tasks, 1)
+	task := event.Tasks[0]
+	assert.Nil(t, task.S3VersionID)
+	assert.Equal(t, "amzn-s3-demo-bucket", task.BucketName())
+	key, err := task.DecodedKey()
+	require.NoError(t, err)
+	assert.Equal(t, "photos/my photo!.jpg", key)
+}
+
+func TestS3BatchJobTask_BucketNameFromARN(t *testing.T) {
+	var event S3BatchJobEvent
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"invocationSchemaVersion": "1.0",
+		"invocationId": "YXNkbGZqYWRmaiBhc2RmdW9hZHNmZGpmaGFzbGtkaGZza2RmaAo",
+		"job": {"id": "f3cc4f60-61f6-4a2b-8a21-d07600c373ce"},
+		"tasks": [{"taskId": "dGFza2lkZ29lc2hlcmUK", "s3Key": "customerImage1.jpg", "s3VersionId": "1", "s3BucketArn": "arn:aws:s3:::amzn-s3-demo-bucket"}]
+	}`), &event))
+
+	task := event.Tasks[0]
+	assert.Equal(t, "amzn-s3-demo-bucket", task.BucketName())
+	assert.Equal(t, "1", *task.S3VersionID)
+	assert.Nil(t, event.Job.UserArguments)
+}
+
+func TestS3BatchJobEvent_Response(t *testing.T) {
+	var event S3BatchJobEvent
+	readEventFixture(t, "s3-batch-event.json", &event)
+	task := event.Tasks[0]
+
+	response := event.Response(task.Succeeded("resized"), task.TemporaryFailure("throttled"), task.PermanentFailure("not an image"))
+	b, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"invocationSchemaVersion": "2.0",
+		"invocationId": "Ymxhcmdo",
+		"results": [
+			{"taskId": "dGFza2lkZ29lc2hlcmUK", "resultCode": "Succeeded", "resultString": "resized"},
+			{"taskId": "dGFza2lkZ29lc2hlcmUK", "resultCode": "TemporaryFailure", "resultString": "throttled"},
+			{"taskId": "dGFza2lkZ29lc2hlcmUK", "resultCode": "PermanentFailure", "resultString": "not an image"}
+		]
+	}`, string(b))
+
+	response = event.Response()
+	response.TreatMissingKeysAs = S3BatchJobResultSucceeded
+	b, err = json.Marshal(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"invocationSchemaVersion":"2.0","invocationId":"Ymxhcmdo","treatMissingKeysAs":"Succeeded","results":[]}`, string(b))
+}
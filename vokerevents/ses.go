@@ -0,0 +1,120 @@
+package vokerevents
+
+import "time"
+
+// SimpleEmailEvent is the event delivered by an Amazon SES receipt rule
+// Lambda action.
+type SimpleEmailEvent struct {
+	Records []SimpleEmailRecord `json:"Records"`
+}
+
+// SimpleEmailRecord is a single SES receiving notification.
+type SimpleEmailRecord struct {
+	EventVersion string             `json:"eventVersion"`
+	EventSource  string             `json:"eventSource"`
+	SES          SimpleEmailService `json:"ses"`
+}
+
+// SimpleEmailService contains the received message and the receipt result.
+type SimpleEmailService struct {
+	Mail    SimpleEmailMessage `json:"mail"`
+	Receipt SimpleEmailReceipt `json:"receipt"`
+}
+
+// SimpleEmailMessage describes a received email. SES does not include the
+// message body in Lambda events; use an S3 action to store the full message.
+type SimpleEmailMessage struct {
+	CommonHeaders    SimpleEmailCommonHeaders `json:"commonHeaders"`
+	Source           string                   `json:"source"`
+	Timestamp        time.Time                `json:"timestamp"`
+	Destination      []string                 `json:"destination"`
+	Headers          []SimpleEmailHeader      `json:"headers"`
+	HeadersTruncated bool                     `json:"headersTruncated"`
+	MessageID        string                   `json:"messageId"`
+}
+
+// SimpleEmailHeader is a single raw message header, in message order.
+type SimpleEmailHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SimpleEmailCommonHeaders contains the parsed values of frequently used
+// message headers.
+type SimpleEmailCommonHeaders struct {
+	ReturnPath string   `json:"returnPath"`
+	From       []string `json:"from"`
+	Sender     string   `json:"sender,omitempty"`
+	ReplyTo    []string `json:"replyTo,omitempty"`
+	To         []string `json:"to"`
+	CC         []string `json:"cc,omitempty"`
+	BCC        []string `json:"bcc,omitempty"`
+	Date       string   `json:"date"`
+	MessageID  string   `json:"messageId"`
+	Subject    string   `json:"subject"`
+}
+
+// SimpleEmailReceipt contains the result of SES's checks on a received
+// message and the action that invoked the function.
+type SimpleEmailReceipt struct {
+	Recipients           []string                 `json:"recipients"`
+	Timestamp            time.Time                `json:"timestamp"`
+	SpamVerdict          SimpleEmailVerdict       `json:"spamVerdict"`
+	DKIMVerdict          SimpleEmailVerdict       `json:"dkimVerdict"`
+	DMARCVerdict         SimpleEmailVerdict       `json:"dmarcVerdict"`
+	DMARCPolicy          string                   `json:"dmarcPolicy,omitempty"`
+	SPFVerdict           SimpleEmailVerdict       `json:"spfVerdict"`
+	VirusVerdict         SimpleEmailVerdict       `json:"virusVerdict"`
+	Action               SimpleEmailReceiptAction `json:"action"`
+	ProcessingTimeMillis int64                    `json:"processingTimeMillis"`
+}
+
+// SES receipt verdict statuses.
+const (
+	SimpleEmailVerdictPass             = "PASS"
+	SimpleEmailVerdictFail             = "FAIL"
+	SimpleEmailVerdictGray             = "GRAY"
+	SimpleEmailVerdictProcessingFailed = "PROCESSING_FAILED"
+	SimpleEmailVerdictDisabled         = "DISABLED"
+)
+
+// SimpleEmailVerdict is the outcome of one of SES's receipt checks. Status is
+// one of the SimpleEmailVerdict constants.
+type SimpleEmailVerdict struct {
+	Status string `json:"status"`
+}
+
+// SimpleEmailReceiptAction describes the receipt rule action that invoked
+// the function.
+type SimpleEmailReceiptAction struct {
+	Type string `json:"type"`
+
+	// InvocationType is "Event" or "RequestResponse". Only RequestResponse
+	// invocations can return a [SimpleEmailDisposition].
+	InvocationType  string `json:"invocationType,omitempty"`
+	FunctionArn     string `json:"functionArn,omitempty"`
+	TopicArn        string `json:"topicArn,omitempty"`
+	BucketName      string `json:"bucketName,omitempty"`
+	ObjectKey       string `json:"objectKey,omitempty"`
+	ObjectKeyPrefix string `json:"objectKeyPrefix,omitempty"`
+}
+
+// SES receipt rule dispositions.
+const (
+	// SimpleEmailContinue continues evaluating the receipt rule set.
+	SimpleEmailContinue = "CONTINUE"
+
+	// SimpleEmailStopRule stops the remaining actions of the current rule.
+	SimpleEmailStopRule = "STOP_RULE"
+
+	// SimpleEmailStopRuleSet stops the current rule and all later rules.
+	SimpleEmailStopRuleSet = "STOP_RULE_SET"
+)
+
+// SimpleEmailDisposition is the response a RequestResponse receipt rule
+// Lambda action returns to control further rule evaluation.
+//
+//	return vokerevents.SimpleEmailDisposition{Disposition: vokerevents.SimpleEmailStopRuleSet}, nil
+type SimpleEmailDisposition struct {
+	Disposition string `json:"disposition"`
+}
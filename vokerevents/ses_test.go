@@ -0,0 +1,49 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimpleEmailEvent_Fixture(t *testing.T) {
+	var event SimpleEmailEvent
+	readEventFixture(t, "ses-event.json", &event)
+
+	require.Len(t, event.Records, 1)
+	record := event.Records[0]
+	assert.Equal(t, "aws:ses", record.EventSource)
+
+	mail := record.SES.Mail
+	assert.Equal(t, "janedoe@example.com", mail.Source)
+	assert.Equal(t, []string{"Jane Doe <janedoe@example.com>"}, mail.CommonHeaders.From)
+	assert.Equal(t, []string{"johndoe@example.com"}, mail.CommonHeaders.To)
+	assert.Equal(t, "Test Subject", mail.CommonHeaders.Subject)
+	assert.Equal(t, time.Unix(0, 0).UTC(), mail.Timestamp)
+	require.Len(t, mail.Headers, 3)
+	assert.Equal(t, SimpleEmailHeader{Name: "Subject", Value: "Test Subject"}, mail.Headers[2])
+	assert.Equal(t, "o3vrnil0e2ic28tr", mail.MessageID)
+
+	receipt := record.SES.Receipt
+	assert.Equal(t, SimpleEmailVerdictPass, receipt.SpamVerdict.Status)
+	assert.Equal(t, SimpleEmailVerdictPass, receipt.DKIMVerdict.Status)
+	assert.Equal(t, SimpleEmailVerdictFail, receipt.DMARCVerdict.Status)
+	assert.Equal(t, "reject", receipt.DMARCPolicy)
+	assert.Equal(t, SimpleEmailVerdictPass, receipt.SPFVerdict.Status)
+	assert.Equal(t, SimpleEmailVerdictGray, receipt.VirusVerdict.Status)
+	assert.Equal(t, int64(574), receipt.ProcessingTimeMillis)
+	assert.Equal(t, SimpleEmailReceiptAction{
+		Type:           "Lambda",
+		InvocationType: "RequestResponse",
+		FunctionArn:    "arn:aws:lambda:us-west-2:123456789012:function:Example",
+	}, receipt.Action)
+}
+
+func TestSimpleEmailDisposition_JSON(t *testing.T) {
+	b, err := json.Marshal(SimpleEmailDisposition{Disposition: SimpleEmailStopRuleSet})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"disposition":"STOP_RULE_SET"}`, string(b))
+}
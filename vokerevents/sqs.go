@@ -0,0 +1,38 @@
+package vokerevents
+
+// SQSEvent is the event delivered by an Amazon SQS event source mapping.
+type SQSEvent struct {
+	Records []SQSMessage `json:"Records"`
+}
+
+// SQSMessage is a single message in an SQS batch.
+type SQSMessage struct {
+	MessageID              string                         `json:"messageId"`
+	ReceiptHandle          string                         `json:"receiptHandle"`
+	Body                   string                         `json:"body"`
+	MD5OfBody              string                         `json:"md5OfBody"`
+	MD5OfMessageAttributes string                         `json:"md5OfMessageAttributes,omitempty"`
+	Attributes             map[string]string              `json:"attributes"`
+	MessageAttributes      map[string]SQSMessageAttribute `json:"messageAttributes"`
+	EventSourceARN         string                         `json:"eventSourceARN"`
+	EventSource            string                         `json:"eventSource"`
+	AWSRegion              string                         `json:"awsRegion"`
+}
+
+// SQSMessageAttribute is a user-defined message attribute. DataType is
+// "String", "Number", or "Binary", optionally followed by a custom suffix
+// such as "Number.int".
+type SQSMessageAttribute struct {
+	StringValue      *string  `json:"stringValue,omitempty"`
+	BinaryValue      []byte   `json:"binaryValue,omitempty"`
+	StringListValues []string `json:"stringListValues"`
+	BinaryListValues [][]byte `json:"binaryListValues"`
+	DataType         string   `json:"dataType"`
+}
+
+// SQSEventResponse is the partial batch response for SQS event sources. The
+// event source mapping must enable ReportBatchItemFailures; Lambda then
+// deletes every message except the listed failures.
+type SQSEventResponse struct {
+	BatchItemFailures []BatchItemFailure `json:"batchItemFailures"`
+}
@@ -0,0 +1,34 @@
+package vokerevents
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQSEvent_Fixture(t *testing.T) {
+	var event SQSEvent
+	readEventFixture(t, "sqs-event.json", &event)
+
+	require.Len(t, event.Records, 2)
+	message := event.Records[0]
+	assert.Equal(t, "059f36b4-87a3-44ab-83d2-661975830a7d", message.MessageID)
+	assert.Equal(t, `{"name":"one"}`, message.Body)
+	assert.Equal(t, "1", message.Attributes["ApproximateReceiveCount"])
+	require.Contains(t, message.MessageAttributes, "attempt")
+	require.NotNil(t, message.MessageAttributes["attempt"].StringValue)
+	assert.Equal(t, "1", *message.MessageAttributes["attempt"].StringValue)
+	assert.Equal(t, "Number", message.MessageAttributes["attempt"].DataType)
+	assert.Equal(t, "aws:sqs", message.EventSource)
+	assert.Equal(t, "arn:aws:sqs:us-east-2:123456789012:my-queue", message.EventSourceARN)
+}
+
+func TestSQSEventResponse_JSON(t *testing.T) {
+	b, err := json.Marshal(SQSEventResponse{
+		BatchItemFailures: []BatchItemFailure{{ItemIdentifier: "059f36b4-87a3-44ab-83d2-661975830a7d"}},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"batchItemFailures":[{"itemIdentifier":"059f36b4-87a3-44ab-83d2-661975830a7d"}]}`, string(b))
+}
@@ -0,0 +1,85 @@
+package vokerevents
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// WebSocketEventType is the kind of API Gateway WebSocket API event: a
+// client connecting, disconnecting, or sending a message.
+type WebSocketEventType string
+
+const (
+	WebSocketConnect    WebSocketEventType = "CONNECT"
+	WebSocketDisconnect WebSocketEventType = "DISCONNECT"
+	WebSocketMessage    WebSocketEventType = "MESSAGE"
+)
+
+// WebSocketRequest is the event an API Gateway WebSocket API sends for the
+// $connect, $disconnect, $default, and custom routes. Headers and query
+// string parameters are only present for $connect. Use the vokeraws
+// package's WebSocketClient to send messages back to the connection.
+type WebSocketRequest struct {
+	Headers                         map[string]string       `json:"headers,omitempty"`
+	MultiValueHeaders               map[string][]string     `json:"multiValueHeaders,omitempty"`
+	QueryStringParameters           map[string]string       `json:"queryStringParameters,omitempty"`
+	MultiValueQueryStringParameters map[string][]string     `json:"multiValueQueryStringParameters,omitempty"`
+	StageVariables                  map[string]string       `json:"stageVariables,omitempty"`
+	RequestContext                  WebSocketRequestContext `json:"requestContext"`
+	Body                            string                  `json:"body,omitempty"`
+	IsBase64Encoded                 bool                    `json:"isBase64Encoded"`
+}
+
+// DecodeBody returns the message body, decoding it when the client sent a
+// binary frame.
+func (r WebSocketRequest) DecodeBody() ([]byte, error) {
+	if !r.IsBase64Encoded {
+		return []byte(r.Body), nil
+	}
+	body, err := base64.StdEncoding.DecodeString(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 body: %w", err)
+	}
+	return body, nil
+}
+
+// WebSocketRequestContext describes the connection and the event. DomainName
+// and Stage locate the API's @connections endpoint.
+type WebSocketRequestContext struct {
+	RouteKey          string             `json:"routeKey"`
+	EventType         WebSocketEventType `json:"eventType"`
+	ConnectionID      string             `json:"connectionId"`
+	ConnectedAt       int64              `json:"connectedAt"`
+	MessageID         string             `json:"messageId,omitempty"`
+	MessageDirection  string             `json:"messageDirection"`
+	ExtendedRequestID string             `json:"extendedRequestId"`
+	RequestID         string             `json:"requestId"`
+	RequestTime       string             `json:"requestTime"`
+	RequestTimeEpoch  int64              `json:"requestTimeEpoch"`
+	DomainName        string             `json:"domainName"`
+	Stage             string             `json:"stage"`
+	APIID             string             `json:"apiId"`
+	Identity          WebSocketIdentity  `json:"identity"`
+	Authorizer        map[string]any     `json:"authorizer,omitempty"`
+
+	// DisconnectStatusCode and DisconnectReason are set for $disconnect
+	// events with the close frame's status code and reason.
+	DisconnectStatusCode int    `json:"disconnectStatusCode,omitempty"`
+	DisconnectReason     string `json:"disconnectReason,omitempty"`
+}
+
+// WebSocketIdentity contains the client's identity.
+type WebSocketIdentity struct {
+	SourceIP  string `json:"sourceIp"`
+	UserAgent string `json:"userAgent,omitempty"`
+}
+
+// WebSocketResponse is a route's response. For $connect, a non-2xx
+// StatusCode rejects the connection. For other routes with a route
+// response, Body is sent to the client.
+type WebSocketResponse struct {
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            string            `json:"body,omitempty"`
+	IsBase64Encoded bool              `json:"isBase64Encoded,omitempty"`
+}
@@ -0,0 +1,50 @@
+package vokerevents
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocketRequest_ConnectFixture(t *testing.T) {
+	var event WebSocketRequest
+	readEventFixture(t, "websocket-connect-event.json", &event)
+
+	rc := event.RequestContext
+	assert.Equal(t, WebSocketConnect, rc.EventType)
+	assert.Equal(t, "$connect", rc.RouteKey)
+	assert.Equal(t, "L0SM9cOFvHcCIhw=", rc.ConnectionID)
+	assert.Equal(t, "abcdef123.execute-api.us-east-1.amazonaws.com", rc.DomainName)
+	assert.Equal(t, "production", rc.Stage)
+	assert.Equal(t, int64(1727784000000), rc.ConnectedAt)
+	assert.Equal(t, "192.0.2.1", rc.Identity.SourceIP)
+	assert.Equal(t, "lobby", event.QueryStringParameters["room"])
+	assert.Equal(t, "13", event.Headers["Sec-WebSocket-Version"])
+}
+
+func TestWebSocketRequest_MessageFixture(t *testing.T) {
+	var event WebSocketRequest
+	readEventFixture(t, "websocket-message-event.json", &event)
+
+	assert.Equal(t, WebSocketMessage, event.RequestContext.EventType)
+	assert.Equal(t, "sendmessage", event.RequestContext.RouteKey)
+	assert.Equal(t, "GXLKJfX4FAMCKUA=", event.RequestContext.MessageID)
+	assert.Nil(t, event.Headers)
+
+	body, err := event.DecodeBody()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"action":"sendmessage","message":"hello"}`, string(body))
+}
+
+func TestWebSocketRequest_DecodeBody(t *testing.T) {
+	event := WebSocketRequest{Body: base64.StdEncoding.EncodeToString([]byte{0, 1, 2}), IsBase64Encoded: true}
+	body, err := event.DecodeBody()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2}, body)
+
+	event.Body = "not base64!"
+	_, err = event.DecodeBody()
+	assert.ErrorContains(t, err, "failed to decode base64 body")
+}
@@ -90,11 +90,8 @@ func (a *ALB) Request(ctx context.Context, event ALBRequest) (*http.Request, err
 		}
 	}
 
-	xff := headerValue(event.Headers, event.MultiValueHeaders, "x-forwarded-for")
-	if xff != "" {
-		// x-forwarded-for may contain multiple IPs; the first is the client
-		ip, _, _ := strings.Cut(xff, ",")
-		req.RemoteAddr = strings.TrimSpace(ip)
+	if addr := albClientAddr(event.Headers, event.MultiValueHeaders); addr != "" {
+		req.RemoteAddr = addr
 	}
 
 	req.RequestURI = uri
@@ -102,6 +99,27 @@ func (a *ALB) Request(ctx context.Context, event ALBRequest) (*http.Request, err
 	return req, nil
 }
 
+// albClientAddr returns the address of the client that connected to the load
+// balancer: the last X-Forwarded-For entry. ALB appends that address to any
+// X-Forwarded-For header the client sent, so earlier entries are supplied by
+// the client and can't be trusted.
+func albClientAddr(single map[string]string, multi map[string][]string) string {
+	var xff string
+	if len(multi) > 0 {
+		for k, vals := range multi {
+			if strings.EqualFold(k, "x-forwarded-for") && len(vals) > 0 {
+				xff = vals[len(vals)-1]
+			}
+		}
+	} else {
+		xff = headerValue(single, nil, "x-forwarded-for")
+	}
+	if i := strings.LastIndexByte(xff, ','); i >= 0 {
+		xff = xff[i+1:]
+	}
+	return strings.TrimSpace(xff)
+}
+
 func buildALBRawQuery(single map[string]string, multi map[string][]string) string {
 	// ALB passes URL-encoded query parameter values through without decoding
 	// them first, so this preserves event values as-is instead of applying
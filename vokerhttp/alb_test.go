@@ -129,7 +129,9 @@ func TestALBRequest_MultipleForwardedIPs(t *testing.T) {
 	req, err := adapter.Request(context.Background(), event)
 	require.NoError(t, err)
 
-	assert.Equal(t, "72.21.198.66", req.RemoteAddr)
+	// ALB appends the address that connected to it; earlier entries come
+	// from the client.
+	assert.Equal(t, "192.168.1.1", req.RemoteAddr)
 }
 
 func TestALBRequest_InvalidBase64Body(t *testing.T) {
@@ -165,7 +167,7 @@ func TestALBRequest_MultiValueHeadersAndQuery(t *testing.T) {
 
 	assert.Equal(t, "my-alb-123.us-east-1.elb.amazonaws.com", req.URL.Host)
 	assert.Equal(t, "https", req.URL.Scheme)
-	assert.Equal(t, "72.21.198.66", req.RemoteAddr)
+	assert.Equal(t, "10.0.0.1", req.RemoteAddr)
 	assert.Equal(t, []string{"text/html", "application/json"}, req.Header.Values("Accept"))
 	assert.ElementsMatch(t, []string{"red", "blue"}, req.URL.Query()["color"])
 }
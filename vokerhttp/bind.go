@@ -0,0 +1,365 @@
+package vokerhttp
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldError describes a request value that could not be bound to a field.
+type FieldError struct {
+	// Source is where the value comes from: "path", "query", "header",
+	// "form", or "body".
+	Source string
+
+	// Name is the parameter or header name, or the JSON field path for the
+	// body. It is empty when a body error isn't specific to one field.
+	Name string
+
+	// Message describes the problem.
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if e.Name == "" {
+		return e.Source + ": " + e.Message
+	}
+	return fmt.Sprintf("%s %q: %s", e.Source, e.Name, e.Message)
+}
+
+// BindError is returned by [Bind] when request values are missing or
+// invalid. It lists every field that failed, so a handler can report them
+// all at once.
+type BindError struct {
+	Fields []FieldError
+}
+
+func (e *BindError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Error()
+	}
+	return "invalid request: " + strings.Join(messages, "; ")
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// Bind populates a T from r. A JSON body is decoded into T first, using its
+// json tags, and then fields tagged with path, query, header, or form are
+// set from the request's path parameters, query string, headers, and form
+// body:
+//
+//	type UpdateOrder struct {
+//	    ID     string   `path:"id" json:"-"`
+//	    DryRun bool     `query:"dryRun" json:"-"`
+//	    Tenant string   `header:"X-Tenant-Id,required" json:"-"`
+//	    Tags   []string `query:"tag" json:"-"`
+//	    Status string   `json:"status"`
+//	}
+//
+//	order, err := vokerhttp.Bind[UpdateOrder](r)
+//
+// Path parameters come from r.PathValue, so routes registered on an
+// http.ServeMux work, and fall back to the pathParameters of the API Gateway
+// or Function URL event. Tagged fields may be strings, bools, numbers,
+// time.Durations, encoding.TextUnmarshalers, pointers to those (left nil
+// when the value is absent), or slices of them, which receive every value of
+// a repeated parameter. Adding ",required" to a tag rejects requests without
+// the value. Anonymous struct fields are bound as if their fields were in T.
+//
+// The body is decoded only when it is non-empty. A JSON Content-Type, or
+// none, decodes it as JSON; application/x-www-form-urlencoded and
+// multipart/form-data bodies are parsed with [ParseForm] and bind form
+// fields instead. Form fields of type *multipart.FileHeader or
+// []*multipart.FileHeader receive multipart file parts. The adapters have
+// already decoded base64 bodies.
+// Bind reads r.Body and replaces it with a copy, so the handler can read it
+// again.
+//
+// When values are missing or invalid, Bind returns a *[BindError] that lists
+// every failing field, along with the partially populated T.
+func Bind[T any](r *http.Request) (T, error) {
+	var out T
+	target := reflect.ValueOf(&out).Elem()
+	if target.Kind() != reflect.Struct {
+		return out, fmt.Errorf("vokerhttp: Bind requires a struct type, got %s", target.Type())
+	}
+
+	var fields []FieldError
+	form, field, err := bindBody(r, &out)
+	if err != nil {
+		return out, err
+	} else if field != nil {
+		fields = append(fields, *field)
+	}
+
+	b := binder{request: r, pathParameters: eventPathParameters(r.Context()), form: form}
+	if err := b.bindStruct(target, &fields); err != nil {
+		return out, err
+	}
+	if len(fields) > 0 {
+		return out, &BindError{Fields: fields}
+	}
+	return out, nil
+}
+
+// BindEvent builds the *http.Request adapter would give an http.Handler for
+// event and binds it with [Bind], for typed handlers registered with
+// [voker.Start] that receive HTTP events directly:
+//
+//	func handler(ctx context.Context, event vokerhttp.APIGatewayV2Request) (vokerhttp.APIGatewayV2Response, error) {
+//	    order, err := vokerhttp.BindEvent[UpdateOrder](ctx, event, &vokerhttp.APIGatewayV2{})
+//	    ...
+//	}
+func BindEvent[T, E, R any](ctx context.Context, event E, adapter Adapter[E, R]) (T, error) {
+	req, err := adapter.Request(ctx, event)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("failed to build http request: %w", err)
+	}
+	return Bind[T](req.WithContext(context.WithValue(req.Context(), eventContextKey{}, event)))
+}
+
+// bindBody decodes a JSON request body into out, or parses a form body. It
+// returns a FieldError for bodies the client got wrong and an error when the
+// body can't be read.
+func bindBody(r *http.Request, out any) (*multipart.Form, *FieldError, error) {
+	body, err := readBody(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(body) == 0 {
+		return nil, nil, nil
+	}
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		form, err := parseFormBody(body, contentType)
+		if err != nil {
+			return nil, &FieldError{Source: "body", Message: err.Error()}, nil
+		}
+		if form != nil {
+			return form, nil, nil
+		}
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+			return nil, &FieldError{Source: "body", Message: fmt.Sprintf("unsupported content type %q", mediaType)}, nil
+		}
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return nil, &FieldError{Source: "body", Name: typeErr.Field, Message: fmt.Sprintf("cannot decode JSON %s into %s", typeErr.Value, typeErr.Type)}, nil
+		}
+		return nil, &FieldError{Source: "body", Message: err.Error()}, nil
+	}
+	return nil, nil, nil
+}
+
+// eventPathParameters returns the path parameters of the Lambda event that
+// produced the request, if the event carries any.
+func eventPathParameters(ctx context.Context) map[string]string {
+	switch event := ctx.Value(eventContextKey{}).(type) {
+	case APIGatewayV2Request:
+		return event.PathParameters
+	case FunctionURLRequest:
+		return event.PathParameters
+	case APIGatewayV1Request:
+		return event.PathParameters
+	}
+	return nil
+}
+
+type binder struct {
+	request        *http.Request
+	pathParameters map[string]string
+	query          map[string][]string
+	form           *multipart.Form
+}
+
+func (b *binder) bindStruct(v reflect.Value, fields *[]FieldError) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if err := b.bindStruct(v.Field(i), fields); err != nil {
+				return err
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		for _, source := range []string{"path", "query", "header", "form"} {
+			tag, ok := sf.Tag.Lookup(source)
+			if !ok {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if source == "form" && (sf.Type == fileHeaderType || sf.Type == fileHeadersType) {
+				b.bindFiles(v.Field(i), name, opts, fields)
+				continue
+			}
+			values := b.values(source, name)
+			if len(values) == 0 {
+				if opts == "required" {
+					*fields = append(*fields, FieldError{Source: source, Name: name, Message: "is required"})
+				}
+				continue
+			}
+			if !bindable(sf.Type) {
+				return fmt.Errorf("vokerhttp: cannot bind %s field %s of type %s", source, sf.Name, sf.Type)
+			}
+			if err := setField(v.Field(i), values); err != nil {
+				*fields = append(*fields, FieldError{Source: source, Name: name, Message: err.Error()})
+			}
+		}
+	}
+	return nil
+}
+
+func (b *binder) values(source, name string) []string {
+	switch source {
+	case "path":
+		if value := b.request.PathValue(name); value != "" {
+			return []string{value}
+		}
+		if value := b.pathParameters[name]; value != "" {
+			return []string{value}
+		}
+		return nil
+	case "query":
+		if b.query == nil {
+			b.query = b.request.URL.Query()
+		}
+		return b.query[name]
+	case "form":
+		if b.form == nil {
+			return nil
+		}
+		return b.form.Value[name]
+	default:
+		return b.request.Header.Values(name)
+	}
+}
+
+// bindFiles sets a *multipart.FileHeader or []*multipart.FileHeader field to
+// the file parts named name.
+func (b *binder) bindFiles(v reflect.Value, name, opts string, fields *[]FieldError) {
+	var files []*multipart.FileHeader
+	if b.form != nil {
+		files = b.form.File[name]
+	}
+	if len(files) == 0 {
+		if opts == "required" {
+			*fields = append(*fields, FieldError{Source: "form", Name: name, Message: "is required"})
+		}
+		return
+	}
+	if v.Type() == fileHeaderType {
+		v.Set(reflect.ValueOf(files[0]))
+		return
+	}
+	v.Set(reflect.ValueOf(files))
+}
+
+// bindable reports whether setField can set a field of type t.
+func bindable(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+		if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+			return true
+		}
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setField parses values into v, which bindable accepted.
+func setField(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setField(elem.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	if v.Kind() == reflect.Slice && !reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return setValue(v, values[0])
+}
+
+func setValue(v reflect.Value, value string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		v.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeFor[time.Duration]() {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", value)
+			}
+			v.SetInt(int64(parsed))
+			return nil
+		}
+		parsed, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		v.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", value)
+		}
+		v.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		v.SetFloat(parsed)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package vokerhttp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bindPaging struct {
+	Limit  *int   `query:"limit" json:"-"`
+	Cursor string `query:"cursor" json:"-"`
+}
+
+type bindOrder struct {
+	bindPaging
+	ID      string        `path:"id" json:"-"`
+	DryRun  bool          `query:"dryRun" json:"-"`
+	Tags    []string      `query:"tag" json:"-"`
+	Timeout time.Duration `query:"timeout" json:"-"`
+	Tenant  string        `header:"X-Tenant-Id,required" json:"-"`
+	Client  netip.Addr    `header:"X-Client-Ip" json:"-"`
+	Status  string        `json:"status"`
+	Amount  float64       `json:"amount"`
+}
+
+func TestBind(t *testing.T) {
+	mux := http.NewServeMux()
+	var order bindOrder
+	var bindErr error
+	var body string
+	mux.HandleFunc("PUT /orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		order, bindErr = Bind[bindOrder](r)
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/orders/o-1?dryRun=true&tag=a&tag=b&limit=10&timeout=1.5s", strings.NewReader(`{"status":"shipped","amount":12.5}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Tenant-Id", "acme")
+	req.Header.Set("X-Client-Ip", "10.0.0.1")
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NoError(t, bindErr)
+	assert.Equal(t, "o-1", order.ID)
+	assert.True(t, order.DryRun)
+	assert.Equal(t, []string{"a", "b"}, order.Tags)
+	assert.Equal(t, 10, *order.Limit)
+	assert.Empty(t, order.Cursor)
+	assert.Equal(t, 1500*time.Millisecond, order.Timeout)
+	assert.Equal(t, "acme", order.Tenant)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), order.Client)
+	assert.Equal(t, "shipped", order.Status)
+	assert.Equal(t, 12.5, order.Amount)
+	assert.Equal(t, `{"status":"shipped","amount":12.5}`, body, "body can be read again")
+}
+
+func TestBind_FieldErrors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/orders?dryRun=maybe&limit=ten&timeout=soon", strings.NewReader(`{"status":"new","amount":"lots"}`))
+	req.Header.Set("X-Client-Ip", "not-an-ip")
+
+	order, err := Bind[bindOrder](req)
+	var bindErr *BindError
+	require.ErrorAs(t, err, &bindErr)
+	assert.Equal(t, []FieldError{
+		{Source: "body", Name: "amount", Message: bindErr.Fields[0].Message},
+		{Source: "query", Name: "limit", Message: `invalid integer "ten"`},
+		{Source: "query", Name: "dryRun", Message: `invalid boolean "maybe"`},
+		{Source: "query", Name: "timeout", Message: `invalid duration "soon"`},
+		{Source: "header", Name: "X-Tenant-Id", Message: "is required"},
+		{Source: "header", Name: "X-Client-Ip", Message: bindErr.Fields[5].Message},
+	}, bindErr.Fields)
+	assert.NotEmpty(t, bindErr.Fields[0].Message)
+	assert.Contains(t, err.Error(), `invalid request: body "amount": `)
+	assert.Contains(t, err.Error(), `; header "X-Tenant-Id": is required`)
+	assert.Nil(t, order.Limit)
+}
+
+func TestBind_Body(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"no content type", "", `{"status":"new"}`, ""},
+		{"vendor JSON", "application/vnd.api+json", `{"status":"new"}`, ""},
+		{"text", "text/plain", "status=new", `body: unsupported content type "text/plain"`},
+		{"malformed", "application/json", `{"status":`, "body: "},
+		{"empty", "application/json", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			type status struct {
+				Status string `json:"status"`
+			}
+			got, err := Bind[status](req)
+			if tt.want == "" {
+				require.NoError(t, err)
+				if tt.body != "" {
+					assert.Equal(t, "new", got.Status)
+				}
+				return
+			}
+			var bindErr *BindError
+			require.ErrorAs(t, err, &bindErr)
+			require.Len(t, bindErr.Fields, 1)
+			assert.Contains(t, bindErr.Fields[0].Error(), tt.want)
+		})
+	}
+}
+
+func TestBind_ProgrammerErrors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?when=now", nil)
+
+	_, err := Bind[string](req)
+	assert.EqualError(t, err, "vokerhttp: Bind requires a struct type, got string")
+
+	_, err = Bind[struct {
+		When map[string]string `query:"when"`
+	}](req)
+	assert.EqualError(t, err, "vokerhttp: cannot bind query field When of type map[string]string")
+	assert.False(t, errors.As(err, new(*BindError)))
+}
+
+func TestBindEvent(t *testing.T) {
+	event := newTestFunctionURLRequest()
+	event.RequestContext.HTTP.Method = http.MethodPut
+	event.RawQueryString = "tag=x"
+	event.PathParameters = map[string]string{"id": "o-2"}
+	event.Headers["x-tenant-id"] = "acme"
+	event.Body = base64.StdEncoding.EncodeToString([]byte(`{"status":"paid"}`))
+	event.IsBase64Encoded = true
+
+	order, err := BindEvent[bindOrder](context.Background(), event, &FunctionURL{})
+	require.NoError(t, err)
+	assert.Equal(t, "o-2", order.ID)
+	assert.Equal(t, []string{"x"}, order.Tags)
+	assert.Equal(t, "acme", order.Tenant)
+	assert.Equal(t, "paid", order.Status)
+}
@@ -0,0 +1,286 @@
+package vokerhttp
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultMinCompressSize is the smallest response body [Compress]
+// compresses by default. Smaller bodies barely shrink and can grow once
+// base64-encoded.
+const defaultMinCompressSize = 1024
+
+type contentEncoder struct {
+	encoding  string
+	newWriter func(w io.Writer) io.WriteCloser
+}
+
+type compressOptions struct {
+	encoders    []contentEncoder
+	gzipLevel   int
+	minSize     int
+	gzipWriters sync.Pool
+}
+
+// CompressOption configures [Compress].
+type CompressOption func(*compressOptions)
+
+// WithCompressionLevel sets the gzip compression level, from
+// gzip.BestSpeed to gzip.BestCompression. The default is
+// gzip.DefaultCompression.
+func WithCompressionLevel(level int) CompressOption {
+	return func(o *compressOptions) {
+		o.gzipLevel = level
+	}
+}
+
+// WithMinCompressSize sets the smallest response body that is compressed.
+// The default is 1024 bytes.
+func WithMinCompressSize(n int) CompressOption {
+	return func(o *compressOptions) {
+		o.minSize = n
+	}
+}
+
+// WithEncoder adds a content coding such as "br" or "zstd". Encoders are
+// preferred over gzip, in the order they are added, when the client accepts
+// them. If the writer newWriter returns has a Flush() error method, it is
+// called when the handler flushes a streaming response.
+//
+//	vokerhttp.WithEncoder("br", func(w io.Writer) io.WriteCloser {
+//	    return brotli.NewWriterLevel(w, brotli.DefaultCompression)
+//	})
+func WithEncoder(encoding string, newWriter func(w io.Writer) io.WriteCloser) CompressOption {
+	return func(o *compressOptions) {
+		o.encoders = append(o.encoders, contentEncoder{encoding: strings.ToLower(encoding), newWriter: newWriter})
+	}
+}
+
+// Compress compresses text responses, such as JSON and HTML, with the best
+// content coding the request's Accept-Encoding header allows. Lambda
+// limits buffered responses to 6 MB, and large JSON bodies often compress
+// by 80% or more.
+//
+//	vokerhttp.Start(vokerhttp.Compress(mux), &vokerhttp.APIGatewayV2{})
+//
+// Responses that already set Content-Encoding, aren't text according to
+// their Content-Type, or are smaller than the minimum size are sent
+// unchanged. The adapters base64-encode compressed bodies and set
+// isBase64Encoded, which API Gateway, ALB, and Function URLs decode before
+// sending the bytes to the client.
+func Compress(next http.Handler, opts ...CompressOption) http.Handler {
+	options := &compressOptions{gzipLevel: gzip.DefaultCompression, minSize: defaultMinCompressSize}
+	for _, opt := range opts {
+		opt(options)
+	}
+	options.encoders = append(options.encoders, contentEncoder{encoding: "gzip", newWriter: options.newGzipWriter})
+	available := make([]string, len(options.encoders))
+	for i, encoder := range options.encoders {
+		available[i] = encoder.encoding
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Values("Accept-Encoding"), available)
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressResponseWriter{ResponseWriter: w, options: options}
+		for _, encoder := range options.encoders {
+			if encoder.encoding == encoding {
+				cw.encoder = encoder
+				break
+			}
+		}
+		defer cw.finish()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// pooledGzipWriter returns its gzip.Writer to the pool when closed.
+type pooledGzipWriter struct {
+	*gzip.Writer
+	pool *sync.Pool
+}
+
+func (w pooledGzipWriter) Close() error {
+	err := w.Writer.Close()
+	w.pool.Put(w.Writer)
+	return err
+}
+
+func (o *compressOptions) newGzipWriter(w io.Writer) io.WriteCloser {
+	if gz, ok := o.gzipWriters.Get().(*gzip.Writer); ok {
+		gz.Reset(w)
+		return pooledGzipWriter{Writer: gz, pool: &o.gzipWriters}
+	}
+	gz, err := gzip.NewWriterLevel(w, o.gzipLevel)
+	if err != nil {
+		gz = gzip.NewWriter(w)
+	}
+	return pooledGzipWriter{Writer: gz, pool: &o.gzipWriters}
+}
+
+// negotiateEncoding returns the first of available that the Accept-Encoding
+// values accept with a non-zero quality, or "" if none are.
+func negotiateEncoding(acceptEncoding []string, available []string) string {
+	qualities := make(map[string]float64)
+	for _, value := range acceptEncoding {
+		for part := range strings.SplitSeq(value, ",") {
+			coding, params, _ := strings.Cut(part, ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "" {
+				continue
+			}
+			q := 1.0
+			if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+			qualities[coding] = q
+		}
+	}
+
+	for _, encoding := range available {
+		q, ok := qualities[encoding]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > 0 {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressResponseWriter buffers the start of the body until it knows
+// whether the response is worth compressing, then either compresses the
+// rest or passes it through.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	options *compressOptions
+	encoder contentEncoder
+
+	statusCode  int
+	wroteHeader bool
+	decided     bool
+	buf         []byte
+	writer      io.WriteCloser
+}
+
+func (w *compressResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	if statusCode >= 100 && statusCode <= 199 {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	w.statusCode = statusCode
+	w.wroteHeader = true
+}
+
+func (w *compressResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.decided {
+		if w.writer != nil {
+			return w.writer.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.options.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends the buffered body, compressing it if its type allows even
+// when it is below the minimum size, since a streaming response's later
+// writes can't be known yet.
+func (w *compressResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		if w.decide(len(w.buf) > 0) != nil {
+			return
+		}
+	}
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		if flusher.Flush() != nil {
+			return
+		}
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide writes the header and the buffered body, compressing them when
+// large is true and the response is compressible.
+func (w *compressResponseWriter) decide(large bool) error {
+	w.decided = true
+	header := w.Header()
+	if len(w.buf) > 0 && header.Get("Content-Type") == "" && len(header.Values("Content-Encoding")) == 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	compress := large &&
+		w.statusCode != http.StatusNoContent &&
+		w.statusCode != http.StatusNotModified &&
+		w.statusCode != http.StatusPartialContent &&
+		len(header.Values("Content-Encoding")) == 0 &&
+		isTextContent(header.Get("Content-Type"))
+	if compress {
+		header.Set("Content-Encoding", w.encoder.encoding)
+		header.Del("Content-Length")
+		header.Del("Accept-Ranges")
+		w.writer = w.encoder.newWriter(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.statusCode)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.writer != nil {
+		_, err = w.writer.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// finish writes anything still buffered and completes the compressed
+// stream once the handler returns.
+func (w *compressResponseWriter) finish() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		_ = w.decide(len(w.buf) >= w.options.minSize)
+	}
+	if w.writer != nil {
+		_ = w.writer.Close()
+	}
+}
@@ -0,0 +1,182 @@
+package vokerhttp
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var largeJSON = `{"items":[` + strings.Repeat(`{"id":1,"name":"widget"},`, 200) + `{}]}`
+
+func gunzip(t *testing.T, data []byte) string {
+	t.Helper()
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func serveCompressed(t *testing.T, handler http.Handler, acceptEncoding string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func jsonHandler(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "999")
+		w.WriteHeader(http.StatusCreated)
+		// Write in pieces so the body crosses the minimum size mid-stream.
+		for chunk := range strings.SplitSeq(body, ",") {
+			_, _ = io.WriteString(w, chunk+",")
+		}
+	})
+}
+
+func TestCompress_Gzip(t *testing.T) {
+	resp := serveCompressed(t, Compress(jsonHandler(largeJSON)), "deflate, gzip;q=0.8")
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", resp.Header.Get("Vary"))
+	assert.Empty(t, resp.Header.Get("Content-Length"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Less(t, len(body), len(largeJSON)/4)
+	assert.Equal(t, largeJSON+",", gunzip(t, body))
+}
+
+func TestCompress_PassThrough(t *testing.T) {
+	binary := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(bytes.Repeat([]byte{0x89}, 4096))
+	})
+	preEncoded := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "br")
+		_, _ = w.Write(bytes.Repeat([]byte{'x'}, 4096))
+	})
+
+	tests := []struct {
+		name           string
+		handler        http.Handler
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{"no Accept-Encoding", jsonHandler(largeJSON), "", ""},
+		{"gzip refused", jsonHandler(largeJSON), "gzip;q=0, identity", ""},
+		{"unsupported coding", jsonHandler(largeJSON), "compress", ""},
+		{"small body", jsonHandler(`{"ok":true}`), "gzip", ""},
+		{"binary content", binary, "gzip", ""},
+		{"already encoded", preEncoded, "gzip", "br"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := serveCompressed(t, Compress(tt.handler), tt.acceptEncoding)
+			assert.Equal(t, tt.wantEncoding, resp.Header.Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", resp.Header.Get("Vary"))
+		})
+	}
+}
+
+func TestCompress_SniffsContentType(t *testing.T) {
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "<html>"+strings.Repeat("hello ", 500)+"</html>")
+	}))
+	resp := serveCompressed(t, handler, "*")
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+}
+
+func TestCompress_PrefersAddedEncoders(t *testing.T) {
+	deflate := WithEncoder("deflate", func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.BestSpeed)
+		return fw
+	})
+
+	resp := serveCompressed(t, Compress(jsonHandler(largeJSON), deflate), "gzip, deflate")
+	assert.Equal(t, "deflate", resp.Header.Get("Content-Encoding"))
+	body, err := io.ReadAll(flate.NewReader(resp.Body))
+	require.NoError(t, err)
+	assert.Equal(t, largeJSON+",", string(body))
+
+	resp = serveCompressed(t, Compress(jsonHandler(largeJSON), deflate), "gzip")
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+}
+
+func TestCompress_Options(t *testing.T) {
+	resp := serveCompressed(t, Compress(jsonHandler(`{"ok":true}`), WithMinCompressSize(0), WithCompressionLevel(gzip.BestSpeed)), "gzip")
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, `{"ok":true},`, gunzip(t, body))
+}
+
+func TestCompress_Flush(t *testing.T) {
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		_, _ = io.WriteString(w, "data: second\n\n")
+	}))
+	resp := serveCompressed(t, handler, "gzip")
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, "data: first\n\ndata: second\n\n", gunzip(t, body))
+}
+
+func TestCompress_APIGatewayV2Response(t *testing.T) {
+	handler := eventHandler(Compress(jsonHandler(largeJSON)), &APIGatewayV2{})
+	event := newTestAPIGatewayV2Request()
+	event.Headers["accept-encoding"] = "gzip"
+
+	response, err := handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.True(t, response.IsBase64Encoded)
+	assert.Equal(t, "gzip", response.Headers["content-encoding"])
+	assert.Equal(t, "application/json", response.Headers["content-type"])
+
+	compressed, err := base64.StdEncoding.DecodeString(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, largeJSON+",", gunzip(t, compressed))
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	available := []string{"br", "gzip"}
+	tests := []struct {
+		accept []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"gzip"}, "gzip"},
+		{[]string{"gzip, br"}, "br"},
+		{[]string{"GZIP", "BR;q=0"}, "gzip"},
+		{[]string{"*"}, "br"},
+		{[]string{"*;q=0, gzip"}, "gzip"},
+		{[]string{"identity"}, ""},
+		{[]string{"gzip; q=0.5"}, "gzip"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, negotiateEncoding(tt.accept, available), "%q", tt.accept)
+	}
+}
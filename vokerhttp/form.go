@@ -0,0 +1,102 @@
+package vokerhttp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// maxFormMemory is the number of bytes of multipart file parts kept in
+// memory before ReadForm spills them to temporary files. Lambda request
+// bodies are at most 6 MB, so in practice every part stays in memory.
+const maxFormMemory = 32 << 20
+
+var (
+	fileHeaderType  = reflect.TypeFor[*multipart.FileHeader]()
+	fileHeadersType = reflect.TypeFor[[]*multipart.FileHeader]()
+)
+
+// ParseForm decodes an application/x-www-form-urlencoded or
+// multipart/form-data request body, as posted by webhook providers such as
+// Twilio and Slack. Form values are in Value; multipart file parts are in
+// File. The adapters have already decoded base64 bodies.
+//
+// Unlike http.Request.ParseForm, query parameters are not merged into the
+// values, and r.Body is replaced with a copy, so signature verification or
+// the handler can read the raw body again.
+func ParseForm(r *http.Request) (*multipart.Form, error) {
+	body, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+	form, err := parseFormBody(body, r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	if form == nil {
+		return nil, fmt.Errorf("vokerhttp: unsupported form content type %q", r.Header.Get("Content-Type"))
+	}
+	return form, nil
+}
+
+// ParseEventForm builds the *http.Request adapter would give an
+// http.Handler for event and parses its body with [ParseForm], for typed
+// handlers registered with [voker.Start] that receive HTTP events directly:
+//
+//	func handler(ctx context.Context, event vokerhttp.FunctionURLRequest) (vokerhttp.FunctionURLResponse, error) {
+//	    form, err := vokerhttp.ParseEventForm(ctx, event, &vokerhttp.FunctionURL{})
+//	    ...
+//	    from := form.Value.Get("From")
+//	}
+func ParseEventForm[E, R any](ctx context.Context, event E, adapter Adapter[E, R]) (*multipart.Form, error) {
+	req, err := adapter.Request(ctx, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build http request: %w", err)
+	}
+	return ParseForm(req)
+}
+
+// readBody reads r.Body and replaces it with a copy that can be read again.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return body, nil
+}
+
+// parseFormBody decodes body according to contentType. It returns a nil
+// form when contentType is not a form content type.
+func parseFormBody(body []byte, contentType string) (*multipart.Form, error) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse form body: %w", err)
+		}
+		return &multipart.Form{Value: values, File: map[string][]*multipart.FileHeader{}}, nil
+	case "multipart/form-data":
+		boundary := params["boundary"]
+		if boundary == "" {
+			return nil, fmt.Errorf("failed to parse multipart body: %w", http.ErrMissingBoundary)
+		}
+		form, err := multipart.NewReader(bytes.NewReader(body), boundary).ReadForm(maxFormMemory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse multipart body: %w", err)
+		}
+		return form, nil
+	}
+	return nil, nil
+}
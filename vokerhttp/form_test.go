@@ -0,0 +1,133 @@
+package vokerhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMultipartBody(t *testing.T) (string, []byte) {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	require.NoError(t, w.WriteField("channel", "general"))
+	require.NoError(t, w.WriteField("tag", "a"))
+	require.NoError(t, w.WriteField("tag", "b"))
+	part, err := w.CreateFormFile("attachment", "report.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("id,total\n1,10\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return w.FormDataContentType(), body.Bytes()
+}
+
+func TestParseForm_URLEncoded(t *testing.T) {
+	const body = "From=%2B15551234567&Body=Hello+there&Body=again"
+	req := httptest.NewRequest(http.MethodPost, "/sms?From=ignored", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	form, err := ParseForm(req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"+15551234567"}, form.Value["From"], "query parameters are not merged")
+	assert.Equal(t, []string{"Hello there", "again"}, form.Value["Body"])
+	assert.Empty(t, form.File)
+
+	raw, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(raw), "body can be read again")
+}
+
+func TestParseForm_Multipart(t *testing.T) {
+	contentType, body := newMultipartBody(t)
+	req := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+
+	form, err := ParseForm(req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"general"}, form.Value["channel"])
+	require.Len(t, form.File["attachment"], 1)
+	file := form.File["attachment"][0]
+	assert.Equal(t, "report.csv", file.Filename)
+	f, err := file.Open()
+	require.NoError(t, err)
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "id,total\n1,10\n", string(content))
+}
+
+func TestParseForm_Errors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	_, err := ParseForm(req)
+	assert.EqualError(t, err, `vokerhttp: unsupported form content type "application/json"`)
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("x"))
+	req.Header.Set("Content-Type", "multipart/form-data")
+	_, err = ParseForm(req)
+	assert.ErrorIs(t, err, http.ErrMissingBoundary)
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a=%zz"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	_, err = ParseForm(req)
+	assert.ErrorContains(t, err, "failed to parse form body")
+}
+
+func TestParseEventForm_Base64(t *testing.T) {
+	contentType, body := newMultipartBody(t)
+	event := newTestFunctionURLRequest()
+	event.RequestContext.HTTP.Method = http.MethodPost
+	event.Headers["content-type"] = contentType
+	event.Body = base64.StdEncoding.EncodeToString(body)
+	event.IsBase64Encoded = true
+
+	form, err := ParseEventForm(context.Background(), event, &FunctionURL{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, form.Value["tag"])
+	require.Len(t, form.File["attachment"], 1)
+	assert.Equal(t, "report.csv", form.File["attachment"][0].Filename)
+}
+
+type bindUpload struct {
+	Channel    string                  `form:"channel,required"`
+	Tags       []string                `form:"tag"`
+	Attachment *multipart.FileHeader   `form:"attachment,required"`
+	Extra      []*multipart.FileHeader `form:"extra"`
+	Tenant     string                  `header:"X-Tenant-Id"`
+}
+
+func TestBind_Form(t *testing.T) {
+	contentType, body := newMultipartBody(t)
+	req := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Tenant-Id", "acme")
+
+	upload, err := Bind[bindUpload](req)
+	require.NoError(t, err)
+	assert.Equal(t, "general", upload.Channel)
+	assert.Equal(t, []string{"a", "b"}, upload.Tags)
+	require.NotNil(t, upload.Attachment)
+	assert.Equal(t, "report.csv", upload.Attachment.Filename)
+	assert.Nil(t, upload.Extra)
+	assert.Equal(t, "acme", upload.Tenant)
+
+	req = httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("tag=x"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	upload, err = Bind[bindUpload](req)
+	bindErr, ok := err.(*BindError)
+	require.True(t, ok)
+	assert.Equal(t, []FieldError{
+		{Source: "form", Name: "channel", Message: "is required"},
+		{Source: "form", Name: "attachment", Message: "is required"},
+	}, bindErr.Fields)
+	assert.Equal(t, []string{"x"}, upload.Tags)
+}
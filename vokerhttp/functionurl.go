@@ -3,7 +3,9 @@ package vokerhttp
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 )
@@ -51,6 +53,66 @@ type FunctionURLRequest PayloadV2Request
 // FunctionURLResponse is the Lambda Function URL response (payload format 2.0).
 type FunctionURLResponse PayloadV2Response
 
+// FunctionURLStreamingResponse is a streamed Lambda Function URL response for
+// handlers that use [voker.Start] directly rather than net/http. It
+// implements io.Reader, so voker streams it through the Runtime API: Lambda
+// receives the status code, headers, and cookies as the streaming metadata
+// prelude, followed by Body as it is read. The function URL must use the
+// RESPONSE_STREAM invoke mode.
+//
+//	func handler(ctx context.Context, event vokerhttp.FunctionURLRequest) (*vokerhttp.FunctionURLStreamingResponse, error) {
+//	    return &vokerhttp.FunctionURLStreamingResponse{
+//	        StatusCode: http.StatusOK,
+//	        Headers:    map[string]string{"content-type": "text/plain"},
+//	        Body:       strings.NewReader("hello"),
+//	    }, nil
+//	}
+//
+// If Body also implements io.Closer, voker closes it after the response
+// finishes, including when streaming fails.
+type FunctionURLStreamingResponse struct {
+	StatusCode int
+	Headers    map[string]string
+	Cookies    []string
+	Body       io.Reader
+
+	reader io.Reader
+}
+
+// Read implements io.Reader, producing the metadata prelude followed by Body.
+func (r *FunctionURLStreamingResponse) Read(p []byte) (int, error) {
+	if r.reader == nil {
+		metadata, err := json.Marshal(StreamingResponseMetadata{
+			StatusCode: r.StatusCode,
+			Headers:    r.Headers,
+			Cookies:    r.Cookies,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal streaming response metadata: %w", err)
+		}
+		readers := []io.Reader{bytes.NewReader(metadata), bytes.NewReader(streamingMetadataDelimiter[:])}
+		if r.Body != nil {
+			readers = append(readers, r.Body)
+		}
+		r.reader = io.MultiReader(readers...)
+	}
+	return r.reader.Read(p)
+}
+
+// ContentType returns the content type Lambda requires for streamed HTTP
+// integration responses.
+func (r *FunctionURLStreamingResponse) ContentType() string {
+	return streamingIntegrationContentType
+}
+
+// Close closes Body when it implements io.Closer.
+func (r *FunctionURLStreamingResponse) Close() error {
+	if closer, ok := r.Body.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 // PayloadV2Request is the shared event shape for payload format 2.0,
 // used by both Lambda Function URLs and API Gateway v2 HTTP APIs.
 type PayloadV2Request struct {
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/hotsock/voker"
@@ -335,3 +336,52 @@ func TestFunctionURLResponse_MultiValueHeaders(t *testing.T) {
 
 	assert.Equal(t, "val1, val2", resp.Headers["x-custom"])
 }
+
+type closeTrackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *closeTrackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestFunctionURLStreamingResponse(t *testing.T) {
+	body := &closeTrackingBody{Reader: strings.NewReader("hello stream")}
+	response := &FunctionURLStreamingResponse{
+		StatusCode: http.StatusCreated,
+		Headers:    map[string]string{"content-type": "text/plain"},
+		Cookies:    []string{"a=one", "b=two"},
+		Body:       body,
+	}
+
+	// The response must satisfy the interfaces voker.Start uses to select and
+	// configure a streaming response.
+	var _ io.ReadCloser = response
+	assert.Equal(t, streamingIntegrationContentType, response.ContentType())
+
+	data, err := io.ReadAll(response)
+	require.NoError(t, err)
+	metadata, streamed := decodeStreamingResponse(t, data)
+	assert.Equal(t, StreamingResponseMetadata{
+		StatusCode: http.StatusCreated,
+		Headers:    map[string]string{"content-type": "text/plain"},
+		Cookies:    []string{"a=one", "b=two"},
+	}, metadata)
+	assert.Equal(t, "hello stream", string(streamed))
+
+	require.NoError(t, response.Close())
+	assert.True(t, body.closed)
+}
+
+func TestFunctionURLStreamingResponse_NilBody(t *testing.T) {
+	response := &FunctionURLStreamingResponse{StatusCode: http.StatusNoContent}
+
+	data, err := io.ReadAll(response)
+	require.NoError(t, err)
+	metadata, streamed := decodeStreamingResponse(t, data)
+	assert.Equal(t, http.StatusNoContent, metadata.StatusCode)
+	assert.Empty(t, streamed)
+	assert.NoError(t, response.Close())
+}
@@ -87,6 +87,18 @@ func StartStreaming[E any](handler http.Handler, adapter StreamingAdapter[E], op
 	voker.Start(streamingEventHandler(handler, adapter), opts...)
 }
 
+// Wrap adapts an http.Handler and Adapter into a typed Lambda handler for
+// [voker.Start]. Most programs should call [Start] directly; Wrap is useful
+// when composing a custom entrypoint, such as the OnHTTP handler of
+// [voker.StartAuto]:
+//
+//	voker.StartAuto(voker.AutoHandlers{
+//	    OnHTTP: voker.RawHandler(vokerhttp.Wrap(mux, &vokerhttp.APIGatewayV2{})),
+//	})
+func Wrap[E, R any](handler http.Handler, adapter Adapter[E, R]) func(context.Context, E) (R, error) {
+	return eventHandler(handler, adapter)
+}
+
 // eventHandler builds the typed Lambda handler that Start passes to
 // [voker.Start]. It is kept separate from Start so the event-to-request
 // conversion, context propagation, and response conversion can be exercised
@@ -0,0 +1,374 @@
+package vokerhttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat = "20060102T150405Z"
+
+	// DefaultMaxClockSkew is how far the X-Amz-Date of a request verified by
+	// [SigV4Verifier] may be from the current time.
+	DefaultMaxClockSkew = 5 * time.Minute
+)
+
+var (
+	errMissingSigV4 = errors.New("missing SigV4 authorization")
+	errUnknownKey   = errors.New("unknown access key")
+)
+
+// IAMCaller identifies the AWS principal that signed a request.
+type IAMCaller struct {
+	AccessKeyID    string
+	AccountID      string
+	ARN            string
+	UserID         string
+	PrincipalOrgID string
+}
+
+type iamCallerKey struct{}
+
+// IAMCallerFromContext returns the caller verified by [RequireIAM] or
+// [SigV4Verifier.Middleware].
+func IAMCallerFromContext(ctx context.Context) (*IAMCaller, bool) {
+	caller, ok := ctx.Value(iamCallerKey{}).(*IAMCaller)
+	return caller, ok
+}
+
+// RequireIAM is net/http middleware for Function URLs with the AWS_IAM auth
+// type and API Gateway routes with IAM authorization. Lambda and API Gateway
+// verify the SigV4 signature before invoking the function and describe the
+// caller in the event; RequireIAM makes that caller available to next
+// through [IAMCallerFromContext], and responds 403 Forbidden to requests
+// whose event has no IAM caller, such as those from a Function URL whose
+// auth type was changed to NONE.
+func RequireIAM(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := eventIAMCaller(r.Context())
+		if !ok {
+			writeForbidden(w)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), iamCallerKey{}, caller)))
+	})
+}
+
+func eventIAMCaller(ctx context.Context) (*IAMCaller, bool) {
+	var caller IAMCaller
+	if event, ok := EventFromContext[FunctionURLRequest](ctx); ok {
+		caller = payloadV2IAMCaller(event.RequestContext.Authorizer.IAM)
+	} else if event, ok := EventFromContext[APIGatewayV2Request](ctx); ok {
+		caller = payloadV2IAMCaller(event.RequestContext.Authorizer.IAM)
+	} else if event, ok := EventFromContext[APIGatewayV1Request](ctx); ok {
+		identity := event.RequestContext.Identity
+		caller = IAMCaller{
+			AccessKeyID:    identity.AccessKey,
+			AccountID:      identity.AccountID,
+			ARN:            identity.UserARN,
+			UserID:         identity.User,
+			PrincipalOrgID: identity.PrincipalOrgID,
+		}
+	}
+	if caller.ARN == "" {
+		return nil, false
+	}
+	return &caller, true
+}
+
+func payloadV2IAMCaller(iam PayloadV2AuthorizerIAM) IAMCaller {
+	return IAMCaller{
+		AccessKeyID:    iam.AccessKey,
+		AccountID:      iam.AccountID,
+		ARN:            iam.UserARN,
+		UserID:         iam.UserID,
+		PrincipalOrgID: iam.PrincipalOrgID,
+	}
+}
+
+// SigV4Credentials are the secret and identity behind an access key that
+// [SigV4Verifier] accepts.
+type SigV4Credentials struct {
+	SecretAccessKey string
+	Caller          IAMCaller
+}
+
+type sigV4Options struct {
+	service string
+	region  string
+	maxSkew time.Duration
+}
+
+// SigV4Option configures a [SigV4Verifier].
+type SigV4Option func(*sigV4Options)
+
+// WithSigV4Service sets the service name requests must be signed for. The
+// default is "lambda", which Function URL clients sign for.
+func WithSigV4Service(service string) SigV4Option {
+	return func(o *sigV4Options) {
+		o.service = service
+	}
+}
+
+// WithSigV4Region sets the region requests must be signed for. The default
+// is the function's region, from AWS_REGION.
+func WithSigV4Region(region string) SigV4Option {
+	return func(o *sigV4Options) {
+		o.region = region
+	}
+}
+
+// WithMaxClockSkew sets how far a request's X-Amz-Date may be from the
+// current time. The default is [DefaultMaxClockSkew].
+func WithMaxClockSkew(skew time.Duration) SigV4Option {
+	return func(o *sigV4Options) {
+		o.maxSkew = skew
+	}
+}
+
+// SigV4Verifier verifies AWS Signature Version 4 signatures itself, for
+// signed requests that reach the function through layers that don't verify
+// them, such as a proxy in front of a Function URL with the NONE auth type.
+// Verifying a signature takes the signing key's secret, so lookup returns
+// the credentials for the access key IDs the verifier accepts, or an error
+// for any other:
+//
+//	verifier := vokerhttp.NewSigV4Verifier(func(ctx context.Context, accessKeyID string) (vokerhttp.SigV4Credentials, error) {
+//	    return partnerKeys.Get(ctx, accessKeyID)
+//	})
+//	vokerhttp.Start(verifier.Middleware(mux), &vokerhttp.FunctionURL{})
+//
+// Signatures must be in the Authorization header; presigned URLs are not
+// supported.
+type SigV4Verifier struct {
+	lookup  func(ctx context.Context, accessKeyID string) (SigV4Credentials, error)
+	options sigV4Options
+	now     func() time.Time
+}
+
+// NewSigV4Verifier returns a verifier that finds signing credentials with
+// lookup.
+func NewSigV4Verifier(lookup func(ctx context.Context, accessKeyID string) (SigV4Credentials, error), opts ...SigV4Option) *SigV4Verifier {
+	options := sigV4Options{
+		service: "lambda",
+		region:  os.Getenv("AWS_REGION"),
+		maxSkew: DefaultMaxClockSkew,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &SigV4Verifier{lookup: lookup, options: options, now: time.Now}
+}
+
+type sigV4Authorization struct {
+	accessKeyID   string
+	date          string
+	region        string
+	service       string
+	signedHeaders []string
+	signature     []byte
+}
+
+// Verify checks the SigV4 signature of r and returns the caller of the
+// access key that signed it. It reads r.Body and replaces it with a copy,
+// so the handler can read it again.
+func (v *SigV4Verifier) Verify(r *http.Request) (*IAMCaller, error) {
+	auth, err := parseSigV4Authorization(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+	if auth.service != v.options.service {
+		return nil, fmt.Errorf("request is signed for service %q", auth.service)
+	}
+	if v.options.region != "" && auth.region != v.options.region {
+		return nil, fmt.Errorf("request is signed for region %q", auth.region)
+	}
+	if !slices.Contains(auth.signedHeaders, "host") || !slices.Contains(auth.signedHeaders, "x-amz-date") {
+		return nil, errors.New("host and x-amz-date must be signed")
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	signedAt, err := time.Parse(sigV4TimeFormat, amzDate)
+	if err != nil {
+		return nil, errors.New("invalid X-Amz-Date")
+	}
+	if skew := v.now().Sub(signedAt); skew > v.options.maxSkew || skew < -v.options.maxSkew {
+		return nil, errors.New("request signature has expired")
+	}
+	if amzDate[:8] != auth.date {
+		return nil, errors.New("credential date does not match X-Amz-Date")
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	bodyHash := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(bodyHash[:])
+	if declared := r.Header.Get("X-Amz-Content-Sha256"); declared == "UNSIGNED-PAYLOAD" {
+		payloadHash = declared
+	} else if declared != "" && declared != payloadHash {
+		return nil, errInvalidSignature
+	}
+
+	creds, err := v.lookup(r.Context(), auth.accessKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", errUnknownKey, auth.accessKeyID, err)
+	}
+
+	scope := strings.Join([]string{auth.date, auth.region, auth.service, "aws4_request"}, "/")
+	canonicalHash := sha256.Sum256([]byte(canonicalSigV4Request(r, auth.signedHeaders, payloadHash)))
+	stringToSign := sigV4Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := []byte("AWS4" + creds.SecretAccessKey)
+	for _, part := range []string{auth.date, auth.region, auth.service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	if !hmac.Equal(hmacSHA256(key, stringToSign), auth.signature) {
+		return nil, errInvalidSignature
+	}
+
+	caller := creds.Caller
+	caller.AccessKeyID = auth.accessKeyID
+	return &caller, nil
+}
+
+// Middleware responds 403 Forbidden, with the {"message":"Forbidden"} body
+// API Gateway returns, to requests without a valid signature. The verified
+// caller is available to next through [IAMCallerFromContext].
+func (v *SigV4Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller, err := v.Verify(r)
+		if err != nil {
+			writeForbidden(w)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), iamCallerKey{}, caller)))
+	})
+}
+
+func parseSigV4Authorization(header string) (sigV4Authorization, error) {
+	algorithm, params, ok := strings.Cut(header, " ")
+	if !ok || algorithm != sigV4Algorithm {
+		return sigV4Authorization{}, errMissingSigV4
+	}
+
+	var auth sigV4Authorization
+	var credential string
+	for param := range strings.SplitSeq(params, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch name {
+		case "Credential":
+			credential = value
+		case "SignedHeaders":
+			auth.signedHeaders = strings.Split(value, ";")
+		case "Signature":
+			auth.signature, _ = hex.DecodeString(value)
+		}
+	}
+
+	scope := strings.Split(credential, "/")
+	if len(scope) != 5 || scope[4] != "aws4_request" || len(scope[1]) != 8 || len(auth.signedHeaders) == 0 || len(auth.signature) == 0 {
+		return sigV4Authorization{}, errors.New("malformed SigV4 authorization")
+	}
+	auth.accessKeyID, auth.date, auth.region, auth.service = scope[0], scope[1], scope[2], scope[3]
+	return auth, nil
+}
+
+func canonicalSigV4Request(r *http.Request, signedHeaders []string, payloadHash string) string {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte('\n')
+
+	// Services other than S3 escape the already-escaped path again, as the
+	// AWS SDKs do when they sign.
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	b.WriteString(sigV4Escape(path, false))
+	b.WriteByte('\n')
+
+	query := r.URL.Query()
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, sigV4Escape(name, true)+"="+sigV4Escape(value, true))
+		}
+	}
+	slices.Sort(pairs)
+	b.WriteString(strings.Join(pairs, "&"))
+	b.WriteByte('\n')
+
+	for _, name := range signedHeaders {
+		values := r.Header.Values(name)
+		// net/http moves these headers out of r.Header.
+		if len(values) == 0 {
+			switch name {
+			case "host":
+				values = []string{r.Host}
+			case "content-length":
+				values = []string{strconv.FormatInt(r.ContentLength, 10)}
+			}
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(trimmed, ","))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	b.WriteString(strings.Join(signedHeaders, ";"))
+	b.WriteByte('\n')
+	b.WriteString(payloadHash)
+	return b.String()
+}
+
+// sigV4Escape percent-encodes every byte of s except the RFC 3986 unreserved
+// characters, and slashes unless encodeSlash is set.
+func sigV4Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := range len(s) {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !encodeSlash {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func writeForbidden(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	_, _ = io.WriteString(w, `{"message":"Forbidden"}`)
+}
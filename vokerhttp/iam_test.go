@@ -0,0 +1,170 @@
+package vokerhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireIAM(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/my/path", func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := IAMCallerFromContext(r.Context())
+		require.True(t, ok)
+		_, _ = io.WriteString(w, caller.ARN)
+	})
+
+	event := newTestFunctionURLRequest()
+	event.RequestContext.Authorizer.IAM = PayloadV2AuthorizerIAM{
+		AccessKey: "ASIAEXAMPLE",
+		AccountID: "123456789012",
+		UserARN:   "arn:aws:iam::123456789012:user/alice",
+		UserID:    "AIDAEXAMPLE",
+	}
+	response, err := eventHandler(RequireIAM(mux), &FunctionURL{})(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/alice", response.Body)
+
+	v1 := APIGatewayV1Request{HTTPMethod: http.MethodGet, Path: "/my/path"}
+	v1.RequestContext.Identity.UserARN = "arn:aws:sts::123456789012:assumed-role/ops/bob"
+	v1Response, err := eventHandler(RequireIAM(mux), &APIGatewayV1{})(context.Background(), v1)
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:sts::123456789012:assumed-role/ops/bob", v1Response.Body)
+
+	response, err = eventHandler(RequireIAM(mux), &FunctionURL{})(context.Background(), newTestFunctionURLRequest())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	assert.JSONEq(t, `{"message":"Forbidden"}`, response.Body)
+}
+
+// newTestSigV4Request returns a request signed by the AWS SDK for Go v2 with
+// the AWS documentation's example credentials.
+func newTestSigV4Request() *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "https://abc123.lambda-url.us-east-1.on.aws/my/path?foo=bar&baz=qux", strings.NewReader(`{"name":"voker"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Amz-Date", "20240501T130000Z")
+	r.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240501/us-east-1/lambda/aws4_request, SignedHeaders=content-length;content-type;host;x-amz-date, Signature=146d3e1a1f718327e11063b309d5d409945d50a50911d4d4b414d92e863132c3")
+	return r
+}
+
+func newTestSigV4Verifier(opts ...SigV4Option) *SigV4Verifier {
+	verifier := NewSigV4Verifier(func(ctx context.Context, accessKeyID string) (SigV4Credentials, error) {
+		if accessKeyID != "AKIDEXAMPLE" {
+			return SigV4Credentials{}, errors.New("not found")
+		}
+		return SigV4Credentials{
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+			Caller:          IAMCaller{AccountID: "123456789012", ARN: "arn:aws:iam::123456789012:user/partner"},
+		}, nil
+	}, append([]SigV4Option{WithSigV4Region("us-east-1")}, opts...)...)
+	verifier.now = func() time.Time { return time.Date(2024, 5, 1, 13, 2, 0, 0, time.UTC) }
+	return verifier
+}
+
+func TestSigV4Verifier(t *testing.T) {
+	r := newTestSigV4Request()
+	caller, err := newTestSigV4Verifier().Verify(r)
+	require.NoError(t, err)
+	assert.Equal(t, &IAMCaller{
+		AccessKeyID: "AKIDEXAMPLE",
+		AccountID:   "123456789012",
+		ARN:         "arn:aws:iam::123456789012:user/partner",
+	}, caller)
+	body, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"voker"}`, string(body))
+}
+
+func TestSigV4Verifier_Rejects(t *testing.T) {
+	tests := map[string]struct {
+		modify func(r *http.Request)
+		opts   []SigV4Option
+		err    string
+	}{
+		"missing authorization": {
+			modify: func(r *http.Request) { r.Header.Del("Authorization") },
+			err:    "missing SigV4 authorization",
+		},
+		"malformed authorization": {
+			modify: func(r *http.Request) { r.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE") },
+			err:    "malformed SigV4 authorization",
+		},
+		"modified body": {
+			modify: func(r *http.Request) { r.Body = io.NopCloser(strings.NewReader(`{"name":"other"}`)) },
+			err:    "invalid signature",
+		},
+		"modified query": {
+			modify: func(r *http.Request) { r.URL.RawQuery = "foo=bar" },
+			err:    "invalid signature",
+		},
+		"mismatched content hash": {
+			modify: func(r *http.Request) { r.Header.Set("X-Amz-Content-Sha256", strings.Repeat("0", 64)) },
+			err:    "invalid signature",
+		},
+		"expired": {
+			modify: func(r *http.Request) { r.Header.Set("X-Amz-Date", "20240501T120000Z") },
+			err:    "request signature has expired",
+		},
+		"wrong service": {
+			opts: []SigV4Option{WithSigV4Service("execute-api")},
+			err:  `request is signed for service "lambda"`,
+		},
+		"wrong region": {
+			opts: []SigV4Option{WithSigV4Region("eu-west-1")},
+			err:  `request is signed for region "us-east-1"`,
+		},
+		"unknown key": {
+			modify: func(r *http.Request) {
+				r.Header.Set("Authorization", strings.Replace(r.Header.Get("Authorization"), "AKIDEXAMPLE", "AKIDOTHER", 1))
+			},
+			err: "unknown access key AKIDOTHER: not found",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newTestSigV4Request()
+			if tt.modify != nil {
+				tt.modify(r)
+			}
+			_, err := newTestSigV4Verifier(tt.opts...).Verify(r)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestSigV4Verifier_Middleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/my/path", func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := IAMCallerFromContext(r.Context())
+		require.True(t, ok)
+		_, _ = fmt.Fprintf(w, "hello %s", caller.ARN)
+	})
+	handler := eventHandler(newTestSigV4Verifier().Middleware(mux), &FunctionURL{})
+
+	event := newTestFunctionURLRequest()
+	event.RequestContext.HTTP.Method = http.MethodPost
+	event.Body = `{"name":"voker"}`
+	event.Headers["content-length"] = "16"
+	event.Headers["x-amz-date"] = "20240501T130000Z"
+	event.Headers["authorization"] = newTestSigV4Request().Header.Get("Authorization")
+	response, err := handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "hello arn:aws:iam::123456789012:user/partner", response.Body)
+
+	event.Body = `{"name":"other"}`
+	response, err = handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	assert.JSONEq(t, `{"message":"Forbidden"}`, response.Body)
+}
@@ -0,0 +1,101 @@
+package vokerhttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// IPFilter allows or denies requests by client IP address. Function URLs
+// with the NONE auth type accept requests from anywhere, and IPFilter
+// restricts them without API Gateway or AWS WAF in front.
+//
+// The client address is the source IP Lambda or API Gateway reports in the
+// event, or for ALB events the last X-Forwarded-For address, which ALB
+// appends after any addresses the client sent itself. Behind CloudFront or
+// another proxy, that is the proxy's address.
+type IPFilter struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// NewIPFilter returns a filter for the addresses and CIDR ranges, such as
+// "203.0.113.7" or "2001:db8::/32", in allow and deny. A request is allowed
+// when its address matches no deny entry and, if allow is not empty, matches
+// an allow entry.
+func NewIPFilter(allow, deny []string) (*IPFilter, error) {
+	allowPrefixes, err := parsePrefixes(allow)
+	if err != nil {
+		return nil, err
+	}
+	denyPrefixes, err := parsePrefixes(deny)
+	if err != nil {
+		return nil, err
+	}
+	return &IPFilter{allow: allowPrefixes, deny: denyPrefixes}, nil
+}
+
+func parsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		var prefix netip.Prefix
+		var err error
+		if strings.Contains(entry, "/") {
+			prefix, err = netip.ParsePrefix(entry)
+		} else {
+			var addr netip.Addr
+			addr, err = netip.ParseAddr(entry)
+			prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("vokerhttp: invalid IP filter entry %q: %w", entry, err)
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// Allowed reports whether the filter allows remoteAddr, an IP address with
+// or without a port. Addresses that can't be parsed are not allowed.
+func (f *IPFilter) Allowed(remoteAddr string) bool {
+	addr, err := netip.ParseAddr(remoteAddr)
+	if err != nil {
+		addrPort, err := netip.ParseAddrPort(remoteAddr)
+		if err != nil {
+			return false
+		}
+		addr = addrPort.Addr()
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range f.deny {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, prefix := range f.allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware responds 403 Forbidden, with the {"message":"Forbidden"} body
+// API Gateway returns, to requests from addresses the filter doesn't allow.
+func (f *IPFilter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !f.Allowed(r.RemoteAddr) {
+			writeForbidden(w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
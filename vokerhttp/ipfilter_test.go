@@ -0,0 +1,92 @@
+package vokerhttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPFilter_Allowed(t *testing.T) {
+	filter, err := NewIPFilter([]string{"203.0.113.0/24", "2001:db8::/32", "198.51.100.7"}, []string{"203.0.113.66"})
+	require.NoError(t, err)
+
+	tests := map[string]bool{
+		"203.0.113.7":           true,
+		"203.0.113.7:443":       true,
+		"::ffff:203.0.113.7":    true,
+		"198.51.100.7":          true,
+		"2001:db8::1":           true,
+		"[2001:db8::1]:443":     true,
+		"203.0.113.66":          false,
+		"198.51.100.8":          false,
+		"2001:db9::1":           false,
+		"":                      false,
+		"not an address":        false,
+		"::ffff:203.0.113.66":   false,
+		"[::ffff:198.51.100.7]": false,
+	}
+	for addr, allowed := range tests {
+		assert.Equal(t, allowed, filter.Allowed(addr), addr)
+	}
+
+	denyOnly, err := NewIPFilter(nil, []string{"192.0.2.0/24"})
+	require.NoError(t, err)
+	assert.True(t, denyOnly.Allowed("198.51.100.1"))
+	assert.False(t, denyOnly.Allowed("192.0.2.1"))
+}
+
+func TestNewIPFilter_InvalidEntry(t *testing.T) {
+	_, err := NewIPFilter([]string{"203.0.113.0/33"}, nil)
+	assert.ErrorContains(t, err, `vokerhttp: invalid IP filter entry "203.0.113.0/33"`)
+
+	_, err = NewIPFilter(nil, []string{"example.com"})
+	assert.ErrorContains(t, err, `vokerhttp: invalid IP filter entry "example.com"`)
+}
+
+func TestIPFilter_Middleware(t *testing.T) {
+	filter, err := NewIPFilter([]string{"1.2.3.0/24"}, nil)
+	require.NoError(t, err)
+	handler := eventHandler(filter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})), &FunctionURL{})
+
+	event := newTestFunctionURLRequest()
+	event.RequestContext.HTTP.SourceIP = "1.2.3.4"
+	response, err := handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	event.RequestContext.HTTP.SourceIP = "5.6.7.8"
+	response, err = handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	assert.JSONEq(t, `{"message":"Forbidden"}`, response.Body)
+}
+
+func TestIPFilter_ALBSpoofedForwardedFor(t *testing.T) {
+	filter, err := NewIPFilter([]string{"1.2.3.0/24"}, []string{"9.9.9.9"})
+	require.NoError(t, err)
+	handler := eventHandler(filter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})), &ALB{})
+
+	// The client sent an allowed address; ALB appended the real one.
+	event := newTestALBRequest()
+	event.Headers["x-forwarded-for"] = "1.2.3.4, 5.6.7.8"
+	response, err := handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	event.Headers["x-forwarded-for"] = "1.2.3.4, 9.9.9.9"
+	response, err = handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	event.Headers["x-forwarded-for"] = "5.6.7.8, 1.2.3.4"
+	response, err = handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
@@ -0,0 +1,517 @@
+package vokerhttp
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksRefreshInterval limits how often a token signed with an unknown key
+// triggers a JWKS refetch, so forged key IDs can't be used to hammer the
+// issuer.
+const jwksRefreshInterval = time.Minute
+
+var (
+	errMissingToken = errors.New("missing bearer token")
+	errInvalidToken = errors.New("invalid token")
+
+	errInvalidSignature = errors.New("invalid signature")
+)
+
+type jwtOptions struct {
+	issuer     string
+	audience   []string
+	leeway     time.Duration
+	httpClient *http.Client
+}
+
+// JWTOption configures a [JWTVerifier].
+type JWTOption func(*jwtOptions)
+
+// WithIssuer requires the token's iss claim to equal issuer.
+func WithIssuer(issuer string) JWTOption {
+	return func(o *jwtOptions) {
+		o.issuer = issuer
+	}
+}
+
+// WithAudience requires the token's aud claim to contain at least one of
+// audience.
+func WithAudience(audience ...string) JWTOption {
+	return func(o *jwtOptions) {
+		o.audience = append(o.audience, audience...)
+	}
+}
+
+// WithLeeway allows for clock skew when checking the exp and nbf claims.
+func WithLeeway(leeway time.Duration) JWTOption {
+	return func(o *jwtOptions) {
+		o.leeway = leeway
+	}
+}
+
+// WithJWKSClient sets the HTTP client used to fetch the JWKS. The default
+// is a client with a 5 second timeout.
+func WithJWKSClient(client *http.Client) JWTOption {
+	return func(o *jwtOptions) {
+		o.httpClient = client
+	}
+}
+
+// JWTClaims holds the verified claims of a token.
+type JWTClaims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+
+	// Raw is the token's JSON payload, for reading custom claims with
+	// [JWTClaims.Decode].
+	Raw json.RawMessage
+}
+
+// Decode unmarshals the token's payload into v.
+func (c *JWTClaims) Decode(v any) error {
+	return json.Unmarshal(c.Raw, v)
+}
+
+type jwtClaimsKey struct{}
+
+// JWTClaimsFromContext returns the claims placed on the request context by
+// [JWTVerifier.Middleware].
+func JWTClaimsFromContext(ctx context.Context) (*JWTClaims, bool) {
+	claims, ok := ctx.Value(jwtClaimsKey{}).(*JWTClaims)
+	return claims, ok
+}
+
+// JWTVerifier verifies bearer tokens signed by keys from a JSON Web Key Set,
+// for Function URLs and other HTTP events without an API Gateway
+// authorizer. RS256, RS384, RS512, PS256, PS384, PS512, ES256, ES384, ES512,
+// and EdDSA (Ed25519) signatures are supported.
+//
+// Keys are fetched once when the verifier is created, during
+// initialization. A token signed with a key ID missing from the set
+// triggers a refetch, at most once a minute, to pick up rotated keys.
+type JWTVerifier struct {
+	jwksURL string
+	options jwtOptions
+	now     func() time.Time
+
+	mu        sync.RWMutex
+	keys      []jwk
+	lastFetch time.Time
+}
+
+// NewJWTVerifier fetches the key set at jwksURL and returns a verifier for
+// tokens signed with its keys:
+//
+//	verifier, err := vokerhttp.NewJWTVerifier(ctx,
+//	    "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_abc/.well-known/jwks.json",
+//	    vokerhttp.WithIssuer("https://cognito-idp.us-east-1.amazonaws.com/us-east-1_abc"),
+//	    vokerhttp.WithAudience("client-id"),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	vokerhttp.Start(verifier.Middleware(mux), &vokerhttp.FunctionURL{})
+func NewJWTVerifier(ctx context.Context, jwksURL string, opts ...JWTOption) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		jwksURL: jwksURL,
+		options: jwtOptions{httpClient: &http.Client{Timeout: 5 * time.Second}},
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(&v.options)
+	}
+	if err := v.fetchKeys(ctx); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Middleware rejects requests without a valid bearer token in the
+// Authorization header with a 401 response carrying a WWW-Authenticate
+// challenge and the {"message":"Unauthorized"} body API Gateway authorizers
+// return. Verified claims are available to next through
+// [JWTClaimsFromContext].
+func (v *JWTVerifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			writeUnauthorized(w, errMissingToken)
+			return
+		}
+		claims, err := v.Verify(r.Context(), token)
+		if err != nil {
+			writeUnauthorized(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), jwtClaimsKey{}, claims)))
+	})
+}
+
+func bearerToken(authorization string) (string, bool) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func writeUnauthorized(w http.ResponseWriter, err error) {
+	challenge := "Bearer"
+	if !errors.Is(err, errMissingToken) {
+		// RFC 6750 error descriptions are quoted strings and may not
+		// contain quotes or backslashes.
+		description := strings.NewReplacer(`"`, "'", `\`, "/").Replace(err.Error())
+		challenge = fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, description)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	_, _ = io.WriteString(w, `{"message":"Unauthorized"}`)
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type jwtPayload struct {
+	Issuer    string      `json:"iss"`
+	Subject   string      `json:"sub"`
+	Audience  jwtAudience `json:"aud"`
+	ExpiresAt *float64    `json:"exp"`
+	NotBefore *float64    `json:"nbf"`
+	IssuedAt  *float64    `json:"iat"`
+}
+
+// jwtAudience decodes the aud claim, which is a string or an array of
+// strings.
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return json.Unmarshal(data, (*[]string)(a))
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	*a = jwtAudience{single}
+	return nil
+}
+
+// Verify checks token's signature and its exp, nbf, iss, and aud claims and
+// returns its claims. Errors wrap a description of why the token was
+// rejected.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", errInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", errInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", errInvalidToken)
+	}
+
+	key, err := v.key(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyJWTSignature(header.Algorithm, key.publicKey, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidToken, err)
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", errInvalidToken)
+	}
+	var payload jwtPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", errInvalidToken)
+	}
+	if err := v.checkClaims(payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidToken, err)
+	}
+
+	return &JWTClaims{
+		Issuer:    payload.Issuer,
+		Subject:   payload.Subject,
+		Audience:  payload.Audience,
+		ExpiresAt: numericDate(payload.ExpiresAt),
+		NotBefore: numericDate(payload.NotBefore),
+		IssuedAt:  numericDate(payload.IssuedAt),
+		Raw:       raw,
+	}, nil
+}
+
+func decodeJWTSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func numericDate(seconds *float64) time.Time {
+	if seconds == nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(*seconds * 1000))
+}
+
+func (v *JWTVerifier) checkClaims(payload jwtPayload) error {
+	now := v.now()
+	if payload.ExpiresAt == nil {
+		return errors.New("missing exp claim")
+	}
+	if !now.Before(numericDate(payload.ExpiresAt).Add(v.options.leeway)) {
+		return errors.New("token is expired")
+	}
+	if payload.NotBefore != nil && now.Add(v.options.leeway).Before(numericDate(payload.NotBefore)) {
+		return errors.New("token is not valid yet")
+	}
+	if v.options.issuer != "" && payload.Issuer != v.options.issuer {
+		return fmt.Errorf("unexpected issuer %q", payload.Issuer)
+	}
+	if len(v.options.audience) > 0 && !slices.ContainsFunc(payload.Audience, func(aud string) bool {
+		return slices.Contains(v.options.audience, aud)
+	}) {
+		return errors.New("unexpected audience")
+	}
+	return nil
+}
+
+// key returns the key that signed a token with header, refetching the key
+// set when the key ID is unknown.
+func (v *JWTVerifier) key(ctx context.Context, header jwtHeader) (jwk, error) {
+	if key, ok := v.findKey(header); ok {
+		return key, nil
+	}
+
+	v.mu.RLock()
+	refresh := v.now().Sub(v.lastFetch) >= jwksRefreshInterval
+	v.mu.RUnlock()
+	// A failed refetch leaves the cached keys in place, and the token is
+	// rejected like any other with an unknown key.
+	if refresh && v.fetchKeys(ctx) == nil {
+		if key, ok := v.findKey(header); ok {
+			return key, nil
+		}
+	}
+	return jwk{}, fmt.Errorf("%w: unknown signing key %q", errInvalidToken, header.KeyID)
+}
+
+func (v *JWTVerifier) findKey(header jwtHeader) (jwk, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, key := range v.keys {
+		if key.id == header.KeyID && (key.algorithm == "" || key.algorithm == header.Algorithm) {
+			return key, true
+		}
+	}
+	return jwk{}, false
+}
+
+type jwk struct {
+	id        string
+	algorithm string
+	publicKey crypto.PublicKey
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+func (v *JWTVerifier) fetchKeys(ctx context.Context) error {
+	v.mu.Lock()
+	v.lastFetch = v.now()
+	v.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	resp, err := v.options.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make([]jwk, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		publicKey, err := k.publicKey()
+		if err != nil {
+			// Skip keys of unsupported types rather than failing the
+			// whole set.
+			continue
+		}
+		keys = append(keys, jwk{id: k.KeyID, algorithm: k.Algorithm, publicKey: publicKey})
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+	return nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("invalid EC point")
+		}
+		return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+}
+
+func verifyJWTSignature(algorithm string, key crypto.PublicKey, signed, signature []byte) error {
+	if algorithm == "EdDSA" {
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(pub, signed, signature) {
+			return errInvalidSignature
+		}
+		return nil
+	}
+
+	var hashID crypto.Hash
+	switch algorithm {
+	case "RS256", "PS256", "ES256":
+		hashID = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hashID = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hashID = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	h := hashID.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch algorithm[:2] {
+	case "RS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPKCS1v15(pub, hashID, digest, signature) != nil {
+			return errInvalidSignature
+		}
+	case "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPSS(pub, hashID, digest, signature, nil) != nil {
+			return errInvalidSignature
+		}
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != ecdsaCurves[algorithm] {
+			return errInvalidSignature
+		}
+		size := (pub.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errInvalidSignature
+		}
+	}
+	return nil
+}
+
+// ecdsaCurves maps each ECDSA algorithm to the only curve it may be used
+// with.
+var ecdsaCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
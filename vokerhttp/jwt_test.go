@@ -0,0 +1,265 @@
+package vokerhttp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testJWTKeys struct {
+	rsa     *rsa.PrivateKey
+	ecdsa   *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func newTestJWTKeys(t *testing.T) testJWTKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return testJWTKeys{rsa: rsaKey, ecdsa: ecKey, ed25519: edKey}
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (k testJWTKeys) jwks() string {
+	ecPoint, _ := k.ecdsa.PublicKey.Bytes()
+	keys := []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecPoint[1:33]), "y": b64(ecPoint[33:])},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(k.ed25519.Public().(ed25519.PublicKey))},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": b64(k.rsa.N.Bytes()), "e": "AQAB"},
+		{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+	}
+	data, _ := json.Marshal(map[string]any{"keys": keys})
+	return string(data)
+}
+
+func (k testJWTKeys) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+	case "PS256":
+		signature, err = rsa.SignPSS(rand.Reader, k.rsa, crypto.SHA256, digest[:], nil)
+	case "ES256":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k.ecdsa, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case "EdDSA":
+		signature = ed25519.Sign(k.ed25519, []byte(signed))
+	}
+	require.NoError(t, err)
+	return signed + "." + b64(signature)
+}
+
+func newTestJWTVerifier(t *testing.T, keys testJWTKeys, opts ...JWTOption) (*JWTVerifier, *atomic.Int32) {
+	t.Helper()
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(keys.jwks()))
+	}))
+	t.Cleanup(server.Close)
+
+	verifier, err := NewJWTVerifier(context.Background(), server.URL, opts...)
+	require.NoError(t, err)
+	return verifier, &fetches
+}
+
+func validClaims() map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":   "https://issuer.example.com",
+		"sub":   "user-123",
+		"aud":   "my-app",
+		"exp":   now.Add(time.Hour).Unix(),
+		"iat":   now.Unix(),
+		"scope": "orders:read",
+	}
+}
+
+func TestJWTVerifier_Algorithms(t *testing.T) {
+	keys := newTestJWTKeys(t)
+	verifier, _ := newTestJWTVerifier(t, keys,
+		WithIssuer("https://issuer.example.com"),
+		WithAudience("other-app", "my-app"),
+	)
+
+	for _, tc := range []struct{ alg, kid string }{
+		{"RS256", "rsa"}, {"PS256", "rsa"}, {"ES256", "ec"}, {"EdDSA", "ed"},
+	} {
+		t.Run(tc.alg, func(t *testing.T) {
+			claims, err := verifier.Verify(context.Background(), keys.sign(t, tc.alg, tc.kid, validClaims()))
+			require.NoError(t, err)
+			assert.Equal(t, "user-123", claims.Subject)
+			assert.Equal(t, "https://issuer.example.com", claims.Issuer)
+			assert.Equal(t, []string{"my-app"}, claims.Audience)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), claims.ExpiresAt, 2*time.Second)
+
+			var custom struct {
+				Scope string `json:"scope"`
+			}
+			require.NoError(t, claims.Decode(&custom))
+			assert.Equal(t, "orders:read", custom.Scope)
+		})
+	}
+}
+
+func TestJWTVerifier_Rejects(t *testing.T) {
+	keys := newTestJWTKeys(t)
+	verifier, _ := newTestJWTVerifier(t, keys,
+		WithIssuer("https://issuer.example.com"),
+		WithAudience("my-app"),
+		WithLeeway(30*time.Second),
+	)
+
+	withClaim := func(key string, value any) map[string]any {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	valid := keys.sign(t, "RS256", "rsa", validClaims())
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"malformed", "abc.def", "malformed token"},
+		{"bad signature", parts[0] + "." + parts[1] + "." + b64([]byte("forged")), "invalid signature"},
+		{"tampered payload", parts[0] + "." + b64([]byte(`{"sub":"admin","exp":9999999999}`)) + "." + parts[2], "invalid signature"},
+		{"wrong key type", keys.sign(t, "ES256", "rsa", validClaims()), "invalid signature"},
+		{"unknown key", keys.sign(t, "RS256", "missing", validClaims()), `unknown signing key "missing"`},
+		{"encryption key", keys.sign(t, "RS256", "enc", validClaims()), `unknown signing key "enc"`},
+		{"none", b64([]byte(`{"alg":"none","kid":"rsa"}`)) + "." + parts[1] + ".", `unsupported algorithm "none"`},
+		{"HMAC", keys.sign(t, "HS256", "rsa", validClaims()), `unsupported algorithm "HS256"`},
+		{"expired", keys.sign(t, "RS256", "rsa", withClaim("exp", time.Now().Add(-time.Minute).Unix())), "token is expired"},
+		{"no exp", keys.sign(t, "RS256", "rsa", withClaim("exp", nil)), "missing exp claim"},
+		{"not yet valid", keys.sign(t, "RS256", "rsa", withClaim("nbf", time.Now().Add(time.Minute).Unix())), "token is not valid yet"},
+		{"issuer", keys.sign(t, "RS256", "rsa", withClaim("iss", "https://evil.example.com")), `unexpected issuer "https://evil.example.com"`},
+		{"audience", keys.sign(t, "RS256", "rsa", withClaim("aud", []string{"a", "b"})), "unexpected audience"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(context.Background(), tt.token)
+			require.ErrorIs(t, err, errInvalidToken)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	// Leeway accepts recently expired tokens.
+	_, err := verifier.Verify(context.Background(), keys.sign(t, "RS256", "rsa", withClaim("exp", time.Now().Add(-10*time.Second).Unix())))
+	assert.NoError(t, err)
+}
+
+func TestJWTVerifier_RefetchesForUnknownKey(t *testing.T) {
+	keys := newTestJWTKeys(t)
+	verifier, fetches := newTestJWTVerifier(t, keys)
+	now := time.Now()
+	verifier.now = func() time.Time { return now }
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// Within the refresh interval an unknown key doesn't refetch.
+	_, err := verifier.Verify(context.Background(), keys.sign(t, "RS256", "rotated", validClaims()))
+	assert.ErrorContains(t, err, "unknown signing key")
+	assert.Equal(t, int32(1), fetches.Load())
+
+	now = now.Add(jwksRefreshInterval)
+	_, err = verifier.Verify(context.Background(), keys.sign(t, "RS256", "rotated", validClaims()))
+	assert.ErrorContains(t, err, "unknown signing key")
+	assert.Equal(t, int32(2), fetches.Load())
+
+	// Known keys never refetch.
+	now = now.Add(jwksRefreshInterval)
+	_, err = verifier.Verify(context.Background(), keys.sign(t, "RS256", "rsa", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestNewJWTVerifier_FetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := NewJWTVerifier(context.Background(), server.URL)
+	assert.EqualError(t, err, "failed to fetch JWKS: status 500")
+}
+
+func TestJWTVerifier_Middleware(t *testing.T) {
+	keys := newTestJWTKeys(t)
+	verifier, _ := newTestJWTVerifier(t, keys, WithAudience("my-app"))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/my/path", func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := JWTClaimsFromContext(r.Context())
+		require.True(t, ok)
+		_, _ = w.Write([]byte("hello " + claims.Subject))
+	})
+	handler := eventHandler(verifier.Middleware(mux), &FunctionURL{})
+
+	event := newTestFunctionURLRequest()
+	event.Headers["authorization"] = "Bearer " + keys.sign(t, "ES256", "ec", validClaims())
+	response, err := handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "hello user-123", response.Body)
+
+	event = newTestFunctionURLRequest()
+	response, err = handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "Bearer", response.Headers["www-authenticate"])
+	assert.Equal(t, "application/json", response.Headers["content-type"])
+	assert.JSONEq(t, `{"message":"Unauthorized"}`, response.Body)
+	assert.False(t, response.IsBase64Encoded)
+
+	event.Headers["authorization"] = "Bearer " + keys.sign(t, "RS256", "rsa", map[string]any{"aud": "my-app", "exp": 1})
+	response, err = handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, `Bearer error="invalid_token", error_description="invalid token: token is expired"`, response.Headers["www-authenticate"])
+
+	event.Headers["authorization"] = "Basic dXNlcjpwYXNz"
+	response, err = handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", response.Headers["www-authenticate"])
+}
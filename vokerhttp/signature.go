@@ -0,0 +1,206 @@
+package vokerhttp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var errMissingSignature = errors.New("missing signature")
+
+// SignatureEncoding is how a signature is encoded in a request.
+type SignatureEncoding int
+
+const (
+	// SignatureHex is lowercase or uppercase hexadecimal.
+	SignatureHex SignatureEncoding = iota
+	// SignatureBase64 is standard base64, with or without padding.
+	SignatureBase64
+)
+
+type signatureOptions struct {
+	header        string
+	prefix        string
+	extract       func(r *http.Request, body []byte) string
+	encoding      SignatureEncoding
+	signedContent func(r *http.Request, body []byte) []byte
+}
+
+// SignatureOption configures a [SignatureVerifier].
+type SignatureOption func(*signatureOptions)
+
+// WithSignatureHeader reads the signature from the header name, after
+// prefix, such as "sha256=" for GitHub's X-Hub-Signature-256. The default
+// is the X-Signature header with no prefix.
+func WithSignatureHeader(name, prefix string) SignatureOption {
+	return func(o *signatureOptions) {
+		o.header = name
+		o.prefix = prefix
+	}
+}
+
+// WithSignatureFrom reads the signature with extract, for signatures in
+// other places, such as a query parameter or a field of the body. extract
+// returns "" when the request has no signature.
+func WithSignatureFrom(extract func(r *http.Request, body []byte) string) SignatureOption {
+	return func(o *signatureOptions) {
+		o.extract = extract
+	}
+}
+
+// WithSignatureEncoding sets how the signature is encoded. The default is
+// [SignatureHex].
+func WithSignatureEncoding(encoding SignatureEncoding) SignatureOption {
+	return func(o *signatureOptions) {
+		o.encoding = encoding
+	}
+}
+
+// WithSignedContent sets the bytes the signature covers. The default is the
+// raw request body. Senders that sign a timestamp along with the body, such
+// as Slack's "v0:<timestamp>:<body>", need the same bytes rebuilt:
+//
+//	vokerhttp.WithSignedContent(func(r *http.Request, body []byte) []byte {
+//	    return fmt.Appendf(nil, "v0:%s:%s", r.Header.Get("X-Slack-Request-Timestamp"), body)
+//	})
+//
+// Check such timestamps in the handler to reject replayed requests.
+func WithSignedContent(signedContent func(r *http.Request, body []byte) []byte) SignatureOption {
+	return func(o *signatureOptions) {
+		o.signedContent = signedContent
+	}
+}
+
+// SignatureVerifier checks the signatures webhook senders attach to their
+// requests.
+type SignatureVerifier struct {
+	options signatureOptions
+	verify  func(content, signature []byte) bool
+}
+
+// NewHMACVerifier returns a verifier for HMAC-SHA256 signatures made with
+// secret. Signatures are compared in constant time.
+func NewHMACVerifier(secret []byte, opts ...SignatureOption) *SignatureVerifier {
+	return newSignatureVerifier(func(content, signature []byte) bool {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(content)
+		return hmac.Equal(mac.Sum(nil), signature)
+	}, opts)
+}
+
+// NewPublicKeyVerifier returns a verifier for signatures made with the
+// private key of key: an *ed25519.PublicKey or ed25519.PublicKey, an
+// *ecdsa.PublicKey with ASN.1-encoded ECDSA-SHA256 signatures, or an
+// *rsa.PublicKey with PKCS #1 v1.5 RSA-SHA256 signatures.
+func NewPublicKeyVerifier(key crypto.PublicKey, opts ...SignatureOption) (*SignatureVerifier, error) {
+	var verify func(content, signature []byte) bool
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		verify = func(content, signature []byte) bool {
+			return ed25519.Verify(key, content, signature)
+		}
+	case *ed25519.PublicKey:
+		return NewPublicKeyVerifier(*key, opts...)
+	case *ecdsa.PublicKey:
+		verify = func(content, signature []byte) bool {
+			digest := sha256.Sum256(content)
+			return ecdsa.VerifyASN1(key, digest[:], signature)
+		}
+	case *rsa.PublicKey:
+		verify = func(content, signature []byte) bool {
+			digest := sha256.Sum256(content)
+			return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+		}
+	default:
+		return nil, fmt.Errorf("vokerhttp: unsupported public key type %T", key)
+	}
+	return newSignatureVerifier(verify, opts), nil
+}
+
+func newSignatureVerifier(verify func(content, signature []byte) bool, opts []SignatureOption) *SignatureVerifier {
+	options := signatureOptions{header: "X-Signature"}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &SignatureVerifier{options: options, verify: verify}
+}
+
+// Verify checks the signature of r. It reads r.Body and replaces it with a
+// copy, so the handler can read it again.
+func (v *SignatureVerifier) Verify(r *http.Request) error {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	var encoded string
+	if v.options.extract != nil {
+		encoded = v.options.extract(r, body)
+	} else {
+		value := r.Header.Get(v.options.header)
+		if prefixed, ok := strings.CutPrefix(value, v.options.prefix); ok {
+			encoded = prefixed
+		}
+	}
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return errMissingSignature
+	}
+
+	var signature []byte
+	var err error
+	switch v.options.encoding {
+	case SignatureBase64:
+		signature, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	default:
+		signature, err = hex.DecodeString(encoded)
+	}
+	if err != nil {
+		return errInvalidSignature
+	}
+
+	content := body
+	if v.options.signedContent != nil {
+		content = v.options.signedContent(r, body)
+	}
+	if !v.verify(content, signature) {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// Middleware returns net/http middleware that responds 401 Unauthorized,
+// with the {"message":"Unauthorized"} body API Gateway returns, to requests
+// whose signature is missing or invalid.
+//
+//	verifier := vokerhttp.NewHMACVerifier(secret,
+//	    vokerhttp.WithSignatureHeader("X-Hub-Signature-256", "sha256="))
+//	vokerhttp.Start(verifier.Middleware(mux), &vokerhttp.FunctionURL{})
+func (v *SignatureVerifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Verify(r); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"message":"Unauthorized"}`)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
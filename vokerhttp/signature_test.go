@@ -0,0 +1,164 @@
+package vokerhttp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hmacHex(secret, content string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(content))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSignatureVerifier_HMAC(t *testing.T) {
+	verifier := NewHMACVerifier([]byte("secret"), WithSignatureHeader("X-Hub-Signature-256", "sha256="))
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"action":"opened"}`))
+	r.Header.Set("X-Hub-Signature-256", "sha256="+hmacHex("secret", `{"action":"opened"}`))
+	require.NoError(t, verifier.Verify(r))
+	body, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"action":"opened"}`, string(body))
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"action":"closed"}`))
+	r.Header.Set("X-Hub-Signature-256", "sha256="+hmacHex("secret", `{"action":"opened"}`))
+	assert.ErrorIs(t, verifier.Verify(r), errInvalidSignature)
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	r.Header.Set("X-Hub-Signature-256", hmacHex("secret", `{}`))
+	assert.ErrorIs(t, verifier.Verify(r), errMissingSignature)
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	r.Header.Set("X-Hub-Signature-256", "sha256=not-hex")
+	assert.ErrorIs(t, verifier.Verify(r), errInvalidSignature)
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	assert.ErrorIs(t, verifier.Verify(r), errMissingSignature)
+}
+
+func TestSignatureVerifier_SignedContent(t *testing.T) {
+	verifier := NewHMACVerifier([]byte("secret"),
+		WithSignatureHeader("X-Slack-Signature", "v0="),
+		WithSignedContent(func(r *http.Request, body []byte) []byte {
+			return fmt.Appendf(nil, "v0:%s:%s", r.Header.Get("X-Slack-Request-Timestamp"), body)
+		}))
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("token=abc"))
+	r.Header.Set("X-Slack-Request-Timestamp", "1531420618")
+	r.Header.Set("X-Slack-Signature", "v0="+hmacHex("secret", "v0:1531420618:token=abc"))
+	require.NoError(t, verifier.Verify(r))
+
+	r.Header.Set("X-Slack-Request-Timestamp", "1531420619")
+	r.Body = io.NopCloser(strings.NewReader("token=abc"))
+	assert.ErrorIs(t, verifier.Verify(r), errInvalidSignature)
+}
+
+func TestSignatureVerifier_SignatureFrom(t *testing.T) {
+	verifier := NewHMACVerifier([]byte("secret"),
+		WithSignatureEncoding(SignatureBase64),
+		WithSignatureFrom(func(r *http.Request, body []byte) string {
+			return r.URL.Query().Get("signature")
+		}))
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("payload"))
+	query := url.Values{"signature": {base64.StdEncoding.EncodeToString(mac.Sum(nil))}}
+
+	r := httptest.NewRequest(http.MethodPost, "/?"+query.Encode(), strings.NewReader("payload"))
+	require.NoError(t, verifier.Verify(r))
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload"))
+	assert.ErrorIs(t, verifier.Verify(r), errMissingSignature)
+}
+
+func TestSignatureVerifier_PublicKeys(t *testing.T) {
+	content := []byte(`{"id":"evt_1"}`)
+	digest := sha256.Sum256(content)
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecSignature, err := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		key       crypto.PublicKey
+		signature []byte
+	}{
+		"ed25519":         {edPublic, ed25519.Sign(edPrivate, content)},
+		"ed25519 pointer": {&edPublic, ed25519.Sign(edPrivate, content)},
+		"ecdsa":           {&ecKey.PublicKey, ecSignature},
+		"rsa":             {&rsaKey.PublicKey, rsaSignature},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			verifier, err := NewPublicKeyVerifier(tt.key, WithSignatureEncoding(SignatureBase64))
+			require.NoError(t, err)
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(content)))
+			r.Header.Set("X-Signature", base64.StdEncoding.EncodeToString(tt.signature))
+			require.NoError(t, verifier.Verify(r))
+
+			r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":"evt_2"}`))
+			r.Header.Set("X-Signature", base64.StdEncoding.EncodeToString(tt.signature))
+			assert.ErrorIs(t, verifier.Verify(r), errInvalidSignature)
+		})
+	}
+
+	_, err = NewPublicKeyVerifier("not a key")
+	assert.EqualError(t, err, "vokerhttp: unsupported public key type string")
+}
+
+func TestSignatureVerifier_Middleware(t *testing.T) {
+	verifier := NewHMACVerifier([]byte("secret"))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/my/path", func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		_, _ = fmt.Fprintf(w, "hello %s", payload["name"])
+	})
+	handler := eventHandler(verifier.Middleware(mux), &FunctionURL{})
+
+	event := newTestFunctionURLRequest()
+	event.Body = `{"name":"voker"}`
+	event.Headers["x-signature"] = hmacHex("secret", event.Body)
+	response, err := handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "hello voker", response.Body)
+
+	event.Headers["x-signature"] = hmacHex("other", event.Body)
+	response, err = handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, "application/json", response.Headers["content-type"])
+	assert.Empty(t, response.Headers["www-authenticate"])
+	assert.JSONEq(t, `{"message":"Unauthorized"}`, response.Body)
+}
@@ -0,0 +1,231 @@
+// Package sse writes Server-Sent Events to streaming HTTP responses, such as
+// token-by-token model output sent from a Function URL in RESPONSE_STREAM
+// mode:
+//
+//	mux.HandleFunc("GET /chat", func(w http.ResponseWriter, r *http.Request) {
+//	    events := sse.NewWriter(w, sse.WithKeepAlive(15*time.Second))
+//	    defer events.Close()
+//	    for token := range generate(r.Context()) {
+//	        if err := events.Send(sse.Event{Event: "token", Data: token}); err != nil {
+//	            return
+//	        }
+//	    }
+//	})
+//	vokerhttp.StartStreaming(mux, &vokerhttp.FunctionURL{})
+//
+// Every event is flushed as soon as it is written, so clients receive it
+// without waiting for the response to end.
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the media type of an event stream.
+const ContentType = "text/event-stream"
+
+var (
+	// ErrClosed is returned when writing to a closed Writer.
+	ErrClosed = errors.New("sse: writer is closed")
+
+	errInvalidField = errors.New("sse: event and id must not contain line breaks")
+)
+
+// Event is a single Server-Sent Event. Empty fields are omitted.
+type Event struct {
+	// ID sets the client's last event ID, which it sends back in the
+	// Last-Event-ID header when it reconnects.
+	ID string
+
+	// Event is the event type. Clients dispatch events without one as
+	// "message".
+	Event string
+
+	// Data is the event payload. Line breaks are preserved by sending each
+	// line as its own data field.
+	Data string
+
+	// Retry asks the client to wait this long before reconnecting.
+	Retry time.Duration
+}
+
+// Option configures a [Writer].
+type Option func(*Writer)
+
+// WithKeepAlive sends a comment whenever no event has been written for
+// interval, so that idle connections aren't closed by clients or proxies
+// while the handler waits on slow work.
+func WithKeepAlive(interval time.Duration) Option {
+	return func(w *Writer) {
+		w.keepAlive = interval
+	}
+}
+
+// Writer formats events onto a response and flushes each one. It is safe
+// for concurrent use.
+type Writer struct {
+	mu        sync.Mutex
+	w         io.Writer
+	flush     func() error
+	keepAlive time.Duration
+	written   chan struct{}
+	done      chan struct{}
+	closed    bool
+	err       error
+}
+
+// NewWriter returns a Writer that writes events to w. When w is an
+// http.ResponseWriter whose headers haven't been written, NewWriter sets
+// Content-Type to text/event-stream and Cache-Control to no-cache, unless
+// the handler already set them. Events are flushed when w implements
+// http.Flusher or has a Flush method that returns an error.
+//
+// Call Close when the stream ends to stop keep-alive comments.
+func NewWriter(w io.Writer, opts ...Option) *Writer {
+	sw := &Writer{w: w, flush: func() error { return nil }, done: make(chan struct{})}
+	for _, opt := range opts {
+		opt(sw)
+	}
+
+	if rw, ok := w.(http.ResponseWriter); ok {
+		header := rw.Header()
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", ContentType)
+		}
+		if header.Get("Cache-Control") == "" {
+			header.Set("Cache-Control", "no-cache")
+		}
+	}
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		sw.flush = f.Flush
+	case http.Flusher:
+		sw.flush = func() error {
+			f.Flush()
+			return nil
+		}
+	}
+
+	if sw.keepAlive > 0 {
+		sw.written = make(chan struct{}, 1)
+		go sw.sendKeepAlives()
+	}
+	return sw
+}
+
+// Send writes e and flushes it.
+func (w *Writer) Send(e Event) error {
+	if strings.ContainsAny(e.ID, "\r\n\x00") || strings.ContainsAny(e.Event, "\r\n") {
+		return errInvalidField
+	}
+
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range splitLines(e.Data) {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return w.write(b.String())
+}
+
+// SendJSON writes an event of type event whose data is the JSON encoding of
+// v.
+func (w *Writer) SendJSON(event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.Send(Event{Event: event, Data: string(data)})
+}
+
+// Comment writes a comment, which clients ignore. Each line of text becomes
+// its own comment line.
+func (w *Writer) Comment(text string) error {
+	var b strings.Builder
+	for _, line := range splitLines(text) {
+		b.WriteString(": " + line + "\n")
+	}
+	b.WriteString("\n")
+	return w.write(b.String())
+}
+
+// Close stops keep-alive comments. Later writes return [ErrClosed]. Close
+// does not close the underlying writer.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.done)
+	}
+	return nil
+}
+
+// write writes s and flushes it. After the first failure, every write
+// returns the same error, as the client has most likely gone away.
+func (w *Writer) write(s string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrClosed
+	}
+	if w.err != nil {
+		return w.err
+	}
+	if _, err := io.WriteString(w.w, s); err != nil {
+		w.err = err
+		return err
+	}
+	if err := w.flush(); err != nil {
+		w.err = err
+		return err
+	}
+	if w.written != nil {
+		select {
+		case w.written <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (w *Writer) sendKeepAlives() {
+	timer := time.NewTimer(w.keepAlive)
+	defer timer.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.written:
+			timer.Reset(w.keepAlive)
+		case <-timer.C:
+			if err := w.write(":\n\n"); err != nil {
+				return
+			}
+			timer.Reset(w.keepAlive)
+		}
+	}
+}
+
+// splitLines splits s at CRLF, CR, and LF line breaks, which clients all
+// treat as the end of a field.
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.Split(s, "\n")
+}
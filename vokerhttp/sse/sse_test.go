@@ -0,0 +1,119 @@
+package sse
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_Send(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewWriter(rec)
+	defer w.Close()
+
+	require.NoError(t, w.Send(Event{Data: "hello"}))
+	require.NoError(t, w.Send(Event{ID: "42", Event: "token", Data: "one\ntwo\r\nthree\rfour", Retry: 3 * time.Second}))
+	require.NoError(t, w.SendJSON("usage", map[string]int{"tokens": 7}))
+	require.NoError(t, w.Comment("ping"))
+
+	assert.Equal(t, "data: hello\n\n"+
+		"id: 42\nevent: token\nretry: 3000\ndata: one\ndata: two\ndata: three\ndata: four\n\n"+
+		"event: usage\ndata: {\"tokens\":7}\n\n"+
+		": ping\n\n", rec.Body.String())
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	assert.True(t, rec.Flushed)
+}
+
+func TestWriter_KeepsHandlerHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	NewWriter(rec)
+
+	assert.Equal(t, "text/event-stream; charset=utf-8", rec.Header().Get("Content-Type"))
+}
+
+func TestWriter_InvalidFields(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	assert.ErrorIs(t, w.Send(Event{Event: "a\nb"}), errInvalidField)
+	assert.ErrorIs(t, w.Send(Event{ID: "a\rb"}), errInvalidField)
+	assert.Empty(t, buf.String())
+}
+
+type flushCounter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	flushes int
+}
+
+func (f *flushCounter) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.buf.Write(p)
+}
+
+func (f *flushCounter) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushes++
+	return nil
+}
+
+func (f *flushCounter) String() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.buf.String()
+}
+
+func TestWriter_FlushesEachEvent(t *testing.T) {
+	out := &flushCounter{}
+	w := NewWriter(out)
+
+	require.NoError(t, w.Send(Event{Data: "a"}))
+	require.NoError(t, w.Send(Event{Data: "b"}))
+	assert.Equal(t, 2, out.flushes)
+}
+
+func TestWriter_KeepAlive(t *testing.T) {
+	out := &flushCounter{}
+	w := NewWriter(out, WithKeepAlive(10*time.Millisecond))
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(out.String(), ":\n\n")
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, w.Close())
+	written := out.String()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, written, out.String(), "keep-alives stop after Close")
+	assert.ErrorIs(t, w.Send(Event{Data: "late"}), ErrClosed)
+}
+
+type failingWriter struct {
+	http.ResponseWriter
+	writes int
+}
+
+func (f *failingWriter) Write([]byte) (int, error) {
+	f.writes++
+	return 0, errors.New("connection reset")
+}
+
+func TestWriter_WriteError(t *testing.T) {
+	out := &failingWriter{ResponseWriter: httptest.NewRecorder()}
+	w := NewWriter(out)
+
+	assert.EqualError(t, w.Send(Event{Data: "a"}), "connection reset")
+	assert.EqualError(t, w.Send(Event{Data: "b"}), "connection reset")
+	assert.Equal(t, 1, out.writes, "writes stop after the first failure")
+}
@@ -0,0 +1,83 @@
+// Package vokerlogs provides [voker.LogSink] implementations that need no
+// dependencies beyond the standard library.
+package vokerlogs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hotsock/voker"
+)
+
+// HTTPSink posts each batch of log records to an HTTP endpoint as a JSON
+// array of Telemetry API events, the format Lambda itself delivers:
+//
+//	voker.Start(handler, voker.WithLogShipper(voker.LogShipper{
+//	    Sink: vokerlogs.NewHTTPSink("https://logs.example.com/ingest",
+//	        vokerlogs.WithHeader("Authorization", "Bearer "+token)),
+//	}))
+type HTTPSink struct {
+	url    string
+	header http.Header
+	client *http.Client
+}
+
+// HTTPOption configures an [HTTPSink].
+type HTTPOption func(*HTTPSink)
+
+// WithHeader adds a header to every request, such as an API key.
+func WithHeader(name, value string) HTTPOption {
+	return func(s *HTTPSink) {
+		s.header.Add(name, value)
+	}
+}
+
+// WithHTTPClient sets the client requests are sent with. The default is
+// http.DefaultClient.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(s *HTTPSink) {
+		s.client = client
+	}
+}
+
+// NewHTTPSink returns a sink that posts to url.
+func NewHTTPSink(url string, opts ...HTTPOption) *HTTPSink {
+	s := &HTTPSink{url: url, header: make(http.Header), client: http.DefaultClient}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WriteLogs implements [voker.LogSink]. Responses other than 2xx are
+// returned as errors, so the shipper retries them.
+func (s *HTTPSink) WriteLogs(ctx context.Context, events []voker.TelemetryEvent) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to encode log records: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range s.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send log records: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("log endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package vokerlogs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSink(t *testing.T) {
+	var header http.Header
+	var body string
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL, WithHeader("X-Api-Key", "secret"), WithHTTPClient(server.Client()))
+	events := []voker.TelemetryEvent{{
+		Time:   time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC),
+		Type:   "function",
+		Record: []byte(`{"level":"INFO","message":"hello"}`),
+	}}
+	require.NoError(t, sink.WriteLogs(context.Background(), events))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, "secret", header.Get("X-Api-Key"))
+	assert.JSONEq(t, `[{"time":"2024-05-01T13:00:00Z","type":"function","record":{"level":"INFO","message":"hello"}}]`, body)
+
+	status = http.StatusServiceUnavailable
+	assert.EqualError(t, sink.WriteLogs(context.Background(), events), "log endpoint returned status 503")
+}
@@ -0,0 +1,202 @@
+// Package vokermetrics provides [voker.MetricsSink] implementations that
+// need no dependencies beyond the standard library.
+package vokermetrics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hotsock/voker"
+)
+
+// EMFSink writes invocation metrics to CloudWatch in the Embedded Metric
+// Format. Lambda sends each line the function writes to CloudWatch Logs,
+// which extracts the metrics asynchronously, so publishing costs no API
+// calls and adds no latency:
+//
+//	voker.Start(handler, voker.WithMetrics(vokermetrics.NewEMFSink("Orders")))
+//
+// Every invocation publishes Invocations, ColdStarts, Errors, Duration,
+// ResponseLatency, NextLatency, RequestBytes, and ResponseBytes with the
+// FunctionName
+// dimension, and cold starts also publish InitDuration, the time the process
+// took to become ready for invocations. Failed invocations are additionally
+// published with the FunctionName and ErrorType dimensions, which gives
+// error counts by type. The tenant [voker.WithTenant] resolved is recorded
+// as the tenantId property, which CloudWatch Logs Insights can query.
+type EMFSink struct {
+	namespace       string
+	functionName    string
+	tenantDimension bool
+	mu              sync.Mutex
+	w               io.Writer
+	now             func() time.Time
+}
+
+// EMFOption configures an [EMFSink].
+type EMFOption func(*EMFSink)
+
+// WithWriter sets the destination for metric records. The default is
+// os.Stdout.
+func WithWriter(w io.Writer) EMFOption {
+	return func(s *EMFSink) {
+		s.w = w
+	}
+}
+
+// WithTenantDimension additionally publishes the metrics of invocations
+// with a tenant under the FunctionName and TenantId dimensions. Each tenant
+// creates new custom metrics, so use it only with a bounded set of tenants.
+func WithTenantDimension() EMFOption {
+	return func(s *EMFSink) {
+		s.tenantDimension = true
+	}
+}
+
+// NewEMFSink returns a sink that publishes metrics to the CloudWatch
+// namespace.
+func NewEMFSink(namespace string, opts ...EMFOption) *EMFSink {
+	s := &EMFSink{
+		namespace:    namespace,
+		functionName: os.Getenv("AWS_LAMBDA_FUNCTION_NAME"),
+		w:            os.Stdout,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+var emfMetrics = []emfMetric{
+	{Name: "Invocations", Unit: "Count"},
+	{Name: "ColdStarts", Unit: "Count"},
+	{Name: "Errors", Unit: "Count"},
+	{Name: "Duration", Unit: "Milliseconds"},
+	{Name: "ResponseLatency", Unit: "Milliseconds"},
+	{Name: "NextLatency", Unit: "Milliseconds"},
+	{Name: "RequestBytes", Unit: "Bytes"},
+	{Name: "ResponseBytes", Unit: "Bytes"},
+}
+
+var emfInitDuration = emfMetric{Name: "InitDuration", Unit: "Milliseconds"}
+
+// RecordInvocation implements [voker.MetricsSink]. Write errors are
+// discarded.
+func (s *EMFSink) RecordInvocation(_ context.Context, metrics voker.InvocationMetrics) {
+	dimensions := [][]string{{"FunctionName"}}
+	record := map[string]any{
+		"FunctionName":    s.functionName,
+		"requestId":       metrics.RequestID,
+		"Invocations":     1,
+		"ColdStarts":      boolCount(metrics.ColdStart),
+		"Errors":          boolCount(metrics.ErrorType != ""),
+		"Duration":        milliseconds(metrics.Duration),
+		"ResponseLatency": milliseconds(metrics.ResponseLatency),
+		"NextLatency":     milliseconds(metrics.NextLatency),
+		"RequestBytes":    metrics.RequestBytes,
+		"ResponseBytes":   metrics.ResponseBytes,
+	}
+	if metrics.ErrorType != "" {
+		record["ErrorType"] = metrics.ErrorType
+		dimensions = append(dimensions, []string{"FunctionName", "ErrorType"})
+	}
+	if metrics.TenantID != "" {
+		record["tenantId"] = metrics.TenantID
+		if s.tenantDimension {
+			record["TenantId"] = metrics.TenantID
+			dimensions = append(dimensions, []string{"FunctionName", "TenantId"})
+		}
+	}
+	published := emfMetrics
+	if metrics.InitDuration > 0 {
+		record["InitDuration"] = milliseconds(metrics.InitDuration)
+		published = append(published[:len(published):len(published)], emfInitDuration)
+	}
+	record["_aws"] = emfMetadata{
+		Timestamp: s.now().UnixMilli(),
+		CloudWatchMetrics: []emfDirective{{
+			Namespace:  s.namespace,
+			Dimensions: dimensions,
+			Metrics:    published,
+		}},
+	}
+
+	s.write(record)
+}
+
+// write writes record as one line.
+func (s *EMFSink) write(record map[string]any) {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.w.Write(b)
+}
+
+var emfHeartbeatMetrics = []emfMetric{
+	{Name: "Heartbeats", Unit: "Count"},
+	{Name: "Uptime", Unit: "Seconds"},
+	{Name: "InvocationsServed", Unit: "Count"},
+}
+
+// RecordHeartbeat implements [voker.HeartbeatSink]. It publishes
+// Heartbeats, Uptime, and InvocationsServed with the FunctionName
+// dimension, and records the environment as the environmentId property, so
+// CloudWatch Logs Insights can count distinct environments. Write errors
+// are discarded.
+func (s *EMFSink) RecordHeartbeat(_ context.Context, heartbeat voker.HeartbeatMetrics) {
+	record := map[string]any{
+		"FunctionName":      s.functionName,
+		"environmentId":     heartbeat.EnvironmentID,
+		"initType":          heartbeat.InitType,
+		"final":             heartbeat.Final,
+		"Heartbeats":        1,
+		"Uptime":            heartbeat.Uptime.Seconds(),
+		"InvocationsServed": heartbeat.Invocations,
+		"_aws": emfMetadata{
+			Timestamp: s.now().UnixMilli(),
+			CloudWatchMetrics: []emfDirective{{
+				Namespace:  s.namespace,
+				Dimensions: [][]string{{"FunctionName"}},
+				Metrics:    emfHeartbeatMetrics,
+			}},
+		},
+	}
+	s.write(record)
+}
+
+func boolCount(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,135 @@
+package vokermetrics
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEMFSink_RecordInvocation(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "orders")
+	var buf bytes.Buffer
+	sink := NewEMFSink("Orders", WithWriter(&buf))
+	sink.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	sink.RecordInvocation(context.Background(), voker.InvocationMetrics{
+		RequestID:       "request-1",
+		ColdStart:       true,
+		InitDuration:    250 * time.Millisecond,
+		Duration:        1500 * time.Microsecond,
+		RequestBytes:    10,
+		ResponseBytes:   20,
+		ResponseLatency: 2 * time.Millisecond,
+		NextLatency:     3 * time.Millisecond,
+	})
+	sink.RecordInvocation(context.Background(), voker.InvocationMetrics{
+		RequestID: "request-2",
+		ErrorType: "OrderNotFound",
+	})
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{
+		"_aws": {
+			"Timestamp": 1700000000000,
+			"CloudWatchMetrics": [{
+				"Namespace": "Orders",
+				"Dimensions": [["FunctionName"]],
+				"Metrics": [
+					{"Name": "Invocations", "Unit": "Count"},
+					{"Name": "ColdStarts", "Unit": "Count"},
+					{"Name": "Errors", "Unit": "Count"},
+					{"Name": "Duration", "Unit": "Milliseconds"},
+					{"Name": "ResponseLatency", "Unit": "Milliseconds"},
+					{"Name": "NextLatency", "Unit": "Milliseconds"},
+					{"Name": "RequestBytes", "Unit": "Bytes"},
+					{"Name": "ResponseBytes", "Unit": "Bytes"},
+					{"Name": "InitDuration", "Unit": "Milliseconds"}
+				]
+			}]
+		},
+		"FunctionName": "orders",
+		"requestId": "request-1",
+		"Invocations": 1,
+		"ColdStarts": 1,
+		"Errors": 0,
+		"Duration": 1.5,
+		"ResponseLatency": 2,
+		"NextLatency": 3,
+		"RequestBytes": 10,
+		"ResponseBytes": 20,
+		"InitDuration": 250
+	}`, lines[0])
+
+	assert.NotContains(t, lines[1], "InitDuration")
+
+	assert.Contains(t, lines[1], `"Dimensions":[["FunctionName"],["FunctionName","ErrorType"]]`)
+	assert.Contains(t, lines[1], `"ErrorType":"OrderNotFound"`)
+	assert.Contains(t, lines[1], `"Errors":1`)
+}
+
+func TestEMFSink_Tenant(t *testing.T) {
+	var buf bytes.Buffer
+	NewEMFSink("Orders", WithWriter(&buf)).RecordInvocation(context.Background(), voker.InvocationMetrics{TenantID: "acme"})
+	assert.Contains(t, buf.String(), `"tenantId":"acme"`)
+	assert.Contains(t, buf.String(), `"Dimensions":[["FunctionName"]]`)
+	assert.NotContains(t, buf.String(), `"TenantId"`)
+
+	buf.Reset()
+	sink := NewEMFSink("Orders", WithWriter(&buf), WithTenantDimension())
+	sink.RecordInvocation(context.Background(), voker.InvocationMetrics{TenantID: "acme", ErrorType: "OrderNotFound"})
+	assert.Contains(t, buf.String(), `"Dimensions":[["FunctionName"],["FunctionName","ErrorType"],["FunctionName","TenantId"]]`)
+	assert.Contains(t, buf.String(), `"TenantId":"acme"`)
+
+	buf.Reset()
+	sink.RecordInvocation(context.Background(), voker.InvocationMetrics{})
+	assert.NotContains(t, buf.String(), "enantId")
+}
+
+var (
+	_ voker.MetricsSink   = (*EMFSink)(nil)
+	_ voker.HeartbeatSink = (*EMFSink)(nil)
+)
+
+func TestEMFSink_RecordHeartbeat(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "orders")
+	var buf bytes.Buffer
+	sink := NewEMFSink("Orders", WithWriter(&buf))
+	sink.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	sink.RecordHeartbeat(context.Background(), voker.HeartbeatMetrics{
+		EnvironmentID: "2024/05/01/[$LATEST]abc",
+		InitType:      voker.InitProvisionedConcurrency,
+		Uptime:        90 * time.Second,
+		Invocations:   12,
+		Final:         true,
+	})
+
+	assert.JSONEq(t, `{
+		"_aws": {
+			"Timestamp": 1700000000000,
+			"CloudWatchMetrics": [{
+				"Namespace": "Orders",
+				"Dimensions": [["FunctionName"]],
+				"Metrics": [
+					{"Name": "Heartbeats", "Unit": "Count"},
+					{"Name": "Uptime", "Unit": "Seconds"},
+					{"Name": "InvocationsServed", "Unit": "Count"}
+				]
+			}]
+		},
+		"FunctionName": "orders",
+		"environmentId": "2024/05/01/[$LATEST]abc",
+		"initType": "provisioned-concurrency",
+		"final": true,
+		"Heartbeats": 1,
+		"Uptime": 90,
+		"InvocationsServed": 12
+	}`, buf.String())
+}
@@ -0,0 +1,117 @@
+package vokerotel
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hotsock/voker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+)
+
+// MetricsSink records voker invocation metrics with OpenTelemetry
+// instruments. Register it with [voker.WithMetrics]:
+//
+//	sink, err := vokerotel.NewMetricsSink(vokerotel.WithMeterProvider(mp))
+//	voker.Start(handler, voker.WithMetrics(sink))
+//
+// It records the faas.invocations, faas.coldstarts, faas.errors,
+// faas.invoke_duration, and (for cold starts) faas.init_duration semantic
+// convention metrics, with error.type on failures, plus voker.request.size,
+// voker.response.size, voker.runtime_api.duration, and
+// voker.runtime_api.next.duration.
+type MetricsSink struct {
+	meterProvider   metric.MeterProvider
+	invocations     metric.Int64Counter
+	coldStarts      metric.Int64Counter
+	errors          metric.Int64Counter
+	duration        metric.Float64Histogram
+	initDuration    metric.Float64Histogram
+	requestSize     metric.Int64Histogram
+	responseSize    metric.Int64Histogram
+	responseLatency metric.Float64Histogram
+	nextLatency     metric.Float64Histogram
+}
+
+// NewMetricsSink creates the sink's instruments on the configured meter
+// provider.
+func NewMetricsSink(opts ...Option) (*MetricsSink, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.meterProvider == nil {
+		o.meterProvider = otel.GetMeterProvider()
+	}
+
+	meter := o.meterProvider.Meter(instrumentationName)
+	s := &MetricsSink{
+		meterProvider: o.meterProvider,
+	}
+	var err error
+	var errs []error
+	record := func(e error) {
+		if e != nil {
+			errs = append(errs, e)
+		}
+	}
+	s.invocations, err = meter.Int64Counter("faas.invocations",
+		metric.WithDescription("Number of invocations."), metric.WithUnit("{invocation}"))
+	record(err)
+	s.coldStarts, err = meter.Int64Counter("faas.coldstarts",
+		metric.WithDescription("Number of invocation cold starts."), metric.WithUnit("{coldstart}"))
+	record(err)
+	s.errors, err = meter.Int64Counter("faas.errors",
+		metric.WithDescription("Number of invocation errors."), metric.WithUnit("{error}"))
+	record(err)
+	s.duration, err = meter.Float64Histogram("faas.invoke_duration",
+		metric.WithDescription("Duration of the handler."), metric.WithUnit("s"))
+	record(err)
+	s.initDuration, err = meter.Float64Histogram("faas.init_duration",
+		metric.WithDescription("Duration of the function's initialization."), metric.WithUnit("s"))
+	record(err)
+	s.requestSize, err = meter.Int64Histogram("voker.request.size",
+		metric.WithDescription("Size of invocation payloads."), metric.WithUnit("By"))
+	record(err)
+	s.responseSize, err = meter.Int64Histogram("voker.response.size",
+		metric.WithDescription("Size of buffered response payloads."), metric.WithUnit("By"))
+	record(err)
+	s.responseLatency, err = meter.Float64Histogram("voker.runtime_api.duration",
+		metric.WithDescription("Time taken to deliver results to the Lambda Runtime API."), metric.WithUnit("s"))
+	record(err)
+	s.nextLatency, err = meter.Float64Histogram("voker.runtime_api.next.duration",
+		metric.WithDescription("Round trip of the Lambda Runtime API request for the next invocation."), metric.WithUnit("s"))
+	record(err)
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RecordInvocation implements [voker.MetricsSink].
+func (s *MetricsSink) RecordInvocation(ctx context.Context, metrics voker.InvocationMetrics) {
+	s.invocations.Add(ctx, 1)
+	if metrics.ColdStart {
+		s.coldStarts.Add(ctx, 1)
+	}
+	if metrics.ErrorType != "" {
+		s.errors.Add(ctx, 1, metric.WithAttributes(semconv.ErrorTypeKey.String(metrics.ErrorType)))
+	}
+	s.duration.Record(ctx, metrics.Duration.Seconds())
+	if metrics.InitDuration > 0 {
+		s.initDuration.Record(ctx, metrics.InitDuration.Seconds())
+	}
+	s.requestSize.Record(ctx, int64(metrics.RequestBytes))
+	if metrics.ResponseBytes > 0 {
+		s.responseSize.Record(ctx, int64(metrics.ResponseBytes))
+	}
+	s.responseLatency.Record(ctx, metrics.ResponseLatency.Seconds())
+	s.nextLatency.Record(ctx, metrics.NextLatency.Seconds())
+
+	if f, ok := s.meterProvider.(flusher); ok {
+		if err := f.ForceFlush(ctx); err != nil {
+			otel.Handle(err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package vokerotel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	assert.Equal(t, instrumentationName, rm.ScopeMetrics[0].Scope.Name)
+
+	metrics := map[string]metricdata.Aggregation{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m.Data
+	}
+	return metrics
+}
+
+func TestMetricsSink_RecordInvocation(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+
+	sink, err := NewMetricsSink(WithMeterProvider(mp))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	sink.RecordInvocation(ctx, voker.InvocationMetrics{
+		ColdStart:       true,
+		InitDuration:    500 * time.Millisecond,
+		Duration:        100 * time.Millisecond,
+		RequestBytes:    10,
+		ResponseBytes:   20,
+		ResponseLatency: time.Millisecond,
+		NextLatency:     2 * time.Millisecond,
+	})
+	sink.RecordInvocation(ctx, voker.InvocationMetrics{
+		Duration:     200 * time.Millisecond,
+		RequestBytes: 30,
+		ErrorType:    "OrderNotFound",
+	})
+
+	metrics := collect(t, reader)
+
+	invocations := metrics["faas.invocations"].(metricdata.Sum[int64])
+	require.Len(t, invocations.DataPoints, 1)
+	assert.Equal(t, int64(2), invocations.DataPoints[0].Value)
+
+	coldStarts := metrics["faas.coldstarts"].(metricdata.Sum[int64])
+	assert.Equal(t, int64(1), coldStarts.DataPoints[0].Value)
+
+	errorCounts := metrics["faas.errors"].(metricdata.Sum[int64])
+	require.Len(t, errorCounts.DataPoints, 1)
+	assert.Equal(t, int64(1), errorCounts.DataPoints[0].Value)
+	errorType, ok := errorCounts.DataPoints[0].Attributes.Value(attribute.Key("error.type"))
+	require.True(t, ok)
+	assert.Equal(t, "OrderNotFound", errorType.AsString())
+
+	duration := metrics["faas.invoke_duration"].(metricdata.Histogram[float64])
+	assert.Equal(t, uint64(2), duration.DataPoints[0].Count)
+	assert.InDelta(t, 0.3, duration.DataPoints[0].Sum, 1e-9)
+
+	initDuration := metrics["faas.init_duration"].(metricdata.Histogram[float64])
+	assert.Equal(t, uint64(1), initDuration.DataPoints[0].Count)
+	assert.InDelta(t, 0.5, initDuration.DataPoints[0].Sum, 1e-9)
+
+	requestSize := metrics["voker.request.size"].(metricdata.Histogram[int64])
+	assert.Equal(t, int64(40), requestSize.DataPoints[0].Sum)
+	responseSize := metrics["voker.response.size"].(metricdata.Histogram[int64])
+	assert.Equal(t, uint64(1), responseSize.DataPoints[0].Count)
+	assert.Contains(t, metrics, "voker.runtime_api.duration")
+	nextLatency := metrics["voker.runtime_api.next.duration"].(metricdata.Histogram[float64])
+	assert.Equal(t, uint64(2), nextLatency.DataPoints[0].Count)
+	assert.InDelta(t, 0.002, nextLatency.DataPoints[0].Sum, 1e-9)
+}
+
+var _ voker.MetricsSink = (*MetricsSink)(nil)
@@ -0,0 +1,211 @@
+// Package vokerotel instruments voker handlers with OpenTelemetry tracing.
+//
+// [Middleware] starts a span for every invocation, continues the trace that
+// invoked the function, and flushes the tracer provider before voker reports
+// the result to Lambda, so spans are exported before the execution
+// environment is frozen:
+//
+//	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+//	voker.Start(handler, voker.WithMiddleware(vokerotel.Middleware(
+//	    vokerotel.WithTracerProvider(tp),
+//	)))
+package vokerotel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hotsock/voker"
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/hotsock/voker/vokerotel"
+
+type options struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// Option configures [Middleware] and [NewMetricsSink].
+type Option func(*options)
+
+// WithTracerProvider sets the tracer provider used to create invocation
+// spans. If it implements ForceFlush(context.Context) error, as the SDK
+// provider does, it is flushed after every invocation. The default is the
+// global provider from otel.GetTracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider used by [NewMetricsSink]. If it
+// implements ForceFlush(context.Context) error, as the SDK provider does, it
+// is flushed after every invocation. The default is the global provider from
+// otel.GetMeterProvider.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(o *options) {
+		o.meterProvider = mp
+	}
+}
+
+// WithPropagator sets the propagator used to extract the parent trace
+// context. The default extracts the Lambda X-Ray trace header and then a W3C
+// traceparent header from HTTP events, so an upstream W3C trace takes
+// precedence over the Lambda-managed X-Ray segment.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(o *options) {
+		o.propagator = propagator
+	}
+}
+
+type flusher interface {
+	ForceFlush(context.Context) error
+}
+
+// Middleware returns voker middleware that wraps each invocation in a server
+// span named after the function. The span records the faas.* and cloud.*
+// semantic convention attributes available to the function, plus tenant.id
+// when an earlier [voker.WithTenant] resolved a tenant, and its status
+// is set to Error when the handler fails or panics, with error.type set to
+// the reported Lambda errorType.
+func Middleware(opts ...Option) voker.Middleware {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.tracerProvider == nil {
+		o.tracerProvider = otel.GetTracerProvider()
+	}
+	if o.propagator == nil {
+		o.propagator = propagation.NewCompositeTextMapPropagator(xray.Propagator{}, propagation.TraceContext{})
+	}
+
+	tracer := o.tracerProvider.Tracer(instrumentationName)
+	functionName := os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	attrs := functionAttributes(functionName)
+	var invoked atomic.Bool
+
+	return func(next voker.InvokeFunc) voker.InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (output any, err error) {
+			lc, _ := voker.FromContext(ctx)
+			if lc == nil {
+				lc = &voker.LambdaContext{}
+			}
+
+			parent := o.propagator.Extract(ctx, traceCarrier(lc))
+			spanAttrs := append(attrs[:len(attrs):len(attrs)],
+				semconv.FaaSColdstart(!invoked.Swap(true)),
+				semconv.FaaSInvocationID(lc.AwsRequestID),
+			)
+			if lc.InvokedFunctionArn != "" {
+				spanAttrs = append(spanAttrs, semconv.CloudResourceID(lc.InvokedFunctionArn))
+				if account := accountID(lc.InvokedFunctionArn); account != "" {
+					spanAttrs = append(spanAttrs, semconv.CloudAccountID(account))
+				}
+			}
+			if tenant, ok := voker.TenantFromContext(ctx); ok {
+				spanAttrs = append(spanAttrs, attribute.String("tenant.id", tenant))
+			}
+
+			spanCtx, span := tracer.Start(parent, functionName,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(spanAttrs...),
+			)
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					span.SetStatus(codes.Error, fmt.Sprint(recovered))
+					span.SetAttributes(semconv.ErrorTypeKey.String("Runtime.Panic"))
+					end(ctx, span, o.tracerProvider)
+					panic(recovered)
+				}
+				end(ctx, span, o.tracerProvider)
+			}()
+
+			output, err = next(spanCtx, payload)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				span.SetAttributes(errorType(err))
+			}
+			return output, err
+		}
+	}
+}
+
+func end(ctx context.Context, span trace.Span, tp trace.TracerProvider) {
+	span.End()
+	if f, ok := tp.(flusher); ok {
+		if err := f.ForceFlush(ctx); err != nil {
+			otel.Handle(err)
+		}
+	}
+}
+
+func functionAttributes(functionName string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.CloudProviderAWS,
+		semconv.CloudPlatformAWSLambda,
+		semconv.FaaSName(functionName),
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		attrs = append(attrs, semconv.CloudRegion(region))
+	}
+	if version := os.Getenv("AWS_LAMBDA_FUNCTION_VERSION"); version != "" {
+		attrs = append(attrs, semconv.FaaSVersion(version))
+	}
+	if stream := os.Getenv("AWS_LAMBDA_LOG_STREAM_NAME"); stream != "" {
+		attrs = append(attrs, semconv.FaaSInstance(stream))
+	}
+	if memory, err := strconv.Atoi(os.Getenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE")); err == nil {
+		attrs = append(attrs, semconv.FaaSMaxMemory(memory*1024*1024))
+	}
+	return attrs
+}
+
+// traceCarrier collects the headers a propagator may extract from: the
+// Lambda X-Ray trace header and the W3C Trace Context of HTTP events.
+func traceCarrier(lc *voker.LambdaContext) propagation.HeaderCarrier {
+	header := http.Header{}
+	if lc.TraceID != "" {
+		header.Set("X-Amzn-Trace-Id", lc.TraceID)
+	}
+	if lc.TraceParent != "" {
+		header.Set("Traceparent", lc.TraceParent)
+	}
+	if lc.TraceState != "" {
+		header.Set("Tracestate", lc.TraceState)
+	}
+	return propagation.HeaderCarrier(header)
+}
+
+func errorType(err error) attribute.KeyValue {
+	if typed, ok := errors.AsType[*voker.ErrorResponse](err); ok && typed.Type != "" {
+		return semconv.ErrorTypeKey.String(typed.Type)
+	}
+	return semconv.ErrorTypeOther
+}
+
+// accountID returns the account ID field of a Lambda function ARN.
+func accountID(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+	return parts[4]
+}
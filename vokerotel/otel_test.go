@@ -0,0 +1,180 @@
+package vokerotel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testFunctionARN = "arn:aws:lambda:us-east-1:123456789012:function:orders"
+	testXRayHeader  = "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"
+)
+
+type flushRecorder struct {
+	*sdktrace.TracerProvider
+	flushes int
+}
+
+func (r *flushRecorder) ForceFlush(ctx context.Context) error {
+	r.flushes++
+	return r.TracerProvider.ForceFlush(ctx)
+}
+
+func newTestProvider(t *testing.T) (*flushRecorder, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return &flushRecorder{TracerProvider: tp}, recorder
+}
+
+func invoke(t *testing.T, middleware voker.Middleware, payload string, next voker.InvokeFunc) (any, error) {
+	t.Helper()
+
+	ctx := voker.NewContext(context.Background(), &voker.LambdaContext{
+		AwsRequestID:       "request-1",
+		InvokedFunctionArn: testFunctionARN,
+		TraceID:            testXRayHeader,
+	})
+	return middleware(next)(ctx, json.RawMessage(payload))
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestMiddleware_Span(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "orders")
+	t.Setenv("AWS_LAMBDA_FUNCTION_VERSION", "$LATEST")
+	t.Setenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE", "128")
+	t.Setenv("AWS_REGION", "us-east-1")
+	tp, recorder := newTestProvider(t)
+	middleware := Middleware(WithTracerProvider(tp))
+
+	var handlerSpan trace.SpanContext
+	output, err := invoke(t, middleware, `{}`, func(ctx context.Context, _ json.RawMessage) (any, error) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", output)
+	assert.Equal(t, 1, tp.flushes)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "orders", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+	assert.Equal(t, span.SpanContext(), handlerSpan)
+	assert.Equal(t, "5759e988bd862e3fe1be46a994272793", span.SpanContext().TraceID().String())
+	assert.Equal(t, "53995c3f42cd8ad8", span.Parent().SpanID().String())
+
+	attrs := attributes(span)
+	assert.Equal(t, "aws", attrs["cloud.provider"].AsString())
+	assert.Equal(t, "aws_lambda", attrs["cloud.platform"].AsString())
+	assert.Equal(t, "us-east-1", attrs["cloud.region"].AsString())
+	assert.Equal(t, "123456789012", attrs["cloud.account.id"].AsString())
+	assert.Equal(t, testFunctionARN, attrs["cloud.resource_id"].AsString())
+	assert.Equal(t, "orders", attrs["faas.name"].AsString())
+	assert.Equal(t, "$LATEST", attrs["faas.version"].AsString())
+	assert.Equal(t, int64(128*1024*1024), attrs["faas.max_memory"].AsInt64())
+	assert.Equal(t, "request-1", attrs["faas.invocation_id"].AsString())
+	assert.True(t, attrs["faas.coldstart"].AsBool())
+
+	_, err = invoke(t, middleware, `{}`, func(context.Context, json.RawMessage) (any, error) { return nil, nil })
+	require.NoError(t, err)
+	spans = recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.False(t, attributes(spans[1])["faas.coldstart"].AsBool())
+	assert.NotContains(t, attributes(spans[1]), attribute.Key("tenant.id"))
+}
+
+func TestMiddleware_Tenant(t *testing.T) {
+	tp, recorder := newTestProvider(t)
+	ctx := voker.NewTenantContext(context.Background(), "acme")
+
+	next := func(context.Context, json.RawMessage) (any, error) { return nil, nil }
+	_, err := Middleware(WithTracerProvider(tp))(next)(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "acme", attributes(recorder.Ended()[0])["tenant.id"].AsString())
+}
+
+func TestMiddleware_W3CParent(t *testing.T) {
+	tp, recorder := newTestProvider(t)
+	ctx := voker.NewContext(context.Background(), &voker.LambdaContext{
+		TraceID:     testXRayHeader,
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+
+	next := func(context.Context, json.RawMessage) (any, error) { return nil, nil }
+	_, err := Middleware(WithTracerProvider(tp))(next)(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+
+	span := recorder.Ended()[0]
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+}
+
+func TestMiddleware_Error(t *testing.T) {
+	tp, recorder := newTestProvider(t)
+	middleware := Middleware(WithTracerProvider(tp))
+
+	_, err := invoke(t, middleware, `{}`, func(context.Context, json.RawMessage) (any, error) {
+		return nil, &voker.ErrorResponse{Type: "OrderNotFound", Message: "order not found"}
+	})
+	require.Error(t, err)
+
+	_, err = invoke(t, middleware, `{}`, func(context.Context, json.RawMessage) (any, error) {
+		return nil, errors.New("failed")
+	})
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "order not found", spans[0].Status().Description)
+	assert.Equal(t, "OrderNotFound", attributes(spans[0])["error.type"].AsString())
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+	assert.Equal(t, "_OTHER", attributes(spans[1])["error.type"].AsString())
+}
+
+func TestMiddleware_Panic(t *testing.T) {
+	tp, recorder := newTestProvider(t)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_, _ = invoke(t, Middleware(WithTracerProvider(tp)), `{}`, func(context.Context, json.RawMessage) (any, error) {
+			panic("boom")
+		})
+	})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "Runtime.Panic", attributes(spans[0])["error.type"].AsString())
+	assert.Equal(t, 1, tp.flushes)
+}
+
+func TestAccountID(t *testing.T) {
+	assert.Equal(t, "123456789012", accountID(testFunctionARN))
+	assert.Equal(t, "123456789012", accountID(testFunctionARN+":live"))
+	assert.Empty(t, accountID("not-an-arn"))
+}
@@ -0,0 +1,306 @@
+package vokerotel
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// OTLP protocols, as named by OTEL_EXPORTER_OTLP_PROTOCOL.
+const (
+	ProtocolGRPC         = "grpc"
+	ProtocolHTTPProtobuf = "http/protobuf"
+	ProtocolHTTPJSON     = "http/json"
+)
+
+const (
+	defaultOTLPHTTPEndpoint = "http://localhost:4318"
+	defaultOTLPGRPCEndpoint = "http://localhost:4317"
+	defaultOTLPTimeout      = 10 * time.Second
+)
+
+type telemetryOptions struct {
+	protocol       string
+	endpoint       string
+	logsURL        string
+	metricsURL     string
+	headers        map[string]string
+	insecure       bool
+	timeout        time.Duration
+	serviceName    string
+	disableMetrics bool
+}
+
+// TelemetryOption configures [NewTelemetrySink].
+type TelemetryOption func(*telemetryOptions)
+
+// WithOTLPProtocol sets the export protocol to [ProtocolGRPC],
+// [ProtocolHTTPProtobuf], or [ProtocolHTTPJSON], overriding
+// OTEL_EXPORTER_OTLP_PROTOCOL. The default is http/protobuf.
+func WithOTLPProtocol(protocol string) TelemetryOption {
+	return func(o *telemetryOptions) {
+		o.protocol = protocol
+	}
+}
+
+// WithOTLPEndpoint sets the collector's base URL, such as
+// "https://collector.example.com:4318", overriding
+// OTEL_EXPORTER_OTLP_ENDPOINT and the signal-specific endpoint variables.
+// Over HTTP, logs and metrics are posted to /v1/logs and /v1/metrics under
+// it. The default is http://localhost:4318, or http://localhost:4317 for
+// gRPC, where a collector running as a Lambda extension listens.
+func WithOTLPEndpoint(endpoint string) TelemetryOption {
+	return func(o *telemetryOptions) {
+		o.endpoint = endpoint
+		o.logsURL = ""
+		o.metricsURL = ""
+	}
+}
+
+// WithOTLPHeaders adds headers, or gRPC metadata, to every export, such as
+// a vendor's API key. They are merged over OTEL_EXPORTER_OTLP_HEADERS.
+func WithOTLPHeaders(headers map[string]string) TelemetryOption {
+	return func(o *telemetryOptions) {
+		for k, v := range headers {
+			o.headers[k] = v
+		}
+	}
+}
+
+// WithOTLPTimeout bounds each export, overriding OTEL_EXPORTER_OTLP_TIMEOUT.
+// The default is 10 seconds.
+func WithOTLPTimeout(timeout time.Duration) TelemetryOption {
+	return func(o *telemetryOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithServiceName sets the service.name resource attribute, overriding
+// OTEL_SERVICE_NAME. The default is the function name.
+func WithServiceName(name string) TelemetryOption {
+	return func(o *telemetryOptions) {
+		o.serviceName = name
+	}
+}
+
+// WithoutReportMetrics exports platform.report events as logs only, without
+// converting their measurements to metrics.
+func WithoutReportMetrics() TelemetryOption {
+	return func(o *telemetryOptions) {
+		o.disableMetrics = true
+	}
+}
+
+// newTelemetryOptions reads the standard OTLP exporter environment
+// variables and applies opts over them.
+func newTelemetryOptions(opts []TelemetryOption) (*telemetryOptions, error) {
+	o := &telemetryOptions{
+		protocol:    os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"),
+		endpoint:    os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		logsURL:     os.Getenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"),
+		metricsURL:  os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"),
+		headers:     map[string]string{},
+		timeout:     defaultOTLPTimeout,
+		serviceName: os.Getenv("OTEL_SERVICE_NAME"),
+	}
+	o.insecure, _ = strconv.ParseBool(os.Getenv("OTEL_EXPORTER_OTLP_INSECURE"))
+	if err := parseOTLPHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), o.headers); err != nil {
+		return nil, err
+	}
+	if raw := os.Getenv("OTEL_EXPORTER_OTLP_TIMEOUT"); raw != "" {
+		ms, err := strconv.Atoi(raw)
+		if err != nil || ms <= 0 {
+			return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_TIMEOUT %q", raw)
+		}
+		o.timeout = time.Duration(ms) * time.Millisecond
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.protocol == "" {
+		o.protocol = ProtocolHTTPProtobuf
+	}
+	if o.serviceName == "" {
+		o.serviceName = os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	}
+	switch o.protocol {
+	case ProtocolGRPC:
+		if o.endpoint == "" {
+			o.endpoint = defaultOTLPGRPCEndpoint
+		}
+	case ProtocolHTTPProtobuf, ProtocolHTTPJSON:
+		if o.endpoint == "" {
+			o.endpoint = defaultOTLPHTTPEndpoint
+		}
+		base := strings.TrimSuffix(o.endpoint, "/")
+		if o.logsURL == "" {
+			o.logsURL = base + "/v1/logs"
+		}
+		if o.metricsURL == "" {
+			o.metricsURL = base + "/v1/metrics"
+		}
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q", o.protocol)
+	}
+	return o, nil
+}
+
+// parseOTLPHeaders parses the W3C baggage-style key=value list of
+// OTEL_EXPORTER_OTLP_HEADERS, whose values are URL-encoded.
+func parseOTLPHeaders(raw string, headers map[string]string) error {
+	for entry := range strings.SplitSeq(raw, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS entry %q", entry)
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS entry %q: %w", entry, err)
+		}
+		headers[key] = decoded
+	}
+	return nil
+}
+
+// otlpExporter sends export requests to a collector over one protocol.
+type otlpExporter interface {
+	exportLogs(ctx context.Context, req *collogspb.ExportLogsServiceRequest) error
+	exportMetrics(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) error
+	close() error
+}
+
+func newOTLPExporter(o *telemetryOptions) (otlpExporter, error) {
+	if o.protocol == ProtocolGRPC {
+		return newGRPCExporter(o)
+	}
+	return &httpExporter{
+		client:     &http.Client{},
+		logsURL:    o.logsURL,
+		metricsURL: o.metricsURL,
+		headers:    o.headers,
+		json:       o.protocol == ProtocolHTTPJSON,
+	}, nil
+}
+
+type httpExporter struct {
+	client     *http.Client
+	logsURL    string
+	metricsURL string
+	headers    map[string]string
+	json       bool
+}
+
+func (e *httpExporter) exportLogs(ctx context.Context, req *collogspb.ExportLogsServiceRequest) error {
+	return e.post(ctx, e.logsURL, req)
+}
+
+func (e *httpExporter) exportMetrics(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) error {
+	return e.post(ctx, e.metricsURL, req)
+}
+
+func (e *httpExporter) post(ctx context.Context, endpoint string, msg proto.Message) error {
+	contentType := "application/x-protobuf"
+	marshal := proto.Marshal
+	if e.json {
+		contentType = "application/json"
+		marshal = protojson.Marshal
+	}
+	body, err := marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode OTLP request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("OTLP export to %s failed with status %d: %s", endpoint, resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (e *httpExporter) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+type grpcExporter struct {
+	conn    *grpc.ClientConn
+	logs    collogspb.LogsServiceClient
+	metrics colmetricspb.MetricsServiceClient
+	headers metadata.MD
+}
+
+// newGRPCExporter connects to the endpoint lazily. An http:// endpoint, or
+// one without a scheme when OTEL_EXPORTER_OTLP_INSECURE is true, uses a
+// plaintext connection; any other uses TLS.
+func newGRPCExporter(o *telemetryOptions) (*grpcExporter, error) {
+	target := o.endpoint
+	plaintext := o.insecure
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		target = u.Host
+		plaintext = u.Scheme == "http"
+	}
+	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if plaintext {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP gRPC client: %w", err)
+	}
+	return &grpcExporter{
+		conn:    conn,
+		logs:    collogspb.NewLogsServiceClient(conn),
+		metrics: colmetricspb.NewMetricsServiceClient(conn),
+		headers: metadata.New(o.headers),
+	}, nil
+}
+
+func (e *grpcExporter) exportLogs(ctx context.Context, req *collogspb.ExportLogsServiceRequest) error {
+	_, err := e.logs.Export(metadata.NewOutgoingContext(ctx, e.headers), req)
+	return err
+}
+
+func (e *grpcExporter) exportMetrics(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) error {
+	_, err := e.metrics.Export(metadata.NewOutgoingContext(ctx, e.headers), req)
+	return err
+}
+
+func (e *grpcExporter) close() error {
+	return e.conn.Close()
+}
@@ -0,0 +1,91 @@
+package vokerotel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clearOTLPEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"OTEL_EXPORTER_OTLP_PROTOCOL",
+		"OTEL_EXPORTER_OTLP_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_LOGS_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_HEADERS",
+		"OTEL_EXPORTER_OTLP_TIMEOUT",
+		"OTEL_EXPORTER_OTLP_INSECURE",
+		"OTEL_SERVICE_NAME",
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestNewTelemetryOptions_Defaults(t *testing.T) {
+	clearOTLPEnv(t)
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "orders")
+
+	o, err := newTelemetryOptions(nil)
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolHTTPProtobuf, o.protocol)
+	assert.Equal(t, "http://localhost:4318/v1/logs", o.logsURL)
+	assert.Equal(t, "http://localhost:4318/v1/metrics", o.metricsURL)
+	assert.Equal(t, 10*time.Second, o.timeout)
+	assert.Equal(t, "orders", o.serviceName)
+	assert.Empty(t, o.headers)
+}
+
+func TestNewTelemetryOptions_Env(t *testing.T) {
+	clearOTLPEnv(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://collector.example.com:4318/")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "https://metrics.example.com/ingest")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=secret%3D1, x-team = payments")
+	t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "2500")
+	t.Setenv("OTEL_SERVICE_NAME", "checkout")
+
+	o, err := newTelemetryOptions([]TelemetryOption{WithOTLPHeaders(map[string]string{"x-team": "billing"})})
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolHTTPJSON, o.protocol)
+	assert.Equal(t, "https://collector.example.com:4318/v1/logs", o.logsURL)
+	assert.Equal(t, "https://metrics.example.com/ingest", o.metricsURL)
+	assert.Equal(t, map[string]string{"api-key": "secret=1", "x-team": "billing"}, o.headers)
+	assert.Equal(t, 2500*time.Millisecond, o.timeout)
+	assert.Equal(t, "checkout", o.serviceName)
+}
+
+func TestNewTelemetryOptions_OptionsOverrideEnv(t *testing.T) {
+	clearOTLPEnv(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT", "https://logs.example.com/ingest")
+
+	o, err := newTelemetryOptions([]TelemetryOption{
+		WithOTLPProtocol(ProtocolGRPC),
+		WithOTLPEndpoint("http://localhost:9317"),
+		WithOTLPTimeout(time.Second),
+		WithServiceName("custom"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolGRPC, o.protocol)
+	assert.Equal(t, "http://localhost:9317", o.endpoint)
+	assert.Empty(t, o.logsURL)
+	assert.Equal(t, time.Second, o.timeout)
+	assert.Equal(t, "custom", o.serviceName)
+}
+
+func TestNewTelemetryOptions_Errors(t *testing.T) {
+	clearOTLPEnv(t)
+	_, err := newTelemetryOptions([]TelemetryOption{WithOTLPProtocol("thrift")})
+	assert.EqualError(t, err, `unsupported OTLP protocol "thrift"`)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "novalue")
+	_, err = newTelemetryOptions(nil)
+	assert.EqualError(t, err, `invalid OTEL_EXPORTER_OTLP_HEADERS entry "novalue"`)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "soon")
+	_, err = newTelemetryOptions(nil)
+	assert.EqualError(t, err, `invalid OTEL_EXPORTER_OTLP_TIMEOUT "soon"`)
+}
@@ -0,0 +1,343 @@
+package vokerotel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/hotsock/voker"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+const platformReport = "platform.report"
+
+// TelemetrySink is a [voker.LogSink] that exports Lambda telemetry to an
+// OpenTelemetry collector over OTLP. Function and extension log lines
+// become log records, with the severity and attributes of JSON log lines
+// preserved. Platform events become log records named after the event type,
+// such as "platform.start", and the measurements of each platform.report,
+// such as its duration and maximum memory used, also become metrics named
+// "aws.lambda.duration", "aws.lambda.max_memory_used", and so on.
+//
+// Ship telemetry to it with [voker.WithLogShipper], including the platform
+// stream for metrics:
+//
+//	sink, err := vokerotel.NewTelemetrySink()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	voker.Start(handler, voker.WithLogShipper(voker.LogShipper{
+//	    Sink:  sink,
+//	    Types: []voker.TelemetryType{voker.TelemetryPlatform, voker.TelemetryFunction},
+//	}))
+type TelemetrySink struct {
+	exporter       otlpExporter
+	timeout        time.Duration
+	resource       *resourcepb.Resource
+	disableMetrics bool
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewTelemetrySink returns a sink configured by the standard OTLP exporter
+// environment variables, so telemetry export can be set up without code
+// changes: OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_LOGS_ENDPOINT,
+// OTEL_EXPORTER_OTLP_METRICS_ENDPOINT, OTEL_EXPORTER_OTLP_PROTOCOL,
+// OTEL_EXPORTER_OTLP_HEADERS, OTEL_EXPORTER_OTLP_TIMEOUT,
+// OTEL_EXPORTER_OTLP_INSECURE, and OTEL_SERVICE_NAME. Options override
+// them. It returns an error for an unsupported protocol or malformed
+// variables; gRPC connections are made on first export.
+func NewTelemetrySink(opts ...TelemetryOption) (*TelemetrySink, error) {
+	o, err := newTelemetryOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := newOTLPExporter(o)
+	if err != nil {
+		return nil, err
+	}
+	return &TelemetrySink{
+		exporter:       exporter,
+		timeout:        o.timeout,
+		resource:       lambdaResource(o.serviceName),
+		disableMetrics: o.disableMetrics,
+	}, nil
+}
+
+// WriteLogs converts events to OTLP and exports them, logs first and then
+// the metrics of any platform.report events. It implements
+// [voker.LogSink].
+func (s *TelemetrySink) WriteLogs(ctx context.Context, events []voker.TelemetryEvent) error {
+	records := make([]*logspb.LogRecord, 0, len(events))
+	var metrics []*metricspb.Metric
+	for _, event := range events {
+		records = append(records, telemetryLogRecord(event))
+		if event.Type == platformReport && !s.disableMetrics {
+			metrics = append(metrics, reportMetrics(event)...)
+		}
+	}
+	scope := &commonpb.InstrumentationScope{Name: instrumentationName}
+
+	var errs []error
+	if len(records) > 0 {
+		ctx, cancel := context.WithTimeout(ctx, s.timeout)
+		errs = append(errs, s.exporter.exportLogs(ctx, &collogspb.ExportLogsServiceRequest{
+			ResourceLogs: []*logspb.ResourceLogs{{
+				Resource:  s.resource,
+				ScopeLogs: []*logspb.ScopeLogs{{Scope: scope, LogRecords: records}},
+			}},
+		}))
+		cancel()
+	}
+	if len(metrics) > 0 {
+		ctx, cancel := context.WithTimeout(ctx, s.timeout)
+		errs = append(errs, s.exporter.exportMetrics(ctx, &colmetricspb.ExportMetricsServiceRequest{
+			ResourceMetrics: []*metricspb.ResourceMetrics{{
+				Resource:     s.resource,
+				ScopeMetrics: []*metricspb.ScopeMetrics{{Scope: scope, Metrics: metrics}},
+			}},
+		}))
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+// Close releases the sink's connections. Call it after the runtime stops,
+// or from an OnSIGTERM hook after the log shipper has flushed.
+func (s *TelemetrySink) Close() error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.exporter.close()
+	})
+	return s.closeErr
+}
+
+// lambdaResource describes the function, following the OpenTelemetry
+// semantic conventions for AWS Lambda.
+func lambdaResource(serviceName string) *resourcepb.Resource {
+	attrs := []*commonpb.KeyValue{
+		stringAttr("cloud.provider", "aws"),
+		stringAttr("cloud.platform", "aws_lambda"),
+	}
+	for _, attr := range []struct{ key, value string }{
+		{"service.name", serviceName},
+		{"cloud.region", os.Getenv("AWS_REGION")},
+		{"faas.name", os.Getenv("AWS_LAMBDA_FUNCTION_NAME")},
+		{"faas.version", os.Getenv("AWS_LAMBDA_FUNCTION_VERSION")},
+	} {
+		if attr.value != "" {
+			attrs = append(attrs, stringAttr(attr.key, attr.value))
+		}
+	}
+	if memory, err := strconv.ParseInt(os.Getenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE"), 10, 64); err == nil {
+		attrs = append(attrs, &commonpb.KeyValue{Key: "faas.max_memory", Value: intValue(memory << 20)})
+	}
+	return &resourcepb.Resource{Attributes: attrs}
+}
+
+// telemetryLogRecord converts one Telemetry API event to a log record.
+func telemetryLogRecord(event voker.TelemetryEvent) *logspb.LogRecord {
+	record := &logspb.LogRecord{
+		TimeUnixNano:         unixNano(event.Time),
+		ObservedTimeUnixNano: unixNano(time.Now()),
+		Body:                 jsonValue(event.Record),
+		Attributes:           []*commonpb.KeyValue{stringAttr("aws.lambda.telemetry.type", event.Type)},
+	}
+	if strings.HasPrefix(event.Type, "platform.") {
+		record.EventName = event.Type
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(event.Record, &fields) != nil {
+		return record
+	}
+	if requestID := jsonString(fields["requestId"]); requestID != "" {
+		record.Attributes = append(record.Attributes, stringAttr("faas.invocation_id", requestID))
+	}
+	if level := jsonString(fields["level"]); level != "" {
+		record.SeverityText = level
+		record.SeverityNumber = severityNumber(level)
+	}
+	return record
+}
+
+// severityNumber maps the level names of Lambda's JSON log format, slog,
+// and common logging libraries to OTLP severities.
+func severityNumber(level string) logspb.SeverityNumber {
+	switch strings.ToUpper(level) {
+	case "TRACE":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_TRACE
+	case "DEBUG":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG
+	case "INFO":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	case "WARN", "WARNING":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case "ERROR":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	case "FATAL", "CRITICAL":
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL
+	}
+	return logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED
+}
+
+// reportMetrics converts the measurements of a platform.report event to
+// gauges, named by dropping each measurement's unit suffix: durationMs
+// becomes aws.lambda.duration in ms, and maxMemoryUsedMB becomes
+// aws.lambda.max_memory_used in MiBy. Each data point carries the report's
+// status.
+func reportMetrics(event voker.TelemetryEvent) []*metricspb.Metric {
+	var report struct {
+		Status  string                     `json:"status"`
+		Metrics map[string]json.RawMessage `json:"metrics"`
+	}
+	if json.Unmarshal(event.Record, &report) != nil {
+		return nil
+	}
+
+	var attrs []*commonpb.KeyValue
+	if report.Status != "" {
+		attrs = append(attrs, stringAttr("aws.lambda.status", report.Status))
+	}
+	names := make([]string, 0, len(report.Metrics))
+	for name := range report.Metrics {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	metrics := make([]*metricspb.Metric, 0, len(names))
+	for _, name := range names {
+		var value float64
+		if json.Unmarshal(report.Metrics[name], &value) != nil {
+			continue
+		}
+		base, unit := name, ""
+		switch {
+		case strings.HasSuffix(name, "Ms"):
+			base, unit = strings.TrimSuffix(name, "Ms"), "ms"
+		case strings.HasSuffix(name, "MB"):
+			base, unit = strings.TrimSuffix(name, "MB"), "MiBy"
+		}
+		metrics = append(metrics, &metricspb.Metric{
+			Name: "aws.lambda." + snakeCase(base),
+			Unit: unit,
+			Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+				DataPoints: []*metricspb.NumberDataPoint{{
+					TimeUnixNano: unixNano(event.Time),
+					Attributes:   attrs,
+					Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: value},
+				}},
+			}},
+		})
+	}
+	return metrics
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// jsonValue converts a JSON document to an OTLP value, keeping object keys
+// in their original order. Invalid JSON becomes a string value.
+func jsonValue(raw json.RawMessage) *commonpb.AnyValue {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	value, err := decodeJSONValue(dec)
+	if err != nil {
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: string(raw)}}
+	}
+	return value
+}
+
+func decodeJSONValue(dec *json.Decoder) (*commonpb.AnyValue, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '[' {
+			var values []*commonpb.AnyValue
+			for dec.More() {
+				value, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, value)
+			}
+			_, err := dec.Token()
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}, err
+		}
+		var fields []*commonpb.KeyValue
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, &commonpb.KeyValue{Key: key.(string), Value: value})
+		}
+		_, err := dec.Token()
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{Values: fields}}}, err
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: t}}, nil
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: t}}, nil
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return intValue(n), nil
+		}
+		f, err := t.Float64()
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: f}}, err
+	}
+	return &commonpb.AnyValue{}, nil
+}
+
+func jsonString(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		return ""
+	}
+	return s
+}
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func intValue(n int64) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: n}}
+}
+
+func unixNano(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano())
+}
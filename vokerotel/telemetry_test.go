@@ -0,0 +1,267 @@
+package vokerotel
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var telemetryTime = time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC)
+
+func testTelemetryEvents() []voker.TelemetryEvent {
+	return []voker.TelemetryEvent{
+		{Time: telemetryTime, Type: "function", Record: json.RawMessage(`"plain text line"`)},
+		{Time: telemetryTime, Type: "function", Record: json.RawMessage(`{"level":"WARN","msg":"slow","requestId":"req-1","attempt":2}`)},
+		{Time: telemetryTime, Type: "platform.report", Record: json.RawMessage(`{"requestId":"req-1","status":"success","metrics":{"durationMs":12.5,"billedDurationMs":13,"memorySizeMB":128,"maxMemoryUsedMB":64}}`)},
+	}
+}
+
+func TestTelemetryLogRecord(t *testing.T) {
+	events := testTelemetryEvents()
+
+	text := telemetryLogRecord(events[0])
+	assert.Equal(t, uint64(telemetryTime.UnixNano()), text.TimeUnixNano)
+	assert.Equal(t, "plain text line", text.Body.GetStringValue())
+	assert.Empty(t, text.EventName)
+	assert.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED, text.SeverityNumber)
+
+	structured := telemetryLogRecord(events[1])
+	assert.Equal(t, "WARN", structured.SeverityText)
+	assert.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_WARN, structured.SeverityNumber)
+	fields := structured.Body.GetKvlistValue().GetValues()
+	require.Len(t, fields, 4)
+	assert.Equal(t, "level", fields[0].Key, "object keys keep their order")
+	assert.Equal(t, int64(2), fields[3].Value.GetIntValue())
+	assert.Equal(t, map[string]string{
+		"aws.lambda.telemetry.type": "function",
+		"faas.invocation_id":        "req-1",
+	}, stringAttrs(structured.Attributes))
+
+	report := telemetryLogRecord(events[2])
+	assert.Equal(t, "platform.report", report.EventName)
+	assert.Equal(t, "req-1", stringAttrs(report.Attributes)["faas.invocation_id"])
+}
+
+func TestReportMetrics(t *testing.T) {
+	metrics := reportMetrics(testTelemetryEvents()[2])
+
+	got := map[string]float64{}
+	units := map[string]string{}
+	for _, metric := range metrics {
+		point := metric.GetGauge().GetDataPoints()[0]
+		got[metric.Name] = point.GetAsDouble()
+		units[metric.Name] = metric.Unit
+		assert.Equal(t, map[string]string{"aws.lambda.status": "success"}, stringAttrs(point.Attributes))
+		assert.Equal(t, uint64(telemetryTime.UnixNano()), point.TimeUnixNano)
+	}
+	assert.Equal(t, map[string]float64{
+		"aws.lambda.billed_duration": 13,
+		"aws.lambda.duration":        12.5,
+		"aws.lambda.max_memory_used": 64,
+		"aws.lambda.memory_size":     128,
+	}, got)
+	assert.Equal(t, "ms", units["aws.lambda.duration"])
+	assert.Equal(t, "MiBy", units["aws.lambda.memory_size"])
+}
+
+func TestJSONValue(t *testing.T) {
+	value := jsonValue(json.RawMessage(`{"a":[1,2.5,"x",true,null],"b":{"c":"d"}}`))
+	fields := value.GetKvlistValue().GetValues()
+	require.Len(t, fields, 2)
+	items := fields[0].Value.GetArrayValue().GetValues()
+	require.Len(t, items, 5)
+	assert.Equal(t, int64(1), items[0].GetIntValue())
+	assert.Equal(t, 2.5, items[1].GetDoubleValue())
+	assert.Equal(t, "x", items[2].GetStringValue())
+	assert.True(t, items[3].GetBoolValue())
+	assert.Nil(t, items[4].Value)
+	assert.Equal(t, "d", fields[1].Value.GetKvlistValue().GetValues()[0].Value.GetStringValue())
+
+	assert.Equal(t, "{not json", jsonValue(json.RawMessage(`{not json`)).GetStringValue())
+}
+
+func stringAttrs(attrs []*commonpb.KeyValue) map[string]string {
+	out := map[string]string{}
+	for _, attr := range attrs {
+		out[attr.Key] = attr.Value.GetStringValue()
+	}
+	return out
+}
+
+type otlpRequests struct {
+	mu      sync.Mutex
+	logs    []*collogspb.ExportLogsServiceRequest
+	metrics []*colmetricspb.ExportMetricsServiceRequest
+	headers []string
+}
+
+func newOTLPHTTPServer(t *testing.T, requests *otlpRequests, unmarshal func([]byte, proto.Message) error, contentType string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, contentType, r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests.mu.Lock()
+		defer requests.mu.Unlock()
+		requests.headers = append(requests.headers, r.Header.Get("Api-Key"))
+		switch r.URL.Path {
+		case "/v1/logs":
+			req := &collogspb.ExportLogsServiceRequest{}
+			require.NoError(t, unmarshal(body, req))
+			requests.logs = append(requests.logs, req)
+		case "/v1/metrics":
+			req := &colmetricspb.ExportMetricsServiceRequest{}
+			require.NoError(t, unmarshal(body, req))
+			requests.metrics = append(requests.metrics, req)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTelemetrySink_HTTP(t *testing.T) {
+	clearOTLPEnv(t)
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "orders")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE", "128")
+
+	for _, tt := range []struct {
+		protocol    string
+		contentType string
+		unmarshal   func([]byte, proto.Message) error
+	}{
+		{ProtocolHTTPProtobuf, "application/x-protobuf", proto.Unmarshal},
+		{ProtocolHTTPJSON, "application/json", protojson.Unmarshal},
+	} {
+		t.Run(tt.protocol, func(t *testing.T) {
+			requests := &otlpRequests{}
+			server := newOTLPHTTPServer(t, requests, tt.unmarshal, tt.contentType)
+
+			sink, err := NewTelemetrySink(
+				WithOTLPProtocol(tt.protocol),
+				WithOTLPEndpoint(server.URL),
+				WithOTLPHeaders(map[string]string{"api-key": "secret"}),
+			)
+			require.NoError(t, err)
+			defer sink.Close()
+			require.NoError(t, sink.WriteLogs(context.Background(), testTelemetryEvents()))
+
+			require.Len(t, requests.logs, 1)
+			resourceLogs := requests.logs[0].ResourceLogs[0]
+			assert.Equal(t, map[string]string{
+				"cloud.provider":  "aws",
+				"cloud.platform":  "aws_lambda",
+				"service.name":    "orders",
+				"cloud.region":    "us-east-1",
+				"faas.name":       "orders",
+				"faas.max_memory": "",
+			}, stringAttrs(resourceLogs.Resource.Attributes))
+			for _, attr := range resourceLogs.Resource.Attributes {
+				if attr.Key == "faas.max_memory" {
+					assert.Equal(t, int64(128<<20), attr.Value.GetIntValue())
+				}
+			}
+			assert.Equal(t, instrumentationName, resourceLogs.ScopeLogs[0].Scope.Name)
+			assert.Len(t, resourceLogs.ScopeLogs[0].LogRecords, 3)
+
+			require.Len(t, requests.metrics, 1)
+			assert.Len(t, requests.metrics[0].ResourceMetrics[0].ScopeMetrics[0].Metrics, 4)
+			assert.Equal(t, []string{"secret", "secret"}, requests.headers)
+		})
+	}
+}
+
+func TestTelemetrySink_WithoutReportMetrics(t *testing.T) {
+	clearOTLPEnv(t)
+	requests := &otlpRequests{}
+	server := newOTLPHTTPServer(t, requests, proto.Unmarshal, "application/x-protobuf")
+
+	sink, err := NewTelemetrySink(WithOTLPEndpoint(server.URL), WithoutReportMetrics())
+	require.NoError(t, err)
+	require.NoError(t, sink.WriteLogs(context.Background(), testTelemetryEvents()))
+	assert.Len(t, requests.logs, 1)
+	assert.Empty(t, requests.metrics)
+}
+
+func TestTelemetrySink_HTTPError(t *testing.T) {
+	clearOTLPEnv(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	sink, err := NewTelemetrySink(WithOTLPEndpoint(server.URL), WithoutReportMetrics())
+	require.NoError(t, err)
+	err = sink.WriteLogs(context.Background(), testTelemetryEvents())
+	assert.EqualError(t, err, "OTLP export to "+server.URL+"/v1/logs failed with status 429: quota exceeded")
+}
+
+type fakeLogsService struct {
+	collogspb.UnimplementedLogsServiceServer
+	requests chan *collogspb.ExportLogsServiceRequest
+	apiKeys  chan []string
+}
+
+func (s *fakeLogsService) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.apiKeys <- md.Get("api-key")
+	s.requests <- req
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+type fakeMetricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	requests chan *colmetricspb.ExportMetricsServiceRequest
+}
+
+func (s *fakeMetricsService) Export(_ context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	s.requests <- req
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func TestTelemetrySink_GRPC(t *testing.T) {
+	clearOTLPEnv(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	logs := &fakeLogsService{requests: make(chan *collogspb.ExportLogsServiceRequest, 1), apiKeys: make(chan []string, 1)}
+	metrics := &fakeMetricsService{requests: make(chan *colmetricspb.ExportMetricsServiceRequest, 1)}
+	server := grpc.NewServer()
+	collogspb.RegisterLogsServiceServer(server, logs)
+	colmetricspb.RegisterMetricsServiceServer(server, metrics)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	sink, err := NewTelemetrySink(
+		WithOTLPProtocol(ProtocolGRPC),
+		WithOTLPEndpoint("http://"+listener.Addr().String()),
+		WithOTLPHeaders(map[string]string{"api-key": "secret"}),
+	)
+	require.NoError(t, err)
+	defer sink.Close()
+	require.NoError(t, sink.WriteLogs(context.Background(), testTelemetryEvents()))
+
+	assert.Equal(t, []string{"secret"}, <-logs.apiKeys)
+	assert.Len(t, (<-logs.requests).ResourceLogs[0].ScopeLogs[0].LogRecords, 3)
+	assert.Len(t, (<-metrics.requests).ResourceMetrics[0].ScopeMetrics[0].Metrics, 4)
+	require.NoError(t, sink.Close())
+}
@@ -0,0 +1,153 @@
+// Package vokerpprof serves net/http/pprof profiles from inside a Lambda
+// execution environment.
+//
+// Importing this package registers the net/http/pprof handlers on
+// http.DefaultServeMux, as importing net/http/pprof does. The profiling
+// server itself uses its own mux on a loopback address, so profiles are
+// never exposed through a function's own HTTP handler.
+package vokerpprof
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	runtimepprof "runtime/pprof"
+	"time"
+
+	"github.com/hotsock/voker"
+)
+
+// ExtensionName is the name the profiling extension registers with.
+const ExtensionName = "voker-pprof"
+
+type options struct {
+	profileDir string
+}
+
+// Option configures [WithPprof] and [Extension].
+type Option func(*options)
+
+// WithSIGTERMProfiles writes heap, goroutine, and allocs profiles to dir when
+// Lambda sends SIGTERM before shutting the execution environment down, such
+// as dir "/tmp". Files are named <profile>-<unix seconds>.pprof.
+func WithSIGTERMProfiles(dir string) Option {
+	return func(o *options) {
+		o.profileDir = dir
+	}
+}
+
+// WithPprof serves the net/http/pprof endpoints under /debug/pprof/ on addr,
+// which must be a loopback address such as "127.0.0.1:6060". An external
+// extension, or code in the function itself, can then capture profiles while
+// invocations run:
+//
+//	voker.Start(handler, vokerpprof.WithPprof("127.0.0.1:6060",
+//	    vokerpprof.WithSIGTERMProfiles("/tmp"),
+//	))
+//
+// The server runs as an internal extension, which also makes Lambda deliver
+// SIGTERM to the function. Internal extensions are not supported on Lambda
+// Managed Instances.
+func WithPprof(addr string, opts ...Option) voker.Option {
+	return voker.WithInternalExtension(Extension(addr, opts...))
+}
+
+// Extension returns the internal extension that [WithPprof] registers.
+func Extension(addr string, opts ...Option) voker.InternalExtension {
+	s := newServer(addr, opts...)
+	return voker.InternalExtension{
+		Name:      ExtensionName,
+		OnInit:    s.start,
+		OnSIGTERM: s.stop,
+	}
+}
+
+type server struct {
+	addr       string
+	profileDir string
+	srv        *http.Server
+	listener   net.Listener
+	now        func() time.Time
+}
+
+func newServer(addr string, opts ...Option) *server {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return &server{
+		addr:       addr,
+		profileDir: o.profileDir,
+		srv:        &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+		now:        time.Now,
+	}
+}
+
+func (s *server) start() error {
+	if err := checkLoopback(s.addr); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for pprof: %w", err)
+	}
+	s.listener = listener
+
+	go func() { _ = s.srv.Serve(listener) }()
+	return nil
+}
+
+func (s *server) stop(ctx context.Context) {
+	if s.profileDir != "" {
+		// Best effort: the environment is shutting down and there is no
+		// invocation to report a failure to.
+		_ = s.writeProfiles()
+	}
+	_ = s.srv.Shutdown(ctx)
+}
+
+func (s *server) writeProfiles() error {
+	suffix := fmt.Sprintf("-%d.pprof", s.now().Unix())
+	var errs []error
+	for _, name := range []string{"heap", "goroutine", "allocs"} {
+		profile := runtimepprof.Lookup(name)
+		if profile == nil {
+			continue
+		}
+		f, err := os.Create(filepath.Join(s.profileDir, name+suffix))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, profile.WriteTo(f, 0), f.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid pprof address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("pprof address %q is not a loopback address", addr)
+}
@@ -0,0 +1,61 @@
+package vokerpprof
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ServesProfiles(t *testing.T) {
+	s := newServer("127.0.0.1:0")
+	require.NoError(t, s.start())
+	t.Cleanup(func() { s.stop(context.Background()) })
+
+	resp, err := http.Get("http://" + s.listener.Addr().String() + "/debug/pprof/goroutine?debug=1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), "goroutine profile")
+}
+
+func TestServer_RejectsNonLoopback(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:6060", ":6060", "example.com:6060", "6060"} {
+		assert.Error(t, newServer(addr).start(), addr)
+	}
+	assert.NoError(t, checkLoopback("localhost:6060"))
+	assert.NoError(t, checkLoopback("[::1]:6060"))
+}
+
+func TestServer_SIGTERMProfiles(t *testing.T) {
+	dir := t.TempDir()
+	s := newServer("127.0.0.1:0", WithSIGTERMProfiles(dir))
+	s.now = func() time.Time { return time.Unix(1700000000, 0) }
+	require.NoError(t, s.start())
+
+	s.stop(context.Background())
+
+	for _, name := range []string{"heap", "goroutine", "allocs"} {
+		info, err := os.Stat(filepath.Join(dir, name+"-1700000000.pprof"))
+		require.NoError(t, err, name)
+		assert.Positive(t, info.Size(), name)
+	}
+	_, err := http.Get("http://" + s.listener.Addr().String() + "/debug/pprof/")
+	assert.Error(t, err, "server must be shut down")
+}
+
+func TestExtension(t *testing.T) {
+	ext := Extension("127.0.0.1:0")
+	assert.Equal(t, ExtensionName, ext.Name)
+	assert.NotNil(t, ext.OnInit)
+	assert.NotNil(t, ext.OnSIGTERM)
+	assert.Nil(t, ext.OnInvoke)
+}
@@ -0,0 +1,234 @@
+package vokerslog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/hotsock/voker"
+)
+
+const defaultMaxBufferedRecords = 1000
+
+// BufferingHandler wraps a [slog.Handler] and holds an invocation's
+// low-level records in memory, writing them only if the invocation fails.
+// Successful invocations log just their warnings and errors, while failed
+// ones keep the full detail that led up to the failure:
+//
+//	logs := vokerslog.NewBufferingHandler(
+//	    vokerslog.NewHandler(os.Stderr, vokerslog.WithLevel(slog.LevelDebug)))
+//	logger := slog.New(logs)
+//	voker.Start(handler, voker.WithLogger(logger), voker.WithMiddleware(logs.Middleware()))
+//
+// Records are buffered per invocation between the start and end of
+// [BufferingHandler.Middleware], and are written when the handler returns an
+// error or panics, when an ERROR record is logged, or when
+// [BufferingHandler.Flush] is called. Records logged without an invocation
+// context, such as during initialization, are written immediately. Records
+// the wrapped handler isn't enabled for are dropped as usual, so its level
+// must be at or below the buffer level.
+type BufferingHandler struct {
+	next  slog.Handler
+	state *bufferState
+}
+
+// bufferState is shared by a BufferingHandler and the handlers derived from
+// it with WithAttrs and WithGroup.
+type bufferState struct {
+	root         slog.Handler
+	level        slog.Leveler
+	maxRecords   int
+	flushOnError bool
+
+	mu          sync.Mutex
+	invocations map[string]*invocationBuffer
+}
+
+// invocationBuffer holds one invocation's records, oldest first.
+type invocationBuffer struct {
+	records []bufferedRecord
+	dropped int
+}
+
+type bufferedRecord struct {
+	handler slog.Handler
+	ctx     context.Context
+	record  slog.Record
+}
+
+// BufferOption configures a [BufferingHandler]. Pass options to
+// [NewBufferingHandler].
+type BufferOption func(*bufferState)
+
+// WithBufferLevel sets the highest level that is buffered. Defaults to INFO,
+// so WARN and ERROR records are written immediately.
+func WithBufferLevel(level slog.Leveler) BufferOption {
+	return func(s *bufferState) {
+		s.level = level
+	}
+}
+
+// WithMaxBufferedRecords sets how many records one invocation buffers.
+// When the buffer is full, the oldest record is dropped, and a flush
+// reports how many were. Defaults to 1000.
+func WithMaxBufferedRecords(n int) BufferOption {
+	return func(s *bufferState) {
+		s.maxRecords = n
+	}
+}
+
+// WithoutFlushOnErrorLog keeps the buffer when an ERROR record is logged, so
+// only a failed invocation or [BufferingHandler.Flush] writes it.
+func WithoutFlushOnErrorLog() BufferOption {
+	return func(s *bufferState) {
+		s.flushOnError = false
+	}
+}
+
+// NewBufferingHandler returns a [BufferingHandler] that writes to next.
+func NewBufferingHandler(next slog.Handler, options ...BufferOption) *BufferingHandler {
+	s := &bufferState{
+		root:         next,
+		level:        slog.LevelInfo,
+		maxRecords:   defaultMaxBufferedRecords,
+		flushOnError: true,
+		invocations:  make(map[string]*invocationBuffer),
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	if s.maxRecords <= 0 {
+		s.maxRecords = defaultMaxBufferedRecords
+	}
+	return &BufferingHandler{next: next, state: s}
+}
+
+// Middleware returns middleware that buffers the records of each invocation
+// and writes them if the invocation fails. Register it before other
+// middleware whose logs should be buffered.
+func (h *BufferingHandler) Middleware() voker.Middleware {
+	return func(next voker.InvokeFunc) voker.InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			id, ok := requestID(ctx)
+			if !ok {
+				return next(ctx, payload)
+			}
+			h.state.begin(id)
+
+			failed := true
+			defer func() {
+				h.state.end(id, failed)
+			}()
+			output, err := next(ctx, payload)
+			failed = err != nil
+			return output, err
+		}
+	}
+}
+
+// Flush writes the records buffered for the invocation that owns ctx. Later
+// records are buffered again.
+func (h *BufferingHandler) Flush(ctx context.Context) error {
+	id, ok := requestID(ctx)
+	if !ok {
+		return nil
+	}
+	return h.state.flush(id)
+}
+
+func (h *BufferingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *BufferingHandler) Handle(ctx context.Context, record slog.Record) error {
+	id, ok := requestID(ctx)
+	if !ok {
+		return h.next.Handle(ctx, record)
+	}
+	if record.Level <= h.state.level.Level() && h.state.add(id, bufferedRecord{h.next, ctx, record.Clone()}) {
+		return nil
+	}
+
+	var err error
+	if h.state.flushOnError && record.Level >= slog.LevelError {
+		err = h.state.flush(id)
+	}
+	return errors.Join(err, h.next.Handle(ctx, record))
+}
+
+func (h *BufferingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &BufferingHandler{next: h.next.WithAttrs(attrs), state: h.state}
+}
+
+func (h *BufferingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &BufferingHandler{next: h.next.WithGroup(name), state: h.state}
+}
+
+var _ slog.Handler = (*BufferingHandler)(nil)
+
+func (s *bufferState) begin(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invocations[id] = new(invocationBuffer)
+}
+
+// end stops buffering for an invocation, writing its records if it failed.
+func (s *bufferState) end(id string, failed bool) {
+	if failed {
+		_ = s.flush(id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.invocations, id)
+}
+
+// add buffers a record, reporting false when the invocation isn't
+// buffering.
+func (s *bufferState) add(id string, r bufferedRecord) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.invocations[id]
+	if !ok {
+		return false
+	}
+	if len(b.records) >= s.maxRecords {
+		b.records[0] = bufferedRecord{}
+		b.records = b.records[1:]
+		b.dropped++
+	}
+	b.records = append(b.records, r)
+	return true
+}
+
+// flush writes and clears an invocation's buffered records.
+func (s *bufferState) flush(id string) error {
+	s.mu.Lock()
+	b, ok := s.invocations[id]
+	if !ok || (len(b.records) == 0 && b.dropped == 0) {
+		s.mu.Unlock()
+		return nil
+	}
+	records, dropped := b.records, b.dropped
+	b.records, b.dropped = nil, 0
+	s.mu.Unlock()
+
+	var errs []error
+	if dropped > 0 {
+		r := slog.NewRecord(time.Now(), slog.LevelWarn, "dropped buffered log records", 0)
+		r.AddAttrs(slog.Int("dropped", dropped))
+		errs = append(errs, s.root.Handle(records[0].ctx, r))
+	}
+	for _, r := range records {
+		errs = append(errs, r.handler.Handle(r.ctx, r.record))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,145 @@
+package vokerslog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/hotsock/voker"
+	"github.com/hotsock/voker/vokerslog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferingHandler(t *testing.T) {
+	ctx := voker.NewContext(context.Background(), &voker.LambdaContext{AwsRequestID: "abc-123"})
+
+	newLogger := func(options ...vokerslog.BufferOption) (*slog.Logger, *vokerslog.BufferingHandler, *bytes.Buffer) {
+		buffer := new(bytes.Buffer)
+		h := vokerslog.NewBufferingHandler(vokerslog.NewHandler(buffer,
+			vokerslog.WithLevel(slog.LevelDebug), vokerslog.WithText(), vokerslog.WithoutTime()), options...)
+		return slog.New(h), h, buffer
+	}
+
+	invoke := func(h *vokerslog.BufferingHandler, fn func(ctx context.Context) error) error {
+		_, err := h.Middleware()(func(ctx context.Context, _ json.RawMessage) (any, error) {
+			return nil, fn(ctx)
+		})(ctx, nil)
+		return err
+	}
+
+	t.Run("discards the records of a successful invocation", func(t *testing.T) {
+		logger, h, buffer := newLogger()
+		require.NoError(t, invoke(h, func(ctx context.Context) error {
+			logger.DebugContext(ctx, "debug")
+			logger.InfoContext(ctx, "info")
+			logger.WarnContext(ctx, "warn")
+			return nil
+		}))
+
+		assert.Equal(t, `level="WARN" msg="warn" record.requestId="abc-123" type="app.log"`+"\n", buffer.String())
+	})
+
+	t.Run("writes the records of a failed invocation", func(t *testing.T) {
+		logger, h, buffer := newLogger()
+		err := invoke(h, func(ctx context.Context) error {
+			logger.With("order", 42).DebugContext(ctx, "debug")
+			logger.WarnContext(ctx, "warn")
+			return errors.New("boom")
+		})
+		require.EqualError(t, err, "boom")
+
+		assert.Equal(t, []string{
+			`level="WARN" msg="warn" record.requestId="abc-123" type="app.log"`,
+			`level="DEBUG" msg="debug" record.requestId="abc-123" type="app.log" order=42`,
+		}, strings.Split(strings.TrimSpace(buffer.String()), "\n"))
+
+		// The invocation is over, so later records are written immediately.
+		logger.DebugContext(ctx, "after")
+		assert.Contains(t, buffer.String(), `msg="after"`)
+	})
+
+	t.Run("writes the records of a panicking invocation", func(t *testing.T) {
+		logger, h, buffer := newLogger()
+		assert.Panics(t, func() {
+			_ = invoke(h, func(ctx context.Context) error {
+				logger.InfoContext(ctx, "info")
+				panic("boom")
+			})
+		})
+
+		assert.Contains(t, buffer.String(), `msg="info"`)
+	})
+
+	t.Run("flushes before an error record", func(t *testing.T) {
+		logger, h, buffer := newLogger()
+		require.NoError(t, invoke(h, func(ctx context.Context) error {
+			logger.InfoContext(ctx, "info")
+			logger.ErrorContext(ctx, "error")
+			logger.InfoContext(ctx, "discarded")
+			return nil
+		}))
+
+		assert.Equal(t, []string{
+			`level="INFO" msg="info" record.requestId="abc-123" type="app.log"`,
+			`level="ERROR" msg="error" record.requestId="abc-123" type="app.log"`,
+		}, strings.Split(strings.TrimSpace(buffer.String()), "\n"))
+	})
+
+	t.Run("WithoutFlushOnErrorLog", func(t *testing.T) {
+		logger, h, buffer := newLogger(vokerslog.WithoutFlushOnErrorLog())
+		require.NoError(t, invoke(h, func(ctx context.Context) error {
+			logger.InfoContext(ctx, "info")
+			logger.ErrorContext(ctx, "error")
+			return nil
+		}))
+
+		assert.NotContains(t, buffer.String(), `msg="info"`)
+		assert.Contains(t, buffer.String(), `msg="error"`)
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		logger, h, buffer := newLogger(vokerslog.WithBufferLevel(slog.LevelDebug))
+		require.NoError(t, invoke(h, func(ctx context.Context) error {
+			logger.DebugContext(ctx, "debug")
+			logger.InfoContext(ctx, "info")
+			require.NoError(t, h.Flush(ctx))
+			logger.DebugContext(ctx, "discarded")
+			return nil
+		}))
+
+		assert.Equal(t, []string{
+			`level="INFO" msg="info" record.requestId="abc-123" type="app.log"`,
+			`level="DEBUG" msg="debug" record.requestId="abc-123" type="app.log"`,
+		}, strings.Split(strings.TrimSpace(buffer.String()), "\n"))
+	})
+
+	t.Run("drops the oldest records when full", func(t *testing.T) {
+		logger, h, buffer := newLogger(vokerslog.WithMaxBufferedRecords(2))
+		_ = invoke(h, func(ctx context.Context) error {
+			for _, msg := range []string{"one", "two", "three", "four"} {
+				logger.InfoContext(ctx, msg)
+			}
+			return errors.New("boom")
+		})
+
+		assert.Equal(t, []string{
+			`level="WARN" msg="dropped buffered log records" record.requestId="abc-123" type="app.log" dropped=2`,
+			`level="INFO" msg="three" record.requestId="abc-123" type="app.log"`,
+			`level="INFO" msg="four" record.requestId="abc-123" type="app.log"`,
+		}, strings.Split(strings.TrimSpace(buffer.String()), "\n"))
+	})
+
+	t.Run("writes records outside an invocation immediately", func(t *testing.T) {
+		logger, _, buffer := newLogger()
+		logger.InfoContext(context.Background(), "init")
+		logger.InfoContext(ctx, "unbuffered")
+
+		assert.Contains(t, buffer.String(), `msg="init"`)
+		assert.Contains(t, buffer.String(), `msg="unbuffered"`)
+	})
+}
@@ -2,8 +2,9 @@
 //
 // It auto-configures log format (JSON or text) and level from Lambda's
 // advanced logging environment variables, and enriches every record with
-// Lambda metadata (function name, version, and the request ID from the
-// invocation context). Options override the environment values.
+// Lambda metadata (function name, version, and the request ID, any
+// [voker.WithTenant] tenant, and any [voker.WithCorrelation] attributes from
+// the invocation context). Options override the environment values.
 //
 // See https://docs.aws.amazon.com/lambda/latest/dg/monitoring-logs.html
 //
@@ -12,6 +13,9 @@
 //	logger := slog.New(vokerslog.NewHandler(os.Stderr))
 //	slog.SetDefault(logger)
 //	voker.Start(handler, voker.WithLogger(logger))
+//
+// A [BufferingHandler] wraps a handler to write an invocation's low-level
+// records only when the invocation fails.
 package vokerslog
 
 import (
@@ -282,7 +286,10 @@ func (h *Handler) appendJSON(buf *buffer, ctx context.Context, record slog.Recor
 		buf.writeByte('"')
 	}
 
-	if reqID, hasReq := requestID(ctx); h.hasFunctionName || h.hasFunctionVersion || hasReq {
+	reqID, hasReq := requestID(ctx)
+	tenant, hasTenant := voker.TenantFromContext(ctx)
+	correlation := voker.CorrelationFromContext(ctx)
+	if h.hasFunctionName || h.hasFunctionVersion || hasReq || hasTenant || len(correlation) > 0 {
 		buf.writeString(`,"record":{`)
 		sep := false
 		writeField := func(key, val string) {
@@ -303,6 +310,18 @@ func (h *Handler) appendJSON(buf *buffer, ctx context.Context, record slog.Recor
 		if hasReq {
 			writeField("requestId", reqID)
 		}
+		if hasTenant {
+			writeField("tenantId", tenant)
+		}
+		for _, a := range correlation {
+			if sep {
+				buf.writeByte(',')
+			}
+			appendJSONString(buf, a.Key)
+			buf.writeByte(':')
+			appendJSONValue(buf, a.Value.Resolve())
+			sep = true
+		}
 		buf.writeByte('}')
 	}
 
@@ -513,6 +532,14 @@ func (h *Handler) appendText(buf *buffer, ctx context.Context, record slog.Recor
 		*buf = strconv.AppendQuote(*buf, reqID)
 		buf.writeByte(' ')
 	}
+	if tenant, ok := voker.TenantFromContext(ctx); ok {
+		buf.writeString("record.tenantId=")
+		*buf = strconv.AppendQuote(*buf, tenant)
+		buf.writeByte(' ')
+	}
+	for _, a := range voker.CorrelationFromContext(ctx) {
+		appendTextAttr(buf, []byte("record."), a)
+	}
 
 	if logType := h.recordType(record); logType != "" {
 		buf.writeString("type=")
@@ -170,6 +170,59 @@ func TestHandler(t *testing.T) {
 		})
 	})
 
+	t.Run("given a tenant", func(t *testing.T) {
+		ctx := voker.NewContext(context.Background(), &voker.LambdaContext{
+			AwsRequestID: "abc-123",
+		})
+		ctx = voker.NewTenantContext(ctx, "acme")
+
+		t.Run("JSON", func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			logger := slog.New(vokerslog.NewHandler(buffer, vokerslog.WithJSON()))
+
+			logger.InfoContext(ctx, t.Name())
+
+			assert.Contains(t, buffer.String(), `"record":{"requestId":"abc-123","tenantId":"acme"}`)
+		})
+
+		t.Run("Text", func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			logger := slog.New(vokerslog.NewHandler(buffer, vokerslog.WithText()))
+
+			logger.InfoContext(ctx, t.Name())
+
+			assert.Contains(t, buffer.String(), `record.requestId="abc-123" record.tenantId="acme"`)
+		})
+	})
+
+	t.Run("given correlation attributes", func(t *testing.T) {
+		ctx := voker.NewContext(context.Background(), &voker.LambdaContext{
+			AwsRequestID: "abc-123",
+		})
+		ctx = voker.NewCorrelationContext(ctx,
+			slog.String("apiRequestId", "api-1"),
+			slog.Any("sqsMessageIds", []string{"m1", "m2"}),
+		)
+
+		t.Run("JSON", func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			logger := slog.New(vokerslog.NewHandler(buffer, vokerslog.WithJSON()))
+
+			logger.InfoContext(ctx, t.Name())
+
+			assert.Contains(t, buffer.String(), `"record":{"requestId":"abc-123","apiRequestId":"api-1","sqsMessageIds":["m1","m2"]}`)
+		})
+
+		t.Run("Text", func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			logger := slog.New(vokerslog.NewHandler(buffer, vokerslog.WithText()))
+
+			logger.InfoContext(ctx, t.Name())
+
+			assert.Contains(t, buffer.String(), `record.requestId="abc-123" record.apiRequestId="api-1" record.sqsMessageIds=[m1 m2]`)
+		})
+	})
+
 	t.Run("emits a deterministic JSON shape", func(t *testing.T) {
 		buffer := new(bytes.Buffer)
 		logger := slog.New(vokerslog.NewHandler(buffer, vokerslog.WithJSON(), vokerslog.WithoutTime()))
@@ -0,0 +1,340 @@
+// Package vokerxray records AWS X-Ray subsegments under the segment Lambda
+// creates for each invocation, without depending on the X-Ray SDK.
+//
+// Subsegments are sent to the X-Ray daemon over UDP as soon as they are
+// closed. They are only recorded when active tracing is enabled and the
+// invocation is sampled; otherwise every call is a cheap no-op:
+//
+//	func handler(ctx context.Context, event Event) (Response, error) {
+//	    ctx, seg := vokerxray.BeginSubsegment(ctx, "load-order")
+//	    order, err := loadOrder(ctx, event.OrderID)
+//	    seg.AddAnnotation("order_id", event.OrderID)
+//	    seg.Close(err)
+//	    // ...
+//	}
+//
+// With [Middleware] installed, every invocation runs in a subsegment that
+// [AddAnnotation] and [AddMetadata] reach through the context, so handlers
+// can add searchable annotations to the trace without managing subsegments:
+//
+//	voker.Start(handler, voker.WithMiddleware(vokerxray.Middleware()))
+//
+//	// In the handler:
+//	vokerxray.AddAnnotation(ctx, "tenant", event.Tenant)
+package vokerxray
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hotsock/voker"
+)
+
+const (
+	// handlerSubsegmentName is the name Powertools for AWS Lambda gives the
+	// subsegment around each invocation.
+	handlerSubsegmentName = "## handler"
+
+	defaultDaemonAddress = "127.0.0.1:2000"
+	daemonHeader         = "{\"format\":\"json\",\"version\":1}\n"
+)
+
+// Subsegment is an X-Ray subsegment started by [BeginSubsegment]. A nil or
+// unsampled Subsegment ignores all calls, so callers never need to check
+// whether tracing is active. A Subsegment is safe for concurrent use.
+type Subsegment struct {
+	mu          sync.Mutex
+	traceID     string
+	parentID    string
+	id          string
+	name        string
+	namespace   string
+	start       time.Time
+	annotations map[string]any
+	metadata    map[string]any
+	closed      bool
+}
+
+type subsegmentKey struct{}
+
+// BeginSubsegment starts a subsegment named name. Its parent is the
+// subsegment already in ctx, if any, or the invocation's Lambda function
+// segment. The returned context carries the new subsegment so nested calls
+// become its children. Close must be called to record it.
+func BeginSubsegment(ctx context.Context, name string) (context.Context, *Subsegment) {
+	traceID, parentID, ok := parentFromContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	seg := &Subsegment{
+		traceID:  traceID,
+		parentID: parentID,
+		id:       newID(),
+		name:     name,
+		start:    time.Now(),
+	}
+	return context.WithValue(ctx, subsegmentKey{}, seg), seg
+}
+
+// Capture runs fn in a subsegment named name and closes it with fn's error.
+func Capture(ctx context.Context, name string, fn func(context.Context) error) error {
+	ctx, seg := BeginSubsegment(ctx, name)
+	err := fn(ctx)
+	seg.Close(err)
+	return err
+}
+
+// Middleware returns middleware that runs each sampled invocation in a
+// subsegment, closed with the handler's error when it returns, or with a
+// fault when it panics. Annotations and metadata added with [AddAnnotation]
+// and [AddMetadata] outside any other subsegment are recorded on it.
+func Middleware() voker.Middleware {
+	return func(next voker.InvokeFunc) voker.InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (output any, err error) {
+			ctx, seg := BeginSubsegment(ctx, handlerSubsegmentName)
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					seg.Close(fmt.Errorf("panic: %v", recovered))
+					panic(recovered)
+				}
+				seg.Close(err)
+			}()
+			return next(ctx, payload)
+		}
+	}
+}
+
+// SubsegmentFromContext returns the innermost subsegment started in ctx, or
+// nil if there is none.
+func SubsegmentFromContext(ctx context.Context) *Subsegment {
+	seg, _ := ctx.Value(subsegmentKey{}).(*Subsegment)
+	return seg
+}
+
+// AddAnnotation records an annotation on the innermost subsegment in ctx,
+// which is the invocation's subsegment when [Middleware] is installed and
+// no other subsegment has begun. It does nothing when ctx has none.
+func AddAnnotation(ctx context.Context, key string, value any) {
+	SubsegmentFromContext(ctx).AddAnnotation(key, value)
+}
+
+// AddMetadata records metadata on the innermost subsegment in ctx, like
+// [AddAnnotation].
+func AddMetadata(ctx context.Context, key string, value any) {
+	SubsegmentFromContext(ctx).AddMetadata(key, value)
+}
+
+// TraceHeader returns the X-Amzn-Trace-Id header that makes a downstream
+// call part of the trace as a child of the subsegment, or "" for a nil
+// Subsegment.
+func (s *Subsegment) TraceHeader() string {
+	if s == nil {
+		return ""
+	}
+	return "Root=" + s.traceID + ";Parent=" + s.id + ";Sampled=1"
+}
+
+// SetNamespace marks the subsegment as a call to an AWS service ("aws") or
+// another HTTP service ("remote"), which X-Ray uses to draw downstream nodes
+// on the service map.
+func (s *Subsegment) SetNamespace(namespace string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.namespace = namespace
+}
+
+// AddAnnotation records an indexed key-value pair that can be used in X-Ray
+// filter expressions. Values should be strings, numbers, or booleans.
+func (s *Subsegment) AddAnnotation(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.annotations == nil {
+		s.annotations = make(map[string]any)
+	}
+	s.annotations[key] = value
+}
+
+// AddMetadata records a non-indexed value of any JSON-serializable type.
+func (s *Subsegment) AddMetadata(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.metadata == nil {
+		s.metadata = make(map[string]any)
+	}
+	s.metadata[key] = value
+}
+
+// Close ends the subsegment and sends it to the X-Ray daemon. A non-nil err
+// marks the subsegment as a fault and records the error as its cause. Calls
+// after the first are ignored. Delivery is best effort: send errors are
+// discarded so tracing never fails an invocation.
+func (s *Subsegment) Close(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	doc := s.document(time.Now(), err)
+	s.mu.Unlock()
+
+	b, marshalErr := json.Marshal(doc)
+	if marshalErr != nil {
+		// Metadata that cannot be encoded drops the metadata, not the timing.
+		doc.Metadata = nil
+		if b, marshalErr = json.Marshal(doc); marshalErr != nil {
+			return
+		}
+	}
+	defaultEmitter.send(b)
+}
+
+type document struct {
+	Name        string                    `json:"name"`
+	ID          string                    `json:"id"`
+	TraceID     string                    `json:"trace_id"`
+	ParentID    string                    `json:"parent_id"`
+	Type        string                    `json:"type"`
+	StartTime   float64                   `json:"start_time"`
+	EndTime     float64                   `json:"end_time"`
+	Namespace   string                    `json:"namespace,omitempty"`
+	Fault       bool                      `json:"fault,omitempty"`
+	Cause       *cause                    `json:"cause,omitempty"`
+	Annotations map[string]any            `json:"annotations,omitempty"`
+	Metadata    map[string]map[string]any `json:"metadata,omitempty"`
+}
+
+type cause struct {
+	Exceptions []exception `json:"exceptions"`
+}
+
+type exception struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+func (s *Subsegment) document(end time.Time, err error) document {
+	doc := document{
+		Name:        s.name,
+		ID:          s.id,
+		TraceID:     s.traceID,
+		ParentID:    s.parentID,
+		Type:        "subsegment",
+		StartTime:   epochSeconds(s.start),
+		EndTime:     epochSeconds(end),
+		Namespace:   s.namespace,
+		Annotations: s.annotations,
+	}
+	if len(s.metadata) > 0 {
+		doc.Metadata = map[string]map[string]any{"default": s.metadata}
+	}
+	if err != nil {
+		doc.Fault = true
+		doc.Cause = &cause{Exceptions: []exception{{ID: newID(), Message: err.Error()}}}
+	}
+	return doc
+}
+
+func epochSeconds(t time.Time) float64 {
+	return float64(t.UnixMicro()) / 1e6
+}
+
+// parentFromContext returns the trace and parent IDs for a new subsegment.
+func parentFromContext(ctx context.Context) (traceID, parentID string, ok bool) {
+	if parent := SubsegmentFromContext(ctx); parent != nil {
+		return parent.traceID, parent.id, true
+	}
+
+	lc, ok := voker.FromContext(ctx)
+	if !ok {
+		return "", "", false
+	}
+	return parseTraceHeader(lc.TraceID)
+}
+
+// parseTraceHeader parses an X-Amzn-Trace-Id header such as
+// "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1".
+// It reports false unless the header has a root and parent and is sampled.
+func parseTraceHeader(header string) (traceID, parentID string, ok bool) {
+	var sampled bool
+	for field := range strings.SplitSeq(header, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "Root":
+			traceID = value
+		case "Parent":
+			parentID = value
+		case "Sampled":
+			sampled = value == "1"
+		}
+	}
+	return traceID, parentID, sampled && traceID != "" && parentID != ""
+}
+
+func newID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+var defaultEmitter = &emitter{}
+
+// emitter sends segment documents to the X-Ray daemon. The connection is
+// opened on first use from AWS_XRAY_DAEMON_ADDRESS, which Lambda sets when
+// active tracing is enabled.
+type emitter struct {
+	once sync.Once
+	conn net.Conn
+}
+
+func (e *emitter) send(doc []byte) {
+	e.once.Do(func() {
+		conn, err := net.Dial("udp", daemonAddress(os.Getenv("AWS_XRAY_DAEMON_ADDRESS")))
+		if err == nil {
+			e.conn = conn
+		}
+	})
+	if e.conn == nil {
+		return
+	}
+	_, _ = e.conn.Write(append([]byte(daemonHeader), doc...))
+}
+
+// daemonAddress returns the UDP address from an AWS_XRAY_DAEMON_ADDRESS
+// value, which is either "host:port" or "tcp:host:port udp:host:port".
+func daemonAddress(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultDaemonAddress
+	}
+	for field := range strings.FieldsSeq(value) {
+		if address, ok := strings.CutPrefix(field, "udp:"); ok {
+			return address
+		}
+	}
+	if strings.Contains(value, " ") || strings.HasPrefix(value, "tcp:") {
+		return defaultDaemonAddress
+	}
+	return value
+}
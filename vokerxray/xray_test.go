@@ -0,0 +1,210 @@
+package vokerxray
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hotsock/voker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTraceID  = "1-5759e988-bd862e3fe1be46a994272793"
+	testParentID = "53995c3f42cd8ad8"
+)
+
+// listenDaemon replaces the package emitter with one that sends to a local
+// UDP listener standing in for the X-Ray daemon.
+func listenDaemon(t *testing.T) *net.UDPConn {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	t.Setenv("AWS_XRAY_DAEMON_ADDRESS", "tcp:127.0.0.1:2000 udp:"+conn.LocalAddr().String())
+	previous := defaultEmitter
+	defaultEmitter = &emitter{}
+	t.Cleanup(func() { defaultEmitter = previous })
+	return conn
+}
+
+func readDocument(t *testing.T, conn *net.UDPConn) map[string]any {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buf := make([]byte, 64*1024)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	header, body, ok := bytes.Cut(buf[:n], []byte("\n"))
+	require.True(t, ok)
+	assert.JSONEq(t, `{"format":"json","version":1}`, string(header))
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(body, &doc))
+	return doc
+}
+
+func sampledContext(sampled string) context.Context {
+	return voker.NewContext(context.Background(), &voker.LambdaContext{
+		TraceID: "Root=" + testTraceID + ";Parent=" + testParentID + ";Sampled=" + sampled,
+	})
+}
+
+func TestBeginSubsegment_Sends(t *testing.T) {
+	conn := listenDaemon(t)
+
+	ctx, seg := BeginSubsegment(sampledContext("1"), "load-order")
+	require.NotNil(t, seg)
+	seg.SetNamespace("aws")
+	seg.AddAnnotation("order_id", "o-1")
+	seg.AddMetadata("attempt", 2)
+
+	_, child := BeginSubsegment(ctx, "query")
+	child.Close(errors.New("timeout"))
+	seg.Close(nil)
+	seg.Close(nil)
+
+	childDoc := readDocument(t, conn)
+	assert.Equal(t, "query", childDoc["name"])
+	assert.Equal(t, testTraceID, childDoc["trace_id"])
+	assert.Equal(t, seg.id, childDoc["parent_id"])
+	assert.Equal(t, true, childDoc["fault"])
+	exceptions := childDoc["cause"].(map[string]any)["exceptions"].([]any)
+	assert.Equal(t, "timeout", exceptions[0].(map[string]any)["message"])
+
+	doc := readDocument(t, conn)
+	assert.Equal(t, "load-order", doc["name"])
+	assert.Equal(t, seg.id, doc["id"])
+	assert.Equal(t, testParentID, doc["parent_id"])
+	assert.Equal(t, "subsegment", doc["type"])
+	assert.Equal(t, "aws", doc["namespace"])
+	assert.Equal(t, map[string]any{"order_id": "o-1"}, doc["annotations"])
+	assert.Equal(t, map[string]any{"default": map[string]any{"attempt": float64(2)}}, doc["metadata"])
+	assert.NotContains(t, doc, "fault")
+	assert.GreaterOrEqual(t, doc["end_time"].(float64), doc["start_time"].(float64))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+	_, err := conn.Read(make([]byte, 1024))
+	assert.Error(t, err, "second Close must not send again")
+}
+
+func TestBeginSubsegment_UnencodableMetadata(t *testing.T) {
+	conn := listenDaemon(t)
+
+	_, seg := BeginSubsegment(sampledContext("1"), "encode")
+	seg.AddMetadata("fn", func() {})
+	seg.Close(nil)
+
+	doc := readDocument(t, conn)
+	assert.Equal(t, "encode", doc["name"])
+	assert.NotContains(t, doc, "metadata")
+}
+
+func TestBeginSubsegment_NoOp(t *testing.T) {
+	for name, ctx := range map[string]context.Context{
+		"no lambda context": context.Background(),
+		"not sampled":       sampledContext("0"),
+		"no trace header":   voker.NewContext(context.Background(), &voker.LambdaContext{}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, seg := BeginSubsegment(ctx, "noop")
+			assert.Nil(t, seg)
+			assert.Equal(t, ctx, got)
+
+			assert.NotPanics(t, func() {
+				seg.SetNamespace("remote")
+				seg.AddAnnotation("key", "value")
+				seg.AddMetadata("key", "value")
+				seg.Close(errors.New("ignored"))
+			})
+		})
+	}
+}
+
+func TestCapture(t *testing.T) {
+	conn := listenDaemon(t)
+	want := errors.New("failed")
+
+	err := Capture(sampledContext("1"), "work", func(ctx context.Context) error {
+		assert.NotNil(t, ctx.Value(subsegmentKey{}))
+		return want
+	})
+	assert.ErrorIs(t, err, want)
+
+	doc := readDocument(t, conn)
+	assert.Equal(t, "work", doc["name"])
+	assert.Equal(t, true, doc["fault"])
+}
+
+func TestMiddleware(t *testing.T) {
+	conn := listenDaemon(t)
+	want := errors.New("failed")
+
+	_, err := Middleware()(func(ctx context.Context, _ json.RawMessage) (any, error) {
+		AddAnnotation(ctx, "tenant", "acme")
+		AddMetadata(ctx, "items", 3)
+		return nil, want
+	})(sampledContext("1"), nil)
+	assert.ErrorIs(t, err, want)
+
+	doc := readDocument(t, conn)
+	assert.Equal(t, "## handler", doc["name"])
+	assert.Equal(t, testParentID, doc["parent_id"])
+	assert.Equal(t, map[string]any{"tenant": "acme"}, doc["annotations"])
+	assert.Equal(t, map[string]any{"default": map[string]any{"items": float64(3)}}, doc["metadata"])
+	assert.Equal(t, true, doc["fault"])
+}
+
+func TestMiddleware_Panic(t *testing.T) {
+	conn := listenDaemon(t)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_, _ = Middleware()(func(context.Context, json.RawMessage) (any, error) {
+			panic("boom")
+		})(sampledContext("1"), nil)
+	})
+
+	doc := readDocument(t, conn)
+	exceptions := doc["cause"].(map[string]any)["exceptions"].([]any)
+	assert.Equal(t, "panic: boom", exceptions[0].(map[string]any)["message"])
+}
+
+func TestSubsegment_TraceHeader(t *testing.T) {
+	_, seg := BeginSubsegment(sampledContext("1"), "call")
+	assert.Equal(t, "Root="+testTraceID+";Parent="+seg.id+";Sampled=1", seg.TraceHeader())
+
+	var unsampled *Subsegment
+	assert.Empty(t, unsampled.TraceHeader())
+}
+
+func TestAddAnnotation_NoSubsegment(t *testing.T) {
+	assert.Nil(t, SubsegmentFromContext(context.Background()))
+	assert.NotPanics(t, func() {
+		AddAnnotation(context.Background(), "key", "value")
+		AddMetadata(context.Background(), "key", "value")
+	})
+}
+
+func TestParseTraceHeader(t *testing.T) {
+	traceID, parentID, ok := parseTraceHeader("Root=" + testTraceID + "; Parent=" + testParentID + "; Sampled=1; Lineage=a:1")
+	assert.True(t, ok)
+	assert.Equal(t, testTraceID, traceID)
+	assert.Equal(t, testParentID, parentID)
+
+	_, _, ok = parseTraceHeader("Root=" + testTraceID + ";Sampled=1")
+	assert.False(t, ok)
+}
+
+func TestDaemonAddress(t *testing.T) {
+	assert.Equal(t, defaultDaemonAddress, daemonAddress(""))
+	assert.Equal(t, "169.254.79.129:2000", daemonAddress("169.254.79.129:2000"))
+	assert.Equal(t, "127.0.0.1:2001", daemonAddress("tcp:127.0.0.1:2000 udp:127.0.0.1:2001"))
+	assert.Equal(t, defaultDaemonAddress, daemonAddress("tcp:127.0.0.1:2000"))
+}
@@ -0,0 +1,58 @@
+package voker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// WarmupMatcher reports whether an invocation payload is a warm-up ping
+// rather than a real event.
+type WarmupMatcher func(payload json.RawMessage) bool
+
+// ServerlessPluginWarmup matches the events sent by serverless-plugin-warmup,
+// which carry "source": "serverless-plugin-warmup".
+var ServerlessPluginWarmup WarmupMatcher = func(payload json.RawMessage) bool {
+	if !bytes.Contains(payload, []byte("serverless-plugin-warmup")) {
+		return false
+	}
+	var event struct {
+		Source string `json:"source"`
+	}
+	return json.Unmarshal(payload, &event) == nil && event.Source == "serverless-plugin-warmup"
+}
+
+// LambdaWarmer matches the events sent by lambda-warmer, which carry
+// "warmer": true.
+var LambdaWarmer WarmupMatcher = func(payload json.RawMessage) bool {
+	if !bytes.Contains(payload, []byte("warmer")) {
+		return false
+	}
+	var event struct {
+		Warmer bool `json:"warmer"`
+	}
+	return json.Unmarshal(payload, &event) == nil && event.Warmer
+}
+
+// WithWarmupFilter answers invocations whose payload matches matcher with an
+// immediate null response, without decoding the payload or calling the
+// handler. onWarmup, if non-nil, is called with each warm-up payload first so
+// warmers can still record statistics.
+//
+// The filter is middleware. Register it before other middleware to skip
+// that middleware for warm-ups too.
+//
+//	voker.Start(handler, voker.WithWarmupFilter(voker.ServerlessPluginWarmup, nil))
+func WithWarmupFilter(matcher WarmupMatcher, onWarmup func(ctx context.Context, payload json.RawMessage)) Option {
+	return WithMiddleware(func(next InvokeFunc) InvokeFunc {
+		return func(ctx context.Context, payload json.RawMessage) (any, error) {
+			if !matcher(payload) {
+				return next(ctx, payload)
+			}
+			if onWarmup != nil {
+				onWarmup(ctx, payload)
+			}
+			return nil, nil
+		}
+	})
+}
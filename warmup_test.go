@@ -0,0 +1,71 @@
+package voker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmupMatchers(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher WarmupMatcher
+		payload string
+		want    bool
+	}{
+		{"serverless plugin", ServerlessPluginWarmup, `{"source":"serverless-plugin-warmup"}`, true},
+		{"serverless plugin in body", ServerlessPluginWarmup, `{"body":"serverless-plugin-warmup"}`, false},
+		{"serverless plugin other source", ServerlessPluginWarmup, `{"source":"aws.events"}`, false},
+		{"lambda warmer", LambdaWarmer, `{"warmer":true,"concurrency":3}`, true},
+		{"lambda warmer false", LambdaWarmer, `{"warmer":false}`, false},
+		{"lambda warmer string", LambdaWarmer, `{"warmer":"yes"}`, false},
+		{"not JSON", LambdaWarmer, `warmer`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.matcher(json.RawMessage(tt.payload)))
+		})
+	}
+}
+
+func TestWithWarmupFilter(t *testing.T) {
+	var handled, warmups []string
+	handler := func(_ context.Context, event testEvent) (testResponse, error) {
+		handled = append(handled, event.Name)
+		return testResponse{Message: "Hello, " + event.Name}, nil
+	}
+
+	opts := &options{}
+	WithWarmupFilter(ServerlessPluginWarmup, func(ctx context.Context, payload json.RawMessage) {
+		warmups = append(warmups, string(payload))
+	})(opts)
+
+	response, err := callHandler(context.Background(), []byte(`{"source":"serverless-plugin-warmup"}`), handler, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(response.payload))
+
+	response, err = callHandler(context.Background(), []byte(`{"name":"world"}`), handler, opts)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message":"Hello, world"}`, string(response.payload))
+
+	assert.Equal(t, []string{"world"}, handled)
+	assert.Equal(t, []string{`{"source":"serverless-plugin-warmup"}`}, warmups)
+}
+
+func TestWithWarmupFilter_NilHook(t *testing.T) {
+	handler := func(context.Context, testEvent) (testResponse, error) {
+		t.Error("handler must not be called for warm-ups")
+		return testResponse{}, nil
+	}
+
+	opts := &options{}
+	WithWarmupFilter(LambdaWarmer, nil)(opts)
+
+	response, err := callHandler(context.Background(), []byte(`{"warmer":true}`), handler, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(response.payload))
+}
@@ -0,0 +1,68 @@
+package voker
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+const maxGoroutineDumpBytes = 64 << 20
+
+type watchdogOptions struct {
+	margin        time.Duration
+	onNearTimeout func(context.Context)
+}
+
+// WithTimeoutWatchdog logs a dump of every goroutine's stack when a handler
+// is still running margin before the invocation deadline, then calls
+// onNearTimeout if it is non-nil. The dump shows what the handler was
+// waiting on when Lambda reports "Task timed out", which is otherwise lost
+// when the execution environment is stopped:
+//
+//	voker.Start(handler, voker.WithTimeoutWatchdog(500*time.Millisecond, nil))
+//
+// onNearTimeout runs on its own goroutine, concurrently with the handler,
+// and receives the invocation's context. The watchdog is skipped for
+// invocations that start with less than margin remaining.
+func WithTimeoutWatchdog(margin time.Duration, onNearTimeout func(context.Context)) Option {
+	return func(o *options) {
+		o.watchdog = &watchdogOptions{margin: margin, onNearTimeout: onNearTimeout}
+	}
+}
+
+// startWatchdog arms the timeout watchdog for one invocation and returns a
+// function that disarms it.
+func (o *options) startWatchdog(ctx context.Context, deadline time.Time) (stop func()) {
+	if o.watchdog == nil {
+		return func() {}
+	}
+
+	delay := time.Until(deadline) - o.watchdog.margin
+	if delay <= 0 {
+		return func() {}
+	}
+
+	timer := time.AfterFunc(delay, func() {
+		o.logger.WarnContext(ctx, "invocation near timeout",
+			"remaining", time.Until(deadline).String(),
+			"goroutines", string(goroutineDump()),
+		)
+		if o.watchdog.onNearTimeout != nil {
+			o.watchdog.onNearTimeout(ctx)
+		}
+	})
+	return func() { timer.Stop() }
+}
+
+// goroutineDump returns the stacks of all goroutines, growing the buffer
+// until the dump fits or reaches maxGoroutineDumpBytes.
+func goroutineDump() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxGoroutineDumpBytes {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
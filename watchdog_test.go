@@ -0,0 +1,79 @@
+package voker
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStartWatchdog_Fires(t *testing.T) {
+	logs := &syncBuffer{}
+	called := make(chan context.Context, 1)
+	options := &options{logger: slog.New(slog.NewJSONHandler(logs, nil))}
+	WithTimeoutWatchdog(time.Second, func(ctx context.Context) { called <- ctx })(options)
+
+	ctx := NewContext(context.Background(), &LambdaContext{AwsRequestID: "slow"})
+	stop := options.startWatchdog(ctx, time.Now().Add(time.Second+20*time.Millisecond))
+	defer stop()
+
+	select {
+	case got := <-called:
+		lc, ok := FromContext(got)
+		require.True(t, ok)
+		assert.Equal(t, "slow", lc.AwsRequestID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("watchdog did not fire")
+	}
+
+	output := logs.String()
+	assert.Contains(t, output, `"msg":"invocation near timeout"`)
+	assert.Contains(t, output, "goroutine ")
+	assert.Contains(t, output, "TestStartWatchdog_Fires")
+}
+
+func TestStartWatchdog_Stopped(t *testing.T) {
+	logs := &syncBuffer{}
+	options := &options{logger: slog.New(slog.NewJSONHandler(logs, nil))}
+	WithTimeoutWatchdog(time.Second, func(context.Context) { t.Error("callback called after stop") })(options)
+
+	stop := options.startWatchdog(context.Background(), time.Now().Add(time.Second+20*time.Millisecond))
+	stop()
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, logs.String())
+}
+
+func TestStartWatchdog_Disabled(t *testing.T) {
+	options := &options{}
+	assert.NotPanics(t, options.startWatchdog(context.Background(), time.Now().Add(time.Minute)))
+
+	WithTimeoutWatchdog(time.Minute, func(context.Context) { t.Error("callback called") })(options)
+	assert.NotPanics(t, options.startWatchdog(context.Background(), time.Now().Add(time.Second)))
+}
+
+func TestGoroutineDump(t *testing.T) {
+	dump := goroutineDump()
+	assert.Contains(t, string(dump), "TestGoroutineDump")
+}